@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidAddress возвращается для адреса, отклонённого AddressValidator.
+var ErrInvalidAddress = errors.New("invalid address")
+
+// Границы длины адреса в символах: верхняя совпадает с VARCHAR(512) колонки.
+const (
+	minAddressLen = 3
+	maxAddressLen = 512
+)
+
+// AddressValidator проверяет адрес посылки, уже приведённый
+// к каноническому виду через NormalizeAddress. Отказ должен
+// оборачивать ErrInvalidAddress.
+type AddressValidator interface {
+	ValidateAddress(address string) error
+}
+
+// DefaultAddressValidator требует непустой адрес длиной от MinLength
+// до MaxLength символов (0 — значения по умолчанию), хотя бы с одной
+// буквой и без управляющих символов.
+type DefaultAddressValidator struct {
+	MinLength int
+	MaxLength int
+}
+
+var _ AddressValidator = DefaultAddressValidator{}
+
+func (v DefaultAddressValidator) ValidateAddress(address string) error {
+	minLen, maxLen := v.MinLength, v.MaxLength
+	if minLen == 0 {
+		minLen = minAddressLen
+	}
+	if maxLen == 0 {
+		maxLen = maxAddressLen
+	}
+
+	n := utf8.RuneCountInString(address)
+	switch {
+	case n == 0:
+		return fmt.Errorf("%w: empty", ErrInvalidAddress)
+	case n < minLen:
+		return fmt.Errorf("%w: shorter than %d characters", ErrInvalidAddress, minLen)
+	case n > maxLen:
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidAddress, maxLen)
+	case !utf8.ValidString(address):
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidAddress)
+	case strings.IndexFunc(address, unicode.IsControl) >= 0:
+		return fmt.Errorf("%w: control characters", ErrInvalidAddress)
+	case strings.IndexFunc(address, unicode.IsLetter) < 0:
+		return fmt.Errorf("%w: no letters", ErrInvalidAddress)
+	}
+	return nil
+}
+
+// NormalizeAddress приводит адрес к каноническому виду: обрезает пробелы
+// по краям, схлопывает пробельные символы внутри в один пробел, убирает
+// пробел перед запятой и ставит после неё. Адрес, набранный целиком
+// заглавными буквами, переводится в обычный регистр с заглавной первой
+// буквой каждого слова; в остальных случаях регистр не трогается,
+// чтобы не портить сокращения вроде «д.» и «ул.».
+func NormalizeAddress(address string) string {
+	address = strings.Join(strings.Fields(strings.ReplaceAll(address, ",", ", ")), " ")
+	address = strings.ReplaceAll(address, " ,", ",")
+
+	if strings.IndexFunc(address, unicode.IsLetter) >= 0 && strings.IndexFunc(address, unicode.IsLower) < 0 {
+		address = titleWords(address)
+	}
+	return address
+}
+
+// titleWords оставляет заглавной только первую букву каждого слова.
+func titleWords(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	start := true
+	for _, r := range s {
+		if start {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		start = !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	return b.String()
+}
+
+// prepareAddress нормализует адрес и проверяет его валидатором v.
+func prepareAddress(v AddressValidator, address string) (string, error) {
+	address = NormalizeAddress(address)
+	if err := v.ValidateAddress(address); err != nil {
+		return "", err
+	}
+	return address, nil
+}
+
+// prepareParcel проверяет новую посылку p через Validate, нормализует
+// и проверяет её адреса и оплату. Пустой статус означает registered.
+// Адрес получателя обязателен, адрес отправителя проверяется, только
+// если задан: у посылок, заведённых до его появления, его нет.
+func prepareParcel(v AddressValidator, p Parcel) (Parcel, error) {
+	if p.Status == "" {
+		p.Status = ParcelStatusRegistered
+	}
+	if err := p.Validate(); err != nil {
+		return p, err
+	}
+	p, err := preparePayment(p)
+	if err != nil {
+		return p, err
+	}
+
+	if p.RecipientAddress, err = prepareAddress(v, p.RecipientAddress); err != nil {
+		return p, err
+	}
+	if p.SenderAddress != "" {
+		if p.SenderAddress, err = prepareAddress(v, p.SenderAddress); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// WithAddressValidator возвращает копию хранилища, проверяющую адреса
+// валидатором v вместо DefaultAddressValidator.
+func (s ParcelStore) WithAddressValidator(v AddressValidator) ParcelStore {
+	s.addresses = v
+	return s
+}
+
+// SetAddressValidator задаёт проверку адресов вместо DefaultAddressValidator.
+func (s *MemoryParcelStore) SetAddressValidator(v AddressValidator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addresses = v
+}
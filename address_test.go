@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeAddress проверяет приведение адреса к каноническому виду
+func TestNormalizeAddress(t *testing.T) {
+	for in, want := range map[string]string{
+		"  Москва,ул. Тверская ,  д. 1 ": "Москва, ул. Тверская, д. 1",
+		"многострочный\n\tадрес":         "многострочный адрес",
+		"МОСКВА, УЛ. ТВЕРСКАЯ, Д. 1":     "Москва, Ул. Тверская, Д. 1",
+		"ул. Ленина, д. 1":               "ул. Ленина, д. 1",
+		"   ":                            "",
+	} {
+		require.Equal(t, want, NormalizeAddress(in), in)
+	}
+}
+
+// TestDefaultAddressValidator проверяет отказы валидатора по умолчанию
+func TestDefaultAddressValidator(t *testing.T) {
+	v := DefaultAddressValidator{}
+	require.NoError(t, v.ValidateAddress("Москва, ул. Тверская, д. 1"))
+
+	for _, address := range []string{"", "ул", "12345", "адрес\x00", strings.Repeat("а", maxAddressLen+1)} {
+		require.ErrorIs(t, v.ValidateAddress(address), ErrInvalidAddress, address)
+	}
+
+	// границы длины настраиваются
+	require.ErrorIs(t, DefaultAddressValidator{MaxLength: 5}.ValidateAddress("Москва"), ErrInvalidAddress)
+	require.NoError(t, DefaultAddressValidator{MinLength: 1}.ValidateAddress("А"))
+}
+
+// checkAddressValidation проверяет нормализацию и проверку адреса в хранилище store
+func checkAddressValidation(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	parcel := getTestParcel()
+	parcel.RecipientAddress = "  Москва ,ул. Тверская "
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Москва, ул. Тверская", p.RecipientAddress)
+
+	parcel.RecipientAddress = " "
+	_, err = store.Add(ctx, parcel)
+	require.ErrorIs(t, err, ErrInvalidAddress)
+
+	batcher := store.(interface {
+		AddBatch(ctx context.Context, parcels []Parcel) ([]int, error)
+	})
+	_, err = batcher.AddBatch(ctx, []Parcel{getTestParcel(), parcel})
+	require.ErrorIs(t, err, ErrInvalidAddress)
+
+	require.NoError(t, store.SetRecipientAddress(ctx, id, "САНКТ-ПЕТЕРБУРГ,  НЕВСКИЙ ПР."))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Санкт-Петербург, Невский Пр.", p.RecipientAddress)
+
+	require.ErrorIs(t, store.SetRecipientAddress(ctx, id, "42"), ErrInvalidAddress)
+
+	p.RecipientAddress = ""
+	require.ErrorIs(t, store.Update(ctx, p), ErrInvalidAddress)
+
+	// неизменный адрес в Update не проверяется
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	p.Status = ParcelStatusSent
+	require.NoError(t, store.Update(ctx, p))
+}
+
+// TestAddressValidation проверяет проверку адресов в SQLite
+func TestAddressValidation(t *testing.T) {
+	checkAddressValidation(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryAddressValidation проверяет проверку адресов в памяти
+func TestMemoryAddressValidation(t *testing.T) {
+	checkAddressValidation(t, NewMemoryParcelStore())
+}
+
+// checkSenderAddress проверяет адрес отправителя в хранилище store
+func checkSenderAddress(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	parcel := getTestParcel()
+	parcel.SenderAddress = " Москва,  ул. Тверская, д. 1"
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Москва, ул. Тверская, д. 1", p.SenderAddress)
+	require.Equal(t, parcel.RecipientAddress, p.RecipientAddress)
+
+	require.NoError(t, store.SetSenderAddress(ctx, id, "Псков, ул. Колотушкина, д. 5"))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Псков, ул. Колотушкина, д. 5", p.SenderAddress)
+	require.Equal(t, parcel.RecipientAddress, p.RecipientAddress)
+	require.Equal(t, 2, p.Version)
+
+	require.ErrorIs(t, store.SetSenderAddress(ctx, id, ""), ErrInvalidAddress)
+	require.ErrorIs(t, store.SetSenderAddress(ctx, 999999, "Псков"), ErrParcelNotFound)
+
+	// после отправки не меняется ни один адрес
+	p.SenderAddress = "Саратов, ул. Козлова, д. 25"
+	require.NoError(t, store.Update(ctx, p))
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+	require.ErrorIs(t, store.SetSenderAddress(ctx, id, "Псков"), ErrAddressChangeNotAllowed)
+
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Саратов, ул. Козлова, д. 25", p.SenderAddress)
+	p.SenderAddress = "Псков"
+	require.ErrorIs(t, store.Update(ctx, p), ErrAddressChangeNotAllowed)
+}
+
+// TestSenderAddress проверяет адрес отправителя в SQLite
+func TestSenderAddress(t *testing.T) {
+	checkSenderAddress(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemorySenderAddress проверяет адрес отправителя в памяти
+func TestMemorySenderAddress(t *testing.T) {
+	checkSenderAddress(t, NewMemoryParcelStore())
+}
+
+// postcodeValidator требует шестизначный индекс в начале адреса
+type postcodeValidator struct{}
+
+func (postcodeValidator) ValidateAddress(address string) error {
+	code, _, ok := strings.Cut(address, ",")
+	if !ok || len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+		return fmt.Errorf("%w: postcode required", ErrInvalidAddress)
+	}
+	return nil
+}
+
+// TestWithAddressValidator проверяет замену валидатора адресов
+func TestWithAddressValidator(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t)).WithAddressValidator(postcodeValidator{})
+	parcel := getTestParcel()
+
+	// check
+	_, err := store.Add(ctx, parcel)
+	require.ErrorIs(t, err, ErrInvalidAddress)
+
+	parcel.RecipientAddress = "123456,  Москва"
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "123456, Москва", p.RecipientAddress)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//go:embed admin
+var adminFS embed.FS
+
+// adminPageSize — посылок на странице списка и в результатах поиска.
+const adminPageSize = 50
+
+// adminStatuses — статусы в фильтре списка в порядке жизненного цикла посылки.
+var adminStatuses = []Status{
+	ParcelStatusRegistered,
+	ParcelStatusSent,
+	ParcelStatusDelivered,
+	ParcelStatusReturnToSender,
+	ParcelStatusReturnRequested,
+	ParcelStatusReturning,
+	ParcelStatusReturned,
+	ParcelStatusCancelled,
+}
+
+// adminMessages — сообщения после успешного изменения по параметру ok.
+var adminMessages = map[string]string{
+	"status":  "Статус изменён.",
+	"address": "Адрес изменён.",
+}
+
+// AdminServer — встроенная веб-админка на /admin: список посылок с поиском,
+// карточка посылки с историей и формы смены статуса и адреса. Она работает
+// за тем же Authenticator, что и REST API; браузер передаёт ключ паролем
+// HTTP Basic, см. Authenticator.Middleware.
+type AdminServer struct {
+	service ParcelService
+	mux     *http.ServeMux
+	pages   map[string]*template.Template
+}
+
+func NewAdminServer(service ParcelService) *AdminServer {
+	s := &AdminServer{service: service, mux: http.NewServeMux(), pages: map[string]*template.Template{}}
+
+	funcs := template.FuncMap{
+		"statusName": func(st Status) string { return st.DisplayName(LangRU) },
+		"formatTime": formatTime,
+	}
+	for _, page := range []string{"list.html", "parcel.html"} {
+		s.pages[page] = template.Must(template.New(page).Funcs(funcs).
+			ParseFS(adminFS, "admin/layout.html", "admin/"+page))
+	}
+
+	s.mux.HandleFunc("GET /admin", s.handleList)
+	s.mux.HandleFunc("GET /admin/parcels/{number}", s.handleParcel)
+	s.mux.HandleFunc("POST /admin/parcels/{number}/status", s.handleSetStatus)
+	s.mux.HandleFunc("POST /admin/parcels/{number}/address", s.handleSetAddress)
+
+	return s
+}
+
+// ServeHTTP отклоняет формы с чужих сайтов: браузер сам подставляет
+// пароль HTTP Basic в любой запрос к админке, поэтому без проверки
+// Origin страница другого сайта могла бы менять посылки.
+func (s *AdminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && !sameOrigin(r) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// sameOrigin сообщает, что запрос пришёл со страницы этого же сервера
+// или без заголовка Origin, то есть не из браузера.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// adminPage — данные страницы админки. Error и Message показывает layout.html.
+type adminPage struct {
+	Error   string
+	Message string
+
+	// список
+	Query    string
+	Status   Status
+	Statuses []Status
+	Parcels  []Parcel
+	Next     string
+
+	// карточка посылки
+	Parcel       Parcel
+	History      []StatusChange
+	Events       []TrackingEvent
+	NextStatuses []Status
+}
+
+// handleList показывает посылки по статусу постранично или, с параметром q,
+// результат поиска: номер и трек-код открывают карточку посылки,
+// остальное ищется в адресах.
+func (s *AdminServer) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	page := adminPage{Query: strings.TrimSpace(q.Get("q")), Statuses: adminStatuses}
+	if v := q.Get("status"); v != "" {
+		st, err := ParseStatus(v)
+		if err != nil {
+			page.Error = err.Error()
+			s.render(w, http.StatusBadRequest, "list.html", page)
+			return
+		}
+		page.Status = st
+	}
+
+	if page.Query != "" {
+		if number, err := strconv.Atoi(page.Query); err == nil {
+			http.Redirect(w, r, adminParcelURL(number, ""), http.StatusSeeOther)
+			return
+		}
+		if code, err := NormalizeTrackCode(page.Query); err == nil {
+			p, err := s.service.GetByTrackCode(ctx, code)
+			if err == nil {
+				http.Redirect(w, r, adminParcelURL(p.Number, ""), http.StatusSeeOther)
+				return
+			}
+			if !errors.Is(err, ErrParcelNotFound) {
+				s.renderError(w, "list.html", page, err)
+				return
+			}
+		}
+		found, err := s.service.SearchByAddress(ctx, page.Query, ListOptions{Limit: adminPageSize})
+		if err != nil && !errors.Is(err, ErrInvalidSearchQuery) {
+			s.renderError(w, "list.html", page, err)
+			return
+		}
+		for _, p := range found.Parcels {
+			if page.Status == "" || p.Status == page.Status {
+				page.Parcels = append(page.Parcels, p)
+			}
+		}
+		s.render(w, http.StatusOK, "list.html", page)
+		return
+	}
+
+	after, err := DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		page.Error = err.Error()
+		s.render(w, http.StatusBadRequest, "list.html", page)
+		return
+	}
+	var filter Filter
+	if page.Status != "" {
+		filter.Statuses = []Status{page.Status}
+	}
+	list, err := s.service.List(ctx, filter, after, adminPageSize)
+	if err != nil {
+		s.renderError(w, "list.html", page, err)
+		return
+	}
+	page.Parcels, page.Next = list.Parcels, list.NextCursor
+	s.render(w, http.StatusOK, "list.html", page)
+}
+
+func (s *AdminServer) handleParcel(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.renderParcel(w, r, number, http.StatusOK, adminPage{Message: adminMessages[r.URL.Query().Get("ok")]})
+}
+
+func (s *AdminServer) handleSetStatus(w http.ResponseWriter, r *http.Request) {
+	s.handleForm(w, r, "status", func(number int) error {
+		st, err := ParseStatus(r.PostFormValue("status"))
+		if err != nil {
+			return err
+		}
+		return s.service.SetStatus(r.Context(), number, st)
+	})
+}
+
+func (s *AdminServer) handleSetAddress(w http.ResponseWriter, r *http.Request) {
+	s.handleForm(w, r, "address", func(number int) error {
+		set := s.service.ChangeAddress
+		if r.PostFormValue("sender") == "true" {
+			set = s.service.ChangeSenderAddress
+		}
+		return set(r.Context(), number, r.PostFormValue("address"))
+	})
+}
+
+// handleForm выполняет change и после успеха перенаправляет на карточку
+// посылки с сообщением ok, а при ошибке показывает карточку с ошибкой.
+func (s *AdminServer) handleForm(w http.ResponseWriter, r *http.Request, ok string, change func(number int) error) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := change(number); err != nil {
+		status := httpStatus(err)
+		if status == http.StatusInternalServerError {
+			err = errors.New(http.StatusText(status))
+		}
+		s.renderParcel(w, r, number, status, adminPage{Error: err.Error()})
+		return
+	}
+	http.Redirect(w, r, adminParcelURL(number, ok), http.StatusSeeOther)
+}
+
+// renderParcel показывает карточку посылки number с историей и событиями.
+func (s *AdminServer) renderParcel(w http.ResponseWriter, r *http.Request, number, status int, page adminPage) {
+	ctx := r.Context()
+	p, err := s.service.Get(ctx, number)
+	if err != nil {
+		s.renderError(w, "list.html", adminPage{Statuses: adminStatuses}, err)
+		return
+	}
+	page.Parcel, page.NextStatuses = p, s.service.NextStatuses(p.Status)
+	if page.History, err = s.service.GetHistory(ctx, number); err != nil {
+		s.renderError(w, "list.html", adminPage{Statuses: adminStatuses}, err)
+		return
+	}
+	if page.Events, err = s.service.GetEvents(ctx, number); err != nil {
+		s.renderError(w, "list.html", adminPage{Statuses: adminStatuses}, err)
+		return
+	}
+	s.render(w, status, "parcel.html", page)
+}
+
+// renderError показывает страницу name с ошибкой err и кодом ответа
+// REST API на ту же ошибку. Детали ошибок БД наружу не отдаются.
+func (s *AdminServer) renderError(w http.ResponseWriter, name string, page adminPage, err error) {
+	status := httpStatus(err)
+	page.Error = err.Error()
+	if status == http.StatusInternalServerError {
+		page.Error = http.StatusText(status)
+	}
+	s.render(w, status, name, page)
+}
+
+func (s *AdminServer) render(w http.ResponseWriter, status int, name string, page adminPage) {
+	var buf bytes.Buffer
+	if err := s.pages[name].ExecuteTemplate(&buf, "layout", page); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// adminParcelURL возвращает адрес карточки посылки с сообщением ok.
+func adminParcelURL(number int, ok string) string {
+	u := "/admin/parcels/" + strconv.Itoa(number)
+	if ok != "" {
+		u += "?ok=" + ok
+	}
+	return u
+}
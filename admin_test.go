@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// postAdminForm отправляет форму админки form на target со страницы origin
+func postAdminForm(t *testing.T, h http.Handler, target, origin string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestAdminServer проверяет список, поиск, карточку посылки и формы админки
+func TestAdminServer(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(NewMemoryParcelStore())
+	srv := NewHTTPServer(service)
+
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	number := strconv.Itoa(p.Number)
+	page := "/admin/parcels/" + number
+
+	rec := doRequest(t, srv, http.MethodGet, "/admin", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, rec.Body.String(), page)
+
+	rec = doRequest(t, srv, http.MethodGet, "/admin?q="+number, "")
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	require.Equal(t, page, rec.Header().Get("Location"))
+	rec = doRequest(t, srv, http.MethodGet, "/admin?q="+p.TrackCode, "")
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	require.Equal(t, page, rec.Header().Get("Location"))
+
+	// add
+	rec = postAdminForm(t, srv, page+"/status", "http://example.com", url.Values{"status": {"sent"}})
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	require.Equal(t, page+"?ok=status", rec.Header().Get("Location"))
+
+	// check
+	got, err := service.Get(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, got.Status)
+
+	rec = doRequest(t, srv, http.MethodGet, page+"?ok=status", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, "Статус изменён.")
+	require.Contains(t, body, p.TrackCode)
+	require.Contains(t, body, ParcelStatusRegistered.DisplayName(LangRU))
+
+	// ошибка сервиса показывается на карточке с кодом REST API
+	rec = postAdminForm(t, srv, page+"/address", "", url.Values{"address": {"new"}})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), ErrAddressChangeNotAllowed.Error())
+
+	// форма с чужого сайта отклоняется
+	rec = postAdminForm(t, srv, page+"/status", "http://evil.example", url.Values{"status": {"delivered"}})
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	got, err = service.Get(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, got.Status)
+
+	rec = doRequest(t, srv, http.MethodGet, "/admin/parcels/100000", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestAdminAuth проверяет вход в админку по ключу в пароле HTTP Basic
+func TestAdminAuth(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, reader, err := store.IssueAPIKey(ctx, APIKey{Name: "dashboard", Scopes: []string{ScopeRead}})
+	require.NoError(t, err)
+	h := NewAuthenticator(store).Middleware(NewHTTPServer(NewParcelService(store)))
+	p, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	rec := doRequest(t, h, http.MethodGet, "/admin", "")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Contains(t, rec.Header().Get("WWW-Authenticate"), "Basic")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("admin", reader)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// ключ на чтение не меняет посылки
+	req = httptest.NewRequest(http.MethodPost, "/admin/parcels/"+strconv.Itoa(p)+"/status",
+		strings.NewReader(url.Values{"status": {"sent"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", reader)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	// вне админки Basic не принимается
+	req = httptest.NewRequest(http.MethodGet, "/parcels/"+strconv.Itoa(p), nil)
+	req.SetBasicAuth("admin", reader)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// reachedStatusBefore отбирает посылки, перешедшие в статус раньше отметки
+// времени: момент перехода — последняя смена на этот статус из истории,
+// а для посылок без истории — время создания. Параметры: статус дважды,
+// затем отметка строкой и аргументом колонки created_at. Отметка
+// сравнивается с changed_at и created_at по отдельности, потому что
+// вне SQLite created_at имеет тип даты, а changed_at остаётся строкой.
+const reachedStatusBefore = "CASE WHEN EXISTS (SELECT 1 FROM parcel_status_history h" +
+	" WHERE h.parcel_number = parcel.number AND h.new_status = ?)" +
+	" THEN (SELECT MAX(h.changed_at) FROM parcel_status_history h" +
+	" WHERE h.parcel_number = parcel.number AND h.new_status = ?) < ?" +
+	" ELSE parcel.created_at < ? END"
+
+// ParcelArchiver переносит старые доставленные посылки в архив.
+type ParcelArchiver interface {
+	ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+var _ ParcelArchiver = ParcelStore{}
+
+// ArchiveOlderThan переносит посылки, доставленные раньше cutoff, в таблицу
+// parcel_archive и возвращает их количество. История и события посылок
+// остаются на месте. Посылки с открытыми претензиями не архивируются.
+func (s ParcelStore) ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	var archived int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		numbers, err := tx.numbers(ctx,
+			"SELECT number FROM parcel WHERE status = ? AND "+reachedStatusBefore+withoutOpenClaims("parcel"),
+			ParcelStatusDelivered, ParcelStatusDelivered, ParcelStatusDelivered,
+			formatTime(cutoff), tx.dialect.timeArg(cutoff))
+		if err != nil {
+			return err
+		}
+
+		archivedAt := formatTime(tx.now())
+		for start := 0; start < len(numbers); start += inBatchSize {
+			batch := numbers[start:min(start+inBatchSize, len(numbers))]
+			in := " WHERE number IN (" + placeholders(len(batch)) + ")"
+
+			args := []any{archivedAt}
+			for _, number := range batch {
+				args = append(args, number)
+			}
+
+			_, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+				"INSERT INTO parcel_archive (number, client, status, sender_address, recipient_address, created_at, archived_at, tenant_id)"+
+					" SELECT number, client, status, sender_address, recipient_address, created_at, ?, tenant_id FROM parcel"+in),
+				args...)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.q.ExecContext(ctx, tx.dialect.rebind("DELETE FROM parcel"+in), args[1:]...)
+			if err != nil {
+				return err
+			}
+		}
+		archived = len(numbers)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return archived, nil
+}
+
+// numbers выполняет запрос, возвращающий одну колонку с номерами посылок.
+func (s ParcelStore) numbers(ctx context.Context, query string, args ...any) ([]int, error) {
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []int
+	for rows.Next() {
+		var number int
+		if err := rows.Scan(&number); err != nil {
+			return nil, err
+		}
+		res = append(res, number)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ArchiveRunner периодически архивирует посылки, доставленные больше maxAge назад.
+type ArchiveRunner struct {
+	archiver ParcelArchiver
+	maxAge   time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewArchiveRunner(archiver ParcelArchiver, maxAge, interval time.Duration) ArchiveRunner {
+	return ArchiveRunner{archiver: archiver, maxAge: maxAge, interval: interval,
+		logger: slog.Default(), now: time.Now}
+}
+
+// WithLogger возвращает копию планировщика, пишущую результаты в logger.
+func (r ArchiveRunner) WithLogger(logger *slog.Logger) ArchiveRunner {
+	r.logger = logger
+	return r
+}
+
+// Run архивирует посылки сразу и затем каждые interval, пока не отменён ctx.
+// Ошибка одного прогона логируется и не останавливает планировщик.
+func (r ArchiveRunner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выполняет один прогон архивации и возвращает число перенесённых посылок.
+func (r ArchiveRunner) RunOnce(ctx context.Context) (int, error) {
+	cutoff := r.now().Add(-r.maxAge)
+	n, err := r.archiver.ArchiveOlderThan(ctx, cutoff)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "archive parcels", "cutoff", formatTime(cutoff), "error", err)
+		}
+		return 0, err
+	}
+
+	r.logger.InfoContext(ctx, "archive parcels", "cutoff", formatTime(cutoff), "archived", n)
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestArchiveOlderThan проверяет перенос давно доставленных посылок в архив
+func TestArchiveOlderThan(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+	ctx := context.Background()
+	store := NewParcelStore(db)
+	old := store.WithClock(func() time.Time { return time.Now().Add(-48 * time.Hour) })
+
+	// доставлена только что
+	recent, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, recent, ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, recent, ParcelStatusDelivered))
+
+	// доставлена давно, истории смен статуса нет
+	delivered := getTestParcel()
+	archived, err := old.Add(ctx, delivered)
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE parcel SET status = ? WHERE number = ?", ParcelStatusDelivered, archived)
+	require.NoError(t, err)
+
+	// старая, но не доставленная
+	kept, err := old.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// archive
+	n, err := store.ArchiveOlderThan(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// check
+	_, err = store.Get(ctx, archived)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	var address, archivedAt string
+	err = db.QueryRow("SELECT recipient_address, archived_at FROM parcel_archive WHERE number = ?", archived).
+		Scan(&address, &archivedAt)
+	require.NoError(t, err)
+	require.Equal(t, delivered.RecipientAddress, address)
+	require.NotEmpty(t, archivedAt)
+
+	for _, number := range []int{recent, kept} {
+		_, err = store.Get(ctx, number)
+		require.NoError(t, err)
+	}
+
+	// более поздняя граница захватывает и недавно доставленную посылку
+	n, err = store.ArchiveOlderThan(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+// countingArchiver считает вызовы, запоминает последнюю границу и возвращает заданную ошибку
+type countingArchiver struct {
+	calls  atomic.Int32
+	cutoff time.Time
+	err    error
+}
+
+func (a *countingArchiver) ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	a.calls.Add(1)
+	a.cutoff = cutoff
+	return 1, a.err
+}
+
+// TestArchiveRunner проверяет, что планировщик повторяет архивацию до отмены и не останавливается на ошибках
+func TestArchiveRunner(t *testing.T) {
+	// prepare
+	archiver := &countingArchiver{err: errors.New("db is down")}
+	runner := NewArchiveRunner(archiver, time.Hour, time.Millisecond).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// run
+	err := runner.Run(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// check
+	require.Greater(t, archiver.calls.Load(), int32(1))
+}
+
+// TestArchiveRunnerClock проверяет, что граница архивации отсчитывается от часов планировщика
+func TestArchiveRunnerClock(t *testing.T) {
+	// prepare
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	archiver := &countingArchiver{}
+	runner := NewArchiveRunner(archiver, 24*time.Hour, time.Hour).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	runner.now = func() time.Time { return now }
+
+	// run
+	_, err := runner.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, now.Add(-24*time.Hour), archiver.cutoff)
+}
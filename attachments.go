@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttachmentKind — вид подтверждения доставки.
+type AttachmentKind string
+
+const (
+	AttachmentPhoto     AttachmentKind = "photo"
+	AttachmentSignature AttachmentKind = "signature"
+)
+
+// defaultAttachmentMaxBytes ограничивает вложение, если в настройках
+// не задан max_bytes.
+const defaultAttachmentMaxBytes = 10 << 20
+
+var (
+	// ErrInvalidAttachment возвращается для вложения неизвестного вида,
+	// пустого, слишком большого или не изображения.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentNotFound возвращается для вложения, которого нет.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrProofNotAllowed возвращается при вложении к посылке, которая
+	// ещё не отправлена или уже ушла в возврат или отмену.
+	ErrProofNotAllowed = errors.New("proof of delivery can be attached only to sent or delivered parcels")
+	// ErrAttachmentsDisabled возвращает ParcelService, если вложения
+	// не настроены, см. AttachmentConfig.
+	ErrAttachmentsDisabled = errors.New("attachments are not configured")
+)
+
+// AttachmentConfig задаёт хранение вложений: содержимое — в файлах
+// каталога Dir, описание — в таблице parcel_attachment. Пустой Dir
+// выключает вложения. MaxBytes ограничивает размер одного вложения,
+// ноль — 10 МиБ.
+type AttachmentConfig struct {
+	Dir      string `yaml:"dir"`
+	MaxBytes int64  `yaml:"max_bytes"`
+}
+
+func (c AttachmentConfig) enabled() bool {
+	return c.Dir != ""
+}
+
+func (c AttachmentConfig) validate() error {
+	if c.MaxBytes < 0 {
+		return errors.New("config: attachments max_bytes must not be negative")
+	}
+	return nil
+}
+
+// Attachment — описание вложения к посылке Number. Содержимое лежит
+// в BlobStore под ключом BlobKey, SHA256 — его контрольная сумма.
+type Attachment struct {
+	ID          int
+	Number      int
+	Kind        AttachmentKind
+	ContentType string
+	Size        int64
+	SHA256      string
+	BlobKey     string
+	CreatedAt   time.Time
+}
+
+// AttachmentStore хранит описания вложений в таблице parcel_attachment.
+type AttachmentStore interface {
+	// AddAttachment сохраняет описание вложения к посылке a.Number и возвращает его id
+	AddAttachment(ctx context.Context, a Attachment) (int, error)
+	GetAttachment(ctx context.Context, id int) (Attachment, error)
+	// ListAttachments возвращает вложения посылки number по порядку добавления
+	ListAttachments(ctx context.Context, number int) ([]Attachment, error)
+}
+
+var (
+	_ AttachmentStore = ParcelStore{}
+	_ AttachmentStore = (*MemoryParcelStore)(nil)
+)
+
+const attachmentColumns = "a.id, a.parcel_number, a.kind, a.content_type, a.size, a.sha256, a.blob_key, a.created_at"
+
+func (s ParcelStore) AddAttachment(ctx context.Context, a Attachment) (int, error) {
+	var id int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		if _, err := tx.status(ctx, a.Number); err != nil {
+			return err
+		}
+		var err error
+		id, err = tx.insert(ctx,
+			"INSERT INTO parcel_attachment (parcel_number, kind, content_type, size, sha256, blob_key, created_at)"+
+				" VALUES (?, ?, ?, ?, ?, ?, ?)", "id",
+			a.Number, a.Kind, a.ContentType, a.Size, a.SHA256, a.BlobKey, tx.dialect.timeArg(a.CreatedAt))
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetAttachment возвращает вложение id, если его посылка не удалена
+// и принадлежит арендатору из ctx, иначе ErrAttachmentNotFound.
+func (s ParcelStore) GetAttachment(ctx context.Context, id int) (Attachment, error) {
+	list, err := s.attachments(ctx, "a.id = ?", id)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if len(list) == 0 {
+		return Attachment{}, ErrAttachmentNotFound
+	}
+	return list[0], nil
+}
+
+func (s ParcelStore) ListAttachments(ctx context.Context, number int) ([]Attachment, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+	return s.attachments(ctx, "a.parcel_number = ?", number)
+}
+
+// attachments возвращает вложения по условию where по возрастанию id.
+// Вложения удалённых и чужих посылок в выборку не попадают.
+func (s ParcelStore) attachments(ctx context.Context, where string, args ...any) ([]Attachment, error) {
+	cond, tenantArgs := tenantCondOn(ctx, "p.tenant_id")
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT "+attachmentColumns+" FROM parcel_attachment a JOIN parcel p ON p.number = a.parcel_number"+
+			" WHERE "+where+" AND p.deleted_at IS NULL"+cond+" ORDER BY a.id"), append(args, tenantArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Attachment
+	for rows.Next() {
+		var a Attachment
+		err := rows.Scan(&a.ID, &a.Number, &a.Kind, &a.ContentType, &a.Size, &a.SHA256, &a.BlobKey,
+			scanTime{&a.CreatedAt})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+func (s *MemoryParcelStore) AddAttachment(ctx context.Context, a Attachment) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, a.Number); !ok {
+		return 0, ErrParcelNotFound
+	}
+	s.lastAttach++
+	a.ID = s.lastAttach
+	s.attachments[a.Number] = append(s.attachments[a.Number], a)
+
+	return a.ID, nil
+}
+
+func (s *MemoryParcelStore) GetAttachment(ctx context.Context, id int) (Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return Attachment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for number, list := range s.attachments {
+		if _, ok := s.parcel(ctx, number); !ok {
+			continue
+		}
+		for _, a := range list {
+			if a.ID == id {
+				return a, nil
+			}
+		}
+	}
+	return Attachment{}, ErrAttachmentNotFound
+}
+
+func (s *MemoryParcelStore) ListAttachments(ctx context.Context, number int) ([]Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return nil, ErrParcelNotFound
+	}
+	return append([]Attachment(nil), s.attachments[number]...), nil
+}
+
+// Attachments сохраняет вложения к посылкам: содержимое — в blobs,
+// описание — в meta. Содержимое пишется первым, и при ошибке записи
+// описания удаляется, поэтому описание без содержимого не появляется.
+type Attachments struct {
+	meta     AttachmentStore
+	blobs    BlobStore
+	maxBytes int64
+	now      func() time.Time
+}
+
+func NewAttachments(meta AttachmentStore, blobs BlobStore, maxBytes int64) *Attachments {
+	if maxBytes <= 0 {
+		maxBytes = defaultAttachmentMaxBytes
+	}
+	return &Attachments{meta: meta, blobs: blobs, maxBytes: maxBytes, now: time.Now}
+}
+
+// Add сохраняет изображение из r как вложение вида kind к посылке number.
+// Тип содержимого определяется по первым байтам, а не со слов клиента.
+func (a *Attachments) Add(ctx context.Context, number int, kind AttachmentKind, r io.Reader) (Attachment, error) {
+	if kind != AttachmentPhoto && kind != AttachmentSignature {
+		return Attachment{}, fmt.Errorf("%w: unknown kind %q", ErrInvalidAttachment, kind)
+	}
+
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Attachment{}, err
+	}
+	if len(head) == 0 {
+		return Attachment{}, fmt.Errorf("%w: empty content", ErrInvalidAttachment)
+	}
+	contentType := http.DetectContentType(head)
+	if !strings.HasPrefix(contentType, "image/") {
+		return Attachment{}, fmt.Errorf("%w: content type %s is not an image", ErrInvalidAttachment, contentType)
+	}
+
+	att := Attachment{
+		Number:      number,
+		Kind:        kind,
+		ContentType: contentType,
+		BlobKey:     "parcels/" + strconv.Itoa(number) + "/" + uuid.NewString(),
+		CreatedAt:   timestamp(a.now),
+	}
+	hash := sha256.New()
+	// лишний байт сверх лимита показывает, что содержимое слишком большое
+	size, err := a.blobs.Put(ctx, att.BlobKey, io.TeeReader(io.LimitReader(br, a.maxBytes+1), hash))
+	if err != nil {
+		return Attachment{}, err
+	}
+	if size > a.maxBytes {
+		return Attachment{}, errors.Join(
+			fmt.Errorf("%w: larger than %d bytes", ErrInvalidAttachment, a.maxBytes),
+			a.blobs.Delete(ctx, att.BlobKey))
+	}
+	att.Size = size
+	att.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if att.ID, err = a.meta.AddAttachment(ctx, att); err != nil {
+		return Attachment{}, errors.Join(err, a.blobs.Delete(ctx, att.BlobKey))
+	}
+	return att, nil
+}
+
+// List возвращает вложения посылки number.
+func (a *Attachments) List(ctx context.Context, number int) ([]Attachment, error) {
+	return a.meta.ListAttachments(ctx, number)
+}
+
+// Open возвращает описание и содержимое вложения id; содержимое
+// закрывает вызывающий.
+func (a *Attachments) Open(ctx context.Context, id int) (Attachment, io.ReadCloser, error) {
+	att, err := a.meta.GetAttachment(ctx, id)
+	if err != nil {
+		return Attachment{}, nil, err
+	}
+	rc, err := a.blobs.Open(ctx, att.BlobKey)
+	if err != nil {
+		return Attachment{}, nil, err
+	}
+	return att, rc, nil
+}
+
+// WithAttachments возвращает копию сервиса, сохраняющую подтверждения
+// доставки в attachments.
+func (s ParcelService) WithAttachments(attachments *Attachments) ParcelService {
+	s.attachments = attachments
+	return s
+}
+
+// AttachProofOfDelivery сохраняет фото или подпись получателя из r
+// к отправленной или доставленной посылке number.
+func (s ParcelService) AttachProofOfDelivery(ctx context.Context, number int, kind AttachmentKind, r io.Reader) (Attachment, error) {
+	if s.attachments == nil {
+		return Attachment{}, ErrAttachmentsDisabled
+	}
+	p, err := s.store.Get(ctx, number)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if p.Status != ParcelStatusSent && p.Status != ParcelStatusDelivered {
+		return Attachment{}, ErrProofNotAllowed
+	}
+
+	att, err := s.attachments.Add(ctx, number, kind, r)
+	if err != nil {
+		return Attachment{}, err
+	}
+	s.logger.InfoContext(ctx, "proof of delivery attached",
+		slog.Int("number", number), slog.Int("attachment", att.ID), slog.String("kind", string(kind)),
+		slog.Int64("size", att.Size))
+
+	return att, nil
+}
+
+// ListAttachments возвращает вложения посылки number.
+func (s ParcelService) ListAttachments(ctx context.Context, number int) ([]Attachment, error) {
+	if s.attachments == nil {
+		return nil, ErrAttachmentsDisabled
+	}
+	return s.attachments.List(ctx, number)
+}
+
+// OpenAttachment возвращает описание и содержимое вложения id.
+func (s ParcelService) OpenAttachment(ctx context.Context, id int) (Attachment, io.ReadCloser, error) {
+	if s.attachments == nil {
+		return Attachment{}, nil, ErrAttachmentsDisabled
+	}
+	return s.attachments.Open(ctx, id)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG — начало PNG-файла, по которому определяется тип image/png
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+// checkAttachments проверяет сохранение, выборку и выгрузку подтверждений
+// доставки с описаниями в хранилище store
+func checkAttachments(t *testing.T, store interface {
+	ParcelStorage
+	AttachmentStore
+}) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(store).WithAttachments(NewAttachments(store, NewFileBlobStore(t.TempDir()), 1024))
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// к ещё не отправленной посылке подтверждение не прикладывается
+	_, err = service.AttachProofOfDelivery(ctx, p.Number, AttachmentPhoto, bytes.NewReader(testPNG))
+	require.ErrorIs(t, err, ErrProofNotAllowed)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+
+	_, err = service.AttachProofOfDelivery(ctx, p.Number, "video", bytes.NewReader(testPNG))
+	require.ErrorIs(t, err, ErrInvalidAttachment)
+	_, err = service.AttachProofOfDelivery(ctx, p.Number, AttachmentPhoto, strings.NewReader("просто текст"))
+	require.ErrorIs(t, err, ErrInvalidAttachment)
+	_, err = service.AttachProofOfDelivery(ctx, p.Number, AttachmentPhoto, strings.NewReader(""))
+	require.ErrorIs(t, err, ErrInvalidAttachment)
+	big := append(append([]byte{}, testPNG...), bytes.Repeat([]byte{1}, 1024)...)
+	_, err = service.AttachProofOfDelivery(ctx, p.Number, AttachmentPhoto, bytes.NewReader(big))
+	require.ErrorIs(t, err, ErrInvalidAttachment)
+
+	// add
+	att, err := service.AttachProofOfDelivery(ctx, p.Number, AttachmentSignature, bytes.NewReader(testPNG))
+	require.NoError(t, err)
+
+	// check
+	require.NotZero(t, att.ID)
+	require.Equal(t, "image/png", att.ContentType)
+	require.Equal(t, int64(len(testPNG)), att.Size)
+	require.Len(t, att.SHA256, 64)
+
+	list, err := service.ListAttachments(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, []Attachment{att}, list)
+
+	got, content, err := service.OpenAttachment(ctx, att.ID)
+	require.NoError(t, err)
+	defer content.Close()
+	require.Equal(t, att, got)
+	b, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.Equal(t, testPNG, b)
+
+	_, _, err = service.OpenAttachment(ctx, att.ID+100)
+	require.ErrorIs(t, err, ErrAttachmentNotFound)
+
+	// вложение посылки другого арендатора не находится
+	_, err = store.GetAttachment(WithTenant(ctx, "shop-b"), att.ID)
+	require.ErrorIs(t, err, ErrAttachmentNotFound)
+}
+
+// TestAttachments проверяет подтверждения доставки в SQLite и в памяти
+func TestAttachments(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkAttachments(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkAttachments(t, NewMemoryParcelStore())
+	})
+}
+
+// TestFileBlobStoreKeys проверяет, что ключ не выводит за каталог хранилища
+func TestFileBlobStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	blobs := NewFileBlobStore(t.TempDir())
+
+	for _, key := range []string{"", "../escape", "/abs", `a\b`} {
+		_, err := blobs.Put(ctx, key, strings.NewReader("x"))
+		require.Error(t, err, key)
+	}
+	_, err := blobs.Open(ctx, "parcels/1/missing")
+	require.ErrorIs(t, err, ErrBlobNotFound)
+	require.NoError(t, blobs.Delete(ctx, "parcels/1/missing"))
+}
+
+// TestHTTPAttachments проверяет загрузку и выгрузку подтверждения
+// доставки через REST API
+func TestHTTPAttachments(t *testing.T) {
+	// prepare
+	store := NewMemoryParcelStore()
+	service := NewParcelService(store)
+	rec := doRequest(t, NewHTTPServer(service), http.MethodGet, "/parcels/1/attachments", "")
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+
+	srv := NewHTTPServer(service.WithAttachments(NewAttachments(store, NewFileBlobStore(t.TempDir()), 0)))
+	rec = doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 7, "recipient_address": "test"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	number := strconv.Itoa(created.Number)
+	require.NoError(t, store.SetStatus(context.Background(), created.Number, ParcelStatusSent))
+
+	// add
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/attachments?kind=photo", string(testPNG))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var att attachmentResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&att))
+
+	// check
+	require.Equal(t, "photo", att.Kind)
+	rec = doRequest(t, srv, http.MethodGet, "/parcels/"+number+"/attachments", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list []attachmentResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Equal(t, []attachmentResponse{att}, list)
+
+	rec = doRequest(t, srv, http.MethodGet, "/attachments/"+strconv.Itoa(att.ID), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	require.Equal(t, testPNG, rec.Body.Bytes())
+
+	rec = doRequest(t, srv, http.MethodGet, "/attachments/999", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestHTTPAttachmentTenant проверяет, что арендатор не выгружает
+// вложение чужой посылки через REST API
+func TestHTTPAttachmentTenant(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, keyA, err := store.IssueAPIKey(ctx, APIKey{Name: "shop a", Tenant: "shop-a", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+	_, keyB, err := store.IssueAPIKey(ctx, APIKey{Name: "shop b", Tenant: "shop-b", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+	service := NewParcelService(store).WithAttachments(NewAttachments(store, NewFileBlobStore(t.TempDir()), 0))
+	h := NewAuthenticator(store).Middleware(NewHTTPServer(service))
+
+	shopA := WithTenant(ctx, "shop-a")
+	p, err := service.Create(shopA, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, service.SetStatus(shopA, p.Number, ParcelStatusSent))
+	att, err := service.AttachProofOfDelivery(shopA, p.Number, AttachmentPhoto, bytes.NewReader(testPNG))
+	require.NoError(t, err)
+
+	// check
+	get := func(secret string) int {
+		req := httptest.NewRequest(http.MethodGet, "/attachments/"+strconv.Itoa(att.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusOK, get(keyA))
+	require.Equal(t, http.StatusNotFound, get(keyB))
+}
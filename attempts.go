@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+)
+
+// AttemptOutcome — причина, по которой курьер не смог доставить посылку.
+type AttemptOutcome string
+
+const (
+	AttemptNoAnswer     AttemptOutcome = "no_answer"
+	AttemptRefused      AttemptOutcome = "refused"
+	AttemptWrongAddress AttemptOutcome = "wrong_address"
+	AttemptNoAccess     AttemptOutcome = "no_access"
+)
+
+// maxAttemptNoteLen — длина колонки note таблицы delivery_attempt.
+const maxAttemptNoteLen = 512
+
+// defaultMaxAttempts — число неудачных попыток до возврата отправителю,
+// если в настройках не задан max_attempts.
+const defaultMaxAttempts = 3
+
+var (
+	// ErrInvalidAttempt возвращается для попытки с неизвестным исходом
+	// или слишком длинным комментарием.
+	ErrInvalidAttempt = errors.New("invalid delivery attempt")
+	// ErrAttemptNotAllowed возвращается при записи попытки доставки
+	// посылки, которая не в пути.
+	ErrAttemptNotAllowed = errors.New("delivery attempt can be recorded only for sent parcels")
+	// ErrNoAttemptStore возвращает ParcelService без WithDeliveryAttempts.
+	ErrNoAttemptStore = errors.New("parcel service has no delivery attempt store")
+)
+
+// AttemptConfig задаёт правило возврата: после MaxAttempts неудачных
+// попыток посылка переходит в return_to_sender, ноль — после трёх.
+type AttemptConfig struct {
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+func (c AttemptConfig) validate() error {
+	if c.MaxAttempts < 0 {
+		return errors.New("config: attempts max_attempts must not be negative")
+	}
+	return nil
+}
+
+// DeliveryAttempt — неудачная попытка доставки посылки Number.
+// Attempt — её порядковый номер среди попыток этой посылки, начиная с 1.
+type DeliveryAttempt struct {
+	ID          int
+	Number      int
+	Attempt     int
+	Outcome     AttemptOutcome
+	Note        string
+	AttemptedAt time.Time
+}
+
+func validateAttempt(outcome AttemptOutcome, note string) error {
+	switch outcome {
+	case AttemptNoAnswer, AttemptRefused, AttemptWrongAddress, AttemptNoAccess:
+	default:
+		return fmt.Errorf("%w: unknown outcome %q", ErrInvalidAttempt, outcome)
+	}
+	if utf8.RuneCountInString(note) > maxAttemptNoteLen {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalidAttempt, maxAttemptNoteLen)
+	}
+	return nil
+}
+
+// AttemptStore хранит журнал попыток доставки в таблице delivery_attempt.
+type AttemptStore interface {
+	// AddDeliveryAttempt записывает неудачную попытку доставки посылки number
+	// в статусе sent
+	AddDeliveryAttempt(ctx context.Context, number int, outcome AttemptOutcome, note string) (DeliveryAttempt, error)
+	// GetAttempts возвращает попытки доставки посылки number по порядку
+	GetAttempts(ctx context.Context, number int) ([]DeliveryAttempt, error)
+}
+
+var (
+	_ AttemptStore = ParcelStore{}
+	_ AttemptStore = (*MemoryParcelStore)(nil)
+)
+
+func (s ParcelStore) AddDeliveryAttempt(ctx context.Context, number int, outcome AttemptOutcome, note string) (DeliveryAttempt, error) {
+	if err := validateAttempt(outcome, note); err != nil {
+		return DeliveryAttempt{}, err
+	}
+
+	a := DeliveryAttempt{Number: number, Outcome: outcome, Note: note, AttemptedAt: timestamp(s.now)}
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		status, err := tx.status(ctx, number)
+		if err != nil {
+			return err
+		}
+		if status != ParcelStatusSent {
+			return ErrAttemptNotAllowed
+		}
+		err = tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+			"SELECT COUNT(*) FROM delivery_attempt WHERE parcel_number = ?"), number).Scan(&a.Attempt)
+		if err != nil {
+			return err
+		}
+		a.Attempt++
+		a.ID, err = tx.insert(ctx,
+			"INSERT INTO delivery_attempt (parcel_number, outcome, note, attempted_at) VALUES (?, ?, ?, ?)", "id",
+			number, outcome, note, tx.dialect.timeArg(a.AttemptedAt))
+		return err
+	})
+	if err != nil {
+		return DeliveryAttempt{}, err
+	}
+	return a, nil
+}
+
+func (s ParcelStore) GetAttempts(ctx context.Context, number int) ([]DeliveryAttempt, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT id, parcel_number, outcome, note, attempted_at FROM delivery_attempt WHERE parcel_number = ? ORDER BY id"),
+		number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []DeliveryAttempt
+	for rows.Next() {
+		a := DeliveryAttempt{Attempt: len(res) + 1}
+		if err := rows.Scan(&a.ID, &a.Number, &a.Outcome, &a.Note, scanTime{&a.AttemptedAt}); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+func (s *MemoryParcelStore) AddDeliveryAttempt(ctx context.Context, number int, outcome AttemptOutcome, note string) (DeliveryAttempt, error) {
+	if err := ctx.Err(); err != nil {
+		return DeliveryAttempt{}, err
+	}
+	if err := validateAttempt(outcome, note); err != nil {
+		return DeliveryAttempt{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return DeliveryAttempt{}, ErrParcelNotFound
+	}
+	if p.Status != ParcelStatusSent {
+		return DeliveryAttempt{}, ErrAttemptNotAllowed
+	}
+	s.lastAttempt++
+	a := DeliveryAttempt{
+		ID:          s.lastAttempt,
+		Number:      number,
+		Attempt:     len(s.attempts[number]) + 1,
+		Outcome:     outcome,
+		Note:        note,
+		AttemptedAt: timestamp(s.now),
+	}
+	s.attempts[number] = append(s.attempts[number], a)
+
+	return a, nil
+}
+
+func (s *MemoryParcelStore) GetAttempts(ctx context.Context, number int) ([]DeliveryAttempt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return nil, ErrParcelNotFound
+	}
+	return append([]DeliveryAttempt(nil), s.attempts[number]...), nil
+}
+
+// WithDeliveryAttempts возвращает копию сервиса, записывающую попытки
+// доставки в attempts. После maxAttempts неудачных попыток посылка
+// возвращается отправителю; неположительный maxAttempts означает три.
+func (s ParcelService) WithDeliveryAttempts(attempts AttemptStore, maxAttempts int) ParcelService {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	s.attempts = attempts
+	s.maxAttempts = maxAttempts
+	return s
+}
+
+// RecordDeliveryAttempt записывает неудачную попытку доставки посылки number.
+// Попытка, достигшая лимита WithDeliveryAttempts, переводит посылку
+// в return_to_sender через SetStatus, с событием и записью в истории.
+func (s ParcelService) RecordDeliveryAttempt(ctx context.Context, number int, outcome AttemptOutcome, note string) (DeliveryAttempt, error) {
+	if s.attempts == nil {
+		return DeliveryAttempt{}, ErrNoAttemptStore
+	}
+
+	a, err := s.attempts.AddDeliveryAttempt(ctx, number, outcome, note)
+	if err != nil {
+		return DeliveryAttempt{}, err
+	}
+	s.logger.InfoContext(ctx, "delivery attempt failed",
+		slog.Int("number", number), slog.Int("attempt", a.Attempt), slog.String("outcome", string(outcome)))
+
+	if a.Attempt >= s.maxAttempts {
+		if err := s.SetStatus(ctx, number, ParcelStatusReturnToSender); err != nil {
+			return a, fmt.Errorf("return parcel %d to sender: %w", number, err)
+		}
+	}
+	return a, nil
+}
+
+// GetAttempts возвращает попытки доставки посылки number.
+func (s ParcelService) GetAttempts(ctx context.Context, number int) ([]DeliveryAttempt, error) {
+	if s.attempts == nil {
+		return nil, ErrNoAttemptStore
+	}
+	return s.attempts.GetAttempts(ctx, number)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// attemptStorage — хранилище посылок с журналом попыток доставки.
+type attemptStorage interface {
+	ParcelStorage
+	AttemptStore
+}
+
+// checkAttempts проверяет журнал попыток доставки и возврат посылки
+// отправителю после лимита неудач на хранилище store
+func checkAttempts(t *testing.T, store attemptStorage) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(store).WithDeliveryAttempts(store, 2)
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// посылка ещё не у курьера
+	_, err = service.RecordDeliveryAttempt(ctx, p.Number, AttemptNoAnswer, "")
+	require.ErrorIs(t, err, ErrAttemptNotAllowed)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+
+	_, err = service.RecordDeliveryAttempt(ctx, p.Number, "lost", "")
+	require.ErrorIs(t, err, ErrInvalidAttempt)
+	_, err = service.RecordDeliveryAttempt(ctx, p.Number, AttemptRefused, strings.Repeat("я", maxAttemptNoteLen+1))
+	require.ErrorIs(t, err, ErrInvalidAttempt)
+	_, err = service.RecordDeliveryAttempt(ctx, p.Number+1000, AttemptNoAnswer, "")
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// add
+	first, err := service.RecordDeliveryAttempt(ctx, p.Number, AttemptNoAnswer, "домофон не работает")
+	require.NoError(t, err)
+	got, err := service.Get(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, got.Status)
+
+	second, err := service.RecordDeliveryAttempt(ctx, p.Number, AttemptNoAccess, "")
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, 1, first.Attempt)
+	require.Equal(t, 2, second.Attempt)
+	got, err = service.Get(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusReturnToSender, got.Status)
+
+	attempts, err := service.GetAttempts(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, []DeliveryAttempt{first, second}, attempts)
+
+	// после возврата попытки больше не записываются
+	_, err = service.RecordDeliveryAttempt(ctx, p.Number, AttemptNoAnswer, "")
+	require.ErrorIs(t, err, ErrAttemptNotAllowed)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusReturned))
+}
+
+// TestDeliveryAttempts проверяет попытки доставки в SQLite и в памяти
+func TestDeliveryAttempts(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkAttempts(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkAttempts(t, NewMemoryParcelStore())
+	})
+}
+
+// TestDeliveryAttemptsDisabled проверяет сервис без журнала попыток
+func TestDeliveryAttemptsDisabled(t *testing.T) {
+	service := NewParcelService(NewMemoryParcelStore())
+
+	_, err := service.RecordDeliveryAttempt(context.Background(), 1, AttemptNoAnswer, "")
+	require.ErrorIs(t, err, ErrNoAttemptStore)
+	_, err = service.GetAttempts(context.Background(), 1)
+	require.ErrorIs(t, err, ErrNoAttemptStore)
+}
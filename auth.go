@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelpb"
+)
+
+// Права API-ключей. Каждое следующее включает предыдущие: write позволяет
+// и читать, admin — всё.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// scopeLevels упорядочивает права для проверки «не ниже».
+var scopeLevels = map[string]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+// apiKeyPrefix отличает ключи трекера от других секретов, например в логах.
+const apiKeyPrefix = "pk_"
+
+var (
+	// ErrUnauthorized возвращается запросу без API-ключа или с неизвестным,
+	// отозванным ключом.
+	ErrUnauthorized = errors.New("missing or invalid api key")
+	// ErrForbidden возвращается, если прав ключа не хватает для операции.
+	ErrForbidden = errors.New("api key scope does not allow this operation")
+	// ErrAPIKeyNotFound возвращает RevokeAPIKey для неизвестного ключа.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey возвращается при выпуске ключа без имени или прав.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+)
+
+// AuthConfig включает проверку API-ключей в HTTP и gRPC API.
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// APIKey — выпущенный API-ключ. Сам ключ не хранится, только его SHA-256.
+type APIKey struct {
+	ID   int
+	Name string
+	// Tenant — арендатор, от имени которого работает ключ, см. WithTenant;
+	// пустой у операторских ключей с доступом ко всем арендаторам
+	Tenant    string
+	Scopes    []string
+	CreatedAt time.Time
+	// RevokedAt — время отзыва, нулевое у действующего ключа
+	RevokedAt time.Time
+}
+
+// Allows сообщает, хватает ли прав ключа для операции, требующей scope.
+func (k APIKey) Allows(scope string) bool {
+	for _, s := range k.Scopes {
+		if scopeLevels[s] >= scopeLevels[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyStore хранит API-ключи в таблице api_keys.
+type KeyStore interface {
+	// IssueAPIKey выпускает ключ с именем, арендатором и правами k и возвращает
+	// его вместе с секретом, который больше нигде не сохраняется
+	IssueAPIKey(ctx context.Context, k APIKey) (APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	// AuthenticateAPIKey возвращает действующий ключ по секрету или ErrUnauthorized
+	AuthenticateAPIKey(ctx context.Context, secret string) (APIKey, error)
+}
+
+var _ KeyStore = ParcelStore{}
+
+// hashAPIKey возвращает SHA-256 секрета. Секрет — 32 случайных байта,
+// поэтому соль и медленный хеш вроде bcrypt не нужны, а поиск по хешу
+// идёт по уникальному индексу.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKeySecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	for _, s := range scopes {
+		if _, ok := scopeLevels[s]; !ok || strings.Contains(s, ",") {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKey, s)
+		}
+	}
+	return nil
+}
+
+func (s ParcelStore) IssueAPIKey(ctx context.Context, k APIKey) (APIKey, string, error) {
+	name := strings.TrimSpace(k.Name)
+	if name == "" {
+		return APIKey{}, "", fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if err := validateScopes(k.Scopes); err != nil {
+		return APIKey{}, "", err
+	}
+
+	secret := newAPIKeySecret()
+	key := APIKey{Name: name, Tenant: strings.TrimSpace(k.Tenant), Scopes: slices.Clone(k.Scopes), CreatedAt: timestamp(s.now)}
+	id, err := s.insert(ctx, "INSERT INTO api_keys (name, tenant_id, key_hash, scopes, created_at) VALUES (?, ?, ?, ?, ?)", "id",
+		key.Name, key.Tenant, hashAPIKey(secret), strings.Join(key.Scopes, ","), s.dialect.timeArg(key.CreatedAt))
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	key.ID = id
+
+	return key, secret, nil
+}
+
+// RevokeAPIKey отзывает ключ; повторный отзыв ничего не меняет.
+func (s ParcelStore) RevokeAPIKey(ctx context.Context, id int) error {
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"),
+		s.timestampArg(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	var exists int
+	err = s.q.QueryRowContext(ctx, s.dialect.rebind("SELECT 1 FROM api_keys WHERE id = ?"), id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+// ListAPIKeys возвращает все ключи, включая отозванные, по возрастанию id.
+func (s ParcelStore) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.q.QueryContext(ctx,
+		"SELECT id, name, tenant_id, scopes, created_at, revoked_at FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, k)
+	}
+	return res, rows.Err()
+}
+
+func (s ParcelStore) AuthenticateAPIKey(ctx context.Context, secret string) (APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return APIKey{}, ErrUnauthorized
+	}
+
+	k, err := scanAPIKey(s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT id, name, tenant_id, scopes, created_at, revoked_at FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL"),
+		hashAPIKey(secret)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return APIKey{}, ErrUnauthorized
+	}
+	return k, err
+}
+
+func scanAPIKey(row interface{ Scan(...any) error }) (APIKey, error) {
+	var k APIKey
+	var scopes string
+	if err := row.Scan(&k.ID, &k.Name, &k.Tenant, &scopes, scanTime{&k.CreatedAt}, scanTime{&k.RevokedAt}); err != nil {
+		return APIKey{}, err
+	}
+	k.Scopes = strings.Split(scopes, ",")
+	return k, nil
+}
+
+// grpcScopes — права, нужные методам gRPC. Методы не из списка требуют admin,
+// чтобы новый метод нельзя было вызвать, забыв указать его права.
+var grpcScopes = map[string]string{
+	parcelpb.ParcelService_Add_FullMethodName:                 ScopeWrite,
+	parcelpb.ParcelService_Get_FullMethodName:                 ScopeRead,
+	parcelpb.ParcelService_GetByTrackCode_FullMethodName:      ScopeRead,
+	parcelpb.ParcelService_ListByClient_FullMethodName:        ScopeRead,
+	parcelpb.ParcelService_ListByClientStream_FullMethodName:  ScopeRead,
+	parcelpb.ParcelService_SetStatus_FullMethodName:           ScopeWrite,
+	parcelpb.ParcelService_StreamStatusUpdates_FullMethodName: ScopeWrite,
+	parcelpb.ParcelService_SetAddress_FullMethodName:          ScopeWrite,
+	parcelpb.ParcelService_MarkPaid_FullMethodName:            ScopeAdmin,
+	parcelpb.ParcelService_Delete_FullMethodName:              ScopeWrite,
+}
+
+// httpScope возвращает права, нужные запросу к REST API: чтение для GET,
+// admin для отметки об оплате, которую ставит только платёжная интеграция,
+// и write для остальных изменений. Для GraphQL хватает чтения: права
+// на мутации GraphQLServer проверяет сам, см. requireScope.
+func httpScope(r *http.Request) string {
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead, r.URL.Path == "/graphql":
+		return ScopeRead
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/payment"):
+		return ScopeAdmin
+	}
+	return ScopeWrite
+}
+
+// Authenticator проверяет API-ключи запросов к HTTP и gRPC API.
+type Authenticator struct {
+	keys KeyStore
+}
+
+func NewAuthenticator(keys KeyStore) *Authenticator {
+	return &Authenticator{keys: keys}
+}
+
+// authorize находит ключ по секрету и проверяет, что его прав хватает для scope.
+func (a *Authenticator) authorize(ctx context.Context, secret, scope string) (APIKey, error) {
+	if secret == "" {
+		return APIKey{}, ErrUnauthorized
+	}
+	key, err := a.keys.AuthenticateAPIKey(ctx, secret)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if !key.Allows(scope) {
+		return APIKey{}, ErrForbidden
+	}
+	return key, nil
+}
+
+// Middleware отвечает 401 на запросы без действующего ключа в заголовке
+// Authorization: Bearer <ключ> и 403, если прав ключа не хватает.
+// Остальные запросы выполняются от имени арендатора ключа.
+//
+// Браузерный WebSocket не умеет ставить заголовки, поэтому запрос
+// на открытие WebSocket без заголовка может передать ключ в параметре
+// access_token. Браузер, открывший админку на /admin, передаёт ключ
+// паролем HTTP Basic: на 401 он сам спрашивает его у пользователя.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin := r.URL.Path == "/admin" || strings.HasPrefix(r.URL.Path, "/admin/")
+		secret, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if secret == "" && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			secret = r.URL.Query().Get("access_token")
+		}
+		if _, password, ok := r.BasicAuth(); ok && admin {
+			secret = password
+		}
+
+		key, err := a.authorize(r.Context(), secret, httpScope(r))
+		switch {
+		case errors.Is(err, ErrUnauthorized) && admin:
+			w.Header().Set("WWW-Authenticate", `Basic realm="parcel admin", charset="UTF-8"`)
+			writeError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrUnauthorized):
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, err)
+		case errors.Is(err, ErrForbidden):
+			writeError(w, http.StatusForbidden, err)
+		case err != nil:
+			writeStoreError(w, err)
+		default:
+			ctx := context.WithValue(WithTenant(r.Context(), key.Tenant), apiKeyCtxKey{}, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+	})
+}
+
+// apiKeyCtxKey — ключ контекста, под которым Middleware сохраняет API-ключ запроса.
+type apiKeyCtxKey struct{}
+
+// requireScope возвращает ErrForbidden, если прав API-ключа запроса
+// не хватает для scope. Без ключа в контексте, то есть с выключенной
+// проверкой ключей, разрешено всё.
+func requireScope(ctx context.Context, scope string) error {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(APIKey)
+	if ok && !key.Allows(scope) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// UnaryInterceptor проверяет ключ из метаданных authorization: Bearer <ключ>
+// и возвращает Unauthenticated или PermissionDenied. Вызов выполняется
+// от имени арендатора ключа.
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authorizeGRPC(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor проверяет ключ при открытии потока, как UnaryInterceptor.
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorizeGRPC(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorizeGRPC проверяет ключ вызова method и возвращает контекст
+// с арендатором ключа или ошибку gRPC.
+func (a *Authenticator) authorizeGRPC(ctx context.Context, method string) (context.Context, error) {
+	var secret string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			secret, _ = strings.CutPrefix(v[0], "Bearer ")
+		}
+	}
+
+	scope, ok := grpcScopes[method]
+	if !ok {
+		scope = ScopeAdmin
+	}
+	key, err := a.authorize(ctx, secret, scope)
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, grpcError(err)
+	}
+	return WithTenant(ctx, key.Tenant), nil
+}
+
+// contextStream — поток gRPC с подменённым контекстом.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
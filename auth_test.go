@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelpb"
+)
+
+// TestAPIKeys проверяет выпуск, проверку и отзыв API-ключей
+func TestAPIKeys(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+
+	// add
+	key, secret, err := store.IssueAPIKey(ctx, APIKey{Name: "shop", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+	require.NotZero(t, key.ID)
+	require.Regexp(t, `^pk_[A-Za-z0-9_-]{43}$`, secret)
+
+	// check
+	got, err := store.AuthenticateAPIKey(ctx, secret)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, got.ID)
+	require.Equal(t, []string{ScopeWrite}, got.Scopes)
+	require.True(t, got.Allows(ScopeRead))
+	require.False(t, got.Allows(ScopeAdmin))
+
+	_, err = store.AuthenticateAPIKey(ctx, secret+"x")
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// в таблице хранится только хеш
+	var stored string
+	require.NoError(t, store.db.QueryRow("SELECT key_hash FROM api_keys WHERE id = ?", key.ID).Scan(&stored))
+	require.NotContains(t, stored, secret)
+
+	require.NoError(t, store.RevokeAPIKey(ctx, key.ID))
+	require.NoError(t, store.RevokeAPIKey(ctx, key.ID))
+	_, err = store.AuthenticateAPIKey(ctx, secret)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.ErrorIs(t, store.RevokeAPIKey(ctx, 999999), ErrAPIKeyNotFound)
+
+	keys, err := store.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.False(t, keys[0].RevokedAt.IsZero())
+
+	_, _, err = store.IssueAPIKey(ctx, APIKey{Name: "shop", Scopes: []string{"root"}})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+	_, _, err = store.IssueAPIKey(ctx, APIKey{Name: " ", Scopes: []string{ScopeRead}})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+// TestAuthMiddleware проверяет ответы 401 и 403 REST API
+func TestAuthMiddleware(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, reader, err := store.IssueAPIKey(ctx, APIKey{Name: "dashboard", Scopes: []string{ScopeRead}})
+	require.NoError(t, err)
+	_, writer, err := store.IssueAPIKey(ctx, APIKey{Name: "shop", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+
+	h := NewAuthenticator(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func(method, target, secret string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// check
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/parcels/1", ""))
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/parcels/1", "pk_unknown"))
+	require.Equal(t, http.StatusNoContent, do(http.MethodGet, "/parcels/1", reader))
+	require.Equal(t, http.StatusForbidden, do(http.MethodDelete, "/parcels/1", reader))
+	require.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/parcels/1", writer))
+	require.Equal(t, http.StatusForbidden, do(http.MethodPost, "/parcels/1/payment", writer))
+
+	// ключ в параметре принимается только при открытии WebSocket
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/ws?access_token="+reader, ""))
+	req := httptest.NewRequest(http.MethodGet, "/ws?access_token="+reader, nil)
+	req.Header.Set("Upgrade", "websocket")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+// TestAuthInterceptor проверяет коды Unauthenticated и PermissionDenied для gRPC
+func TestAuthInterceptor(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, reader, err := store.IssueAPIKey(ctx, APIKey{Name: "dashboard", Scopes: []string{ScopeRead}})
+	require.NoError(t, err)
+
+	interceptor := NewAuthenticator(store).UnaryInterceptor()
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	call := func(method, secret string) error {
+		ctx := ctx
+		if secret != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+secret))
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	// check
+	require.Equal(t, codes.Unauthenticated, status.Code(call(parcelpb.ParcelService_Get_FullMethodName, "")))
+	require.NoError(t, call(parcelpb.ParcelService_Get_FullMethodName, reader))
+	require.Equal(t, codes.PermissionDenied, status.Code(call(parcelpb.ParcelService_SetStatus_FullMethodName, reader)))
+	require.Equal(t, codes.PermissionDenied, status.Code(call("/parcel.v1.ParcelService/Unknown", reader)))
+}
+
+// TestAuthStreamInterceptor проверяет ключ и арендатора потоковых вызовов gRPC
+func TestAuthStreamInterceptor(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, reader, err := store.IssueAPIKey(ctx, APIKey{Name: "shop", Tenant: "acme", Scopes: []string{ScopeRead}})
+	require.NoError(t, err)
+
+	interceptor := NewAuthenticator(store).StreamInterceptor()
+	call := func(method, secret string) error {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+secret))
+		return interceptor(nil, testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: method},
+			func(_ any, ss grpc.ServerStream) error {
+				require.Equal(t, "acme", TenantFromContext(ss.Context()))
+				return nil
+			})
+	}
+
+	// check
+	require.Equal(t, codes.Unauthenticated, status.Code(call(parcelpb.ParcelService_ListByClientStream_FullMethodName, "wrong")))
+	require.NoError(t, call(parcelpb.ParcelService_ListByClientStream_FullMethodName, reader))
+	require.Equal(t, codes.PermissionDenied, status.Code(call(parcelpb.ParcelService_StreamStatusUpdates_FullMethodName, reader)))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+)
+
+// Backuper снимает и восстанавливает резервные копии базы без остановки сервиса.
+type Backuper interface {
+	Backup(ctx context.Context, path string) error
+	RestoreBackup(ctx context.Context, path string) error
+}
+
+var _ Backuper = ParcelStore{}
+
+// sqliteBackuper — соединение драйвера modernc.org/sqlite с online backup API.
+type sqliteBackuper interface {
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// Backup сохраняет согласованный снимок базы SQLite в новый файл path через
+// VACUUM INTO. Чтение и запись в базу во время копирования не блокируются,
+// а копия получается без пустых страниц. Файл path не должен существовать.
+func (s ParcelStore) Backup(ctx context.Context, path string) error {
+	if s.dialect.name != "sqlite" {
+		return fmt.Errorf("backup: not supported for %s", s.dialect.name)
+	}
+
+	db, err := s.sqlDB()
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("backup to %s: %w", path, err)
+	}
+	return nil
+}
+
+// RestoreBackup заменяет содержимое базы копией из path, снятой Backup,
+// и применяет миграции, которых в копии ещё нет. Копирование идёт через
+// online backup API SQLite, поэтому другие соединения пула видят
+// восстановленные данные сразу. Внутри WithTx вызывать нельзя.
+func (s ParcelStore) RestoreBackup(ctx context.Context, path string) error {
+	if s.dialect.name != "sqlite" {
+		return fmt.Errorf("restore backup: not supported for %s", s.dialect.name)
+	}
+	if err := checkBackup(ctx, path); err != nil {
+		return err
+	}
+	db, err := s.sqlDB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(sqliteBackuper)
+		if !ok {
+			return errors.New("sqlite driver does not support backup")
+		}
+
+		b, err := c.NewRestore(path)
+		if err != nil {
+			return err
+		}
+		_, err = b.Step(-1)
+		return errors.Join(err, b.Finish())
+	})
+	if err != nil {
+		return fmt.Errorf("restore backup from %s: %w", path, err)
+	}
+
+	return s.Migrate(ctx)
+}
+
+// checkBackup проверяет, что path — целая база трекера: повреждённый
+// или чужой файл не должен затереть рабочие данные.
+func checkBackup(ctx context.Context, path string) error {
+	backup, err := OpenSQLite("file:"+path+"?mode=ro", WithJournalMode(""))
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	var result string
+	if err := backup.db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&result); err != nil {
+		return fmt.Errorf("check backup %s: %w", path, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("check backup %s: %s", path, result)
+	}
+
+	if _, err := backup.SchemaVersion(ctx); err != nil {
+		return fmt.Errorf("check backup %s: not a tracker database: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackupRestore проверяет снимок базы и восстановление из него
+func TestBackupRestore(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	path := filepath.Join(t.TempDir(), "backup.db")
+
+	kept, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// backup
+	require.NoError(t, store.Backup(ctx, path))
+	require.Error(t, store.Backup(ctx, path))
+
+	lost, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, kept, ParcelStatusSent))
+
+	// restore
+	require.NoError(t, store.RestoreBackup(ctx, path))
+
+	// check
+	p, err := store.Get(ctx, kept)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+
+	_, err = store.Get(ctx, lost)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	history, err := store.GetHistory(ctx, kept)
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
+
+// TestRestoreBackupInvalid проверяет, что негодный файл не затирает базу
+func TestRestoreBackupInvalid(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	garbage := filepath.Join(t.TempDir(), "garbage.db")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a database"), 0o600))
+
+	// check
+	require.Error(t, store.RestoreBackup(ctx, garbage))
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	require.Error(t, store.RestoreBackup(ctx, missing))
+	require.NoFileExists(t, missing)
+
+	_, err = store.Get(ctx, id)
+	require.NoError(t, err)
+}
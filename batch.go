@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AddBatch добавляет посылки в одной транзакции, переиспользуя
+// подготовленный запрос, и возвращает их номера в порядке parcels.
+// При ошибке на любой посылке не добавляется ни одна. Ключ идемпотентности,
+// см. WithIdempotencyKey, пачка не поддерживает — возвращается ErrIdempotentBatch.
+func (s ParcelStore) AddBatch(ctx context.Context, parcels []Parcel) ([]int, error) {
+	if err := checkBatchIdempotency(ctx); err != nil {
+		return nil, err
+	}
+	if len(parcels) == 0 {
+		return nil, nil
+	}
+
+	numbers := make([]int, 0, len(parcels))
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		query := insertParcelQuery
+		if s.dialect.returning {
+			query += " RETURNING number"
+		}
+		stmt, err := tx.q.PrepareContext(ctx, s.dialect.rebind(query))
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, p := range parcels {
+			if p, err = prepareParcel(s.addresses, p); err != nil {
+				return err
+			}
+			if p, err = s.sealParcel(p); err != nil {
+				return err
+			}
+			id, err := s.addWithStmt(ctx, stmt, p)
+			if err != nil {
+				return clientError(err)
+			}
+			numbers = append(numbers, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return numbers, nil
+}
+
+func (s ParcelStore) addWithStmt(ctx context.Context, stmt *sql.Stmt, p Parcel) (int, error) {
+	args := s.insertParcelArgs(ctx, p)
+
+	if s.dialect.returning {
+		var id int
+		err := stmt.QueryRowContext(ctx, args...).Scan(&id)
+		return id, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// AddBatch добавляет посылки и возвращает их номера в порядке parcels.
+func (s *MemoryParcelStore) AddBatch(ctx context.Context, parcels []Parcel) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := checkBatchIdempotency(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// адреса проверяются заранее, чтобы при отказе не добавить ни одной посылки
+	parcels = append([]Parcel(nil), parcels...)
+	for i := range parcels {
+		p, err := prepareParcel(s.addresses, parcels[i])
+		if err != nil {
+			return nil, err
+		}
+		parcels[i] = p
+	}
+
+	numbers := make([]int, 0, len(parcels))
+	for _, p := range parcels {
+		numbers = append(numbers, s.insert(p, TenantFromContext(ctx)))
+	}
+
+	return numbers, nil
+}
+
+// StatusResult — итог смены статуса одной посылки в SetStatusBatch.
+// Err равна nil, если статус изменён, иначе ErrParcelNotFound
+// или ErrInvalidTransition.
+type StatusResult struct {
+	Number int
+	Err    error
+}
+
+// SetStatusBatch переводит посылки в статус status в одной транзакции
+// и возвращает результат для каждого номера в порядке numbers.
+// Отказ по отдельной посылке не отменяет смену статуса остальных;
+// ошибка возвращается только при сбое БД, и тогда не меняется ничего.
+func (s ParcelStore) SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error) {
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+
+	var results []StatusResult
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		current, err := tx.statuses(ctx, numbers)
+		if err != nil {
+			return err
+		}
+
+		results = make([]StatusResult, 0, len(numbers))
+		var changes []StatusChange
+		changed := make(map[int]bool, len(numbers))
+		changedAt := formatTime(s.now())
+		for _, number := range numbers {
+			old, ok := current[number]
+			switch {
+			case !ok:
+				err = ErrParcelNotFound
+			default:
+				err = tx.transitions.Validate(old, status)
+			}
+			if err == nil {
+				if err = tx.checkPaid(ctx, number, status); err != nil && !errors.Is(err, ErrPaymentRequired) {
+					return err
+				}
+			}
+			results = append(results, StatusResult{Number: number, Err: err})
+			if err != nil || changed[number] {
+				continue
+			}
+
+			// повтор номера в numbers проверяется уже от нового статуса
+			// и не пишет в историю второй раз
+			current[number] = status
+			changed[number] = true
+			changes = append(changes, StatusChange{Number: number, OldStatus: old, NewStatus: status, ChangedAt: changedAt})
+		}
+
+		for start := 0; start < len(changes); start += inBatchSize {
+			if err := tx.applyStatusChanges(ctx, changes[start:min(start+inBatchSize, len(changes))]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// statuses возвращает текущие статусы существующих посылок из numbers.
+func (s ParcelStore) statuses(ctx context.Context, numbers []int) (map[int]Status, error) {
+	res := make(map[int]Status, len(numbers))
+	for start := 0; start < len(numbers); start += inBatchSize {
+		batch := numbers[start:min(start+inBatchSize, len(numbers))]
+
+		args := make([]any, 0, len(batch))
+		for _, number := range batch {
+			args = append(args, number)
+		}
+
+		cond, tenantArgs := tenantCond(ctx)
+		rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+			"SELECT number, status FROM parcel WHERE number IN ("+placeholders(len(batch))+") AND deleted_at IS NULL"+cond),
+			append(args, tenantArgs...)...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var number int
+			var status Status
+			if err := rows.Scan(&number, &status); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			res[number] = status
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// applyStatusChanges записывает новый статус одним UPDATE и историю одним INSERT.
+// Все изменения в changes должны вести в один и тот же статус.
+func (s ParcelStore) applyStatusChanges(ctx context.Context, changes []StatusChange) error {
+	numbers := make([]any, 0, len(changes))
+	seen := make(map[int]bool, len(changes))
+	history := make([]any, 0, 4*len(changes))
+	for _, c := range changes {
+		if !seen[c.Number] {
+			seen[c.Number] = true
+			numbers = append(numbers, c.Number)
+		}
+		history = append(history, c.Number, c.OldStatus, c.NewStatus, c.ChangedAt)
+	}
+
+	cond, tenantArgs := tenantCond(ctx)
+	args := append([]any{changes[0].NewStatus, s.timestampArg()}, numbers...)
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET status = ?, version = version + 1, updated_at = ? WHERE number IN ("+placeholders(len(numbers))+")"+cond),
+		append(args, tenantArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(changes)), ", ")
+	_, err = s.q.ExecContext(ctx, s.dialect.rebind(
+		"INSERT INTO parcel_status_history (parcel_number, old_status, new_status, changed_at) VALUES "+values),
+		history...)
+	if err != nil {
+		return fmt.Errorf("add status history: %w", err)
+	}
+
+	return nil
+}
+
+// SetStatusBatch переводит посылки в статус status и возвращает результат
+// для каждого номера в порядке numbers.
+func (s *MemoryParcelStore) SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]StatusResult, 0, len(numbers))
+	changed := make(map[int]bool, len(numbers))
+	changedAt := formatTime(s.now())
+	for _, number := range numbers {
+		p, ok := s.parcel(ctx, number)
+		var err error
+		switch {
+		case !ok:
+			err = ErrParcelNotFound
+		default:
+			err = s.transitions.Validate(p.Status, status)
+		}
+		if err == nil {
+			err = checkPayment(s.paymentRequired, p, status)
+		}
+		results = append(results, StatusResult{Number: number, Err: err})
+		if err != nil || changed[number] {
+			continue
+		}
+
+		changed[number] = true
+		s.history[number] = append(s.history[number], StatusChange{
+			Number:    number,
+			OldStatus: p.Status,
+			NewStatus: status,
+			ChangedAt: changedAt,
+		})
+		p.Status = status
+		p.Version++
+		p.UpdatedAt = timestamp(s.now)
+		s.parcels[number] = p
+	}
+
+	return results, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddBatch проверяет пакетное добавление посылок
+func TestAddBatch(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+
+	parcels := []Parcel{getTestParcel(), getTestParcel(), getTestParcel()}
+	client := randRange.Intn(10_000_000)
+	for i := range parcels {
+		parcels[i].Client = client
+		parcels[i].RecipientAddress = fmt.Sprintf("batch %d", i)
+	}
+
+	// add
+	numbers, err := store.AddBatch(ctx, parcels)
+	require.NoError(t, err)
+	require.Len(t, numbers, len(parcels))
+
+	// check
+	for i, number := range numbers {
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, parcels[i].RecipientAddress, stored.RecipientAddress)
+	}
+}
+
+// TestMemoryAddBatch проверяет, что пакет в памяти заполняет служебные поля так же, как Add
+func TestMemoryAddBatch(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+
+	parcel := getTestParcel()
+	parcel.ETA = time.Now().Add(time.Hour)
+	parcel.SLABreachedAt = time.Now()
+
+	// add
+	numbers, err := store.AddBatch(ctx, []Parcel{parcel, parcel})
+	require.NoError(t, err)
+	require.Len(t, numbers, 2)
+
+	// check
+	for _, number := range numbers {
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, number, stored.Number)
+		require.Equal(t, 1, stored.Version)
+		require.Zero(t, stored.ETA)
+		require.Zero(t, stored.SLABreachedAt)
+		require.NotEmpty(t, stored.TrackCode)
+	}
+}
+
+// checkSetStatusBatch проверяет результаты пакетной смены статуса
+func checkSetStatusBatch(t *testing.T, store interface {
+	ParcelStorage
+	SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error)
+}) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	registered, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	rejected, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, rejected, ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, rejected, ParcelStatusDelivered))
+
+	// set status
+	numbers := []int{registered, -1, rejected, registered}
+	results, err := store.SetStatusBatch(ctx, numbers, ParcelStatusSent)
+	require.NoError(t, err)
+	require.Len(t, results, len(numbers))
+
+	// check
+	require.Equal(t, StatusResult{Number: registered}, results[0])
+	require.ErrorIs(t, results[1].Err, ErrParcelNotFound)
+	require.ErrorIs(t, results[2].Err, ErrInvalidTransition)
+	require.ErrorIs(t, results[3].Err, ErrInvalidTransition)
+
+	stored, err := store.Get(ctx, registered)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+
+	history, err := store.GetHistory(ctx, registered)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	stored, err = store.Get(ctx, rejected)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusDelivered, stored.Status)
+}
+
+// checkSetStatusBatchDuplicates проверяет, что повтор номера в пакете
+// пишет в историю одну запись даже при разрешённом переходе в тот же статус
+func checkSetStatusBatchDuplicates(t *testing.T, store interface {
+	ParcelStorage
+	SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error)
+}) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	number, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, number, ParcelStatusSent))
+
+	// set status
+	results, err := store.SetStatusBatch(ctx, []int{number, number}, ParcelStatusSent)
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, []StatusResult{{Number: number}, {Number: number}}, results)
+
+	history, err := store.GetHistory(ctx, number)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+}
+
+// TestSetStatusBatch проверяет пакетную смену статуса в SQLite
+func TestSetStatusBatch(t *testing.T) {
+	checkSetStatusBatch(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemorySetStatusBatch проверяет пакетную смену статуса в памяти
+func TestMemorySetStatusBatch(t *testing.T) {
+	checkSetStatusBatch(t, NewMemoryParcelStore())
+}
+
+// TestSetStatusBatchDuplicates проверяет повторы номеров в пакете в SQLite и в памяти
+func TestSetStatusBatchDuplicates(t *testing.T) {
+	transitions := DefaultStatusTransitions().With(ParcelStatusSent, ParcelStatusSent)
+
+	checkSetStatusBatchDuplicates(t, NewParcelStore(openTempDB(t)).WithTransitions(transitions))
+
+	memory := NewMemoryParcelStore()
+	memory.SetTransitions(transitions)
+	checkSetStatusBatchDuplicates(t, memory)
+}
+
+const benchBatchSize = 1000
+
+func BenchmarkAddLoop(b *testing.B) {
+	store := NewParcelStore(openTempDB(b))
+	ctx := context.Background()
+	parcel := getTestParcel()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < benchBatchSize; j++ {
+			_, err := store.Add(ctx, parcel)
+			require.NoError(b, err)
+		}
+	}
+}
+
+func BenchmarkAddBatch(b *testing.B) {
+	store := NewParcelStore(openTempDB(b))
+	ctx := context.Background()
+
+	parcels := make([]Parcel, benchBatchSize)
+	for i := range parcels {
+		parcels[i] = getTestParcel()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := store.AddBatch(ctx, parcels)
+		require.NoError(b, err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// benchParcelsPerClient — сколько посылок у каждого клиента в заполненной БД
+const benchParcelsPerClient = 10
+
+// seedParcels заполняет store посылками rows штук, по benchParcelsPerClient
+// на клиента с номерами от 1, и возвращает число клиентов
+func seedParcels(b *testing.B, store ParcelStore, rows int) int {
+	ctx := context.Background()
+	clients := max(rows/benchParcelsPerClient, 1)
+
+	parcels := make([]Parcel, 0, benchBatchSize)
+	for i := 0; i < rows; i++ {
+		parcel := getTestParcel()
+		parcel.Client = i%clients + 1
+		parcels = append(parcels, parcel)
+		if len(parcels) == benchBatchSize || i == rows-1 {
+			_, err := store.AddBatch(ctx, parcels)
+			require.NoError(b, err)
+			parcels = parcels[:0]
+		}
+	}
+	return clients
+}
+
+func BenchmarkAdd(b *testing.B) {
+	store := NewParcelStore(openTempDB(b))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.Add(ctx, getTestParcel()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetByClient(b *testing.B) {
+	ctx := context.Background()
+	for _, rows := range []int{10_000, 100_000} {
+		store := NewParcelStore(openTempDB(b))
+		clients := seedParcels(b, store, rows)
+
+		b.Run("rows="+strconv.Itoa(rows), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				parcels, err := store.GetByClient(ctx, i%clients+1)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(parcels) != benchParcelsPerClient {
+					b.Fatalf("got %d parcels, want %d", len(parcels), benchParcelsPerClient)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMixed — параллельная нагрузка на 10k посылок: из каждых
+// десяти операций восемь чтений по клиенту и номеру и две записи
+func BenchmarkMixed(b *testing.B) {
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(b))
+	clients := seedParcels(b, store, 10_000)
+	var op atomic.Int64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := int(op.Add(1))
+			client := n%clients + 1
+			var err error
+			switch n % 10 {
+			case 0:
+				parcel := getTestParcel()
+				parcel.Client = client
+				_, err = store.Add(ctx, parcel)
+			case 1:
+				err = store.SetRecipientAddress(ctx, n%10_000+1, "address "+strconv.Itoa(n))
+			case 2, 3, 4:
+				_, err = store.Get(ctx, n%10_000+1)
+			default:
+				_, err = store.GetByClient(ctx, client)
+			}
+			if err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound возвращает BlobStore.Open для ключа, которого нет.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore хранит содержимое вложений по ключам. Ключи выдаёт
+// Attachments: это пути из латинских букв, цифр, «-» и «/».
+// Реализация для объектного хранилища должна вести себя так же,
+// как FileBlobStore: Put перезаписывает ключ целиком или не меняет его.
+type BlobStore interface {
+	// Put сохраняет содержимое r под ключом key и возвращает его размер
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open открывает содержимое ключа key, для ключа без содержимого — ErrBlobNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет ключ key; ключа без содержимого не считается ошибкой
+	Delete(ctx context.Context, key string) error
+}
+
+// FileBlobStore хранит содержимое в файлах каталога dir.
+type FileBlobStore struct {
+	dir string
+}
+
+var _ BlobStore = FileBlobStore{}
+
+func NewFileBlobStore(dir string) FileBlobStore {
+	return FileBlobStore{dir: dir}
+}
+
+// path возвращает файл ключа key и не выпускает ключ за пределы dir.
+func (s FileBlobStore) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(key) || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put пишет содержимое во временный файл и переименовывает его,
+// поэтому оборванная запись не оставляет под ключом половину файла.
+func (s FileBlobStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, contextReader{ctx: ctx, r: r})
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return n, os.Rename(f.Name(), path)
+}
+
+func (s FileBlobStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	return f, err
+}
+
+func (s FileBlobStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// contextReader прерывает чтение r после отмены ctx.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
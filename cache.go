@@ -0,0 +1,202 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheConfig включает кеш посылок для Get: Size посылок не дольше TTL.
+// Нулевой Size выключает кеш, нулевой TTL хранит посылку, пока её
+// не вытеснят или не изменят.
+type CacheConfig struct {
+	Size int           `yaml:"size"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+func (c CacheConfig) enabled() bool {
+	return c.Size > 0
+}
+
+func (c CacheConfig) validate() error {
+	if c.Size < 0 || c.TTL < 0 {
+		return errors.New("config: cache size and ttl must not be negative")
+	}
+	return nil
+}
+
+// CachedStorage кеширует результаты Get в LRU-кеше. Изменения посылки через
+// это же хранилище сразу вытесняют её из кеша; изменения из других процессов
+// видны не позже TTL. Остальные методы обращаются к хранилищу напрямую.
+// Счётчик parcel_cache_requests_total с результатом hit или miss показывает
+// долю попаданий.
+type CachedStorage struct {
+	ParcelStorage
+
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	lru     *list.List // *cacheEntry, недавно прочитанные в начале
+	entries map[int]*list.Element
+	// generation растёт при каждом вытеснении: Get, начатый до изменения,
+	// не кладёт в кеш прочитанную до него посылку
+	generation uint64
+
+	requests *prometheus.CounterVec
+}
+
+var _ ParcelStorage = (*CachedStorage)(nil)
+
+type cacheEntry struct {
+	tenant  string
+	parcel  Parcel
+	expires time.Time
+}
+
+// NewCachedStorage оборачивает хранилище кешем и регистрирует его метрики в reg.
+func NewCachedStorage(next ParcelStorage, cfg CacheConfig, reg prometheus.Registerer) (*CachedStorage, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	s := &CachedStorage{
+		ParcelStorage: next,
+		size:          max(cfg.Size, 1),
+		ttl:           cfg.TTL,
+		lru:           list.New(),
+		entries:       map[int]*list.Element{},
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parcel_cache_requests_total",
+			Help: "Number of parcel cache lookups by result.",
+		}, []string{"result"}),
+	}
+	if err := reg.Register(s.requests); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get возвращает посылку из кеша, а при промахе читает её из хранилища
+// и запоминает. Посылка другого арендатора в кеше считается промахом.
+func (s *CachedStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	tenant := TenantFromContext(ctx)
+
+	s.mu.Lock()
+	if el, ok := s.entries[number]; ok {
+		e := el.Value.(*cacheEntry)
+		if e.tenant == tenant && (e.expires.IsZero() || time.Now().Before(e.expires)) {
+			s.lru.MoveToFront(el)
+			s.mu.Unlock()
+			s.requests.WithLabelValues("hit").Inc()
+			return e.parcel, nil
+		}
+	}
+	generation := s.generation
+	s.mu.Unlock()
+	s.requests.WithLabelValues("miss").Inc()
+
+	p, err := s.ParcelStorage.Get(ctx, number)
+	if err != nil {
+		return p, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation == generation {
+		s.put(tenant, p)
+	}
+	return p, nil
+}
+
+// put запоминает посылку p, вытесняя давно не читанные сверх size.
+// Вызывается под s.mu.
+func (s *CachedStorage) put(tenant string, p Parcel) {
+	e := &cacheEntry{tenant: tenant, parcel: p}
+	if s.ttl > 0 {
+		e.expires = time.Now().Add(s.ttl)
+	}
+	if el, ok := s.entries[p.Number]; ok {
+		el.Value = e
+		s.lru.MoveToFront(el)
+		return
+	}
+
+	s.entries[p.Number] = s.lru.PushFront(e)
+	for s.lru.Len() > s.size {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*cacheEntry).parcel.Number)
+	}
+}
+
+// invalidate вытесняет посылку number после её изменения.
+func (s *CachedStorage) invalidate(number int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	if el, ok := s.entries[number]; ok {
+		s.lru.Remove(el)
+		delete(s.entries, number)
+	}
+}
+
+func (s *CachedStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.SetStatus(ctx, number, status)
+}
+
+func (s *CachedStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.SetSenderAddress(ctx, number, address)
+}
+
+func (s *CachedStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.SetRecipientAddress(ctx, number, address)
+}
+
+func (s *CachedStorage) Update(ctx context.Context, p Parcel) error {
+	defer s.invalidate(p.Number)
+	return s.ParcelStorage.Update(ctx, p)
+}
+
+func (s *CachedStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.SetETA(ctx, number, eta)
+}
+
+func (s *CachedStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.MarkPaid(ctx, number, txRef)
+}
+
+func (s *CachedStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.Cancel(ctx, number, reason)
+}
+
+func (s *CachedStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.Deliver(ctx, number, sig)
+}
+
+func (s *CachedStorage) Delete(ctx context.Context, number int) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.Delete(ctx, number)
+}
+
+func (s *CachedStorage) HardDelete(ctx context.Context, number int) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.HardDelete(ctx, number)
+}
+
+func (s *CachedStorage) Restore(ctx context.Context, number int) error {
+	defer s.invalidate(number)
+	return s.ParcelStorage.Restore(ctx, number)
+}
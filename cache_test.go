@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCachedStorage проверяет попадания в кеш, вытеснение при изменении
+// посылки, LRU и счётчики попаданий
+func TestCachedStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	store, err := NewCachedStorage(NewMemoryParcelStore(), CacheConfig{Size: 2}, reg)
+	require.NoError(t, err)
+
+	var numbers []int
+	for range 3 {
+		id, err := store.Add(ctx, getTestParcel())
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	hits := func() float64 { return testutil.ToFloat64(store.requests.WithLabelValues("hit")) }
+	misses := func() float64 { return testutil.ToFloat64(store.requests.WithLabelValues("miss")) }
+
+	// check
+	_, err = store.Get(ctx, numbers[0])
+	require.NoError(t, err)
+	p, err := store.Get(ctx, numbers[0])
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+	require.Equal(t, 1.0, hits())
+	require.Equal(t, 1.0, misses())
+
+	// изменение вытесняет посылку, и следующий Get видит новое значение
+	require.NoError(t, store.SetStatus(ctx, numbers[0], ParcelStatusSent))
+	p, err = store.Get(ctx, numbers[0])
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, p.Status)
+	require.Equal(t, 2.0, misses())
+
+	// третья посылка вытесняет давно не читанную numbers[0]
+	_, err = store.Get(ctx, numbers[1])
+	require.NoError(t, err)
+	_, err = store.Get(ctx, numbers[2])
+	require.NoError(t, err)
+	_, err = store.Get(ctx, numbers[0])
+	require.NoError(t, err)
+	require.Equal(t, 5.0, misses())
+	_, err = store.Get(ctx, numbers[2])
+	require.NoError(t, err)
+	require.Equal(t, 2.0, hits())
+
+	// удалённая посылка не возвращается из кеша
+	require.NoError(t, store.Delete(ctx, numbers[2]))
+	_, err = store.Get(ctx, numbers[2])
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// посылка, закешированная для одного арендатора, другому не достаётся
+	_, err = store.Get(WithTenant(ctx, "shop"), numbers[0])
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	require.Equal(t, 2.0, hits())
+
+	_, err = NewCachedStorage(NewMemoryParcelStore(), CacheConfig{Size: 2}, reg)
+	require.Error(t, err)
+	_, err = NewCachedStorage(NewMemoryParcelStore(), CacheConfig{Size: -1}, prometheus.NewRegistry())
+	require.Error(t, err)
+}
+
+// TestCachedStorageTTL проверяет, что устаревшая посылка перечитывается из хранилища
+func TestCachedStorageTTL(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	backend := NewMemoryParcelStore()
+	store, err := NewCachedStorage(backend, CacheConfig{Size: 10, TTL: 20 * time.Millisecond}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	_, err = store.Get(ctx, id)
+	require.NoError(t, err)
+
+	// изменение в обход кеша, как из другого процесса
+	require.NoError(t, backend.SetStatus(ctx, id, ParcelStatusSent))
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+
+	time.Sleep(30 * time.Millisecond)
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, p.Status)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// CancelReason — код причины отмены посылки.
+type CancelReason string
+
+const (
+	CancelCustomerRequest CancelReason = "customer_request"
+	CancelDuplicate       CancelReason = "duplicate"
+	CancelInvalidAddress  CancelReason = "invalid_address"
+	CancelPaymentFailed   CancelReason = "payment_failed"
+	CancelOther           CancelReason = "other"
+)
+
+// cancelReasons — известные коды причин отмены.
+var cancelReasons = []CancelReason{
+	CancelCustomerRequest, CancelDuplicate, CancelInvalidAddress, CancelPaymentFailed, CancelOther,
+}
+
+// ErrInvalidCancelReason возвращается для причины отмены не из cancelReasons.
+var ErrInvalidCancelReason = errors.New("invalid cancel reason")
+
+func (r CancelReason) validate() error {
+	for _, known := range cancelReasons {
+		if r == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidCancelReason, r)
+}
+
+// Cancel отменяет зарегистрированную посылку с причиной reason. В отличие
+// от Delete посылка остаётся в выборках со статусом cancelled, причиной
+// и временем отмены, например для отчётов и возврата оплаты.
+func (s ParcelStore) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	if err := reason.validate(); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		// отменить можно только если значение статуса registered
+		cond, args := tenantCond(ctx)
+		now := tx.timestampArg()
+		res, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET status = ?, cancel_reason = ?, cancelled_at = ?, version = version + 1, updated_at = ?"+
+				" WHERE number = ? AND status = ? AND deleted_at IS NULL"+cond),
+			append([]any{ParcelStatusCancelled, reason, now, now, number, ParcelStatusRegistered}, args...)...)
+		if err != nil {
+			return err
+		}
+		if err := tx.checkAffected(ctx, res, number, isRegistered, ErrCancelNotAllowed); err != nil {
+			return err
+		}
+
+		return tx.addHistory(ctx, StatusChange{
+			Number:    number,
+			OldStatus: ParcelStatusRegistered,
+			NewStatus: ParcelStatusCancelled,
+			ChangedAt: formatTime(s.now()),
+		})
+	})
+}
+
+func (s *MemoryParcelStore) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := reason.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if p.Status != ParcelStatusRegistered {
+		return ErrCancelNotAllowed
+	}
+	s.history[number] = append(s.history[number], StatusChange{
+		Number:    number,
+		OldStatus: p.Status,
+		NewStatus: ParcelStatusCancelled,
+		ChangedAt: formatTime(s.now()),
+	})
+	p.Status = ParcelStatusCancelled
+	p.CancelReason = reason
+	p.Version++
+	p.UpdatedAt = timestamp(s.now)
+	p.CancelledAt = p.UpdatedAt
+	s.parcels[number] = p
+
+	return nil
+}
+
+func (s OutboxStore) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.Cancel(ctx, number, reason)
+	})
+}
+
+// Cancel отменяет посылку, пока она не отправлена, иначе возвращает
+// ErrCancelNotAllowed. Отмена публикуется как смена статуса.
+func (s ParcelService) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	old, err := s.store.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if old.Status != ParcelStatusRegistered {
+		return ErrCancelNotAllowed
+	}
+
+	if err := s.store.Cancel(ctx, number, reason); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "parcel cancelled",
+		slog.Int("number", number), slog.Int("client", old.Client), slog.String("reason", string(reason)))
+	s.changed(ctx, old)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestHTTPCancel проверяет отмену посылки через REST API
+func TestHTTPCancel(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+	rec := doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 7, "recipient_address": "test"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	number := strconv.Itoa(created.Number)
+
+	// cancel
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/cancel", `{"reason": "changed_mind"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/cancel", `{"reason": "duplicate"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/cancel", `{"reason": "duplicate"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	// check
+	rec = doRequest(t, srv, http.MethodGet, "/parcels/"+number, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stored parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stored))
+	require.Equal(t, ParcelStatusCancelled.String(), stored.Status)
+	require.Equal(t, "отменена", ParcelStatusCancelled.DisplayName(LangRU))
+	require.Equal(t, string(CancelDuplicate), stored.CancelReason)
+	require.NotEmpty(t, stored.CancelledAt)
+
+	rec = doRequest(t, srv, http.MethodGet, "/parcels?status=cancelled", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page parcelCursorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Parcels, 1)
+}
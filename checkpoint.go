@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Ошибки пунктов маршрута.
+var (
+	ErrLocationNotFound = errors.New("location not found")
+	ErrInvalidLocation  = errors.New("location code, name and known kind are required")
+)
+
+// Виды пунктов, через которые проходит посылка.
+const (
+	LocationWarehouse = "warehouse"
+	LocationHub       = "hub"
+	LocationPickup    = "pickup"
+)
+
+// Location — склад, сортировочный центр или пункт выдачи.
+// Code — короткий уникальный код пункта, например MSK-HUB-1.
+type Location struct {
+	ID   int
+	Code string
+	Name string
+	Kind string
+}
+
+// validate проверяет обязательные поля пункта.
+func (l Location) validate() error {
+	if l.Code == "" || l.Name == "" {
+		return ErrInvalidLocation
+	}
+	switch l.Kind {
+	case LocationWarehouse, LocationHub, LocationPickup:
+		return nil
+	}
+	return ErrInvalidLocation
+}
+
+// Checkpoint — отметка о прохождении посылкой пункта Location.
+type Checkpoint struct {
+	ID        int
+	Number    int
+	Location  Location
+	ArrivedAt time.Time
+}
+
+// RouteStore записывает маршрут посылки по складам и сортировочным центрам.
+type RouteStore interface {
+	AddLocation(ctx context.Context, l Location) (int, error)
+	GetLocation(ctx context.Context, code string) (Location, error)
+	AddCheckpoint(ctx context.Context, number, locationID int, arrivedAt time.Time) (int, error)
+	GetRoute(ctx context.Context, number int) ([]Checkpoint, error)
+}
+
+var _ RouteStore = ParcelStore{}
+
+// AddLocation добавляет пункт и возвращает его идентификатор.
+func (s ParcelStore) AddLocation(ctx context.Context, l Location) (int, error) {
+	if err := l.validate(); err != nil {
+		return 0, err
+	}
+	return s.insert(ctx, "INSERT INTO locations (code, name, kind) VALUES (?, ?, ?)", "id", l.Code, l.Name, l.Kind)
+}
+
+// GetLocation возвращает пункт по коду.
+func (s ParcelStore) GetLocation(ctx context.Context, code string) (Location, error) {
+	l := Location{}
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT id, code, name, kind FROM locations WHERE code = ?"),
+		code).Scan(&l.ID, &l.Code, &l.Name, &l.Kind)
+	if errors.Is(err, sql.ErrNoRows) {
+		return l, ErrLocationNotFound
+	}
+
+	return l, err
+}
+
+// AddCheckpoint отмечает, что посылка прибыла в пункт locationID
+// в момент arrivedAt, и возвращает идентификатор отметки.
+func (s ParcelStore) AddCheckpoint(ctx context.Context, number, locationID int, arrivedAt time.Time) (int, error) {
+	var id int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		if _, err := tx.status(ctx, number); err != nil {
+			return err
+		}
+
+		var found int
+		err := tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+			"SELECT id FROM locations WHERE id = ?"), locationID).Scan(&found)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrLocationNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		id, err = tx.insert(ctx,
+			"INSERT INTO parcel_checkpoint (parcel_number, location_id, arrived_at) VALUES (?, ?, ?)",
+			"id", number, locationID, tx.dialect.timeArg(arrivedAt.Truncate(time.Second)))
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetRoute возвращает пройденные посылкой пункты в порядке прибытия.
+func (s ParcelStore) GetRoute(ctx context.Context, number int) ([]Checkpoint, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT c.id, c.parcel_number, c.arrived_at, l.id, l.code, l.name, l.kind"+
+			" FROM parcel_checkpoint c JOIN locations l ON l.id = c.location_id"+
+			" WHERE c.parcel_number = ? ORDER BY c.arrived_at, c.id"),
+		number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Checkpoint
+	for rows.Next() {
+		c := Checkpoint{}
+		err := rows.Scan(&c.ID, &c.Number, scanTime{&c.ArrivedAt},
+			&c.Location.ID, &c.Location.Code, &c.Location.Name, &c.Location.Kind)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
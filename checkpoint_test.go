@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoute проверяет запись и получение маршрута посылки по пунктам
+func TestRoute(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+
+	warehouse := Location{Code: "MSK-WH-1", Name: "Склад Москва", Kind: LocationWarehouse}
+	hub := Location{Code: "SPB-HUB-1", Name: "Сортировочный центр Санкт-Петербург", Kind: LocationHub}
+	for _, l := range []*Location{&warehouse, &hub} {
+		id, err := store.AddLocation(ctx, *l)
+		require.NoError(t, err)
+		l.ID = id
+	}
+
+	got, err := store.GetLocation(ctx, "SPB-HUB-1")
+	require.NoError(t, err)
+	require.Equal(t, hub, got)
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// add: отметки приходят не по порядку
+	arrived := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	_, err = store.AddCheckpoint(ctx, id, hub.ID, arrived.Add(20*time.Hour))
+	require.NoError(t, err)
+	_, err = store.AddCheckpoint(ctx, id, warehouse.ID, arrived)
+	require.NoError(t, err)
+
+	// check
+	route, err := store.GetRoute(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, route, 2)
+	require.Equal(t, warehouse, route[0].Location)
+	require.Equal(t, arrived, route[0].ArrivedAt)
+	require.Equal(t, hub, route[1].Location)
+	require.Equal(t, id, route[1].Number)
+
+	// invalid
+	_, err = store.AddLocation(ctx, Location{Code: "X", Name: "X", Kind: "moon"})
+	require.ErrorIs(t, err, ErrInvalidLocation)
+	_, err = store.GetLocation(ctx, "missing")
+	require.ErrorIs(t, err, ErrLocationNotFound)
+	_, err = store.AddCheckpoint(ctx, id, hub.ID+100, arrived)
+	require.ErrorIs(t, err, ErrLocationNotFound)
+	_, err = store.AddCheckpoint(ctx, -1, hub.ID, arrived)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// маршрут удаляется вместе с посылкой
+	require.NoError(t, store.HardDelete(ctx, id))
+	_, err = store.GetRoute(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	var n int
+	require.NoError(t, store.db.QueryRow("SELECT COUNT(*) FROM parcel_checkpoint WHERE parcel_number = ?", id).Scan(&n))
+	require.Zero(t, n)
+}
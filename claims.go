@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClaimType — вид претензии по посылке.
+type ClaimType string
+
+const (
+	ClaimLost    ClaimType = "lost"
+	ClaimDamaged ClaimType = "damaged"
+)
+
+// Ограничения полей претензии, как у колонок таблицы claims.
+const (
+	maxClaimTextLen = 512
+	maxClaimPhotos  = 10
+)
+
+var (
+	// ErrInvalidClaim возвращается для претензии неизвестного вида,
+	// без описания или с неверными фото и для пустого решения.
+	ErrInvalidClaim = errors.New("invalid claim")
+	// ErrClaimNotFound возвращается для претензии, которой нет.
+	ErrClaimNotFound = errors.New("claim not found")
+	// ErrClaimResolved возвращает ResolveClaim для уже решённой претензии.
+	ErrClaimResolved = errors.New("claim is already resolved")
+	// ErrOpenClaim возвращается при удалении посылки с нерешённой претензией.
+	ErrOpenClaim = errors.New("parcel has open claims")
+)
+
+// Claim — претензия клиента о потере или повреждении посылки Number.
+// Photos — ссылки на фотографии повреждений. Нулевое ResolvedAt
+// означает, что претензия ещё открыта.
+type Claim struct {
+	ID          int
+	Number      int
+	Type        ClaimType
+	Description string
+	Photos      []string
+	Resolution  string
+	OpenedAt    time.Time
+	ResolvedAt  time.Time
+}
+
+// Open сообщает, что претензия ещё не решена.
+func (c Claim) Open() bool {
+	return c.ResolvedAt.IsZero()
+}
+
+func (c Claim) validate() error {
+	switch {
+	case c.Type != ClaimLost && c.Type != ClaimDamaged:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidClaim, c.Type)
+	case strings.TrimSpace(c.Description) == "" || len(c.Description) > maxClaimTextLen:
+		return fmt.Errorf("%w: description must be 1 to %d characters", ErrInvalidClaim, maxClaimTextLen)
+	case len(c.Photos) > maxClaimPhotos:
+		return fmt.Errorf("%w: at most %d photos", ErrInvalidClaim, maxClaimPhotos)
+	}
+	for _, photo := range c.Photos {
+		if strings.TrimSpace(photo) == "" {
+			return fmt.Errorf("%w: empty photo link", ErrInvalidClaim)
+		}
+	}
+	return nil
+}
+
+func validateResolution(resolution string) error {
+	if strings.TrimSpace(resolution) == "" || len(resolution) > maxClaimTextLen {
+		return fmt.Errorf("%w: resolution must be 1 to %d characters", ErrInvalidClaim, maxClaimTextLen)
+	}
+	return nil
+}
+
+// ClaimStore хранит претензии по посылкам в таблице claims. Посылку
+// с открытой претензией нельзя удалить, архивировать или удалить
+// по сроку хранения, пока претензия не решена.
+type ClaimStore interface {
+	// OpenClaim открывает претензию по посылке c.Number и возвращает её id
+	OpenClaim(ctx context.Context, c Claim) (int, error)
+	// ResolveClaim закрывает претензию id с решением resolution
+	ResolveClaim(ctx context.Context, id int, resolution string) error
+	GetClaim(ctx context.Context, id int) (Claim, error)
+	// ListClaims возвращает претензии посылки number по порядку открытия
+	ListClaims(ctx context.Context, number int) ([]Claim, error)
+}
+
+var (
+	_ ClaimStore = ParcelStore{}
+	_ ClaimStore = (*MemoryParcelStore)(nil)
+)
+
+// withoutOpenClaims возвращает условие на строку таблицы table
+// без нерешённых претензий.
+func withoutOpenClaims(table string) string {
+	return " AND NOT EXISTS (SELECT 1 FROM claims c WHERE c.parcel_number = " + table + ".number AND c.resolved_at IS NULL)"
+}
+
+const claimColumns = "id, parcel_number, type, description, photos, resolution, opened_at, resolved_at"
+
+func (s ParcelStore) OpenClaim(ctx context.Context, c Claim) (int, error) {
+	if err := c.validate(); err != nil {
+		return 0, err
+	}
+	photos, err := json.Marshal(append([]string{}, c.Photos...))
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = s.withTx(ctx, func(tx ParcelStore) error {
+		if _, err := tx.status(ctx, c.Number); err != nil {
+			return err
+		}
+		id, err = tx.insert(ctx,
+			"INSERT INTO claims (parcel_number, type, description, photos, opened_at) VALUES (?, ?, ?, ?, ?)", "id",
+			c.Number, c.Type, c.Description, string(photos), tx.timestampArg())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s ParcelStore) ResolveClaim(ctx context.Context, id int, resolution string) error {
+	if err := validateResolution(resolution); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		c, err := tx.GetClaim(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !c.Open() {
+			return ErrClaimResolved
+		}
+		_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE claims SET resolution = ?, resolved_at = ? WHERE id = ?"),
+			resolution, tx.timestampArg(), id)
+		return err
+	})
+}
+
+func (s ParcelStore) GetClaim(ctx context.Context, id int) (Claim, error) {
+	claims, err := s.claims(ctx, "id = ?", id)
+	if err != nil {
+		return Claim{}, err
+	}
+	if len(claims) == 0 {
+		return Claim{}, ErrClaimNotFound
+	}
+	return claims[0], nil
+}
+
+func (s ParcelStore) ListClaims(ctx context.Context, number int) ([]Claim, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+	return s.claims(ctx, "parcel_number = ?", number)
+}
+
+// claims возвращает претензии по условию where по возрастанию id.
+func (s ParcelStore) claims(ctx context.Context, where string, args ...any) ([]Claim, error) {
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT "+claimColumns+" FROM claims WHERE "+where+" ORDER BY id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Claim
+	for rows.Next() {
+		var c Claim
+		var photos string
+		err := rows.Scan(&c.ID, &c.Number, &c.Type, &c.Description, &photos, &c.Resolution,
+			scanTime{&c.OpenedAt}, scanTime{&c.ResolvedAt})
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(photos), &c.Photos); err != nil {
+			return nil, fmt.Errorf("claim %d photos: %w", c.ID, err)
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// checkOpenClaims возвращает ErrOpenClaim, если у посылки number
+// есть нерешённая претензия.
+func (s ParcelStore) checkOpenClaims(ctx context.Context, number int) error {
+	var n int
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT COUNT(*) FROM claims WHERE parcel_number = ? AND resolved_at IS NULL"), number).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) || n == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ErrOpenClaim
+}
+
+func (s *MemoryParcelStore) OpenClaim(ctx context.Context, c Claim) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := c.validate(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, c.Number); !ok {
+		return 0, ErrParcelNotFound
+	}
+	s.lastClaim++
+	c.ID = s.lastClaim
+	c.Photos = append([]string{}, c.Photos...)
+	c.Resolution = ""
+	c.OpenedAt = timestamp(s.now)
+	c.ResolvedAt = time.Time{}
+	s.claims = append(s.claims, c)
+
+	return c.ID, nil
+}
+
+func (s *MemoryParcelStore) ResolveClaim(ctx context.Context, id int, resolution string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateResolution(resolution); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.claims {
+		if c.ID != id {
+			continue
+		}
+		if !c.Open() {
+			return ErrClaimResolved
+		}
+		s.claims[i].Resolution = resolution
+		s.claims[i].ResolvedAt = timestamp(s.now)
+		return nil
+	}
+	return ErrClaimNotFound
+}
+
+func (s *MemoryParcelStore) GetClaim(ctx context.Context, id int) (Claim, error) {
+	if err := ctx.Err(); err != nil {
+		return Claim{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.claims {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return Claim{}, ErrClaimNotFound
+}
+
+func (s *MemoryParcelStore) ListClaims(ctx context.Context, number int) ([]Claim, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return nil, ErrParcelNotFound
+	}
+	var res []Claim
+	for _, c := range s.claims {
+		if c.Number == number {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+// hasOpenClaims сообщает, что у посылки number есть нерешённая претензия.
+// Вызывается под s.mu.
+func (s *MemoryParcelStore) hasOpenClaims(number int) bool {
+	for _, c := range s.claims {
+		if c.Number == number && c.Open() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// claimStorage — хранилище посылок с претензиями.
+type claimStorage interface {
+	ParcelStorage
+	ClaimStore
+}
+
+// checkClaims проверяет открытие и решение претензий и запрет удаления
+// посылки с открытой претензией на хранилище store
+func checkClaims(t *testing.T, store claimStorage) {
+	// prepare
+	ctx := context.Background()
+	number, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	_, err = store.OpenClaim(ctx, Claim{Number: number, Type: "stolen", Description: "нет посылки"})
+	require.ErrorIs(t, err, ErrInvalidClaim)
+	_, err = store.OpenClaim(ctx, Claim{Number: number, Type: ClaimLost})
+	require.ErrorIs(t, err, ErrInvalidClaim)
+	_, err = store.OpenClaim(ctx, Claim{Number: number + 1000, Type: ClaimLost, Description: "нет посылки"})
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// add
+	id, err := store.OpenClaim(ctx, Claim{
+		Number:      number,
+		Type:        ClaimDamaged,
+		Description: "помята коробка",
+		Photos:      []string{"https://example.com/1.jpg", "https://example.com/2.jpg"},
+	})
+	require.NoError(t, err)
+
+	// check
+	claims, err := store.ListClaims(ctx, number)
+	require.NoError(t, err)
+	require.Len(t, claims, 1)
+	c := claims[0]
+	require.Equal(t, id, c.ID)
+	require.Equal(t, number, c.Number)
+	require.Equal(t, ClaimDamaged, c.Type)
+	require.Equal(t, "помята коробка", c.Description)
+	require.Equal(t, []string{"https://example.com/1.jpg", "https://example.com/2.jpg"}, c.Photos)
+	require.True(t, c.Open())
+	require.False(t, c.OpenedAt.IsZero())
+
+	// посылку с открытой претензией удалить нельзя
+	require.ErrorIs(t, store.Delete(ctx, number), ErrOpenClaim)
+	require.ErrorIs(t, store.HardDelete(ctx, number), ErrOpenClaim)
+
+	require.ErrorIs(t, store.ResolveClaim(ctx, id, ""), ErrInvalidClaim)
+	require.ErrorIs(t, store.ResolveClaim(ctx, id+1000, "компенсация"), ErrClaimNotFound)
+	require.NoError(t, store.ResolveClaim(ctx, id, "компенсация"))
+	require.ErrorIs(t, store.ResolveClaim(ctx, id, "компенсация"), ErrClaimResolved)
+
+	c, err = store.GetClaim(ctx, id)
+	require.NoError(t, err)
+	require.False(t, c.Open())
+	require.Equal(t, "компенсация", c.Resolution)
+
+	// после решения претензии посылка удаляется вместе с ней
+	require.NoError(t, store.HardDelete(ctx, number))
+	_, err = store.GetClaim(ctx, id)
+	require.ErrorIs(t, err, ErrClaimNotFound)
+}
+
+// TestClaims проверяет претензии в SQLite и в памяти
+func TestClaims(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkClaims(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkClaims(t, NewMemoryParcelStore())
+	})
+}
+
+// TestArchiveSkipsOpenClaims проверяет, что посылка с открытой претензией
+// не архивируется
+func TestArchiveSkipsOpenClaims(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	number, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, number, ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, number, ParcelStatusDelivered))
+	id, err := store.OpenClaim(ctx, Claim{Number: number, Type: ClaimDamaged, Description: "помята коробка"})
+	require.NoError(t, err)
+
+	// check
+	n, err := store.ArchiveOlderThan(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.NoError(t, store.ResolveClaim(ctx, id, "компенсация"))
+	n, err = store.ArchiveOlderThan(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
@@ -0,0 +1,2295 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+)
+
+// cliApp хранит настройки и подключение к БД, открытое для текущей команды.
+type cliApp struct {
+	configPath string
+	cfg        Config
+
+	db      *sql.DB
+	closer  io.Closer
+	events  Publisher     // шина событий, nil если не настроена
+	backend ParcelStorage // хранилище без обёрток, для возможностей вне ParcelStorage
+	store   ParcelStorage
+	service ParcelService
+	logger  *slog.Logger
+}
+
+// newRootCmd собирает CLI parcelctl. Без подкоманды запускаются API-серверы
+// (если заданы --http или --grpc) либо демонстрация работы с посылками.
+func newRootCmd() *cobra.Command {
+	app := &cliApp{}
+	var httpAddr, grpcAddr string
+
+	root := &cobra.Command{
+		Use:          "parcelctl",
+		Short:        "Управление посылками трекера",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := app.loadConfig(cmd); err != nil {
+				return err
+			}
+			return app.open()
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return app.Close()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if httpAddr != "" || grpcAddr != "" {
+				reg := prometheus.NewRegistry()
+				var store ParcelStorage
+				store, err := NewMetricsStorage(app.store, reg, app.db)
+				if err != nil {
+					return err
+				}
+				if app.cfg.Cache.enabled() {
+					if store, err = NewCachedStorage(store, app.cfg.Cache, reg); err != nil {
+						return err
+					}
+				}
+				var limiter *RateLimiter
+				if app.cfg.RateLimit.enabled() {
+					if limiter, err = NewRateLimiter(app.cfg.RateLimit, reg); err != nil {
+						return err
+					}
+				}
+				var auth *Authenticator
+				if app.cfg.Auth.Enabled {
+					keys, ok := app.backend.(KeyStore)
+					if !ok {
+						return fmt.Errorf("storage %s does not support api keys", app.cfg.Driver)
+					}
+					auth = NewAuthenticator(keys)
+				}
+				server := NewServer(app.newService(store), httpAddr, grpcAddr).
+					WithMetrics(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})).
+					WithLimiter(limiter).
+					WithAuth(auth).
+					WithCloser(app).
+					WithLogger(app.logger)
+				if health, ok := app.backend.(HealthChecker); ok {
+					server = server.WithHealth(health)
+				}
+				if app.cfg.ShutdownTimeout > 0 {
+					server = server.WithShutdownTimeout(app.cfg.ShutdownTimeout)
+				}
+				if app.cfg.ReadHeaderTimeout > 0 {
+					server = server.WithReadHeaderTimeout(app.cfg.ReadHeaderTimeout)
+				}
+				if o, ok := app.backend.(Outbox); ok && app.cfg.Events.RelayEvery > 0 {
+					relay := NewOutboxRelay(o, app.events, app.cfg.Events.RelayEvery).WithLogger(app.logger)
+					server = server.WithWorker(relay).WithFlush(func(ctx context.Context) error {
+						_, err := relay.RunOnce(ctx)
+						return err
+					})
+				}
+				if jobs, ok := app.backend.(JobStore); ok && app.cfg.Jobs.Every > 0 && len(app.cfg.Jobs.Rules) > 0 {
+					server = server.WithWorker(NewJobRunner(jobs, app.newService(store), app.cfg.Jobs).WithLogger(app.logger))
+				}
+				if sla, ok := app.backend.(SLAStore); ok && app.cfg.SLA.Every > 0 {
+					server = server.WithWorker(NewSLAChecker(sla, app.cfg.SLA).WithLogger(app.logger))
+				}
+				if m, ok := app.backend.(Maintainer); ok && app.cfg.Maintenance.enabled() {
+					runner, err := NewMaintenanceRunner(m, app.cfg.Maintenance, reg)
+					if err != nil {
+						return err
+					}
+					server = server.WithWorker(runner.WithLogger(app.logger))
+				}
+				if parts, ok := app.backend.(*PartitionedStorage); ok && app.cfg.Partitions.Every > 0 {
+					server = server.WithWorker(NewPartitionManager(parts, app.cfg.Partitions).WithLogger(app.logger))
+				}
+
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return server.Run(ctx)
+			}
+			runDemo(cmd.Context(), app.service)
+			return nil
+		},
+	}
+
+	defaults := DefaultConfig()
+	flags := root.PersistentFlags()
+	flags.StringVar(&app.configPath, "config", "", "YAML-файл настроек, также PARCEL_CONFIG")
+	flags.StringVar(&app.cfg.Driver, "driver", defaults.Driver, "имя хранилища: sqlite, postgres, mysql или memory")
+	flags.StringVar(&app.cfg.DSN, "dsn", defaults.DSN, "строка подключения к БД")
+	flags.StringVar(&app.cfg.LogLevel, "log-level", defaults.LogLevel, "уровень логирования: debug, info, warn или error")
+	flags.IntVar(&app.cfg.Pool.MaxOpenConns, "max-open-conns", 0, "максимум открытых соединений")
+	flags.IntVar(&app.cfg.Pool.MaxIdleConns, "max-idle-conns", 0, "максимум простаивающих соединений")
+	flags.DurationVar(&app.cfg.Pool.ConnMaxLifetime, "conn-max-lifetime", 0, "максимальное время жизни соединения")
+	root.Flags().StringVar(&httpAddr, "http", "", "адрес HTTP API, например :8080")
+	root.Flags().StringVar(&grpcAddr, "grpc", "", "адрес gRPC API, например :9090")
+
+	root.AddCommand(
+		app.addCmd(),
+		app.getCmd(),
+		app.watchCmd(),
+		app.labelCmd(),
+		app.listCmd(),
+		app.searchCmd(),
+		app.setStatusCmd(),
+		app.setAddressCmd(),
+		app.payCmd(),
+		app.returnCmd(),
+		app.cancelCmd(),
+		app.deliverCmd(),
+		app.signatureCmd(),
+		app.attemptCmd(),
+		app.claimCmd(),
+		app.noteCmd(),
+		app.attachmentCmd(),
+		app.deleteCmd(),
+		app.archiveCmd(),
+		app.retentionCmd(),
+		app.jobsCmd(),
+		app.slaCmd(),
+		app.partitionsCmd(),
+		app.maintenanceCmd(),
+		app.exportCmd(),
+		app.importCmd(),
+		app.backupCmd(),
+		app.outboxCmd(),
+		app.clientCmd(),
+		app.courierCmd(),
+		app.routeCmd(),
+		app.geoCmd(),
+		app.priceCmd(),
+		app.apiKeyCmd(),
+		app.encryptionCmd(),
+	)
+
+	return root
+}
+
+// loadConfig собирает настройки из файла и окружения, а затем
+// переопределяет их флагами, явно заданными в командной строке.
+func (a *cliApp) loadConfig(cmd *cobra.Command) error {
+	path := a.configPath
+	if path == "" {
+		path = os.Getenv("PARCEL_CONFIG")
+	}
+
+	cfg, err := LoadConfig(path, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("driver") {
+		cfg.Driver = a.cfg.Driver
+	}
+	if flags.Changed("dsn") {
+		cfg.DSN = a.cfg.DSN
+	}
+	if flags.Changed("log-level") {
+		cfg.LogLevel = a.cfg.LogLevel
+	}
+	if flags.Changed("max-open-conns") {
+		cfg.Pool.MaxOpenConns = a.cfg.Pool.MaxOpenConns
+	}
+	if flags.Changed("max-idle-conns") {
+		cfg.Pool.MaxIdleConns = a.cfg.Pool.MaxIdleConns
+	}
+	if flags.Changed("conn-max-lifetime") {
+		cfg.Pool.ConnMaxLifetime = a.cfg.Pool.ConnMaxLifetime
+	}
+	a.cfg = cfg
+
+	return nil
+}
+
+func (a *cliApp) open() error {
+	store, db, err := NewParcelStoreFromConfig(a.cfg)
+	if err != nil {
+		return err
+	}
+	closer, _ := store.(io.Closer)
+	fail := func(err error) error {
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}
+
+	// схема создаётся и обновляется при каждом запуске
+	if m, ok := store.(interface{ Migrate(context.Context) error }); ok {
+		if err := m.Migrate(context.Background()); err != nil {
+			return fail(err)
+		}
+	}
+
+	logger, err := NewLogger(a.cfg.LogLevel)
+	if err != nil {
+		return fail(err)
+	}
+
+	if a.cfg.Events.Outbox {
+		sqlStore, ok := store.(ParcelStore)
+		if !ok {
+			return fail(fmt.Errorf("storage %s does not support outbox", a.cfg.Driver))
+		}
+		store = NewOutboxStore(sqlStore)
+	}
+
+	a.backend = store
+	if a.cfg.Retry.MaxAttempts > 1 {
+		store = NewRetryStorage(store, a.cfg.Retry)
+	}
+	if a.cfg.ETA.enabled() {
+		store = NewEstimatingStorage(store, NewDeliveryEstimator(a.cfg.ETA), logger)
+	}
+	if a.cfg.Pricing.Enabled {
+		rates, ok := a.backend.(PriceStore)
+		if !ok {
+			return fail(fmt.Errorf("storage %s does not support pricing", a.cfg.Driver))
+		}
+		store = NewPricingStorage(store, NewPricer(rates, NewDeliveryEstimator(a.cfg.ETA)), logger)
+	}
+	if a.cfg.SLA.enabled() {
+		store = NewSLAStorage(store, a.cfg.SLA)
+	}
+	if geocoder := a.cfg.Geocoding.Geocoder(); geocoder != nil {
+		geo, ok := a.backend.(GeoStore)
+		if !ok {
+			return fail(fmt.Errorf("storage %s does not support geocoding", a.cfg.Driver))
+		}
+		store = NewGeocodingStorage(store, geo, geocoder, logger)
+	}
+	if notifier := a.cfg.Notify.Notifier(); notifier != nil {
+		store = NewNotifyingStorage(store, notifier, a.cfg.Notify.Clients, logger)
+	}
+	events, err := a.cfg.Events.Publisher()
+	if err != nil {
+		return fail(err)
+	}
+
+	a.db = db
+	a.closer = closer
+	a.events = events
+	a.logger = logger
+	a.store = NewLoggingStorage(store, logger)
+	a.service = a.newService(a.store)
+
+	return nil
+}
+
+// newService собирает сервис посылок поверх store с журналом и шиной событий.
+func (a *cliApp) newService(store ParcelStorage) ParcelService {
+	service := NewParcelService(store).WithLogger(a.logger).WithEstimator(NewDeliveryEstimator(a.cfg.ETA))
+	if routes, ok := a.backend.(RouteStore); ok {
+		service = service.WithRoutes(routes)
+	}
+	if meta, ok := a.backend.(AttachmentStore); ok && a.cfg.Attachments.enabled() {
+		blobs := NewFileBlobStore(a.cfg.Attachments.Dir)
+		service = service.WithAttachments(NewAttachments(meta, blobs, a.cfg.Attachments.MaxBytes))
+	}
+	if attempts, ok := a.backend.(AttemptStore); ok {
+		service = service.WithDeliveryAttempts(attempts, a.cfg.Attempts.MaxAttempts)
+	}
+	if clients, ok := a.backend.(ClientStore); ok {
+		service = service.WithClients(clients)
+	}
+	if searcher, ok := a.backend.(AddressSearcher); ok {
+		service = service.WithSearcher(searcher)
+	}
+	// с outbox события публикует outbox relay, а не сервис
+	if a.events != nil && !a.cfg.Events.Outbox {
+		service = service.WithPublisher(a.events)
+	}
+	return service
+}
+
+// Close отключается от шины и закрывает хранилище. Повторный вызов
+// ничего не делает: API-сервер закрывает приложение сам при остановке.
+func (a *cliApp) Close() error {
+	var errs []error
+	if a.events != nil {
+		errs = append(errs, a.events.Close())
+		a.events = nil
+	}
+	if a.closer != nil {
+		errs = append(errs, a.closer.Close())
+		a.closer = nil
+	}
+	return errors.Join(errs...)
+}
+
+func (a *cliApp) addCmd() *cobra.Command {
+	var client int
+	var sender, address, key string
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Зарегистрировать посылку",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, err := a.service.Register(WithIdempotencyKey(cmd.Context(), key), client, sender, address)
+			return err
+		},
+	}
+	cmd.Flags().IntVar(&client, "client", 0, "идентификатор клиента")
+	cmd.Flags().StringVar(&sender, "sender", "", "адрес отправителя")
+	cmd.Flags().StringVar(&address, "address", "", "адрес доставки")
+	cmd.Flags().StringVar(&key, "idempotency-key", "", "ключ, с которым повторный запуск не добавит посылку снова")
+	cmd.MarkFlagRequired("client")
+	cmd.MarkFlagRequired("address")
+
+	return cmd
+}
+
+func (a *cliApp) getCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <number>",
+		Short: "Показать посылку",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+
+			p, err := a.service.Get(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			printParcel(cmd, p)
+
+			return nil
+		},
+	}
+}
+
+func (a *cliApp) watchCmd() *cobra.Command {
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch <number>",
+		Short: "Печатать изменения посылки, пока не нажат Ctrl+C",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			events, err := a.service.WithWatchInterval(interval).Watch(ctx, number)
+			if err != nil {
+				return err
+			}
+			for e := range events {
+				printParcelEvent(cmd, e)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", defaultWatchInterval, "как часто перечитывать посылку")
+
+	return cmd
+}
+
+// filterFlags добавляет в cmd флаги фильтра посылок. Возвращённая
+// функция собирает из них фильтр и вызывается в начале RunE.
+func filterFlags(cmd *cobra.Command) func() (Filter, error) {
+	var filter Filter
+	var statuses []string
+	var from, to string
+
+	cmd.Flags().IntVar(&filter.Client, "client", 0, "идентификатор клиента")
+	cmd.Flags().StringSliceVar(&statuses, "status", nil, "только посылки в этих статусах, например sent,delivered")
+	cmd.Flags().StringVar(&filter.ServiceClass, "service-class", "", "только посылки класса economy, standard или express")
+	cmd.Flags().StringVar(&from, "from", "", "созданные не раньше этого момента, RFC 3339")
+	cmd.Flags().StringVar(&to, "to", "", "созданные раньше этого момента, RFC 3339")
+	cmd.Flags().StringVar(&filter.Address, "address", "", "только посылки с этой подстрокой в адресе отправителя или получателя")
+	cmd.Flags().IntVar(&filter.Courier, "courier", 0, "только посылки курьера")
+
+	return func() (Filter, error) {
+		var err error
+		if filter.Statuses, err = parseStatuses(statuses); err != nil {
+			return Filter{}, err
+		}
+		for _, f := range []struct {
+			value string
+			t     *time.Time
+		}{{from, &filter.From}, {to, &filter.To}} {
+			if f.value == "" {
+				continue
+			}
+			if *f.t, err = time.Parse(time.RFC3339, f.value); err != nil {
+				return Filter{}, fmt.Errorf("invalid time %q: %w", f.value, err)
+			}
+		}
+		return filter, nil
+	}
+}
+
+func (a *cliApp) listCmd() *cobra.Command {
+	var opts ListOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Показать посылки клиента или все посылки по фильтру",
+		Args:  cobra.NoArgs,
+	}
+	parseFilter := filterFlags(cmd)
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		filter, err := parseFilter()
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("client") && reflect.DeepEqual(filter, Filter{Client: filter.Client}) {
+			page, err := a.service.ListByClient(cmd.Context(), filter.Client, opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Посылки клиента %d (всего %d):\n", filter.Client, page.Total)
+			for _, p := range page.Parcels {
+				printParcel(cmd, p)
+			}
+			return nil
+		}
+
+		page, err := a.service.ListAll(cmd.Context(), filter, opts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Посылки (всего %d):\n", page.Total)
+		for _, p := range page.Parcels {
+			printParcel(cmd, p)
+		}
+
+		return nil
+	}
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "максимум посылок в ответе, 0 — без ограничения")
+	cmd.Flags().IntVar(&opts.Offset, "offset", 0, "сколько посылок пропустить")
+	cmd.Flags().StringVar(&opts.SortBy, "sort", SortByNumber, "поле сортировки: number, created_at, updated_at или status")
+	cmd.Flags().BoolVar(&opts.Desc, "desc", false, "сортировать по убыванию")
+
+	return cmd
+}
+
+func (a *cliApp) searchCmd() *cobra.Command {
+	var opts ListOptions
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Найти посылки по части адреса отправителя или получателя",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			searcher, ok := a.backend.(AddressSearcher)
+			if !ok {
+				return fmt.Errorf("storage %s does not support address search", a.cfg.Driver)
+			}
+			page, err := searcher.SearchByAddress(cmd.Context(), args[0], opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Найдено посылок: %d\n", page.Total)
+			for _, p := range page.Parcels {
+				printParcel(cmd, p)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&opts.Limit, "limit", 20, "максимум посылок в ответе, 0 — без ограничения")
+	cmd.Flags().IntVar(&opts.Offset, "offset", 0, "сколько посылок пропустить")
+	cmd.Flags().StringVar(&opts.SortBy, "sort", SortByNumber, "поле сортировки: number, created_at, updated_at или status")
+	cmd.Flags().BoolVar(&opts.Desc, "desc", false, "сортировать по убыванию")
+
+	return cmd
+}
+
+func (a *cliApp) setStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-status <number> <status>",
+		Short: "Изменить статус посылки",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			status, err := ParseStatus(args[1])
+			if err != nil {
+				return err
+			}
+			return a.service.SetStatus(cmd.Context(), number, status)
+		},
+	}
+}
+
+func (a *cliApp) payCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pay <number> <tx-ref>",
+		Short: "Отметить посылку оплаченной транзакцией платёжной системы",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			return a.service.MarkPaid(cmd.Context(), number, args[1])
+		},
+	}
+}
+
+func (a *cliApp) returnCmd() *cobra.Command {
+	create := &cobra.Command{
+		Use:   "create <number>",
+		Short: "Создать возвратную посылку для посылки с запрошенным возвратом",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			p, err := a.service.CreateReturn(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			printParcel(cmd, p)
+			return nil
+		},
+	}
+
+	var original bool
+	show := &cobra.Command{
+		Use:   "show <number>",
+		Short: "Показать возвратную посылку для посылки number",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			get := a.service.GetReturn
+			if original {
+				get = a.service.GetOriginal
+			}
+			p, err := get(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			printParcel(cmd, p)
+			return nil
+		},
+	}
+	show.Flags().BoolVar(&original, "original", false, "показать исходную посылку для возвратной number")
+
+	cmd := &cobra.Command{
+		Use:   "return",
+		Short: "Возвраты доставленных посылок",
+	}
+	cmd.AddCommand(create, show)
+
+	return cmd
+}
+
+func (a *cliApp) setAddressCmd() *cobra.Command {
+	var sender bool
+
+	cmd := &cobra.Command{
+		Use:   "set-address <number> <address>",
+		Short: "Изменить адрес доставки или отправителя зарегистрированной посылки",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			if sender {
+				return a.service.ChangeSenderAddress(cmd.Context(), number, args[1])
+			}
+			return a.service.ChangeAddress(cmd.Context(), number, args[1])
+		},
+	}
+	cmd.Flags().BoolVar(&sender, "sender", false, "изменить адрес отправителя")
+
+	return cmd
+}
+
+func (a *cliApp) deleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <number>",
+		Short: "Удалить зарегистрированную посылку",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			return a.service.Delete(cmd.Context(), number)
+		},
+	}
+}
+
+func (a *cliApp) cancelCmd() *cobra.Command {
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:   "cancel <number>",
+		Short: "Отменить зарегистрированную посылку, оставив её в выборках",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			return a.service.Cancel(cmd.Context(), number, CancelReason(reason))
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "причина: customer_request, duplicate, invalid_address, payment_failed или other")
+	cmd.MarkFlagRequired("reason")
+
+	return cmd
+}
+
+func (a *cliApp) claimCmd() *cobra.Command {
+	claimStore := func() (ClaimStore, error) {
+		store, ok := a.backend.(ClaimStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support claims", a.cfg.Driver)
+		}
+		return store, nil
+	}
+	printClaim := func(cmd *cobra.Command, c Claim) {
+		state := "open"
+		if !c.Open() {
+			state = "resolved " + formatTime(c.ResolvedAt)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.ID, c.Number, c.Type, formatTime(c.OpenedAt), state,
+			c.Description, strings.Join(c.Photos, ","), c.Resolution)
+	}
+
+	var c Claim
+	var claimType string
+	open := &cobra.Command{
+		Use:   "open <number>",
+		Short: "Открыть претензию о потере или повреждении посылки",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := claimStore()
+			if err != nil {
+				return err
+			}
+			if c.Number, err = parseNumber(args[0]); err != nil {
+				return err
+			}
+			c.Type = ClaimType(claimType)
+			id, err := store.OpenClaim(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Открыта претензия %d\n", id)
+			return nil
+		},
+	}
+	open.Flags().StringVar(&claimType, "type", "", "вид претензии: lost или damaged")
+	open.Flags().StringVar(&c.Description, "description", "", "описание претензии")
+	open.Flags().StringArrayVar(&c.Photos, "photo", nil, "ссылка на фотографию, можно повторять")
+	open.MarkFlagRequired("type")
+	open.MarkFlagRequired("description")
+
+	var resolution string
+	resolve := &cobra.Command{
+		Use:   "resolve <id>",
+		Short: "Закрыть претензию с решением",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := claimStore()
+			if err != nil {
+				return err
+			}
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid claim id %q", args[0])
+			}
+			return store.ResolveClaim(cmd.Context(), id, resolution)
+		},
+	}
+	resolve.Flags().StringVar(&resolution, "resolution", "", "решение по претензии")
+	resolve.MarkFlagRequired("resolution")
+
+	list := &cobra.Command{
+		Use:   "list <number>",
+		Short: "Показать претензии по посылке",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := claimStore()
+			if err != nil {
+				return err
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			claims, err := store.ListClaims(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			for _, c := range claims {
+				printClaim(cmd, c)
+			}
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "claim",
+		Short: "Претензии о потерянных и повреждённых посылках",
+	}
+	cmd.AddCommand(open, resolve, list)
+
+	return cmd
+}
+
+func (a *cliApp) noteCmd() *cobra.Command {
+	noteStore := func() (NoteStore, error) {
+		store, ok := a.backend.(NoteStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support notes", a.cfg.Driver)
+		}
+		return store, nil
+	}
+
+	var author string
+	add := &cobra.Command{
+		Use:   "add <number> <text>",
+		Short: "Добавить заметку к посылке",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := noteStore()
+			if err != nil {
+				return err
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			n, err := store.AddNote(cmd.Context(), number, author, args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Добавлена заметка %d\n", n.ID)
+			return nil
+		},
+	}
+	add.Flags().StringVar(&author, "author", "", "автор заметки")
+	add.MarkFlagRequired("author")
+
+	list := &cobra.Command{
+		Use:   "list <number>",
+		Short: "Показать заметки к посылке",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := noteStore()
+			if err != nil {
+				return err
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			notes, err := store.GetNotes(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			for _, n := range notes {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\n", n.ID, formatTime(n.CreatedAt), n.Author, n.Text)
+			}
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "note",
+		Short: "Заметки поддержки к посылкам",
+	}
+	cmd.AddCommand(add, list)
+
+	return cmd
+}
+
+func (a *cliApp) attachmentCmd() *cobra.Command {
+	var kind string
+	add := &cobra.Command{
+		Use:   "add <number> <file>",
+		Short: "Приложить фото или подпись получателя к посылке",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			att, err := a.service.AttachProofOfDelivery(cmd.Context(), number, AttachmentKind(kind), f)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Приложено вложение %d (%s, %d байт)\n", att.ID, att.ContentType, att.Size)
+			return nil
+		},
+	}
+	add.Flags().StringVar(&kind, "kind", string(AttachmentPhoto), "вид вложения: photo или signature")
+
+	list := &cobra.Command{
+		Use:   "list <number>",
+		Short: "Показать вложения посылки",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			list, err := a.service.ListAttachments(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			for _, att := range list {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%d\t%s\t%s\n",
+					att.ID, att.Kind, att.ContentType, att.Size, att.SHA256, formatTime(att.CreatedAt))
+			}
+			return nil
+		},
+	}
+
+	var out string
+	get := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Выгрузить содержимое вложения",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid attachment id %q", args[0])
+			}
+			_, content, err := a.service.OpenAttachment(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			defer content.Close()
+
+			w := cmd.OutOrStdout()
+			if out != "" {
+				f, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			_, err = io.Copy(w, content)
+			return err
+		},
+	}
+	get.Flags().StringVarP(&out, "output", "o", "", "файл для содержимого, по умолчанию stdout")
+
+	cmd := &cobra.Command{
+		Use:   "attachment",
+		Short: "Подтверждения доставки: фото и подписи получателей",
+	}
+	cmd.AddCommand(add, list, get)
+
+	return cmd
+}
+
+func (a *cliApp) deliverCmd() *cobra.Command {
+	var signedBy string
+
+	cmd := &cobra.Command{
+		Use:   "deliver <number> <signature.png>",
+		Short: "Доставить посылку с подписью получателя",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			image, err := os.ReadFile(args[1])
+			if err != nil {
+				return err
+			}
+			return a.service.Deliver(cmd.Context(), number, image, signedBy)
+		},
+	}
+	cmd.Flags().StringVar(&signedBy, "signed-by", "", "имя расписавшегося получателя")
+	cmd.MarkFlagRequired("signed-by")
+
+	return cmd
+}
+
+func (a *cliApp) signatureCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "signature <number>",
+		Short: "Выгрузить подпись получателя доставленной посылки в PNG",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, ok := a.backend.(SignatureStore)
+			if !ok {
+				return fmt.Errorf("storage %s does not support signatures", a.cfg.Driver)
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			sig, err := store.GetSignature(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				_, err = cmd.OutOrStdout().Write(sig.Image)
+				return err
+			}
+			if err := os.WriteFile(out, sig.Image, 0o640); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Подпись %s сохранена в %s\n", sig.SignedBy, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "output", "o", "", "файл для подписи, по умолчанию stdout")
+
+	return cmd
+}
+
+func (a *cliApp) attemptCmd() *cobra.Command {
+	var note string
+	record := &cobra.Command{
+		Use:   "record <number> <outcome>",
+		Short: "Записать неудачную попытку доставки: no_answer, refused, wrong_address или no_access",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			attempt, err := a.service.RecordDeliveryAttempt(cmd.Context(), number, AttemptOutcome(args[1]), note)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Записана попытка доставки %d посылки %d\n", attempt.Attempt, number)
+			return nil
+		},
+	}
+	record.Flags().StringVar(&note, "note", "", "комментарий курьера")
+
+	list := &cobra.Command{
+		Use:   "list <number>",
+		Short: "Показать попытки доставки посылки",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			attempts, err := a.service.GetAttempts(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			for _, at := range attempts {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\n", at.Attempt, formatTime(at.AttemptedAt), at.Outcome, at.Note)
+			}
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "attempt",
+		Short: "Попытки доставки посылок",
+	}
+	cmd.AddCommand(record, list)
+
+	return cmd
+}
+
+func (a *cliApp) archiveCmd() *cobra.Command {
+	var olderThan, every time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Перенести давно доставленные посылки в архив",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			archiver, ok := a.backend.(ParcelArchiver)
+			if !ok {
+				return fmt.Errorf("storage %s does not support archiving", a.cfg.Driver)
+			}
+
+			runner := NewArchiveRunner(archiver, olderThan, every).WithLogger(a.logger)
+			if every > 0 {
+				return runner.Run(cmd.Context())
+			}
+
+			n, err := runner.RunOnce(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "В архив перенесено посылок: %d\n", n)
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&olderThan, "older-than", 30*24*time.Hour, "архивировать посылки, доставленные раньше этого срока")
+	cmd.Flags().DurationVar(&every, "every", 0, "повторять архивацию с этим интервалом до остановки")
+
+	return cmd
+}
+
+func (a *cliApp) retentionCmd() *cobra.Command {
+	var dryRun bool
+	var every time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "retention",
+		Short: "Обезличить и удалить посылки по срокам хранения из настроек",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, ok := a.backend.(RetentionStore)
+			if !ok {
+				return fmt.Errorf("storage %s does not support retention", a.cfg.Driver)
+			}
+			cfg := a.cfg.Retention
+			if len(cfg.Rules) == 0 {
+				return errors.New("no retention rules: set retention.rules in config")
+			}
+			if cmd.Flags().Changed("dry-run") {
+				cfg.DryRun = dryRun
+			}
+			if cmd.Flags().Changed("every") {
+				cfg.Every = every
+			}
+
+			runner := NewRetentionRunner(store, cfg).WithLogger(a.logger)
+			if cfg.Every > 0 {
+				return runner.Run(cmd.Context())
+			}
+
+			reports, err := runner.RunOnce(cmd.Context())
+			for _, r := range reports {
+				verb := "изменено"
+				if r.DryRun {
+					verb = "будет изменено"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s старше %s: %s посылок %d\n",
+					r.Rule.Action, r.Rule.status(), formatTime(r.Cutoff), verb, r.Parcels)
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "только посчитать посылки, ничего не меняя")
+	cmd.Flags().DurationVar(&every, "every", 0, "повторять прогон с этим интервалом до остановки")
+
+	return cmd
+}
+
+func (a *cliApp) jobsCmd() *cobra.Command {
+	jobStore := func() (JobStore, error) {
+		store, ok := a.backend.(JobStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support jobs", a.cfg.Driver)
+		}
+		return store, nil
+	}
+	printRun := func(cmd *cobra.Command, r JobRun) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\t%d\t%s\n",
+			r.ID, r.Job, formatTime(r.StartedAt), formatTime(r.FinishedAt), r.Parcels, r.Error)
+	}
+
+	var every time.Duration
+	run := &cobra.Command{
+		Use:   "run [name]",
+		Short: "Выполнить задание name или все задания из настроек",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := jobStore()
+			if err != nil {
+				return err
+			}
+			cfg := a.cfg.Jobs
+			if len(cfg.Rules) == 0 {
+				return errors.New("no jobs: set jobs.rules in config")
+			}
+			if cmd.Flags().Changed("every") {
+				cfg.Every = every
+			}
+
+			runner := NewJobRunner(store, a.service, cfg).WithLogger(a.logger)
+			if len(args) == 1 {
+				r, err := runner.RunJob(cmd.Context(), args[0])
+				if r.Job != "" {
+					printRun(cmd, r)
+				}
+				return err
+			}
+			if cfg.Every > 0 {
+				return runner.Run(cmd.Context())
+			}
+			runs, err := runner.RunOnce(cmd.Context())
+			for _, r := range runs {
+				printRun(cmd, r)
+			}
+			return err
+		},
+	}
+	run.Flags().DurationVar(&every, "every", 0, "повторять прогон всех заданий с этим интервалом до остановки")
+
+	var job string
+	runs := &cobra.Command{
+		Use:   "runs",
+		Short: "Показать журнал прогонов заданий",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := jobStore()
+			if err != nil {
+				return err
+			}
+			all, err := store.ListJobRuns(cmd.Context(), job)
+			if err != nil {
+				return err
+			}
+			for _, r := range all {
+				printRun(cmd, r)
+			}
+			return nil
+		},
+	}
+	runs.Flags().StringVar(&job, "job", "", "только прогоны этого задания")
+
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Автоматические задания над посылками по правилам из настроек",
+	}
+	cmd.AddCommand(run, runs)
+
+	return cmd
+}
+
+func (a *cliApp) slaCmd() *cobra.Command {
+	var every time.Duration
+	check := &cobra.Command{
+		Use:   "check",
+		Short: "Отметить посылки, не доставленные в срок SLA",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, ok := a.backend.(SLAStore)
+			if !ok {
+				return fmt.Errorf("storage %s does not support sla", a.cfg.Driver)
+			}
+			cfg := a.cfg.SLA
+			if cmd.Flags().Changed("every") {
+				cfg.Every = every
+			}
+
+			checker := NewSLAChecker(store, cfg).WithLogger(a.logger)
+			if cfg.Every > 0 {
+				return checker.Run(cmd.Context())
+			}
+			n, err := checker.RunOnce(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Просрочено посылок: %d\n", n)
+			return nil
+		},
+	}
+	check.Flags().DurationVar(&every, "every", 0, "повторять проверку с этим интервалом до остановки")
+
+	var opts ListOptions
+	breached := &cobra.Command{
+		Use:   "breached",
+		Short: "Показать посылки с нарушенным сроком SLA",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			page, err := a.service.ListBreached(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Просроченные посылки (всего %d):\n", page.Total)
+			for _, p := range page.Parcels {
+				printParcel(cmd, p)
+			}
+			return nil
+		},
+	}
+	breached.Flags().IntVar(&opts.Limit, "limit", 0, "максимум посылок в ответе, 0 — без ограничения")
+	breached.Flags().IntVar(&opts.Offset, "offset", 0, "сколько посылок пропустить")
+
+	cmd := &cobra.Command{
+		Use:   "sla",
+		Short: "Сроки доставки по классам обслуживания",
+	}
+	cmd.AddCommand(check, breached)
+
+	return cmd
+}
+
+func (a *cliApp) partitionsCmd() *cobra.Command {
+	var every time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "partitions",
+		Short: "Создать месячные разделы заранее и удалить устаревшие",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			parts, ok := a.backend.(*PartitionedStorage)
+			if !ok {
+				return errors.New("partitions are disabled: set partitions.dsn in config")
+			}
+			cfg := a.cfg.Partitions
+			if cmd.Flags().Changed("every") {
+				cfg.Every = every
+			}
+
+			manager := NewPartitionManager(parts, cfg).WithLogger(a.logger)
+			if cfg.Every > 0 {
+				return manager.Run(cmd.Context())
+			}
+
+			report, err := manager.RunOnce(cmd.Context())
+			for _, name := range report.Created {
+				fmt.Fprintf(cmd.OutOrStdout(), "создан %s\n", name)
+			}
+			for _, name := range report.Dropped {
+				fmt.Fprintf(cmd.OutOrStdout(), "удалён %s\n", name)
+			}
+			return err
+		},
+	}
+	cmd.Flags().DurationVar(&every, "every", 0, "повторять прогон с этим интервалом до остановки")
+
+	return cmd
+}
+
+func (a *cliApp) maintenanceCmd() *cobra.Command {
+	var schedule string
+
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Выполнить VACUUM, ANALYZE и сброс журнала и показать размеры таблиц",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, ok := a.backend.(Maintainer)
+			if !ok {
+				return fmt.Errorf("storage %s does not support maintenance", a.cfg.Driver)
+			}
+			cfg := a.cfg.Maintenance
+			if cmd.Flags().Changed("schedule") {
+				cfg.Schedule = schedule
+			}
+
+			runner, err := NewMaintenanceRunner(store, cfg, nil)
+			if err != nil {
+				return err
+			}
+			runner = runner.WithLogger(a.logger)
+			if cfg.enabled() {
+				return runner.Run(cmd.Context())
+			}
+
+			report, err := runner.RunOnce(cmd.Context())
+			for _, s := range report.Sizes {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%d\n", s.Kind, s.Name, s.Bytes)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&schedule, "schedule", "", "повторять по расписанию crontab, например \"0 3 * * *\"")
+
+	return cmd
+}
+
+func (a *cliApp) exportCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Выгрузить посылки в CSV или JSON Lines на стандартный вывод",
+		Args:  cobra.NoArgs,
+	}
+	parseFilter := filterFlags(cmd)
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		filter, err := parseFilter()
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case "csv":
+			if exporter, ok := a.backend.(ParcelExporter); ok {
+				return exporter.ExportCSV(cmd.Context(), cmd.OutOrStdout(), filter)
+			}
+		case "json":
+			if dumper, ok := a.backend.(ParcelDumper); ok {
+				return dumper.ExportJSON(cmd.Context(), cmd.OutOrStdout(), filter)
+			}
+		default:
+			return fmt.Errorf("unknown export format %q", format)
+		}
+		return fmt.Errorf("storage %s does not support %s export", a.cfg.Driver, format)
+	}
+	cmd.Flags().StringVar(&format, "format", "csv", "формат: csv или json")
+
+	return cmd
+}
+
+func (a *cliApp) importCmd() *cobra.Command {
+	var format string
+	var opts CSVImportOptions
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Загрузить посылки из выгрузки export --format json или зарегистрировать новые из CSV",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "csv" {
+				return fmt.Errorf("unknown import format %q", format)
+			}
+			if opts.DryRun && format != "csv" {
+				return errors.New("--dry-run is supported only for csv")
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if format == "csv" {
+				importer, ok := a.backend.(CSVImporter)
+				if !ok {
+					return fmt.Errorf("storage %s does not support csv import", a.cfg.Driver)
+				}
+				res, err := importer.ImportCSV(cmd.Context(), f, opts)
+				if err != nil {
+					return err
+				}
+				for _, e := range res.Errors {
+					fmt.Fprintf(cmd.ErrOrStderr(), "строка %d: %v\n", e.Line, e.Err)
+				}
+				if opts.DryRun {
+					fmt.Fprintf(cmd.OutOrStdout(), "Проверено строк: %d, с ошибками: %d\n", res.Rows, len(res.Errors))
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "Зарегистрировано посылок: %d из %d строк\n", res.Imported, res.Rows)
+				}
+				if len(res.Errors) > 0 {
+					return fmt.Errorf("%d of %d rows have errors", len(res.Errors), res.Rows)
+				}
+				return nil
+			}
+
+			dumper, ok := a.backend.(ParcelDumper)
+			if !ok {
+				return fmt.Errorf("storage %s does not support import", a.cfg.Driver)
+			}
+			res, err := dumper.ImportJSON(cmd.Context(), f)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Загружено посылок: %d, пропущено как дубликаты: %d\n",
+				res.Imported, res.Duplicates)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "формат: json (выгрузка export) или csv (новые посылки)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "только проверить строки CSV, ничего не записывая")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch-size", 0, "строк CSV в одной транзакции, 0 — 500")
+
+	return cmd
+}
+
+func (a *cliApp) labelCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "label <number> FILE",
+		Short: "Сохранить этикетку посылки с трек-кодом в PNG или PDF",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+
+			label, err := a.service.GenerateLabel(cmd.Context(), number, format)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(args[1], label, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Этикетка посылки № %d сохранена в %s\n", number, args[1])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", LabelCode128, "формат этикетки: code128, qr или pdf (A6 с адресами)")
+
+	return cmd
+}
+
+func (a *cliApp) backupCmd() *cobra.Command {
+	backuper := func() (Backuper, error) {
+		b, ok := a.backend.(Backuper)
+		if !ok || a.cfg.Driver != "sqlite" {
+			return nil, fmt.Errorf("storage %s does not support backups", a.cfg.Driver)
+		}
+		return b, nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Резервные копии базы SQLite",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "create FILE",
+			Short: "Снять копию работающей базы в новый файл",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				b, err := backuper()
+				if err != nil {
+					return err
+				}
+				if err := b.Backup(cmd.Context(), args[0]); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Копия базы сохранена в %s\n", args[0])
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "restore FILE",
+			Short: "Заменить содержимое базы копией из файла",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				b, err := backuper()
+				if err != nil {
+					return err
+				}
+				if err := b.RestoreBackup(cmd.Context(), args[0]); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "База восстановлена из %s\n", args[0])
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
+
+func (a *cliApp) outboxCmd() *cobra.Command {
+	outbox := func() (Outbox, error) {
+		o, ok := a.backend.(Outbox)
+		if !ok || !a.cfg.Events.Outbox {
+			return nil, errors.New("outbox is not enabled: set events.outbox in config")
+		}
+		return o, nil
+	}
+
+	var query OutboxQuery
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Показать записи outbox",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			o, err := outbox()
+			if err != nil {
+				return err
+			}
+			entries, err := o.ListOutbox(cmd.Context(), query)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				sent := "ожидает"
+				if !e.SentAt.IsZero() {
+					sent = formatTime(e.SentAt)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%s\t%s\t%d\t%s\n",
+					e.ID, e.Number, e.Type, sent, e.Attempts, e.LastError)
+			}
+			return nil
+		},
+	}
+	list.Flags().BoolVar(&query.Pending, "pending", false, "только неопубликованные")
+	list.Flags().IntVar(&query.AfterID, "after", 0, "записи с номером больше этого")
+	list.Flags().IntVar(&query.Limit, "limit", 100, "сколько записей показать")
+
+	var from int
+	replay := &cobra.Command{
+		Use:   "replay",
+		Short: "Переотправить опубликованные события",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			o, err := outbox()
+			if err != nil {
+				return err
+			}
+			n, err := o.ReplayOutbox(cmd.Context(), from)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Снова в очереди событий: %d\n", n)
+			return nil
+		},
+	}
+	replay.Flags().IntVar(&from, "from", 1, "переотправить записи начиная с этого номера")
+
+	var every time.Duration
+	relay := &cobra.Command{
+		Use:   "relay",
+		Short: "Опубликовать накопившиеся события в шину",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			o, err := outbox()
+			if err != nil {
+				return err
+			}
+
+			relay := NewOutboxRelay(o, a.events, every).WithLogger(a.logger)
+			if every > 0 {
+				return relay.Run(cmd.Context())
+			}
+
+			n, err := relay.RunOnce(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Опубликовано событий: %d\n", n)
+			return nil
+		},
+	}
+	relay.Flags().DurationVar(&every, "every", 0, "публиковать с этим интервалом до остановки")
+
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Очередь событий для шины (transactional outbox)",
+	}
+	cmd.AddCommand(list, replay, relay)
+
+	return cmd
+}
+
+func (a *cliApp) clientCmd() *cobra.Command {
+	clients := func() (ClientStore, error) {
+		c, ok := a.backend.(ClientStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support clients", a.cfg.Driver)
+		}
+		return c, nil
+	}
+
+	var c Client
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "Завести клиента",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := clients()
+			if err != nil {
+				return err
+			}
+			id, err := store.AddClient(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Клиент %s заведён с идентификатором %d\n", c.Name, id)
+			return nil
+		},
+	}
+	add.Flags().IntVar(&c.ID, "id", 0, "идентификатор клиента, по умолчанию следующий свободный")
+	add.Flags().StringVar(&c.Name, "name", "", "имя клиента")
+	add.Flags().StringVar(&c.Email, "email", "", "адрес электронной почты")
+	add.Flags().StringVar(&c.Phone, "phone", "", "номер телефона")
+	add.MarkFlagRequired("name")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Показать клиентов",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := clients()
+			if err != nil {
+				return err
+			}
+			all, err := store.ListClients(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, c := range all {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\n", c.ID, c.Name, c.Email, c.Phone)
+			}
+			return nil
+		},
+	}
+
+	del := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Удалить клиента без посылок",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := clients()
+			if err != nil {
+				return err
+			}
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid client id %q", args[0])
+			}
+			return store.DeleteClient(cmd.Context(), id)
+		},
+	}
+
+	forgetter := func() (ClientForgetter, error) {
+		f, ok := a.backend.(ClientForgetter)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support forgetting clients", a.cfg.Driver)
+		}
+		return f, nil
+	}
+
+	forget := &cobra.Command{
+		Use:   "forget <id>",
+		Short: "Обезличить клиента и его посылки по запросу на удаление данных",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := forgetter()
+			if err != nil {
+				return err
+			}
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid client id %q", args[0])
+			}
+			e, err := store.ForgetClient(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Клиент %d обезличен: посылок %d, событий %d\n", e.Client, e.Parcels, e.Events)
+			return nil
+		},
+	}
+
+	erasures := &cobra.Command{
+		Use:   "erasures",
+		Short: "Показать журнал обезличиваний",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := forgetter()
+			if err != nil {
+				return err
+			}
+			all, err := store.ListErasures(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, e := range all {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%d\t%d\t%s\n", e.ID, e.Client, e.Parcels, e.Events, formatTime(e.ErasedAt))
+			}
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "client",
+		Short: "Клиенты, которым принадлежат посылки",
+	}
+	cmd.AddCommand(add, list, del, forget, erasures)
+
+	return cmd
+}
+
+func (a *cliApp) courierCmd() *cobra.Command {
+	couriers := func() (CourierStore, error) {
+		c, ok := a.backend.(CourierStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support couriers", a.cfg.Driver)
+		}
+		return c, nil
+	}
+	parseIDs := func(courier, number string) (int, int, error) {
+		courierID, err := strconv.Atoi(courier)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid courier id %q", courier)
+		}
+		n, err := parseNumber(number)
+		return courierID, n, err
+	}
+
+	var c Courier
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "Завести курьера",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := couriers()
+			if err != nil {
+				return err
+			}
+			id, err := store.AddCourier(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Курьер %s заведён с идентификатором %d\n", c.Name, id)
+			return nil
+		},
+	}
+	add.Flags().StringVar(&c.Name, "name", "", "имя курьера")
+	add.Flags().StringVar(&c.Phone, "phone", "", "номер телефона")
+	add.MarkFlagRequired("name")
+
+	assign := &cobra.Command{
+		Use:   "assign <courier> <number>",
+		Short: "Назначить посылку курьеру",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := couriers()
+			if err != nil {
+				return err
+			}
+			courierID, number, err := parseIDs(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return store.AssignCourier(cmd.Context(), number, courierID)
+		},
+	}
+
+	deliver := &cobra.Command{
+		Use:   "deliver <courier> <number>",
+		Short: "Отметить посылку доставленной курьером",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := couriers()
+			if err != nil {
+				return err
+			}
+			courierID, number, err := parseIDs(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return store.CompleteDelivery(cmd.Context(), courierID, number)
+		},
+	}
+
+	parcels := &cobra.Command{
+		Use:   "parcels <courier>",
+		Short: "Показать посылки курьера",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := couriers()
+			if err != nil {
+				return err
+			}
+			courierID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid courier id %q", args[0])
+			}
+			list, err := store.GetByCourier(cmd.Context(), courierID)
+			if err != nil {
+				return err
+			}
+			for _, p := range list {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\n", p.Number, p.Status, p.RecipientAddress)
+			}
+			return nil
+		},
+	}
+
+	var day, order, start string
+	var show bool
+	manifest := &cobra.Command{
+		Use:   "manifest <courier>",
+		Short: "Построить маршрутный лист курьера на день и вывести его в JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, ok := a.backend.(ManifestStore)
+			if !ok {
+				return fmt.Errorf("storage %s does not support manifests", a.cfg.Driver)
+			}
+			courierID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid courier id %q", args[0])
+			}
+			date := time.Now()
+			if day != "" {
+				if date, err = time.ParseInLocation(dayLayout, day, time.Local); err != nil {
+					return fmt.Errorf("invalid date %q", day)
+				}
+			}
+			opts := ManifestOptions{Order: ManifestOrder(order)}
+			if start != "" {
+				lat, lon, ok := strings.Cut(start, ",")
+				opts.Start.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64)
+				if err == nil {
+					opts.Start.Lon, err = strconv.ParseFloat(strings.TrimSpace(lon), 64)
+				}
+				if !ok || err != nil {
+					return fmt.Errorf("invalid start %q, want lat,lon", start)
+				}
+			}
+
+			var m Manifest
+			if show {
+				m, err = store.GetManifest(cmd.Context(), courierID, date)
+			} else {
+				m, err = store.BuildManifest(cmd.Context(), courierID, date, opts)
+			}
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(m)
+		},
+	}
+	manifest.Flags().StringVar(&day, "date", "", "день маршрута в формате 2006-01-02, по умолчанию сегодня")
+	manifest.Flags().StringVar(&order, "order", string(ManifestByPostcode), "порядок остановок: postcode или distance")
+	manifest.Flags().StringVar(&start, "start", "", "точка выезда курьера lat,lon для порядка distance")
+	manifest.Flags().BoolVar(&show, "show", false, "вывести построенный ранее лист, не перестраивая его")
+
+	cmd := &cobra.Command{
+		Use:   "courier",
+		Short: "Курьеры и доставка на последней миле",
+	}
+	cmd.AddCommand(add, assign, deliver, parcels, manifest)
+
+	return cmd
+}
+
+func (a *cliApp) routeCmd() *cobra.Command {
+	routes := func() (RouteStore, error) {
+		r, ok := a.backend.(RouteStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support routes", a.cfg.Driver)
+		}
+		return r, nil
+	}
+
+	var l Location
+	location := &cobra.Command{
+		Use:   "location",
+		Short: "Завести склад, сортировочный центр или пункт выдачи",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := routes()
+			if err != nil {
+				return err
+			}
+			if _, err := store.AddLocation(cmd.Context(), l); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Пункт %s заведён\n", l.Code)
+			return nil
+		},
+	}
+	location.Flags().StringVar(&l.Code, "code", "", "код пункта")
+	location.Flags().StringVar(&l.Name, "name", "", "название пункта")
+	location.Flags().StringVar(&l.Kind, "kind", LocationWarehouse, "вид: warehouse, hub или pickup")
+	location.MarkFlagRequired("code")
+	location.MarkFlagRequired("name")
+
+	var at string
+	add := &cobra.Command{
+		Use:   "add <number> <code>",
+		Short: "Отметить прибытие посылки в пункт",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := routes()
+			if err != nil {
+				return err
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			arrivedAt := time.Now()
+			if at != "" {
+				if arrivedAt, err = time.Parse(time.RFC3339, at); err != nil {
+					return fmt.Errorf("invalid time %q: %w", at, err)
+				}
+			}
+
+			l, err := store.GetLocation(cmd.Context(), args[1])
+			if err != nil {
+				return err
+			}
+			_, err = store.AddCheckpoint(cmd.Context(), number, l.ID, arrivedAt)
+			return err
+		},
+	}
+	add.Flags().StringVar(&at, "at", "", "время прибытия в RFC3339, по умолчанию сейчас")
+
+	show := &cobra.Command{
+		Use:   "show <number>",
+		Short: "Показать маршрут посылки",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := routes()
+			if err != nil {
+				return err
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			route, err := store.GetRoute(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			for _, c := range route {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", formatTime(c.ArrivedAt), c.Location.Code, c.Location.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "route",
+		Short: "Маршрут посылок по складам и сортировочным центрам",
+	}
+	cmd.AddCommand(location, add, show)
+
+	return cmd
+}
+
+func (a *cliApp) geoCmd() *cobra.Command {
+	var lat, lon, radius float64
+	near := &cobra.Command{
+		Use:   "near",
+		Short: "Найти посылки с адресом в радиусе от точки",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			geo, ok := a.backend.(GeoStore)
+			if !ok {
+				return fmt.Errorf("storage %s does not support geocoding", a.cfg.Driver)
+			}
+			parcels, err := geo.GetNear(cmd.Context(), lat, lon, radius)
+			if err != nil {
+				return err
+			}
+			for _, p := range parcels {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%.0f м\t%.6f,%.6f\t%s\n",
+					p.Number, p.Distance, p.Coordinates.Lat, p.Coordinates.Lon, p.RecipientAddress)
+			}
+			return nil
+		},
+	}
+	near.Flags().Float64Var(&lat, "lat", 0, "широта точки")
+	near.Flags().Float64Var(&lon, "lon", 0, "долгота точки")
+	near.Flags().Float64Var(&radius, "radius", 1000, "радиус поиска в метрах")
+	near.MarkFlagRequired("lat")
+	near.MarkFlagRequired("lon")
+
+	locate := &cobra.Command{
+		Use:   "locate <number>",
+		Short: "Определить координаты адреса посылки через геокодер",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			geo, ok := a.backend.(GeoStore)
+			geocoder := a.cfg.Geocoding.Geocoder()
+			if !ok || geocoder == nil {
+				return fmt.Errorf("geocoding is not configured for storage %s", a.cfg.Driver)
+			}
+			number, err := parseNumber(args[0])
+			if err != nil {
+				return err
+			}
+			p, err := a.service.Get(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+			c, err := geocoder.Geocode(cmd.Context(), p.RecipientAddress)
+			if err != nil {
+				return err
+			}
+			if err := geo.SetCoordinates(cmd.Context(), number, c); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%.6f,%.6f\n", c.Lat, c.Lon)
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "geo",
+		Short: "Координаты адресов посылок",
+	}
+	cmd.AddCommand(near, locate)
+
+	return cmd
+}
+
+func (a *cliApp) priceCmd() *cobra.Command {
+	rates := func() (PriceStore, error) {
+		store, ok := a.backend.(PriceStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support pricing", a.cfg.Driver)
+		}
+		return store, nil
+	}
+
+	var rate PriceRate
+	set := &cobra.Command{
+		Use:   "set",
+		Short: "Задать цену доставки в зону до указанного веса",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := rates()
+			if err != nil {
+				return err
+			}
+			return store.SetPriceRate(cmd.Context(), rate)
+		},
+	}
+	set.Flags().StringVar(&rate.Zone, "zone", "", "зона из настроек eta, пустая — адреса вне зон")
+	set.Flags().IntVar(&rate.MaxWeightGrams, "max-weight", 0, "верхняя граница оплачиваемого веса в граммах")
+	set.Flags().Int64Var(&rate.Price, "price", 0, "цена в копейках")
+	set.MarkFlagRequired("max-weight")
+	set.MarkFlagRequired("price")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Показать тарифную сетку",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := rates()
+			if err != nil {
+				return err
+			}
+			list, err := store.ListPriceRates(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, r := range list {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\tдо %d г\t%s\n", r.Zone, r.MaxWeightGrams, formatPrice(r.Price))
+			}
+			return nil
+		},
+	}
+
+	var p Parcel
+	quote := &cobra.Command{
+		Use:   "quote",
+		Short: "Рассчитать стоимость доставки до регистрации посылки",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := rates()
+			if err != nil {
+				return err
+			}
+			q, err := NewPricer(store, NewDeliveryEstimator(a.cfg.ETA)).Quote(cmd.Context(), p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "зона %q, оплачиваемый вес %d г: %s\n", q.Zone, q.ChargeableWeight, formatPrice(q.Price))
+			return nil
+		},
+	}
+	quote.Flags().StringVar(&p.RecipientAddress, "address", "", "адрес доставки")
+	quote.Flags().IntVar(&p.WeightGrams, "weight", 0, "вес в граммах")
+	quote.Flags().IntVar(&p.LengthMM, "length", 0, "длина в миллиметрах")
+	quote.Flags().IntVar(&p.WidthMM, "width", 0, "ширина в миллиметрах")
+	quote.Flags().IntVar(&p.HeightMM, "height", 0, "высота в миллиметрах")
+	quote.MarkFlagRequired("address")
+
+	cmd := &cobra.Command{
+		Use:   "price",
+		Short: "Тарифы и стоимость доставки",
+	}
+	cmd.AddCommand(set, list, quote)
+
+	return cmd
+}
+
+func (a *cliApp) apiKeyCmd() *cobra.Command {
+	keyStore := func() (KeyStore, error) {
+		store, ok := a.backend.(KeyStore)
+		if !ok {
+			return nil, fmt.Errorf("storage %s does not support api keys", a.cfg.Driver)
+		}
+		return store, nil
+	}
+
+	var name, tenant string
+	var scopes []string
+	issue := &cobra.Command{
+		Use:   "issue",
+		Short: "Выпустить API-ключ; ключ показывается только один раз",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := keyStore()
+			if err != nil {
+				return err
+			}
+			key, secret, err := store.IssueAPIKey(cmd.Context(), APIKey{Name: name, Tenant: tenant, Scopes: scopes})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Ключ № %d %q с правами %s:\n%s\n",
+				key.ID, key.Name, strings.Join(key.Scopes, ","), secret)
+			return nil
+		},
+	}
+	issue.Flags().StringVar(&name, "name", "", "кому выдан ключ, например имя интеграции")
+	issue.Flags().StringVar(&tenant, "tenant", "", "арендатор, посылками которого ограничен ключ; без него ключ видит всех")
+	issue.Flags().StringSliceVar(&scopes, "scope", []string{ScopeRead}, "права: read, write или admin")
+	issue.MarkFlagRequired("name")
+
+	revoke := &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Отозвать API-ключ",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := keyStore()
+			if err != nil {
+				return err
+			}
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid api key id %q", args[0])
+			}
+			return store.RevokeAPIKey(cmd.Context(), id)
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Показать API-ключи",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := keyStore()
+			if err != nil {
+				return err
+			}
+			keys, err := store.ListAPIKeys(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				state := "действует"
+				if !k.RevokedAt.IsZero() {
+					state = "отозван " + formatTime(k.RevokedAt)
+				}
+				tenant := k.Tenant
+				if tenant == "" {
+					tenant = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\t%s\n", k.ID, k.Name, tenant, strings.Join(k.Scopes, ","), state)
+			}
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "API-ключи для HTTP и gRPC API",
+	}
+	cmd.AddCommand(issue, revoke, list)
+
+	return cmd
+}
+
+func (a *cliApp) encryptionCmd() *cobra.Command {
+	keygen := &cobra.Command{
+		Use:   "keygen",
+		Short: "Сгенерировать ключ для encryption.keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), base64.StdEncoding.EncodeToString(key))
+			return nil
+		},
+	}
+
+	rotate := &cobra.Command{
+		Use:   "rotate",
+		Short: "Перешифровать адреса активным ключом encryption.active",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			r, ok := a.backend.(EncryptionRotator)
+			if !ok {
+				return fmt.Errorf("storage %s does not support encryption", a.cfg.Driver)
+			}
+			n, err := r.RotateEncryption(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Перешифровано строк: %d\n", n)
+			return nil
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Шифрование адресов в БД",
+	}
+	cmd.AddCommand(keygen, rotate)
+
+	return cmd
+}
+
+func parseNumber(s string) (int, error) {
+	number, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parcel number %q", s)
+	}
+	return number, nil
+}
+
+// printParcelEvent печатает событие Watch о посылке.
+func printParcelEvent(cmd *cobra.Command, e ParcelEvent) {
+	switch e.Type {
+	case EventStatusChanged:
+		fmt.Fprintf(cmd.OutOrStdout(), "У посылки № %d новый статус: %s\n", e.Number, Status(e.Status).DisplayName(LangRU))
+	case EventAddressChanged:
+		fmt.Fprintf(cmd.OutOrStdout(), "У посылки № %d новый адрес: %s\n", e.Number, e.RecipientAddress)
+	case EventParcelDeleted:
+		fmt.Fprintf(cmd.OutOrStdout(), "Посылка № %d удалена\n", e.Number)
+	}
+}
+
+func printParcel(cmd *cobra.Command, p Parcel) {
+	fmt.Fprintf(cmd.OutOrStdout(), "Посылка № %d на адрес %s от клиента с идентификатором %d зарегистрирована %s, статус %s\n",
+		p.Number, p.RecipientAddress, p.Client, formatTime(p.CreatedAt), p.Status.DisplayName(LangRU))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI выполняет parcelctl над базой dsn с аргументами и возвращает вывод
+func runCLI(t *testing.T, dsn string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"--dsn", dsn}, args...))
+
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+// TestCLI проверяет подкоманды parcelctl
+func TestCLI(t *testing.T) {
+	// prepare
+	db, dsn := openTempDBFile(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+
+	parcel := getTestParcel()
+	parcel.Client = randRange.Intn(10_000_000)
+
+	// client add
+	client := strconv.Itoa(parcel.Client)
+	out, err := runCLI(t, dsn, "client", "add", "--id", client, "--name", "test client")
+	require.NoError(t, err)
+	require.Contains(t, out, "с идентификатором "+client)
+
+	out, err = runCLI(t, dsn, "client", "list")
+	require.NoError(t, err)
+	require.Contains(t, out, client+"\ttest client")
+
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	number := strconv.Itoa(id)
+
+	// get
+	out, err = runCLI(t, dsn, "get", number)
+	require.NoError(t, err)
+	require.Contains(t, out, "Посылка № "+number)
+
+	// set-address
+	_, err = runCLI(t, dsn, "set-address", number, "new test address")
+	require.NoError(t, err)
+
+	// list
+	out, err = runCLI(t, dsn, "list", "--client", strconv.Itoa(parcel.Client))
+	require.NoError(t, err)
+	require.Contains(t, out, "всего 1")
+	require.Contains(t, out, "new test address")
+
+	// set-status
+	_, err = runCLI(t, dsn, "set-status", number, ParcelStatusSent.String())
+	require.NoError(t, err)
+
+	// delete sent parcel
+	_, err = runCLI(t, dsn, "delete", number)
+	require.ErrorIs(t, err, ErrDeleteNotAllowed)
+
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+
+	// archive
+	out, err = runCLI(t, dsn, "archive", "--older-than", "8760h")
+	require.NoError(t, err)
+	require.Contains(t, out, "В архив перенесено посылок")
+
+	// export
+	out, err = runCLI(t, dsn, "export", "--client", strconv.Itoa(parcel.Client))
+	require.NoError(t, err)
+	require.Contains(t, out, number+","+strconv.Itoa(parcel.Client)+","+ParcelStatusSent.String())
+
+	// export and import json
+	out, err = runCLI(t, dsn, "export", "--format", "json", "--client", strconv.Itoa(parcel.Client))
+	require.NoError(t, err)
+	dump := filepath.Join(t.TempDir(), "parcels.jsonl")
+	require.NoError(t, os.WriteFile(dump, []byte(out), 0o600))
+
+	out, err = runCLI(t, dsn, "import", dump)
+	require.NoError(t, err)
+	require.Contains(t, out, "Загружено посылок: 0, пропущено как дубликаты: 1")
+
+	// backup
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	out, err = runCLI(t, dsn, "backup", "create", backup)
+	require.NoError(t, err)
+	require.Contains(t, out, "Копия базы сохранена")
+	require.FileExists(t, backup)
+
+	// invalid number
+	_, err = runCLI(t, dsn, "get", "abc")
+	require.Error(t, err)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Client — клиент, которому принадлежат посылки. Parcel.Client ссылается
+// на Client.ID внешним ключом, поэтому посылку нельзя добавить
+// несуществующему клиенту, а клиента с посылками нельзя удалить.
+type Client struct {
+	ID    int
+	Name  string
+	Email string
+	Phone string
+}
+
+// ParcelWithClient — посылка вместе с данными её клиента.
+type ParcelWithClient struct {
+	Parcel Parcel
+	Client Client
+}
+
+// ClientStore хранит клиентов. Реализуется только SQL-хранилищем:
+// целостность ссылок из parcel обеспечивает внешний ключ БД.
+type ClientStore interface {
+	AddClient(ctx context.Context, c Client) (int, error)
+	GetClient(ctx context.Context, id int) (Client, error)
+	ListClients(ctx context.Context) ([]Client, error)
+	UpdateClient(ctx context.Context, c Client) error
+	DeleteClient(ctx context.Context, id int) error
+	GetParcelsWithClient(ctx context.Context, filter Filter) ([]ParcelWithClient, error)
+}
+
+var _ ClientStore = ParcelStore{}
+
+// ErrNoClientStore возвращают методы клиентов сервиса без справочника клиентов.
+var ErrNoClientStore = errors.New("parcel service has no client store")
+
+// WithClients возвращает копию сервиса со справочником клиентов clients.
+func (s ParcelService) WithClients(clients ClientStore) ParcelService {
+	s.clients = clients
+	return s
+}
+
+// GetClient возвращает клиента по идентификатору. Справочник общий для всех
+// арендаторов, поэтому запрос от имени арендатора видит только клиентов
+// с его посылками, остальные для него не существуют.
+func (s ParcelService) GetClient(ctx context.Context, id int) (Client, error) {
+	if s.clients == nil {
+		return Client{}, ErrNoClientStore
+	}
+	if TenantFromContext(ctx) != "" {
+		page, err := s.store.List(ctx, Filter{Client: id}, 0, 1)
+		if err != nil {
+			return Client{}, err
+		}
+		if len(page.Parcels) == 0 {
+			return Client{}, ErrClientNotFound
+		}
+	}
+	return s.clients.GetClient(ctx, id)
+}
+
+// ListClients возвращает всех клиентов по возрастанию идентификатора.
+// Арендатору весь справочник не отдаётся: ErrForbidden.
+func (s ParcelService) ListClients(ctx context.Context) ([]Client, error) {
+	if s.clients == nil {
+		return nil, ErrNoClientStore
+	}
+	if TenantFromContext(ctx) != "" {
+		return nil, ErrForbidden
+	}
+	return s.clients.ListClients(ctx)
+}
+
+const clientColumns = "clients.id, clients.name, clients.email, clients.phone"
+
+// AddClient добавляет клиента и возвращает его идентификатор.
+// Если c.ID не равен нулю, клиент получает этот идентификатор.
+func (s ParcelStore) AddClient(ctx context.Context, c Client) (int, error) {
+	if c.ID == 0 {
+		return s.insert(ctx, "INSERT INTO clients (name, email, phone) VALUES (?, ?, ?)", "id",
+			c.Name, c.Email, c.Phone)
+	}
+
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"INSERT INTO clients (id, name, email, phone) VALUES (?, ?, ?, ?)"),
+		c.ID, c.Name, c.Email, c.Phone)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.dialect.name == "postgres" {
+		_, err = s.q.ExecContext(ctx,
+			"SELECT setval(pg_get_serial_sequence('clients', 'id'), MAX(id)) FROM clients")
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return c.ID, nil
+}
+
+func (s ParcelStore) GetClient(ctx context.Context, id int) (Client, error) {
+	c := Client{}
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT "+clientColumns+" FROM clients WHERE id = ?"),
+		id).Scan(&c.ID, &c.Name, &c.Email, &c.Phone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, ErrClientNotFound
+	}
+
+	return c, err
+}
+
+// ListClients возвращает всех клиентов по возрастанию идентификатора.
+func (s ParcelStore) ListClients(ctx context.Context) ([]Client, error) {
+	rows, err := s.q.QueryContext(ctx, "SELECT "+clientColumns+" FROM clients ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Client
+	for rows.Next() {
+		c := Client{}
+		if err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Phone); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// UpdateClient сохраняет имя и контакты клиента c.ID.
+func (s ParcelStore) UpdateClient(ctx context.Context, c Client) error {
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE clients SET name = ?, email = ?, phone = ? WHERE id = ?"),
+		c.Name, c.Email, c.Phone, c.ID)
+	if err != nil {
+		return err
+	}
+
+	// MySQL не считает строки, значения в которых не изменились
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = s.GetClient(ctx, c.ID)
+	return err
+}
+
+// DeleteClient удаляет клиента без посылок, в том числе удалённых через
+// Delete; иначе возвращает ErrClientHasParcels.
+func (s ParcelStore) DeleteClient(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		var n int
+		err := tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+			"SELECT COUNT(*) FROM parcel WHERE client = ?"), id).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrClientHasParcels
+		}
+
+		res, err := tx.q.ExecContext(ctx, tx.dialect.rebind("DELETE FROM clients WHERE id = ?"), id)
+		if err != nil {
+			return err
+		}
+		deleted, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrClientNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetParcelsWithClient возвращает посылки, подходящие под filter,
+// вместе с данными их клиентов, по возрастанию номера.
+func (s ParcelStore) GetParcelsWithClient(ctx context.Context, filter Filter) ([]ParcelWithClient, error) {
+	if err := s.checkFilter(filter); err != nil {
+		return nil, err
+	}
+	where, args := filter.where(s.dialect)
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT "+parcelColumns+", "+clientColumns+
+			" FROM parcel JOIN clients ON clients.id = parcel.client"+where+" ORDER BY number"),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []ParcelWithClient
+	for rows.Next() {
+		r := ParcelWithClient{}
+		p := &r.Parcel
+		err := rows.Scan(&p.Number, &p.Client, &p.Status, &p.SenderAddress, &p.RecipientAddress, scanTime{&p.CreatedAt}, scanTime{&p.UpdatedAt},
+			&p.Version, &p.TrackCode, &p.UUID, scanTime{&p.ETA}, &p.WeightGrams, &p.LengthMM, &p.WidthMM, &p.HeightMM, &p.Price,
+			&p.Amount, &p.Currency, &p.PaymentStatus, scanTime{&p.PaidAt}, &p.PaymentRef,
+			&p.ServiceClass, scanTime{&p.SLADeadline}, scanTime{&p.SLABreachedAt}, &p.ReturnOf,
+			&p.CancelReason, scanTime{&p.CancelledAt}, &p.SignedBy, scanTime{&p.SignedAt},
+			&r.Client.ID, &r.Client.Name, &r.Client.Email, &r.Client.Phone)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.openParcel(p); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// clientError заменяет нарушение внешнего ключа parcel.client
+// на ErrClientNotFound.
+func clientError(err error) error {
+	if isForeignKeyViolation(err) {
+		return ErrClientNotFound
+	}
+	return err
+}
+
+// isForeignKeyViolation сообщает, что запись нарушила внешний ключ.
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23503"
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1452
+	}
+
+	return false
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// openClientStore открывает временную базу с проверкой внешних ключей
+func openClientStore(t *testing.T) ParcelStore {
+	t.Helper()
+
+	store, err := OpenSQLite(filepath.Join(t.TempDir(), "clients.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	require.NoError(t, store.Migrate(context.Background()))
+
+	return store
+}
+
+// TestClientStore проверяет добавление, чтение, изменение и удаление клиентов
+func TestClientStore(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t)
+
+	// add
+	id, err := store.AddClient(ctx, Client{Name: "Иван", Email: "ivan@example.com"})
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	fixed, err := store.AddClient(ctx, Client{ID: 5000, Name: "Пётр", Phone: "+79990000000"})
+	require.NoError(t, err)
+	require.Equal(t, 5000, fixed)
+
+	// get
+	c, err := store.GetClient(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, Client{ID: id, Name: "Иван", Email: "ivan@example.com"}, c)
+
+	_, err = store.GetClient(ctx, 999999)
+	require.ErrorIs(t, err, ErrClientNotFound)
+
+	// update
+	c.Phone = "+79991112233"
+	require.NoError(t, store.UpdateClient(ctx, c))
+	require.NoError(t, store.UpdateClient(ctx, c))
+	require.ErrorIs(t, store.UpdateClient(ctx, Client{ID: 999999}), ErrClientNotFound)
+
+	clients, err := store.ListClients(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Client{c, {ID: 5000, Name: "Пётр", Phone: "+79990000000"}}, clients)
+
+	// delete
+	require.NoError(t, store.DeleteClient(ctx, fixed))
+	require.ErrorIs(t, store.DeleteClient(ctx, fixed), ErrClientNotFound)
+}
+
+// TestClientForeignKey проверяет, что посылки не ссылаются на несуществующих клиентов
+func TestClientForeignKey(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t)
+
+	parcel := getTestParcel()
+	_, err := store.AddClient(ctx, Client{ID: parcel.Client, Name: "test"})
+	require.NoError(t, err)
+
+	// check
+	orphan := getTestParcel()
+	orphan.Client = parcel.Client + 1
+	_, err = store.Add(ctx, orphan)
+	require.ErrorIs(t, err, ErrClientNotFound)
+	_, err = store.AddBatch(ctx, []Parcel{getTestParcel(), orphan})
+	require.ErrorIs(t, err, ErrClientNotFound)
+
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	p.Client = orphan.Client
+	require.ErrorIs(t, store.Update(ctx, p), ErrClientNotFound)
+
+	// клиента с посылками, даже удалёнными, удалить нельзя
+	require.NoError(t, store.Delete(ctx, id))
+	require.ErrorIs(t, store.DeleteClient(ctx, parcel.Client), ErrClientHasParcels)
+
+	require.NoError(t, store.HardDelete(ctx, id))
+	require.NoError(t, store.DeleteClient(ctx, parcel.Client))
+}
+
+// TestGetParcelsWithClient проверяет выборку посылок с данными клиентов
+func TestGetParcelsWithClient(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t).WithClock(testClock)
+
+	first := Client{ID: 1, Name: "Иван", Email: "ivan@example.com"}
+	second := Client{ID: 2, Name: "Пётр"}
+	for _, c := range []Client{first, second} {
+		_, err := store.AddClient(ctx, c)
+		require.NoError(t, err)
+	}
+
+	var want []ParcelWithClient
+	for _, c := range []Client{first, second, first} {
+		parcel := getTestParcel()
+		parcel.Client = c.ID
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		parcel.Number = id
+		parcel.Version = 1
+		want = append(want, ParcelWithClient{Parcel: parcel, Client: c})
+	}
+
+	// check
+	got, err := store.GetParcelsWithClient(ctx, Filter{})
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	got, err = store.GetParcelsWithClient(ctx, Filter{Client: first.ID})
+	require.NoError(t, err)
+	require.Equal(t, []ParcelWithClient{want[0], want[2]}, got)
+}
+
+// TestClientMigration проверяет, что миграция заводит клиентов уже существующих посылок
+func TestClientMigration(t *testing.T) {
+	// prepare: схема до появления клиентов
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "old.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations, err := loadMigrations(sqliteDialect.name)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx,
+		"CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at VARCHAR(64) NOT NULL)")
+	require.NoError(t, err)
+	for _, m := range migrations {
+		if m.version >= 12 {
+			break
+		}
+		for _, stmt := range splitStatements(m.sql) {
+			_, err := db.ExecContext(ctx, stmt)
+			require.NoError(t, err)
+		}
+		_, err := db.ExecContext(ctx, "INSERT INTO schema_migrations (version, applied_at) VALUES (?, '')", m.version)
+		require.NoError(t, err)
+	}
+
+	// посылки пишутся в схему того времени, а не через текущий Add
+	store := NewParcelStore(db)
+	var numbers []int
+	for _, client := range []int{7, 8, 7} {
+		res, err := db.ExecContext(ctx,
+			"INSERT INTO parcel (client, status, address, created_at, updated_at, track_code) VALUES (?, ?, ?, ?, ?, ?)",
+			client, ParcelStatusRegistered, "test", formatTime(testCreatedAt), formatTime(testCreatedAt), NewTrackCode())
+		require.NoError(t, err)
+		id, err := res.LastInsertId()
+		require.NoError(t, err)
+		numbers = append(numbers, int(id))
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM parcel WHERE number = ?", numbers[2])
+	require.NoError(t, err)
+
+	// migrate
+	require.NoError(t, store.Migrate(ctx))
+
+	// check
+	clients, err := store.ListClients(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Client{{ID: 7}, {ID: 8}}, clients)
+
+	p, err := store.Get(ctx, numbers[1])
+	require.NoError(t, err)
+	require.Equal(t, 8, p.Client)
+	// прежний адрес становится адресом получателя
+	require.Equal(t, "test", p.RecipientAddress)
+	require.Empty(t, p.SenderAddress)
+
+	// номер удалённой посылки не выдаётся повторно
+	parcel := getTestParcel()
+	parcel.Client = 7
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.Greater(t, id, numbers[2])
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config описывает подключение к хранилищу и общие настройки приложения.
+// Значения берутся по возрастанию приоритета: значения по умолчанию,
+// YAML-файл, переменные окружения PARCEL_*, флаги командной строки.
+type Config struct {
+	Driver   string     `yaml:"driver"`
+	DSN      string     `yaml:"dsn"`
+	Pool     PoolConfig `yaml:"pool"`
+	LogLevel string     `yaml:"log_level"`
+	// Replica направляет чтения на реплику БД, см. ParcelStore.WithReplica
+	Replica ReplicaConfig `yaml:"replica"`
+	// Shards — DSN сегментов вместо одной БД DSN, см. ShardedStorage
+	Shards []string `yaml:"shards"`
+	// Partitions раскладывает посылки по месячным БД, см. PartitionedStorage
+	Partitions PartitionConfig `yaml:"partitions"`
+	// Retry включает повторы записи при MaxAttempts больше 1
+	Retry RetryPolicy `yaml:"retry"`
+	// KeyMode задаёт ключи новых посылок: int или uuid, см. KeyModeUUID
+	KeyMode KeyMode `yaml:"key_mode"`
+	// Notify настраивает уведомления клиентов о смене статуса
+	Notify NotifyConfig `yaml:"notify"`
+	// Events включает публикацию событий посылок в NATS или Kafka
+	Events EventsConfig `yaml:"events"`
+	// ETA задаёт тариф для расчёта ожидаемой даты доставки
+	ETA Tariff `yaml:"eta"`
+	// Geocoding включает поиск координат адресов для GetNear
+	Geocoding GeocodingConfig `yaml:"geocoding"`
+	// Pricing включает расчёт стоимости доставки по таблице price_rates
+	Pricing PricingConfig `yaml:"pricing"`
+	// Payment задаёт правила оплаты, например запрет отправки без оплаты
+	Payment PaymentConfig `yaml:"payment"`
+	// RateLimit ограничивает частоту запросов к HTTP и gRPC API
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// Auth включает проверку API-ключей, см. parcelctl apikey
+	Auth AuthConfig `yaml:"auth"`
+	// Encryption включает шифрование адресов в БД, см. parcelctl encryption
+	Encryption EncryptionConfig `yaml:"encryption"`
+	// Retention задаёт сроки хранения посылок, см. parcelctl retention
+	Retention RetentionConfig `yaml:"retention"`
+	// Jobs задаёт автоматические задания над посылками, см. parcelctl jobs
+	Jobs JobsConfig `yaml:"jobs"`
+	// SLA задаёт сроки доставки по классам обслуживания, см. parcelctl sla
+	SLA SLAConfig `yaml:"sla"`
+	// Maintenance включает обслуживание БД по расписанию, см. parcelctl maintenance
+	Maintenance MaintenanceConfig `yaml:"maintenance"`
+	// Cache включает кеш посылок для Get в API-сервере, см. CachedStorage
+	Cache CacheConfig `yaml:"cache"`
+	// Attachments включает подтверждения доставки, см. parcelctl attachment
+	Attachments AttachmentConfig `yaml:"attachments"`
+	// Attempts задаёт возврат отправителю после неудачных попыток доставки
+	Attempts AttemptConfig `yaml:"attempts"`
+	// ShutdownTimeout ограничивает остановку API-сервера по SIGTERM,
+	// ноль означает 30 секунд, см. Server.Run
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ReadHeaderTimeout ограничивает чтение заголовков запроса к HTTP API,
+	// ноль означает 10 секунд, см. Server.WithReadHeaderTimeout
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+}
+
+// NotifyConfig задаёт каналы уведомлений и контакты клиентов.
+// Канал включается, если задан его адрес: smtp.addr или sms.account_sid.
+type NotifyConfig struct {
+	SMTP    SMTPConfig      `yaml:"smtp"`
+	SMS     SMSConfig       `yaml:"sms"`
+	Clients map[int]Contact `yaml:"clients"`
+}
+
+// Notifier возвращает включённые каналы или nil, если уведомления выключены.
+func (c NotifyConfig) Notifier() Notifier {
+	var ns Notifiers
+	if c.SMTP.Addr != "" {
+		ns = append(ns, NewEmailNotifier(c.SMTP))
+	}
+	if c.SMS.AccountSID != "" {
+		ns = append(ns, NewSMSNotifier(c.SMS))
+	}
+	if len(ns) == 0 || len(c.Clients) == 0 {
+		return nil
+	}
+	return ns
+}
+
+// PoolConfig задаёт настройки пула соединений sql.DB.
+// Нулевые значения оставляют настройки database/sql по умолчанию.
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+// apply применяет ненулевые настройки к пулу db.
+func (p PoolConfig) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+}
+
+// DefaultConfig возвращает настройки для локального tracker.db.
+func DefaultConfig() Config {
+	return Config{
+		Driver:   "sqlite",
+		DSN:      "tracker.db",
+		LogLevel: "info",
+		KeyMode:  KeyModeInt,
+	}
+}
+
+// LoadConfig собирает настройки из файла path (если он задан) и окружения.
+// getenv обычно os.Getenv; в тестах передаётся своя функция.
+func LoadConfig(path string, getenv func(string) string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(getenv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) applyEnv(getenv func(string) string) error {
+	if v := getenv("PARCEL_DRIVER"); v != "" {
+		c.Driver = v
+	}
+	if v := getenv("PARCEL_DSN"); v != "" {
+		c.DSN = v
+	}
+	if v := getenv("PARCEL_REPLICA_DSN"); v != "" {
+		c.Replica.DSN = v
+	}
+	if v := getenv("PARCEL_SHARDS"); v != "" {
+		c.Shards = strings.Split(v, ",")
+	}
+	if v := getenv("PARCEL_PARTITIONS_DSN"); v != "" {
+		c.Partitions.DSN = v
+	}
+	if v := getenv("PARCEL_MAINTENANCE_SCHEDULE"); v != "" {
+		c.Maintenance.Schedule = v
+	}
+	if v := getenv("PARCEL_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := getenv("PARCEL_KEY_MODE"); v != "" {
+		c.KeyMode = KeyMode(v)
+	}
+	c.Encryption.applyEnv(getenv)
+
+	var err error
+	if v := getenv("PARCEL_MAX_OPEN_CONNS"); v != "" {
+		if c.Pool.MaxOpenConns, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("PARCEL_MAX_OPEN_CONNS: %w", err)
+		}
+	}
+	if v := getenv("PARCEL_MAX_IDLE_CONNS"); v != "" {
+		if c.Pool.MaxIdleConns, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("PARCEL_MAX_IDLE_CONNS: %w", err)
+		}
+	}
+	if v := getenv("PARCEL_CONN_MAX_LIFETIME"); v != "" {
+		if c.Pool.ConnMaxLifetime, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("PARCEL_CONN_MAX_LIFETIME: %w", err)
+		}
+	}
+	if v := getenv("PARCEL_SHUTDOWN_TIMEOUT"); v != "" {
+		if c.ShutdownTimeout, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("PARCEL_SHUTDOWN_TIMEOUT: %w", err)
+		}
+	}
+	if v := getenv("PARCEL_READ_HEADER_TIMEOUT"); v != "" {
+		if c.ReadHeaderTimeout, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("PARCEL_READ_HEADER_TIMEOUT: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate проверяет, что настройки можно применить.
+func (c Config) Validate() error {
+	if c.Driver == "" {
+		return errors.New("config: driver is required")
+	}
+	if c.DSN == "" && c.Driver != "memory" && len(c.Shards) == 0 && !c.Partitions.enabled() {
+		return errors.New("config: dsn is required")
+	}
+	if c.Pool.MaxOpenConns < 0 || c.Pool.MaxIdleConns < 0 || c.Pool.ConnMaxLifetime < 0 {
+		return errors.New("config: pool settings must not be negative")
+	}
+	if c.Retry.MaxAttempts < 0 || c.Retry.BaseDelay < 0 || c.Retry.MaxDelay < 0 ||
+		c.Retry.Jitter < 0 || c.Retry.Jitter > 1 {
+		return errors.New("config: invalid retry policy")
+	}
+	if c.ShutdownTimeout < 0 {
+		return errors.New("config: shutdown timeout must not be negative")
+	}
+	if c.ReadHeaderTimeout < 0 {
+		return errors.New("config: read header timeout must not be negative")
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("config: unknown log level %q", c.LogLevel)
+	}
+	switch c.KeyMode {
+	case KeyModeInt, KeyModeUUID:
+	default:
+		return fmt.Errorf("config: unknown key mode %q", c.KeyMode)
+	}
+	if err := c.Events.validate(); err != nil {
+		return err
+	}
+	if err := c.ETA.validate(); err != nil {
+		return err
+	}
+	if err := c.RateLimit.validate(); err != nil {
+		return err
+	}
+	if err := c.Cache.validate(); err != nil {
+		return err
+	}
+	if err := c.Attempts.validate(); err != nil {
+		return err
+	}
+	if err := c.Attachments.validate(); err != nil {
+		return err
+	}
+	if err := c.Replica.validate(c.Driver); err != nil {
+		return err
+	}
+	if len(c.Shards) > 0 && (c.Driver == "memory" || c.Replica.DSN != "") {
+		return errors.New("config: shards need an SQL driver and no replica")
+	}
+	if err := c.Partitions.validate(c.Driver); err != nil {
+		return err
+	}
+	if c.Partitions.enabled() && (len(c.Shards) > 0 || c.Replica.DSN != "") {
+		return errors.New("config: partitions do not combine with shards or a replica")
+	}
+	if err := c.Encryption.validate(); err != nil {
+		return err
+	}
+	if err := c.Retention.validate(); err != nil {
+		return err
+	}
+	if err := c.Jobs.validate(); err != nil {
+		return err
+	}
+	if err := c.SLA.validate(); err != nil {
+		return err
+	}
+	if err := c.Maintenance.validate(c.Driver); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewParcelStoreFromConfig открывает БД по настройкам cfg, настраивает пул
+// и создаёт хранилище, зарегистрированное под именем cfg.Driver.
+// Возвращённую БД закрывает вызывающий; для хранилища memory она равна nil.
+func NewParcelStoreFromConfig(cfg Config) (ParcelStorage, *sql.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Driver == "memory" {
+		store, err := OpenStorage(cfg.Driver, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return withPaymentRequired(withKeyMode(store, cfg.KeyMode), cfg.Payment.RequiredForSending), nil, nil
+	}
+
+	if len(cfg.Shards) > 0 {
+		// у сегментов свои БД, общей для вызывающего нет
+		store, err := openShards(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, nil, nil
+	}
+
+	if cfg.Partitions.enabled() {
+		// у каждого раздела своя БД, как и у сегментов
+		store, err := NewPartitionedStorage(context.Background(), sqlitePartitions{cfg: cfg})
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, nil, nil
+	}
+
+	db, err := openDB(cfg, cfg.DSN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := OpenStorage(cfg.Driver, db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if store, err = withEncryption(store, cfg.Encryption); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if store, err = withReplica(store, cfg); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return withPaymentRequired(withKeyMode(store, cfg.KeyMode), cfg.Payment.RequiredForSending), db, nil
+}
+
+// openDB открывает БД драйвера cfg.Driver по dsn с настройками пула cfg.Pool.
+func openDB(cfg Config, dsn string) (*sql.DB, error) {
+	if cfg.Driver == "sqlite" {
+		// SQLite проверяет внешние ключи, только если их включило соединение
+		dsn = sqliteDSN(dsn, sqliteOptions{foreignKeys: true})
+	}
+
+	db, err := sql.Open(cfg.Driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Pool.apply(db)
+
+	return db, nil
+}
+
+// withReplica подключает реплику cfg.Replica к SQL-хранилищу.
+func withReplica(store ParcelStorage, cfg Config) (ParcelStorage, error) {
+	if cfg.Replica.DSN == "" {
+		return store, nil
+	}
+	s, ok := store.(ParcelStore)
+	if !ok {
+		return nil, errors.New("config: storage does not support replicas")
+	}
+	replica, err := openDB(cfg, cfg.Replica.DSN)
+	if err != nil {
+		return nil, err
+	}
+	s = s.WithReplica(replica)
+	if cfg.Replica.ReadYourWrites > 0 {
+		s = s.WithReadYourWrites(cfg.Replica.ReadYourWrites)
+	}
+	return s, nil
+}
+
+// withEncryption включает шифрование адресов у SQL-хранилища. Хранилища
+// из других пакетов, зарегистрированные через RegisterStorage, его
+// не поддерживают.
+func withEncryption(store ParcelStorage, cfg EncryptionConfig) (ParcelStorage, error) {
+	c, err := cfg.Cipher()
+	if err != nil || c == nil {
+		return store, err
+	}
+	s, ok := store.(ParcelStore)
+	if !ok {
+		return nil, errors.New("config: storage does not support encryption")
+	}
+	return s.WithCipher(c), nil
+}
+
+// withKeyMode включает режим ключей m у встроенных хранилищ.
+// Хранилища из других пакетов, зарегистрированные через RegisterStorage,
+// возвращаются как есть.
+func withKeyMode(store ParcelStorage, m KeyMode) ParcelStorage {
+	switch s := store.(type) {
+	case ParcelStore:
+		return s.WithKeyMode(m)
+	case *MemoryParcelStore:
+		s.SetKeyMode(m)
+	}
+	return store
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// env возвращает getenv поверх заданных переменных окружения
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+// TestLoadConfig проверяет приоритет файла и переменных окружения
+func TestLoadConfig(t *testing.T) {
+	// prepare
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+driver: postgres
+dsn: postgres://localhost/tracker
+log_level: debug
+key_mode: uuid
+pool:
+  max_open_conns: 10
+  conn_max_lifetime: 5m
+retry:
+  max_attempts: 3
+  base_delay: 20ms
+`), 0o600)
+	require.NoError(t, err)
+
+	// defaults
+	cfg, err := LoadConfig("", env(nil))
+	require.NoError(t, err)
+	require.Equal(t, DefaultConfig(), cfg)
+
+	// file
+	cfg, err = LoadConfig(path, env(nil))
+	require.NoError(t, err)
+	require.Equal(t, "postgres", cfg.Driver)
+	require.Equal(t, "debug", cfg.LogLevel)
+	require.Equal(t, KeyModeUUID, cfg.KeyMode)
+	require.Equal(t, 10, cfg.Pool.MaxOpenConns)
+	require.Equal(t, 5*time.Minute, cfg.Pool.ConnMaxLifetime)
+	require.Equal(t, RetryPolicy{MaxAttempts: 3, BaseDelay: 20 * time.Millisecond}, cfg.Retry)
+
+	// env overrides file
+	cfg, err = LoadConfig(path, env(map[string]string{
+		"PARCEL_DSN":                 "postgres://db/tracker",
+		"PARCEL_MAX_IDLE_CONNS":      "3",
+		"PARCEL_READ_HEADER_TIMEOUT": "5s",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "postgres://db/tracker", cfg.DSN)
+	require.Equal(t, 3, cfg.Pool.MaxIdleConns)
+	require.Equal(t, 5*time.Second, cfg.ReadHeaderTimeout)
+	require.Equal(t, 10, cfg.Pool.MaxOpenConns)
+
+	// invalid
+	_, err = LoadConfig("", env(map[string]string{"PARCEL_MAX_OPEN_CONNS": "many"}))
+	require.Error(t, err)
+
+	_, err = LoadConfig("", env(map[string]string{"PARCEL_LOG_LEVEL": "verbose"}))
+	require.Error(t, err)
+
+	_, err = LoadConfig("", env(map[string]string{"PARCEL_KEY_MODE": "serial"}))
+	require.Error(t, err)
+
+	_, err = LoadConfig("", env(map[string]string{"PARCEL_READ_HEADER_TIMEOUT": "-1s"}))
+	require.Error(t, err)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"), env(nil))
+	require.Error(t, err)
+}
+
+// TestNewParcelStoreFromConfig проверяет создание хранилища по настройкам
+func TestNewParcelStoreFromConfig(t *testing.T) {
+	// sqlite
+	cfg := DefaultConfig()
+	cfg.Pool.MaxOpenConns = 2
+
+	store, db, err := NewParcelStoreFromConfig(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+	require.IsType(t, ParcelStore{}, store)
+	require.Equal(t, 2, db.Stats().MaxOpenConnections)
+
+	// memory
+	cfg.Driver = "memory"
+	cfg.KeyMode = KeyModeUUID
+	store, db, err = NewParcelStoreFromConfig(cfg)
+	require.NoError(t, err)
+	require.Nil(t, db)
+	require.IsType(t, &MemoryParcelStore{}, store)
+
+	id, err := store.Add(context.Background(), getTestParcel())
+	require.NoError(t, err)
+	p, err := store.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.NotEmpty(t, p.UUID)
+
+	// unknown
+	cfg.Driver = "oracle"
+	_, _, err = NewParcelStoreFromConfig(cfg)
+	require.Error(t, err)
+}
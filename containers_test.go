@@ -0,0 +1,88 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// Образы СУБД для интеграционных тестов.
+const (
+	postgresImage = "postgres:16-alpine"
+	mysqlImage    = "mysql:8.4"
+)
+
+// TestMain под тегом integration поднимает PostgreSQL и MySQL
+// в контейнерах и передаёт их адреса тестам через POSTGRES_DSN и MYSQL_DSN,
+// так что тесты postgres_test.go, mysql_test.go и общие правила
+// хранилища из contract_test.go идут на всех СУБД. Заданная заранее
+// переменная оставляет свою БД и её контейнер не запускается:
+//
+//	go test -tags integration ./...
+func TestMain(m *testing.M) {
+	os.Exit(runWithContainers(m))
+}
+
+func runWithContainers(m *testing.M) (code int) {
+	ctx := context.Background()
+	// без Docker testcontainers паникует, а не возвращает ошибку
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintln(os.Stderr, "start containers:", r)
+			code = 1
+		}
+	}()
+
+	if os.Getenv("POSTGRES_DSN") == "" {
+		pg, err := postgres.Run(ctx, postgresImage,
+			postgres.WithDatabase("tracker"),
+			postgres.WithUsername("tracker"),
+			postgres.WithPassword("tracker"),
+			postgres.BasicWaitStrategies())
+		defer terminate(pg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "start postgres:", err)
+			return 1
+		}
+		dsn, err := pg.ConnectionString(ctx, "sslmode=disable")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "postgres dsn:", err)
+			return 1
+		}
+		os.Setenv("POSTGRES_DSN", dsn)
+	}
+
+	if os.Getenv("MYSQL_DSN") == "" {
+		my, err := mysql.Run(ctx, mysqlImage,
+			mysql.WithDatabase("tracker"),
+			mysql.WithUsername("tracker"),
+			mysql.WithPassword("tracker"))
+		defer terminate(my)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "start mysql:", err)
+			return 1
+		}
+		dsn, err := my.ConnectionString(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mysql dsn:", err)
+			return 1
+		}
+		os.Setenv("MYSQL_DSN", dsn)
+	}
+
+	return m.Run()
+}
+
+// terminate останавливает контейнер; nil-контейнер пропускается.
+func terminate(c testcontainers.Container) {
+	if err := testcontainers.TerminateContainer(c); err != nil {
+		fmt.Fprintln(os.Stderr, "terminate container:", err)
+	}
+}
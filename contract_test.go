@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testStorageContract проверяет общие правила ParcelStorage на хранилище,
+// которое возвращает open. Каждая проверка работает со своим клиентом,
+// поэтому open может отдавать одну и ту же БД с данными прошлых прогонов.
+func testStorageContract(t *testing.T, open func(t *testing.T) ParcelStorage) {
+	ctx := context.Background()
+	// add регистрирует посылку нового клиента client
+	add := func(t *testing.T, store ParcelStorage, client int) int {
+		parcel := getTestParcel()
+		parcel.Client = client
+		number, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		require.NotZero(t, number)
+		return number
+	}
+	newClient := func() int { return 1_000_000 + rand.IntN(1_000_000_000) }
+
+	t.Run("AddGet", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		parcel := getTestParcel()
+		parcel.Client = newClient()
+		parcel.SenderAddress = "sender"
+
+		// add
+		number, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+
+		// check
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, number, stored.Number)
+		require.Equal(t, parcel.Client, stored.Client)
+		require.Equal(t, ParcelStatusRegistered, stored.Status)
+		require.Equal(t, parcel.SenderAddress, stored.SenderAddress)
+		require.Equal(t, parcel.RecipientAddress, stored.RecipientAddress)
+		require.Equal(t, parcel.TrackCode, stored.TrackCode)
+		require.Equal(t, 1, stored.Version)
+		require.Equal(t, PaymentStatusUnpaid, stored.PaymentStatus)
+
+		byCode, err := store.GetByTrackCode(ctx, parcel.TrackCode)
+		require.NoError(t, err)
+		require.Equal(t, number, byCode.Number)
+
+		_, err = store.Get(ctx, -1)
+		require.ErrorIs(t, err, ErrParcelNotFound)
+		_, err = store.GetByTrackCode(ctx, NewTrackCode())
+		require.ErrorIs(t, err, ErrParcelNotFound)
+	})
+
+	t.Run("ByClient", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		client := newClient()
+		numbers := []int{add(t, store, client), add(t, store, client), add(t, store, client)}
+		require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+
+		// check
+		parcels, err := store.GetByClient(ctx, client)
+		require.NoError(t, err)
+		require.Len(t, parcels, 3)
+
+		sent, err := store.ListAll(ctx, Filter{Client: client, Statuses: []Status{ParcelStatusSent}}, ListOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []int{numbers[1]}, parcelNumbers(sent.Parcels))
+
+		page, err := store.ListByClient(ctx, client, ListOptions{Limit: 2, SortBy: SortByNumber})
+		require.NoError(t, err)
+		require.Equal(t, 3, page.Total)
+		require.Equal(t, numbers[:2], parcelNumbers(page.Parcels))
+
+		page, err = store.ListAll(ctx, Filter{Client: client, Statuses: []Status{ParcelStatusRegistered}}, ListOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 2, page.Total)
+
+		first, err := store.List(ctx, Filter{Client: client}, 0, 2)
+		require.NoError(t, err)
+		require.Equal(t, numbers[:2], parcelNumbers(first.Parcels))
+		require.NotEmpty(t, first.NextCursor)
+
+		after, err := DecodeCursor(first.NextCursor)
+		require.NoError(t, err)
+		rest, err := store.List(ctx, Filter{Client: client}, after, 2)
+		require.NoError(t, err)
+		require.Equal(t, numbers[2:], parcelNumbers(rest.Parcels))
+		require.Empty(t, rest.NextCursor)
+
+		_, err = store.ListByClient(ctx, client, ListOptions{SortBy: "password"})
+		require.ErrorIs(t, err, ErrInvalidSort)
+	})
+
+	t.Run("StatusRules", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		number := add(t, store, newClient())
+
+		// check
+		require.NoError(t, store.SetStatus(ctx, number, ParcelStatusSent))
+		require.ErrorIs(t, store.SetRecipientAddress(ctx, number, "new address"), ErrAddressChangeNotAllowed)
+		require.ErrorIs(t, store.SetSenderAddress(ctx, number, "new address"), ErrAddressChangeNotAllowed)
+		require.ErrorIs(t, store.Delete(ctx, number), ErrDeleteNotAllowed)
+
+		require.NoError(t, store.SetStatus(ctx, number, ParcelStatusDelivered))
+		require.ErrorIs(t, store.SetStatus(ctx, number, ParcelStatusRegistered), ErrInvalidTransition)
+		require.ErrorIs(t, store.SetStatus(ctx, -1, ParcelStatusSent), ErrParcelNotFound)
+
+		history, err := store.GetHistory(ctx, number)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, ParcelStatusRegistered, history[0].OldStatus)
+		require.Equal(t, ParcelStatusSent, history[0].NewStatus)
+		require.Equal(t, ParcelStatusDelivered, history[1].NewStatus)
+	})
+
+	t.Run("DeleteRestore", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		client := newClient()
+		number := add(t, store, client)
+
+		// delete
+		require.NoError(t, store.Delete(ctx, number))
+		_, err := store.Get(ctx, number)
+		require.ErrorIs(t, err, ErrParcelNotFound)
+		parcels, err := store.GetByClient(ctx, client)
+		require.NoError(t, err)
+		require.Empty(t, parcels)
+		require.ErrorIs(t, store.SetStatus(ctx, number, ParcelStatusSent), ErrParcelNotFound)
+
+		// restore
+		require.NoError(t, store.Restore(ctx, number))
+		_, err = store.Get(ctx, number)
+		require.NoError(t, err)
+
+		// hard delete
+		require.NoError(t, store.HardDelete(ctx, number))
+		require.ErrorIs(t, store.Restore(ctx, number), ErrParcelNotFound)
+		require.ErrorIs(t, store.HardDelete(ctx, number), ErrParcelNotFound)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		client := newClient()
+		number := add(t, store, client)
+		sent := add(t, store, client)
+		require.NoError(t, store.SetStatus(ctx, sent, ParcelStatusSent))
+
+		// cancel
+		require.ErrorIs(t, store.Cancel(ctx, number, "changed_mind"), ErrInvalidCancelReason)
+		require.NoError(t, store.Cancel(ctx, number, CancelCustomerRequest))
+		require.ErrorIs(t, store.Cancel(ctx, number, CancelOther), ErrCancelNotAllowed)
+		require.ErrorIs(t, store.Cancel(ctx, sent, CancelOther), ErrCancelNotAllowed)
+
+		// check
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, ParcelStatusCancelled, stored.Status)
+		require.Equal(t, CancelCustomerRequest, stored.CancelReason)
+		require.False(t, stored.CancelledAt.IsZero())
+		require.Equal(t, 2, stored.Version)
+
+		// отменённая посылка остаётся в выборках, в отличие от удалённой
+		page, err := store.ListAll(ctx, Filter{Client: client, Statuses: []Status{ParcelStatusCancelled}}, ListOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []int{number}, parcelNumbers(page.Parcels))
+		history, err := store.GetHistory(ctx, number)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.Equal(t, ParcelStatusCancelled, history[0].NewStatus)
+		require.ErrorIs(t, store.SetStatus(ctx, number, ParcelStatusSent), ErrInvalidTransition)
+	})
+
+	t.Run("Deliver", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		number := add(t, store, newClient())
+		sig := Signature{SignedBy: "Иванов И. И.", Image: testPNG}
+
+		// deliver
+		require.ErrorIs(t, store.Deliver(ctx, number, sig), ErrInvalidTransition)
+		require.NoError(t, store.SetStatus(ctx, number, ParcelStatusSent))
+		require.ErrorIs(t, store.Deliver(ctx, number, Signature{Image: testPNG}), ErrInvalidSignature)
+		require.ErrorIs(t, store.Deliver(ctx, number, Signature{SignedBy: "Иванов", Image: []byte("GIF89a")}), ErrInvalidSignature)
+		require.NoError(t, store.Deliver(ctx, number, sig))
+		require.ErrorIs(t, store.Deliver(ctx, -1, sig), ErrParcelNotFound)
+
+		// check
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, ParcelStatusDelivered, stored.Status)
+		require.True(t, stored.HasSignature())
+		require.Equal(t, "Иванов И. И.", stored.SignedBy)
+		history, err := store.GetHistory(ctx, number)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, ParcelStatusDelivered, history[1].NewStatus)
+		// повторная доставка не меняет сохранённую подпись
+		require.ErrorIs(t, store.Deliver(ctx, number, sig), ErrInvalidTransition)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		number := add(t, store, newClient())
+		parcel, err := store.Get(ctx, number)
+		require.NoError(t, err)
+
+		// update
+		stale := parcel
+		parcel.RecipientAddress = "new address"
+		require.NoError(t, store.Update(ctx, parcel))
+
+		// check
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, "new address", stored.RecipientAddress)
+		require.Equal(t, 2, stored.Version)
+
+		stale.SenderAddress = "other address"
+		require.ErrorIs(t, store.Update(ctx, stale), ErrConflict)
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		number := add(t, store, newClient())
+		at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		// add
+		later, err := store.AddEvent(ctx, number, "arrived", "прибыла в пункт выдачи", at.Add(time.Hour))
+		require.NoError(t, err)
+		earlier, err := store.AddEvent(ctx, number, "accepted", "принята в отделении", at)
+		require.NoError(t, err)
+		_, err = store.AddEvent(ctx, number, "", "без кода", at)
+		require.ErrorIs(t, err, ErrInvalidEvent)
+
+		// check
+		events, err := store.GetEvents(ctx, number)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, earlier, events[0].ID)
+		require.Equal(t, later, events[1].ID)
+		require.Equal(t, "принята в отделении", events[0].Description)
+	})
+
+	t.Run("PaymentAndETA", func(t *testing.T) {
+		// prepare
+		store := open(t)
+		number := add(t, store, newClient())
+		eta := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+
+		// check
+		require.NoError(t, store.MarkPaid(ctx, number, "tx-1"))
+		require.NoError(t, store.MarkPaid(ctx, number, "tx-1"))
+		require.ErrorIs(t, store.MarkPaid(ctx, number, "tx-2"), ErrAlreadyPaid)
+
+		require.NoError(t, store.SetETA(ctx, number, eta))
+		stored, err := store.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, PaymentStatusPaid, stored.PaymentStatus)
+		require.Equal(t, "tx-1", stored.PaymentRef)
+		require.True(t, eta.Equal(stored.ETA), "eta %v", stored.ETA)
+		require.Equal(t, 2, stored.Version)
+	})
+}
+
+func parcelNumbers(parcels []Parcel) []int {
+	numbers := make([]int, 0, len(parcels))
+	for _, p := range parcels {
+		numbers = append(numbers, p.Number)
+	}
+	return numbers
+}
+
+// TestSQLiteContract проверяет общие правила хранилища на SQLite
+func TestSQLiteContract(t *testing.T) {
+	testStorageContract(t, func(t *testing.T) ParcelStorage {
+		return NewParcelStore(openTempDB(t))
+	})
+}
+
+// TestMemoryContract проверяет общие правила хранилища в памяти
+func TestMemoryContract(t *testing.T) {
+	testStorageContract(t, func(*testing.T) ParcelStorage {
+		return NewMemoryParcelStore()
+	})
+}
+
+// TestShardedContract проверяет общие правила хранилища на трёх сегментах SQLite
+func TestShardedContract(t *testing.T) {
+	testStorageContract(t, func(t *testing.T) ParcelStorage {
+		store, err := NewShardedStorage(NewParcelStore(openTempDB(t)), NewParcelStore(openTempDB(t)), NewParcelStore(openTempDB(t)))
+		require.NoError(t, err)
+		return store
+	})
+}
+
+// TestPartitionedContract проверяет общие правила хранилища на месячных разделах SQLite
+func TestPartitionedContract(t *testing.T) {
+	testStorageContract(t, func(t *testing.T) ParcelStorage {
+		opener := newMemoryPartitions()
+		opener.open = func() ParcelStorage { return NewParcelStore(openTempDB(t)) }
+		store, err := NewPartitionedStorage(context.Background(), opener)
+		require.NoError(t, err)
+		return store
+	})
+}
+
+// TestPostgresContract проверяет общие правила хранилища на PostgreSQL
+func TestPostgresContract(t *testing.T) {
+	db := openPostgres(t)
+	testStorageContract(t, func(*testing.T) ParcelStorage {
+		return NewPostgresParcelStore(db)
+	})
+}
+
+// TestMySQLContract проверяет общие правила хранилища на MySQL
+func TestMySQLContract(t *testing.T) {
+	db := openMySQL(t)
+	testStorageContract(t, func(*testing.T) ParcelStorage {
+		return NewMySQLParcelStore(db)
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import "context"
+
+// ParcelCounter считает посылки агрегатами в хранилище,
+// не загружая сами посылки. Удалённые посылки не учитываются.
+type ParcelCounter interface {
+	CountByClient(ctx context.Context, client int) (int, error)
+	CountByStatus(ctx context.Context, status Status) (int, error)
+	CountsByStatus(ctx context.Context) (map[Status]int, error)
+}
+
+var (
+	_ ParcelCounter = ParcelStore{}
+	_ ParcelCounter = (*MemoryParcelStore)(nil)
+)
+
+// CountByClient возвращает число посылок клиента.
+func (s ParcelStore) CountByClient(ctx context.Context, client int) (int, error) {
+	return s.count(ctx, "SELECT COUNT(*) FROM parcel WHERE client = ? AND deleted_at IS NULL", client)
+}
+
+// CountByStatus возвращает число посылок в статусе status.
+func (s ParcelStore) CountByStatus(ctx context.Context, status Status) (int, error) {
+	return s.count(ctx, "SELECT COUNT(*) FROM parcel WHERE status = ? AND deleted_at IS NULL", status)
+}
+
+// CountsByStatus возвращает число посылок в каждом статусе.
+// Статусов без посылок в ответе нет.
+func (s ParcelStore) CountsByStatus(ctx context.Context) (map[Status]int, error) {
+	cond, args := tenantCond(ctx)
+	rows, err := s.reader(0).QueryContext(ctx, s.dialect.rebind(
+		"SELECT status, COUNT(*) FROM parcel WHERE deleted_at IS NULL"+cond+" GROUP BY status"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[Status]int{}
+	for rows.Next() {
+		var status Status
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// count выполняет COUNT-запрос query, ограниченный арендатором из ctx.
+func (s ParcelStore) count(ctx context.Context, query string, args ...any) (int, error) {
+	cond, tenantArgs := tenantCond(ctx)
+	var n int
+	err := s.reader(0).QueryRowContext(ctx, s.dialect.rebind(query+cond), append(args, tenantArgs...)...).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// CountByClient возвращает число посылок клиента.
+func (s *MemoryParcelStore) CountByClient(ctx context.Context, client int) (int, error) {
+	return s.count(ctx, func(p Parcel) bool { return p.Client == client })
+}
+
+// CountByStatus возвращает число посылок в статусе status.
+func (s *MemoryParcelStore) CountByStatus(ctx context.Context, status Status) (int, error) {
+	return s.count(ctx, func(p Parcel) bool { return p.Status == status })
+}
+
+// CountsByStatus возвращает число посылок в каждом статусе.
+func (s *MemoryParcelStore) CountsByStatus(ctx context.Context) (map[Status]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := map[Status]int{}
+	for _, p := range s.parcels {
+		if s.visible(ctx, p.Number) {
+			counts[p.Status]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (s *MemoryParcelStore) count(ctx context.Context, match func(Parcel) bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, p := range s.parcels {
+		if match(p) && s.visible(ctx, p.Number) {
+			n++
+		}
+	}
+
+	return n, nil
+}
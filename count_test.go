@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkCounts проверяет подсчёт посылок в хранилище store с пустой базой
+func checkCounts(t *testing.T, store interface {
+	ParcelStorage
+	ParcelCounter
+}) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	var numbers []int
+	for _, client := range []int{1, 1, 1, 2} {
+		parcel := getTestParcel()
+		parcel.Client = client
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[0], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+	require.NoError(t, store.Delete(ctx, numbers[2]))
+
+	// check
+	n, err := store.CountByClient(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = store.CountByClient(ctx, 3)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = store.CountByStatus(ctx, ParcelStatusSent)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	counts, err := store.CountsByStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[Status]int{ParcelStatusSent: 2, ParcelStatusRegistered: 1}, counts)
+}
+
+// TestCounts проверяет подсчёт посылок в SQLite
+func TestCounts(t *testing.T) {
+	checkCounts(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryCounts проверяет подсчёт посылок в памяти
+func TestMemoryCounts(t *testing.T) {
+	checkCounts(t, NewMemoryParcelStore())
+}
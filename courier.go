@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Ошибки назначения курьеров.
+var (
+	ErrCourierNotFound  = errors.New("courier not found")
+	ErrAssignNotAllowed = errors.New("courier can be assigned only to registered or sent parcels")
+	ErrNotAssigned      = errors.New("parcel is not assigned to this courier")
+)
+
+// Courier — курьер, доставляющий посылки на последней миле.
+type Courier struct {
+	ID    int
+	Name  string
+	Phone string
+}
+
+// CourierStore назначает посылки курьерам. Статус следует за назначением:
+// назначенная посылка в статусе registered уходит в sent, а доставленной
+// её отмечает только назначенный курьер.
+type CourierStore interface {
+	AddCourier(ctx context.Context, c Courier) (int, error)
+	GetCourier(ctx context.Context, id int) (Courier, error)
+	AssignCourier(ctx context.Context, number, courierID int) error
+	GetByCourier(ctx context.Context, courierID int) ([]Parcel, error)
+	CompleteDelivery(ctx context.Context, courierID, number int) error
+}
+
+var _ CourierStore = ParcelStore{}
+
+// AddCourier добавляет курьера и возвращает его идентификатор.
+func (s ParcelStore) AddCourier(ctx context.Context, c Courier) (int, error) {
+	return s.insert(ctx, "INSERT INTO couriers (name, phone) VALUES (?, ?)", "id", c.Name, c.Phone)
+}
+
+func (s ParcelStore) GetCourier(ctx context.Context, id int) (Courier, error) {
+	c := Courier{}
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT id, name, phone FROM couriers WHERE id = ?"),
+		id).Scan(&c.ID, &c.Name, &c.Phone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, ErrCourierNotFound
+	}
+
+	return c, err
+}
+
+// AssignCourier назначает посылку курьеру: посылка в статусе registered
+// при этом переходит в sent, у посылки в sent просто меняется курьер.
+// Доставленную посылку назначить нельзя — возвращается ErrAssignNotAllowed.
+func (s ParcelStore) AssignCourier(ctx context.Context, number, courierID int) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		if _, err := tx.GetCourier(ctx, courierID); err != nil {
+			return err
+		}
+		current, err := tx.status(ctx, number)
+		if err != nil {
+			return err
+		}
+
+		status := current
+		switch current {
+		case ParcelStatusRegistered:
+			status = ParcelStatusSent
+			if err := tx.transitions.Validate(current, status); err != nil {
+				return err
+			}
+			if err := tx.checkPaid(ctx, number, status); err != nil {
+				return err
+			}
+		case ParcelStatusSent:
+		default:
+			return ErrAssignNotAllowed
+		}
+
+		return tx.setCourierStatus(ctx, number, courierID, current, status)
+	})
+}
+
+// CompleteDelivery отмечает посылку доставленной по подтверждению курьера.
+// Посылку, назначенную другому курьеру, отметить нельзя — ErrNotAssigned.
+func (s ParcelStore) CompleteDelivery(ctx context.Context, courierID, number int) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		cond, args := tenantCond(ctx)
+		var current Status
+		var assigned sql.NullInt64
+		err := tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+			"SELECT status, courier_id FROM parcel WHERE number = ? AND deleted_at IS NULL"+cond),
+			append([]any{number}, args...)...).Scan(&current, &assigned)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrParcelNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !assigned.Valid || int(assigned.Int64) != courierID {
+			return ErrNotAssigned
+		}
+		if err := tx.transitions.Validate(current, ParcelStatusDelivered); err != nil {
+			return err
+		}
+
+		return tx.setCourierStatus(ctx, number, courierID, current, ParcelStatusDelivered)
+	})
+}
+
+// setCourierStatus записывает курьера и статус посылки, добавляя смену
+// статуса в историю.
+func (s ParcelStore) setCourierStatus(ctx context.Context, number, courierID int, current, status Status) error {
+	cond, args := tenantCond(ctx)
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET courier_id = ?, status = ?, version = version + 1, updated_at = ? WHERE number = ?"+cond),
+		append([]any{courierID, status, s.timestampArg(), number}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	if status == current {
+		return nil
+	}
+	return s.addHistory(ctx, StatusChange{
+		Number:    number,
+		OldStatus: current,
+		NewStatus: status,
+		ChangedAt: formatTime(s.now()),
+	})
+}
+
+// GetByCourier возвращает очередь курьера: назначенные ему посылки сначала
+// express, затем standard и economy, внутри класса — по возрастанию номера.
+func (s ParcelStore) GetByCourier(ctx context.Context, courierID int) ([]Parcel, error) {
+	if _, err := s.GetCourier(ctx, courierID); err != nil {
+		return nil, err
+	}
+
+	cond, args := tenantCond(ctx)
+	return s.query(ctx, "SELECT "+parcelColumns+" FROM parcel WHERE courier_id = ? AND deleted_at IS NULL"+cond+" ORDER BY "+serviceClassOrder+", number",
+		append([]any{courierID}, args...)...)
+}
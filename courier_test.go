@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCourierAssignment проверяет назначение курьера и смену статуса вслед за ним
+func TestCourierAssignment(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+
+	courier, err := store.AddCourier(ctx, Courier{Name: "Алексей", Phone: "+79990000000"})
+	require.NoError(t, err)
+	other, err := store.AddCourier(ctx, Courier{Name: "Мария"})
+	require.NoError(t, err)
+
+	c, err := store.GetCourier(ctx, courier)
+	require.NoError(t, err)
+	require.Equal(t, Courier{ID: courier, Name: "Алексей", Phone: "+79990000000"}, c)
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// assign: registered -> sent
+	require.NoError(t, store.AssignCourier(ctx, id, courier))
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, p.Status)
+
+	parcels, err := store.GetByCourier(ctx, courier)
+	require.NoError(t, err)
+	require.Equal(t, []Parcel{p}, parcels)
+
+	// переназначение в пути статус не меняет
+	require.NoError(t, store.AssignCourier(ctx, id, other))
+	parcels, err = store.GetByCourier(ctx, courier)
+	require.NoError(t, err)
+	require.Empty(t, parcels)
+
+	// доставку подтверждает только назначенный курьер
+	require.ErrorIs(t, store.CompleteDelivery(ctx, courier, id), ErrNotAssigned)
+	require.NoError(t, store.CompleteDelivery(ctx, other, id))
+
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusDelivered, p.Status)
+
+	history, err := store.GetHistory(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, ParcelStatusSent, history[0].NewStatus)
+	require.Equal(t, ParcelStatusDelivered, history[1].NewStatus)
+
+	// check
+	require.ErrorIs(t, store.AssignCourier(ctx, id, courier), ErrAssignNotAllowed)
+	require.ErrorIs(t, store.AssignCourier(ctx, id, other+100), ErrCourierNotFound)
+	require.ErrorIs(t, store.AssignCourier(ctx, id+100, courier), ErrParcelNotFound)
+	require.ErrorIs(t, store.CompleteDelivery(ctx, courier, id+100), ErrParcelNotFound)
+	_, err = store.GetByCourier(ctx, other+100)
+	require.ErrorIs(t, err, ErrCourierNotFound)
+}
+
+// TestCourierTransitions проверяет, что назначение подчиняется правилам переходов
+func TestCourierTransitions(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	transitions := StatusTransitions{ParcelStatusRegistered: {ParcelStatusDelivered}}
+	store := NewParcelStore(openTempDB(t)).WithTransitions(transitions)
+
+	courier, err := store.AddCourier(ctx, Courier{Name: "Алексей"})
+	require.NoError(t, err)
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	require.ErrorIs(t, store.AssignCourier(ctx, id, courier), ErrInvalidTransition)
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvImportColumns — колонки файла ImportCSV. Обязательны client
+// и recipient_address, остальные можно не указывать.
+var csvImportColumns = []string{"client", "sender_address", "recipient_address",
+	"weight_grams", "length_mm", "width_mm", "height_mm", "service_class"}
+
+// CSVImportOptions задаёт загрузку ImportCSV. DryRun только проверяет
+// строки, ничего не записывая; существование клиентов при этом
+// не проверяется. BatchSize — строк в одной транзакции, ноль — inBatchSize.
+type CSVImportOptions struct {
+	DryRun    bool
+	BatchSize int
+}
+
+// CSVRowError — ошибка строки Line файла ImportCSV, считая строку заголовка первой.
+type CSVRowError struct {
+	Line int
+	Err  error
+}
+
+func (e CSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// CSVImportResult — итог ImportCSV: сколько строк прочитано и загружено
+// и ошибки строк, которые не загружены. При DryRun Imported равен нулю.
+type CSVImportResult struct {
+	Rows     int
+	Imported int
+	Errors   []CSVRowError
+}
+
+// CSVImporter регистрирует посылки из CSV-файла партнёра.
+type CSVImporter interface {
+	ImportCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (CSVImportResult, error)
+}
+
+var (
+	_ CSVImporter = ParcelStore{}
+	_ CSVImporter = (*MemoryParcelStore)(nil)
+)
+
+// ImportCSV регистрирует посылки из строк r, см. importCSV.
+func (s ParcelStore) ImportCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (CSVImportResult, error) {
+	return importCSV(ctx, r, opts, s.addresses, s)
+}
+
+func (s *MemoryParcelStore) ImportCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (CSVImportResult, error) {
+	s.mu.Lock()
+	addresses := s.addresses
+	s.mu.Unlock()
+
+	return importCSV(ctx, r, opts, addresses, s)
+}
+
+// csvParcelAdder добавляет посылки по одной и пачками.
+type csvParcelAdder interface {
+	Add(ctx context.Context, p Parcel) (int, error)
+	AddBatch(ctx context.Context, parcels []Parcel) ([]int, error)
+}
+
+// csvRow — посылка из строки line.
+type csvRow struct {
+	line   int
+	parcel Parcel
+}
+
+// importCSV проверяет каждую строку r, как Add, и регистрирует верные
+// пачками через AddBatch: каждая пачка — своя транзакция. Если пачку
+// отклонило хранилище, например из-за несуществующего клиента, её строки
+// добавляются по одной, чтобы ошибка досталась своей строке. Ошибка
+// возвращается только для файла без нужных колонок или при сбое БД;
+// загруженные до сбоя пачки остаются в хранилище.
+func importCSV(ctx context.Context, r io.Reader, opts CSVImportOptions, v AddressValidator, store csvParcelAdder) (CSVImportResult, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = inBatchSize
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return CSVImportResult{}, fmt.Errorf("%w: empty file", ErrInvalidImport)
+	}
+	if err != nil {
+		return CSVImportResult{}, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	columns, err := csvImportHeader(header)
+	if err != nil {
+		return CSVImportResult{}, err
+	}
+
+	var res CSVImportResult
+	var batch []csvRow
+	flush := func() error {
+		defer func() { batch = batch[:0] }()
+		if opts.DryRun || len(batch) == 0 {
+			return nil
+		}
+		parcels := make([]Parcel, len(batch))
+		for i, row := range batch {
+			parcels[i] = row.parcel
+		}
+		_, err := store.AddBatch(ctx, parcels)
+		if err == nil {
+			res.Imported += len(batch)
+			return nil
+		}
+		if !isDomainError(err) {
+			return err
+		}
+		for _, row := range batch {
+			if _, err := store.Add(ctx, row.parcel); err != nil {
+				if !isDomainError(err) {
+					return err
+				}
+				res.Errors = append(res.Errors, CSVRowError{Line: row.line, Err: err})
+				continue
+			}
+			res.Imported++
+		}
+		return nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+		}
+		line, _ := cr.FieldPos(0)
+		res.Rows++
+
+		p, err := csvImportParcel(columns, record)
+		if err == nil {
+			p, err = prepareParcel(v, p)
+		}
+		if err != nil {
+			res.Errors = append(res.Errors, CSVRowError{Line: line, Err: err})
+			continue
+		}
+		if batch = append(batch, csvRow{line: line, parcel: p}); len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return res, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// csvImportHeader возвращает номера колонок файла по именам из csvImportColumns.
+func csvImportHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		known := false
+		for _, c := range csvImportColumns {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, name)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidImport, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"client", "recipient_address"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: column %q is required", ErrInvalidImport, name)
+		}
+	}
+	return columns, nil
+}
+
+// csvImportParcel разбирает строку record в новую посылку.
+func csvImportParcel(columns map[string]int, record []string) (Parcel, error) {
+	if len(record) != len(columns) {
+		return Parcel{}, fmt.Errorf("%w: want %d fields, got %d", ErrInvalidImport, len(columns), len(record))
+	}
+	value := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(name string) (int, error) {
+		s := value(name)
+		if s == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s must be an integer", ErrInvalidImport, name)
+		}
+		return n, nil
+	}
+
+	p := Parcel{
+		Status:           ParcelStatusRegistered,
+		SenderAddress:    value("sender_address"),
+		RecipientAddress: value("recipient_address"),
+		ServiceClass:     value("service_class"),
+	}
+	var err error
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{
+		{"client", &p.Client},
+		{"weight_grams", &p.WeightGrams},
+		{"length_mm", &p.LengthMM},
+		{"width_mm", &p.WidthMM},
+		{"height_mm", &p.HeightMM},
+	} {
+		if *f.dst, err = number(f.name); err != nil {
+			return Parcel{}, err
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testImportCSV — файл партнёра: строки 3 и 5 с ошибками, адрес
+// в строке 4 занимает две строки файла
+const testImportCSV = "client,recipient_address,sender_address,weight_grams\n" +
+	"1000,\"Москва, ул. Тверская, 1\",,500\n" +
+	"abc,Санкт-Петербург,,\n" +
+	"1000,\"Казань,\nул. Баумана, 2\",Москва,\n" +
+	"1000,,,100\n" +
+	"1000,Самара,,\n"
+
+// csvImportStorage — хранилище посылок с загрузкой из CSV.
+type csvImportStorage interface {
+	ParcelStorage
+	CSVImporter
+}
+
+// checkImportCSV проверяет проверку строк, пробный прогон и загрузку
+// пачками на хранилище store
+func checkImportCSV(t *testing.T, store csvImportStorage) {
+	// prepare
+	ctx := context.Background()
+
+	_, err := store.ImportCSV(ctx, strings.NewReader("client,address\n1000,Москва\n"), CSVImportOptions{})
+	require.ErrorIs(t, err, ErrInvalidImport)
+	_, err = store.ImportCSV(ctx, strings.NewReader("client\n1000\n"), CSVImportOptions{})
+	require.ErrorIs(t, err, ErrInvalidImport)
+
+	res, err := store.ImportCSV(ctx, strings.NewReader(testImportCSV), CSVImportOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 5, res.Rows)
+	require.Zero(t, res.Imported)
+	parcels, err := store.GetByClient(ctx, 1000)
+	require.NoError(t, err)
+	require.Empty(t, parcels)
+
+	// add
+	res, err = store.ImportCSV(ctx, strings.NewReader(testImportCSV), CSVImportOptions{BatchSize: 2})
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, 5, res.Rows)
+	require.Equal(t, 3, res.Imported)
+	require.Len(t, res.Errors, 2)
+	require.Equal(t, 3, res.Errors[0].Line)
+	require.ErrorIs(t, res.Errors[0], ErrInvalidImport)
+	require.Equal(t, 6, res.Errors[1].Line)
+	require.ErrorIs(t, res.Errors[1], ErrInvalidParcel)
+
+	parcels, err = store.GetByClient(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, parcels, 3)
+	require.Equal(t, 500, parcels[0].WeightGrams)
+	require.Equal(t, "Казань, ул. Баумана, 2", parcels[1].RecipientAddress)
+	for _, p := range parcels {
+		require.Equal(t, ParcelStatusRegistered, p.Status)
+		require.NotEmpty(t, p.TrackCode)
+	}
+}
+
+// TestImportCSV проверяет загрузку посылок из CSV в SQLite и в память
+func TestImportCSV(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkImportCSV(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkImportCSV(t, NewMemoryParcelStore())
+	})
+}
+
+// TestImportCSVUnknownClient проверяет, что отказ БД по одной строке
+// не отменяет остальные строки её пачки
+func TestImportCSVUnknownClient(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t)
+	_, err := store.AddClient(ctx, Client{ID: 1000, Name: "test"})
+	require.NoError(t, err)
+
+	// add
+	res, err := store.ImportCSV(ctx, strings.NewReader("client,recipient_address\n1000,Москва\n1001,Казань\n1000,Самара\n"),
+		CSVImportOptions{})
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, 2, res.Imported)
+	require.Len(t, res.Errors, 1)
+	require.Equal(t, 3, res.Errors[0].Line)
+	require.ErrorIs(t, res.Errors[0], ErrClientNotFound)
+}
+
+// TestCLIImportCSV проверяет import --format csv и --dry-run
+func TestCLIImportCSV(t *testing.T) {
+	// prepare
+	_, dsn := openTempDBFile(t)
+	client := strconv.Itoa(randRange.Intn(10_000_000))
+	_, err := runCLI(t, dsn, "client", "add", "--id", client, "--name", "csv client")
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "parcels.csv")
+	require.NoError(t, os.WriteFile(file, []byte("client,recipient_address\n"+client+",Москва\n"), 0o600))
+
+	// check
+	out, err := runCLI(t, dsn, "import", "--format", "csv", "--dry-run", file)
+	require.NoError(t, err)
+	require.Contains(t, out, "Проверено строк: 1, с ошибками: 0")
+
+	out, err = runCLI(t, dsn, "import", "--format", "csv", file)
+	require.NoError(t, err)
+	require.Contains(t, out, "Зарегистрировано посылок: 1 из 1 строк")
+
+	_, err = runCLI(t, dsn, "import", "--dry-run", file)
+	require.Error(t, err)
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidRange возвращается, если To не позже From.
+var ErrInvalidRange = errors.New("invalid created_at range")
+
+// formatTime приводит время к формату, в котором хранятся created_at и другие отметки времени.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
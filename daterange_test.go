@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestListByCreatedRange проверяет выборку посылок по времени создания
+func TestListByCreatedRange(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+	client := randRange.Intn(10_000_000)
+	base := time.Date(2001, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// add
+	var numbers []int
+	for i := 0; i < 3; i++ {
+		parcel := getTestParcel()
+		parcel.Client = client
+		createdAt := base.Add(time.Duration(i) * time.Hour)
+		id, err := store.WithClock(func() time.Time { return createdAt }).Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+
+	// get by range
+	moscow := time.FixedZone("MSK", 3*60*60)
+	page, err := store.ListAll(ctx, Filter{
+		From:   base.Add(time.Hour).In(moscow),
+		To:     base.Add(3 * time.Hour),
+		Client: client,
+	}, ListOptions{SortBy: SortByCreatedAt})
+	require.NoError(t, err)
+	require.Equal(t, numbers[1:], parcelNumbers(page.Parcels))
+
+	// invalid range
+	_, err = store.ListAll(ctx, Filter{From: base, To: base}, ListOptions{})
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dialect описывает отличия SQL разных СУБД, которые важны для ParcelStore.
+// Запросы в хранилище пишутся с плейсхолдерами "?" и переписываются
+// под конкретную СУБД методом rebind.
+type dialect struct {
+	name string
+	// positional включает нумерованные плейсхолдеры $1, $2, ...
+	positional bool
+	// returning означает, что идентификатор новой строки
+	// возвращается через INSERT ... RETURNING, а не LastInsertId
+	returning bool
+	// textTime означает, что отметки времени хранятся строками RFC3339
+	textTime bool
+}
+
+var (
+	sqliteDialect   = dialect{name: "sqlite", textTime: true}
+	postgresDialect = dialect{name: "postgres", positional: true, returning: true}
+	mysqlDialect    = dialect{name: "mysql"}
+)
+
+// rebind переписывает плейсхолдеры "?" в запросе под диалект.
+func (d dialect) rebind(query string) string {
+	if !d.positional {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			continue
+		}
+		n++
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// secondsBetween возвращает выражение с числом секунд от from до to.
+// from — колонка created_at, to — строка RFC3339 вроде changed_at.
+func (d dialect) secondsBetween(from, to string) string {
+	switch d.name {
+	case "postgres":
+		return "EXTRACT(EPOCH FROM CAST(" + to + " AS TIMESTAMPTZ) - " + from + ")"
+	case "mysql":
+		return "TIMESTAMPDIFF(SECOND, " + from + ", STR_TO_DATE(" + to + ", '%Y-%m-%dT%H:%i:%sZ'))"
+	}
+	return "(julianday(" + to + ") - julianday(" + from + ")) * 86400"
+}
+
+// day возвращает выражение с днём отметки времени column в формате 2006-01-02 (UTC).
+func (d dialect) day(column string) string {
+	switch d.name {
+	case "postgres":
+		return "TO_CHAR(" + column + " AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	case "mysql":
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d')"
+	}
+	return "substr(" + column + ", 1, 10)"
+}
+
+// syncSequence возвращает запрос, который сдвигает счётчик номеров посылок
+// за максимальный номер после вставки строк с явными номерами. SQLite
+// и MySQL делают это сами, PostgreSQL — нет.
+func (d dialect) syncSequence() string {
+	if d.name == "postgres" {
+		return "SELECT setval(pg_get_serial_sequence('parcel', 'number'), MAX(number)) FROM parcel"
+	}
+	return ""
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRebind проверяет переписывание плейсхолдеров под диалект
+func TestRebind(t *testing.T) {
+	query := "UPDATE parcel SET address = ? WHERE number = ? AND status = ?"
+
+	require.Equal(t, query, sqliteDialect.rebind(query))
+	require.Equal(t, query, mysqlDialect.rebind(query))
+	require.Equal(t,
+		"UPDATE parcel SET address = $1 WHERE number = $2 AND status = $3",
+		postgresDialect.rebind(query))
+}
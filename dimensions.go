@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDimensions возвращается для отрицательных или превышающих
+// ограничения веса и габаритов посылки.
+var ErrInvalidDimensions = errors.New("invalid parcel weight or dimensions")
+
+// Ограничения на вес и каждую из сторон посылки.
+const (
+	maxWeightGrams = 50_000
+	maxSideMM      = 2_000
+)
+
+// volumetricDivisor — делитель объёмного веса: 5000 см³ на килограмм,
+// как у большинства курьерских служб. В миллиметрах и граммах он тот же.
+const volumetricDivisor = 5000
+
+// validateDimensions проверяет вес и габариты посылки p.
+// Нулевое значение означает, что посылку ещё не взвесили или не измерили.
+func validateDimensions(p Parcel) error {
+	if p.WeightGrams < 0 || p.WeightGrams > maxWeightGrams {
+		return fmt.Errorf("%w: weight must be within %d g", ErrInvalidDimensions, maxWeightGrams)
+	}
+	for _, side := range []int{p.LengthMM, p.WidthMM, p.HeightMM} {
+		if side < 0 || side > maxSideMM {
+			return fmt.Errorf("%w: each side must be within %d mm", ErrInvalidDimensions, maxSideMM)
+		}
+	}
+	return nil
+}
+
+// ComputeVolumetricWeight возвращает объёмный вес посылки в граммах,
+// округлённый вверх: длина × ширина × высота в мм, делённые на 5000.
+// Для неизмеренной посылки он равен нулю.
+func ComputeVolumetricWeight(p Parcel) int {
+	volume := p.LengthMM * p.WidthMM * p.HeightMM
+	return (volume + volumetricDivisor - 1) / volumetricDivisor
+}
+
+// ChargeableWeight возвращает вес, по которому считается стоимость
+// доставки: больший из фактического и объёмного.
+func ChargeableWeight(p Parcel) int {
+	return max(p.WeightGrams, ComputeVolumetricWeight(p))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestComputeVolumetricWeight проверяет объёмный и оплачиваемый вес
+func TestComputeVolumetricWeight(t *testing.T) {
+	// коробка 40 × 30 × 20 см весит по объёму 4,8 кг
+	box := Parcel{WeightGrams: 2000, LengthMM: 400, WidthMM: 300, HeightMM: 200}
+	require.Equal(t, 4800, ComputeVolumetricWeight(box))
+	require.Equal(t, 4800, ChargeableWeight(box))
+
+	// плотная посылка оплачивается по фактическому весу
+	box.WeightGrams = 10_000
+	require.Equal(t, 10_000, ChargeableWeight(box))
+
+	// объём округляется вверх
+	require.Equal(t, 1, ComputeVolumetricWeight(Parcel{LengthMM: 1, WidthMM: 1, HeightMM: 1}))
+	require.Zero(t, ComputeVolumetricWeight(Parcel{WeightGrams: 500}))
+}
+
+// checkDimensions проверяет запись и проверку веса и габаритов в хранилище store
+func checkDimensions(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	parcel := getTestParcel()
+	parcel.Client = randRange.Intn(10_000_000)
+	parcel.WeightGrams = 1500
+	parcel.LengthMM = 300
+	parcel.WidthMM = 200
+	parcel.HeightMM = 100
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 1500, p.WeightGrams)
+	require.Equal(t, []int{300, 200, 100}, []int{p.LengthMM, p.WidthMM, p.HeightMM})
+
+	var b strings.Builder
+	require.NoError(t, store.(ParcelExporter).ExportCSV(ctx, &b, Filter{Client: parcel.Client}))
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, []string{"1500", "300", "200", "100"}, records[1][8:12])
+
+	for _, invalid := range []Parcel{
+		{WeightGrams: -1},
+		{WeightGrams: maxWeightGrams + 1},
+		{LengthMM: maxSideMM + 1},
+		{HeightMM: -5},
+	} {
+		invalid.Client = parcel.Client
+		invalid.Status = ParcelStatusRegistered
+		invalid.RecipientAddress = "test"
+		_, err := store.Add(ctx, invalid)
+		require.ErrorIs(t, err, ErrInvalidDimensions)
+	}
+}
+
+// TestDimensions проверяет вес и габариты в SQLite
+func TestDimensions(t *testing.T) {
+	checkDimensions(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryDimensions проверяет вес и габариты в памяти
+func TestMemoryDimensions(t *testing.T) {
+	checkDimensions(t, NewMemoryParcelStore())
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// encryptedPrefix начинает зашифрованное значение колонки:
+// enc:<id ключа>:<base64 от nonce и шифротекста>. Значения без префикса
+// записаны до включения шифрования и читаются как есть.
+const encryptedPrefix = "enc:"
+
+// ErrUnknownEncryptionKey возвращается, если значение зашифровано
+// ключом, которого нет в настройках encryption.keys.
+var ErrUnknownEncryptionKey = errors.New("unknown encryption key")
+
+// EncryptionConfig включает шифрование адресов в БД. Новые значения
+// шифруются ключом Active, а прочие ключи нужны, чтобы читать значения,
+// записанные до ротации, пока их не перешифрует parcelctl encryption rotate.
+type EncryptionConfig struct {
+	Active string          `yaml:"active"`
+	Keys   []EncryptionKey `yaml:"keys"`
+}
+
+// EncryptionKey — ключ AES-256 в base64. Вместо Key можно задать KeyEnv —
+// имя переменной окружения с ключом, куда его кладёт KMS или менеджер секретов.
+type EncryptionKey struct {
+	ID     string `yaml:"id"`
+	Key    string `yaml:"key"`
+	KeyEnv string `yaml:"key_env"`
+}
+
+func (c EncryptionConfig) enabled() bool {
+	return len(c.Keys) > 0
+}
+
+// applyEnv подставляет ключи из переменных окружения KeyEnv.
+func (c *EncryptionConfig) applyEnv(getenv func(string) string) {
+	for i, k := range c.Keys {
+		if k.KeyEnv != "" {
+			if v := getenv(k.KeyEnv); v != "" {
+				c.Keys[i].Key = v
+			}
+		}
+	}
+}
+
+// Cipher возвращает шифр по настройкам или nil, если шифрование выключено.
+func (c EncryptionConfig) Cipher() (*FieldCipher, error) {
+	if !c.enabled() {
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte, len(c.Keys))
+	for _, k := range c.Keys {
+		if _, ok := keys[k.ID]; ok {
+			return nil, fmt.Errorf("config: duplicate encryption key %q", k.ID)
+		}
+		key, err := base64.StdEncoding.DecodeString(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("config: encryption key %q: %w", k.ID, err)
+		}
+		keys[k.ID] = key
+	}
+	return NewFieldCipher(c.Active, keys)
+}
+
+func (c EncryptionConfig) validate() error {
+	_, err := c.Cipher()
+	return err
+}
+
+// FieldCipher шифрует отдельные значения колонок AES-GCM. В значении
+// хранится id ключа, поэтому после ротации старые значения читаются
+// прежним ключом.
+type FieldCipher struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewFieldCipher создаёт шифр с ключами AES-256 keys по их id;
+// новые значения шифруются ключом active.
+func NewFieldCipher(active string, keys map[string][]byte) (*FieldCipher, error) {
+	c := &FieldCipher{active: active, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("config: invalid encryption key id %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("config: encryption key %q must be 32 bytes", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if c.aeads[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := c.aeads[active]; !ok {
+		return nil, fmt.Errorf("config: active encryption key %q is not configured", active)
+	}
+	return c, nil
+}
+
+// Encrypt шифрует value активным ключом. Пустое значение остаётся пустым,
+// чтобы отсутствующий адрес отправителя не отличался от незашифрованного.
+func (c *FieldCipher) Encrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	aead := c.aeads[c.active]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + c.active + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение, записанное Encrypt. Значения
+// без префикса enc: возвращаются как есть.
+func (c *FieldCipher) Decrypt(value string) (string, error) {
+	id, data, ok := parseEncrypted(value)
+	if !ok {
+		return value, nil
+	}
+
+	aead, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownEncryptionKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("decrypt value: malformed ciphertext")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// stale сообщает, что value нужно перешифровать активным ключом:
+// оно записано открытым текстом или другим ключом.
+func (c *FieldCipher) stale(value string) bool {
+	if value == "" {
+		return false
+	}
+	id, _, ok := parseEncrypted(value)
+	return !ok || id != c.active
+}
+
+func parseEncrypted(value string) (id, data string, ok bool) {
+	rest, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ":")
+}
+
+// WithCipher возвращает копию хранилища, шифрующую адреса посылок
+// и события outbox шифром c; nil выключает шифрование.
+func (s ParcelStore) WithCipher(c *FieldCipher) ParcelStore {
+	s.cipher = c
+	return s
+}
+
+// encrypt шифрует значение колонки, если шифрование включено.
+func (s ParcelStore) encrypt(value string) (string, error) {
+	if s.cipher == nil {
+		return value, nil
+	}
+	return s.cipher.Encrypt(value)
+}
+
+// decrypt расшифровывает значение колонки, если шифрование включено.
+func (s ParcelStore) decrypt(value string) (string, error) {
+	if s.cipher == nil {
+		return value, nil
+	}
+	return s.cipher.Decrypt(value)
+}
+
+// sealParcel возвращает p с зашифрованными адресами для записи в БД.
+func (s ParcelStore) sealParcel(p Parcel) (Parcel, error) {
+	var err error
+	if p.SenderAddress, err = s.encrypt(p.SenderAddress); err != nil {
+		return p, err
+	}
+	p.RecipientAddress, err = s.encrypt(p.RecipientAddress)
+	return p, err
+}
+
+// openParcel расшифровывает адреса прочитанной посылки.
+func (s ParcelStore) openParcel(p *Parcel) error {
+	var err error
+	if p.SenderAddress, err = s.decrypt(p.SenderAddress); err != nil {
+		return err
+	}
+	p.RecipientAddress, err = s.decrypt(p.RecipientAddress)
+	return err
+}
+
+// EncryptionRotator перешифровывает данные активным ключом.
+type EncryptionRotator interface {
+	RotateEncryption(ctx context.Context) (int, error)
+}
+
+var _ EncryptionRotator = ParcelStore{}
+
+// encryptedColumns — зашифрованные колонки по таблицам с ключом строки.
+var encryptedColumns = []struct {
+	table, key string
+	columns    []string
+}{
+	{"parcel", "number", []string{"sender_address", "recipient_address"}},
+	{"parcel_archive", "number", []string{"sender_address", "recipient_address"}},
+	{"parcel_outbox", "id", []string{"payload"}},
+	{"courier_manifest_stop", "id", []string{"recipient_address"}},
+}
+
+// RotateEncryption перешифровывает активным ключом значения, записанные
+// открытым текстом или прежними ключами, и возвращает число изменённых
+// строк. Так шифрование включается для уже сохранённых посылок, а после
+// ротации старый ключ можно убрать из настроек. Строки обрабатываются
+// пачками по inBatchSize, каждая пачка — в своей транзакции.
+func (s ParcelStore) RotateEncryption(ctx context.Context) (int, error) {
+	if s.cipher == nil {
+		return 0, errors.New("encryption is not configured")
+	}
+
+	var rotated int
+	for _, t := range encryptedColumns {
+		for after := 0; ; {
+			var n, last int
+			err := s.withTx(ctx, func(tx ParcelStore) error {
+				var err error
+				n, last, err = tx.rotateBatch(ctx, t.table, t.key, t.columns, after)
+				return err
+			})
+			if err != nil {
+				return rotated, err
+			}
+			rotated += n
+			if last == 0 {
+				break
+			}
+			after = last
+		}
+	}
+	return rotated, nil
+}
+
+// rotateBatch перешифровывает до inBatchSize строк table с ключом больше
+// after и возвращает число изменённых строк и последний ключ пачки или 0,
+// если строк не осталось.
+func (s ParcelStore) rotateBatch(ctx context.Context, table, key string, columns []string, after int) (int, int, error) {
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT "+key+", "+strings.Join(columns, ", ")+" FROM "+table+" WHERE "+key+" > ? ORDER BY "+key+" LIMIT ?"),
+		after, inBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	type row struct {
+		key    int
+		values []string
+	}
+	var batch []row
+	for rows.Next() {
+		r := row{values: make([]string, len(columns))}
+		dest := []any{&r.key}
+		for i := range r.values {
+			dest = append(dest, &r.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	sets := make([]string, len(columns))
+	for i, c := range columns {
+		sets[i] = c + " = ?"
+	}
+	update := s.dialect.rebind("UPDATE " + table + " SET " + strings.Join(sets, ", ") + " WHERE " + key + " = ?")
+
+	var rotated int
+	for _, r := range batch {
+		if !slices.ContainsFunc(r.values, s.cipher.stale) {
+			continue
+		}
+		args := make([]any, 0, len(columns)+1)
+		for _, v := range r.values {
+			plain, err := s.cipher.Decrypt(v)
+			if err != nil {
+				return 0, 0, fmt.Errorf("%s %d: %w", table, r.key, err)
+			}
+			sealed, err := s.cipher.Encrypt(plain)
+			if err != nil {
+				return 0, 0, err
+			}
+			args = append(args, sealed)
+		}
+		if _, err := s.q.ExecContext(ctx, update, append(args, r.key)...); err != nil {
+			return 0, 0, err
+		}
+		rotated++
+	}
+	return rotated, batch[len(batch)-1].key, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCipher возвращает шифр с ключами по id из одинаковых байт id[0]
+func newTestCipher(t *testing.T, active string, ids ...string) *FieldCipher {
+	t.Helper()
+
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte{id[0]}, 32)
+	}
+	c, err := NewFieldCipher(active, keys)
+	require.NoError(t, err)
+	return c
+}
+
+// TestFieldCipher проверяет шифрование значений и чтение после смены ключа
+func TestFieldCipher(t *testing.T) {
+	// prepare
+	old := newTestCipher(t, "a", "a")
+	rotated := newTestCipher(t, "b", "a", "b")
+	address := "Псков, д. Пушкина, ул. Колотушкина, д. 5"
+
+	// encrypt
+	sealed, err := old.Encrypt(address)
+	require.NoError(t, err)
+	require.NotContains(t, sealed, "Пушкина")
+	again, err := old.Encrypt(address)
+	require.NoError(t, err)
+	require.NotEqual(t, sealed, again)
+
+	empty, err := old.Encrypt("")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+
+	// check
+	for _, c := range []*FieldCipher{old, rotated} {
+		plain, err := c.Decrypt(sealed)
+		require.NoError(t, err)
+		require.Equal(t, address, plain)
+	}
+	require.True(t, rotated.stale(sealed))
+	require.False(t, old.stale(sealed))
+
+	plain, err := old.Decrypt("Москва")
+	require.NoError(t, err)
+	require.Equal(t, "Москва", plain)
+
+	newer, err := rotated.Encrypt(address)
+	require.NoError(t, err)
+	_, err = old.Decrypt(newer)
+	require.ErrorIs(t, err, ErrUnknownEncryptionKey)
+
+	_, err = old.Decrypt(sealed[:len(sealed)-4] + "AAAA")
+	require.Error(t, err)
+
+	_, err = NewFieldCipher("c", map[string][]byte{"a": make([]byte, 32)})
+	require.Error(t, err)
+	_, err = NewFieldCipher("a", map[string][]byte{"a": make([]byte, 16)})
+	require.Error(t, err)
+}
+
+// TestEncryptedStore проверяет, что адреса и события outbox хранятся зашифрованными
+func TestEncryptedStore(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	db := openTempDB(t)
+	store := NewParcelStore(db).WithCipher(newTestCipher(t, "a", "a"))
+	outbox := NewOutboxStore(store)
+	parcel := getTestParcel()
+
+	// add
+	id, err := outbox.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NoError(t, store.SetSenderAddress(ctx, id, "Москва, ул. Тверская, д. 1"))
+
+	// check
+	var sender, recipient, payload string
+	require.NoError(t, db.QueryRow("SELECT sender_address, recipient_address FROM parcel WHERE number = ?", id).
+		Scan(&sender, &recipient))
+	require.Contains(t, sender, encryptedPrefix)
+	require.Contains(t, recipient, encryptedPrefix)
+	require.NotContains(t, recipient, parcel.RecipientAddress)
+	require.NoError(t, db.QueryRow("SELECT payload FROM parcel_outbox").Scan(&payload))
+	require.NotContains(t, payload, parcel.RecipientAddress)
+
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel.RecipientAddress, stored.RecipientAddress)
+	require.Equal(t, "Москва, ул. Тверская, д. 1", stored.SenderAddress)
+
+	stored.RecipientAddress = "Саратов, ул. Козлова, д. 25"
+	require.NoError(t, store.Update(ctx, stored))
+	page, err := store.ListByClient(ctx, parcel.Client, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Саратов, ул. Козлова, д. 25", page.Parcels[0].RecipientAddress)
+
+	entries, err := store.ListOutbox(ctx, OutboxQuery{})
+	require.NoError(t, err)
+	e, err := entries[0].Event()
+	require.NoError(t, err)
+	require.Equal(t, parcel.RecipientAddress, e.RecipientAddress)
+
+	_, err = store.SearchByAddress(ctx, "Козлова", ListOptions{})
+	require.ErrorIs(t, err, ErrEncryptedSearch)
+
+	_, err = NewParcelStore(db).WithCipher(newTestCipher(t, "b", "b")).Get(ctx, id)
+	require.ErrorIs(t, err, ErrUnknownEncryptionKey)
+}
+
+// TestRotateEncryption проверяет шифрование старых записей и смену ключа
+func TestRotateEncryption(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	db := openTempDB(t)
+	parcel := getTestParcel()
+	id, err := NewParcelStore(db).Add(ctx, parcel)
+	require.NoError(t, err)
+
+	recipient := func() string {
+		var v string
+		require.NoError(t, db.QueryRow("SELECT recipient_address FROM parcel WHERE number = ?", id).Scan(&v))
+		return v
+	}
+
+	// encrypt plaintext rows
+	first := NewParcelStore(db).WithCipher(newTestCipher(t, "a", "a"))
+	n, err := first.RotateEncryption(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Contains(t, recipient(), encryptedPrefix+"a:")
+
+	n, err = first.RotateEncryption(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// rotate key
+	second := NewParcelStore(db).WithCipher(newTestCipher(t, "b", "a", "b"))
+	n, err = second.RotateEncryption(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Contains(t, recipient(), encryptedPrefix+"b:")
+
+	// check
+	stored, err := NewParcelStore(db).WithCipher(newTestCipher(t, "b", "b")).Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel.RecipientAddress, stored.RecipientAddress)
+}
+
+// TestEncryptionConfig проверяет ключи шифрования из файла и окружения
+func TestEncryptionConfig(t *testing.T) {
+	// prepare
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+encryption:
+  active: k2
+  keys:
+    - id: k1
+      key: `+key+`
+    - id: k2
+      key_env: TEST_ENCRYPTION_KEY
+`), 0o600)
+	require.NoError(t, err)
+
+	// check
+	_, err = LoadConfig(path, env(nil))
+	require.Error(t, err)
+
+	cfg, err := LoadConfig(path, env(map[string]string{"TEST_ENCRYPTION_KEY": key}))
+	require.NoError(t, err)
+	c, err := cfg.Encryption.Cipher()
+	require.NoError(t, err)
+	require.Equal(t, "k2", c.active)
+
+	cfg.Encryption.Active = "k3"
+	require.Error(t, cfg.Validate())
+}
@@ -0,0 +1,16 @@
+package main
+
+import "errors"
+
+// Ошибки хранилища посылок. Все реализации ParcelStorage возвращают их
+// вместо ошибок конкретного драйвера, поэтому вызывающий код может
+// проверять их через errors.Is независимо от СУБД.
+var (
+	ErrParcelNotFound          = errors.New("parcel not found")
+	ErrAddressChangeNotAllowed = errors.New("address can be changed only for registered parcels")
+	ErrDeleteNotAllowed        = errors.New("only registered parcels can be deleted")
+	ErrCancelNotAllowed        = errors.New("only registered parcels can be cancelled")
+	ErrConflict                = errors.New("parcel was modified concurrently")
+	ErrClientNotFound          = errors.New("client not found")
+	ErrClientHasParcels        = errors.New("client has parcels")
+)
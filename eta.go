@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Zone — зона тарифа: адреса, содержащие одну из строк Match
+// (без учёта регистра), доставляются за TransitDays дней после отправки.
+type Zone struct {
+	Name        string   `yaml:"name"`
+	Match       []string `yaml:"match"`
+	TransitDays int      `yaml:"transit_days"`
+}
+
+// Tariff — таблица сроков доставки. HandlingDays — сколько дней посылка
+// ждёт отправки после регистрации; адреса вне зон доставляются
+// за DefaultTransitDays. ServiceDays сдвигает срок в пути по классу
+// обслуживания, например express: -1, но не меньше нуля дней.
+type Tariff struct {
+	HandlingDays       int            `yaml:"handling_days"`
+	DefaultTransitDays int            `yaml:"default_transit_days"`
+	Zones              []Zone         `yaml:"zones"`
+	ServiceDays        map[string]int `yaml:"service_days"`
+}
+
+// enabled сообщает, что тариф задан и даты доставки нужно считать.
+func (t Tariff) enabled() bool {
+	return len(t.Zones) > 0 || t.DefaultTransitDays > 0
+}
+
+// validate проверяет, что сроки тарифа не отрицательные.
+func (t Tariff) validate() error {
+	if t.HandlingDays < 0 || t.DefaultTransitDays < 0 {
+		return errors.New("config: eta days must not be negative")
+	}
+	for _, z := range t.Zones {
+		if z.TransitDays < 0 || len(z.Match) == 0 {
+			return errors.New("config: eta zone " + z.Name + " needs match and non-negative transit_days")
+		}
+	}
+	for class := range t.ServiceDays {
+		if serviceClassRank(class) < 0 {
+			return errors.New("config: eta service_days class " + class + " must be economy, standard or express")
+		}
+	}
+	return nil
+}
+
+// DeliveryEstimator считает ожидаемую дату доставки по тарифу.
+type DeliveryEstimator struct {
+	tariff Tariff
+}
+
+func NewDeliveryEstimator(t Tariff) DeliveryEstimator {
+	return DeliveryEstimator{tariff: t}
+}
+
+// Zone возвращает зону тарифа для адреса. Если адрес не попал ни в одну
+// зону, возвращается зона без имени со сроком DefaultTransitDays.
+func (e DeliveryEstimator) Zone(address string) Zone {
+	address = strings.ToLower(address)
+	for _, z := range e.tariff.Zones {
+		for _, m := range z.Match {
+			if strings.Contains(address, strings.ToLower(m)) {
+				return z
+			}
+		}
+	}
+	return Zone{TransitDays: e.tariff.DefaultTransitDays}
+}
+
+// EstimateDelivery возвращает ожидаемую дату доставки посылки:
+// для registered — от времени создания с учётом ожидания отправки,
+// для sent — от времени отправки, для delivered — фактическое время доставки.
+// Срок в пути зависит от зоны адреса получателя и класса обслуживания.
+// Время отправки и доставки берётся из UpdatedAt, поэтому дату нужно
+// пересчитывать сразу после смены статуса, как делает EstimatingStorage.
+func (e DeliveryEstimator) EstimateDelivery(p Parcel) time.Time {
+	transit := max(0, e.Zone(p.RecipientAddress).TransitDays+e.serviceDays(p.ServiceClass))
+	switch p.Status {
+	case ParcelStatusRegistered:
+		return p.CreatedAt.AddDate(0, 0, e.tariff.HandlingDays+transit)
+	case ParcelStatusSent:
+		return p.UpdatedAt.AddDate(0, 0, transit)
+	case ParcelStatusDelivered:
+		return p.UpdatedAt
+	}
+	return time.Time{}
+}
+
+// serviceDays возвращает сдвиг срока в пути для класса class;
+// посылки без класса считаются standard.
+func (e DeliveryEstimator) serviceDays(class string) int {
+	if class == "" {
+		class = ServiceStandard
+	}
+	return e.tariff.ServiceDays[class]
+}
+
+// EstimatingStorage пересчитывает ожидаемую дату доставки при регистрации
+// посылки, смене её статуса и адреса. Дата — производное значение,
+// поэтому её запись не меняет версию посылки.
+type EstimatingStorage struct {
+	ParcelStorage
+	estimator DeliveryEstimator
+	logger    *slog.Logger
+}
+
+var _ ParcelStorage = EstimatingStorage{}
+
+func NewEstimatingStorage(next ParcelStorage, estimator DeliveryEstimator, logger *slog.Logger) EstimatingStorage {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return EstimatingStorage{ParcelStorage: next, estimator: estimator, logger: logger}
+}
+
+func (s EstimatingStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	number, err := s.ParcelStorage.Add(ctx, p)
+	if err != nil {
+		return number, err
+	}
+
+	s.estimate(ctx, number)
+	return number, nil
+}
+
+func (s EstimatingStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	if err := s.ParcelStorage.SetStatus(ctx, number, status); err != nil {
+		return err
+	}
+
+	s.estimate(ctx, number)
+	return nil
+}
+
+func (s EstimatingStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	if err := s.ParcelStorage.SetRecipientAddress(ctx, number, address); err != nil {
+		return err
+	}
+
+	s.estimate(ctx, number)
+	return nil
+}
+
+func (s EstimatingStorage) Update(ctx context.Context, p Parcel) error {
+	if err := s.ParcelStorage.Update(ctx, p); err != nil {
+		return err
+	}
+
+	s.estimate(ctx, p.Number)
+	return nil
+}
+
+// estimate записывает ожидаемую дату доставки посылки number. Ошибка
+// логируется: посылка уже сохранена, а дату пересчитает следующее изменение.
+func (s EstimatingStorage) estimate(ctx context.Context, number int) {
+	p, err := s.ParcelStorage.Get(ctx, number)
+	if err == nil {
+		err = s.ParcelStorage.SetETA(ctx, number, s.estimator.EstimateDelivery(p))
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "estimate delivery", "number", number, "error", err)
+	}
+}
+
+// SetETA записывает ожидаемую дату доставки; нулевое время её стирает.
+// Версия и updated_at посылки не меняются.
+func (s ParcelStore) SetETA(ctx context.Context, number int, eta time.Time) error {
+	var arg any
+	if !eta.IsZero() {
+		arg = s.dialect.timeArg(eta.Truncate(time.Second))
+	}
+
+	cond, args := tenantCond(ctx)
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET eta = ? WHERE number = ? AND deleted_at IS NULL"+cond),
+		append([]any{arg, number}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	// MySQL не считает строки, значения в которых не изменились
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = s.status(ctx, number)
+	return err
+}
+
+// SetETA записывает ожидаемую дату доставки; нулевое время её стирает.
+func (s *MemoryParcelStore) SetETA(ctx context.Context, number int, eta time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if !eta.IsZero() {
+		eta = eta.UTC().Truncate(time.Second)
+	}
+	p.ETA = eta
+	s.parcels[number] = p
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testTariff — тариф с одной зоной для тестов
+var testTariff = Tariff{
+	HandlingDays:       1,
+	DefaultTransitDays: 7,
+	Zones:              []Zone{{Name: "Москва", Match: []string{"москва"}, TransitDays: 2}},
+}
+
+// TestEstimateDelivery проверяет расчёт даты доставки по тарифу
+func TestEstimateDelivery(t *testing.T) {
+	e := NewDeliveryEstimator(testTariff)
+	require.Equal(t, "Москва", e.Zone("Москва, ул. Тверская, д. 1").Name)
+	require.Equal(t, 7, e.Zone("Псков").TransitDays)
+
+	p := Parcel{Status: ParcelStatusRegistered, RecipientAddress: "г. МОСКВА", CreatedAt: testCreatedAt, UpdatedAt: testCreatedAt}
+	require.Equal(t, testCreatedAt.AddDate(0, 0, 3), e.EstimateDelivery(p))
+
+	p.Status = ParcelStatusSent
+	p.UpdatedAt = testCreatedAt.Add(5 * time.Hour)
+	require.Equal(t, p.UpdatedAt.AddDate(0, 0, 2), e.EstimateDelivery(p))
+
+	p.Status = ParcelStatusDelivered
+	require.Equal(t, p.UpdatedAt, e.EstimateDelivery(p))
+
+	require.Error(t, Tariff{HandlingDays: -1}.validate())
+	require.Error(t, Tariff{Zones: []Zone{{Name: "empty"}}}.validate())
+	require.NoError(t, testTariff.validate())
+}
+
+// checkETA проверяет пересчёт даты доставки при изменениях посылки в хранилище store
+func checkETA(t *testing.T, store ParcelStorage, now *time.Time) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	*now = testCreatedAt
+	store = NewEstimatingStorage(store, NewDeliveryEstimator(testTariff), nil)
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, testCreatedAt.AddDate(0, 0, 8), p.ETA)
+
+	// смена адреса меняет зону, а версию — только сама смена адреса
+	require.NoError(t, store.SetRecipientAddress(ctx, id, "Москва, ул. Тверская, д. 1"))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, testCreatedAt.AddDate(0, 0, 3), p.ETA)
+	require.Equal(t, 2, p.Version)
+
+	// check
+	*now = testCreatedAt.Add(30 * time.Hour)
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, now.AddDate(0, 0, 2), p.ETA)
+
+	*now = now.Add(24 * time.Hour)
+	p.Status = ParcelStatusDelivered
+	require.NoError(t, store.Update(ctx, p))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, *now, p.ETA)
+
+	require.ErrorIs(t, store.SetETA(ctx, -1, *now), ErrParcelNotFound)
+	require.NoError(t, store.SetETA(ctx, id, time.Time{}))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.True(t, p.ETA.IsZero())
+}
+
+// TestETA проверяет дату доставки в SQLite
+func TestETA(t *testing.T) {
+	var now time.Time
+	store := NewParcelStore(openTempDB(t)).WithClock(func() time.Time { return now })
+	checkETA(t, store, &now)
+}
+
+// TestMemoryETA проверяет дату доставки в памяти
+func TestMemoryETA(t *testing.T) {
+	var now time.Time
+	store := NewMemoryParcelStore()
+	store.SetClock(func() time.Time { return now })
+	checkETA(t, store, &now)
+}
+
+// TestHTTPETA проверяет, что REST API отдаёт дату доставки
+func TestHTTPETA(t *testing.T) {
+	// prepare
+	store := NewMemoryParcelStore()
+	store.SetClock(testClock)
+	srv := NewHTTPServer(NewParcelService(NewEstimatingStorage(store, NewDeliveryEstimator(testTariff), nil)))
+
+	// check
+	rec := doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 7, "recipient_address": "Москва"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.Equal(t, formatTime(testCreatedAt.AddDate(0, 0, 3)), created.ETA)
+
+	rec = doRequest(t, srv, http.MethodGet, "/parcels/"+strconv.Itoa(created.Number), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var stored parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stored))
+	require.Equal(t, created.ETA, stored.ETA)
+}
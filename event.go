@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// TrackingEvent — произвольное событие отслеживания посылки,
+// например «прибыла в сортировочный центр Москва».
+type TrackingEvent struct {
+	ID          int
+	Number      int
+	Code        string
+	Description string
+	OccurredAt  string
+}
+
+// ErrInvalidEvent возвращается для события без кода.
+var ErrInvalidEvent = errors.New("tracking event code is required")
+
+// AddEvent добавляет событие отслеживания к посылке и возвращает его идентификатор.
+func (s ParcelStore) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	if code == "" {
+		return 0, ErrInvalidEvent
+	}
+
+	var id int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		if _, err := tx.status(ctx, number); err != nil {
+			return err
+		}
+
+		var err error
+		id, err = tx.insert(ctx,
+			"INSERT INTO parcel_event (parcel_number, code, description, occurred_at) VALUES (?, ?, ?, ?)",
+			"id", number, code, description, formatTime(occurredAt))
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetEvents возвращает события посылки в порядке их наступления.
+func (s ParcelStore) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.reader(number).QueryContext(ctx, s.dialect.rebind(
+		"SELECT id, parcel_number, code, description, occurred_at FROM parcel_event WHERE parcel_number = ? ORDER BY occurred_at, id"),
+		number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []TrackingEvent
+	for rows.Next() {
+		e := TrackingEvent{}
+		err := rows.Scan(&e.ID, &e.Number, &e.Code, &e.Description, &e.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// AddEvent добавляет событие отслеживания к посылке и возвращает его идентификатор.
+func (s *MemoryParcelStore) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if code == "" {
+		return 0, ErrInvalidEvent
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return 0, ErrParcelNotFound
+	}
+
+	s.lastEvent++
+	s.events[number] = append(s.events[number], TrackingEvent{
+		ID:          s.lastEvent,
+		Number:      number,
+		Code:        code,
+		Description: description,
+		OccurredAt:  formatTime(occurredAt),
+	})
+
+	return s.lastEvent, nil
+}
+
+// GetEvents возвращает события посылки в порядке их наступления.
+func (s *MemoryParcelStore) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return nil, ErrParcelNotFound
+	}
+
+	res := append([]TrackingEvent(nil), s.events[number]...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].OccurredAt < res[j].OccurredAt
+	})
+
+	return res, nil
+}
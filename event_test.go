@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddGetEvents проверяет добавление и получение событий отслеживания
+func TestAddGetEvents(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	stores := map[string]ParcelStorage{
+		"sqlite": NewParcelStore(db),
+		"memory": NewMemoryParcelStore(),
+	}
+	now := time.Now()
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			id, err := store.Add(ctx, getTestParcel())
+			require.NoError(t, err)
+
+			// add
+			_, err = store.AddEvent(ctx, id, "arrived", "прибыла в сортировочный центр Москва", now)
+			require.NoError(t, err)
+			_, err = store.AddEvent(ctx, id, "accepted", "принята в отделении", now.Add(-time.Hour))
+			require.NoError(t, err)
+
+			// get
+			events, err := store.GetEvents(ctx, id)
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			require.Equal(t, "accepted", events[0].Code)
+			require.Equal(t, "arrived", events[1].Code)
+			require.Equal(t, formatTime(now), events[1].OccurredAt)
+
+			// invalid
+			_, err = store.AddEvent(ctx, id, "", "", now)
+			require.ErrorIs(t, err, ErrInvalidEvent)
+
+			_, err = store.AddEvent(ctx, -1, "arrived", "", now)
+			require.ErrorIs(t, err, ErrParcelNotFound)
+
+			// delete
+			require.NoError(t, store.Delete(ctx, id))
+			_, err = store.GetEvents(ctx, id)
+			require.ErrorIs(t, err, ErrParcelNotFound)
+		})
+	}
+}
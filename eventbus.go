@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Типы событий шины.
+const (
+	EventParcelCreated  = "parcel.created"
+	EventStatusChanged  = "parcel.status_changed"
+	EventAddressChanged = "parcel.address_changed"
+	EventParcelDeleted  = "parcel.deleted"
+)
+
+// ParcelEvent — сообщение шины событий об изменении посылки.
+// Old* заполняются только для событий смены статуса и адреса.
+type ParcelEvent struct {
+	Type                string `json:"type"`
+	Number              int    `json:"number"`
+	Client              int    `json:"client"`
+	Status              string `json:"status"`
+	SenderAddress       string `json:"sender_address,omitempty"`
+	RecipientAddress    string `json:"recipient_address"`
+	TrackCode           string `json:"track_code,omitempty"`
+	OldStatus           string `json:"old_status,omitempty"`
+	OldSenderAddress    string `json:"old_sender_address,omitempty"`
+	OldRecipientAddress string `json:"old_recipient_address,omitempty"`
+	OccurredAt          string `json:"occurred_at"`
+}
+
+// newParcelEvent собирает событие типа typ о посылке p.
+func newParcelEvent(typ string, p Parcel) ParcelEvent {
+	return ParcelEvent{
+		Type:             typ,
+		Number:           p.Number,
+		Client:           p.Client,
+		Status:           string(p.Status),
+		SenderAddress:    p.SenderAddress,
+		RecipientAddress: p.RecipientAddress,
+		TrackCode:        p.TrackCode,
+		OccurredAt:       formatTime(time.Now()),
+	}
+}
+
+// Publisher отправляет события в шину.
+type Publisher interface {
+	Publish(ctx context.Context, e ParcelEvent) error
+	Close() error
+}
+
+// NATSPublisher публикует события в NATS в тему <prefix>.<тип события>,
+// например parcels.parcel.created.
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher подключается к NATS по адресу url.
+func NewNATSPublisher(url, prefix string) (NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("parcel-tracker"))
+	if err != nil {
+		return NATSPublisher{}, fmt.Errorf("connect nats %s: %w", url, err)
+	}
+	return NATSPublisher{conn: conn, prefix: prefix}, nil
+}
+
+func (p NATSPublisher) Publish(_ context.Context, e ParcelEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return p.conn.Publish(p.prefix+"."+e.Type, data)
+}
+
+// Close отправляет буферизованные сообщения и закрывает соединение.
+func (p NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+// KafkaPublisher публикует события в топик Kafka. Ключ сообщения — номер
+// посылки, поэтому события одной посылки попадают в одну партицию по порядку.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) KafkaPublisher {
+	return KafkaPublisher{w: &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}}
+}
+
+func (p KafkaPublisher) Publish(ctx context.Context, e ParcelEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return p.w.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(strconv.Itoa(e.Number)),
+		Value:   data,
+		Headers: []kafka.Header{{Key: "type", Value: []byte(e.Type)}},
+	})
+}
+
+func (p KafkaPublisher) Close() error {
+	return p.w.Close()
+}
+
+// EventsConfig задаёт шину событий: driver nats или kafka, пустой — без шины.
+type EventsConfig struct {
+	Driver string `yaml:"driver"`
+	// URL — адрес сервера NATS
+	URL string `yaml:"url"`
+	// Brokers — адреса брокеров Kafka
+	Brokers []string `yaml:"brokers"`
+	// Topic — топик Kafka или префикс тем NATS, по умолчанию parcels
+	Topic string `yaml:"topic"`
+	// Outbox пишет события в parcel_outbox вместе с изменением,
+	// а публикует их команда outbox relay, см. OutboxStore
+	Outbox bool `yaml:"outbox"`
+	// RelayEvery — интервал публикации outbox внутри API-сервера;
+	// ноль оставляет публикацию отдельной команде outbox relay
+	RelayEvery time.Duration `yaml:"relay_every"`
+}
+
+// validate проверяет, что для выбранной шины заданы адреса.
+func (c EventsConfig) validate() error {
+	if c.RelayEvery < 0 {
+		return errors.New("config: events relay interval must not be negative")
+	}
+	if c.RelayEvery > 0 && !c.Outbox {
+		return errors.New("config: events relay_every requires outbox")
+	}
+	switch c.Driver {
+	case "":
+		if c.Outbox {
+			return errors.New("config: events outbox requires a driver")
+		}
+	case "nats":
+		if c.URL == "" {
+			return errors.New("config: events url is required for nats")
+		}
+	case "kafka":
+		if len(c.Brokers) == 0 {
+			return errors.New("config: events brokers are required for kafka")
+		}
+	default:
+		return fmt.Errorf("config: unknown events driver %q", c.Driver)
+	}
+	return nil
+}
+
+// Publisher подключается к шине или возвращает nil, если шина не задана.
+func (c EventsConfig) Publisher() (Publisher, error) {
+	topic := c.Topic
+	if topic == "" {
+		topic = "parcels"
+	}
+
+	switch c.Driver {
+	case "nats":
+		return NewNATSPublisher(c.URL, topic)
+	case "kafka":
+		return NewKafkaPublisher(c.Brokers, topic), nil
+	}
+	return nil, nil
+}
+
+// changeEvents возвращает события, которыми посылка old стала p.
+// Нулевая Parcel означает, что посылки нет: нулевая old даёт
+// ParcelCreated, нулевая p — ParcelDeleted.
+func changeEvents(old, p Parcel) []ParcelEvent {
+	switch {
+	case old.Number == 0 && p.Number == 0:
+		return nil
+	case old.Number == 0:
+		return []ParcelEvent{newParcelEvent(EventParcelCreated, p)}
+	case p.Number == 0:
+		return []ParcelEvent{newParcelEvent(EventParcelDeleted, old)}
+	}
+
+	var res []ParcelEvent
+	if p.Status != old.Status {
+		e := newParcelEvent(EventStatusChanged, p)
+		e.OldStatus = string(old.Status)
+		res = append(res, e)
+	}
+	if p.SenderAddress != old.SenderAddress || p.RecipientAddress != old.RecipientAddress {
+		e := newParcelEvent(EventAddressChanged, p)
+		e.OldSenderAddress = old.SenderAddress
+		e.OldRecipientAddress = old.RecipientAddress
+		res = append(res, e)
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordPublisher запоминает опубликованные события.
+type recordPublisher struct {
+	mu     sync.Mutex
+	events []ParcelEvent
+	err    error
+}
+
+func (r *recordPublisher) Publish(_ context.Context, e ParcelEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	return r.err
+}
+
+func (r *recordPublisher) Close() error { return nil }
+
+// types возвращает типы опубликованных событий по порядку.
+func (r *recordPublisher) types() []string {
+	var res []string
+	for _, e := range r.events {
+		res = append(res, e.Type)
+	}
+	return res
+}
+
+// TestParcelEventJSON проверяет формат сообщения шины
+func TestParcelEventJSON(t *testing.T) {
+	e := newParcelEvent(EventStatusChanged, Parcel{Number: 1, Client: 2, Status: ParcelStatusSent, RecipientAddress: "a"})
+	e.OldStatus = string(ParcelStatusRegistered)
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.Equal(t, "parcel.status_changed", m["type"])
+	require.Equal(t, ParcelStatusRegistered.String(), m["old_status"])
+	require.NotContains(t, m, "old_address")
+	require.NotEmpty(t, m["occurred_at"])
+}
+
+// TestEventsConfig проверяет настройки шины событий
+func TestEventsConfig(t *testing.T) {
+	require.NoError(t, EventsConfig{}.validate())
+	require.NoError(t, EventsConfig{Driver: "nats", URL: "nats://localhost:4222"}.validate())
+	require.NoError(t, EventsConfig{Driver: "kafka", Brokers: []string{"localhost:9092"}}.validate())
+	require.Error(t, EventsConfig{Driver: "nats"}.validate())
+	require.Error(t, EventsConfig{Driver: "kafka"}.validate())
+	require.Error(t, EventsConfig{Driver: "rabbit"}.validate())
+
+	p, err := EventsConfig{}.Publisher()
+	require.NoError(t, err)
+	require.Nil(t, p)
+}
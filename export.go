@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// csvHeader — первая строка экспорта, порядок колонок совпадает с csvRecord.
+var csvHeader = []string{"number", "client", "status", "sender_address", "recipient_address", "created_at", "updated_at", "track_code",
+	"weight_grams", "length_mm", "width_mm", "height_mm", "price",
+	"amount", "currency", "payment_status", "paid_at"}
+
+// ParcelExporter выгружает посылки для партнёров.
+type ParcelExporter interface {
+	ExportCSV(ctx context.Context, w io.Writer, filter Filter) error
+}
+
+var (
+	_ ParcelExporter = ParcelStore{}
+	_ ParcelExporter = (*MemoryParcelStore)(nil)
+)
+
+func csvRecord(p Parcel) []string {
+	var paidAt string
+	if !p.PaidAt.IsZero() {
+		paidAt = formatTime(p.PaidAt)
+	}
+	return []string{
+		strconv.Itoa(p.Number),
+		strconv.Itoa(p.Client),
+		p.Status.String(),
+		p.SenderAddress,
+		p.RecipientAddress,
+		formatTime(p.CreatedAt),
+		formatTime(p.UpdatedAt),
+		p.TrackCode,
+		strconv.Itoa(p.WeightGrams),
+		strconv.Itoa(p.LengthMM),
+		strconv.Itoa(p.WidthMM),
+		strconv.Itoa(p.HeightMM),
+		strconv.FormatInt(p.Price, 10),
+		strconv.FormatInt(p.Amount, 10),
+		p.Currency,
+		p.PaymentStatus,
+		paidAt,
+	}
+}
+
+// ExportCSV пишет в w посылки, подходящие под filter, в формате CSV
+// (RFC 4180: строка заголовка, кавычки по необходимости, CRLF) по возрастанию
+// номера. Строки читаются из БД и пишутся по одной, поэтому выгрузка
+// не держит в памяти всю таблицу.
+func (s ParcelStore) ExportCSV(ctx context.Context, w io.Writer, filter Filter) error {
+	cw := newCSVWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	err := s.eachParcel(ctx, filter, func(p Parcel) error {
+		return cw.Write(csvRecord(p))
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// eachParcel вызывает fn для каждой посылки, подходящей под filter,
+// по возрастанию номера, читая строки из БД по одной.
+func (s ParcelStore) eachParcel(ctx context.Context, filter Filter, fn func(Parcel) error) error {
+	where, args, err := s.scopedWhere(ctx, filter)
+	if err != nil {
+		return err
+	}
+	rows, err := s.reader(0).QueryContext(ctx, s.dialect.rebind(
+		"SELECT "+parcelColumns+" FROM parcel"+where+" ORDER BY number"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := s.scanParcel(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func newCSVWriter(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	return cw
+}
+
+// ExportCSV пишет в w посылки, подходящие под filter, в формате CSV.
+func (s *MemoryParcelStore) ExportCSV(ctx context.Context, w io.Writer, filter Filter) error {
+	parcels, err := s.filtered(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	cw := newCSVWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, p := range parcels {
+		if err := cw.Write(csvRecord(p)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// filtered возвращает копии посылок, подходящих под filter, по возрастанию номера.
+func (s *MemoryParcelStore) filtered(ctx context.Context, filter Filter) ([]Parcel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkFilter(filter); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	var parcels []Parcel
+	for _, p := range s.parcels {
+		if filter.match(p) && s.visible(ctx, p.Number) {
+			parcels = append(parcels, p)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(parcels, func(i, j int) bool { return parcels[i].Number < parcels[j].Number })
+
+	return parcels, nil
+}
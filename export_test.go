@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkExportCSV проверяет выгрузку посылок в CSV из хранилища store
+func checkExportCSV(t *testing.T, store interface {
+	ParcelStorage
+	ParcelExporter
+}) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	client := randRange.Intn(10_000_000)
+	var numbers []int
+	for _, address := range []string{`ул. Ленина, д. 1, кв. "2"`, "многострочный\nадрес", "test"} {
+		parcel := getTestParcel()
+		parcel.Client = client
+		parcel.RecipientAddress = address
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusSent))
+
+	// export
+	var b strings.Builder
+	err := store.ExportCSV(ctx, &b, Filter{Client: client, Statuses: []Status{ParcelStatusRegistered}})
+	require.NoError(t, err)
+
+	// check
+	require.Contains(t, b.String(), `"ул. Ленина, д. 1, кв. ""2"""`)
+	require.True(t, strings.HasPrefix(b.String(), strings.Join(csvHeader, ",")+"\r\n"))
+
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, strconv.Itoa(numbers[0]), records[1][0])
+	// перевод строки в адресе заменяется пробелом ещё при добавлении
+	require.Equal(t, "многострочный адрес", records[2][4])
+	require.Equal(t, formatTime(testCreatedAt), records[1][5])
+
+	// пустая выгрузка содержит только заголовок
+	b.Reset()
+	require.NoError(t, store.ExportCSV(ctx, &b, Filter{Client: client, Statuses: []Status{ParcelStatusDelivered}}))
+	require.Equal(t, strings.Join(csvHeader, ",")+"\r\n", b.String())
+}
+
+// TestExportCSV проверяет выгрузку в CSV из SQLite
+func TestExportCSV(t *testing.T) {
+	checkExportCSV(t, NewParcelStore(openTempDB(t)).WithClock(testClock))
+}
+
+// TestMemoryExportCSV проверяет выгрузку в CSV из памяти
+func TestMemoryExportCSV(t *testing.T) {
+	store := NewMemoryParcelStore()
+	store.SetClock(testClock)
+	checkExportCSV(t, store)
+}
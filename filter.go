@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidFilter возвращается для фильтра с недопустимыми значениями полей.
+	ErrInvalidFilter = errors.New("invalid parcel filter")
+	// ErrUnsupportedFilter возвращает хранилище, которое не умеет
+	// выполнить условие фильтра, например MemoryParcelStore для Courier.
+	ErrUnsupportedFilter = errors.New("parcel filter is not supported by the storage")
+)
+
+// Filter отбирает посылки по нескольким условиям сразу.
+// Нулевые поля не ограничивают выборку; удалённые посылки не попадают в неё никогда.
+type Filter struct {
+	Client int
+	// Statuses оставляет посылки в любом из этих статусов
+	Statuses []Status
+	// From и To задают полуинтервал [From, To) по времени создания
+	From time.Time
+	To   time.Time
+	// Address оставляет посылки, в адресе отправителя или получателя которых
+	// есть эта подстрока с учётом регистра. С шифрованием адресов, см.
+	// WithCipher, ParcelStore такой фильтр не поддерживает
+	Address string
+	// Courier оставляет посылки, назначенные курьеру с этим идентификатором.
+	// MemoryParcelStore курьеров не хранит и возвращает ErrUnsupportedFilter
+	Courier int
+	// SLABreached оставляет только посылки с нарушенным сроком SLA
+	SLABreached bool
+	// ReturnOf оставляет только возвраты посылки с этим номером
+	ReturnOf int
+	// ServiceClass оставляет только посылки этого класса обслуживания
+	ServiceClass string
+}
+
+// validate проверяет значения полей до того, как они попадут в запрос.
+func (f Filter) validate() error {
+	for _, id := range []struct {
+		name  string
+		value int
+	}{{"client", f.Client}, {"courier", f.Courier}, {"return_of", f.ReturnOf}} {
+		if id.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidFilter, id.name)
+		}
+	}
+	for _, st := range f.Statuses {
+		if !st.Valid() {
+			return fmt.Errorf("%w: %q", ErrUnknownStatus, st)
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.To.After(f.From) {
+		return ErrInvalidRange
+	}
+	if utf8.RuneCountInString(f.Address) > maxAddressLen {
+		return fmt.Errorf("%w: address longer than %d characters", ErrInvalidFilter, maxAddressLen)
+	}
+	return validateServiceClass(f.ServiceClass)
+}
+
+// parseStatuses разбирает статусы фильтра из values; каждое значение может
+// перечислять статусы через запятую, например "sent,delivered".
+func parseStatuses(values []string) ([]Status, error) {
+	var res []Status
+	for _, v := range values {
+		for _, name := range strings.Split(v, ",") {
+			st, err := ParseStatus(name)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, st)
+		}
+	}
+	return res, nil
+}
+
+// checkFilter проверяет filter и то, что хранилище может его выполнить.
+func (s ParcelStore) checkFilter(f Filter) error {
+	if f.Address != "" && s.cipher != nil {
+		return ErrEncryptedSearch
+	}
+	return f.validate()
+}
+
+// checkFilter проверяет filter и то, что хранилище в памяти может его выполнить.
+func (s *MemoryParcelStore) checkFilter(f Filter) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	if f.Courier != 0 {
+		return fmt.Errorf("%w: courier", ErrUnsupportedFilter)
+	}
+	return nil
+}
+
+// where возвращает условие WHERE с плейсхолдерами "?" и его аргументы.
+// Значения полей передаются только аргументами, колонки берутся из кода,
+// поэтому фильтр из запроса не может изменить сам SQL.
+func (f Filter) where(d dialect) (string, []any) {
+	conds := []string{"deleted_at IS NULL"}
+	var args []any
+	if f.Client != 0 {
+		conds = append(conds, "client = ?")
+		args = append(args, f.Client)
+	}
+	if len(f.Statuses) > 0 {
+		conds = append(conds, "status IN ("+placeholders(len(f.Statuses))+")")
+		for _, st := range f.Statuses {
+			args = append(args, st)
+		}
+	}
+	// в SQLite created_at хранится строкой RFC3339 в UTC, и timeArg приводит
+	// границы к тому же формату: так строковое сравнение совпадает
+	// с хронологическим и запрос использует индекс parcel_created_at_idx
+	if !f.From.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, d.timeArg(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, d.timeArg(f.To))
+	}
+	if f.Address != "" {
+		conds = append(conds, "("+d.contains("sender_address")+" OR "+d.contains("recipient_address")+")")
+		args = append(args, f.Address, f.Address)
+	}
+	if f.Courier != 0 {
+		conds = append(conds, "courier_id = ?")
+		args = append(args, f.Courier)
+	}
+	if f.ReturnOf != 0 {
+		conds = append(conds, "return_of = ?")
+		args = append(args, f.ReturnOf)
+	}
+	if f.SLABreached {
+		conds = append(conds, "sla_breached_at IS NOT NULL")
+	}
+	if f.ServiceClass != "" {
+		conds = append(conds, "service_class = ?")
+		args = append(args, f.ServiceClass)
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// match проверяет посылку на те же условия, что и where, кроме Courier,
+// см. MemoryParcelStore.checkFilter.
+func (f Filter) match(p Parcel) bool {
+	return (f.Client == 0 || p.Client == f.Client) &&
+		(len(f.Statuses) == 0 || slices.Contains(f.Statuses, p.Status)) &&
+		(f.From.IsZero() || !p.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || p.CreatedAt.Before(f.To)) &&
+		(f.Address == "" || strings.Contains(p.SenderAddress, f.Address) || strings.Contains(p.RecipientAddress, f.Address)) &&
+		(!f.SLABreached || !p.SLABreachedAt.IsZero()) &&
+		(f.ReturnOf == 0 || p.ReturnOf == f.ReturnOf) &&
+		(f.ServiceClass == "" || p.ServiceClass == f.ServiceClass)
+}
+
+// contains возвращает условие «в column есть подстрока ?» с учётом регистра.
+// В отличие от LIKE, подстрока не нуждается в экранировании % и _.
+func (d dialect) contains(column string) string {
+	switch d.name {
+	case "postgres":
+		return "strpos(" + column + ", ?) > 0"
+	case "mysql":
+		// с двоичным аргументом LOCATE учитывает регистр при любой сортировке
+		return "LOCATE(CAST(? AS BINARY), " + column + ") > 0"
+	}
+	return "instr(" + column + ", ?) > 0"
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// filterStorage — хранилище посылок с выгрузкой в CSV.
+type filterStorage interface {
+	ParcelStorage
+	ParcelExporter
+}
+
+// checkFilter проверяет условия Filter и отказ от недопустимых значений
+// на хранилище store
+func checkFilter(t *testing.T, store filterStorage) {
+	// prepare
+	ctx := context.Background()
+	var numbers []int
+	for _, address := range []string{"Москва, ул. Ленина, 1", "Казань, пр. 100%_скидки, 2", "Самара, ул. Мира, 3"} {
+		p := getTestParcel()
+		p.RecipientAddress = address
+		number, err := store.Add(ctx, p)
+		require.NoError(t, err)
+		numbers = append(numbers, number)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusDelivered))
+
+	// check
+	for _, tt := range []struct {
+		name   string
+		filter Filter
+		want   []int
+	}{
+		{"statuses", Filter{Statuses: []Status{ParcelStatusRegistered, ParcelStatusDelivered}}, []int{numbers[0], numbers[2]}},
+		{"address", Filter{Address: "ул. "}, []int{numbers[0], numbers[2]}},
+		{"address case", Filter{Address: "москва"}, nil},
+		// % и _ ищутся как обычные символы, а не как шаблон LIKE
+		{"address wildcard", Filter{Address: "100%_"}, []int{numbers[1]}},
+		{"address not wildcard", Filter{Address: "%"}, []int{numbers[1]}},
+		{"sender address", Filter{Address: getTestParcel().SenderAddress}, numbers},
+		{"address and status", Filter{Address: "ул. ", Statuses: []Status{ParcelStatusDelivered}}, []int{numbers[2]}},
+	} {
+		page, err := store.ListAll(ctx, tt.filter, ListOptions{})
+		require.NoError(t, err, tt.name)
+		if tt.want == nil {
+			require.Empty(t, page.Parcels, tt.name)
+			continue
+		}
+		require.Equal(t, tt.want, parcelNumbers(page.Parcels), tt.name)
+	}
+
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		filter Filter
+		err    error
+	}{
+		{Filter{Statuses: []Status{ParcelStatusSent, "lost"}}, ErrUnknownStatus},
+		{Filter{Client: -1}, ErrInvalidFilter},
+		{Filter{Courier: -1}, ErrInvalidFilter},
+		{Filter{From: day, To: day}, ErrInvalidRange},
+		{Filter{Address: strings.Repeat("а", maxAddressLen+1)}, ErrInvalidFilter},
+		{Filter{ServiceClass: "overnight"}, ErrUnknownServiceClass},
+	} {
+		_, err := store.ListAll(ctx, tt.filter, ListOptions{})
+		require.ErrorIs(t, err, tt.err)
+		_, err = store.List(ctx, tt.filter, 0, 0)
+		require.ErrorIs(t, err, tt.err)
+		require.ErrorIs(t, store.ExportCSV(ctx, &bytes.Buffer{}, tt.filter), tt.err)
+	}
+}
+
+// TestFilter проверяет фильтр посылок в SQLite и в памяти
+func TestFilter(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkFilter(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkFilter(t, NewMemoryParcelStore())
+	})
+}
+
+// TestFilterCourier проверяет выборку посылок курьера
+func TestFilterCourier(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	courier, err := store.AddCourier(ctx, Courier{Name: "Алексей"})
+	require.NoError(t, err)
+	assigned, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	_, err = store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// add
+	require.NoError(t, store.AssignCourier(ctx, assigned, courier))
+
+	// check
+	page, err := store.ListAll(ctx, Filter{Courier: courier}, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []int{assigned}, parcelNumbers(page.Parcels))
+
+	var b bytes.Buffer
+	require.NoError(t, store.ExportCSV(ctx, &b, Filter{Courier: courier + 1}))
+	require.Equal(t, 1, strings.Count(b.String(), "\n"))
+}
+
+// TestFilterCourierMemory проверяет, что хранилище в памяти без курьеров
+// отказывается от фильтра по курьеру, а не возвращает пустую выборку
+func TestFilterCourierMemory(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	_, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	_, err = store.ListAll(ctx, Filter{Courier: 1}, ListOptions{})
+	require.ErrorIs(t, err, ErrUnsupportedFilter)
+	_, err = store.List(ctx, Filter{Courier: 1}, 0, 0)
+	require.ErrorIs(t, err, ErrUnsupportedFilter)
+	require.ErrorIs(t, store.ExportCSV(ctx, &bytes.Buffer{}, Filter{Courier: 1}), ErrUnsupportedFilter)
+
+	rec := doRequest(t, NewHTTPServer(NewParcelService(store)), http.MethodGet, "/parcels?courier=1", "")
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+}
+
+// TestFilterEncrypted проверяет, что с шифрованием адресов фильтр
+// по адресу недоступен
+func TestFilterEncrypted(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t)).WithCipher(newTestCipher(t, "a", "a"))
+
+	// check
+	_, err := store.ListAll(ctx, Filter{Address: "Москва"}, ListOptions{})
+	require.ErrorIs(t, err, ErrEncryptedSearch)
+	_, err = store.ListAll(ctx, Filter{}, ListOptions{})
+	require.NoError(t, err)
+}
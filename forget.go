@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// forgottenParcels отбирает номера посылок клиента, в том числе удалённых
+// и перенесённых в архив.
+const forgottenParcels = "SELECT number FROM parcel WHERE client = ?" +
+	" UNION SELECT number FROM parcel_archive WHERE client = ?"
+
+// Erasure — запись журнала client_erasures о выполненном ForgetClient.
+type Erasure struct {
+	ID     int
+	Client int
+	// Parcels и Events — сколько посылок и событий трекинга обезличено
+	Parcels  int
+	Events   int
+	ErasedAt time.Time
+}
+
+// ClientForgetter удаляет персональные данные клиента по его запросу.
+// Реализуется только SQL-хранилищем, как и ClientStore.
+type ClientForgetter interface {
+	ForgetClient(ctx context.Context, client int) (Erasure, error)
+	ListErasures(ctx context.Context) ([]Erasure, error)
+}
+
+var _ ClientForgetter = ParcelStore{}
+
+// ForgetClient в одной транзакции обезличивает клиента: стирает его имя
+// и контакты, адреса и координаты его посылок, включая удалённые
+// и архивные, описания их событий трекинга, отправленные события outbox
+// и адреса в ещё не отправленных. Сами посылки, их статусы, история
+// статусов и оплата остаются для учёта — персональных данных в них нет.
+// Операция записывается в журнал client_erasures и не зависит
+// от арендатора ctx. Для клиента без посылок и записи в clients
+// возвращается ErrClientNotFound.
+func (s ParcelStore) ForgetClient(ctx context.Context, client int) (Erasure, error) {
+	erasure := Erasure{Client: client, ErasedAt: timestamp(s.now)}
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		numbers, err := tx.numbers(ctx, forgottenParcels, client, client)
+		if err != nil {
+			return err
+		}
+		erasure.Parcels = len(numbers)
+
+		res, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE clients SET name = '', email = '', phone = '' WHERE id = ?"), client)
+		if err != nil {
+			return err
+		}
+		// MySQL не считает строки, значения в которых не изменились,
+		// поэтому повторное обезличивание проверяется по посылкам
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 && len(numbers) == 0 {
+			if _, err := tx.GetClient(ctx, client); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET sender_address = '', recipient_address = '', lat = NULL, lon = NULL, signed_by = '', signed_at = NULL,"+
+				" version = version + 1, updated_at = ? WHERE client = ?"),
+			tx.timestampArg(), client)
+		if err != nil {
+			return err
+		}
+		_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel_archive SET sender_address = '', recipient_address = '' WHERE client = ?"), client)
+		if err != nil {
+			return err
+		}
+
+		for start := 0; start < len(numbers); start += inBatchSize {
+			batch := numbers[start:min(start+inBatchSize, len(numbers))]
+			events, err := tx.forgetParcels(ctx, batch)
+			if err != nil {
+				return err
+			}
+			erasure.Events += events
+		}
+
+		erasure.ID, err = tx.insert(ctx,
+			"INSERT INTO client_erasures (client, parcels, events, erased_at) VALUES (?, ?, ?, ?)", "id",
+			client, erasure.Parcels, erasure.Events, tx.dialect.timeArg(erasure.ErasedAt))
+		return err
+	})
+	if err != nil {
+		return Erasure{}, err
+	}
+
+	return erasure, nil
+}
+
+// forgetParcels стирает описания событий трекинга, заметки, подписи, остановки маршрутных
+// листов и адреса в событиях outbox посылок numbers и возвращает количество их событий трекинга.
+func (s ParcelStore) forgetParcels(ctx context.Context, numbers []int) (int, error) {
+	in, args := numbersIn("parcel_number", numbers)
+
+	var events int
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind("SELECT COUNT(*) FROM parcel_event WHERE"+in),
+		args...).Scan(&events)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.q.ExecContext(ctx, s.dialect.rebind("UPDATE parcel_event SET description = '' WHERE"+in), args...)
+	if err != nil {
+		return 0, err
+	}
+	// в заметках поддержки бывают личные данные получателя,
+	// а в маршрутных листах — его адрес
+	for _, table := range []string{"parcel_note", "parcel_signature", "courier_manifest_stop"} {
+		if _, err := s.q.ExecContext(ctx, s.dialect.rebind("DELETE FROM "+table+" WHERE"+in), args...); err != nil {
+			return 0, err
+		}
+	}
+
+	// отправленные события больше не нужны, а неотправленные ещё должны
+	// дойти до подписчиков, поэтому из них убираются только адреса
+	_, err = s.q.ExecContext(ctx, s.dialect.rebind("DELETE FROM parcel_outbox WHERE sent_at IS NOT NULL AND"+in), args...)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind("SELECT id, payload FROM parcel_outbox WHERE"+in), args...)
+	if err != nil {
+		return 0, err
+	}
+	payloads := map[int]string{}
+	for rows.Next() {
+		var id int
+		var payload string
+		if err := rows.Scan(&id, &payload); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if payload, err = s.decrypt(payload); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		payloads[id] = payload
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, payload := range payloads {
+		e, err := OutboxEntry{Payload: payload}.Event()
+		if err != nil {
+			return 0, err
+		}
+		e.SenderAddress, e.RecipientAddress = "", ""
+		e.OldSenderAddress, e.OldRecipientAddress = "", ""
+		b, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		if payload, err = s.encrypt(string(b)); err != nil {
+			return 0, err
+		}
+		_, err = s.q.ExecContext(ctx, s.dialect.rebind("UPDATE parcel_outbox SET payload = ? WHERE id = ?"), payload, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return events, nil
+}
+
+// ListErasures возвращает журнал обезличиваний по возрастанию id.
+func (s ParcelStore) ListErasures(ctx context.Context) ([]Erasure, error) {
+	rows, err := s.q.QueryContext(ctx,
+		"SELECT id, client, parcels, events, erased_at FROM client_erasures ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Erasure
+	for rows.Next() {
+		var e Erasure
+		if err := rows.Scan(&e.ID, &e.Client, &e.Parcels, &e.Events, scanTime{&e.ErasedAt}); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestForgetClient проверяет обезличивание клиента, его посылок, событий и outbox
+func TestForgetClient(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t)
+	outbox := NewOutboxStore(store)
+
+	client, err := store.AddClient(ctx, Client{Name: "Иван", Email: "ivan@example.com", Phone: "+79990000000"})
+	require.NoError(t, err)
+	other, err := store.AddClient(ctx, Client{Name: "Пётр"})
+	require.NoError(t, err)
+
+	parcel := Parcel{Client: client, Status: ParcelStatusRegistered,
+		SenderAddress: "Москва, Тверская 1", RecipientAddress: "Псков, Колотушкина 5"}
+
+	// add
+	kept, err := outbox.Add(ctx, parcel)
+	require.NoError(t, err)
+	deleted, err := outbox.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(ctx, deleted))
+	_, err = store.AddEvent(ctx, kept, "handover", "передана лично Ивану", time.Now())
+	require.NoError(t, err)
+
+	entries, err := store.ListOutbox(ctx, OutboxQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.NoError(t, store.MarkOutboxSent(ctx, entries[0].ID))
+
+	foreign, err := store.Add(ctx, Parcel{Client: other, Status: ParcelStatusRegistered, RecipientAddress: "Псков, Колотушкина 5"})
+	require.NoError(t, err)
+
+	// forget
+	erasure, err := store.ForgetClient(ctx, client)
+	require.NoError(t, err)
+	require.NotEmpty(t, erasure.ID)
+	require.Equal(t, 2, erasure.Parcels)
+	require.Equal(t, 1, erasure.Events)
+
+	// check
+	c, err := store.GetClient(ctx, client)
+	require.NoError(t, err)
+	require.Equal(t, Client{ID: client}, c)
+
+	p, err := store.Get(ctx, kept)
+	require.NoError(t, err)
+	require.Empty(t, p.SenderAddress)
+	require.Empty(t, p.RecipientAddress)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+
+	require.NoError(t, store.Restore(ctx, deleted))
+	p, err = store.Get(ctx, deleted)
+	require.NoError(t, err)
+	require.Empty(t, p.RecipientAddress)
+
+	events, err := store.GetEvents(ctx, kept)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "handover", events[0].Code)
+	require.Empty(t, events[0].Description)
+
+	entries, err = store.ListOutbox(ctx, OutboxQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	e, err := entries[0].Event()
+	require.NoError(t, err)
+	require.Equal(t, deleted, e.Number)
+	require.Empty(t, e.SenderAddress)
+	require.Empty(t, e.RecipientAddress)
+
+	page, err := store.SearchByAddress(ctx, "Колотушкина", ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Parcels, 1)
+	require.Equal(t, foreign, page.Parcels[0].Number)
+
+	erasures, err := store.ListErasures(ctx)
+	require.NoError(t, err)
+	require.Len(t, erasures, 1)
+	require.Equal(t, client, erasures[0].Client)
+
+	_, err = store.ForgetClient(ctx, 999999)
+	require.ErrorIs(t, err, ErrClientNotFound)
+}
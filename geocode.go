@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAddressNotGeocoded возвращает Geocoder, не нашедший адрес.
+	ErrAddressNotGeocoded = errors.New("address not found by geocoder")
+	// ErrInvalidCoordinates возвращается для широты вне [-90, 90],
+	// долготы вне [-180, 180] или неположительного радиуса поиска.
+	ErrInvalidCoordinates = errors.New("invalid coordinates or radius")
+)
+
+// earthRadius — средний радиус Земли в метрах.
+const earthRadius = 6_371_000.0
+
+// Coordinates — широта и долгота в градусах. Нулевое значение
+// означает, что адрес посылки не геокодирован.
+type Coordinates struct {
+	Lat float64
+	Lon float64
+}
+
+func (c Coordinates) IsZero() bool {
+	return c == Coordinates{}
+}
+
+func (c Coordinates) validate() error {
+	if math.IsNaN(c.Lat) || math.IsNaN(c.Lon) || math.Abs(c.Lat) > 90 || math.Abs(c.Lon) > 180 {
+		return ErrInvalidCoordinates
+	}
+	return nil
+}
+
+// Distance возвращает расстояние до d по дуге большого круга в метрах.
+func (c Coordinates) Distance(d Coordinates) float64 {
+	lat1, lat2 := c.Lat*math.Pi/180, d.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (d.Lon - c.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(min(h, 1)))
+}
+
+// Geocoder находит координаты адреса.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (Coordinates, error)
+}
+
+// GeocodingConfig — настройки геокодера. Геокодирование включается,
+// если задан URL, например https://nominatim.openstreetmap.org.
+type GeocodingConfig struct {
+	URL string `yaml:"url"`
+	// UserAgent обязателен по правилам публичного Nominatim
+	UserAgent string `yaml:"user_agent"`
+}
+
+// Geocoder возвращает геокодер или nil, если геокодирование выключено.
+func (c GeocodingConfig) Geocoder() Geocoder {
+	if c.URL == "" {
+		return nil
+	}
+	return NewNominatimGeocoder(c)
+}
+
+// NominatimGeocoder ищет адреса через GET /search API Nominatim
+// (OpenStreetMap). Публичный сервер разрешает не больше запроса в секунду,
+// для массовой загрузки нужен свой экземпляр.
+type NominatimGeocoder struct {
+	cfg    GeocodingConfig
+	client *http.Client
+}
+
+func NewNominatimGeocoder(cfg GeocodingConfig) NominatimGeocoder {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "go-db-sql-final"
+	}
+	return NominatimGeocoder{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (g NominatimGeocoder) Geocode(ctx context.Context, address string) (Coordinates, error) {
+	q := url.Values{}
+	q.Set("q", address)
+	q.Set("format", "jsonv2")
+	q.Set("limit", "1")
+
+	endpoint := strings.TrimSuffix(g.cfg.URL, "/") + "/search?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	req.Header.Set("User-Agent", g.cfg.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("geocode %q: %w", address, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return Coordinates{}, fmt.Errorf("geocode %q: %s", address, resp.Status)
+	}
+
+	// Nominatim отдаёт координаты строками
+	var places []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return Coordinates{}, fmt.Errorf("geocode %q: %w", address, err)
+	}
+	if len(places) == 0 {
+		return Coordinates{}, ErrAddressNotGeocoded
+	}
+
+	var c Coordinates
+	if c.Lat, err = strconv.ParseFloat(places[0].Lat, 64); err != nil {
+		return Coordinates{}, fmt.Errorf("geocode %q: lat: %w", address, err)
+	}
+	if c.Lon, err = strconv.ParseFloat(places[0].Lon, 64); err != nil {
+		return Coordinates{}, fmt.Errorf("geocode %q: lon: %w", address, err)
+	}
+	return c, nil
+}
+
+// NearParcel — посылка, найденная GetNear, с расстоянием до точки поиска в метрах.
+type NearParcel struct {
+	Parcel
+	Coordinates Coordinates
+	Distance    float64
+}
+
+// GeoStore хранит координаты адресов посылок.
+type GeoStore interface {
+	SetCoordinates(ctx context.Context, number int, c Coordinates) error
+	GetCoordinates(ctx context.Context, number int) (Coordinates, error)
+	GetNear(ctx context.Context, lat, lon, radius float64) ([]NearParcel, error)
+}
+
+var _ GeoStore = ParcelStore{}
+
+// SetCoordinates записывает координаты адреса посылки; нулевое значение
+// их стирает. Как и ETA, координаты — производное значение, поэтому
+// версия и updated_at посылки не меняются.
+func (s ParcelStore) SetCoordinates(ctx context.Context, number int, c Coordinates) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	var lat, lon any
+	if !c.IsZero() {
+		lat, lon = c.Lat, c.Lon
+	}
+
+	cond, args := tenantCond(ctx)
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET lat = ?, lon = ? WHERE number = ? AND deleted_at IS NULL"+cond),
+		append([]any{lat, lon, number}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	// MySQL не считает строки, значения в которых не изменились
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = s.status(ctx, number)
+	return err
+}
+
+// GetCoordinates возвращает координаты адреса посылки
+// или нулевое значение, если адрес не геокодирован.
+func (s ParcelStore) GetCoordinates(ctx context.Context, number int) (Coordinates, error) {
+	cond, args := tenantCond(ctx)
+	var lat, lon sql.NullFloat64
+	err := s.reader(number).QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT lat, lon FROM parcel WHERE number = ? AND deleted_at IS NULL"+cond),
+		append([]any{number}, args...)...).Scan(&lat, &lon)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Coordinates{}, ErrParcelNotFound
+	}
+	if err != nil {
+		return Coordinates{}, err
+	}
+
+	return Coordinates{Lat: lat.Float64, Lon: lon.Float64}, nil
+}
+
+// GetNear возвращает посылки с адресом в радиусе radius метров от точки
+// (lat, lon), ближние первыми. База отбирает кандидатов по индексу
+// в описанном прямоугольнике, точное расстояние считается в Go,
+// поэтому запрос не зависит от геофункций СУБД.
+func (s ParcelStore) GetNear(ctx context.Context, lat, lon, radius float64) ([]NearParcel, error) {
+	center := Coordinates{Lat: lat, Lon: lon}
+	if err := center.validate(); err != nil {
+		return nil, err
+	}
+	if !(radius > 0) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	dLat := radius / earthRadius * 180 / math.Pi
+	query := "SELECT " + parcelColumns + ", lat, lon FROM parcel" +
+		" WHERE lat BETWEEN ? AND ? AND lon IS NOT NULL AND deleted_at IS NULL"
+	args := []any{lat - dLat, lat + dLat}
+	// у полюсов и через 180-й меридиан долготу не ограничиваем
+	if cos := math.Cos(lat * math.Pi / 180); math.Abs(lat)+dLat < 90 && dLat/cos < 180-math.Abs(lon) {
+		dLon := dLat / cos
+		query += " AND lon BETWEEN ? AND ?"
+		args = append(args, lon-dLon, lon+dLon)
+	}
+	cond, tenantArgs := tenantCond(ctx)
+	query += cond
+	args = append(args, tenantArgs...)
+
+	rows, err := s.reader(0).QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []NearParcel
+	for rows.Next() {
+		var (
+			n NearParcel
+			p = &n.Parcel
+		)
+		err := rows.Scan(&p.Number, &p.Client, &p.Status, &p.SenderAddress, &p.RecipientAddress, scanTime{&p.CreatedAt}, scanTime{&p.UpdatedAt},
+			&p.Version, &p.TrackCode, &p.UUID, scanTime{&p.ETA}, &p.WeightGrams, &p.LengthMM, &p.WidthMM, &p.HeightMM, &p.Price,
+			&p.Amount, &p.Currency, &p.PaymentStatus, scanTime{&p.PaidAt}, &p.PaymentRef,
+			&p.ServiceClass, scanTime{&p.SLADeadline}, scanTime{&p.SLABreachedAt}, &p.ReturnOf,
+			&p.CancelReason, scanTime{&p.CancelledAt}, &p.SignedBy, scanTime{&p.SignedAt}, &n.Coordinates.Lat, &n.Coordinates.Lon)
+		if err != nil {
+			return nil, err
+		}
+		if n.Distance = center.Distance(n.Coordinates); n.Distance <= radius {
+			res = append(res, n)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Distance < res[j].Distance })
+	return res, nil
+}
+
+// GeocodingStorage определяет координаты адреса при регистрации посылки
+// и смене адреса. Ошибка геокодера логируется и не отменяет запись,
+// а прежние координаты стираются, чтобы не указывать на старый адрес.
+type GeocodingStorage struct {
+	ParcelStorage
+	geo      GeoStore
+	geocoder Geocoder
+	logger   *slog.Logger
+}
+
+var _ ParcelStorage = GeocodingStorage{}
+
+func NewGeocodingStorage(next ParcelStorage, geo GeoStore, geocoder Geocoder, logger *slog.Logger) GeocodingStorage {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return GeocodingStorage{ParcelStorage: next, geo: geo, geocoder: geocoder, logger: logger}
+}
+
+func (s GeocodingStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	number, err := s.ParcelStorage.Add(ctx, p)
+	if err != nil {
+		return number, err
+	}
+
+	s.Locate(ctx, number)
+	return number, nil
+}
+
+func (s GeocodingStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	if err := s.ParcelStorage.SetRecipientAddress(ctx, number, address); err != nil {
+		return err
+	}
+
+	s.Locate(ctx, number)
+	return nil
+}
+
+func (s GeocodingStorage) Update(ctx context.Context, p Parcel) error {
+	old, err := s.ParcelStorage.Get(ctx, p.Number)
+	if err != nil {
+		return err
+	}
+	if err := s.ParcelStorage.Update(ctx, p); err != nil {
+		return err
+	}
+
+	if p.RecipientAddress != old.RecipientAddress {
+		s.Locate(ctx, p.Number)
+	}
+	return nil
+}
+
+// Locate геокодирует текущий адрес посылки number и сохраняет координаты.
+// Возвращает их или нулевое значение, если адрес найти не удалось.
+func (s GeocodingStorage) Locate(ctx context.Context, number int) Coordinates {
+	var c Coordinates
+	p, err := s.ParcelStorage.Get(ctx, number)
+	if err == nil {
+		c, err = s.geocoder.Geocode(ctx, p.RecipientAddress)
+		if err != nil {
+			s.logger.WarnContext(ctx, "geocode address", "number", number, "error", err)
+			c = Coordinates{}
+		}
+		err = s.geo.SetCoordinates(ctx, number, c)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "geocode address", "number", number, "error", err)
+	}
+	return c
+}
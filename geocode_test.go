@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mapGeocoder находит адреса по словарю
+type mapGeocoder map[string]Coordinates
+
+func (g mapGeocoder) Geocode(_ context.Context, address string) (Coordinates, error) {
+	c, ok := g[address]
+	if !ok {
+		return Coordinates{}, ErrAddressNotGeocoded
+	}
+	return c, nil
+}
+
+var (
+	kremlin   = Coordinates{Lat: 55.7520, Lon: 37.6175}
+	tverskaya = Coordinates{Lat: 55.7650, Lon: 37.6050}
+	hermitage = Coordinates{Lat: 59.9398, Lon: 30.3146}
+)
+
+// TestDistance проверяет расстояние между точками
+func TestDistance(t *testing.T) {
+	require.Zero(t, kremlin.Distance(kremlin))
+	require.InDelta(t, 1650, kremlin.Distance(tverskaya), 50)
+	require.InDelta(t, 634_000, kremlin.Distance(hermitage), 5_000)
+	require.InDelta(t, kremlin.Distance(hermitage), hermitage.Distance(kremlin), 1e-6)
+}
+
+// TestNominatimGeocoder проверяет запрос к Nominatim и разбор ответа
+func TestNominatimGeocoder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/search", r.URL.Path)
+		require.Equal(t, "jsonv2", r.URL.Query().Get("format"))
+		require.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+		if r.URL.Query().Get("q") != "Москва, Красная площадь" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"lat": "55.7520", "lon": "37.6175", "display_name": "Красная площадь"}]`))
+	}))
+	defer srv.Close()
+
+	g := GeocodingConfig{URL: srv.URL + "/", UserAgent: "test-agent"}.Geocoder()
+
+	c, err := g.Geocode(context.Background(), "Москва, Красная площадь")
+	require.NoError(t, err)
+	require.Equal(t, kremlin, c)
+
+	_, err = g.Geocode(context.Background(), "нигде")
+	require.ErrorIs(t, err, ErrAddressNotGeocoded)
+
+	require.Nil(t, GeocodingConfig{}.Geocoder())
+}
+
+// TestGetNear проверяет поиск посылок в радиусе от точки
+func TestGetNear(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+
+	var numbers []int
+	for _, c := range []Coordinates{kremlin, tverskaya, hermitage, {}} {
+		id, err := store.Add(ctx, getTestParcel())
+		require.NoError(t, err)
+		require.NoError(t, store.SetCoordinates(ctx, id, c))
+		numbers = append(numbers, id)
+	}
+
+	// check
+	c, err := store.GetCoordinates(ctx, numbers[0])
+	require.NoError(t, err)
+	require.Equal(t, kremlin, c)
+
+	near, err := store.GetNear(ctx, tverskaya.Lat, tverskaya.Lon, 5_000)
+	require.NoError(t, err)
+	require.Len(t, near, 2)
+	require.Equal(t, numbers[1], near[0].Number)
+	require.Equal(t, numbers[0], near[1].Number)
+	require.InDelta(t, 1650, near[1].Distance, 50)
+	require.Equal(t, kremlin, near[1].Coordinates)
+
+	near, err = store.GetNear(ctx, kremlin.Lat, kremlin.Lon, 1_000_000)
+	require.NoError(t, err)
+	require.Len(t, near, 3)
+
+	// посылка без координат в поиск не попадает, удалённая — тоже
+	require.NoError(t, store.Delete(ctx, numbers[1]))
+	near, err = store.GetNear(ctx, tverskaya.Lat, tverskaya.Lon, 5_000)
+	require.NoError(t, err)
+	require.Len(t, near, 1)
+
+	_, err = store.GetNear(ctx, 91, 0, 1000)
+	require.ErrorIs(t, err, ErrInvalidCoordinates)
+	_, err = store.GetNear(ctx, 0, 0, 0)
+	require.ErrorIs(t, err, ErrInvalidCoordinates)
+	require.ErrorIs(t, store.SetCoordinates(ctx, numbers[0], Coordinates{Lat: 0, Lon: 200}), ErrInvalidCoordinates)
+	require.ErrorIs(t, store.SetCoordinates(ctx, 999999, kremlin), ErrParcelNotFound)
+}
+
+// TestGeocodingStorage проверяет геокодирование при записи посылки
+func TestGeocodingStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	sqlStore := NewParcelStore(openTempDB(t))
+	geocoder := mapGeocoder{"Москва, Кремль": kremlin, "Санкт-Петербург, Эрмитаж": hermitage}
+	store := NewGeocodingStorage(sqlStore, sqlStore, geocoder, nil)
+
+	parcel := getTestParcel()
+	parcel.RecipientAddress = "Москва, Кремль"
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	c, err := sqlStore.GetCoordinates(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, kremlin, c)
+
+	require.NoError(t, store.SetRecipientAddress(ctx, id, "Санкт-Петербург, Эрмитаж"))
+	c, err = sqlStore.GetCoordinates(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, hermitage, c)
+
+	// ненайденный адрес стирает прежние координаты, но не отменяет запись
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	p.RecipientAddress = "неизвестный адрес"
+	require.NoError(t, store.Update(ctx, p))
+	c, err = sqlStore.GetCoordinates(ctx, id)
+	require.NoError(t, err)
+	require.True(t, c.IsZero())
+
+	// ошибка записи возвращается как есть
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+	err = store.SetRecipientAddress(ctx, id, "Москва, Кремль")
+	require.ErrorIs(t, err, ErrAddressChangeNotAllowed)
+}
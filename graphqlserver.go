@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelgql"
+)
+
+const (
+	// graphQLPageSize — посылок на странице parcels без first и в одном
+	// чтении Client.parcels
+	graphQLPageSize = 100
+	// graphQLMaxPageSize — наибольшее first в parcels
+	graphQLMaxPageSize = 1000
+	// graphQLComplexityLimit ограничивает сложность запроса, чтобы глубокая
+	// вложенность client → parcels → client не перебирала всю БД
+	graphQLComplexityLimit = 1000
+)
+
+// GraphQLServer отдаёт посылки, клиентов, историю и события через GraphQL API
+// на /graphql, схема — parcelgql/schema.graphqls. Запросы принимаются
+// GET и POST, мутации — только POST и только с правами write.
+type GraphQLServer struct {
+	service ParcelService
+	handler http.Handler
+}
+
+func NewGraphQLServer(service ParcelService) *GraphQLServer {
+	s := &GraphQLServer{service: service}
+
+	srv := handler.New(parcelgql.NewExecutableSchema(parcelgql.Config{Resolvers: s}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(graphQLComplexityLimit))
+	srv.SetErrorPresenter(graphQLError)
+	s.handler = srv
+
+	return s
+}
+
+func (s *GraphQLServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *GraphQLServer) Query() parcelgql.QueryResolver       { return gqlQuery{s} }
+func (s *GraphQLServer) Mutation() parcelgql.MutationResolver { return gqlMutation{s} }
+func (s *GraphQLServer) Parcel() parcelgql.ParcelResolver     { return gqlParcel{s} }
+func (s *GraphQLServer) Client() parcelgql.ClientResolver     { return gqlClient{s} }
+
+// graphQLError добавляет к ошибке сервиса код HTTP в extensions.status,
+// как у ответа REST API на ту же ошибку. Детали ошибок БД наружу не отдаются.
+func graphQLError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Err == nil {
+		// ошибка разбора или проверки запроса
+		return gqlErr
+	}
+	status := httpStatus(gqlErr.Err)
+	if status == http.StatusInternalServerError {
+		gqlErr.Message = http.StatusText(status)
+	}
+	gqlErr.Extensions = map[string]any{"status": status}
+	return gqlErr
+}
+
+type gqlQuery struct{ *GraphQLServer }
+
+// Parcel возвращает посылку или null, если её нет.
+func (q gqlQuery) Parcel(ctx context.Context, number int) (*parcelgql.Parcel, error) {
+	return nullIfNotFound(q.service.Get(ctx, number))
+}
+
+func (q gqlQuery) ParcelByTrackCode(ctx context.Context, code string) (*parcelgql.Parcel, error) {
+	return nullIfNotFound(q.service.GetByTrackCode(ctx, code))
+}
+
+func (q gqlQuery) Parcels(ctx context.Context, client *int, status *string, after *int, first *int) (*parcelgql.ParcelPage, error) {
+	var filter Filter
+	if client != nil {
+		filter.Client = *client
+	}
+	if status != nil {
+		st, err := ParseStatus(*status)
+		if err != nil {
+			return nil, err
+		}
+		filter.Statuses = []Status{st}
+	}
+	limit := graphQLPageSize
+	if first != nil {
+		if *first <= 0 || *first > graphQLMaxPageSize {
+			return nil, ErrInvalidListOptions
+		}
+		limit = *first
+	}
+	var afterNumber int
+	if after != nil {
+		afterNumber = *after
+	}
+
+	page, err := q.service.List(ctx, filter, afterNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := &parcelgql.ParcelPage{Parcels: newParcelsGQL(page.Parcels)}
+	if page.NextCursor != "" {
+		last := page.Parcels[len(page.Parcels)-1].Number
+		res.NextAfter = &last
+	}
+	return res, nil
+}
+
+// Client возвращает клиента или null, если его нет.
+func (q gqlQuery) Client(ctx context.Context, id int) (*parcelgql.Client, error) {
+	c, err := q.service.GetClient(ctx, id)
+	if errors.Is(err, ErrClientNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newClientGQL(c), nil
+}
+
+func (q gqlQuery) Clients(ctx context.Context) ([]*parcelgql.Client, error) {
+	clients, err := q.service.ListClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*parcelgql.Client, len(clients))
+	for i, c := range clients {
+		res[i] = newClientGQL(c)
+	}
+	return res, nil
+}
+
+type gqlMutation struct{ *GraphQLServer }
+
+// SetStatus меняет статус посылки и возвращает её после изменения.
+func (m gqlMutation) SetStatus(ctx context.Context, number int, status string) (*parcelgql.Parcel, error) {
+	if err := requireScope(ctx, ScopeWrite); err != nil {
+		return nil, err
+	}
+	st, err := ParseStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.service.SetStatus(ctx, number, st); err != nil {
+		return nil, err
+	}
+	return m.get(ctx, number)
+}
+
+func (m gqlMutation) SetAddress(ctx context.Context, number int, address string, sender *bool) (*parcelgql.Parcel, error) {
+	if err := requireScope(ctx, ScopeWrite); err != nil {
+		return nil, err
+	}
+	set := m.service.ChangeAddress
+	if sender != nil && *sender {
+		set = m.service.ChangeSenderAddress
+	}
+	if err := set(ctx, number, address); err != nil {
+		return nil, err
+	}
+	return m.get(ctx, number)
+}
+
+func (m gqlMutation) get(ctx context.Context, number int) (*parcelgql.Parcel, error) {
+	p, err := m.service.Get(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return newParcelGQL(p), nil
+}
+
+type gqlParcel struct{ *GraphQLServer }
+
+// Client возвращает клиента посылки или null, если хранилище не ведёт клиентов.
+func (r gqlParcel) Client(ctx context.Context, obj *parcelgql.Parcel) (*parcelgql.Client, error) {
+	c, err := r.service.GetClient(ctx, obj.ClientID)
+	if errors.Is(err, ErrNoClientStore) || errors.Is(err, ErrClientNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newClientGQL(c), nil
+}
+
+func (r gqlParcel) History(ctx context.Context, obj *parcelgql.Parcel) ([]*parcelgql.StatusChange, error) {
+	history, err := r.service.GetHistory(ctx, obj.Number)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*parcelgql.StatusChange, len(history))
+	for i, c := range history {
+		res[i] = &parcelgql.StatusChange{OldStatus: string(c.OldStatus), NewStatus: string(c.NewStatus), ChangedAt: c.ChangedAt}
+	}
+	return res, nil
+}
+
+func (r gqlParcel) Events(ctx context.Context, obj *parcelgql.Parcel) ([]*parcelgql.TrackingEvent, error) {
+	events, err := r.service.GetEvents(ctx, obj.Number)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*parcelgql.TrackingEvent, len(events))
+	for i, e := range events {
+		res[i] = &parcelgql.TrackingEvent{ID: e.ID, Code: e.Code, Description: e.Description, OccurredAt: e.OccurredAt}
+	}
+	return res, nil
+}
+
+type gqlClient struct{ *GraphQLServer }
+
+// Parcels возвращает все посылки клиента по возрастанию номера,
+// читая их страницами, как ListByClientStream.
+func (r gqlClient) Parcels(ctx context.Context, obj *parcelgql.Client, status *string) ([]*parcelgql.Parcel, error) {
+	filter := Filter{Client: obj.ID}
+	if status != nil {
+		st, err := ParseStatus(*status)
+		if err != nil {
+			return nil, err
+		}
+		filter.Statuses = []Status{st}
+	}
+
+	res := []*parcelgql.Parcel{}
+	after := 0
+	for {
+		page, err := r.service.List(ctx, filter, after, graphQLPageSize)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, newParcelsGQL(page.Parcels)...)
+		if page.NextCursor == "" {
+			return res, nil
+		}
+		after = page.Parcels[len(page.Parcels)-1].Number
+	}
+}
+
+// nullIfNotFound превращает ErrParcelNotFound в null поля GraphQL.
+func nullIfNotFound(p Parcel, err error) (*parcelgql.Parcel, error) {
+	if errors.Is(err, ErrParcelNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newParcelGQL(p), nil
+}
+
+func newParcelGQL(p Parcel) *parcelgql.Parcel {
+	res := &parcelgql.Parcel{
+		Number:           p.Number,
+		ClientID:         p.Client,
+		Status:           string(p.Status),
+		SenderAddress:    p.SenderAddress,
+		RecipientAddress: p.RecipientAddress,
+		TrackCode:        p.TrackCode,
+		CreatedAt:        formatTime(p.CreatedAt),
+		UpdatedAt:        formatTime(p.UpdatedAt),
+		WeightGrams:      p.WeightGrams,
+		PaymentStatus:    p.PaymentStatus,
+	}
+	if !p.ETA.IsZero() {
+		eta := formatTime(p.ETA)
+		res.Eta = &eta
+	}
+	return res
+}
+
+func newParcelsGQL(parcels []Parcel) []*parcelgql.Parcel {
+	res := make([]*parcelgql.Parcel, len(parcels))
+	for i, p := range parcels {
+		res[i] = newParcelGQL(p)
+	}
+	return res
+}
+
+func newClientGQL(c Client) *parcelgql.Client {
+	return &parcelgql.Client{ID: c.ID, Name: c.Name, Email: c.Email, Phone: c.Phone}
+}
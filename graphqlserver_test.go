@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// graphQLResponse — ответ GraphQL API
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// doGraphQL отправляет запрос query на /graphql с ключом secret и разбирает ответ
+func doGraphQL(t *testing.T, h http.Handler, secret, query string) graphQLResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+// TestGraphQLServer проверяет вложенные запросы клиент → посылки → события
+// и мутации GraphQL API
+func TestGraphQLServer(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t)
+	_, err := store.AddClient(ctx, Client{ID: 1000, Name: "test"})
+	require.NoError(t, err)
+	service := NewParcelService(store).WithClients(store)
+	srv := NewHTTPServer(service)
+
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	_, err = service.AddEvent(ctx, p.Number, "arrived", "Сортировочный центр", time.Now())
+	require.NoError(t, err)
+
+	// add
+	resp := doGraphQL(t, srv, "", `mutation { setStatus(number: `+strconv.Itoa(p.Number)+`, status: "sent") { status } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"setStatus": {"status": "sent"}}`, string(resp.Data))
+
+	// check
+	resp = doGraphQL(t, srv, "", `{ client(id: 1000) { id parcels { number status
+		history { oldStatus newStatus } events { code } client { id } } } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"client": {"id": 1000, "parcels": [{"number": `+strconv.Itoa(p.Number)+`, "status": "sent",
+		"history": [{"oldStatus": "registered", "newStatus": "sent"}],
+		"events": [{"code": "arrived"}], "client": {"id": 1000}}]}}`, string(resp.Data))
+
+	resp = doGraphQL(t, srv, "", `{ parcel(number: 100000) { number } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"parcel": null}`, string(resp.Data))
+
+	resp = doGraphQL(t, srv, "", `{ parcels(status: "sent", first: 1) { parcels { number } nextAfter } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"parcels": {"parcels": [{"number": `+strconv.Itoa(p.Number)+`}], "nextAfter": null}}`, string(resp.Data))
+
+	// ошибка сервиса получает код REST API
+	resp = doGraphQL(t, srv, "", `mutation { setAddress(number: `+strconv.Itoa(p.Number)+`, address: "new") { number } }`)
+	require.Len(t, resp.Errors, 1)
+	require.EqualValues(t, http.StatusConflict, resp.Errors[0].Extensions["status"])
+}
+
+// TestGraphQLAuth проверяет права ключей и арендаторов в GraphQL API
+func TestGraphQLAuth(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := openClientStore(t)
+	_, err := store.AddClient(ctx, Client{ID: 1000, Name: "test"})
+	require.NoError(t, err)
+	service := NewParcelService(store).WithClients(store)
+	_, reader, err := store.IssueAPIKey(ctx, APIKey{Name: "dashboard", Scopes: []string{ScopeRead}})
+	require.NoError(t, err)
+	_, shop, err := store.IssueAPIKey(ctx, APIKey{Name: "shop", Tenant: "acme", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+	h := NewAuthenticator(store).Middleware(NewHTTPServer(service))
+
+	p, err := service.Create(WithTenant(ctx, "acme"), getTestParcel())
+	require.NoError(t, err)
+	setStatus := `mutation { setStatus(number: ` + strconv.Itoa(p.Number) + `, status: "sent") { status } }`
+
+	// check
+	resp := doGraphQL(t, h, reader, setStatus)
+	require.Len(t, resp.Errors, 1)
+	require.EqualValues(t, http.StatusForbidden, resp.Errors[0].Extensions["status"])
+
+	resp = doGraphQL(t, h, shop, setStatus)
+	require.Empty(t, resp.Errors)
+
+	// арендатор видит клиентов своих посылок, но не весь справочник
+	resp = doGraphQL(t, h, shop, `{ parcel(number: `+strconv.Itoa(p.Number)+`) { client { id } } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"parcel": {"client": {"id": 1000}}}`, string(resp.Data))
+	resp = doGraphQL(t, h, shop, `{ clients { id } }`)
+	require.Len(t, resp.Errors, 1)
+	require.EqualValues(t, http.StatusForbidden, resp.Errors[0].Extensions["status"])
+
+	resp = doGraphQL(t, h, reader, `{ clients { id } }`)
+	require.Empty(t, resp.Errors)
+}
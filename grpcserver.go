@@ -0,0 +1,297 @@
+package main
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelpb"
+)
+
+// GRPCServer реализует parcelpb.ParcelServiceServer поверх сервиса посылок.
+type GRPCServer struct {
+	parcelpb.UnimplementedParcelServiceServer
+
+	service ParcelService
+}
+
+func NewGRPCServer(service ParcelService) *GRPCServer {
+	return &GRPCServer{service: service}
+}
+
+// Add регистрирует посылку; ключ идемпотентности берётся из метаданных
+// idempotency-key, см. WithIdempotencyKey.
+func (s *GRPCServer) Add(ctx context.Context, req *parcelpb.AddRequest) (*parcelpb.Parcel, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(idempotencyKeyHeader); len(v) > 0 {
+			ctx = WithIdempotencyKey(ctx, v[0])
+		}
+	}
+	p, err := s.service.Create(ctx, Parcel{
+		Client:           int(req.GetClient()),
+		SenderAddress:    req.GetSenderAddress(),
+		RecipientAddress: req.GetRecipientAddress(),
+		TrackCode:        NewTrackCode(),
+		WeightGrams:      int(req.GetWeightGrams()),
+		LengthMM:         int(req.GetLengthMm()),
+		WidthMM:          int(req.GetWidthMm()),
+		HeightMM:         int(req.GetHeightMm()),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return newParcelProto(p), nil
+}
+
+func (s *GRPCServer) Get(ctx context.Context, req *parcelpb.GetRequest) (*parcelpb.Parcel, error) {
+	p, err := s.service.Get(ctx, int(req.GetNumber()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return newParcelProto(p), nil
+}
+
+func (s *GRPCServer) GetByTrackCode(ctx context.Context, req *parcelpb.GetByTrackCodeRequest) (*parcelpb.Parcel, error) {
+	p, err := s.service.GetByTrackCode(ctx, req.GetTrackCode())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return newParcelProto(p), nil
+}
+
+func (s *GRPCServer) ListByClient(ctx context.Context, req *parcelpb.ListByClientRequest) (*parcelpb.ListByClientResponse, error) {
+	page, err := s.service.ListByClient(ctx, int(req.GetClient()), ListOptions{
+		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+		SortBy: req.GetSortBy(),
+		Desc:   req.GetDesc(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &parcelpb.ListByClientResponse{Total: int64(page.Total)}
+	for _, p := range page.Parcels {
+		resp.Parcels = append(resp.Parcels, newParcelProto(p))
+	}
+
+	return resp, nil
+}
+
+// streamPageSize — сколько посылок ListByClientStream читает из хранилища за раз.
+const streamPageSize = 100
+
+// ListByClientStream читает посылки страницами по номеру, как List,
+// и отправляет их по одной, поэтому ни сервер, ни клиент не держат
+// в памяти весь список клиента.
+func (s *GRPCServer) ListByClientStream(req *parcelpb.ListByClientStreamRequest, stream parcelpb.ParcelService_ListByClientStreamServer) error {
+	ctx := stream.Context()
+	if req.GetClient() <= 0 {
+		return grpcError(fmt.Errorf("%w: client is required", ErrInvalidListOptions))
+	}
+	filter := Filter{Client: int(req.GetClient())}
+	if req.GetStatus() != "" {
+		status, err := ParseStatus(req.GetStatus())
+		if err != nil {
+			return grpcError(err)
+		}
+		filter.Statuses = []Status{status}
+	}
+
+	after := int(req.GetAfterNumber())
+	for {
+		page, err := s.service.List(ctx, filter, after, streamPageSize)
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, p := range page.Parcels {
+			if err := stream.Send(newParcelProto(p)); err != nil {
+				return err
+			}
+			after = p.Number
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+	}
+}
+
+// StreamStatusUpdates меняет статусы, как SetStatus, и отвечает на каждый
+// запрос результатом с кодом gRPC. Поток закрывается, когда клиент закончил
+// отправку или соединение оборвалось.
+func (s *GRPCServer) StreamStatusUpdates(stream parcelpb.ParcelService_StreamStatusUpdatesServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		res := &parcelpb.StatusUpdateResult{Number: req.GetNumber()}
+		if _, err := s.SetStatus(stream.Context(), req); err != nil {
+			st := status.Convert(err)
+			res.Code, res.Message = int32(st.Code()), st.Message()
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *GRPCServer) SetStatus(ctx context.Context, req *parcelpb.SetStatusRequest) (*parcelpb.SetStatusResponse, error) {
+	status, err := ParseStatus(req.GetStatus())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err := s.service.SetStatus(ctx, int(req.GetNumber()), status); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &parcelpb.SetStatusResponse{}, nil
+}
+
+func (s *GRPCServer) MarkPaid(ctx context.Context, req *parcelpb.MarkPaidRequest) (*parcelpb.MarkPaidResponse, error) {
+	if err := s.service.MarkPaid(ctx, int(req.GetNumber()), req.GetTxRef()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &parcelpb.MarkPaidResponse{}, nil
+}
+
+func (s *GRPCServer) SetAddress(ctx context.Context, req *parcelpb.SetAddressRequest) (*parcelpb.SetAddressResponse, error) {
+	set := s.service.ChangeAddress
+	if req.GetSender() {
+		set = s.service.ChangeSenderAddress
+	}
+	if err := set(ctx, int(req.GetNumber()), req.GetAddress()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &parcelpb.SetAddressResponse{}, nil
+}
+
+func (s *GRPCServer) Delete(ctx context.Context, req *parcelpb.DeleteRequest) (*parcelpb.DeleteResponse, error) {
+	if err := s.service.Delete(ctx, int(req.GetNumber())); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &parcelpb.DeleteResponse{}, nil
+}
+
+func newParcelProto(p Parcel) *parcelpb.Parcel {
+	var paidAt string
+	if !p.PaidAt.IsZero() {
+		paidAt = formatTime(p.PaidAt)
+	}
+	return &parcelpb.Parcel{
+		Number:           int64(p.Number),
+		Client:           int64(p.Client),
+		Status:           string(p.Status),
+		SenderAddress:    p.SenderAddress,
+		RecipientAddress: p.RecipientAddress,
+		CreatedAt:        formatTime(p.CreatedAt),
+		UpdatedAt:        formatTime(p.UpdatedAt),
+		TrackCode:        p.TrackCode,
+		Uuid:             p.UUID,
+		WeightGrams:      int32(p.WeightGrams),
+		LengthMm:         int32(p.LengthMM),
+		WidthMm:          int32(p.WidthMM),
+		HeightMm:         int32(p.HeightMM),
+		Price:            p.Price,
+		Amount:           p.Amount,
+		Currency:         p.Currency,
+		PaymentStatus:    p.PaymentStatus,
+		PaidAt:           paidAt,
+	}
+}
+
+// grpcCode сопоставляет ошибки хранилища кодам gRPC.
+func grpcCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, ErrParcelNotFound),
+		errors.Is(err, ErrClientNotFound),
+		errors.Is(err, ErrCourierNotFound),
+		errors.Is(err, ErrLocationNotFound),
+		errors.Is(err, ErrClaimNotFound),
+		errors.Is(err, ErrAttachmentNotFound),
+		errors.Is(err, ErrBlobNotFound),
+		errors.Is(err, ErrSignatureNotFound),
+		errors.Is(err, ErrManifestNotFound),
+		errors.Is(err, ErrNoPriceRate):
+		return codes.NotFound
+	case errors.Is(err, ErrAddressChangeNotAllowed),
+		errors.Is(err, ErrDeleteNotAllowed),
+		errors.Is(err, ErrCancelNotAllowed),
+		errors.Is(err, ErrOpenClaim),
+		errors.Is(err, ErrClaimResolved),
+		errors.Is(err, ErrProofNotAllowed),
+		errors.Is(err, ErrAttemptNotAllowed),
+		errors.Is(err, ErrClientHasParcels),
+		errors.Is(err, ErrAssignNotAllowed),
+		errors.Is(err, ErrNotAssigned),
+		errors.Is(err, ErrInvalidTransition),
+		errors.Is(err, ErrPaymentRequired),
+		errors.Is(err, ErrAlreadyPaid),
+		errors.Is(err, ErrCrossShardUpdate):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrConflict):
+		return codes.Aborted
+	case errors.Is(err, ErrUnsupportedFilter):
+		return codes.Unimplemented
+	case errors.Is(err, ErrInvalidListOptions),
+		errors.Is(err, ErrUnknownStatus),
+		errors.Is(err, ErrInvalidSort),
+		errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, ErrInvalidSearchQuery),
+		errors.Is(err, ErrInvalidEvent),
+		errors.Is(err, ErrInvalidRange),
+		errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, ErrInvalidTrackCode),
+		errors.Is(err, ErrInvalidAddress),
+		errors.Is(err, ErrInvalidCoordinates),
+		errors.Is(err, ErrInvalidDimensions),
+		errors.Is(err, ErrInvalidPriceRate),
+		errors.Is(err, ErrInvalidPayment),
+		errors.Is(err, ErrInvalidUUID),
+		errors.Is(err, ErrInvalidImport),
+		errors.Is(err, ErrInvalidLocation),
+		errors.Is(err, ErrInvalidParcel),
+		errors.Is(err, ErrInvalidIdempotencyKey),
+		errors.Is(err, ErrIdempotentBatch),
+		errors.Is(err, ErrUnknownServiceClass),
+		errors.Is(err, ErrInvalidCancelReason),
+		errors.Is(err, ErrInvalidClaim),
+		errors.Is(err, ErrInvalidNote),
+		errors.Is(err, ErrInvalidAttachment),
+		errors.Is(err, ErrInvalidAttempt),
+		errors.Is(err, ErrInvalidManifest),
+		errors.Is(err, ErrInvalidSignature):
+		return codes.InvalidArgument
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+func grpcError(err error) error {
+	code := grpcCode(err)
+	if code == codes.Internal {
+		// детали ошибок БД наружу не отдаём
+		return status.Error(code, "internal error")
+	}
+	return status.Error(code, err.Error())
+}
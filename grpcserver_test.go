@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelpb"
+)
+
+// newGRPCClient поднимает gRPC-сервер в памяти и возвращает клиента к нему
+func newGRPCClient(t *testing.T, store ParcelStorage) parcelpb.ParcelServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := grpc.NewServer()
+	parcelpb.RegisterParcelServiceServer(srv, NewGRPCServer(NewParcelService(store)))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return parcelpb.NewParcelServiceClient(conn)
+}
+
+// TestGRPCServer проверяет основные операции gRPC-сервиса
+func TestGRPCServer(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	client := newGRPCClient(t, NewMemoryParcelStore())
+
+	// add
+	created, err := client.Add(ctx, &parcelpb.AddRequest{Client: 7, RecipientAddress: "test"})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.GetNumber())
+	require.Equal(t, ParcelStatusRegistered.String(), created.GetStatus())
+
+	// get
+	stored, err := client.Get(ctx, &parcelpb.GetRequest{Number: created.GetNumber()})
+	require.NoError(t, err)
+	require.Equal(t, created.GetRecipientAddress(), stored.GetRecipientAddress())
+
+	// set address
+	_, err = client.SetAddress(ctx, &parcelpb.SetAddressRequest{Number: created.GetNumber(), Address: "new"})
+	require.NoError(t, err)
+
+	// set status
+	_, err = client.SetStatus(ctx, &parcelpb.SetStatusRequest{Number: created.GetNumber(), Status: string(ParcelStatusSent)})
+	require.NoError(t, err)
+
+	// list by client
+	list, err := client.ListByClient(ctx, &parcelpb.ListByClientRequest{Client: 7})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, list.GetTotal())
+	require.Equal(t, "new", list.GetParcels()[0].GetRecipientAddress())
+
+	// delete sent parcel
+	_, err = client.Delete(ctx, &parcelpb.DeleteRequest{Number: created.GetNumber()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// not found
+	_, err = client.Get(ctx, &parcelpb.GetRequest{Number: 100})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// invalid sort
+	_, err = client.ListByClient(ctx, &parcelpb.ListByClientRequest{Client: 7, SortBy: "address"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestGRPCListByClientStream проверяет потоковый список посылок клиента
+// больше одной страницы хранилища
+func TestGRPCListByClientStream(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	client := newGRPCClient(t, NewMemoryParcelStore())
+	var numbers []int64
+	for range streamPageSize + 5 {
+		created, err := client.Add(ctx, &parcelpb.AddRequest{Client: 7, RecipientAddress: "test"})
+		require.NoError(t, err)
+		numbers = append(numbers, created.GetNumber())
+	}
+	_, err := client.Add(ctx, &parcelpb.AddRequest{Client: 8, RecipientAddress: "test"})
+	require.NoError(t, err)
+	recv := func(req *parcelpb.ListByClientStreamRequest) ([]int64, error) {
+		stream, err := client.ListByClientStream(ctx, req)
+		require.NoError(t, err)
+		var res []int64
+		for {
+			p, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return res, nil
+			}
+			if err != nil {
+				return res, err
+			}
+			res = append(res, p.GetNumber())
+		}
+	}
+
+	// check
+	got, err := recv(&parcelpb.ListByClientStreamRequest{Client: 7})
+	require.NoError(t, err)
+	require.Equal(t, numbers, got)
+
+	got, err = recv(&parcelpb.ListByClientStreamRequest{Client: 7, AfterNumber: numbers[streamPageSize]})
+	require.NoError(t, err)
+	require.Equal(t, numbers[streamPageSize+1:], got)
+
+	got, err = recv(&parcelpb.ListByClientStreamRequest{Client: 7, Status: string(ParcelStatusSent)})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = recv(&parcelpb.ListByClientStreamRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = recv(&parcelpb.ListByClientStreamRequest{Client: 7, Status: "lost"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestGRPCStreamStatusUpdates проверяет, что ошибка одного обновления
+// в потоке не мешает остальным
+func TestGRPCStreamStatusUpdates(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	client := newGRPCClient(t, NewMemoryParcelStore())
+	created, err := client.Add(ctx, &parcelpb.AddRequest{Client: 7, RecipientAddress: "test"})
+	require.NoError(t, err)
+	stream, err := client.StreamStatusUpdates(ctx)
+	require.NoError(t, err)
+
+	// add
+	for _, req := range []*parcelpb.SetStatusRequest{
+		{Number: created.GetNumber(), Status: string(ParcelStatusSent)},
+		{Number: created.GetNumber() + 100, Status: string(ParcelStatusSent)},
+		{Number: created.GetNumber(), Status: string(ParcelStatusDelivered)},
+	} {
+		require.NoError(t, stream.Send(req))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	// check
+	var codesGot []codes.Code
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		codesGot = append(codesGot, codes.Code(res.GetCode()))
+		if res.GetCode() != 0 {
+			require.Equal(t, created.GetNumber()+100, res.GetNumber())
+			require.NotEmpty(t, res.GetMessage())
+		}
+	}
+	require.Equal(t, []codes.Code{codes.OK, codes.NotFound, codes.OK}, codesGot)
+
+	stored, err := client.Get(ctx, &parcelpb.GetRequest{Number: created.GetNumber()})
+	require.NoError(t, err)
+	require.Equal(t, string(ParcelStatusDelivered), stored.GetStatus())
+}
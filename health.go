@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// healthTimeout ограничивает проверку готовности: зависшая БД должна
+// означать «не готов», а не зависший запрос оркестратора.
+const healthTimeout = 2 * time.Second
+
+// ErrSchemaOutdated означает, что миграции ещё не применены к БД.
+var ErrSchemaOutdated = errors.New("database schema is not migrated")
+
+// HealthReport — состояние хранилища для /readyz.
+type HealthReport struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// SchemaVersion — применённая версия схемы, LatestSchemaVersion —
+	// последняя миграция в бинарнике; у хранилища в памяти обе нулевые
+	SchemaVersion       int        `json:"schema_version"`
+	LatestSchemaVersion int        `json:"latest_schema_version"`
+	Pool                *PoolStats `json:"pool,omitempty"`
+}
+
+// PoolStats — срез sql.DBStats для отчёта о здоровье.
+type PoolStats struct {
+	MaxOpen   int    `json:"max_open"`
+	Open      int    `json:"open"`
+	InUse     int    `json:"in_use"`
+	Idle      int    `json:"idle"`
+	WaitCount int64  `json:"wait_count"`
+	WaitTime  string `json:"wait_time"`
+}
+
+// HealthChecker проверяет, что хранилище может обслуживать запросы.
+type HealthChecker interface {
+	// Health возвращает отчёт и ошибку, если хранилище не готово
+	Health(ctx context.Context) (HealthReport, error)
+}
+
+var (
+	_ HealthChecker = ParcelStore{}
+	_ HealthChecker = (*MemoryParcelStore)(nil)
+)
+
+// Health пингует БД, сверяет версию схемы с последней миграцией
+// и возвращает статистику пула соединений.
+func (s ParcelStore) Health(ctx context.Context) (HealthReport, error) {
+	db, err := s.sqlDB()
+	if err != nil {
+		return HealthReport{}, err
+	}
+	stats := db.Stats()
+	report := HealthReport{Pool: &PoolStats{
+		MaxOpen:   stats.MaxOpenConnections,
+		Open:      stats.OpenConnections,
+		InUse:     stats.InUse,
+		Idle:      stats.Idle,
+		WaitCount: stats.WaitCount,
+		WaitTime:  stats.WaitDuration.String(),
+	}}
+
+	if err := db.PingContext(ctx); err != nil {
+		return report, err
+	}
+
+	migrations, err := loadMigrations(s.dialect.name)
+	if err != nil {
+		return report, err
+	}
+	if len(migrations) > 0 {
+		report.LatestSchemaVersion = migrations[len(migrations)-1].version
+	}
+	if report.SchemaVersion, err = s.SchemaVersion(ctx); err != nil {
+		return report, err
+	}
+	if report.SchemaVersion < report.LatestSchemaVersion {
+		return report, ErrSchemaOutdated
+	}
+
+	return report, nil
+}
+
+// Health у хранилища в памяти всегда успешен.
+func (s *MemoryParcelStore) Health(context.Context) (HealthReport, error) {
+	return HealthReport{}, nil
+}
+
+// NewHealthHandler отдаёт GET /healthz и GET /readyz. /healthz отвечает 200,
+// пока процесс обслуживает HTTP, и не ходит в БД: недоступная БД не повод
+// перезапускать процесс. /readyz проверяет checker с таймаутом healthTimeout
+// и отвечает 503, пока хранилище не готово, чтобы на процесс не шёл трафик.
+func NewHealthHandler(checker HealthChecker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, HealthReport{Status: "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+		defer cancel()
+
+		report, err := checker.Health(ctx)
+		if err != nil {
+			report.Status, report.Error = "unavailable", err.Error()
+			writeJSON(w, http.StatusServiceUnavailable, report)
+			return
+		}
+		report.Status = "ok"
+		writeJSON(w, http.StatusOK, report)
+	})
+	return mux
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingHealth — хранилище, которое никогда не готово
+type failingHealth struct{}
+
+func (failingHealth) Health(context.Context) (HealthReport, error) {
+	return HealthReport{SchemaVersion: 3}, errors.New("connection refused")
+}
+
+// TestStoreHealth проверяет пинг БД, версию схемы и статистику пула
+func TestStoreHealth(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	db := openTempDB(t)
+	store := NewParcelStore(db)
+
+	// check
+	report, err := store.Health(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, report.SchemaVersion)
+	require.Equal(t, report.LatestSchemaVersion, report.SchemaVersion)
+	require.NotNil(t, report.Pool)
+
+	_, err = db.Exec("DELETE FROM schema_migrations WHERE version = ?", report.SchemaVersion)
+	require.NoError(t, err)
+	_, err = store.Health(ctx)
+	require.ErrorIs(t, err, ErrSchemaOutdated)
+
+	require.NoError(t, db.Close())
+	_, err = store.Health(ctx)
+	require.Error(t, err)
+}
+
+// TestHealthHandler проверяет ответы /healthz и /readyz
+func TestHealthHandler(t *testing.T) {
+	// prepare
+	do := func(h http.Handler, target string) (int, HealthReport) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var report HealthReport
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+		return rec.Code, report
+	}
+	ready := NewHealthHandler(NewParcelStore(openTempDB(t)))
+	broken := NewHealthHandler(failingHealth{})
+
+	// check
+	code, report := do(ready, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", report.Status)
+	require.NotZero(t, report.SchemaVersion)
+
+	code, report = do(broken, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "unavailable", report.Status)
+	require.Equal(t, "connection refused", report.Error)
+
+	code, _ = do(broken, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
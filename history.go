@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// StatusChange — запись истории статусов посылки.
+type StatusChange struct {
+	Number    int
+	OldStatus Status
+	NewStatus Status
+	ChangedAt string
+}
+
+func (s ParcelStore) addHistory(ctx context.Context, c StatusChange) error {
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"INSERT INTO parcel_status_history (parcel_number, old_status, new_status, changed_at) VALUES (?, ?, ?, ?)"),
+		c.Number, c.OldStatus, c.NewStatus, c.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("add status history: %w", err)
+	}
+
+	return nil
+}
+
+// GetHistory возвращает смены статуса посылки в хронологическом порядке.
+// Для посылки без смен статуса возвращается пустой срез,
+// для несуществующей посылки — ErrParcelNotFound.
+func (s ParcelStore) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.reader(number).QueryContext(ctx, s.dialect.rebind(
+		"SELECT parcel_number, old_status, new_status, changed_at FROM parcel_status_history WHERE parcel_number = ? ORDER BY id"),
+		number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []StatusChange
+	for rows.Next() {
+		c := StatusChange{}
+		err := rows.Scan(&c.Number, &c.OldStatus, &c.NewStatus, &c.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetHistory возвращает смены статуса посылки в хронологическом порядке.
+func (s *MemoryParcelStore) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return nil, ErrParcelNotFound
+	}
+
+	return append([]StatusChange(nil), s.history[number]...), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetHistory проверяет запись истории при смене статуса
+func TestGetHistory(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	stores := map[string]ParcelStorage{
+		"sqlite": NewParcelStore(db),
+		"memory": NewMemoryParcelStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			id, err := store.Add(ctx, getTestParcel())
+			require.NoError(t, err)
+
+			// empty history
+			history, err := store.GetHistory(ctx, id)
+			require.NoError(t, err)
+			require.Empty(t, history)
+
+			// set status
+			require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+			require.NoError(t, store.SetStatus(ctx, id, ParcelStatusDelivered))
+
+			// rejected transition is not recorded
+			require.Error(t, store.SetStatus(ctx, id, ParcelStatusSent))
+
+			// check
+			history, err = store.GetHistory(ctx, id)
+			require.NoError(t, err)
+			require.Len(t, history, 2)
+			require.Equal(t, ParcelStatusRegistered, history[0].OldStatus)
+			require.Equal(t, ParcelStatusSent, history[0].NewStatus)
+			require.Equal(t, ParcelStatusSent, history[1].OldStatus)
+			require.Equal(t, ParcelStatusDelivered, history[1].NewStatus)
+			require.NotEmpty(t, history[1].ChangedAt)
+
+			// not found
+			_, err = store.GetHistory(ctx, -1)
+			require.ErrorIs(t, err, ErrParcelNotFound)
+		})
+	}
+}
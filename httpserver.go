@@ -0,0 +1,841 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPServer отдаёт операции сервиса посылок через REST API.
+type HTTPServer struct {
+	service ParcelService
+	mux     *http.ServeMux
+	// heartbeat — период комментариев в молчащих потоках Server-Sent Events
+	heartbeat time.Duration
+	// closing закрывает CloseStreams, чтобы завершить потоки
+	closing   chan struct{}
+	closeOnce sync.Once
+	// maxSubscriptions — лимит подписок одного соединения WebSocket
+	maxSubscriptions int
+}
+
+func NewHTTPServer(service ParcelService) *HTTPServer {
+	s := &HTTPServer{service: service, mux: http.NewServeMux(), heartbeat: sseHeartbeat, closing: make(chan struct{}),
+		maxSubscriptions: wsMaxSubscriptions}
+
+	s.mux.HandleFunc("POST /parcels", s.handleAdd)
+	s.mux.HandleFunc("GET /parcels", s.handleList)
+	s.mux.HandleFunc("GET /parcels/{number}", s.handleGet)
+	s.mux.HandleFunc("GET /parcels/{number}/label", s.handleLabel)
+	s.mux.HandleFunc("GET /parcels/{number}/watch", s.handleWatch)
+	s.mux.HandleFunc("GET /parcels/{number}/events/stream", s.handleTrackingStream)
+	s.mux.HandleFunc("GET /ws", s.handleWS)
+	s.mux.HandleFunc("GET /track/{code}", s.handleTrack)
+	s.mux.HandleFunc("GET /clients/{id}/parcels", s.handleListByClient)
+	s.mux.HandleFunc("PATCH /parcels/{number}/status", s.handleSetStatus)
+	s.mux.HandleFunc("PATCH /parcels/{number}/address", s.handleSetAddress)
+	s.mux.HandleFunc("POST /parcels/{number}/payment", s.handleMarkPaid)
+	s.mux.HandleFunc("POST /parcels/{number}/cancel", s.handleCancel)
+	s.mux.HandleFunc("POST /parcels/{number}/deliver", s.handleDeliver)
+	s.mux.HandleFunc("POST /parcels/{number}/return", s.handleCreateReturn)
+	s.mux.HandleFunc("GET /parcels/{number}/return", s.handleGetReturn)
+	s.mux.HandleFunc("POST /parcels/{number}/attachments", s.handleAttach)
+	s.mux.HandleFunc("GET /parcels/{number}/attachments", s.handleListAttachments)
+	s.mux.HandleFunc("GET /attachments/{id}", s.handleGetAttachment)
+	s.mux.HandleFunc("DELETE /parcels/{number}", s.handleDelete)
+
+	graphQL := NewGraphQLServer(service)
+	s.mux.Handle("GET /graphql", graphQL)
+	s.mux.Handle("POST /graphql", graphQL)
+
+	admin := NewAdminServer(service)
+	s.mux.Handle("GET /admin", admin)
+	s.mux.Handle("/admin/", admin)
+
+	return s
+}
+
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// CloseStreams завершает открытые потоки Server-Sent Events и соединения WebSocket.
+// http.Server.Shutdown ждёт, пока соединения освободятся, а поток
+// не освобождает соединение сам, поэтому Server вызывает CloseStreams
+// в начале остановки.
+func (s *HTTPServer) CloseStreams() {
+	s.closeOnce.Do(func() { close(s.closing) })
+}
+
+type parcelResponse struct {
+	Number int    `json:"number"`
+	Client int    `json:"client"`
+	Status string `json:"status"`
+	// StatusName — название статуса на языке из заголовка Accept-Language
+	StatusName       string `json:"status_name"`
+	SenderAddress    string `json:"sender_address,omitempty"`
+	RecipientAddress string `json:"recipient_address"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+	TrackCode        string `json:"track_code"`
+	UUID             string `json:"uuid,omitempty"`
+	ETA              string `json:"eta,omitempty"`
+	WeightGrams      int    `json:"weight_grams,omitempty"`
+	LengthMM         int    `json:"length_mm,omitempty"`
+	WidthMM          int    `json:"width_mm,omitempty"`
+	HeightMM         int    `json:"height_mm,omitempty"`
+	Price            int64  `json:"price,omitempty"`
+	Amount           int64  `json:"amount"`
+	Currency         string `json:"currency"`
+	PaymentStatus    string `json:"payment_status"`
+	PaidAt           string `json:"paid_at,omitempty"`
+	ServiceClass     string `json:"service_class,omitempty"`
+	SLADeadline      string `json:"sla_deadline,omitempty"`
+	SLABreachedAt    string `json:"sla_breached_at,omitempty"`
+	// ReturnOf — номер исходной посылки у возвратной
+	ReturnOf     int    `json:"return_of,omitempty"`
+	CancelReason string `json:"cancel_reason,omitempty"`
+	CancelledAt  string `json:"cancelled_at,omitempty"`
+	// HasSignature сообщает, что получатель расписался при доставке
+	HasSignature bool   `json:"has_signature"`
+	SignedBy     string `json:"signed_by,omitempty"`
+	SignedAt     string `json:"signed_at,omitempty"`
+}
+
+type parcelListResponse struct {
+	Parcels []parcelResponse `json:"parcels"`
+	Total   int              `json:"total"`
+}
+
+// parcelCursorResponse — страница постраничного обхода; следующую
+// отдаёт запрос с cursor=next_cursor, на последней next_cursor нет.
+type parcelCursorResponse struct {
+	Parcels    []parcelResponse `json:"parcels"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type addParcelRequest struct {
+	Client           int    `json:"client"`
+	SenderAddress    string `json:"sender_address"`
+	RecipientAddress string `json:"recipient_address"`
+	WeightGrams      int    `json:"weight_grams"`
+	LengthMM         int    `json:"length_mm"`
+	WidthMM          int    `json:"width_mm"`
+	HeightMM         int    `json:"height_mm"`
+	// ServiceClass — класс обслуживания economy, standard или express,
+	// пустой — класс по умолчанию из настроек sla
+	ServiceClass string `json:"service_class"`
+}
+
+type statusChangeResponse struct {
+	OldStatus string `json:"old_status"`
+	NewStatus string `json:"new_status"`
+	ChangedAt string `json:"changed_at"`
+}
+
+type trackingEventResponse struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+	OccurredAt  string `json:"occurred_at"`
+}
+
+type attachmentResponse struct {
+	ID          int    `json:"id"`
+	Number      int    `json:"number"`
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	CreatedAt   string `json:"created_at"`
+}
+
+func newAttachmentResponse(a Attachment) attachmentResponse {
+	return attachmentResponse{
+		ID:          a.ID,
+		Number:      a.Number,
+		Kind:        string(a.Kind),
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		SHA256:      a.SHA256,
+		CreatedAt:   formatTime(a.CreatedAt),
+	}
+}
+
+type setStatusRequest struct {
+	Status Status `json:"status"`
+}
+
+// setAddressRequest меняет адрес получателя, а с Sender — адрес отправителя.
+type setAddressRequest struct {
+	Address string `json:"address"`
+	Sender  bool   `json:"sender"`
+}
+
+type markPaidRequest struct {
+	TxRef string `json:"tx_ref"`
+}
+
+type cancelRequest struct {
+	Reason CancelReason `json:"reason"`
+}
+
+// deliverRequest — подпись получателя: PNG в base64 и имя расписавшегося.
+type deliverRequest struct {
+	SignedBy  string `json:"signed_by"`
+	Signature []byte `json:"signature"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Fields — ошибки по полям посылки, не прошедшей Parcel.Validate
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+func newParcelResponse(p Parcel, lang string) parcelResponse {
+	var eta, paidAt, deadline, breachedAt, cancelledAt, signedAt string
+	if !p.ETA.IsZero() {
+		eta = formatTime(p.ETA)
+	}
+	if !p.PaidAt.IsZero() {
+		paidAt = formatTime(p.PaidAt)
+	}
+	if !p.SLADeadline.IsZero() {
+		deadline = formatTime(p.SLADeadline)
+	}
+	if !p.SLABreachedAt.IsZero() {
+		breachedAt = formatTime(p.SLABreachedAt)
+	}
+	if !p.CancelledAt.IsZero() {
+		cancelledAt = formatTime(p.CancelledAt)
+	}
+	if p.HasSignature() {
+		signedAt = formatTime(p.SignedAt)
+	}
+	return parcelResponse{
+		Number:           p.Number,
+		Client:           p.Client,
+		Status:           string(p.Status),
+		StatusName:       p.Status.DisplayName(lang),
+		SenderAddress:    p.SenderAddress,
+		RecipientAddress: p.RecipientAddress,
+		CreatedAt:        formatTime(p.CreatedAt),
+		UpdatedAt:        formatTime(p.UpdatedAt),
+		TrackCode:        p.TrackCode,
+		UUID:             p.UUID,
+		ETA:              eta,
+		WeightGrams:      p.WeightGrams,
+		LengthMM:         p.LengthMM,
+		WidthMM:          p.WidthMM,
+		HeightMM:         p.HeightMM,
+		Price:            p.Price,
+		Amount:           p.Amount,
+		Currency:         p.Currency,
+		PaymentStatus:    p.PaymentStatus,
+		PaidAt:           paidAt,
+		ServiceClass:     p.ServiceClass,
+		SLADeadline:      deadline,
+		SLABreachedAt:    breachedAt,
+		ReturnOf:         p.ReturnOf,
+		CancelReason:     string(p.CancelReason),
+		CancelledAt:      cancelledAt,
+		HasSignature:     p.HasSignature(),
+		SignedBy:         p.SignedBy,
+		SignedAt:         signedAt,
+	}
+}
+
+// requestLang возвращает первый язык из заголовка Accept-Language без веса,
+// например ru-RU из "ru-RU,ru;q=0.9,en;q=0.8".
+func requestLang(r *http.Request) string {
+	lang, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	lang, _, _ = strings.Cut(lang, ";")
+	return strings.TrimSpace(lang)
+}
+
+func (s *HTTPServer) handleAdd(w http.ResponseWriter, r *http.Request) {
+	var req addParcelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := WithIdempotencyKey(r.Context(), r.Header.Get(idempotencyKeyHeader))
+	p, err := s.service.Create(ctx, Parcel{
+		Client:           req.Client,
+		SenderAddress:    req.SenderAddress,
+		RecipientAddress: req.RecipientAddress,
+		TrackCode:        NewTrackCode(),
+		WeightGrams:      req.WeightGrams,
+		LengthMM:         req.LengthMM,
+		WidthMM:          req.WidthMM,
+		HeightMM:         req.HeightMM,
+		ServiceClass:     req.ServiceClass,
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newParcelResponse(p, requestLang(r)))
+}
+
+func (s *HTTPServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	p, err := s.service.Get(r.Context(), number)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newParcelResponse(p, requestLang(r)))
+}
+
+// handleLabel отдаёт этикетку посылки с трек-кодом, формат задаёт
+// параметр format: PNG code128 (по умолчанию) или qr либо PDF pdf.
+func (s *HTTPServer) handleLabel(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	label, err := s.service.GenerateLabel(r.Context(), number, format)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", labelContentType(format))
+	w.Write(label)
+}
+
+// handleWatch отдаёт изменения посылки потоком Server-Sent Events:
+// на каждое событие Watch — запись с типом события в event и ParcelEvent
+// в JSON в data. Поток заканчивается удалением посылки, отключением
+// клиента или остановкой сервера.
+func (s *HTTPServer) handleWatch(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := s.streamContext(r)
+	defer cancel()
+	events, err := s.service.Watch(ctx, number)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	streamSSE(s, w, events, func(e ParcelEvent) sseMessage {
+		return sseMessage{event: e.Type, data: e}
+	})
+}
+
+// handleTrackingStream отдаёт ленту отслеживания посылки потоком
+// Server-Sent Events: смены статуса (event: status_changed) и события
+// отслеживания (event: tracking_event) с курсором ленты в id. Клиент,
+// переподключившийся с заголовком Last-Event-ID, получает только записи
+// после него, без заголовка — всю ленту с начала.
+func (s *HTTPServer) handleTrackingStream(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+	after, err := ParseTrackingCursor(r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := s.streamContext(r)
+	defer cancel()
+	updates, err := s.service.StreamTracking(ctx, number, after)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	streamSSE(s, w, updates, func(u TrackingUpdate) sseMessage {
+		msg := sseMessage{id: u.Cursor.String()}
+		if c := u.StatusChange; c != nil {
+			msg.event = "status_changed"
+			msg.data = statusChangeResponse{OldStatus: string(c.OldStatus), NewStatus: string(c.NewStatus), ChangedAt: c.ChangedAt}
+		} else {
+			e := u.Event
+			msg.event = "tracking_event"
+			msg.data = trackingEventResponse{ID: e.ID, Code: e.Code, Description: e.Description, OccurredAt: e.OccurredAt}
+		}
+		return msg
+	})
+}
+
+// handleTrack ищет посылку по трек-коду, который выдаётся клиентам.
+func (s *HTTPServer) handleTrack(w http.ResponseWriter, r *http.Request) {
+	p, err := s.service.GetByTrackCode(r.Context(), r.PathValue("code"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newParcelResponse(p, requestLang(r)))
+}
+
+// handleListByClient поддерживает параметры limit, offset, sort и desc.
+func (s *HTTPServer) handleListByClient(w http.ResponseWriter, r *http.Request) {
+	client, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	// с параметром cursor, даже пустым, список отдаётся по курсору
+	if r.URL.Query().Has("cursor") {
+		s.listByCursor(w, r, Filter{Client: client})
+		return
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := s.service.ListByClient(r.Context(), client, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	resp := parcelListResponse{Parcels: []parcelResponse{}, Total: page.Total}
+	for _, p := range page.Parcels {
+		resp.Parcels = append(resp.Parcels, newParcelResponse(p, requestLang(r)))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleList отдаёт посылки по фильтру client, status, from, to, address,
+// courier, sla_breached и service_class постранично по курсору. Параметр
+// status можно повторить или перечислить статусы через запятую.
+// Значения проверяет хранилище, см. Filter.
+func (s *HTTPServer) handleList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := Filter{Address: q.Get("address"), ServiceClass: q.Get("service_class")}
+	statuses, err := parseStatuses(q["status"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	filter.Statuses = statuses
+	for name, id := range map[string]*int{"client": &filter.Client, "courier": &filter.Courier} {
+		if v := q.Get(name); v != "" {
+			if *id, err = strconv.Atoi(v); err != nil {
+				writeError(w, http.StatusBadRequest, errors.New("invalid "+name))
+				return
+			}
+		}
+	}
+	if v := q.Get("sla_breached"); v != "" {
+		if filter.SLABreached, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid sla_breached"))
+			return
+		}
+	}
+	for name, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := q.Get(name); v != "" {
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				writeError(w, http.StatusBadRequest, errors.New("invalid "+name))
+				return
+			}
+		}
+	}
+
+	s.listByCursor(w, r, filter)
+}
+
+// listByCursor отдаёт страницу посылок filter после курсора из запроса.
+func (s *HTTPServer) listByCursor(w http.ResponseWriter, r *http.Request, filter Filter) {
+	q := r.URL.Query()
+	after, err := DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+	}
+
+	page, err := s.service.List(r.Context(), filter, after, limit)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	resp := parcelCursorResponse{Parcels: []parcelResponse{}, NextCursor: page.NextCursor}
+	for _, p := range page.Parcels {
+		resp.Parcels = append(resp.Parcels, newParcelResponse(p, requestLang(r)))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *HTTPServer) handleSetStatus(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	var req setStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.service.SetStatus(r.Context(), number, req.Status); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) handleMarkPaid(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	var req markPaidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.service.MarkPaid(r.Context(), number, req.TxRef); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	var req cancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.service.Cancel(r.Context(), number, req.Reason); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) handleDeliver(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	var req deliverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.service.Deliver(r.Context(), number, req.Signature, req.SignedBy); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) handleSetAddress(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	var req setAddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	set := s.service.ChangeAddress
+	if req.Sender {
+		set = s.service.ChangeSenderAddress
+	}
+	if err := set(r.Context(), number, req.Address); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	if err := s.service.Delete(r.Context(), number); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreateReturn создаёт возвратную посылку для посылки в статусе
+// return_requested, см. ParcelService.CreateReturn.
+func (s *HTTPServer) handleCreateReturn(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	p, err := s.service.CreateReturn(r.Context(), number)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newParcelResponse(p, requestLang(r)))
+}
+
+func (s *HTTPServer) handleGetReturn(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	p, err := s.service.GetReturn(r.Context(), number)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newParcelResponse(p, requestLang(r)))
+}
+
+// handleAttach сохраняет тело запроса как подтверждение доставки,
+// вид задаёт параметр kind: photo (по умолчанию) или signature.
+func (s *HTTPServer) handleAttach(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	kind := AttachmentKind(r.URL.Query().Get("kind"))
+	if kind == "" {
+		kind = AttachmentPhoto
+	}
+	a, err := s.service.AttachProofOfDelivery(r.Context(), number, kind, r.Body)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newAttachmentResponse(a))
+}
+
+func (s *HTTPServer) handleListAttachments(w http.ResponseWriter, r *http.Request) {
+	number, ok := pathInt(w, r, "number")
+	if !ok {
+		return
+	}
+
+	list, err := s.service.ListAttachments(r.Context(), number)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	resp := make([]attachmentResponse, 0, len(list))
+	for _, a := range list {
+		resp = append(resp, newAttachmentResponse(a))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGetAttachment отдаёт содержимое вложения с его типом.
+func (s *HTTPServer) handleGetAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	a, content, err := s.service.OpenAttachment(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("ETag", `"`+a.SHA256+`"`)
+	io.Copy(w, content)
+}
+
+func parseListOptions(r *http.Request) (ListOptions, error) {
+	q := r.URL.Query()
+	opts := ListOptions{SortBy: q.Get("sort")}
+
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil {
+			return opts, errors.New("invalid limit")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if opts.Offset, err = strconv.Atoi(v); err != nil {
+			return opts, errors.New("invalid offset")
+		}
+	}
+	if v := q.Get("desc"); v != "" {
+		if opts.Desc, err = strconv.ParseBool(v); err != nil {
+			return opts, errors.New("invalid desc")
+		}
+	}
+
+	return opts, nil
+}
+
+// pathInt разбирает числовой параметр пути и при ошибке сам отвечает 400.
+func pathInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	v, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid "+name))
+		return 0, false
+	}
+	return v, true
+}
+
+// httpStatus сопоставляет ошибки хранилища кодам ответа.
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrParcelNotFound),
+		errors.Is(err, ErrClientNotFound),
+		errors.Is(err, ErrCourierNotFound),
+		errors.Is(err, ErrLocationNotFound),
+		errors.Is(err, ErrClaimNotFound),
+		errors.Is(err, ErrAttachmentNotFound),
+		errors.Is(err, ErrBlobNotFound),
+		errors.Is(err, ErrSignatureNotFound),
+		errors.Is(err, ErrManifestNotFound),
+		errors.Is(err, ErrNoPriceRate):
+		return http.StatusNotFound
+	case errors.Is(err, ErrAddressChangeNotAllowed),
+		errors.Is(err, ErrDeleteNotAllowed),
+		errors.Is(err, ErrCancelNotAllowed),
+		errors.Is(err, ErrOpenClaim),
+		errors.Is(err, ErrClaimResolved),
+		errors.Is(err, ErrProofNotAllowed),
+		errors.Is(err, ErrAttemptNotAllowed),
+		errors.Is(err, ErrConflict),
+		errors.Is(err, ErrClientHasParcels),
+		errors.Is(err, ErrAssignNotAllowed),
+		errors.Is(err, ErrNotAssigned),
+		errors.Is(err, ErrInvalidTransition),
+		errors.Is(err, ErrPaymentRequired),
+		errors.Is(err, ErrAlreadyPaid),
+		errors.Is(err, ErrNoTrackCode),
+		errors.Is(err, ErrCrossShardUpdate):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidListOptions),
+		errors.Is(err, ErrUnknownStatus),
+		errors.Is(err, ErrInvalidSort),
+		errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, ErrInvalidSearchQuery),
+		errors.Is(err, ErrInvalidEvent),
+		errors.Is(err, ErrInvalidRange),
+		errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, ErrInvalidTrackCode),
+		errors.Is(err, ErrInvalidAddress),
+		errors.Is(err, ErrInvalidCoordinates),
+		errors.Is(err, ErrInvalidDimensions),
+		errors.Is(err, ErrInvalidPriceRate),
+		errors.Is(err, ErrInvalidPayment),
+		errors.Is(err, ErrInvalidUUID),
+		errors.Is(err, ErrInvalidImport),
+		errors.Is(err, ErrInvalidLocation),
+		errors.Is(err, ErrInvalidParcel),
+		errors.Is(err, ErrInvalidIdempotencyKey),
+		errors.Is(err, ErrIdempotentBatch),
+		errors.Is(err, ErrUnknownServiceClass),
+		errors.Is(err, ErrInvalidCancelReason),
+		errors.Is(err, ErrInvalidClaim),
+		errors.Is(err, ErrInvalidNote),
+		errors.Is(err, ErrInvalidAttachment),
+		errors.Is(err, ErrInvalidAttempt),
+		errors.Is(err, ErrInvalidManifest),
+		errors.Is(err, ErrInvalidSignature),
+		errors.Is(err, ErrInvalidLabelFormat):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrTooManySubscriptions):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrAttachmentsDisabled),
+		errors.Is(err, ErrNoAttemptStore),
+		errors.Is(err, ErrNoClientStore),
+		errors.Is(err, ErrNoAddressSearcher),
+		errors.Is(err, ErrEncryptedSearch),
+		errors.Is(err, ErrUnsupportedFilter):
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	status := httpStatus(err)
+	if status == http.StatusInternalServerError {
+		// детали ошибок БД наружу не отдаём
+		err = errors.New(http.StatusText(status))
+	}
+	writeError(w, status, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := errorResponse{Error: err.Error()}
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		resp.Fields = make(map[string]string, len(invalid.Fields))
+		for _, f := range invalid.Fields {
+			resp.Fields[f.Field] = f.Err.Error()
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// doRequest выполняет запрос к серверу и возвращает ответ
+func doRequest(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestHTTPServer проверяет основные операции REST API
+func TestHTTPServer(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+
+	// add
+	rec := doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 7, "recipient_address": "test"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.NotEmpty(t, created.Number)
+	require.Equal(t, ParcelStatusRegistered.String(), created.Status)
+
+	number := strconv.Itoa(created.Number)
+
+	// get
+	rec = doRequest(t, srv, http.MethodGet, "/parcels/"+number, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var stored parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stored))
+	require.Equal(t, created, stored)
+
+	// track
+	rec = doRequest(t, srv, http.MethodGet, "/track/"+strings.ToLower(created.TrackCode), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var tracked parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&tracked))
+	require.Equal(t, created, tracked)
+
+	// set address
+	rec = doRequest(t, srv, http.MethodPatch, "/parcels/"+number+"/address", `{"address": "new"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(t, srv, http.MethodPatch, "/parcels/"+number+"/address", `{"address": "sender", "sender": true}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// set status
+	rec = doRequest(t, srv, http.MethodPatch, "/parcels/"+number+"/status", `{"status": "sent"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// list by client
+	rec = doRequest(t, srv, http.MethodGet, "/clients/7/parcels?limit=10", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var list parcelListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Equal(t, 1, list.Total)
+	require.Equal(t, "new", list.Parcels[0].RecipientAddress)
+	require.Equal(t, "sender", list.Parcels[0].SenderAddress)
+	require.Equal(t, ParcelStatusSent.String(), list.Parcels[0].Status)
+
+	// delete sent parcel
+	rec = doRequest(t, srv, http.MethodDelete, "/parcels/"+number, "")
+	require.Equal(t, http.StatusConflict, rec.Code)
+}
+
+// TestHTTPServerErrors проверяет коды ответа для ошибок
+func TestHTTPServerErrors(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{"not found", http.MethodGet, "/parcels/100", "", http.StatusNotFound},
+		{"bad number", http.MethodGet, "/parcels/abc", "", http.StatusBadRequest},
+		{"bad track code", http.MethodGet, "/track/abc", "", http.StatusBadRequest},
+		{"track code not found", http.MethodGet, "/track/" + NewTrackCode(), "", http.StatusNotFound},
+		{"bad json", http.MethodPost, "/parcels", "{", http.StatusBadRequest},
+		{"bad sort", http.MethodGet, "/clients/1/parcels?sort=address", "", http.StatusBadRequest},
+		{"bad limit", http.MethodGet, "/clients/1/parcels?limit=x", "", http.StatusBadRequest},
+		{"set status not found", http.MethodPatch, "/parcels/100/status", `{"status": "sent"}`, http.StatusNotFound},
+		{"bad cursor", http.MethodGet, "/parcels?cursor=abc", "", http.StatusBadRequest},
+		{"bad cursor limit", http.MethodGet, "/clients/1/parcels?cursor=&limit=-1", "", http.StatusBadRequest},
+		{"bad list from", http.MethodGet, "/parcels?from=yesterday", "", http.StatusBadRequest},
+		{"bad list status", http.MethodGet, "/parcels?status=sent,lost", "", http.StatusBadRequest},
+		{"bad list courier", http.MethodGet, "/parcels?courier=-1", "", http.StatusBadRequest},
+		{"bad list range", http.MethodGet, "/parcels?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z", "", http.StatusBadRequest},
+		{"cancel not found", http.MethodPost, "/parcels/100/cancel", `{"reason": "other"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, srv, tt.method, tt.target, tt.body)
+			require.Equal(t, tt.code, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+// TestHTTPListCursor проверяет обход списков REST API по курсору
+func TestHTTPListCursor(t *testing.T) {
+	// prepare
+	store := NewMemoryParcelStore()
+	srv := NewHTTPServer(NewParcelService(store))
+	for i := 0; i < 3; i++ {
+		_, err := store.Add(context.Background(), getTestParcel())
+		require.NoError(t, err)
+	}
+
+	// check
+	rec := doRequest(t, srv, http.MethodGet, "/clients/1000/parcels?cursor=&limit=2", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page parcelCursorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Parcels, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	rec = doRequest(t, srv, http.MethodGet, "/parcels?status=registered&limit=2&cursor="+page.NextCursor, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	page = parcelCursorResponse{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Parcels, 1)
+	require.Equal(t, 3, page.Parcels[0].Number)
+	require.Empty(t, page.NextCursor)
+}
+
+// TestHTTPListFilter проверяет фильтр списка REST API по нескольким
+// статусам и подстроке адреса
+func TestHTTPListFilter(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	srv := NewHTTPServer(NewParcelService(store))
+	var numbers []int
+	for _, address := range []string{"Москва, ул. Ленина, 1", "Казань, ул. Ленина, 2", "Москва, ул. Мира, 3"} {
+		p := getTestParcel()
+		p.RecipientAddress = address
+		number, err := store.Add(ctx, p)
+		require.NoError(t, err)
+		numbers = append(numbers, number)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusDelivered))
+
+	// check
+	for target, want := range map[string][]int{
+		"/parcels?status=registered,delivered":                                       {numbers[0], numbers[2]},
+		"/parcels?status=sent&status=delivered&address=" + url.QueryEscape("Москва"): {numbers[2]},
+		"/parcels?address=" + url.QueryEscape("ул. Ленина"):                          {numbers[0], numbers[1]},
+		"/parcels?address=" + url.QueryEscape("ул. ленина"):                          {},
+		"/parcels?client=1000&address=" + url.QueryEscape("Казань"):                  {numbers[1]},
+	} {
+		rec := doRequest(t, srv, http.MethodGet, target, "")
+		require.Equal(t, http.StatusOK, rec.Code, target)
+		var page parcelCursorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+		got := []int{}
+		for _, p := range page.Parcels {
+			got = append(got, p.Number)
+		}
+		require.Equal(t, want, got, target)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// idempotencyKeyHeader — заголовок HTTP и ключ метаданных gRPC
+// с ключом идемпотентности запроса Add.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLen совпадает с колонкой parcel.idempotency_key.
+const maxIdempotencyKeyLen = 64
+
+var (
+	// ErrInvalidIdempotencyKey возвращается для слишком длинного ключа идемпотентности.
+	ErrInvalidIdempotencyKey = fmt.Errorf("idempotency key must be at most %d characters", maxIdempotencyKeyLen)
+	// ErrIdempotentBatch возвращает AddBatch в контексте с ключом
+	// идемпотентности: ключ защищает одну посылку, а не пачку.
+	ErrIdempotentBatch = errors.New("idempotency key is not supported for batch add")
+)
+
+// idempotencyCtxKey — ключ идемпотентности в контексте запроса.
+type idempotencyCtxKey struct{}
+
+type idempotency struct {
+	key string
+	// replayed выставляет хранилище, если Add нашёл посылку с этим ключом
+	replayed bool
+}
+
+// WithIdempotencyKey возвращает контекст, в котором Add с ключом key
+// добавляет посылку только один раз: повтор с тем же ключом, например
+// после таймаута у мобильного клиента, возвращает номер уже добавленной
+// посылки. Ключ уникален в пределах арендатора, см. WithTenant. Пустой
+// key ничего не меняет.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyCtxKey{}, &idempotency{key: key})
+}
+
+// IdempotencyKeyFromContext возвращает ключ идемпотентности запроса или пустую строку.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	if i, ok := ctx.Value(idempotencyCtxKey{}).(*idempotency); ok {
+		return i.key
+	}
+	return ""
+}
+
+// AddReplayed сообщает, что Add в контексте ctx не добавил посылку,
+// а вернул добавленную раньше с тем же ключом. Обёртки хранилища и сервис
+// по нему не повторяют побочные действия, например событие о создании.
+func AddReplayed(ctx context.Context) bool {
+	i, ok := ctx.Value(idempotencyCtxKey{}).(*idempotency)
+	return ok && i.replayed
+}
+
+func markReplayed(ctx context.Context) {
+	if i, ok := ctx.Value(idempotencyCtxKey{}).(*idempotency); ok {
+		i.replayed = true
+	}
+}
+
+func checkIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLen {
+		return ErrInvalidIdempotencyKey
+	}
+	return nil
+}
+
+// checkBatchIdempotency не даёт AddBatch молча проигнорировать ключ
+// идемпотентности из ctx.
+func checkBatchIdempotency(ctx context.Context) error {
+	if IdempotencyKeyFromContext(ctx) != "" {
+		return ErrIdempotentBatch
+	}
+	return nil
+}
+
+// insertIdempotentParcelQuery — insertParcelQuery с ключом идемпотентности
+// последней колонкой.
+var insertIdempotentParcelQuery = strings.Replace(
+	strings.Replace(insertParcelQuery, "tenant_id)", "tenant_id, idempotency_key)", 1),
+	"?)", "?, ?)", 1)
+
+// addIdempotent добавляет посылку p с ключом key, если посылки с таким
+// ключом у арендатора ещё нет, иначе возвращает номер существующей.
+func (s ParcelStore) addIdempotent(ctx context.Context, p Parcel, key string) (int, error) {
+	number, err := s.idempotentNumber(ctx, key)
+	if err == nil {
+		markReplayed(ctx)
+		return number, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	number, err = s.insert(ctx, insertIdempotentParcelQuery, "number", append(s.insertParcelArgs(ctx, p), key)...)
+	if err != nil {
+		// параллельный запрос с тем же ключом успел первым
+		if existing, lookupErr := s.idempotentNumber(ctx, key); lookupErr == nil {
+			markReplayed(ctx)
+			return existing, nil
+		}
+		return 0, clientError(err)
+	}
+	return number, nil
+}
+
+func (s ParcelStore) idempotentNumber(ctx context.Context, key string) (int, error) {
+	var number int
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT number FROM parcel WHERE tenant_id = ? AND idempotency_key = ?"),
+		TenantFromContext(ctx), key).Scan(&number)
+	return number, err
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkIdempotentAdd проверяет Add с ключом идемпотентности в хранилище store
+func checkIdempotentAdd(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	first := WithIdempotencyKey(ctx, "retry-1")
+
+	// add
+	number, err := store.Add(first, getTestParcel())
+	require.NoError(t, err)
+	require.False(t, AddReplayed(first))
+
+	retry := WithIdempotencyKey(ctx, "retry-1")
+	again, err := store.Add(retry, getTestParcel())
+	require.NoError(t, err)
+	require.True(t, AddReplayed(retry))
+
+	other, err := store.Add(WithIdempotencyKey(ctx, "retry-2"), getTestParcel())
+	require.NoError(t, err)
+	plain, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, number, again)
+	require.NotEqual(t, number, other)
+	require.NotEqual(t, number, plain)
+
+	parcels, err := store.GetByClient(ctx, getTestParcel().Client)
+	require.NoError(t, err)
+	require.Len(t, parcels, 3)
+
+	_, err = store.Add(WithIdempotencyKey(ctx, strings.Repeat("k", maxIdempotencyKeyLen+1)), getTestParcel())
+	require.ErrorIs(t, err, ErrInvalidIdempotencyKey)
+}
+
+// TestIdempotentAdd проверяет ключи идемпотентности в SQLite
+func TestIdempotentAdd(t *testing.T) {
+	checkIdempotentAdd(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryIdempotentAdd проверяет ключи идемпотентности в памяти
+func TestMemoryIdempotentAdd(t *testing.T) {
+	checkIdempotentAdd(t, NewMemoryParcelStore())
+}
+
+// TestIdempotentAddBatch проверяет, что пачка с ключом идемпотентности
+// отклоняется целиком, а не добавляется без проверки ключа
+func TestIdempotentAddBatch(t *testing.T) {
+	for name, store := range map[string]interface {
+		ParcelStorage
+		csvParcelAdder
+	}{
+		"sqlite": NewParcelStore(openTempDB(t)),
+		"memory": NewMemoryParcelStore(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// prepare
+			ctx := context.Background()
+			parcels := []Parcel{getTestParcel(), getTestParcel()}
+
+			// check
+			_, err := store.AddBatch(WithIdempotencyKey(ctx, "order-1"), parcels)
+			require.ErrorIs(t, err, ErrIdempotentBatch)
+			stored, err := store.GetByClient(ctx, getTestParcel().Client)
+			require.NoError(t, err)
+			require.Empty(t, stored)
+
+			numbers, err := store.AddBatch(ctx, parcels)
+			require.NoError(t, err)
+			require.Len(t, numbers, 2)
+		})
+	}
+}
+
+// TestIdempotentAddScope проверяет, что ключ уникален в пределах арендатора
+// и что повтор не пишет второе событие в outbox
+func TestIdempotentAddScope(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewOutboxStore(NewParcelStore(openTempDB(t)))
+
+	// add
+	a, err := store.Add(WithIdempotencyKey(WithTenant(ctx, "shop-a"), "order-7"), getTestParcel())
+	require.NoError(t, err)
+	b, err := store.Add(WithIdempotencyKey(WithTenant(ctx, "shop-b"), "order-7"), getTestParcel())
+	require.NoError(t, err)
+	again, err := store.Add(WithIdempotencyKey(WithTenant(ctx, "shop-a"), "order-7"), getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	require.NotEqual(t, a, b)
+	require.Equal(t, a, again)
+
+	entries, err := store.ListOutbox(ctx, OutboxQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+// TestHTTPIdempotencyKey проверяет заголовок Idempotency-Key в POST /parcels
+func TestHTTPIdempotencyKey(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+	add := func() parcelResponse {
+		req := httptest.NewRequest(http.MethodPost, "/parcels", strings.NewReader(`{"client": 7, "recipient_address": "test"}`))
+		req.Header.Set(idempotencyKeyHeader, "mobile-42")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var resp parcelResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		return resp
+	}
+
+	// check
+	first := add()
+	require.Equal(t, first, add())
+
+	rec := doRequest(t, srv, http.MethodGet, "/clients/7/parcels", "")
+	var list parcelListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Equal(t, 1, list.Total)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Действия правил автоматических заданий.
+const (
+	// JobFlag помечает посылку событием трекинга с кодом Code,
+	// например stalled для застрявших в пути
+	JobFlag = "flag"
+	// JobTransition переводит посылку в статус To
+	JobTransition = "transition"
+	// JobArchive переносит доставленные посылки в архив, см. ArchiveOlderThan
+	JobArchive = "archive"
+)
+
+// defaultJobFlagCode — код события JobFlag по умолчанию.
+const defaultJobFlagCode = "stalled"
+
+// ErrJobNotFound возвращает JobRunner.RunJob для правила не из настроек.
+var ErrJobNotFound = errors.New("job not found")
+
+// JobsConfig задаёт автоматические задания над посылками. Правила
+// выполняются по порядку; Every — интервал прогонов в API-сервере
+// и parcelctl jobs run --every, ноль выключает их в API-сервере.
+type JobsConfig struct {
+	Rules []JobRule     `yaml:"rules"`
+	Every time.Duration `yaml:"every"`
+}
+
+// JobRule применяет Action к посылкам в статусе Status, перешедшим
+// в него больше AfterDays дней назад. Name отличает правило в журнале
+// job_runs и в parcelctl jobs run.
+type JobRule struct {
+	Name      string `yaml:"name"`
+	Action    string `yaml:"action"`
+	Status    Status `yaml:"status"`
+	AfterDays int    `yaml:"after_days"`
+	// To — новый статус для действия transition
+	To Status `yaml:"to"`
+	// Code — код события для действия flag, по умолчанию stalled
+	Code string `yaml:"code"`
+}
+
+func (r JobRule) code() string {
+	if r.Code == "" {
+		return defaultJobFlagCode
+	}
+	return r.Code
+}
+
+func (r JobRule) String() string {
+	return fmt.Sprintf("%s: %s %s after %d days", r.Name, r.Action, r.Status, r.AfterDays)
+}
+
+func (c JobsConfig) validate() error {
+	if c.Every < 0 {
+		return errors.New("config: jobs interval must not be negative")
+	}
+	names := map[string]bool{}
+	for _, r := range c.Rules {
+		if r.Name == "" || len(r.Name) > 64 {
+			return fmt.Errorf("config: job %q: name must be 1 to 64 characters", r.Name)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("config: duplicate job %q", r.Name)
+		}
+		names[r.Name] = true
+		if r.AfterDays <= 0 {
+			return fmt.Errorf("config: job %q: after_days must be positive", r.Name)
+		}
+		if !r.Status.Valid() {
+			return fmt.Errorf("config: job %q: unknown status %q", r.Name, r.Status)
+		}
+		switch r.Action {
+		case JobFlag:
+		case JobTransition:
+			if !r.To.Valid() {
+				return fmt.Errorf("config: job %q: unknown target status %q", r.Name, r.To)
+			}
+		case JobArchive:
+			if r.Status != ParcelStatusDelivered {
+				return fmt.Errorf("config: job %q: only delivered parcels can be archived", r.Name)
+			}
+		default:
+			return fmt.Errorf("config: job %q: unknown action %q", r.Name, r.Action)
+		}
+	}
+	return nil
+}
+
+// JobRun — запись журнала job_runs о прогоне правила.
+type JobRun struct {
+	ID         int
+	Job        string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Parcels — сколько посылок изменено
+	Parcels int
+	// Error — текст ошибки прогона, пустой при успехе
+	Error string
+}
+
+// JobStore отбирает посылки для заданий и ведёт журнал их прогонов.
+// Реализуется только SQL-хранилищем, как и RetentionStore.
+type JobStore interface {
+	ParcelArchiver
+	// JobCandidates возвращает номера посылок, перешедших в статус status
+	// раньше cutoff. Непустой skipCode исключает посылки с событием этого кода
+	JobCandidates(ctx context.Context, status Status, cutoff time.Time, skipCode string) ([]int, error)
+	AddJobRun(ctx context.Context, run JobRun) (int, error)
+	// ListJobRuns возвращает журнал прогонов job по возрастанию id,
+	// пустой job — всех заданий
+	ListJobRuns(ctx context.Context, job string) ([]JobRun, error)
+}
+
+var _ JobStore = ParcelStore{}
+
+// JobCandidates не зависит от арендатора ctx.
+func (s ParcelStore) JobCandidates(ctx context.Context, status Status, cutoff time.Time, skipCode string) ([]int, error) {
+	query := "SELECT number FROM parcel WHERE deleted_at IS NULL AND status = ? AND " + reachedStatusBefore
+	args := []any{status, status, status, formatTime(cutoff), s.dialect.timeArg(cutoff)}
+	if skipCode != "" {
+		query += " AND NOT EXISTS (SELECT 1 FROM parcel_event e WHERE e.parcel_number = parcel.number AND e.code = ?)"
+		args = append(args, skipCode)
+	}
+	return s.numbers(ctx, query+" ORDER BY number", args...)
+}
+
+func (s ParcelStore) AddJobRun(ctx context.Context, run JobRun) (int, error) {
+	return s.insert(ctx,
+		"INSERT INTO job_runs (job, started_at, finished_at, parcels, error) VALUES (?, ?, ?, ?, ?)", "id",
+		run.Job, s.dialect.timeArg(run.StartedAt), s.dialect.timeArg(run.FinishedAt), run.Parcels, run.Error)
+}
+
+func (s ParcelStore) ListJobRuns(ctx context.Context, job string) ([]JobRun, error) {
+	query := "SELECT id, job, started_at, finished_at, parcels, error FROM job_runs"
+	var args []any
+	if job != "" {
+		query += " WHERE job = ?"
+		args = append(args, job)
+	}
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(query+" ORDER BY id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []JobRun
+	for rows.Next() {
+		var r JobRun
+		if err := rows.Scan(&r.ID, &r.Job, scanTime{&r.StartedAt}, scanTime{&r.FinishedAt}, &r.Parcels, &r.Error); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+// JobRunner выполняет правила заданий и записывает каждый прогон в журнал.
+// Посылки отбирает store, а меняет service, чтобы переходы статусов
+// проверялись правилами сервиса и публиковали события.
+type JobRunner struct {
+	store    JobStore
+	service  ParcelService
+	rules    []JobRule
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewJobRunner(store JobStore, service ParcelService, cfg JobsConfig) JobRunner {
+	return JobRunner{store: store, service: service, rules: cfg.Rules, interval: cfg.Every,
+		logger: slog.Default(), now: time.Now}
+}
+
+// WithLogger возвращает копию планировщика, пишущую отчёты в logger.
+func (r JobRunner) WithLogger(logger *slog.Logger) JobRunner {
+	r.logger = logger
+	return r
+}
+
+// Run выполняет правила сразу и затем каждые interval, пока не отменён ctx.
+// Ошибки прогонов логируются и не останавливают планировщик.
+func (r JobRunner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выполняет все правила по порядку. Ошибка правила не мешает
+// следующим; ошибки возвращаются вместе.
+func (r JobRunner) RunOnce(ctx context.Context) ([]JobRun, error) {
+	runs := make([]JobRun, 0, len(r.rules))
+	var errs []error
+	for _, rule := range r.rules {
+		run, err := r.run(ctx, rule)
+		runs = append(runs, run)
+		errs = append(errs, err)
+	}
+	return runs, errors.Join(errs...)
+}
+
+// RunJob выполняет одно правило name.
+func (r JobRunner) RunJob(ctx context.Context, name string) (JobRun, error) {
+	for _, rule := range r.rules {
+		if rule.Name == name {
+			return r.run(ctx, rule)
+		}
+	}
+	return JobRun{}, fmt.Errorf("%w: %q", ErrJobNotFound, name)
+}
+
+// run применяет правило и записывает прогон в журнал, в том числе неудачный.
+func (r JobRunner) run(ctx context.Context, rule JobRule) (JobRun, error) {
+	run := JobRun{Job: rule.Name, StartedAt: r.now()}
+	n, err := r.apply(ctx, rule, run.StartedAt.AddDate(0, 0, -rule.AfterDays))
+	run.FinishedAt, run.Parcels = r.now(), n
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	id, logErr := r.store.AddJobRun(ctx, run)
+	run.ID = id
+	if err = errors.Join(err, logErr); err != nil {
+		if ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "run job", "job", rule.String(), "parcels", n, "error", err)
+		}
+		return run, err
+	}
+
+	r.logger.InfoContext(ctx, "run job", "job", rule.String(), "parcels", n)
+	return run, nil
+}
+
+// apply применяет правило к посылкам, перешедшим в его статус раньше cutoff,
+// и возвращает число изменённых. Посылку, которую правило изменить
+// не может, например без оплаты, оно пропускает.
+func (r JobRunner) apply(ctx context.Context, rule JobRule, cutoff time.Time) (int, error) {
+	if rule.Action == JobArchive {
+		return r.store.ArchiveOlderThan(ctx, cutoff)
+	}
+
+	var skip string
+	if rule.Action == JobFlag {
+		skip = rule.code()
+	}
+	numbers, err := r.store.JobCandidates(ctx, rule.Status, cutoff, skip)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, number := range numbers {
+		switch rule.Action {
+		case JobFlag:
+			_, err = r.service.AddEvent(ctx, number, skip,
+				fmt.Sprintf("в статусе %s больше %d дней", rule.Status, rule.AfterDays), r.now())
+		case JobTransition:
+			err = r.service.SetStatus(ctx, number, rule.To)
+		default:
+			return changed, fmt.Errorf("unknown job action %q", rule.Action)
+		}
+		if isDomainError(err) {
+			continue
+		}
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
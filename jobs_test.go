@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobRunner проверяет правила заданий и журнал их прогонов
+func TestJobRunner(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	var numbers []int
+	for range 3 {
+		id, err := store.Add(ctx, getTestParcel())
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusDelivered))
+
+	cfg := JobsConfig{Rules: []JobRule{
+		{Name: "stalled", Action: JobFlag, Status: ParcelStatusSent, AfterDays: 14},
+		{Name: "archive", Action: JobArchive, Status: ParcelStatusDelivered, AfterDays: 30},
+		{Name: "auto-send", Action: JobTransition, Status: ParcelStatusRegistered, AfterDays: 7, To: ParcelStatusSent},
+		// недопустимый переход пропускается без ошибки
+		{Name: "skip-sent", Action: JobTransition, Status: ParcelStatusSent, AfterDays: 7, To: ParcelStatusRegistered},
+	}}
+	require.NoError(t, cfg.validate())
+	runner := NewJobRunner(store, NewParcelService(store), cfg)
+	runner.now = func() time.Time { return time.Now().AddDate(0, 0, 40) }
+
+	// check
+	runs, err := runner.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Len(t, runs, 4)
+	for i, want := range []int{1, 1, 1, 0} {
+		require.Equal(t, want, runs[i].Parcels, runs[i].Job)
+		require.Empty(t, runs[i].Error)
+	}
+
+	events, err := store.GetEvents(ctx, numbers[1])
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "stalled", events[0].Code)
+	_, err = store.Get(ctx, numbers[2])
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	p, err := store.Get(ctx, numbers[0])
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, p.Status)
+
+	// помеченная посылка не помечается снова, а отправленная заданием — помечается
+	run, err := runner.RunJob(ctx, "stalled")
+	require.NoError(t, err)
+	require.Equal(t, 1, run.Parcels)
+	_, err = runner.RunJob(ctx, "missing")
+	require.ErrorIs(t, err, ErrJobNotFound)
+
+	history, err := store.ListJobRuns(ctx, "stalled")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, run.ID, history[1].ID)
+	require.False(t, history[1].FinishedAt.Before(history[1].StartedAt))
+	all, err := store.ListJobRuns(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+}
+
+// TestJobsConfig проверяет проверку правил заданий
+func TestJobsConfig(t *testing.T) {
+	valid := JobRule{Name: "stalled", Action: JobFlag, Status: ParcelStatusSent, AfterDays: 14}
+	require.NoError(t, JobsConfig{Rules: []JobRule{valid}}.validate())
+
+	tests := []JobRule{
+		{Action: JobFlag, Status: ParcelStatusSent, AfterDays: 14},
+		{Name: "a", Action: "notify", Status: ParcelStatusSent, AfterDays: 14},
+		{Name: "a", Action: JobFlag, Status: "lost", AfterDays: 14},
+		{Name: "a", Action: JobFlag, Status: ParcelStatusSent},
+		{Name: "a", Action: JobTransition, Status: ParcelStatusSent, AfterDays: 14},
+		{Name: "a", Action: JobArchive, Status: ParcelStatusSent, AfterDays: 14},
+	}
+	for _, rule := range tests {
+		require.Error(t, JobsConfig{Rules: []JobRule{rule}}.validate(), rule.String())
+	}
+	require.Error(t, JobsConfig{Rules: []JobRule{valid, valid}}.validate())
+	require.Error(t, JobsConfig{Every: -time.Hour}.validate())
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidImport возвращается для записи ImportJSON, которую нельзя загрузить.
+var ErrInvalidImport = errors.New("invalid import record")
+
+// ParcelDumper переносит посылки между хранилищами в формате JSON Lines:
+// одна посылка на строку, со всеми ключами и отметками времени.
+type ParcelDumper interface {
+	ExportJSON(ctx context.Context, w io.Writer, filter Filter) error
+	ImportJSON(ctx context.Context, r io.Reader) (ImportResult, error)
+}
+
+var (
+	_ ParcelDumper = ParcelStore{}
+	_ ParcelDumper = (*MemoryParcelStore)(nil)
+)
+
+// ImportResult — итог ImportJSON: сколько посылок загружено и сколько
+// пропущено, потому что посылка с тем же номером, трек-кодом или UUID уже есть.
+type ImportResult struct {
+	Imported   int
+	Duplicates int
+}
+
+// parcelJSON — запись ExportJSON и ImportJSON.
+type parcelJSON struct {
+	Number           int    `json:"number"`
+	Client           int    `json:"client"`
+	Status           string `json:"status"`
+	SenderAddress    string `json:"sender_address,omitempty"`
+	RecipientAddress string `json:"recipient_address"`
+	// Address — адрес получателя в выгрузках, сделанных до появления
+	// адреса отправителя
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+	TrackCode string    `json:"track_code,omitempty"`
+	UUID      string    `json:"uuid,omitempty"`
+	// вес и габариты пишутся, только если посылку взвесили и измерили
+	WeightGrams int `json:"weight_grams,omitempty"`
+	LengthMM    int `json:"length_mm,omitempty"`
+	WidthMM     int `json:"width_mm,omitempty"`
+	HeightMM    int `json:"height_mm,omitempty"`
+	// Price — стоимость доставки в копейках
+	Price int64 `json:"price,omitempty"`
+	// поля оплаты; у выгрузок без них посылка считается неоплаченной
+	Amount        int64      `json:"amount,omitempty"`
+	Currency      string     `json:"currency,omitempty"`
+	PaymentStatus string     `json:"payment_status,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	PaymentRef    string     `json:"payment_ref,omitempty"`
+	// поля SLA пишутся, только если у посылки есть класс обслуживания
+	ServiceClass  string     `json:"service_class,omitempty"`
+	SLADeadline   *time.Time `json:"sla_deadline,omitempty"`
+	SLABreachedAt *time.Time `json:"sla_breached_at,omitempty"`
+	ReturnOf      int        `json:"return_of,omitempty"`
+	CancelReason  string     `json:"cancel_reason,omitempty"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty"`
+}
+
+func newParcelJSON(p Parcel) parcelJSON {
+	j := parcelJSON{
+		Number:           p.Number,
+		Client:           p.Client,
+		Status:           string(p.Status),
+		SenderAddress:    p.SenderAddress,
+		RecipientAddress: p.RecipientAddress,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+		Version:          p.Version,
+		TrackCode:        p.TrackCode,
+		UUID:             p.UUID,
+		WeightGrams:      p.WeightGrams,
+		LengthMM:         p.LengthMM,
+		WidthMM:          p.WidthMM,
+		HeightMM:         p.HeightMM,
+		Price:            p.Price,
+		Amount:           p.Amount,
+		Currency:         p.Currency,
+		PaymentStatus:    p.PaymentStatus,
+		PaymentRef:       p.PaymentRef,
+		ServiceClass:     p.ServiceClass,
+		ReturnOf:         p.ReturnOf,
+		CancelReason:     string(p.CancelReason),
+	}
+	if !p.PaidAt.IsZero() {
+		j.PaidAt = &p.PaidAt
+	}
+	if !p.SLADeadline.IsZero() {
+		j.SLADeadline = &p.SLADeadline
+	}
+	if !p.SLABreachedAt.IsZero() {
+		j.SLABreachedAt = &p.SLABreachedAt
+	}
+	if !p.CancelledAt.IsZero() {
+		j.CancelledAt = &p.CancelledAt
+	}
+	return j
+}
+
+// parcel проверяет запись и возвращает посылку. Недостающие updated_at
+// и version заполняются, как у новой посылки.
+func (j parcelJSON) parcel(t StatusTransitions) (Parcel, error) {
+	p := Parcel{
+		Number:           j.Number,
+		Client:           j.Client,
+		Status:           Status(j.Status),
+		SenderAddress:    j.SenderAddress,
+		RecipientAddress: j.RecipientAddress,
+		CreatedAt:        j.CreatedAt,
+		UpdatedAt:        j.UpdatedAt,
+		Version:          j.Version,
+		TrackCode:        j.TrackCode,
+		UUID:             j.UUID,
+		WeightGrams:      j.WeightGrams,
+		LengthMM:         j.LengthMM,
+		WidthMM:          j.WidthMM,
+		HeightMM:         j.HeightMM,
+		Price:            j.Price,
+		Amount:           j.Amount,
+		Currency:         j.Currency,
+		PaymentStatus:    j.PaymentStatus,
+		PaymentRef:       j.PaymentRef,
+		ServiceClass:     j.ServiceClass,
+		ReturnOf:         j.ReturnOf,
+		CancelReason:     CancelReason(j.CancelReason),
+	}
+	if j.PaidAt != nil {
+		p.PaidAt = j.PaidAt.UTC().Truncate(time.Second)
+	}
+	if j.SLADeadline != nil {
+		p.SLADeadline = j.SLADeadline.UTC().Truncate(time.Second)
+	}
+	if j.SLABreachedAt != nil {
+		p.SLABreachedAt = j.SLABreachedAt.UTC().Truncate(time.Second)
+	}
+	if j.CancelledAt != nil {
+		p.CancelledAt = j.CancelledAt.UTC().Truncate(time.Second)
+	}
+	if p.RecipientAddress == "" {
+		p.RecipientAddress = j.Address
+	}
+	switch {
+	case p.Number <= 0:
+		return p, errors.New("number must be positive")
+	case p.RecipientAddress == "":
+		return p, errors.New("recipient address is required")
+	// отмена — не переход статуса, а отдельная операция, см. ParcelService.Cancel
+	case !t.Known(p.Status) && p.Status != ParcelStatusCancelled:
+		return p, fmt.Errorf("unknown status %q", p.Status)
+	case p.CreatedAt.IsZero():
+		return p, errors.New("created_at is required")
+	}
+	if err := validateDimensions(p); err != nil {
+		return p, err
+	}
+	p, err := preparePayment(p)
+	if err != nil {
+		return p, err
+	}
+
+	if p.TrackCode != "" {
+		code, err := NormalizeTrackCode(p.TrackCode)
+		if err != nil {
+			return p, err
+		}
+		p.TrackCode = code
+	}
+	if p.UUID != "" {
+		id, err := uuid.Parse(p.UUID)
+		if err != nil {
+			return p, ErrInvalidUUID
+		}
+		p.UUID = id.String()
+	}
+
+	p.CreatedAt = p.CreatedAt.UTC().Truncate(time.Second)
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = p.CreatedAt
+	}
+	p.UpdatedAt = p.UpdatedAt.UTC().Truncate(time.Second)
+	if p.Version < 1 {
+		p.Version = 1
+	}
+
+	return p, nil
+}
+
+// readParcelsJSON разбирает записи из r и передаёт их fn по одной.
+func readParcelsJSON(r io.Reader, t StatusTransitions, fn func(Parcel) error) error {
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var j parcelJSON
+		err := dec.Decode(&j)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("import record %d: %w: %w", n, ErrInvalidImport, err)
+		}
+
+		p, err := j.parcel(t)
+		if err != nil {
+			return fmt.Errorf("import record %d: %w: %w", n, ErrInvalidImport, err)
+		}
+		if err := fn(p); err != nil {
+			return fmt.Errorf("import record %d: %w", n, err)
+		}
+	}
+}
+
+// ExportJSON пишет в w посылки, подходящие под filter, по одной на строку,
+// по возрастанию номера. Результат загружается обратно через ImportJSON.
+func (s ParcelStore) ExportJSON(ctx context.Context, w io.Writer, filter Filter) error {
+	enc := json.NewEncoder(w)
+	return s.eachParcel(ctx, filter, func(p Parcel) error {
+		return enc.Encode(newParcelJSON(p))
+	})
+}
+
+// ImportJSON загружает посылки, выгруженные ExportJSON, сохраняя номера,
+// ключи и отметки времени. Посылки, которые уже есть в хранилище, пропускаются.
+// Загрузка идёт в одной транзакции: при ошибке в любой записи
+// не загружается ничего.
+func (s ParcelStore) ImportJSON(ctx context.Context, r io.Reader) (ImportResult, error) {
+	var res ImportResult
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		err := readParcelsJSON(r, tx.transitions, func(p Parcel) error {
+			trackCode, id := tx.parcelKeys(p)
+
+			var n int
+			err := tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+				"SELECT COUNT(*) FROM parcel WHERE number = ? OR track_code = ? OR uuid = ?"),
+				p.Number, trackCode, id).Scan(&n)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				res.Duplicates++
+				return nil
+			}
+			if p, err = tx.sealParcel(p); err != nil {
+				return err
+			}
+
+			_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+				"INSERT INTO parcel (number, client, status, sender_address, recipient_address,"+
+					" created_at, updated_at, version, track_code, uuid, weight_grams, length_mm, width_mm, height_mm, price,"+
+					" amount, currency, payment_status, paid_at, payment_ref, service_class, sla_deadline, sla_breached_at, return_of,"+
+					" cancel_reason, cancelled_at)"+
+					" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+				p.Number, p.Client, p.Status, p.SenderAddress, p.RecipientAddress,
+				tx.dialect.timeArg(p.CreatedAt), tx.dialect.timeArg(p.UpdatedAt),
+				p.Version, trackCode, id, p.WeightGrams, p.LengthMM, p.WidthMM, p.HeightMM, p.Price,
+				p.Amount, p.Currency, p.PaymentStatus, tx.dialect.paidAtArg(p.PaidAt), p.PaymentRef,
+				p.ServiceClass, tx.dialect.paidAtArg(p.SLADeadline), tx.dialect.paidAtArg(p.SLABreachedAt), returnOfArg(p.ReturnOf),
+				p.CancelReason, tx.dialect.paidAtArg(p.CancelledAt))
+			if err != nil {
+				return err
+			}
+			res.Imported++
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if q := tx.dialect.syncSequence(); q != "" && res.Imported > 0 {
+			_, err = tx.q.ExecContext(ctx, q)
+		}
+		return err
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	return res, nil
+}
+
+// ExportJSON пишет в w посылки, подходящие под filter, по одной на строку.
+func (s *MemoryParcelStore) ExportJSON(ctx context.Context, w io.Writer, filter Filter) error {
+	parcels, err := s.filtered(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for _, p := range parcels {
+		if err := enc.Encode(newParcelJSON(p)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportJSON загружает посылки, выгруженные ExportJSON. Все записи
+// разбираются до изменения хранилища, поэтому ошибка не оставляет
+// загрузку наполовину выполненной.
+func (s *MemoryParcelStore) ImportJSON(ctx context.Context, r io.Reader) (ImportResult, error) {
+	if err := ctx.Err(); err != nil {
+		return ImportResult{}, err
+	}
+
+	s.mu.Lock()
+	transitions := s.transitions
+	s.mu.Unlock()
+
+	var parcels []Parcel
+	err := readParcelsJSON(r, transitions, func(p Parcel) error {
+		parcels = append(parcels, p)
+		return nil
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// занятые трек-коды и UUID; пустые строки сюда не попадают
+	keys := map[string]bool{}
+	addKeys := func(p Parcel) {
+		for _, k := range []string{p.TrackCode, p.UUID} {
+			if k != "" {
+				keys[k] = true
+			}
+		}
+	}
+	for _, m := range []map[int]Parcel{s.parcels, s.deleted} {
+		for _, p := range m {
+			addKeys(p)
+		}
+	}
+
+	var res ImportResult
+	for _, p := range parcels {
+		_, live := s.parcels[p.Number]
+		_, deleted := s.deleted[p.Number]
+		if live || deleted || keys[p.TrackCode] || keys[p.UUID] {
+			res.Duplicates++
+			continue
+		}
+
+		if p.TrackCode == "" {
+			p.TrackCode = NewTrackCode()
+		}
+		if p.UUID == "" && s.keys == KeyModeUUID {
+			p.UUID = newParcelUUID()
+		}
+		addKeys(p)
+
+		s.parcels[p.Number] = p
+		s.tenants[p.Number] = TenantFromContext(ctx)
+		s.last = max(s.last, p.Number)
+		res.Imported++
+	}
+
+	return res, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dumpStore — хранилище, поддерживающее выгрузку и загрузку JSON
+type dumpStore interface {
+	ParcelStorage
+	ParcelDumper
+}
+
+// checkJSONRoundTrip переносит посылки из from в пустое хранилище to
+// и проверяет, что они совпадают
+func checkJSONRoundTrip(t *testing.T, from, to dumpStore) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	var numbers []int
+	for i := 0; i < 3; i++ {
+		id, err := from.Add(ctx, getTestParcel())
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	require.NoError(t, from.SetStatus(ctx, numbers[1], ParcelStatusSent))
+
+	// export
+	var dump bytes.Buffer
+	require.NoError(t, from.ExportJSON(ctx, &dump, Filter{}))
+	require.Equal(t, 3, strings.Count(dump.String(), "\n"))
+
+	// import
+	res, err := to.ImportJSON(ctx, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, ImportResult{Imported: 3}, res)
+
+	// check
+	for _, number := range numbers {
+		want, err := from.Get(ctx, number)
+		require.NoError(t, err)
+		got, err := to.Get(ctx, number)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	// повторная загрузка ничего не дублирует
+	res, err = to.ImportJSON(ctx, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, ImportResult{Duplicates: 3}, res)
+
+	// новые посылки получают номера после загруженных
+	id, err := to.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.Greater(t, id, numbers[2])
+}
+
+// TestJSONRoundTrip проверяет перенос посылок из SQLite в память и обратно
+func TestJSONRoundTrip(t *testing.T) {
+	sqlite := NewParcelStore(openTempDB(t)).WithClock(testClock)
+	memory := NewMemoryParcelStore()
+	memory.SetClock(testClock)
+
+	t.Run("sqlite to memory", func(t *testing.T) {
+		checkJSONRoundTrip(t, sqlite, NewMemoryParcelStore())
+	})
+	t.Run("memory to sqlite", func(t *testing.T) {
+		checkJSONRoundTrip(t, memory, NewParcelStore(openTempDB(t)))
+	})
+}
+
+// TestImportJSONInvalid проверяет, что ошибочная запись отменяет всю загрузку
+func TestImportJSONInvalid(t *testing.T) {
+	ctx := context.Background()
+	valid := `{"number": 10, "client": 1, "status": "registered", "address": "test", "created_at": "2024-01-02T03:04:05Z"}`
+
+	for _, bad := range []string{
+		`{"number": 11, "client": 1, "status": "lost", "address": "test", "created_at": "2024-01-02T03:04:05Z"}`,
+		`{"number": 0, "client": 1, "status": "registered", "address": "test", "created_at": "2024-01-02T03:04:05Z"}`,
+		`{"number": 11, "client": 1, "status": "registered", "address": "", "created_at": "2024-01-02T03:04:05Z"}`,
+		`{"number": 11, "client": 1, "status": "registered", "address": "test"}`,
+		`{"number": 11, "client": 1, "status": "registered", "address": "test", "created_at": "2024-01-02T03:04:05Z", "track_code": "bad"}`,
+		`{"number": "eleven"}`,
+	} {
+		for _, store := range []dumpStore{NewParcelStore(openTempDB(t)), NewMemoryParcelStore()} {
+			_, err := store.ImportJSON(ctx, strings.NewReader(valid+"\n"+bad+"\n"))
+			require.ErrorIs(t, err, ErrInvalidImport, bad)
+			require.ErrorContains(t, err, "record 2", bad)
+
+			_, err = store.Get(ctx, 10)
+			require.ErrorIs(t, err, ErrParcelNotFound)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+)
+
+// Форматы этикеток посылок.
+const (
+	// LabelCode128 — линейный штрихкод Code 128, его читает любой сканер склада
+	LabelCode128 = "code128"
+	// LabelQR — QR-код, его можно прочитать и камерой телефона
+	LabelQR = "qr"
+	// LabelPDF — транспортная этикетка A6 с адресами и штрихкодом,
+	// см. GenerateShippingLabelPDF
+	LabelPDF = "pdf"
+)
+
+// Размеры этикеток в пикселях.
+const (
+	code128LabelWidth  = 400
+	code128LabelHeight = 120
+	qrLabelSize        = 256
+	// labelMargin — белое поле вокруг кода: без него сканеры не находят
+	// границы штрихкода
+	labelMargin = 16
+)
+
+var (
+	// ErrInvalidLabelFormat возвращается для формата этикетки не из списка.
+	ErrInvalidLabelFormat = errors.New("label format must be code128, qr or pdf")
+	// ErrNoTrackCode возвращается для посылки, заведённой до появления
+	// трек-кодов: на её этикетке нечего печатать.
+	ErrNoTrackCode = errors.New("parcel has no track code")
+)
+
+// GenerateLabel возвращает PNG с трек-кодом посылки number в формате
+// format: LabelCode128 или LabelQR, пустой format означает LabelCode128.
+// Для LabelPDF возвращает PDF из GenerateShippingLabelPDF.
+func (s ParcelService) GenerateLabel(ctx context.Context, number int, format string) ([]byte, error) {
+	if format == "" {
+		format = LabelCode128
+	}
+	if format == LabelPDF {
+		return s.GenerateShippingLabelPDF(ctx, number)
+	}
+	if format != LabelCode128 && format != LabelQR {
+		return nil, ErrInvalidLabelFormat
+	}
+
+	p, err := s.store.Get(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if p.TrackCode == "" {
+		return nil, ErrNoTrackCode
+	}
+
+	return renderLabel(p.TrackCode, format)
+}
+
+// labelContentType возвращает MIME-тип этикетки в формате format.
+func labelContentType(format string) string {
+	if format == LabelPDF {
+		return "application/pdf"
+	}
+	return "image/png"
+}
+
+// renderLabel рисует code в формате format и кодирует картинку в PNG.
+func renderLabel(code, format string) ([]byte, error) {
+	var (
+		bc   barcode.Barcode
+		err  error
+		w, h int
+	)
+	switch format {
+	case LabelCode128:
+		bc, err = code128.Encode(code)
+		w, h = code128LabelWidth, code128LabelHeight
+	case LabelQR:
+		bc, err = qr.Encode(code, qr.M, qr.AlphaNumeric)
+		w, h = qrLabelSize, qrLabelSize
+	default:
+		return nil, ErrInvalidLabelFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode %s label: %w", format, err)
+	}
+	if bc, err = barcode.Scale(bc, w-2*labelMargin, h-2*labelMargin); err != nil {
+		return nil, fmt.Errorf("scale %s label: %w", format, err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(labelMargin, labelMargin, w-labelMargin, h-labelMargin), bc, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateLabel проверяет этикетки Code 128 и QR и ошибки GenerateLabel
+func TestGenerateLabel(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(NewMemoryParcelStore())
+
+	// add
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	for format, size := range map[string][2]int{
+		"":           {code128LabelWidth, code128LabelHeight},
+		LabelCode128: {code128LabelWidth, code128LabelHeight},
+		LabelQR:      {qrLabelSize, qrLabelSize},
+	} {
+		label, err := service.GenerateLabel(ctx, p.Number, format)
+		require.NoError(t, err, format)
+
+		img, err := png.Decode(bytes.NewReader(label))
+		require.NoError(t, err, format)
+		require.Equal(t, size[0], img.Bounds().Dx(), format)
+		require.Equal(t, size[1], img.Bounds().Dy(), format)
+	}
+
+	_, err = service.GenerateLabel(ctx, p.Number, "ean13")
+	require.ErrorIs(t, err, ErrInvalidLabelFormat)
+
+	_, err = service.GenerateLabel(ctx, p.Number+1, LabelQR)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestHTTPLabel проверяет GET /parcels/{number}/label
+func TestHTTPLabel(t *testing.T) {
+	// prepare
+	service := NewParcelService(NewMemoryParcelStore())
+	srv := NewHTTPServer(service)
+	p, err := service.Create(context.Background(), getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	rec := doRequest(t, srv, http.MethodGet, fmt.Sprintf("/parcels/%d/label?format=qr", p.Number), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	_, err = png.Decode(rec.Body)
+	require.NoError(t, err)
+
+	rec = doRequest(t, srv, http.MethodGet, fmt.Sprintf("/parcels/%d/label?format=pdf", p.Number), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+
+	rec = doRequest(t, srv, http.MethodGet, fmt.Sprintf("/parcels/%d/label?format=svg", p.Number), "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestGenerateShippingLabelPDF проверяет транспортную этикетку A6 и класс доставки
+func TestGenerateShippingLabelPDF(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(NewMemoryParcelStore()).WithEstimator(NewDeliveryEstimator(Tariff{
+		DefaultTransitDays: 5,
+		Zones:              []Zone{{Name: "express", Match: []string{"москва"}, TransitDays: 1}},
+	}))
+
+	parcel := getTestParcel()
+	parcel.SenderAddress = "Санкт-Петербург, Невский пр., 10"
+	parcel.RecipientAddress = "г. Москва, ул. Тверская, д. 1"
+	parcel.WeightGrams = 1250
+	parcel.LengthMM, parcel.WidthMM, parcel.HeightMM = 300, 200, 100
+
+	// add
+	p, err := service.Create(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	label, err := service.GenerateShippingLabelPDF(ctx, p.Number)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(label, []byte("%PDF-")))
+	require.Contains(t, string(label), "/MediaBox [0 0 297.64 420.94]")
+
+	viaFormat, err := service.GenerateLabel(ctx, p.Number, LabelPDF)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(viaFormat, []byte("%PDF-")))
+
+	require.Equal(t, "express, 1 дн.", service.serviceClass(p))
+	p.RecipientAddress = "Казань"
+	require.Equal(t, defaultServiceClass+", 5 дн.", service.serviceClass(p))
+	require.Equal(t, defaultServiceClass, NewParcelService(nil).serviceClass(p))
+
+	_, err = service.GenerateShippingLabelPDF(ctx, p.Number+1)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
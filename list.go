@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListOptions задаёт страницу списка посылок и её сортировку.
+// Нулевой Limit означает «без ограничения», пустой SortBy — сортировку по номеру.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	SortBy string
+	Desc   bool
+}
+
+// Поля, по которым можно сортировать списки посылок.
+const (
+	SortByNumber    = "number"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	SortByStatus    = "status"
+)
+
+// sortColumns — белый список полей сортировки и соответствующих им колонок.
+// Имя колонки попадает в текст запроса, поэтому произвольные значения недопустимы.
+var sortColumns = map[string]string{
+	SortByNumber:    "number",
+	SortByCreatedAt: "created_at",
+	SortByUpdatedAt: "updated_at",
+	SortByStatus:    "status",
+}
+
+// ParcelPage содержит страницу посылок и общее число посылок,
+// подходящих под запрос без учёта Limit и Offset.
+type ParcelPage struct {
+	Parcels []Parcel
+	Total   int
+}
+
+// ErrInvalidListOptions возвращается при отрицательных Limit или Offset.
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// ErrInvalidSort возвращается для поля сортировки не из белого списка.
+var ErrInvalidSort = errors.New("invalid sort field")
+
+func (o ListOptions) validate() error {
+	if o.Limit < 0 || o.Offset < 0 {
+		return ErrInvalidListOptions
+	}
+	if _, ok := sortColumns[o.sortBy()]; !ok {
+		return ErrInvalidSort
+	}
+	return nil
+}
+
+func (o ListOptions) sortBy() string {
+	if o.SortBy == "" {
+		return SortByNumber
+	}
+	return o.SortBy
+}
+
+// orderBy возвращает ORDER BY для запроса. Номер посылки добавляется
+// вторым ключом, чтобы порядок был стабильным при равных значениях.
+func (o ListOptions) orderBy() string {
+	dir := " ASC"
+	if o.Desc {
+		dir = " DESC"
+	}
+
+	column := sortColumns[o.sortBy()]
+	if column == "number" {
+		return " ORDER BY number" + dir
+	}
+	return " ORDER BY " + column + dir + ", number" + dir
+}
+
+// sortParcels упорядочивает посылки так же, как orderBy в SQL.
+func (o ListOptions) sortParcels(parcels []Parcel) {
+	compare := func(a, b Parcel) int {
+		switch o.sortBy() {
+		case SortByCreatedAt:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case SortByUpdatedAt:
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		case SortByStatus:
+			return strings.Compare(string(a.Status), string(b.Status))
+		}
+		return 0
+	}
+
+	sort.SliceStable(parcels, func(i, j int) bool {
+		a, b := parcels[i], parcels[j]
+		if o.Desc {
+			a, b = b, a
+		}
+		if c := compare(a, b); c != 0 {
+			return c < 0
+		}
+		return a.Number < b.Number
+	})
+}
+
+// limit возвращает значение для LIMIT: большинство СУБД не допускают
+// OFFSET без LIMIT, поэтому «без ограничения» заменяется на максимум.
+func (o ListOptions) limit() int {
+	if o.Limit == 0 {
+		return math.MaxInt32
+	}
+	return o.Limit
+}
+
+// ListByClient возвращает страницу посылок клиента в порядке opts.
+func (s ParcelStore) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+
+	cond, args := tenantCond(ctx)
+	args = append([]any{client}, args...)
+
+	var page ParcelPage
+	err := s.reader(0).QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT COUNT(*) FROM parcel WHERE client = ? AND deleted_at IS NULL"+cond),
+		args...).Scan(&page.Total)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	page.Parcels, err = s.query(ctx,
+		"SELECT "+parcelColumns+" FROM parcel WHERE client = ? AND deleted_at IS NULL"+cond+opts.orderBy()+" LIMIT ? OFFSET ?",
+		append(args, opts.limit(), opts.Offset)...)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	return page, nil
+}
+
+// ListByClient возвращает страницу посылок клиента в порядке opts.
+func (s *MemoryParcelStore) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+
+	parcels, err := s.GetByClient(ctx, client)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+	opts.sortParcels(parcels)
+
+	return paginate(parcels, opts), nil
+}
+
+// paginate вырезает страницу из уже упорядоченного среза посылок.
+func paginate(parcels []Parcel, opts ListOptions) ParcelPage {
+	page := ParcelPage{Total: len(parcels)}
+
+	if opts.Offset >= len(parcels) {
+		return page
+	}
+	end := len(parcels)
+	if opts.Limit > 0 && opts.Offset+opts.Limit < end {
+		end = opts.Offset + opts.Limit
+	}
+	page.Parcels = parcels[opts.Offset:end]
+
+	return page
+}
+
+// defaultCursorLimit — размер страницы List, если limit не задан.
+const defaultCursorLimit = 100
+
+// ErrInvalidCursor возвращает DecodeCursor для курсора, выданного не List.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorPage — страница постраничного обхода через List. NextCursor
+// пуст на последней странице.
+type CursorPage struct {
+	Parcels    []Parcel
+	NextCursor string
+}
+
+// EncodeCursor возвращает непрозрачный курсор страницы после посылки number.
+func EncodeCursor(number int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("n" + strconv.Itoa(number)))
+}
+
+// DecodeCursor возвращает номер посылки, после которой начинается страница.
+// Пустой курсор означает первую страницу.
+func DecodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	s, ok := strings.CutPrefix(string(b), "n")
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+	number, err := strconv.Atoi(s)
+	if err != nil || number < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return number, nil
+}
+
+// cursorLimit проверяет размер страницы List и подставляет значение по умолчанию.
+func cursorLimit(limit int) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, ErrInvalidListOptions
+	case limit == 0:
+		return defaultCursorLimit, nil
+	}
+	return limit, nil
+}
+
+// cursorPage обрезает до limit посылки, прочитанные с запасом в одну:
+// лишняя посылка означает, что есть следующая страница.
+func cursorPage(parcels []Parcel, limit int) CursorPage {
+	if len(parcels) <= limit {
+		return CursorPage{Parcels: parcels}
+	}
+	parcels = parcels[:limit]
+	return CursorPage{Parcels: parcels, NextCursor: EncodeCursor(parcels[limit-1].Number)}
+}
+
+// List возвращает до limit посылок, подходящих под filter, с номером больше
+// afterNumber, по возрастанию номера. В отличие от ListByClient с Offset,
+// запрос идёт по первичному ключу с места курсора и не замедляется
+// на дальних страницах.
+func (s ParcelStore) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	limit, err := cursorLimit(limit)
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	where, args, err := s.scopedWhere(ctx, filter)
+	if err != nil {
+		return CursorPage{}, err
+	}
+	parcels, err := s.query(ctx,
+		"SELECT "+parcelColumns+" FROM parcel"+where+" AND number > ? ORDER BY number LIMIT ?",
+		append(args, afterNumber, limit+1)...)
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	return cursorPage(parcels, limit), nil
+}
+
+// List возвращает до limit посылок, подходящих под filter, с номером
+// больше afterNumber, по возрастанию номера.
+func (s *MemoryParcelStore) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	if err := ctx.Err(); err != nil {
+		return CursorPage{}, err
+	}
+	limit, err := cursorLimit(limit)
+	if err != nil {
+		return CursorPage{}, err
+	}
+	if err := s.checkFilter(filter); err != nil {
+		return CursorPage{}, err
+	}
+
+	s.mu.Lock()
+	var parcels []Parcel
+	for _, p := range s.parcels {
+		if p.Number > afterNumber && filter.match(p) && s.visible(ctx, p.Number) {
+			parcels = append(parcels, p)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(parcels, func(i, j int) bool { return parcels[i].Number < parcels[j].Number })
+	if len(parcels) > limit+1 {
+		parcels = parcels[:limit+1]
+	}
+
+	return cursorPage(parcels, limit), nil
+}
+
+// ListAll возвращает страницу посылок, подходящих под filter, в порядке opts.
+func (s ParcelStore) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+
+	where, args, err := s.scopedWhere(ctx, filter)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	var page ParcelPage
+	err = s.reader(0).QueryRowContext(ctx, s.dialect.rebind("SELECT COUNT(*) FROM parcel"+where), args...).Scan(&page.Total)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	page.Parcels, err = s.query(ctx,
+		"SELECT "+parcelColumns+" FROM parcel"+where+opts.orderBy()+" LIMIT ? OFFSET ?",
+		append(args, opts.limit(), opts.Offset)...)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	return page, nil
+}
+
+// ListAll возвращает страницу посылок, подходящих под filter, в порядке opts.
+func (s *MemoryParcelStore) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+
+	parcels, err := s.filtered(ctx, filter)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+	opts.sortParcels(parcels)
+
+	return paginate(parcels, opts), nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestListByClient проверяет постраничное получение посылок клиента
+func TestListByClient(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	testListByClient(t, ctx, NewParcelStore(db))
+}
+
+// TestMemoryListByClient проверяет постраничное получение посылок клиента в памяти
+func TestMemoryListByClient(t *testing.T) {
+	testListByClient(t, context.Background(), NewMemoryParcelStore())
+}
+
+func testListByClient(t *testing.T, ctx context.Context, store ParcelStorage) {
+	client := randRange.Intn(10_000_000)
+
+	// add
+	var numbers []int
+	for i := 0; i < 5; i++ {
+		parcel := getTestParcel()
+		parcel.Client = client
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+
+	// first page
+	page, err := store.ListByClient(ctx, client, ListOptions{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, 5, page.Total)
+	require.Len(t, page.Parcels, 2)
+	require.Equal(t, numbers[0], page.Parcels[0].Number)
+	require.Equal(t, numbers[1], page.Parcels[1].Number)
+
+	// last page
+	page, err = store.ListByClient(ctx, client, ListOptions{Limit: 2, Offset: 4})
+	require.NoError(t, err)
+	require.Equal(t, 5, page.Total)
+	require.Len(t, page.Parcels, 1)
+	require.Equal(t, numbers[4], page.Parcels[0].Number)
+
+	// out of range
+	page, err = store.ListByClient(ctx, client, ListOptions{Offset: 10})
+	require.NoError(t, err)
+	require.Equal(t, 5, page.Total)
+	require.Empty(t, page.Parcels)
+
+	// sort
+	err = store.SetStatus(ctx, numbers[3], ParcelStatusSent)
+	require.NoError(t, err)
+
+	page, err = store.ListByClient(ctx, client, ListOptions{SortBy: SortByStatus, Desc: true})
+	require.NoError(t, err)
+	require.Len(t, page.Parcels, 5)
+	require.Equal(t, numbers[3], page.Parcels[0].Number)
+	require.Equal(t, numbers[4], page.Parcels[1].Number)
+	require.Equal(t, numbers[0], page.Parcels[4].Number)
+
+	// invalid
+	_, err = store.ListByClient(ctx, client, ListOptions{Limit: -1})
+	require.ErrorIs(t, err, ErrInvalidListOptions)
+
+	_, err = store.ListByClient(ctx, client, ListOptions{SortBy: "address; DROP TABLE parcel"})
+	require.ErrorIs(t, err, ErrInvalidSort)
+}
+
+// checkListCursor проверяет обход посылок по курсору в хранилище store
+func checkListCursor(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	client := randRange.Intn(10_000_000)
+	var numbers []int
+	for i := 0; i < 5; i++ {
+		parcel := getTestParcel()
+		parcel.Client = client
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+
+	// check
+	var got []int
+	var cursor string
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+
+		after, err := DecodeCursor(cursor)
+		require.NoError(t, err)
+		page, err := store.List(ctx, Filter{Client: client}, after, 2)
+		require.NoError(t, err)
+		for _, p := range page.Parcels {
+			got = append(got, p.Number)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	require.Equal(t, numbers, got)
+
+	// фильтр применяется вместе с курсором
+	page, err := store.List(ctx, Filter{Client: client, Statuses: []Status{ParcelStatusRegistered}}, numbers[0], 0)
+	require.NoError(t, err)
+	require.Len(t, page.Parcels, 3)
+	require.Equal(t, numbers[2], page.Parcels[0].Number)
+	require.Empty(t, page.NextCursor)
+
+	_, err = store.List(ctx, Filter{}, 0, -1)
+	require.ErrorIs(t, err, ErrInvalidListOptions)
+}
+
+// TestListCursor проверяет обход по курсору в SQLite
+func TestListCursor(t *testing.T) {
+	checkListCursor(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryListCursor проверяет обход по курсору в памяти
+func TestMemoryListCursor(t *testing.T) {
+	checkListCursor(t, NewMemoryParcelStore())
+}
+
+// checkListByStatus проверяет выборку посылок по статусу и составному фильтру в хранилище store
+func checkListByStatus(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	clients := []int{randRange.Intn(10_000_000), randRange.Intn(10_000_000) + 10_000_000}
+
+	// add
+	var numbers []int
+	for i := 0; i < 4; i++ {
+		parcel := getTestParcel()
+		parcel.Client = clients[i%2]
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[0], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[3], ParcelStatusSent))
+
+	// check
+	page, err := store.ListAll(ctx, Filter{Statuses: []Status{ParcelStatusSent}}, ListOptions{Limit: 2, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total)
+	require.Len(t, page.Parcels, 2)
+	require.Equal(t, numbers[3], page.Parcels[0].Number)
+	require.Equal(t, numbers[1], page.Parcels[1].Number)
+
+	page, err = store.ListAll(ctx, Filter{Client: clients[1], Statuses: []Status{ParcelStatusSent}}, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, numbers[1], page.Parcels[0].Number)
+	require.Equal(t, numbers[3], page.Parcels[1].Number)
+
+	// интервал дат, в который не попадает ни одна посылка
+	created := page.Parcels[0].CreatedAt
+	page, err = store.ListAll(ctx, Filter{Statuses: []Status{ParcelStatusSent}, To: created.Add(-time.Hour)}, ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+	require.Empty(t, page.Parcels)
+
+	_, err = store.ListAll(ctx, Filter{Statuses: []Status{ParcelStatusSent}}, ListOptions{SortBy: "client"})
+	require.ErrorIs(t, err, ErrInvalidSort)
+}
+
+// TestListByStatus проверяет выборку по статусу в SQLite
+func TestListByStatus(t *testing.T) {
+	checkListByStatus(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryListByStatus проверяет выборку по статусу в памяти
+func TestMemoryListByStatus(t *testing.T) {
+	checkListByStatus(t, NewMemoryParcelStore())
+}
+
+// TestStatusIndex проверяет, что выборка по статусу идёт по индексу parcel_status_idx
+func TestStatusIndex(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	// check
+	rows, err := db.Query("EXPLAIN QUERY PLAN SELECT number FROM parcel WHERE status = ? AND deleted_at IS NULL", ParcelStatusSent)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		require.NoError(t, rows.Scan(&id, &parent, &notUsed, &detail))
+		plan = append(plan, detail)
+	}
+	require.NoError(t, rows.Err())
+	require.Contains(t, strings.Join(plan, "\n"), "parcel_status_idx")
+}
+
+// TestDecodeCursor проверяет разбор курсора
+func TestDecodeCursor(t *testing.T) {
+	number, err := DecodeCursor(EncodeCursor(42))
+	require.NoError(t, err)
+	require.Equal(t, 42, number)
+
+	number, err = DecodeCursor("")
+	require.NoError(t, err)
+	require.Zero(t, number)
+
+	for _, cursor := range []string{"42", "!!", EncodeCursor(-1)} {
+		_, err := DecodeCursor(cursor)
+		require.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// LoggingStorage логирует каждую операцию хранилища: имя операции,
+// номер посылки, клиента, длительность и ошибку. Успешные операции пишутся
+// на уровне Debug, ожидаемые доменные ошибки — Warn, остальные — Error.
+type LoggingStorage struct {
+	next   ParcelStorage
+	logger *slog.Logger
+}
+
+var _ ParcelStorage = LoggingStorage{}
+
+// NewLoggingStorage оборачивает хранилище логированием.
+// Если logger равен nil, используется slog.Default().
+func NewLoggingStorage(next ParcelStorage, logger *slog.Logger) LoggingStorage {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return LoggingStorage{next: next, logger: logger}
+}
+
+// NewLogger создаёт текстовый логгер в stderr с уровнем из Config.LogLevel.
+func NewLogger(level string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("log level: %w", err)
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l})), nil
+}
+
+func (s LoggingStorage) log(ctx context.Context, op string, start time.Time, err error, attrs ...slog.Attr) {
+	level := slog.LevelDebug
+	switch {
+	case err == nil:
+	case isDomainError(err):
+		level = slog.LevelWarn
+	default:
+		level = slog.LevelError
+	}
+
+	if !s.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append(attrs, slog.String("op", op), slog.Duration("duration", time.Since(start)))
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	s.logger.LogAttrs(ctx, level, "parcel store", attrs...)
+}
+
+func (s LoggingStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	start := time.Now()
+	number, err := s.next.Add(ctx, p)
+	s.log(ctx, "Add", start, err, slog.Int("number", number), slog.Int("client", p.Client))
+	return number, err
+}
+
+func (s LoggingStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	start := time.Now()
+	p, err := s.next.Get(ctx, number)
+	s.log(ctx, "Get", start, err, slog.Int("number", number), slog.Int("client", p.Client))
+	return p, err
+}
+
+func (s LoggingStorage) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	start := time.Now()
+	p, err := s.next.GetByTrackCode(ctx, code)
+	s.log(ctx, "GetByTrackCode", start, err, slog.String("track_code", code))
+	return p, err
+}
+
+func (s LoggingStorage) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	start := time.Now()
+	p, err := s.next.GetByUUID(ctx, id)
+	s.log(ctx, "GetByUUID", start, err, slog.String("uuid", id))
+	return p, err
+}
+
+func (s LoggingStorage) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	start := time.Now()
+	parcels, err := s.next.GetByClient(ctx, client)
+	s.log(ctx, "GetByClient", start, err, slog.Int("client", client), slog.Int("rows", len(parcels)))
+	return parcels, err
+}
+
+func (s LoggingStorage) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	start := time.Now()
+	page, err := s.next.ListByClient(ctx, client, opts)
+	s.log(ctx, "ListByClient", start, err, slog.Int("client", client), slog.Int("rows", len(page.Parcels)))
+	return page, err
+}
+
+func (s LoggingStorage) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	start := time.Now()
+	page, err := s.next.List(ctx, filter, afterNumber, limit)
+	s.log(ctx, "List", start, err, slog.Int("after", afterNumber), slog.Int("rows", len(page.Parcels)))
+	return page, err
+}
+
+func (s LoggingStorage) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	start := time.Now()
+	page, err := s.next.ListAll(ctx, filter, opts)
+	s.log(ctx, "ListAll", start, err, slog.Int("rows", len(page.Parcels)))
+	return page, err
+}
+
+func (s LoggingStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	start := time.Now()
+	err := s.next.SetStatus(ctx, number, status)
+	s.log(ctx, "SetStatus", start, err, slog.Int("number", number), slog.String("status", string(status)))
+	return err
+}
+
+func (s LoggingStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	start := time.Now()
+	err := s.next.SetSenderAddress(ctx, number, address)
+	s.log(ctx, "SetSenderAddress", start, err, slog.Int("number", number))
+	return err
+}
+
+func (s LoggingStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	start := time.Now()
+	err := s.next.SetRecipientAddress(ctx, number, address)
+	s.log(ctx, "SetRecipientAddress", start, err, slog.Int("number", number))
+	return err
+}
+
+func (s LoggingStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	start := time.Now()
+	err := s.next.SetETA(ctx, number, eta)
+	s.log(ctx, "SetETA", start, err, slog.Int("number", number))
+	return err
+}
+
+func (s LoggingStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	start := time.Now()
+	err := s.next.MarkPaid(ctx, number, txRef)
+	s.log(ctx, "MarkPaid", start, err, slog.Int("number", number), slog.String("tx_ref", txRef))
+	return err
+}
+
+func (s LoggingStorage) Update(ctx context.Context, p Parcel) error {
+	start := time.Now()
+	err := s.next.Update(ctx, p)
+	s.log(ctx, "Update", start, err, slog.Int("number", p.Number), slog.Int("version", p.Version))
+	return err
+}
+
+func (s LoggingStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	start := time.Now()
+	err := s.next.Cancel(ctx, number, reason)
+	s.log(ctx, "Cancel", start, err, slog.Int("number", number), slog.String("reason", string(reason)))
+	return err
+}
+
+func (s LoggingStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	start := time.Now()
+	err := s.next.Deliver(ctx, number, sig)
+	s.log(ctx, "Deliver", start, err, slog.Int("number", number), slog.Int("signature_bytes", len(sig.Image)))
+	return err
+}
+
+func (s LoggingStorage) Delete(ctx context.Context, number int) error {
+	start := time.Now()
+	err := s.next.Delete(ctx, number)
+	s.log(ctx, "Delete", start, err, slog.Int("number", number))
+	return err
+}
+
+func (s LoggingStorage) HardDelete(ctx context.Context, number int) error {
+	start := time.Now()
+	err := s.next.HardDelete(ctx, number)
+	s.log(ctx, "HardDelete", start, err, slog.Int("number", number))
+	return err
+}
+
+func (s LoggingStorage) Restore(ctx context.Context, number int) error {
+	start := time.Now()
+	err := s.next.Restore(ctx, number)
+	s.log(ctx, "Restore", start, err, slog.Int("number", number))
+	return err
+}
+
+func (s LoggingStorage) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	start := time.Now()
+	history, err := s.next.GetHistory(ctx, number)
+	s.log(ctx, "GetHistory", start, err, slog.Int("number", number), slog.Int("rows", len(history)))
+	return history, err
+}
+
+func (s LoggingStorage) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	start := time.Now()
+	id, err := s.next.AddEvent(ctx, number, code, description, occurredAt)
+	s.log(ctx, "AddEvent", start, err, slog.Int("number", number), slog.String("code", code))
+	return id, err
+}
+
+func (s LoggingStorage) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	start := time.Now()
+	events, err := s.next.GetEvents(ctx, number)
+	s.log(ctx, "GetEvents", start, err, slog.Int("number", number), slog.Int("rows", len(events)))
+	return events, err
+}
+
+// isDomainError сообщает, что ошибка — ожидаемый отказ хранилища,
+// а не сбой БД или драйвера.
+func isDomainError(err error) bool {
+	for _, target := range []error{
+		ErrParcelNotFound,
+		ErrAddressChangeNotAllowed,
+		ErrDeleteNotAllowed,
+		ErrCancelNotAllowed,
+		ErrConflict,
+		ErrClientNotFound,
+		ErrClientHasParcels,
+		ErrCourierNotFound,
+		ErrAssignNotAllowed,
+		ErrNotAssigned,
+		ErrLocationNotFound,
+		ErrInvalidLocation,
+		ErrInvalidTransition,
+		ErrInvalidListOptions,
+		ErrInvalidSort,
+		ErrInvalidCursor,
+		ErrInvalidSearchQuery,
+		ErrEncryptedSearch,
+		ErrUnsupportedFilter,
+		ErrInvalidEvent,
+		ErrInvalidRange,
+		ErrInvalidFilter,
+		ErrInvalidTrackCode,
+		ErrInvalidAddress,
+		ErrInvalidCoordinates,
+		ErrInvalidDimensions,
+		ErrInvalidPriceRate,
+		ErrNoPriceRate,
+		ErrPaymentRequired,
+		ErrAlreadyPaid,
+		ErrInvalidPayment,
+		ErrInvalidUUID,
+		ErrInvalidImport,
+		ErrInvalidParcel,
+		ErrInvalidIdempotencyKey,
+		ErrIdempotentBatch,
+		ErrUnknownServiceClass,
+		ErrInvalidCancelReason,
+		ErrInvalidClaim,
+		ErrClaimNotFound,
+		ErrClaimResolved,
+		ErrOpenClaim,
+		ErrInvalidNote,
+		ErrInvalidAttachment,
+		ErrAttachmentNotFound,
+		ErrProofNotAllowed,
+		ErrInvalidAttempt,
+		ErrAttemptNotAllowed,
+		ErrInvalidManifest,
+		ErrManifestNotFound,
+		ErrInvalidSignature,
+		ErrSignatureNotFound,
+		ErrCrossShardUpdate,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoggingStorage проверяет, что операции хранилища попадают в лог с нужным уровнем
+func TestLoggingStorage(t *testing.T) {
+	// prepare
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := context.Background()
+	store := NewLoggingStorage(NewMemoryParcelStore(), logger)
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "level=DEBUG")
+	require.Contains(t, buf.String(), "op=Add")
+	require.Contains(t, buf.String(), "client=1000")
+	require.Contains(t, buf.String(), "duration=")
+
+	// domain error
+	buf.Reset()
+	err = store.SetStatus(ctx, id, ParcelStatusDelivered)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+	require.Contains(t, buf.String(), "level=WARN")
+	require.Contains(t, buf.String(), "op=SetStatus")
+	require.Contains(t, buf.String(), "error=")
+}
+
+// TestLoggingStorageLevel проверяет, что уровень логгера отсекает отладочные записи
+func TestLoggingStorageLevel(t *testing.T) {
+	// prepare
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	ctx := context.Background()
+	store := NewLoggingStorage(NewMemoryParcelStore(), logger)
+
+	// check
+	_, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	_, err = store.Get(ctx, 100)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	require.Contains(t, buf.String(), "op=Get")
+
+	_, err = NewLogger("verbose")
+	require.Error(t, err)
+}
@@ -1,111 +1,83 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
-const (
-	ParcelStatusRegistered = "registered"
-	ParcelStatusSent       = "sent"
-	ParcelStatusDelivered  = "delivered"
-)
-
 type Parcel struct {
-	Number    int
-	Client    int
-	Status    string
-	Address   string
-	CreatedAt string
-}
-
-type ParcelService struct {
-	store ParcelStore
-}
-
-func NewParcelService(store ParcelStore) ParcelService {
-	return ParcelService{store: store}
-}
-
-func (s ParcelService) Register(client int, address string) (Parcel, error) {
-	parcel := Parcel{
-		Client:    client,
-		Status:    ParcelStatusRegistered,
-		Address:   address,
-		CreatedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-
-	id, err := s.store.Add(parcel)
-	if err != nil {
-		return parcel, err
-	}
-
-	parcel.Number = id
-
-	fmt.Printf("Новая посылка № %d на адрес %s от клиента с идентификатором %d зарегистрирована %s\n",
-		parcel.Number, parcel.Address, parcel.Client, parcel.CreatedAt)
-
-	return parcel, nil
+	Number int
+	Client int
+	Status Status
+	// SenderAddress — адрес отправителя, пустой у посылок,
+	// зарегистрированных до его появления
+	SenderAddress string
+	// RecipientAddress — адрес получателя, куда доставляется посылка
+	RecipientAddress string
+	CreatedAt        time.Time
+	// UpdatedAt меняется вместе с Version, у новой посылки равен CreatedAt
+	UpdatedAt time.Time
+	// Version растёт на 1 при каждом изменении посылки, см. Update
+	Version int
+	// TrackCode — публичный идентификатор посылки для клиентов, см. NewTrackCode
+	TrackCode string
+	// UUID — глобальный ключ посылки в режиме KeyModeUUID, иначе пустой
+	UUID string
+	// ETA — ожидаемая дата доставки, нулевая, пока не рассчитана, см. DeliveryEstimator
+	ETA time.Time
+	// WeightGrams — вес в граммах, LengthMM, WidthMM и HeightMM — габариты
+	// в миллиметрах; нули означают, что посылку ещё не взвесили и не измерили
+	WeightGrams int
+	LengthMM    int
+	WidthMM     int
+	HeightMM    int
+	// Price — стоимость доставки в копейках по тарифу на момент регистрации,
+	// ноль, если тариф не задан, см. Pricer
+	Price int64
+	// Amount — сумма к оплате в минимальных единицах валюты Currency,
+	// по умолчанию равна Price. PaymentStatus — unpaid или paid,
+	// PaidAt и PaymentRef задаёт MarkPaid
+	Amount        int64
+	Currency      string
+	PaymentStatus string
+	PaidAt        time.Time
+	PaymentRef    string
+	// ServiceClass — класс обслуживания, SLADeadline — крайний срок доставки
+	// по нему, нулевой без срока; SLABreachedAt — когда проверка SLA нашла
+	// посылку просроченной, см. SLAChecker
+	ServiceClass  string
+	SLADeadline   time.Time
+	SLABreachedAt time.Time
+	// ReturnOf — номер исходной посылки у возвратной, см. ParcelService.CreateReturn
+	ReturnOf int
+	// CancelReason и CancelledAt — причина и время отмены, см. ParcelService.Cancel
+	CancelReason CancelReason
+	CancelledAt  time.Time
+	// SignedBy и SignedAt — кто и когда расписался при доставке,
+	// см. ParcelService.Deliver; сама подпись читается через SignatureStore
+	SignedBy string
+	SignedAt time.Time
 }
 
-func (s ParcelService) PrintClientParcels(client int) error {
-	parcels, err := s.store.GetByClient(client)
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("Посылки клиента %d:\n", client)
-	for _, parcel := range parcels {
-		fmt.Printf("Посылка № %d на адрес %s от клиента с идентификатором %d зарегистрирована %s, статус %s\n",
-			parcel.Number, parcel.Address, parcel.Client, parcel.CreatedAt, parcel.Status)
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(1)
 	}
-	fmt.Println()
-
-	return nil
 }
 
-func (s ParcelService) NextStatus(number int) error {
-	parcel, err := s.store.Get(number)
-	if err != nil {
-		return err
-	}
-
-	var nextStatus string
-	switch parcel.Status {
-	case ParcelStatusRegistered:
-		nextStatus = ParcelStatusSent
-	case ParcelStatusSent:
-		nextStatus = ParcelStatusDelivered
-	case ParcelStatusDelivered:
-		return nil
-	}
-
-	fmt.Printf("У посылки № %d новый статус: %s\n", number, nextStatus)
-
-	return s.store.SetStatus(number, nextStatus)
-}
-
-func (s ParcelService) ChangeAddress(number int, address string) error {
-	return s.store.SetAddress(number, address)
-}
-
-func (s ParcelService) Delete(number int) error {
-	return s.store.Delete(number)
-}
-
-func main() {
-	// настройте подключение к БД
-
-	store := // создайте объект ParcelStore функцией NewParcelStore
-	service := NewParcelService(store)
+// runDemo прогоняет основные сценарии работы с посылками и печатает результат.
+func runDemo(ctx context.Context, service ParcelService) {
 
 	// регистрация посылки
 	client := 1
+	sender := "Москва, ул. Тверская, д. 1"
 	address := "Псков, д. Пушкина, ул. Колотушкина, д. 5"
-	p, err := service.Register(client, address)
+	p, err := service.Register(ctx, client, sender, address)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -113,50 +85,52 @@ func main() {
 
 	// изменение адреса
 	newAddress := "Саратов, д. Верхние Зори, ул. Козлова, д. 25"
-	err = service.ChangeAddress(p.Number, newAddress)
+	err = service.ChangeAddress(ctx, p.Number, newAddress)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// изменение статуса
-	err = service.NextStatus(p.Number)
+	err = service.NextStatus(ctx, p.Number)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// вывод посылок клиента
-	err = service.PrintClientParcels(client)
+	err = service.PrintClientParcels(ctx, client)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// попытка удаления отправленной посылки
-	err = service.Delete(p.Number)
-	if err != nil {
+	err = service.Delete(ctx, p.Number)
+	if errors.Is(err, ErrDeleteNotAllowed) {
+		fmt.Printf("Посылку № %d нельзя удалить: она уже отправлена\n", p.Number)
+	} else if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// вывод посылок клиента
 	// предыдущая посылка не должна удалиться, т.к. её статус НЕ «зарегистрирована»
-	err = service.PrintClientParcels(client)
+	err = service.PrintClientParcels(ctx, client)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// регистрация новой посылки
-	p, err = service.Register(client, address)
+	p, err = service.Register(ctx, client, sender, address)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// удаление новой посылки
-	err = service.Delete(p.Number)
+	err = service.Delete(ctx, p.Number)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -164,7 +138,7 @@ func main() {
 
 	// вывод посылок клиента
 	// здесь не должно быть последней посылки, т.к. она должна была успешно удалиться
-	err = service.PrintClientParcels(client)
+	err = service.PrintClientParcels(ctx, client)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MaintenanceTask — работа по обслуживанию БД.
+type MaintenanceTask string
+
+const (
+	// MaintenanceVacuum возвращает место удалённых строк: VACUUM
+	// в SQLite и PostgreSQL, OPTIMIZE TABLE в MySQL
+	MaintenanceVacuum MaintenanceTask = "vacuum"
+	// MaintenanceAnalyze обновляет статистику планировщика запросов
+	MaintenanceAnalyze MaintenanceTask = "analyze"
+	// MaintenanceCheckpoint сбрасывает журнал: усекает WAL в SQLite,
+	// выполняет CHECKPOINT в PostgreSQL и FLUSH BINARY LOGS в MySQL
+	MaintenanceCheckpoint MaintenanceTask = "checkpoint"
+)
+
+// maintenanceTasks — задачи по умолчанию в порядке выполнения.
+var maintenanceTasks = []MaintenanceTask{MaintenanceVacuum, MaintenanceAnalyze, MaintenanceCheckpoint}
+
+// MaintenanceConfig включает обслуживание БД по расписанию Schedule
+// в формате crontab, см. ParseSchedule. Tasks задаёт задачи, пустой
+// список означает все: vacuum, analyze и checkpoint.
+type MaintenanceConfig struct {
+	Schedule string            `yaml:"schedule"`
+	Tasks    []MaintenanceTask `yaml:"tasks"`
+}
+
+func (c MaintenanceConfig) enabled() bool {
+	return c.Schedule != ""
+}
+
+func (c MaintenanceConfig) validate(driver string) error {
+	if c.enabled() {
+		if driver == "memory" {
+			return errors.New("config: storage memory does not support maintenance")
+		}
+		if _, err := ParseSchedule(c.Schedule); err != nil {
+			return fmt.Errorf("config: maintenance: %w", err)
+		}
+	}
+	for _, task := range c.Tasks {
+		if !slices.Contains(maintenanceTasks, task) {
+			return fmt.Errorf("config: unknown maintenance task %q", task)
+		}
+	}
+	return nil
+}
+
+// RelationSize — размер таблицы или индекса на диске.
+type RelationSize struct {
+	Name string
+	// Kind — table или index
+	Kind  string
+	Bytes int64
+}
+
+// Maintainer обслуживает БД. Реализуется только SQL-хранилищем.
+type Maintainer interface {
+	// Maintain выполняет задачу task
+	Maintain(ctx context.Context, task MaintenanceTask) error
+	// RelationSizes возвращает размеры таблиц и индексов
+	RelationSizes(ctx context.Context) ([]RelationSize, error)
+}
+
+var _ Maintainer = ParcelStore{}
+
+// Maintain выполняет задачу на основной БД в обход транзакции:
+// VACUUM нельзя выполнить внутри неё.
+func (s ParcelStore) Maintain(ctx context.Context, task MaintenanceTask) error {
+	db, err := s.sqlDB()
+	if err != nil {
+		return err
+	}
+	var queries []string
+	switch s.dialect.name {
+	case "sqlite":
+		queries = map[MaintenanceTask][]string{
+			MaintenanceVacuum:     {"VACUUM"},
+			MaintenanceAnalyze:    {"ANALYZE"},
+			MaintenanceCheckpoint: {"PRAGMA wal_checkpoint(TRUNCATE)"},
+		}[task]
+	case "postgres":
+		queries = map[MaintenanceTask][]string{
+			MaintenanceVacuum:     {"VACUUM"},
+			MaintenanceAnalyze:    {"ANALYZE"},
+			MaintenanceCheckpoint: {"CHECKPOINT"},
+		}[task]
+	case "mysql":
+		switch task {
+		case MaintenanceVacuum, MaintenanceAnalyze:
+			tables, err := s.mysqlTables(ctx)
+			if err != nil {
+				return err
+			}
+			verb := map[MaintenanceTask]string{MaintenanceVacuum: "OPTIMIZE", MaintenanceAnalyze: "ANALYZE"}[task]
+			queries = []string{verb + " TABLE " + strings.Join(tables, ", ")}
+		case MaintenanceCheckpoint:
+			queries = []string{"FLUSH BINARY LOGS"}
+		}
+	default:
+		return fmt.Errorf("maintenance: not supported for %s", s.dialect.name)
+	}
+	if queries == nil {
+		return fmt.Errorf("maintenance: unknown task %q", task)
+	}
+
+	for _, query := range queries {
+		// OPTIMIZE TABLE и wal_checkpoint возвращают строки с итогом
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("maintenance %s: %w", task, err)
+		}
+		if err := rows.Close(); err != nil {
+			return fmt.Errorf("maintenance %s: %w", task, err)
+		}
+	}
+	return nil
+}
+
+func (s ParcelStore) mysqlTables(ctx context.Context) ([]string, error) {
+	rows, err := s.q.QueryContext(ctx,
+		"SELECT table_name FROM information_schema.TABLES WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, "`"+name+"`")
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("maintenance: no tables")
+	}
+	return tables, nil
+}
+
+// RelationSizes читает размеры из dbstat в SQLite и pg_class в PostgreSQL.
+// MySQL не раскладывает размер по индексам, и индексы таблицы
+// возвращаются одной записью с её именем.
+func (s ParcelStore) RelationSizes(ctx context.Context) ([]RelationSize, error) {
+	var query string
+	switch s.dialect.name {
+	case "sqlite":
+		query = "SELECT m.name, m.type, SUM(d.pgsize) FROM sqlite_master m JOIN dbstat d ON d.name = m.name" +
+			" WHERE m.type IN ('table', 'index') GROUP BY m.name, m.type ORDER BY m.name"
+	case "postgres":
+		query = "SELECT c.relname, CASE c.relkind WHEN 'i' THEN 'index' ELSE 'table' END, pg_relation_size(c.oid)" +
+			" FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace" +
+			" WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'i') ORDER BY c.relname"
+	case "mysql":
+		query = "SELECT table_name, 'table', data_length FROM information_schema.TABLES WHERE table_schema = DATABASE()" +
+			" UNION ALL SELECT table_name, 'index', index_length FROM information_schema.TABLES WHERE table_schema = DATABASE()" +
+			" ORDER BY 1, 2 DESC"
+	default:
+		return nil, fmt.Errorf("maintenance: not supported for %s", s.dialect.name)
+	}
+
+	db, err := s.sqlDB()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sizes []RelationSize
+	for rows.Next() {
+		var r RelationSize
+		if err := rows.Scan(&r.Name, &r.Kind, &r.Bytes); err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, r)
+	}
+	return sizes, rows.Err()
+}
+
+// MaintenanceReport — итог прогона MaintenanceRunner: выполненные задачи
+// и размеры таблиц и индексов после них.
+type MaintenanceReport struct {
+	Tasks []MaintenanceTask
+	Sizes []RelationSize
+}
+
+// MaintenanceRunner обслуживает БД по расписанию и публикует размеры
+// таблиц и индексов в метрике parcel_db_relation_size_bytes.
+type MaintenanceRunner struct {
+	store    Maintainer
+	schedule Schedule
+	tasks    []MaintenanceTask
+	runs     *prometheus.CounterVec
+	sizes    *prometheus.GaugeVec
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewMaintenanceRunner создаёт планировщик обслуживания store по cfg
+// и регистрирует его метрики в reg, если он задан. Пустое расписание
+// годится только для RunOnce.
+func NewMaintenanceRunner(store Maintainer, cfg MaintenanceConfig, reg prometheus.Registerer) (MaintenanceRunner, error) {
+	r := MaintenanceRunner{
+		store:  store,
+		tasks:  cfg.Tasks,
+		logger: slog.Default(),
+		now:    time.Now,
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parcel_maintenance_runs_total",
+			Help: "Number of database maintenance tasks by task and result.",
+		}, []string{"task", "result"}),
+		sizes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "parcel_db_relation_size_bytes",
+			Help: "Size of database tables and indexes on disk, updated by maintenance runs.",
+		}, []string{"relation", "kind"}),
+	}
+	if len(r.tasks) == 0 {
+		r.tasks = maintenanceTasks
+	}
+	if cfg.enabled() {
+		var err error
+		if r.schedule, err = ParseSchedule(cfg.Schedule); err != nil {
+			return MaintenanceRunner{}, err
+		}
+	}
+
+	if reg != nil {
+		for _, c := range []prometheus.Collector{r.runs, r.sizes} {
+			if err := reg.Register(c); err != nil {
+				return MaintenanceRunner{}, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// WithLogger возвращает копию планировщика, пишущую отчёты в logger.
+func (r MaintenanceRunner) WithLogger(logger *slog.Logger) MaintenanceRunner {
+	r.logger = logger
+	return r
+}
+
+// Run выполняет RunOnce в каждую минуту расписания, пока не отменён ctx.
+// Ошибка прогона логируется и не останавливает планировщик.
+func (r MaintenanceRunner) Run(ctx context.Context) error {
+	for {
+		next := r.schedule.Next(r.now())
+		if next.IsZero() {
+			return errors.New("maintenance: schedule never fires")
+		}
+
+		timer := time.NewTimer(next.Sub(r.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		r.RunOnce(ctx)
+	}
+}
+
+// RunOnce выполняет задачи по порядку и обновляет метрику размеров.
+// Ошибка задачи не мешает следующим; ошибки возвращаются вместе.
+func (r MaintenanceRunner) RunOnce(ctx context.Context) (MaintenanceReport, error) {
+	var report MaintenanceReport
+	var errs []error
+	for _, task := range r.tasks {
+		start := time.Now()
+		if err := r.store.Maintain(ctx, task); err != nil {
+			r.runs.WithLabelValues(string(task), "error").Inc()
+			if ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "maintenance", "task", task, "error", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		r.runs.WithLabelValues(string(task), "ok").Inc()
+		r.logger.InfoContext(ctx, "maintenance", "task", task, "duration", time.Since(start))
+		report.Tasks = append(report.Tasks, task)
+	}
+
+	sizes, err := r.store.RelationSizes(ctx)
+	if err != nil {
+		return report, errors.Join(append(errs, err)...)
+	}
+	// удалённые таблицы не должны оставаться в метрике
+	r.sizes.Reset()
+	for _, s := range sizes {
+		r.sizes.WithLabelValues(s.Name, s.Kind).Set(float64(s.Bytes))
+	}
+	report.Sizes = sizes
+
+	return report, errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// checkMaintenance проверяет VACUUM, ANALYZE и размеры таблиц и индексов
+func checkMaintenance(t *testing.T, store ParcelStore) {
+	// prepare
+	ctx := context.Background()
+	_, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	require.NoError(t, store.Maintain(ctx, MaintenanceVacuum))
+	require.NoError(t, store.Maintain(ctx, MaintenanceAnalyze))
+	require.Error(t, store.Maintain(ctx, "reindex"))
+
+	sizes, err := store.RelationSizes(ctx)
+	require.NoError(t, err)
+	kinds := map[string]string{}
+	for _, s := range sizes {
+		require.Positive(t, s.Bytes, s.Name)
+		kinds[s.Name] = s.Kind
+	}
+	require.Equal(t, "table", kinds["parcel"])
+	require.Equal(t, "index", kinds["parcel_status_idx"])
+}
+
+// TestMaintenance проверяет обслуживание SQLite и метрики планировщика
+func TestMaintenance(t *testing.T) {
+	checkMaintenance(t, NewParcelStore(openTempDB(t)))
+
+	// prepare
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	runner, err := NewMaintenanceRunner(NewParcelStore(openTempDB(t)), MaintenanceConfig{Schedule: "@hourly"}, reg)
+	require.NoError(t, err)
+
+	// check
+	report, err := runner.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []MaintenanceTask{MaintenanceVacuum, MaintenanceAnalyze, MaintenanceCheckpoint}, report.Tasks)
+	require.NotEmpty(t, report.Sizes)
+	require.Equal(t, 1.0, testutil.ToFloat64(runner.runs.WithLabelValues("checkpoint", "ok")))
+	require.Equal(t, float64(report.Sizes[0].Bytes),
+		testutil.ToFloat64(runner.sizes.WithLabelValues(report.Sizes[0].Name, report.Sizes[0].Kind)))
+
+	_, err = NewMaintenanceRunner(NewParcelStore(openTempDB(t)), MaintenanceConfig{}, reg)
+	require.Error(t, err)
+	_, err = NewMaintenanceRunner(NewParcelStore(openTempDB(t)), MaintenanceConfig{Schedule: "daily"}, nil)
+	require.Error(t, err)
+
+	cfg := DefaultConfig()
+	cfg.Maintenance = MaintenanceConfig{Schedule: "0 3 * * *", Tasks: []MaintenanceTask{MaintenanceAnalyze}}
+	require.NoError(t, cfg.Validate())
+	cfg.Maintenance.Tasks = []MaintenanceTask{"reindex"}
+	require.Error(t, cfg.Validate())
+	cfg = DefaultConfig()
+	cfg.Driver = "memory"
+	cfg.Maintenance.Schedule = "@daily"
+	require.Error(t, cfg.Validate())
+}
+
+// TestPostgresMaintenance проверяет обслуживание PostgreSQL
+func TestPostgresMaintenance(t *testing.T) {
+	checkMaintenance(t, NewPostgresParcelStore(openPostgres(t)).ParcelStore)
+}
+
+// TestMySQLMaintenance проверяет обслуживание MySQL
+func TestMySQLMaintenance(t *testing.T) {
+	checkMaintenance(t, NewMySQLParcelStore(openMySQL(t)).ParcelStore)
+}
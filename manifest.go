@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ManifestOrder — порядок остановок в маршрутном листе курьера.
+type ManifestOrder string
+
+const (
+	// ManifestByPostcode сортирует остановки по почтовому индексу адреса
+	ManifestByPostcode ManifestOrder = "postcode"
+	// ManifestByDistance объезжает адреса от ближайшего к ближайшему
+	// по координатам геокодера
+	ManifestByDistance ManifestOrder = "distance"
+)
+
+var (
+	// ErrInvalidManifest возвращается для неизвестного порядка остановок
+	// или неверной точки старта.
+	ErrInvalidManifest = errors.New("invalid manifest options")
+	// ErrManifestNotFound возвращается, если маршрутный лист на этот день
+	// не построен.
+	ErrManifestNotFound = errors.New("manifest not found")
+)
+
+// postcodePattern — шестизначный почтовый индекс в адресе.
+var postcodePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// ManifestOptions задаёт построение маршрутного листа. Start — точка,
+// откуда выезжает курьер, для порядка distance; нулевая точка означает
+// первый геокодированный адрес из очереди курьера.
+type ManifestOptions struct {
+	Order ManifestOrder
+	Start Coordinates
+}
+
+// ManifestStop — остановка маршрутного листа. Адрес и координаты
+// записаны на момент построения листа, адрес шифруется, как у посылки.
+type ManifestStop struct {
+	Seq              int      `json:"seq"`
+	Number           int      `json:"number"`
+	TrackCode        string   `json:"track_code,omitempty"`
+	RecipientAddress string   `json:"recipient_address"`
+	Lat              *float64 `json:"lat,omitempty"`
+	Lon              *float64 `json:"lon,omitempty"`
+}
+
+// Manifest — маршрутный лист курьера на день Date в формате 2006-01-02.
+// Остановки идут по возрастанию Seq, начиная с 1. JSON листа читает
+// приложение курьера, см. parcelctl courier manifest.
+type Manifest struct {
+	ID        int            `json:"id"`
+	CourierID int            `json:"courier_id"`
+	Date      string         `json:"date"`
+	Order     ManifestOrder  `json:"order"`
+	CreatedAt time.Time      `json:"created_at"`
+	Stops     []ManifestStop `json:"stops"`
+}
+
+// ManifestStore строит маршрутные листы курьеров.
+type ManifestStore interface {
+	// BuildManifest строит и сохраняет маршрутный лист курьера на день date,
+	// заменяя построенный ранее
+	BuildManifest(ctx context.Context, courierID int, date time.Time, opts ManifestOptions) (Manifest, error)
+	GetManifest(ctx context.Context, courierID int, date time.Time) (Manifest, error)
+}
+
+var _ ManifestStore = ParcelStore{}
+
+// BuildManifest включает в лист посылки курьера в статусе sent, ожидаемые
+// не позже дня date или без ETA, и нумерует остановки в порядке opts.Order.
+func (s ParcelStore) BuildManifest(ctx context.Context, courierID int, date time.Time, opts ManifestOptions) (Manifest, error) {
+	if opts.Order != ManifestByPostcode && opts.Order != ManifestByDistance {
+		return Manifest{}, fmt.Errorf("%w: unknown order %q", ErrInvalidManifest, opts.Order)
+	}
+	if err := opts.Start.validate(); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	m := Manifest{
+		CourierID: courierID,
+		Date:      day.Format(dayLayout),
+		Order:     opts.Order,
+		CreatedAt: timestamp(s.now),
+	}
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		queue, err := tx.GetByCourier(ctx, courierID)
+		if err != nil {
+			return err
+		}
+		var stops []manifestCandidate
+		for _, p := range queue {
+			if p.Status != ParcelStatusSent || (!p.ETA.IsZero() && !p.ETA.Before(day.AddDate(0, 0, 1))) {
+				continue
+			}
+			c, err := tx.GetCoordinates(ctx, p.Number)
+			if err != nil {
+				return err
+			}
+			stops = append(stops, manifestCandidate{parcel: p, coords: c})
+		}
+		if opts.Order == ManifestByPostcode {
+			orderByPostcode(stops)
+		} else {
+			stops = orderByDistance(stops, opts.Start)
+		}
+
+		if err := tx.deleteManifest(ctx, courierID, m.Date); err != nil {
+			return err
+		}
+		m.ID, err = tx.insert(ctx,
+			"INSERT INTO courier_manifest (courier_id, manifest_date, sort_order, created_at) VALUES (?, ?, ?, ?)", "id",
+			courierID, m.Date, m.Order, tx.dialect.timeArg(m.CreatedAt))
+		if err != nil {
+			return err
+		}
+		for i, c := range stops {
+			stop := ManifestStop{Seq: i + 1, Number: c.parcel.Number, TrackCode: c.parcel.TrackCode, RecipientAddress: c.parcel.RecipientAddress}
+			var lat, lon any
+			if !c.coords.IsZero() {
+				stop.Lat, stop.Lon = &c.coords.Lat, &c.coords.Lon
+				lat, lon = c.coords.Lat, c.coords.Lon
+			}
+			address, err := tx.encrypt(stop.RecipientAddress)
+			if err != nil {
+				return err
+			}
+			_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+				"INSERT INTO courier_manifest_stop (manifest_id, seq, parcel_number, track_code, recipient_address, lat, lon)"+
+					" VALUES (?, ?, ?, ?, ?, ?, ?)"),
+				m.ID, stop.Seq, stop.Number, stop.TrackCode, address, lat, lon)
+			if err != nil {
+				return err
+			}
+			m.Stops = append(m.Stops, stop)
+		}
+		return nil
+	})
+	if err != nil {
+		return Manifest{}, err
+	}
+	return m, nil
+}
+
+func (s ParcelStore) GetManifest(ctx context.Context, courierID int, date time.Time) (Manifest, error) {
+	m := Manifest{CourierID: courierID, Date: date.Format(dayLayout)}
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT id, sort_order, created_at FROM courier_manifest WHERE courier_id = ? AND manifest_date = ?"),
+		courierID, m.Date).Scan(&m.ID, &m.Order, scanTime{&m.CreatedAt})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Manifest{}, ErrManifestNotFound
+	}
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT seq, parcel_number, track_code, recipient_address, lat, lon FROM courier_manifest_stop"+
+			" WHERE manifest_id = ? ORDER BY seq"), m.ID)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stop ManifestStop
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&stop.Seq, &stop.Number, &stop.TrackCode, &stop.RecipientAddress, &lat, &lon); err != nil {
+			return Manifest{}, err
+		}
+		if stop.RecipientAddress, err = s.decrypt(stop.RecipientAddress); err != nil {
+			return Manifest{}, err
+		}
+		if lat.Valid && lon.Valid {
+			stop.Lat, stop.Lon = &lat.Float64, &lon.Float64
+		}
+		m.Stops = append(m.Stops, stop)
+	}
+	return m, rows.Err()
+}
+
+// deleteManifest удаляет маршрутный лист курьера на день date вместе с остановками.
+func (s ParcelStore) deleteManifest(ctx context.Context, courierID int, date string) error {
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"DELETE FROM courier_manifest_stop WHERE manifest_id IN"+
+			" (SELECT id FROM courier_manifest WHERE courier_id = ? AND manifest_date = ?)"),
+		courierID, date)
+	if err != nil {
+		return err
+	}
+	_, err = s.q.ExecContext(ctx, s.dialect.rebind(
+		"DELETE FROM courier_manifest WHERE courier_id = ? AND manifest_date = ?"),
+		courierID, date)
+	return err
+}
+
+// manifestCandidate — посылка из очереди курьера с координатами адреса.
+type manifestCandidate struct {
+	parcel Parcel
+	coords Coordinates
+}
+
+// postcode возвращает почтовый индекс из адреса или пустую строку.
+func postcode(address string) string {
+	return postcodePattern.FindString(address)
+}
+
+// orderByPostcode сортирует остановки по индексу, адреса без индекса — в конец.
+// Внутри индекса сохраняется очередь курьера: сначала срочные посылки.
+func orderByPostcode(stops []manifestCandidate) {
+	sort.SliceStable(stops, func(i, j int) bool {
+		a, b := postcode(stops[i].parcel.RecipientAddress), postcode(stops[j].parcel.RecipientAddress)
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+		return a < b
+	})
+}
+
+// orderByDistance строит маршрут жадно: каждая следующая остановка —
+// ближайшая к предыдущей. Адреса без координат идут в конце в порядке очереди.
+func orderByDistance(stops []manifestCandidate, start Coordinates) []manifestCandidate {
+	var located, rest []manifestCandidate
+	for _, c := range stops {
+		if c.coords.IsZero() {
+			rest = append(rest, c)
+		} else {
+			located = append(located, c)
+		}
+	}
+	if len(located) > 0 && start.IsZero() {
+		start = located[0].coords
+	}
+
+	res := make([]manifestCandidate, 0, len(stops))
+	for len(located) > 0 {
+		next := 0
+		for i, c := range located {
+			if c.coords.Distance(start) < located[next].coords.Distance(start) {
+				next = i
+			}
+		}
+		res = append(res, located[next])
+		start = located[next].coords
+		located = append(located[:next], located[next+1:]...)
+	}
+	return append(res, rest...)
+}
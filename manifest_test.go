@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildManifest проверяет маршрутный лист курьера по индексам
+// и по расстоянию между адресами
+func TestBuildManifest(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	courier, err := store.AddCourier(ctx, Courier{Name: "Алексей"})
+	require.NoError(t, err)
+
+	stops := []struct {
+		address string
+		coords  Coordinates
+	}{
+		{"125009, Москва, ул. Тверская, 1", Coordinates{Lat: 55.7577, Lon: 37.6135}},
+		{"Москва, Кутузовский пр., 2", Coordinates{Lat: 55.7490, Lon: 37.5650}},
+		{"119019, Москва, ул. Арбат, 1", Coordinates{Lat: 55.7520, Lon: 37.5990}},
+	}
+	var numbers []int
+	for _, s := range stops {
+		p := getTestParcel()
+		p.RecipientAddress = s.address
+		number, err := store.Add(ctx, p)
+		require.NoError(t, err)
+		require.NoError(t, store.SetCoordinates(ctx, number, s.coords))
+		require.NoError(t, store.AssignCourier(ctx, number, courier))
+		numbers = append(numbers, number)
+	}
+	// доставленная посылка в лист не попадает
+	done, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.AssignCourier(ctx, done, courier))
+	require.NoError(t, store.CompleteDelivery(ctx, courier, done))
+
+	day := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	_, err = store.BuildManifest(ctx, courier, day, ManifestOptions{Order: "random"})
+	require.ErrorIs(t, err, ErrInvalidManifest)
+	_, err = store.GetManifest(ctx, courier, day)
+	require.ErrorIs(t, err, ErrManifestNotFound)
+
+	// add
+	m, err := store.BuildManifest(ctx, courier, day, ManifestOptions{Order: ManifestByPostcode})
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, "2026-10-14", m.Date)
+	require.Equal(t, []int{numbers[2], numbers[0], numbers[1]}, manifestNumbers(m))
+	for i, stop := range m.Stops {
+		require.Equal(t, i+1, stop.Seq)
+	}
+	got, err := store.GetManifest(ctx, courier, day)
+	require.NoError(t, err)
+	require.Equal(t, m, got)
+
+	// перестроение заменяет лист: от Кутузовского ближе Арбат, чем Тверская
+	m, err = store.BuildManifest(ctx, courier, day, ManifestOptions{
+		Order: ManifestByDistance,
+		Start: Coordinates{Lat: 55.7400, Lon: 37.5300},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{numbers[1], numbers[2], numbers[0]}, manifestNumbers(m))
+	require.Equal(t, stops[1].coords.Lat, *m.Stops[0].Lat)
+	got, err = store.GetManifest(ctx, courier, day)
+	require.NoError(t, err)
+	require.Equal(t, m, got)
+}
+
+// manifestNumbers возвращает номера посылок в порядке остановок листа m
+func manifestNumbers(m Manifest) []int {
+	var res []int
+	for _, stop := range m.Stops {
+		res = append(res, stop.Number)
+	}
+	return res
+}
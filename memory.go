@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryParcelStore хранит посылки в памяти процесса.
+// Повторяет поведение SQL-хранилищ, включая ошибки из errors.go
+// и правило registered для смены адреса и удаления,
+// поэтому подходит для юнит-тестов сервиса без базы данных.
+type MemoryParcelStore struct {
+	mu      sync.Mutex
+	parcels map[int]Parcel
+	deleted map[int]Parcel
+	history map[int][]StatusChange
+	events  map[int][]TrackingEvent
+	// tenants — арендаторы посылок, см. WithTenant
+	tenants map[int]string
+	// idempotent — номера посылок по ключам идемпотентности, см. WithIdempotencyKey
+	idempotent  map[scopedIdempotencyKey]int
+	claims      []Claim
+	notes       map[int][]Note
+	attachments map[int][]Attachment
+	signatures  map[int][]byte
+	attempts    map[int][]DeliveryAttempt
+	last        int
+	lastEvent   int
+	lastClaim   int
+	lastNote    int
+	lastAttach  int
+	lastAttempt int
+	transitions StatusTransitions
+	addresses   AddressValidator
+	keys        KeyMode
+	now         func() time.Time
+	// paymentRequired запрещает отправку без оплаты, см. SetPaymentRequired
+	paymentRequired bool
+}
+
+// scopedIdempotencyKey — ключ идемпотентности в пределах арендатора.
+type scopedIdempotencyKey struct {
+	tenant string
+	key    string
+}
+
+var _ ParcelStorage = (*MemoryParcelStore)(nil)
+
+func init() {
+	RegisterStorage("memory", func(*sql.DB) ParcelStorage {
+		return NewMemoryParcelStore()
+	})
+}
+
+func NewMemoryParcelStore() *MemoryParcelStore {
+	return &MemoryParcelStore{
+		parcels:     map[int]Parcel{},
+		deleted:     map[int]Parcel{},
+		history:     map[int][]StatusChange{},
+		events:      map[int][]TrackingEvent{},
+		tenants:     map[int]string{},
+		notes:       map[int][]Note{},
+		attachments: map[int][]Attachment{},
+		signatures:  map[int][]byte{},
+		attempts:    map[int][]DeliveryAttempt{},
+		idempotent:  map[scopedIdempotencyKey]int{},
+		transitions: DefaultStatusTransitions(),
+		addresses:   DefaultAddressValidator{},
+		keys:        KeyModeInt,
+		now:         time.Now,
+	}
+}
+
+// Close ничего не делает: хранилищу в памяти нечего освобождать.
+func (s *MemoryParcelStore) Close() error {
+	return nil
+}
+
+// SetTransitions задаёт правила смены статуса.
+func (s *MemoryParcelStore) SetTransitions(t StatusTransitions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transitions = t
+}
+
+// visible сообщает, видит ли арендатор из ctx посылку number.
+func (s *MemoryParcelStore) visible(ctx context.Context, number int) bool {
+	tenant := TenantFromContext(ctx)
+	return tenant == "" || s.tenants[number] == tenant
+}
+
+// parcel возвращает неудалённую посылку number, если её видит арендатор из ctx.
+func (s *MemoryParcelStore) parcel(ctx context.Context, number int) (Parcel, bool) {
+	p, ok := s.parcels[number]
+	return p, ok && s.visible(ctx, number)
+}
+
+func (s *MemoryParcelStore) Add(ctx context.Context, p Parcel) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := prepareParcel(s.addresses, p)
+	if err != nil {
+		return 0, err
+	}
+	key := IdempotencyKeyFromContext(ctx)
+	if err := checkIdempotencyKey(key); err != nil {
+		return 0, err
+	}
+	scoped := scopedIdempotencyKey{TenantFromContext(ctx), key}
+	if number, ok := s.idempotent[scoped]; ok && key != "" {
+		_, active := s.parcels[number]
+		_, deleted := s.deleted[number]
+		if active || deleted {
+			markReplayed(ctx)
+			return number, nil
+		}
+	}
+
+	number := s.insert(p, scoped.tenant)
+	if key != "" {
+		s.idempotent[scoped] = number
+	}
+
+	return number, nil
+}
+
+// insert сохраняет уже проверенную посылку под новым номером и возвращает
+// его. Служебные поля заполняются самим хранилищем. Вызывается под s.mu.
+func (s *MemoryParcelStore) insert(p Parcel, tenant string) int {
+	s.last++
+	p.Number = s.last
+	p.Version = 1
+	p.CreatedAt = timestamp(s.now)
+	p.UpdatedAt = p.CreatedAt
+	p.ETA = time.Time{}
+	p.SLABreachedAt = time.Time{}
+	if p.TrackCode == "" {
+		p.TrackCode = NewTrackCode()
+	}
+	if p.UUID == "" && s.keys == KeyModeUUID {
+		p.UUID = newParcelUUID()
+	}
+	s.parcels[p.Number] = p
+	s.tenants[p.Number] = tenant
+
+	return p.Number
+}
+
+func (s *MemoryParcelStore) Get(ctx context.Context, number int) (Parcel, error) {
+	if err := ctx.Err(); err != nil {
+		return Parcel{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return Parcel{}, ErrParcelNotFound
+	}
+
+	return p, nil
+}
+
+func (s *MemoryParcelStore) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []Parcel
+	for _, p := range s.parcels {
+		if p.Client == client && s.visible(ctx, p.Number) {
+			res = append(res, p)
+		}
+	}
+	// SQL-хранилища отдают строки в порядке добавления
+	sort.Slice(res, func(i, j int) bool { return res[i].Number < res[j].Number })
+
+	return res, nil
+}
+
+func (s *MemoryParcelStore) SetStatus(ctx context.Context, number int, status Status) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if err := s.transitions.Validate(p.Status, status); err != nil {
+		return err
+	}
+	if err := checkPayment(s.paymentRequired, p, status); err != nil {
+		return err
+	}
+	s.history[number] = append(s.history[number], StatusChange{
+		Number:    number,
+		OldStatus: p.Status,
+		NewStatus: status,
+		ChangedAt: formatTime(s.now()),
+	})
+	p.Status = status
+	p.Version++
+	p.UpdatedAt = timestamp(s.now)
+	s.parcels[number] = p
+
+	return nil
+}
+
+// SetSenderAddress меняет адрес отправителя посылки в статусе registered.
+func (s *MemoryParcelStore) SetSenderAddress(ctx context.Context, number int, address string) error {
+	return s.setAddress(ctx, number, address, func(p *Parcel) *string { return &p.SenderAddress })
+}
+
+// SetRecipientAddress меняет адрес получателя посылки в статусе registered.
+func (s *MemoryParcelStore) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	return s.setAddress(ctx, number, address, func(p *Parcel) *string { return &p.RecipientAddress })
+}
+
+// setAddress записывает адрес в поле посылки, которое возвращает field.
+func (s *MemoryParcelStore) setAddress(ctx context.Context, number int, address string, field func(*Parcel) *string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	address, err := prepareAddress(s.addresses, address)
+	if err != nil {
+		return err
+	}
+
+	// менять адрес можно только если значение статуса registered
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if p.Status != ParcelStatusRegistered {
+		return ErrAddressChangeNotAllowed
+	}
+	*field(&p) = address
+	p.Version++
+	p.UpdatedAt = timestamp(s.now)
+	s.parcels[number] = p
+
+	return nil
+}
+
+// Delete переносит посылку в удалённые, откуда её возвращает Restore.
+func (s *MemoryParcelStore) Delete(ctx context.Context, number int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// удалять можно только если значение статуса registered
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if s.hasOpenClaims(number) {
+		return ErrOpenClaim
+	}
+	if p.Status != ParcelStatusRegistered {
+		return ErrDeleteNotAllowed
+	}
+	delete(s.parcels, number)
+	p.Version++
+	p.UpdatedAt = timestamp(s.now)
+	s.deleted[number] = p
+
+	return nil
+}
+
+// HardDelete удаляет посылку вместе с историей и событиями.
+func (s *MemoryParcelStore) HardDelete(ctx context.Context, number int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		p, ok = s.deleted[number]
+		ok = ok && s.visible(ctx, number)
+	}
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if s.hasOpenClaims(number) {
+		return ErrOpenClaim
+	}
+	if p.Status != ParcelStatusRegistered {
+		return ErrDeleteNotAllowed
+	}
+	delete(s.parcels, number)
+	delete(s.deleted, number)
+	delete(s.history, number)
+	delete(s.events, number)
+	delete(s.tenants, number)
+	delete(s.notes, number)
+	delete(s.attachments, number)
+	delete(s.signatures, number)
+	delete(s.attempts, number)
+	s.claims = slices.DeleteFunc(s.claims, func(c Claim) bool { return c.Number == number })
+
+	return nil
+}
+
+// Restore возвращает посылку, удалённую через Delete.
+func (s *MemoryParcelStore) Restore(ctx context.Context, number int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.deleted[number]; ok && s.visible(ctx, number) {
+		delete(s.deleted, number)
+		p.Version++
+		p.UpdatedAt = timestamp(s.now)
+		s.parcels[number] = p
+		return nil
+	}
+	if _, ok := s.parcel(ctx, number); !ok {
+		return ErrParcelNotFound
+	}
+
+	return nil
+}
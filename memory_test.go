@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryAddGetDelete проверяет добавление, получение и удаление посылки в памяти
+func TestMemoryAddGetDelete(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	store.SetClock(testClock)
+	parcel := getTestParcel()
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	parcel.Number = id
+	parcel.Version = 1
+
+	// get
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel, stored)
+
+	// delete
+	err = store.Delete(ctx, id)
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestMemorySoftDelete проверяет мягкое удаление в памяти
+func TestMemorySoftDelete(t *testing.T) {
+	store := NewMemoryParcelStore()
+	store.SetClock(testClock)
+	checkSoftDelete(t, store)
+}
+
+// TestMemoryRegisteredRules проверяет, что адрес меняется и посылка удаляется только в статусе registered
+func TestMemoryRegisteredRules(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.NoError(t, err)
+
+	// set address
+	err = store.SetRecipientAddress(ctx, id, "new test address")
+	require.ErrorIs(t, err, ErrAddressChangeNotAllowed)
+
+	// delete
+	err = store.Delete(ctx, id)
+	require.ErrorIs(t, err, ErrDeleteNotAllowed)
+
+	// check
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "test", stored.RecipientAddress)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+}
+
+// TestServiceWithMemoryStore проверяет сервис посылок поверх хранилища в памяти
+func TestServiceWithMemoryStore(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	service := NewParcelService(store)
+
+	// register
+	p, err := service.Register(ctx, 1, "sender", "test")
+	require.NoError(t, err)
+	require.Equal(t, "sender", p.SenderAddress)
+
+	// next status
+	err = service.NextStatus(ctx, p.Number)
+	require.NoError(t, err)
+
+	// check
+	stored, err := store.Get(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+
+	parcels, err := store.GetByClient(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, parcels, 1)
+}
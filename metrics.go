@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// MetricsStorage собирает Prometheus-метрики операций хранилища:
+// parcel_store_operations_total по методу и результату,
+// parcel_store_query_duration_seconds и parcel_store_rows_returned.
+type MetricsStorage struct {
+	next ParcelStorage
+
+	operations *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	rows       *prometheus.HistogramVec
+}
+
+var _ ParcelStorage = MetricsStorage{}
+
+// NewMetricsStorage оборачивает хранилище метриками и регистрирует их в reg.
+// Если db не nil, дополнительно регистрируются метрики пула из sql.DBStats.
+func NewMetricsStorage(next ParcelStorage, reg prometheus.Registerer, db *sql.DB) (MetricsStorage, error) {
+	s := MetricsStorage{
+		next: next,
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parcel_store_operations_total",
+			Help: "Number of parcel store operations by method and result.",
+		}, []string{"method", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "parcel_store_query_duration_seconds",
+			Help:    "Duration of parcel store operations.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		rows: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "parcel_store_rows_returned",
+			Help:    "Number of rows returned by parcel store list operations.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+		}, []string{"method"}),
+	}
+
+	cs := []prometheus.Collector{s.operations, s.duration, s.rows}
+	if db != nil {
+		cs = append(cs, collectors.NewDBStatsCollector(db, "parcel_store"))
+	}
+	for _, c := range cs {
+		if err := reg.Register(c); err != nil {
+			return MetricsStorage{}, err
+		}
+	}
+
+	return s, nil
+}
+
+// observe учитывает завершённую операцию; rows < 0 — операция не возвращает строк.
+func (s MetricsStorage) observe(method string, start time.Time, err error, rows int) {
+	result := "ok"
+	switch {
+	case err == nil:
+	case isDomainError(err):
+		result = "rejected"
+	default:
+		result = "error"
+	}
+
+	s.operations.WithLabelValues(method, result).Inc()
+	s.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if rows >= 0 && err == nil {
+		s.rows.WithLabelValues(method).Observe(float64(rows))
+	}
+}
+
+func (s MetricsStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	start := time.Now()
+	number, err := s.next.Add(ctx, p)
+	s.observe("Add", start, err, -1)
+	return number, err
+}
+
+func (s MetricsStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	start := time.Now()
+	p, err := s.next.Get(ctx, number)
+	s.observe("Get", start, err, -1)
+	return p, err
+}
+
+func (s MetricsStorage) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	start := time.Now()
+	p, err := s.next.GetByTrackCode(ctx, code)
+	s.observe("GetByTrackCode", start, err, -1)
+	return p, err
+}
+
+func (s MetricsStorage) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	start := time.Now()
+	p, err := s.next.GetByUUID(ctx, id)
+	s.observe("GetByUUID", start, err, -1)
+	return p, err
+}
+
+func (s MetricsStorage) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	start := time.Now()
+	parcels, err := s.next.GetByClient(ctx, client)
+	s.observe("GetByClient", start, err, len(parcels))
+	return parcels, err
+}
+
+func (s MetricsStorage) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	start := time.Now()
+	page, err := s.next.ListByClient(ctx, client, opts)
+	s.observe("ListByClient", start, err, len(page.Parcels))
+	return page, err
+}
+
+func (s MetricsStorage) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	start := time.Now()
+	page, err := s.next.List(ctx, filter, afterNumber, limit)
+	s.observe("List", start, err, len(page.Parcels))
+	return page, err
+}
+
+func (s MetricsStorage) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	start := time.Now()
+	page, err := s.next.ListAll(ctx, filter, opts)
+	s.observe("ListAll", start, err, len(page.Parcels))
+	return page, err
+}
+
+func (s MetricsStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	start := time.Now()
+	err := s.next.SetStatus(ctx, number, status)
+	s.observe("SetStatus", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	start := time.Now()
+	err := s.next.SetSenderAddress(ctx, number, address)
+	s.observe("SetSenderAddress", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	start := time.Now()
+	err := s.next.SetRecipientAddress(ctx, number, address)
+	s.observe("SetRecipientAddress", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	start := time.Now()
+	err := s.next.SetETA(ctx, number, eta)
+	s.observe("SetETA", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	start := time.Now()
+	err := s.next.MarkPaid(ctx, number, txRef)
+	s.observe("MarkPaid", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) Update(ctx context.Context, p Parcel) error {
+	start := time.Now()
+	err := s.next.Update(ctx, p)
+	s.observe("Update", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	start := time.Now()
+	err := s.next.Cancel(ctx, number, reason)
+	s.observe("Cancel", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	start := time.Now()
+	err := s.next.Deliver(ctx, number, sig)
+	s.observe("Deliver", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) Delete(ctx context.Context, number int) error {
+	start := time.Now()
+	err := s.next.Delete(ctx, number)
+	s.observe("Delete", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) HardDelete(ctx context.Context, number int) error {
+	start := time.Now()
+	err := s.next.HardDelete(ctx, number)
+	s.observe("HardDelete", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) Restore(ctx context.Context, number int) error {
+	start := time.Now()
+	err := s.next.Restore(ctx, number)
+	s.observe("Restore", start, err, -1)
+	return err
+}
+
+func (s MetricsStorage) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	start := time.Now()
+	history, err := s.next.GetHistory(ctx, number)
+	s.observe("GetHistory", start, err, len(history))
+	return history, err
+}
+
+func (s MetricsStorage) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	start := time.Now()
+	id, err := s.next.AddEvent(ctx, number, code, description, occurredAt)
+	s.observe("AddEvent", start, err, -1)
+	return id, err
+}
+
+func (s MetricsStorage) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	start := time.Now()
+	events, err := s.next.GetEvents(ctx, number)
+	s.observe("GetEvents", start, err, len(events))
+	return events, err
+}
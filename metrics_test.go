@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricsStorage проверяет счётчики операций и метрики пула соединений
+func TestMetricsStorage(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	store, err := NewMetricsStorage(NewParcelStore(db), reg, db)
+	require.NoError(t, err)
+
+	// operations
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	_, err = store.GetByClient(ctx, 1000)
+	require.NoError(t, err)
+
+	err = store.SetStatus(ctx, id, ParcelStatusDelivered)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+
+	// check
+	require.Equal(t, 1.0, testutil.ToFloat64(store.operations.WithLabelValues("Add", "ok")))
+	require.Equal(t, 1.0, testutil.ToFloat64(store.operations.WithLabelValues("SetStatus", "rejected")))
+	require.Equal(t, 1, testutil.CollectAndCount(store.rows, "parcel_store_rows_returned"))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	names := map[string]bool{}
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	require.True(t, names["parcel_store_query_duration_seconds"])
+	require.True(t, names["go_sql_open_connections"])
+
+	// duplicate registration
+	_, err = NewMetricsStorage(NewParcelStore(db), reg, nil)
+	require.Error(t, err)
+}
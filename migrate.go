@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationsFS содержит SQL-миграции схемы, по каталогу на диалект.
+// Имя файла — NNNN_описание.sql, где NNNN — номер версии.
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// migration — одна версия схемы.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate создаёт и обновляет схему БД до последней версии.
+// Применённые версии запоминаются в таблице schema_migrations,
+// каждая миграция выполняется в отдельной транзакции.
+func (s ParcelStore) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations(s.dialect.name)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.q.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied_at VARCHAR(64) NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		err := s.withTx(ctx, func(tx ParcelStore) error {
+			for _, stmt := range splitStatements(m.sql) {
+				if _, err := tx.q.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+
+			_, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+				"INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"),
+				m.version, formatTime(time.Now()))
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion возвращает номер последней применённой миграции.
+func (s ParcelStore) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.q.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// loadMigrations читает миграции диалекта, упорядоченные по версии.
+func loadMigrations(dialect string) ([]migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s: %w", dialect, err)
+	}
+
+	var res []migration
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".sql")
+		if !ok || e.IsDir() {
+			continue
+		}
+
+		num, title, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(num)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration name %q", e.Name())
+		}
+
+		data, err := migrationsFS.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, migration{version: version, name: title, sql: string(data)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].version < res[j].version })
+
+	return res, nil
+}
+
+// splitStatements делит скрипт на отдельные запросы по «;» в конце строки.
+// Тела триггеров между строками, оканчивающимися на BEGIN, и END; не делятся.
+func splitStatements(script string) []string {
+	var res []string
+	var cur strings.Builder
+	inBlock := false
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		cur.WriteString(line)
+		cur.WriteByte('\n')
+
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasSuffix(upper, "BEGIN"):
+			inBlock = true
+		case inBlock && upper == "END;":
+			inBlock = false
+			fallthrough
+		case !inBlock && strings.HasSuffix(trimmed, ";"):
+			stmt := strings.TrimSuffix(strings.TrimSpace(cur.String()), ";")
+			res = append(res, stmt)
+			cur.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		res = append(res, rest)
+	}
+
+	return res
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// openTempDB создаёт временную базу SQLite и применяет к ней миграции
+func openTempDB(tb testing.TB) *sql.DB {
+	db, _ := openTempDBFile(tb)
+	return db
+}
+
+// openTempDBFile — openTempDB, который возвращает и путь к файлу базы,
+// например для --dsn в тестах CLI
+func openTempDBFile(tb testing.TB) (*sql.DB, string) {
+	path := filepath.Join(tb.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { db.Close() })
+
+	err = NewParcelStore(db).Migrate(context.Background())
+	require.NoError(tb, err)
+
+	return db, path
+}
+
+// TestMigrate проверяет создание схемы на пустой базе и повторный запуск миграций
+func TestMigrate(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+	ctx := context.Background()
+	store := NewParcelStore(db)
+
+	migrations, err := loadMigrations("sqlite")
+	require.NoError(t, err)
+
+	// check
+	version, err := store.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, migrations[len(migrations)-1].version, version)
+
+	require.NoError(t, store.Migrate(ctx))
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+}
+
+// TestLoadMigrations проверяет, что у каждого диалекта есть одинаковый набор версий
+func TestLoadMigrations(t *testing.T) {
+	sqlite, err := loadMigrations(sqliteDialect.name)
+	require.NoError(t, err)
+
+	for _, d := range []dialect{postgresDialect, mysqlDialect} {
+		migrations, err := loadMigrations(d.name)
+		require.NoError(t, err)
+		require.Len(t, migrations, len(sqlite), d.name)
+
+		for i := range migrations {
+			require.Equal(t, sqlite[i].version, migrations[i].version, d.name)
+			require.Equal(t, sqlite[i].name, migrations[i].name, d.name)
+		}
+	}
+}
+
+// TestSplitStatements проверяет разбиение скрипта миграции на запросы
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (id INTEGER);
+
+CREATE TRIGGER a_ai AFTER INSERT ON a BEGIN
+    INSERT INTO b VALUES (new.id);
+END;
+CREATE INDEX a_idx ON a (id);
+`
+	stmts := splitStatements(script)
+	require.Len(t, stmts, 3)
+	require.Equal(t, "CREATE TABLE a (id INTEGER)", stmts[0])
+	require.Contains(t, stmts[1], "INSERT INTO b VALUES (new.id);")
+	require.Equal(t, "CREATE INDEX a_idx ON a (id)", stmts[2])
+}
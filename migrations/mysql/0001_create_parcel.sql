@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS parcel
+(
+    number     INT AUTO_INCREMENT PRIMARY KEY,
+    client     INT          NOT NULL,
+    status     VARCHAR(128) NOT NULL,
+    address    VARCHAR(512) NOT NULL,
+    created_at VARCHAR(64)  NOT NULL,
+    INDEX parcel_client_status_idx (client, status),
+    INDEX parcel_created_at_idx (created_at)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS parcel_status_history
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT          NOT NULL,
+    old_status    VARCHAR(128) NOT NULL,
+    new_status    VARCHAR(128) NOT NULL,
+    changed_at    VARCHAR(64)  NOT NULL,
+    INDEX parcel_status_history_number_idx (parcel_number)
+);
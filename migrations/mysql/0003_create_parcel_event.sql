@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS parcel_event
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT          NOT NULL,
+    code          VARCHAR(64)  NOT NULL,
+    description   VARCHAR(512) NOT NULL,
+    occurred_at   VARCHAR(64)  NOT NULL,
+    INDEX parcel_event_number_idx (parcel_number, occurred_at)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS parcel_archive
+(
+    number      INT PRIMARY KEY,
+    client      INT          NOT NULL,
+    status      VARCHAR(128) NOT NULL,
+    address     VARCHAR(512) NOT NULL,
+    created_at  VARCHAR(64)  NOT NULL,
+    archived_at VARCHAR(64)  NOT NULL,
+    INDEX parcel_archive_client_idx (client)
+);
@@ -0,0 +1 @@
+ALTER TABLE parcel ADD COLUMN version INT NOT NULL DEFAULT 1;
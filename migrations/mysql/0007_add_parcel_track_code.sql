@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    ADD COLUMN track_code VARCHAR(16) NULL,
+    ADD UNIQUE INDEX parcel_track_code_idx (track_code);
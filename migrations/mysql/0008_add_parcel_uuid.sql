@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    ADD COLUMN uuid VARCHAR(36) NULL,
+    ADD UNIQUE INDEX parcel_uuid_idx (uuid);
@@ -0,0 +1,12 @@
+-- строки RFC3339 сначала переводятся в формат DATETIME, иначе MODIFY их не примет
+UPDATE parcel
+SET created_at = DATE_FORMAT(STR_TO_DATE(created_at, '%Y-%m-%dT%H:%i:%sZ'), '%Y-%m-%d %H:%i:%s');
+
+ALTER TABLE parcel
+    MODIFY created_at DATETIME NOT NULL;
+
+UPDATE parcel_archive
+SET created_at = DATE_FORMAT(STR_TO_DATE(created_at, '%Y-%m-%dT%H:%i:%sZ'), '%Y-%m-%d %H:%i:%s');
+
+ALTER TABLE parcel_archive
+    MODIFY created_at DATETIME NOT NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE parcel ADD COLUMN updated_at DATETIME NULL;
+
+UPDATE parcel SET updated_at = created_at;
+
+ALTER TABLE parcel
+    MODIFY updated_at DATETIME NOT NULL,
+    ADD INDEX parcel_updated_at_idx (updated_at);
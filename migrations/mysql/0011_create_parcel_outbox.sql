@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS parcel_outbox
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT         NOT NULL,
+    type          VARCHAR(64) NOT NULL,
+    payload       TEXT        NOT NULL,
+    created_at    DATETIME    NOT NULL,
+    sent_at       DATETIME    NULL,
+    attempts      INT         NOT NULL DEFAULT 0,
+    last_error    VARCHAR(1024) NOT NULL DEFAULT '',
+    INDEX parcel_outbox_sent_at_idx (sent_at, id)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS clients
+(
+    id    INT AUTO_INCREMENT PRIMARY KEY,
+    name  VARCHAR(256) NOT NULL,
+    email VARCHAR(256) NOT NULL DEFAULT '',
+    phone VARCHAR(32)  NOT NULL DEFAULT ''
+);
+
+-- у уже зарегистрированных посылок клиенты появляются без имени
+INSERT INTO clients (id, name)
+SELECT DISTINCT client, '' FROM parcel WHERE client NOT IN (SELECT id FROM clients);
+
+ALTER TABLE parcel ADD CONSTRAINT parcel_client_fk FOREIGN KEY (client) REFERENCES clients (id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS couriers
+(
+    id    INT AUTO_INCREMENT PRIMARY KEY,
+    name  VARCHAR(256) NOT NULL,
+    phone VARCHAR(32)  NOT NULL DEFAULT ''
+);
+
+ALTER TABLE parcel
+    ADD COLUMN courier_id INT NULL,
+    ADD INDEX parcel_courier_idx (courier_id),
+    ADD CONSTRAINT parcel_courier_fk FOREIGN KEY (courier_id) REFERENCES couriers (id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS locations
+(
+    id   INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(32)  NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    kind VARCHAR(32)  NOT NULL,
+    UNIQUE INDEX locations_code_idx (code)
+);
+
+CREATE TABLE IF NOT EXISTS parcel_checkpoint
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT      NOT NULL,
+    location_id   INT      NOT NULL,
+    arrived_at    DATETIME NOT NULL,
+    INDEX parcel_checkpoint_number_idx (parcel_number, arrived_at),
+    CONSTRAINT parcel_checkpoint_location_fk FOREIGN KEY (location_id) REFERENCES locations (id)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE parcel
+    ADD COLUMN lat DOUBLE NULL,
+    ADD COLUMN lon DOUBLE NULL,
+    ADD INDEX parcel_lat_lon_idx (lat, lon);
@@ -0,0 +1,5 @@
+ALTER TABLE parcel
+    ADD COLUMN weight_grams INT NOT NULL DEFAULT 0,
+    ADD COLUMN length_mm    INT NOT NULL DEFAULT 0,
+    ADD COLUMN width_mm     INT NOT NULL DEFAULT 0,
+    ADD COLUMN height_mm    INT NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS price_rates
+(
+    id               INT AUTO_INCREMENT PRIMARY KEY,
+    zone             VARCHAR(64) NOT NULL,
+    max_weight_grams INT         NOT NULL,
+    price            BIGINT      NOT NULL,
+    UNIQUE INDEX price_rates_zone_weight_idx (zone, max_weight_grams)
+);
+
+ALTER TABLE parcel ADD COLUMN price BIGINT NOT NULL DEFAULT 0;
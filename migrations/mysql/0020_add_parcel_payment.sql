@@ -0,0 +1,9 @@
+ALTER TABLE parcel ADD COLUMN amount BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE parcel ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE parcel ADD COLUMN payment_status VARCHAR(16) NOT NULL DEFAULT 'unpaid';
+
+ALTER TABLE parcel ADD COLUMN paid_at DATETIME NULL;
+
+ALTER TABLE parcel ADD COLUMN payment_ref VARCHAR(128) NOT NULL DEFAULT '';
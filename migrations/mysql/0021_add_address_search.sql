@@ -0,0 +1 @@
+ALTER TABLE parcel ADD FULLTEXT INDEX parcel_address_ft (sender_address, recipient_address);
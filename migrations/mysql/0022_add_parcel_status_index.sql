@@ -0,0 +1 @@
+ALTER TABLE parcel ADD INDEX parcel_status_idx (status);
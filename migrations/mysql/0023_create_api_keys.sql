@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(128) NOT NULL,
+    key_hash   VARCHAR(64)  NOT NULL,
+    scopes     VARCHAR(64)  NOT NULL,
+    created_at DATETIME     NOT NULL,
+    revoked_at DATETIME     NULL,
+    UNIQUE INDEX api_keys_key_hash_idx (key_hash)
+);
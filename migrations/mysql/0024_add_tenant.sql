@@ -0,0 +1,7 @@
+ALTER TABLE parcel
+    ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD INDEX parcel_tenant_client_idx (tenant_id, client);
+
+ALTER TABLE parcel_archive ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE api_keys ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '';
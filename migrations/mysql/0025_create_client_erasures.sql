@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS client_erasures
+(
+    id        INT AUTO_INCREMENT PRIMARY KEY,
+    client    INT      NOT NULL,
+    parcels   INT      NOT NULL,
+    events    INT      NOT NULL,
+    erased_at DATETIME NOT NULL,
+    INDEX client_erasures_client_idx (client)
+);
@@ -0,0 +1,8 @@
+-- зашифрованный адрес из 512 символов занимает до 1.5 тысяч символов base64
+ALTER TABLE parcel
+    MODIFY sender_address VARCHAR(2048) NOT NULL DEFAULT '',
+    MODIFY recipient_address VARCHAR(2048) NOT NULL;
+
+ALTER TABLE parcel_archive
+    MODIFY sender_address VARCHAR(2048) NOT NULL DEFAULT '',
+    MODIFY recipient_address VARCHAR(2048) NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    ADD COLUMN idempotency_key VARCHAR(64),
+    ADD UNIQUE INDEX parcel_idempotency_key_idx (tenant_id, idempotency_key);
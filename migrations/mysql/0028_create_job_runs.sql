@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS job_runs
+(
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    job         VARCHAR(64) NOT NULL,
+    started_at  DATETIME    NOT NULL,
+    finished_at DATETIME    NOT NULL,
+    parcels     INT         NOT NULL,
+    error       TEXT        NOT NULL,
+    INDEX job_runs_job_idx (job)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE parcel
+    ADD COLUMN service_class VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN sla_deadline DATETIME NULL,
+    ADD COLUMN sla_breached_at DATETIME NULL,
+    ADD INDEX parcel_sla_deadline_idx (sla_deadline),
+    ADD INDEX parcel_sla_breached_at_idx (sla_breached_at);
@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    ADD COLUMN return_of INT NULL,
+    ADD INDEX parcel_return_of_idx (return_of);
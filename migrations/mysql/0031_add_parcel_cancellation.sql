@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    ADD COLUMN cancel_reason VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN cancelled_at DATETIME NULL;
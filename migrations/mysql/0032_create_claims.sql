@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS claims
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT          NOT NULL,
+    type          VARCHAR(16)  NOT NULL,
+    description   VARCHAR(512) NOT NULL,
+    photos        TEXT         NOT NULL,
+    resolution    VARCHAR(512) NOT NULL DEFAULT '',
+    opened_at     DATETIME     NOT NULL,
+    resolved_at   DATETIME     NULL,
+    INDEX claims_parcel_number_idx (parcel_number, resolved_at)
+);
@@ -0,0 +1 @@
+ALTER TABLE parcel ADD INDEX parcel_service_class_idx (service_class);
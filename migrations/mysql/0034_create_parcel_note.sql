@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS parcel_note
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT           NOT NULL,
+    author        VARCHAR(128)  NOT NULL,
+    text          VARCHAR(1024) NOT NULL,
+    created_at    DATETIME      NOT NULL,
+    INDEX parcel_note_number_idx (parcel_number, id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS parcel_attachment
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT          NOT NULL,
+    kind          VARCHAR(16)  NOT NULL,
+    content_type  VARCHAR(128) NOT NULL,
+    size          BIGINT       NOT NULL,
+    sha256        CHAR(64)     NOT NULL,
+    blob_key      VARCHAR(255) NOT NULL,
+    created_at    DATETIME     NOT NULL,
+    INDEX parcel_attachment_number_idx (parcel_number, id)
+);
@@ -0,0 +1,9 @@
+ALTER TABLE parcel
+    ADD COLUMN signed_by VARCHAR(128) NOT NULL DEFAULT '',
+    ADD COLUMN signed_at DATETIME NULL;
+
+CREATE TABLE IF NOT EXISTS parcel_signature
+(
+    parcel_number INT PRIMARY KEY,
+    image         MEDIUMBLOB NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS delivery_attempt
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    parcel_number INT          NOT NULL,
+    outcome       VARCHAR(32)  NOT NULL,
+    note          VARCHAR(512) NOT NULL DEFAULT '',
+    attempted_at  DATETIME     NOT NULL,
+    INDEX delivery_attempt_number_idx (parcel_number, id)
+);
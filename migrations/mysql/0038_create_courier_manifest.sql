@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS courier_manifest
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    courier_id    INT         NOT NULL,
+    manifest_date VARCHAR(10) NOT NULL,
+    sort_order    VARCHAR(16) NOT NULL,
+    created_at    DATETIME    NOT NULL,
+    UNIQUE INDEX courier_manifest_day_idx (courier_id, manifest_date),
+    FOREIGN KEY (courier_id) REFERENCES couriers (id)
+);
+
+CREATE TABLE IF NOT EXISTS courier_manifest_stop
+(
+    id                INT AUTO_INCREMENT PRIMARY KEY,
+    manifest_id       INT           NOT NULL,
+    seq               INT           NOT NULL,
+    parcel_number     INT           NOT NULL,
+    track_code        VARCHAR(16)   NOT NULL DEFAULT '',
+    recipient_address VARCHAR(2048) NOT NULL,
+    lat               DOUBLE NULL,
+    lon               DOUBLE NULL,
+    UNIQUE INDEX courier_manifest_stop_seq_idx (manifest_id, seq),
+    INDEX courier_manifest_stop_number_idx (parcel_number),
+    FOREIGN KEY (manifest_id) REFERENCES courier_manifest (id)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS parcel_status_history
+(
+    id            SERIAL PRIMARY KEY,
+    parcel_number INTEGER      NOT NULL,
+    old_status    VARCHAR(128) NOT NULL,
+    new_status    VARCHAR(128) NOT NULL,
+    changed_at    TEXT         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_status_history_number_idx ON parcel_status_history (parcel_number);
@@ -0,0 +1 @@
+ALTER TABLE parcel ADD COLUMN deleted_at TEXT;
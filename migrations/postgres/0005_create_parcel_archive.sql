@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS parcel_archive
+(
+    number      INTEGER PRIMARY KEY,
+    client      INTEGER      NOT NULL,
+    status      VARCHAR(128) NOT NULL,
+    address     VARCHAR(512) NOT NULL,
+    created_at  TEXT         NOT NULL,
+    archived_at TEXT         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_archive_client_idx ON parcel_archive (client);
@@ -0,0 +1,3 @@
+ALTER TABLE parcel ADD COLUMN track_code VARCHAR(16);
+
+CREATE UNIQUE INDEX IF NOT EXISTS parcel_track_code_idx ON parcel (track_code);
@@ -0,0 +1,5 @@
+ALTER TABLE parcel
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at::TIMESTAMPTZ;
+
+ALTER TABLE parcel_archive
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at::TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+ALTER TABLE parcel ADD COLUMN updated_at TIMESTAMPTZ;
+
+UPDATE parcel SET updated_at = created_at;
+
+ALTER TABLE parcel ALTER COLUMN updated_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS parcel_updated_at_idx ON parcel (updated_at);
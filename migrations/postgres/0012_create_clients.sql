@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS clients
+(
+    id    SERIAL PRIMARY KEY,
+    name  VARCHAR(256) NOT NULL,
+    email VARCHAR(256) NOT NULL DEFAULT '',
+    phone VARCHAR(32)  NOT NULL DEFAULT ''
+);
+
+-- у уже зарегистрированных посылок клиенты появляются без имени
+INSERT INTO clients (id, name)
+SELECT DISTINCT client, '' FROM parcel WHERE client NOT IN (SELECT id FROM clients);
+
+SELECT setval(pg_get_serial_sequence('clients', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM clients;
+
+ALTER TABLE parcel ADD CONSTRAINT parcel_client_fk FOREIGN KEY (client) REFERENCES clients (id);
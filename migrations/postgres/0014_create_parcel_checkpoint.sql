@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS locations
+(
+    id   SERIAL PRIMARY KEY,
+    code VARCHAR(32)  NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    kind VARCHAR(32)  NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS locations_code_idx ON locations (code);
+
+CREATE TABLE IF NOT EXISTS parcel_checkpoint
+(
+    id            SERIAL PRIMARY KEY,
+    parcel_number INTEGER     NOT NULL,
+    location_id   INTEGER     NOT NULL
+        CONSTRAINT parcel_checkpoint_location_fk REFERENCES locations (id),
+    arrived_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_checkpoint_number_idx ON parcel_checkpoint (parcel_number, arrived_at);
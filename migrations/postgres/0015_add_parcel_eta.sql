@@ -0,0 +1 @@
+ALTER TABLE parcel ADD COLUMN eta TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+ALTER TABLE parcel ADD COLUMN lat DOUBLE PRECISION;
+
+ALTER TABLE parcel ADD COLUMN lon DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS parcel_lat_lon_idx ON parcel (lat, lon);
@@ -0,0 +1,7 @@
+ALTER TABLE parcel ADD COLUMN weight_grams INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE parcel ADD COLUMN length_mm INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE parcel ADD COLUMN width_mm INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE parcel ADD COLUMN height_mm INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS price_rates
+(
+    id               SERIAL PRIMARY KEY,
+    zone             VARCHAR(64) NOT NULL,
+    max_weight_grams INTEGER     NOT NULL,
+    price            BIGINT      NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS price_rates_zone_weight_idx ON price_rates (zone, max_weight_grams);
+
+ALTER TABLE parcel ADD COLUMN price BIGINT NOT NULL DEFAULT 0;
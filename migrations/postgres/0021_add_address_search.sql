@@ -0,0 +1,6 @@
+-- триграммные индексы ускоряют поиск ILIKE '%...%' по части адреса
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS parcel_sender_address_trgm_idx ON parcel USING gin (sender_address gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS parcel_recipient_address_trgm_idx ON parcel USING gin (recipient_address gin_trgm_ops);
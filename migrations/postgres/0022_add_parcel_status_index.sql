@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS parcel_status_idx ON parcel (status);
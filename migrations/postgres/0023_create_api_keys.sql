@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(128) NOT NULL,
+    key_hash   VARCHAR(64)  NOT NULL,
+    scopes     VARCHAR(64)  NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_key_hash_idx ON api_keys (key_hash);
@@ -0,0 +1,8 @@
+-- зашифрованный адрес из 512 символов занимает до 1.5 тысяч символов base64
+ALTER TABLE parcel
+    ALTER COLUMN sender_address TYPE VARCHAR(2048),
+    ALTER COLUMN recipient_address TYPE VARCHAR(2048);
+
+ALTER TABLE parcel_archive
+    ALTER COLUMN sender_address TYPE VARCHAR(2048),
+    ALTER COLUMN recipient_address TYPE VARCHAR(2048);
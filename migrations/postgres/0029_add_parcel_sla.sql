@@ -0,0 +1,9 @@
+ALTER TABLE parcel ADD COLUMN service_class VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE parcel ADD COLUMN sla_deadline TIMESTAMPTZ;
+
+ALTER TABLE parcel ADD COLUMN sla_breached_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS parcel_sla_deadline_idx ON parcel (sla_deadline);
+
+CREATE INDEX IF NOT EXISTS parcel_sla_breached_at_idx ON parcel (sla_breached_at);
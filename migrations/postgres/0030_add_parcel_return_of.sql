@@ -0,0 +1,3 @@
+ALTER TABLE parcel ADD COLUMN return_of INTEGER;
+
+CREATE INDEX IF NOT EXISTS parcel_return_of_idx ON parcel (return_of);
@@ -0,0 +1,3 @@
+ALTER TABLE parcel ADD COLUMN cancel_reason VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE parcel ADD COLUMN cancelled_at TIMESTAMPTZ;
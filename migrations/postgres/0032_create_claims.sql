@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS claims
+(
+    id            SERIAL PRIMARY KEY,
+    parcel_number INTEGER      NOT NULL,
+    type          VARCHAR(16)  NOT NULL,
+    description   VARCHAR(512) NOT NULL,
+    photos        TEXT         NOT NULL,
+    resolution    VARCHAR(512) NOT NULL DEFAULT '',
+    opened_at     TIMESTAMPTZ  NOT NULL,
+    resolved_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS claims_parcel_number_idx ON claims (parcel_number, resolved_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS delivery_attempt
+(
+    id            SERIAL PRIMARY KEY,
+    parcel_number INTEGER      NOT NULL,
+    outcome       VARCHAR(32)  NOT NULL,
+    note          VARCHAR(512) NOT NULL DEFAULT '',
+    attempted_at  TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS delivery_attempt_number_idx ON delivery_attempt (parcel_number, id);
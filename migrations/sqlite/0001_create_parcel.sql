@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS parcel
+(
+    number     INTEGER
+        CONSTRAINT parcel_pk
+            PRIMARY KEY AUTOINCREMENT,
+    client     INTEGER      NOT NULL,
+    status     VARCHAR(128) NOT NULL,
+    address    VARCHAR(512) NOT NULL,
+    created_at TEXT         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_client_status_idx ON parcel (client, status);
+CREATE INDEX IF NOT EXISTS parcel_created_at_idx ON parcel (created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS parcel_event
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    parcel_number INTEGER      NOT NULL,
+    code          VARCHAR(64)  NOT NULL,
+    description   VARCHAR(512) NOT NULL,
+    occurred_at   TEXT         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_event_number_idx ON parcel_event (parcel_number, occurred_at);
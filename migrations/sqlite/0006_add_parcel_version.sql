@@ -0,0 +1 @@
+ALTER TABLE parcel ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+ALTER TABLE parcel ADD COLUMN uuid VARCHAR(36);
+
+CREATE UNIQUE INDEX IF NOT EXISTS parcel_uuid_idx ON parcel (uuid);
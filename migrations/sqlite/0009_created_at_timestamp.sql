@@ -0,0 +1,5 @@
+-- SQLite не имеет типа даты: created_at остаётся строкой RFC3339,
+-- но теперь его задаёт хранилище, поэтому старые значения приводятся к UTC.
+UPDATE parcel
+SET created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at)
+WHERE strftime('%Y-%m-%dT%H:%M:%SZ', created_at) IS NOT NULL;
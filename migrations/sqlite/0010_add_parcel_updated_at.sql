@@ -0,0 +1,5 @@
+ALTER TABLE parcel ADD COLUMN updated_at TEXT;
+
+UPDATE parcel SET updated_at = created_at;
+
+CREATE INDEX IF NOT EXISTS parcel_updated_at_idx ON parcel (updated_at);
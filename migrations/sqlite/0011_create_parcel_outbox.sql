@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS parcel_outbox
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    parcel_number INTEGER     NOT NULL,
+    type          VARCHAR(64) NOT NULL,
+    payload       TEXT        NOT NULL,
+    created_at    TEXT        NOT NULL,
+    sent_at       TEXT,
+    attempts      INTEGER     NOT NULL DEFAULT 0,
+    last_error    VARCHAR(1024) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS parcel_outbox_sent_at_idx ON parcel_outbox (sent_at, id);
@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS clients
+(
+    id    INTEGER
+        CONSTRAINT clients_pk
+            PRIMARY KEY AUTOINCREMENT,
+    name  VARCHAR(256) NOT NULL,
+    email VARCHAR(256) NOT NULL DEFAULT '',
+    phone VARCHAR(32)  NOT NULL DEFAULT ''
+);
+
+-- у уже зарегистрированных посылок клиенты появляются без имени
+INSERT INTO clients (id, name)
+SELECT DISTINCT client, '' FROM parcel WHERE client NOT IN (SELECT id FROM clients);
+
+-- SQLite не умеет добавлять внешний ключ к таблице, поэтому parcel пересоздаётся
+CREATE TABLE parcel_new
+(
+    number     INTEGER
+        CONSTRAINT parcel_pk
+            PRIMARY KEY AUTOINCREMENT,
+    client     INTEGER      NOT NULL
+        CONSTRAINT parcel_client_fk
+            REFERENCES clients (id),
+    status     VARCHAR(128) NOT NULL,
+    address    VARCHAR(512) NOT NULL,
+    created_at TEXT         NOT NULL,
+    deleted_at TEXT,
+    version    INTEGER      NOT NULL DEFAULT 1,
+    track_code VARCHAR(16),
+    uuid       VARCHAR(36),
+    updated_at TEXT
+);
+
+INSERT INTO parcel_new (number, client, status, address, created_at, deleted_at, version, track_code, uuid, updated_at)
+SELECT number, client, status, address, created_at, deleted_at, version, track_code, uuid, updated_at FROM parcel;
+
+-- номера удалённых посылок не должны выдаваться повторно
+DELETE FROM sqlite_sequence WHERE name = 'parcel_new';
+
+INSERT INTO sqlite_sequence (name, seq)
+SELECT 'parcel_new', seq FROM sqlite_sequence WHERE name = 'parcel';
+
+DROP TABLE parcel;
+
+ALTER TABLE parcel_new RENAME TO parcel;
+
+CREATE INDEX IF NOT EXISTS parcel_client_status_idx ON parcel (client, status);
+CREATE INDEX IF NOT EXISTS parcel_created_at_idx ON parcel (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS parcel_track_code_idx ON parcel (track_code);
+CREATE UNIQUE INDEX IF NOT EXISTS parcel_uuid_idx ON parcel (uuid);
+CREATE INDEX IF NOT EXISTS parcel_updated_at_idx ON parcel (updated_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS couriers
+(
+    id    INTEGER
+        CONSTRAINT couriers_pk
+            PRIMARY KEY AUTOINCREMENT,
+    name  VARCHAR(256) NOT NULL,
+    phone VARCHAR(32)  NOT NULL DEFAULT ''
+);
+
+ALTER TABLE parcel ADD COLUMN courier_id INTEGER
+    CONSTRAINT parcel_courier_fk
+        REFERENCES couriers (id);
+
+CREATE INDEX IF NOT EXISTS parcel_courier_idx ON parcel (courier_id);
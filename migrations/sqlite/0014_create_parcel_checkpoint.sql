@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS locations
+(
+    id   INTEGER
+        CONSTRAINT locations_pk
+            PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(32)  NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    kind VARCHAR(32)  NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS locations_code_idx ON locations (code);
+
+CREATE TABLE IF NOT EXISTS parcel_checkpoint
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    parcel_number INTEGER NOT NULL,
+    location_id   INTEGER NOT NULL
+        CONSTRAINT parcel_checkpoint_location_fk
+            REFERENCES locations (id),
+    arrived_at    TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_checkpoint_number_idx ON parcel_checkpoint (parcel_number, arrived_at);
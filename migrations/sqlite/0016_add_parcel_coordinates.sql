@@ -0,0 +1,5 @@
+ALTER TABLE parcel ADD COLUMN lat REAL;
+
+ALTER TABLE parcel ADD COLUMN lon REAL;
+
+CREATE INDEX IF NOT EXISTS parcel_lat_lon_idx ON parcel (lat, lon);
@@ -0,0 +1,7 @@
+ALTER TABLE parcel RENAME COLUMN address TO recipient_address;
+
+ALTER TABLE parcel ADD COLUMN sender_address VARCHAR(512) NOT NULL DEFAULT '';
+
+ALTER TABLE parcel_archive RENAME COLUMN address TO recipient_address;
+
+ALTER TABLE parcel_archive ADD COLUMN sender_address VARCHAR(512) NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS price_rates
+(
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    zone             VARCHAR(64) NOT NULL,
+    max_weight_grams INTEGER     NOT NULL,
+    price            INTEGER     NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS price_rates_zone_weight_idx ON price_rates (zone, max_weight_grams);
+
+ALTER TABLE parcel ADD COLUMN price INTEGER NOT NULL DEFAULT 0;
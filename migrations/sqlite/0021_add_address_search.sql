@@ -0,0 +1,28 @@
+-- полнотекстовый индекс адресов поверх таблицы parcel, его синхронизируют триггеры
+CREATE VIRTUAL TABLE IF NOT EXISTS parcel_address_fts USING fts5
+(
+    sender_address,
+    recipient_address,
+    content = 'parcel',
+    content_rowid = 'number',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS parcel_address_fts_insert AFTER INSERT ON parcel BEGIN
+    INSERT INTO parcel_address_fts (rowid, sender_address, recipient_address)
+    VALUES (new.number, new.sender_address, new.recipient_address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS parcel_address_fts_delete AFTER DELETE ON parcel BEGIN
+    INSERT INTO parcel_address_fts (parcel_address_fts, rowid, sender_address, recipient_address)
+    VALUES ('delete', old.number, old.sender_address, old.recipient_address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS parcel_address_fts_update AFTER UPDATE OF sender_address, recipient_address ON parcel BEGIN
+    INSERT INTO parcel_address_fts (parcel_address_fts, rowid, sender_address, recipient_address)
+    VALUES ('delete', old.number, old.sender_address, old.recipient_address);
+    INSERT INTO parcel_address_fts (rowid, sender_address, recipient_address)
+    VALUES (new.number, new.sender_address, new.recipient_address);
+END;
+
+INSERT INTO parcel_address_fts (parcel_address_fts) VALUES ('rebuild');
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(128) NOT NULL,
+    key_hash   VARCHAR(64)  NOT NULL,
+    scopes     VARCHAR(64)  NOT NULL,
+    created_at TEXT         NOT NULL,
+    revoked_at TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_key_hash_idx ON api_keys (key_hash);
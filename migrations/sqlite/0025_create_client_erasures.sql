@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS client_erasures
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    client    INTEGER NOT NULL,
+    parcels   INTEGER NOT NULL,
+    events    INTEGER NOT NULL,
+    erased_at TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS client_erasures_client_idx ON client_erasures (client);
@@ -0,0 +1,4 @@
+-- SQLite не ограничивает длину VARCHAR, поэтому зашифрованные адреса
+-- помещаются в колонки без изменений; миграция держит номера версий
+-- одинаковыми во всех диалектах.
+SELECT 1;
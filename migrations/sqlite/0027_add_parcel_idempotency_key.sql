@@ -0,0 +1,3 @@
+ALTER TABLE parcel ADD COLUMN idempotency_key VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS parcel_idempotency_key_idx ON parcel (tenant_id, idempotency_key);
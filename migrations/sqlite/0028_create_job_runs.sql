@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS job_runs
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    job         VARCHAR(64) NOT NULL,
+    started_at  TEXT        NOT NULL,
+    finished_at TEXT        NOT NULL,
+    parcels     INTEGER     NOT NULL,
+    error       TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_idx ON job_runs (job);
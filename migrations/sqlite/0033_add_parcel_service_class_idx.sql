@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS parcel_service_class_idx ON parcel (service_class);
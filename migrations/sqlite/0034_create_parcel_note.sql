@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS parcel_note
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    parcel_number INTEGER       NOT NULL,
+    author        VARCHAR(128)  NOT NULL,
+    text          VARCHAR(1024) NOT NULL,
+    created_at    TEXT          NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_note_number_idx ON parcel_note (parcel_number, id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS parcel_attachment
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    parcel_number INTEGER      NOT NULL,
+    kind          VARCHAR(16)  NOT NULL,
+    content_type  VARCHAR(128) NOT NULL,
+    size          INTEGER      NOT NULL,
+    sha256        CHAR(64)     NOT NULL,
+    blob_key      VARCHAR(255) NOT NULL,
+    created_at    TEXT         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS parcel_attachment_number_idx ON parcel_attachment (parcel_number, id);
@@ -0,0 +1,9 @@
+ALTER TABLE parcel ADD COLUMN signed_by VARCHAR(128) NOT NULL DEFAULT '';
+
+ALTER TABLE parcel ADD COLUMN signed_at TEXT;
+
+CREATE TABLE IF NOT EXISTS parcel_signature
+(
+    parcel_number INTEGER PRIMARY KEY,
+    image         BLOB NOT NULL
+);
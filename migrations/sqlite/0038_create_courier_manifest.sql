@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS courier_manifest
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    courier_id    INTEGER     NOT NULL REFERENCES couriers (id),
+    manifest_date VARCHAR(10) NOT NULL,
+    sort_order    VARCHAR(16) NOT NULL,
+    created_at    TEXT        NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS courier_manifest_day_idx ON courier_manifest (courier_id, manifest_date);
+
+CREATE TABLE IF NOT EXISTS courier_manifest_stop
+(
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    manifest_id       INTEGER       NOT NULL REFERENCES courier_manifest (id),
+    seq               INTEGER       NOT NULL,
+    parcel_number     INTEGER       NOT NULL,
+    track_code        VARCHAR(16)   NOT NULL DEFAULT '',
+    recipient_address VARCHAR(2048) NOT NULL,
+    lat               REAL,
+    lon               REAL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS courier_manifest_stop_seq_idx ON courier_manifest_stop (manifest_id, seq);
+CREATE INDEX IF NOT EXISTS courier_manifest_stop_number_idx ON courier_manifest_stop (parcel_number);
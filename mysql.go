@@ -0,0 +1,28 @@
+package main
+
+import (
+	"database/sql"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// MySQLParcelStore хранит посылки в MySQL или MariaDB.
+// Плейсхолдеры "?" совпадают с SQLite, номер новой посылки
+// берётся из AUTO_INCREMENT через LastInsertId.
+//
+// Схема создаётся методом Migrate из каталога migrations/mysql.
+type MySQLParcelStore struct {
+	ParcelStore
+}
+
+var _ ParcelStorage = MySQLParcelStore{}
+
+func init() {
+	RegisterStorage("mysql", func(db *sql.DB) ParcelStorage {
+		return NewMySQLParcelStore(db)
+	})
+}
+
+func NewMySQLParcelStore(q Querier) MySQLParcelStore {
+	return MySQLParcelStore{newSQLParcelStore(q, mysqlDialect)}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// openMySQL подключается к MySQL/MariaDB из переменной окружения MYSQL_DSN
+// и применяет миграции схемы. Без MYSQL_DSN тест пропускается.
+func openMySQL(t *testing.T) *sql.DB {
+	dsn := os.Getenv("MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_DSN is not set")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	err = NewMySQLParcelStore(db).Migrate(context.Background())
+	require.NoError(t, err)
+
+	return db
+}
+
+// TestMySQLAddGetDelete проверяет добавление, получение и удаление посылки в MySQL
+func TestMySQLAddGetDelete(t *testing.T) {
+	// prepare
+	db := openMySQL(t)
+	ctx := context.Background()
+	store := NewMySQLParcelStore(db)
+	parcel := getTestParcel()
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	parcel.Number = id
+	parcel.Version = 1
+
+	// get
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel, stored)
+
+	// delete
+	err = store.Delete(ctx, id)
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestMySQLSetAddressAndStatus проверяет правило смены адреса только у зарегистрированных посылок
+func TestMySQLSetAddressAndStatus(t *testing.T) {
+	// prepare
+	db := openMySQL(t)
+	ctx := context.Background()
+	store := NewMySQLParcelStore(db)
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// set address
+	err = store.SetRecipientAddress(ctx, id, "new test address")
+	require.NoError(t, err)
+
+	// set status
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.NoError(t, err)
+
+	// адрес отправленной посылки меняться не должен
+	err = store.SetRecipientAddress(ctx, id, "another address")
+	require.ErrorIs(t, err, ErrAddressChangeNotAllowed)
+
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "new test address", stored.RecipientAddress)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+}
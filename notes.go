@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Длины колонок author и text таблицы parcel_note.
+const (
+	maxNoteAuthorLen = 128
+	maxNoteTextLen   = 1024
+)
+
+// ErrInvalidNote возвращается для заметки без автора или текста
+// и для слишком длинных заметок.
+var ErrInvalidNote = errors.New("invalid note")
+
+// Note — заметка сотрудника поддержки к посылке, например «получатель
+// просил оставить у двери». Заметки не зависят от статуса посылки.
+type Note struct {
+	ID        int
+	Number    int
+	Author    string
+	Text      string
+	CreatedAt time.Time
+}
+
+func validateNote(author, text string) error {
+	switch {
+	case strings.TrimSpace(author) == "" || utf8.RuneCountInString(author) > maxNoteAuthorLen:
+		return fmt.Errorf("%w: author must be 1 to %d characters", ErrInvalidNote, maxNoteAuthorLen)
+	case strings.TrimSpace(text) == "" || utf8.RuneCountInString(text) > maxNoteTextLen:
+		return fmt.Errorf("%w: text must be 1 to %d characters", ErrInvalidNote, maxNoteTextLen)
+	}
+	return nil
+}
+
+// NoteStore хранит заметки к посылкам в таблице parcel_note.
+type NoteStore interface {
+	// AddNote добавляет заметку author к посылке number
+	AddNote(ctx context.Context, number int, author, text string) (Note, error)
+	// GetNotes возвращает заметки посылки number по порядку добавления
+	GetNotes(ctx context.Context, number int) ([]Note, error)
+}
+
+var (
+	_ NoteStore = ParcelStore{}
+	_ NoteStore = (*MemoryParcelStore)(nil)
+)
+
+func (s ParcelStore) AddNote(ctx context.Context, number int, author, text string) (Note, error) {
+	if err := validateNote(author, text); err != nil {
+		return Note{}, err
+	}
+
+	n := Note{Number: number, Author: author, Text: text, CreatedAt: timestamp(s.now)}
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		if _, err := tx.status(ctx, number); err != nil {
+			return err
+		}
+		id, err := tx.insert(ctx,
+			"INSERT INTO parcel_note (parcel_number, author, text, created_at) VALUES (?, ?, ?, ?)", "id",
+			number, author, text, tx.dialect.timeArg(n.CreatedAt))
+		n.ID = id
+		return err
+	})
+	if err != nil {
+		return Note{}, err
+	}
+	return n, nil
+}
+
+func (s ParcelStore) GetNotes(ctx context.Context, number int) ([]Note, error) {
+	if _, err := s.status(ctx, number); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT id, parcel_number, author, text, created_at FROM parcel_note WHERE parcel_number = ? ORDER BY id"),
+		number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Note
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.Number, &n.Author, &n.Text, scanTime{&n.CreatedAt}); err != nil {
+			return nil, err
+		}
+		res = append(res, n)
+	}
+	return res, rows.Err()
+}
+
+func (s *MemoryParcelStore) AddNote(ctx context.Context, number int, author, text string) (Note, error) {
+	if err := ctx.Err(); err != nil {
+		return Note{}, err
+	}
+	if err := validateNote(author, text); err != nil {
+		return Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return Note{}, ErrParcelNotFound
+	}
+	s.lastNote++
+	n := Note{ID: s.lastNote, Number: number, Author: author, Text: text, CreatedAt: timestamp(s.now)}
+	s.notes[number] = append(s.notes[number], n)
+
+	return n, nil
+}
+
+func (s *MemoryParcelStore) GetNotes(ctx context.Context, number int) ([]Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parcel(ctx, number); !ok {
+		return nil, ErrParcelNotFound
+	}
+	return append([]Note(nil), s.notes[number]...), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// noteStorage — хранилище посылок с заметками.
+type noteStorage interface {
+	ParcelStorage
+	NoteStore
+}
+
+// checkNotes проверяет добавление заметок и их сохранность при смене
+// статуса посылки на хранилище store
+func checkNotes(t *testing.T, store noteStorage) {
+	// prepare
+	ctx := context.Background()
+	number, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	_, err = store.AddNote(ctx, number, "", "текст")
+	require.ErrorIs(t, err, ErrInvalidNote)
+	_, err = store.AddNote(ctx, number, "Анна", " ")
+	require.ErrorIs(t, err, ErrInvalidNote)
+	_, err = store.AddNote(ctx, number, "Анна", strings.Repeat("я", maxNoteTextLen+1))
+	require.ErrorIs(t, err, ErrInvalidNote)
+	_, err = store.AddNote(ctx, number+1000, "Анна", "текст")
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// add
+	first, err := store.AddNote(ctx, number, "Анна", "получатель просил оставить у двери")
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, number, ParcelStatusSent))
+	second, err := store.AddNote(ctx, number, "Борис", "звонить за час")
+	require.NoError(t, err)
+
+	// check
+	notes, err := store.GetNotes(ctx, number)
+	require.NoError(t, err)
+	require.Equal(t, []Note{first, second}, notes)
+	require.Equal(t, "получатель просил оставить у двери", notes[0].Text)
+	require.Equal(t, "Анна", notes[0].Author)
+	require.False(t, notes[0].CreatedAt.IsZero())
+
+	_, err = store.GetNotes(ctx, number+1000)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestNotes проверяет заметки в SQLite и в памяти
+func TestNotes(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkNotes(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkNotes(t, NewMemoryParcelStore())
+	})
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Contact — куда отправлять уведомления клиенту. Пустое поле отключает канал.
+type Contact struct {
+	Email string `yaml:"email"`
+	Phone string `yaml:"phone"`
+}
+
+// Notification — смена статуса посылки, о которой нужно сообщить клиенту.
+type Notification struct {
+	Contact   Contact
+	Parcel    Parcel
+	OldStatus Status
+}
+
+// Text возвращает текст уведомления для клиента.
+func (n Notification) Text() string {
+	switch n.Parcel.Status {
+	case ParcelStatusSent:
+		return fmt.Sprintf("Ваша посылка № %d отправлена по адресу %s", n.Parcel.Number, n.Parcel.RecipientAddress)
+	case ParcelStatusDelivered:
+		return fmt.Sprintf("Ваша посылка № %d доставлена", n.Parcel.Number)
+	}
+	return fmt.Sprintf("Статус вашей посылки № %d: %s", n.Parcel.Number, n.Parcel.Status.DisplayName(LangRU))
+}
+
+// Notifier доставляет уведомление по одному каналу. Если у контакта
+// нет адреса для этого канала, Notify ничего не делает.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notifiers рассылает уведомление по всем каналам.
+type Notifiers []Notifier
+
+func (ns Notifiers) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range ns {
+		errs = append(errs, notifier.Notify(ctx, n))
+	}
+	return errors.Join(errs...)
+}
+
+// SMTPConfig — настройки почтового сервера для EmailNotifier.
+type SMTPConfig struct {
+	Addr     string `yaml:"addr"`
+	From     string `yaml:"from"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// EmailNotifier отправляет уведомления письмом через SMTP.
+type EmailNotifier struct {
+	cfg SMTPConfig
+	// send — smtp.SendMail, в тестах подменяется
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmailNotifier(cfg SMTPConfig) EmailNotifier {
+	return EmailNotifier{cfg: cfg, send: smtp.SendMail}
+}
+
+func (e EmailNotifier) Notify(_ context.Context, n Notification) error {
+	if n.Contact.Email == "" {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		host, _, _ := strings.Cut(e.cfg.Addr, ":")
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, host)
+	}
+
+	msg := "From: " + e.cfg.From + "\r\n" +
+		"To: " + n.Contact.Email + "\r\n" +
+		"Subject: " + fmt.Sprintf("Посылка № %d", n.Parcel.Number) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + n.Text() + "\r\n"
+
+	if err := e.send(e.cfg.Addr, auth, e.cfg.From, []string{n.Contact.Email}, []byte(msg)); err != nil {
+		return fmt.Errorf("send email to %s: %w", n.Contact.Email, err)
+	}
+	return nil
+}
+
+// SMSConfig — настройки SMS-шлюза с API, совместимым с Twilio.
+type SMSConfig struct {
+	// BaseURL по умолчанию https://api.twilio.com
+	BaseURL    string `yaml:"base_url"`
+	AccountSID string `yaml:"account_sid"`
+	AuthToken  string `yaml:"auth_token"`
+	From       string `yaml:"from"`
+}
+
+// SMSNotifier отправляет уведомления SMS через Twilio-совместимый
+// POST /2010-04-01/Accounts/{AccountSID}/Messages.json.
+type SMSNotifier struct {
+	cfg    SMSConfig
+	client *http.Client
+}
+
+func NewSMSNotifier(cfg SMSConfig) SMSNotifier {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.twilio.com"
+	}
+	return SMSNotifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s SMSNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.Contact.Phone == "" {
+		return nil
+	}
+
+	form := url.Values{}
+	form.Set("To", n.Contact.Phone)
+	form.Set("From", s.cfg.From)
+	form.Set("Body", n.Text())
+
+	endpoint := strings.TrimSuffix(s.cfg.BaseURL, "/") +
+		"/2010-04-01/Accounts/" + url.PathEscape(s.cfg.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.cfg.AccountSID, s.cfg.AuthToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send sms to %s: %w", n.Contact.Phone, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("send sms to %s: %s", n.Contact.Phone, resp.Status)
+	}
+	return nil
+}
+
+// NotifyingStorage уведомляет клиентов о смене статуса их посылок.
+// Уведомление отправляется после успешной записи; ошибка доставки
+// логируется и не отменяет смену статуса.
+type NotifyingStorage struct {
+	ParcelStorage
+	notifier Notifier
+	contacts map[int]Contact
+	logger   *slog.Logger
+}
+
+var _ ParcelStorage = NotifyingStorage{}
+
+// NewNotifyingStorage оборачивает хранилище уведомлениями. Клиенты,
+// которых нет в contacts, уведомлений не получают.
+func NewNotifyingStorage(next ParcelStorage, notifier Notifier, contacts map[int]Contact, logger *slog.Logger) NotifyingStorage {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return NotifyingStorage{ParcelStorage: next, notifier: notifier, contacts: contacts, logger: logger}
+}
+
+func (s NotifyingStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	old, err := s.ParcelStorage.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err := s.ParcelStorage.SetStatus(ctx, number, status); err != nil {
+		return err
+	}
+
+	s.notify(ctx, number, old.Status)
+	return nil
+}
+
+func (s NotifyingStorage) Update(ctx context.Context, p Parcel) error {
+	old, err := s.ParcelStorage.Get(ctx, p.Number)
+	if err != nil {
+		return err
+	}
+	if err := s.ParcelStorage.Update(ctx, p); err != nil {
+		return err
+	}
+
+	if p.Status != old.Status {
+		s.notify(ctx, p.Number, old.Status)
+	}
+	return nil
+}
+
+// Deliver уведомляет о доставке по подписи получателя так же, как SetStatus.
+func (s NotifyingStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	old, err := s.ParcelStorage.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err := s.ParcelStorage.Deliver(ctx, number, sig); err != nil {
+		return err
+	}
+
+	s.notify(ctx, number, old.Status)
+	return nil
+}
+
+// Cancel уведомляет об отмене посылки так же, как SetStatus.
+func (s NotifyingStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	old, err := s.ParcelStorage.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err := s.ParcelStorage.Cancel(ctx, number, reason); err != nil {
+		return err
+	}
+
+	s.notify(ctx, number, old.Status)
+	return nil
+}
+
+// notify отправляет уведомление о посылке number, перешедшей из статуса old.
+func (s NotifyingStorage) notify(ctx context.Context, number int, old Status) {
+	p, err := s.ParcelStorage.Get(ctx, number)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "notify", "number", number, "error", err)
+		return
+	}
+
+	contact, ok := s.contacts[p.Client]
+	if !ok {
+		return
+	}
+
+	err = s.notifier.Notify(ctx, Notification{Contact: contact, Parcel: p, OldStatus: old})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "notify", "number", number, "client", p.Client, "error", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordNotifier запоминает отправленные уведомления.
+type recordNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	err  error
+}
+
+func (r *recordNotifier) Notify(_ context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+// TestNotifyingStorage проверяет уведомления при смене статуса
+func TestNotifyingStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	rec := &recordNotifier{}
+	contact := Contact{Email: "client@example.com"}
+	parcel := getTestParcel()
+	store := NewNotifyingStorage(NewMemoryParcelStore(), rec, map[int]Contact{parcel.Client: contact}, nil)
+
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// другого клиента не уведомляем
+	other := getTestParcel()
+	other.Client = parcel.Client + 1
+	otherID, err := store.Add(ctx, other)
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, otherID, ParcelStatusSent))
+
+	// check
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+	require.Len(t, rec.sent, 1)
+	require.Equal(t, contact, rec.sent[0].Contact)
+	require.Equal(t, ParcelStatusRegistered, rec.sent[0].OldStatus)
+	require.Equal(t, ParcelStatusSent, rec.sent[0].Parcel.Status)
+
+	// недопустимый переход не уведомляет
+	require.Error(t, store.SetStatus(ctx, id, ParcelStatusRegistered))
+	require.Len(t, rec.sent, 1)
+
+	// ошибка канала не отменяет смену статуса
+	rec.err = errors.New("smtp down")
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	p.Status = ParcelStatusDelivered
+	require.NoError(t, store.Update(ctx, p))
+	require.Len(t, rec.sent, 2)
+	require.Equal(t, ParcelStatusSent, rec.sent[1].OldStatus)
+
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusDelivered, p.Status)
+}
+
+// TestNotifyingStorageDeliver проверяет уведомление о доставке по подписи
+func TestNotifyingStorageDeliver(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	rec := &recordNotifier{}
+	parcel := getTestParcel()
+	store := NewNotifyingStorage(NewMemoryParcelStore(), rec, map[int]Contact{parcel.Client: {Email: "client@example.com"}}, nil)
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+
+	// check
+	require.Error(t, store.Deliver(ctx, id, Signature{}))
+	require.Len(t, rec.sent, 1)
+
+	require.NoError(t, store.Deliver(ctx, id, Signature{SignedBy: "Иванов", Image: testPNG}))
+	require.Len(t, rec.sent, 2)
+	require.Equal(t, ParcelStatusSent, rec.sent[1].OldStatus)
+	require.Equal(t, ParcelStatusDelivered, rec.sent[1].Parcel.Status)
+}
+
+// TestNotifyingStorageCancel проверяет уведомление об отмене посылки
+func TestNotifyingStorageCancel(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	rec := &recordNotifier{}
+	parcel := getTestParcel()
+	store := NewNotifyingStorage(NewMemoryParcelStore(), rec, map[int]Contact{parcel.Client: {Email: "client@example.com"}}, nil)
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	require.NoError(t, store.Cancel(ctx, id, CancelDuplicate))
+	require.Len(t, rec.sent, 1)
+	require.Equal(t, ParcelStatusRegistered, rec.sent[0].OldStatus)
+	require.Equal(t, ParcelStatusCancelled, rec.sent[0].Parcel.Status)
+
+	require.Error(t, store.Cancel(ctx, id, CancelDuplicate))
+	require.Len(t, rec.sent, 1)
+}
+
+// TestEmailNotifier проверяет письмо, отправляемое через SMTP
+func TestEmailNotifier(t *testing.T) {
+	// prepare
+	var to []string
+	var msg string
+	notifier := NewEmailNotifier(SMTPConfig{Addr: "localhost:25", From: "tracker@example.com"})
+	notifier.send = func(_ string, _ smtp.Auth, _ string, rcpt []string, body []byte) error {
+		to, msg = rcpt, string(body)
+		return nil
+	}
+	parcel := getTestParcel()
+	parcel.Number = 7
+	parcel.Status = ParcelStatusDelivered
+
+	// check
+	err := notifier.Notify(context.Background(), Notification{Contact: Contact{Phone: "+1"}, Parcel: parcel})
+	require.NoError(t, err)
+	require.Nil(t, to)
+
+	err = notifier.Notify(context.Background(), Notification{Contact: Contact{Email: "client@example.com"}, Parcel: parcel})
+	require.NoError(t, err)
+	require.Equal(t, []string{"client@example.com"}, to)
+	require.Contains(t, msg, "To: client@example.com\r\n")
+	require.Contains(t, msg, "посылка № 7 доставлена")
+}
+
+// TestSMSNotifier проверяет запрос к Twilio-совместимому API
+func TestSMSNotifier(t *testing.T) {
+	// prepare
+	var form map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.URL.Path != "/2010-04-01/Accounts/AC1/Messages.json" || user != "AC1" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		form = map[string]string{"To": r.PostForm.Get("To"), "From": r.PostForm.Get("From"), "Body": r.PostForm.Get("Body")}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	parcel := getTestParcel()
+	parcel.Number = 7
+	parcel.Status = ParcelStatusSent
+	n := Notification{Contact: Contact{Phone: "+15551234567"}, Parcel: parcel}
+
+	// check
+	notifier := NewSMSNotifier(SMSConfig{BaseURL: srv.URL, AccountSID: "AC1", AuthToken: "secret", From: "+15550000000"})
+	require.NoError(t, notifier.Notify(context.Background(), n))
+	require.Equal(t, "+15551234567", form["To"])
+	require.Equal(t, "+15550000000", form["From"])
+	require.True(t, strings.HasPrefix(form["Body"], "Ваша посылка № 7 отправлена"))
+
+	bad := NewSMSNotifier(SMSConfig{BaseURL: srv.URL, AccountSID: "AC1", AuthToken: "wrong"})
+	require.Error(t, bad.Notify(context.Background(), n))
+}
+
+// TestNotifyConfig проверяет включение каналов по настройкам
+func TestNotifyConfig(t *testing.T) {
+	require.Nil(t, NotifyConfig{}.Notifier())
+	require.Nil(t, NotifyConfig{SMTP: SMTPConfig{Addr: "localhost:25"}}.Notifier())
+
+	cfg := NotifyConfig{
+		SMTP:    SMTPConfig{Addr: "localhost:25"},
+		SMS:     SMSConfig{AccountSID: "AC1"},
+		Clients: map[int]Contact{1: {Email: "a@example.com"}},
+	}
+	require.Len(t, cfg.Notifier(), 2)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelapi"
+)
+
+// swaggerUIPage — Swagger UI для /openapi.json; сам интерфейс грузится с CDN.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Трекер посылок — REST API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// openAPISpec возвращает спецификацию REST API из parcelapi.Spec в JSON.
+func openAPISpec() ([]byte, error) {
+	var spec any
+	if err := yaml.Unmarshal(parcelapi.Spec, &spec); err != nil {
+		return nil, fmt.Errorf("parse openapi spec: %w", err)
+	}
+	return json.Marshal(spec)
+}
+
+// NewOpenAPIHandler отдаёт спецификацию REST API на /openapi.json
+// и Swagger UI к ней на /docs. Спецификация встроена в бинарник,
+// поэтому ошибка её разбора — ошибка сборки, и NewOpenAPIHandler паникует.
+func NewOpenAPIHandler() http.Handler {
+	spec, err := openAPISpec()
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+	mux.HandleFunc("GET /docs", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(swaggerUIPage))
+	})
+	return mux
+}
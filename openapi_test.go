@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelapi"
+)
+
+// TestOpenAPIHandler проверяет /openapi.json и /docs
+func TestOpenAPIHandler(t *testing.T) {
+	// prepare
+	h := NewOpenAPIHandler()
+
+	// check
+	rec := doRequest(t, h, http.MethodGet, "/openapi.json", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var spec struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+	require.Contains(t, spec.Paths, "/parcels/{number}")
+
+	rec = doRequest(t, h, http.MethodGet, "/docs", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `url: "/openapi.json"`)
+}
+
+// TestOpenAPIRoutes проверяет, что у каждой операции спецификации
+// есть обработчик в HTTPServer: на несуществующую посылку он отвечает
+// ошибкой в JSON, а не 404 или 405 от маршрутизатора
+func TestOpenAPIRoutes(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	raw, err := openAPISpec()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &spec))
+
+	// check
+	replacer := strings.NewReplacer("{number}", "100", "{id}", "100", "{code}", "XX000000000RU")
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if method == "parameters" {
+				continue
+			}
+			method = strings.ToUpper(method)
+			rec := doRequest(t, srv, method, replacer.Replace(path), "{}")
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"), "%s %s: %d %s", method, path, rec.Code, rec.Body)
+		}
+	}
+}
+
+// TestOpenAPIClient проверяет сгенерированный клиент parcelapi на REST API
+func TestOpenAPIClient(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	srv := httptest.NewServer(NewHTTPServer(NewParcelService(NewMemoryParcelStore())))
+	t.Cleanup(srv.Close)
+	client, err := parcelapi.NewClientWithResponses(srv.URL)
+	require.NoError(t, err)
+
+	// add
+	added, err := client.AddParcelWithResponse(ctx, nil, parcelapi.AddParcelRequest{Client: 7, RecipientAddress: "Москва"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, added.StatusCode())
+	number := added.JSON201.Number
+
+	status, err := client.SetParcelStatusWithResponse(ctx, number, parcelapi.SetStatusRequest{Status: parcelapi.Sent})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, status.StatusCode())
+
+	// check
+	got, err := client.GetParcelWithResponse(ctx, number)
+	require.NoError(t, err)
+	require.Equal(t, parcelapi.Sent, got.JSON200.Status)
+	require.Equal(t, "Москва", got.JSON200.RecipientAddress)
+
+	list, err := client.ListClientParcelsWithResponse(ctx, 7, &parcelapi.ListClientParcelsParams{})
+	require.NoError(t, err)
+	page, err := list.JSON200.AsParcelList()
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+
+	missing, err := client.GetParcelWithResponse(ctx, number+100)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, missing.StatusCode())
+	require.Equal(t, ErrParcelNotFound.Error(), missing.JSONDefault.Error)
+}
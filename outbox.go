@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// OutboxEntry — событие посылки, записанное в parcel_outbox в одной
+// транзакции с изменением. Payload — ParcelEvent в JSON; SentAt нулевое,
+// пока событие не опубликовано.
+type OutboxEntry struct {
+	ID        int
+	Number    int
+	Type      string
+	Payload   string
+	CreatedAt time.Time
+	SentAt    time.Time
+	Attempts  int
+	LastError string
+}
+
+// Event разбирает Payload.
+func (e OutboxEntry) Event() (ParcelEvent, error) {
+	var event ParcelEvent
+	err := json.Unmarshal([]byte(e.Payload), &event)
+	return event, err
+}
+
+// OutboxQuery отбирает записи ListOutbox: с номером больше AfterID,
+// только неопубликованные при Pending. Limit по умолчанию 100.
+type OutboxQuery struct {
+	Pending bool
+	AfterID int
+	Limit   int
+}
+
+// maxLastError — длина колонки parcel_outbox.last_error.
+const maxLastError = 1024
+
+// Outbox даёт доступ к таблице parcel_outbox: её читает OutboxRelay,
+// а через ListOutbox и ReplayOutbox её можно посмотреть и переотправить.
+type Outbox interface {
+	ListOutbox(ctx context.Context, q OutboxQuery) ([]OutboxEntry, error)
+	MarkOutboxSent(ctx context.Context, id int) error
+	MarkOutboxFailed(ctx context.Context, id int, cause error) error
+	ReplayOutbox(ctx context.Context, fromID int) (int, error)
+}
+
+var _ Outbox = ParcelStore{}
+
+// ListOutbox возвращает записи outbox по возрастанию номера.
+func (s ParcelStore) ListOutbox(ctx context.Context, q OutboxQuery) ([]OutboxEntry, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	query := "SELECT id, parcel_number, type, payload, created_at, sent_at, attempts, last_error" +
+		" FROM parcel_outbox WHERE id > ?"
+	if q.Pending {
+		query += " AND sent_at IS NULL"
+	}
+	query += " ORDER BY id LIMIT ?"
+
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(query), q.AfterID, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []OutboxEntry
+	for rows.Next() {
+		e := OutboxEntry{}
+		err := rows.Scan(&e.ID, &e.Number, &e.Type, &e.Payload, scanTime{&e.CreatedAt}, scanTime{&e.SentAt},
+			&e.Attempts, &e.LastError)
+		if err != nil {
+			return nil, err
+		}
+		if e.Payload, err = s.decrypt(e.Payload); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// MarkOutboxSent отмечает запись опубликованной.
+func (s ParcelStore) MarkOutboxSent(ctx context.Context, id int) error {
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel_outbox SET sent_at = ?, attempts = attempts + 1, last_error = '' WHERE id = ?"),
+		s.timestampArg(), id)
+	return err
+}
+
+// MarkOutboxFailed запоминает неудачную попытку публикации записи.
+func (s ParcelStore) MarkOutboxFailed(ctx context.Context, id int, cause error) error {
+	msg := cause.Error()
+	if len(msg) > maxLastError {
+		msg = msg[:maxLastError]
+	}
+
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel_outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?"),
+		msg, id)
+	return err
+}
+
+// ReplayOutbox снова ставит в очередь опубликованные записи с номером
+// от fromID и возвращает их количество: например, чтобы заполнить
+// события нового потребителя.
+func (s ParcelStore) ReplayOutbox(ctx context.Context, fromID int) (int, error) {
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel_outbox SET sent_at = NULL, attempts = 0, last_error = '' WHERE id >= ? AND sent_at IS NOT NULL"),
+		fromID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// appendOutbox записывает события в outbox.
+func (s ParcelStore) appendOutbox(ctx context.Context, events []ParcelEvent) error {
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		// в событии есть адреса, поэтому оно шифруется так же, как посылка
+		payload, err := s.encrypt(string(b))
+		if err != nil {
+			return err
+		}
+
+		_, err = s.q.ExecContext(ctx, s.dialect.rebind(
+			"INSERT INTO parcel_outbox (parcel_number, type, payload, created_at) VALUES (?, ?, ?, ?)"),
+			e.Number, e.Type, payload, s.timestampArg())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutboxStore — SQL-хранилище, которое в той же транзакции, что и изменение
+// посылки, записывает его события в parcel_outbox. Событие не теряется
+// при падении процесса между записью и публикацией: его доставит OutboxRelay.
+type OutboxStore struct {
+	ParcelStore
+}
+
+var _ ParcelStorage = OutboxStore{}
+
+func NewOutboxStore(s ParcelStore) OutboxStore {
+	return OutboxStore{ParcelStore: s}
+}
+
+func (s OutboxStore) Add(ctx context.Context, p Parcel) (int, error) {
+	var number int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		var err error
+		if number, err = tx.Add(ctx, p); err != nil || AddReplayed(ctx) {
+			return err
+		}
+		return tx.recordCreated(ctx, []int{number})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func (s OutboxStore) AddBatch(ctx context.Context, parcels []Parcel) ([]int, error) {
+	var numbers []int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		var err error
+		if numbers, err = tx.AddBatch(ctx, parcels); err != nil {
+			return err
+		}
+		return tx.recordCreated(ctx, numbers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return numbers, nil
+}
+
+func (s OutboxStore) SetStatus(ctx context.Context, number int, status Status) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.SetStatus(ctx, number, status)
+	})
+}
+
+func (s OutboxStore) SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error) {
+	var results []StatusResult
+	err := s.record(ctx, numbers, func(tx ParcelStore) error {
+		var err error
+		results, err = tx.SetStatusBatch(ctx, numbers, status)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s OutboxStore) SetSenderAddress(ctx context.Context, number int, address string) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.SetSenderAddress(ctx, number, address)
+	})
+}
+
+func (s OutboxStore) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.SetRecipientAddress(ctx, number, address)
+	})
+}
+
+func (s OutboxStore) Update(ctx context.Context, p Parcel) error {
+	return s.record(ctx, []int{p.Number}, func(tx ParcelStore) error {
+		return tx.Update(ctx, p)
+	})
+}
+
+func (s OutboxStore) Delete(ctx context.Context, number int) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.Delete(ctx, number)
+	})
+}
+
+func (s OutboxStore) HardDelete(ctx context.Context, number int) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.HardDelete(ctx, number)
+	})
+}
+
+func (s OutboxStore) Restore(ctx context.Context, number int) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.Restore(ctx, number)
+	})
+}
+
+func (s OutboxStore) AssignCourier(ctx context.Context, number, courierID int) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.AssignCourier(ctx, number, courierID)
+	})
+}
+
+func (s OutboxStore) CompleteDelivery(ctx context.Context, courierID, number int) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.CompleteDelivery(ctx, courierID, number)
+	})
+}
+
+// WithTx выполняет fn в транзакции; изменения через tx тоже попадают в outbox.
+func (s OutboxStore) WithTx(ctx context.Context, fn func(tx ParcelTx) error) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		return fn(OutboxStore{ParcelStore: tx})
+	})
+}
+
+// record выполняет write в транзакции и записывает в outbox события,
+// которыми изменились посылки numbers.
+func (s OutboxStore) record(ctx context.Context, numbers []int, write func(tx ParcelStore) error) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		before := make(map[int]Parcel, len(numbers))
+		var order []int
+		for _, number := range numbers {
+			if _, ok := before[number]; ok {
+				continue
+			}
+			p, err := tx.lookup(ctx, number)
+			if err != nil {
+				return err
+			}
+			before[number] = p
+			order = append(order, number)
+		}
+
+		if err := write(tx); err != nil {
+			return err
+		}
+
+		var events []ParcelEvent
+		for _, number := range order {
+			p, err := tx.lookup(ctx, number)
+			if err != nil {
+				return err
+			}
+			events = append(events, changeEvents(before[number], p)...)
+		}
+		return tx.appendOutbox(ctx, events)
+	})
+}
+
+// recordCreated записывает в outbox ParcelCreated для новых посылок.
+func (s ParcelStore) recordCreated(ctx context.Context, numbers []int) error {
+	events := make([]ParcelEvent, 0, len(numbers))
+	for _, number := range numbers {
+		p, err := s.Get(ctx, number)
+		if err != nil {
+			return err
+		}
+		events = append(events, newParcelEvent(EventParcelCreated, p))
+	}
+	return s.appendOutbox(ctx, events)
+}
+
+// lookup возвращает посылку или нулевую Parcel, если её нет.
+func (s ParcelStore) lookup(ctx context.Context, number int) (Parcel, error) {
+	p, err := s.Get(ctx, number)
+	if errors.Is(err, ErrParcelNotFound) {
+		return Parcel{}, nil
+	}
+	return p, err
+}
+
+// OutboxRelay публикует неотправленные записи outbox по порядку.
+// На первой ошибке прогон останавливается, чтобы события одной посылки
+// не обогнали друг друга; запись повторится в следующем прогоне.
+type OutboxRelay struct {
+	outbox    Outbox
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	logger    *slog.Logger
+}
+
+func NewOutboxRelay(outbox Outbox, publisher Publisher, interval time.Duration) OutboxRelay {
+	return OutboxRelay{outbox: outbox, publisher: publisher, interval: interval, batchSize: 100, logger: slog.Default()}
+}
+
+// WithLogger возвращает копию ретранслятора, пишущую результаты в logger.
+func (r OutboxRelay) WithLogger(logger *slog.Logger) OutboxRelay {
+	r.logger = logger
+	return r
+}
+
+// Run публикует события сразу и затем каждые interval, пока не отменён ctx.
+func (r OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce публикует все накопившиеся записи и возвращает их количество.
+func (r OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		entries, err := r.outbox.ListOutbox(ctx, OutboxQuery{Pending: true, Limit: r.batchSize})
+		if err != nil {
+			return sent, r.fail(ctx, err)
+		}
+
+		for _, entry := range entries {
+			if err := r.publish(ctx, entry); err != nil {
+				return sent, r.fail(ctx, err)
+			}
+			sent++
+		}
+
+		if len(entries) < r.batchSize {
+			if sent > 0 {
+				r.logger.InfoContext(ctx, "relay outbox", "sent", sent)
+			}
+			return sent, nil
+		}
+	}
+}
+
+func (r OutboxRelay) publish(ctx context.Context, entry OutboxEntry) error {
+	event, err := entry.Event()
+	if err == nil {
+		err = r.publisher.Publish(ctx, event)
+	}
+	if err != nil {
+		return errors.Join(err, r.outbox.MarkOutboxFailed(ctx, entry.ID, err))
+	}
+
+	return r.outbox.MarkOutboxSent(ctx, entry.ID)
+}
+
+func (r OutboxRelay) fail(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		r.logger.ErrorContext(ctx, "relay outbox", "error", err)
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// outboxTypes возвращает типы событий всех записей outbox по порядку.
+func outboxTypes(t *testing.T, o Outbox) []string {
+	t.Helper()
+
+	entries, err := o.ListOutbox(context.Background(), OutboxQuery{})
+	require.NoError(t, err)
+
+	var res []string
+	for _, e := range entries {
+		res = append(res, e.Type)
+	}
+	return res
+}
+
+// TestOutboxStore проверяет запись событий в outbox вместе с изменениями
+func TestOutboxStore(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewOutboxStore(NewParcelStore(openTempDB(t)))
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetRecipientAddress(ctx, id, "new address"))
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+
+	// check
+	require.Equal(t, []string{EventParcelCreated, EventAddressChanged, EventStatusChanged}, outboxTypes(t, store))
+
+	entries, err := store.ListOutbox(ctx, OutboxQuery{Pending: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, id, entries[2].Number)
+	require.True(t, entries[2].SentAt.IsZero())
+
+	event, err := entries[2].Event()
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered.String(), event.OldStatus)
+	require.Equal(t, ParcelStatusSent.String(), event.Status)
+
+	// отклонённая запись не оставляет событий
+	require.ErrorIs(t, store.Delete(ctx, id), ErrDeleteNotAllowed)
+	require.Len(t, outboxTypes(t, store), 3)
+
+	// откат транзакции откатывает и события
+	err = store.WithTx(ctx, func(tx ParcelTx) error {
+		if _, err := tx.Add(ctx, getTestParcel()); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	require.Error(t, err)
+	require.Len(t, outboxTypes(t, store), 3)
+
+	// удаление и восстановление
+	id, err = store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(ctx, id))
+	require.NoError(t, store.Restore(ctx, id))
+	types := outboxTypes(t, store)
+	require.Equal(t, []string{EventParcelCreated, EventParcelDeleted, EventParcelCreated}, types[3:])
+}
+
+// TestOutboxRelay проверяет публикацию, повтор после ошибки и переотправку
+func TestOutboxRelay(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewOutboxStore(NewParcelStore(openTempDB(t)))
+	rec := &recordPublisher{}
+	relay := NewOutboxRelay(store, rec, 0)
+
+	numbers, err := store.AddBatch(ctx, []Parcel{getTestParcel(), getTestParcel()})
+	require.NoError(t, err)
+
+	// ошибка шины: запись остаётся в очереди с причиной
+	rec.err = errors.New("broker down")
+	n, err := relay.RunOnce(ctx)
+	require.Error(t, err)
+	require.Equal(t, 0, n)
+
+	entries, err := store.ListOutbox(ctx, OutboxQuery{Pending: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 1, entries[0].Attempts)
+	require.Equal(t, "broker down", entries[0].LastError)
+
+	// check
+	rec.err = nil
+	rec.events = nil
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, numbers[0], rec.events[0].Number)
+	require.Equal(t, numbers[1], rec.events[1].Number)
+
+	entries, err = store.ListOutbox(ctx, OutboxQuery{Pending: true})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// replay
+	all, err := store.ListOutbox(ctx, OutboxQuery{})
+	require.NoError(t, err)
+	require.False(t, all[0].SentAt.IsZero())
+
+	replayed, err := store.ReplayOutbox(ctx, all[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, replayed)
+
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, numbers[1], rec.events[2].Number)
+}
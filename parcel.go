@@ -1,60 +1,524 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ParcelStore хранит посылки в SQL-базе. По умолчанию используется SQLite,
+// другие СУБД подключаются через свой диалект.
 type ParcelStore struct {
+	// db — подключение, из которого создано хранилище, или nil,
+	// если оно создано поверх другого Querier
 	db *sql.DB
+	// q выполняет запросы: это либо db, либо открытая транзакция
+	q           querier
+	dialect     dialect
+	transitions StatusTransitions
+	// addresses проверяет адреса, см. WithAddressValidator
+	addresses AddressValidator
+	// keys задаётся через WithKeyMode
+	keys KeyMode
+	// now — часы для created_at, см. WithClock
+	now func() time.Time
+	// stmts включается через WithStatementCache, tracer — через WithTracer
+	stmts  *stmtCache
+	tracer trace.Tracer
+	// paymentRequired задаётся через WithPaymentRequired
+	paymentRequired bool
+	// cipher шифрует адреса, см. WithCipher
+	cipher *FieldCipher
+	// replica и r — реплика для чтения и её обёрнутый querier, см. WithReplica
+	replica      *sql.DB
+	replicaStmts *stmtCache
+	r            querier
+	// fresh задаётся через WithReadYourWrites
+	fresh *freshParcels
+}
+
+// Querier выполняет запросы хранилища. Его реализуют *sql.DB, *sql.Tx
+// и *sql.Conn, а в unit-тестах — testutil.MockQuerier: так текст запросов
+// и их аргументы проверяются без настоящей СУБД.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ErrNoDB возвращают транзакции, резервное копирование, обслуживание
+// и проверка готовности хранилища, созданного не поверх *sql.DB.
+var ErrNoDB = errors.New("storage is not backed by *sql.DB")
+
+// querier объединяет общие методы *sql.DB и *sql.Tx.
+type querier interface {
+	Querier
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
-func NewParcelStore(db *sql.DB) ParcelStore {
-	return ParcelStore{db: db}
+// plainQuerier дополняет Querier без PrepareContext до querier.
+type plainQuerier struct {
+	Querier
 }
 
-func (s ParcelStore) Add(p Parcel) (int, error) {
-	// реализуйте добавление строки в таблицу parcel, используйте данные из переменной p
+func (plainQuerier) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, ErrNoDB
+}
 
-	// верните идентификатор последней добавленной записи
-	return 0, nil
+// wrap оборачивает *sql.DB или *sql.Tx включёнными кэшем запросов и трассировкой.
+func (s ParcelStore) wrap(q querier) querier {
+	if s.stmts != nil {
+		q = cachingQuerier{q: q, cache: s.stmts}
+	}
+	if s.tracer != nil {
+		q = tracingQuerier{q: q, tracer: s.tracer, system: s.dialect.name}
+	}
+	return q
 }
 
-func (s ParcelStore) Get(number int) (Parcel, error) {
-	// реализуйте чтение строки по заданному number
-	// здесь из таблицы должна вернуться только одна строка
+// unwrapQuerier возвращает исходный *sql.DB или *sql.Tx.
+func unwrapQuerier(q querier) querier {
+	for {
+		switch w := q.(type) {
+		case tracingQuerier:
+			q = w.q
+		case cachingQuerier:
+			q = w.q
+		default:
+			return q
+		}
+	}
+}
+
+var _ ParcelStorage = ParcelStore{}
+
+func init() {
+	RegisterStorage("sqlite", func(db *sql.DB) ParcelStorage {
+		return NewParcelStore(db)
+	})
+}
+
+// NewParcelStore создаёт хранилище SQLite поверх q, обычно *sql.DB.
+// Транзакции, пул соединений и кэш запросов есть только у хранилища
+// поверх *sql.DB, см. ErrNoDB.
+func NewParcelStore(q Querier) ParcelStore {
+	return newSQLParcelStore(q, sqliteDialect)
+}
+
+func newSQLParcelStore(q Querier, d dialect) ParcelStore {
+	s := ParcelStore{dialect: d, transitions: DefaultStatusTransitions(),
+		addresses: DefaultAddressValidator{}, keys: KeyModeInt, now: time.Now}
+	switch q := q.(type) {
+	case *sql.DB:
+		s.db, s.q = q, q
+	case querier:
+		s.q = q
+	default:
+		s.q = plainQuerier{q}
+	}
+	return s
+}
+
+// sqlDB возвращает *sql.DB хранилища или ErrNoDB.
+func (s ParcelStore) sqlDB() (*sql.DB, error) {
+	if s.db == nil {
+		return nil, ErrNoDB
+	}
+	return s.db, nil
+}
+
+// WithTransitions возвращает копию хранилища с другими правилами смены статуса.
+func (s ParcelStore) WithTransitions(t StatusTransitions) ParcelStore {
+	s.transitions = t
+	return s
+}
+
+// WithPool настраивает пул соединений хранилища. Настройки применяются
+// к общему *sql.DB, то есть действуют и на другие копии хранилища.
+// У хранилища не поверх *sql.DB пула нет, и настройка ни на что не влияет.
+func (s ParcelStore) WithPool(p PoolConfig) ParcelStore {
+	if s.db != nil {
+		p.apply(s.db)
+	}
+	return s
+}
+
+// Close закрывает подготовленные запросы и подключение к БД.
+// После Close хранилищем и его копиями пользоваться нельзя.
+func (s ParcelStore) Close() error {
+	var errs []error
+	if s.stmts != nil {
+		errs = append(errs, s.stmts.close())
+	}
+	if s.replicaStmts != nil {
+		errs = append(errs, s.replicaStmts.close())
+	}
+	if s.db != nil {
+		errs = append(errs, s.db.Close())
+	}
+	if s.replica != nil {
+		errs = append(errs, s.replica.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+const insertParcelQuery = "INSERT INTO parcel (client, status, sender_address, recipient_address, created_at, updated_at, track_code, uuid," +
+	" weight_grams, length_mm, width_mm, height_mm, price, amount, currency, payment_status, paid_at, payment_ref," +
+	" service_class, sla_deadline, return_of, tenant_id)" +
+	" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+// Add добавляет посылку и возвращает её номер. p.CreatedAt игнорируется:
+// время создания задаёт хранилище. Адреса нормализуются и проверяются,
+// см. WithAddressValidator; адрес отправителя можно не задавать. Если p.TrackCode пуст,
+// трек-код генерируется через NewTrackCode; в режиме KeyModeUUID так же
+// генерируется пустой p.UUID. Посылка записывается на арендатора из ctx, см. WithTenant.
+// С ключом из WithIdempotencyKey повторный Add возвращает номер уже добавленной посылки.
+func (s ParcelStore) Add(ctx context.Context, p Parcel) (int, error) {
+	p, err := prepareParcel(s.addresses, p)
+	if err != nil {
+		return 0, err
+	}
+	if p, err = s.sealParcel(p); err != nil {
+		return 0, err
+	}
+	if key := IdempotencyKeyFromContext(ctx); key != "" {
+		if err := checkIdempotencyKey(key); err != nil {
+			return 0, err
+		}
+		number, err := s.addIdempotent(ctx, p, key)
+		if err == nil {
+			s.fresh.add(number)
+		}
+		return number, err
+	}
+
+	number, err := s.insert(ctx, insertParcelQuery, "number", s.insertParcelArgs(ctx, p)...)
+	if err != nil {
+		return 0, clientError(err)
+	}
+	s.fresh.add(number)
+	return number, nil
+}
+
+func (s ParcelStore) insertParcelArgs(ctx context.Context, p Parcel) []any {
+	now := s.timestampArg()
+	trackCode, id := s.parcelKeys(p)
+	return []any{p.Client, p.Status, p.SenderAddress, p.RecipientAddress, now, now, trackCode, id,
+		p.WeightGrams, p.LengthMM, p.WidthMM, p.HeightMM, p.Price,
+		p.Amount, p.Currency, p.PaymentStatus, s.dialect.paidAtArg(p.PaidAt), p.PaymentRef,
+		p.ServiceClass, s.dialect.paidAtArg(p.SLADeadline), returnOfArg(p.ReturnOf), TenantFromContext(ctx)}
+}
+
+// parcelKeys возвращает трек-код и UUID для записи посылки p,
+// генерируя недостающие.
+func (s ParcelStore) parcelKeys(p Parcel) (string, any) {
+	if p.TrackCode == "" {
+		p.TrackCode = NewTrackCode()
+	}
+	// NULL, а не пустая строка: уникальный индекс допускает много NULL
+	var id any
+	if p.UUID != "" {
+		id = p.UUID
+	} else if s.keys == KeyModeUUID {
+		id = newParcelUUID()
+	}
+	return p.TrackCode, id
+}
+
+// insert выполняет INSERT и возвращает идентификатор новой строки из колонки
+// idColumn: через RETURNING или LastInsertId, в зависимости от диалекта.
+func (s ParcelStore) insert(ctx context.Context, query, idColumn string, args ...any) (int, error) {
+	if s.dialect.returning {
+		var id int
+		err := s.q.QueryRowContext(ctx, s.dialect.rebind(query+" RETURNING "+idColumn), args...).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+		return id, nil
+	}
+
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
 
-	// заполните объект Parcel данными из таблицы
+// parcelColumns читаются через scanParcel. У посылок, добавленных
+// до появления трек-кодов, track_code пустой, а uuid пуст в режиме KeyModeInt;
+// eta равен NULL, пока дата доставки не рассчитана, sla_deadline —
+// у посылок без срока по SLA, sla_breached_at — пока срок не нарушен,
+// return_of — у посылок, которые не возвращают другую, cancelled_at —
+// у неотменённых, signed_at — у доставленных без подписи.
+const parcelColumns = "number, client, status, sender_address, recipient_address, created_at, updated_at, version, COALESCE(track_code, ''), COALESCE(uuid, ''), eta, weight_grams, length_mm, width_mm, height_mm, price," +
+	" amount, currency, payment_status, paid_at, payment_ref, service_class, sla_deadline, sla_breached_at, COALESCE(return_of, 0)," +
+	" cancel_reason, cancelled_at, signed_by, signed_at"
+
+// scanParcel читает колонки parcelColumns из строки результата
+// и расшифровывает адреса.
+func (s ParcelStore) scanParcel(row interface{ Scan(dest ...any) error }) (Parcel, error) {
 	p := Parcel{}
+	err := row.Scan(&p.Number, &p.Client, &p.Status, &p.SenderAddress, &p.RecipientAddress, scanTime{&p.CreatedAt}, scanTime{&p.UpdatedAt}, &p.Version, &p.TrackCode, &p.UUID, scanTime{&p.ETA}, &p.WeightGrams, &p.LengthMM, &p.WidthMM, &p.HeightMM, &p.Price,
+		&p.Amount, &p.Currency, &p.PaymentStatus, scanTime{&p.PaidAt}, &p.PaymentRef,
+		&p.ServiceClass, scanTime{&p.SLADeadline}, scanTime{&p.SLABreachedAt}, &p.ReturnOf,
+		&p.CancelReason, scanTime{&p.CancelledAt}, &p.SignedBy, scanTime{&p.SignedAt})
+	if err != nil {
+		return p, err
+	}
+	return p, s.openParcel(&p)
+}
+
+func (s ParcelStore) Get(ctx context.Context, number int) (Parcel, error) {
+	cond, args := tenantCond(ctx)
+	row := s.reader(number).QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT "+parcelColumns+" FROM parcel WHERE number = ? AND deleted_at IS NULL"+cond),
+		append([]any{number}, args...)...)
+
+	p, err := s.scanParcel(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrParcelNotFound
+	}
+	if err != nil {
+		return p, err
+	}
 
 	return p, nil
 }
 
-func (s ParcelStore) GetByClient(client int) ([]Parcel, error) {
-	// реализуйте чтение строк из таблицы parcel по заданному client
-	// здесь из таблицы может вернуться несколько строк
+// status возвращает текущий статус посылки или ErrParcelNotFound,
+// в том числе для удалённой посылки и посылки другого арендатора.
+func (s ParcelStore) status(ctx context.Context, number int) (Status, error) {
+	cond, args := tenantCond(ctx)
+	var status Status
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT status FROM parcel WHERE number = ? AND deleted_at IS NULL"+cond),
+		append([]any{number}, args...)...).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrParcelNotFound
+	}
+
+	return status, err
+}
+
+func (s ParcelStore) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	cond, args := tenantCond(ctx)
+	return s.query(ctx, "SELECT "+parcelColumns+" FROM parcel WHERE client = ? AND deleted_at IS NULL"+cond+" ORDER BY number",
+		append([]any{client}, args...)...)
+}
+
+// query выполняет SELECT по колонкам parcelColumns и собирает посылки в срез.
+func (s ParcelStore) query(ctx context.Context, query string, args ...any) ([]Parcel, error) {
+	rows, err := s.reader(0).QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	// заполните срез Parcel данными из таблицы
 	var res []Parcel
+	for rows.Next() {
+		p, err := s.scanParcel(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return res, nil
 }
 
-func (s ParcelStore) SetStatus(number int, status string) error {
-	// реализуйте обновление статуса в таблице parcel
+// SetStatus переводит посылку в новый статус, если переход разрешён
+// правилами хранилища, иначе возвращает ErrInvalidTransition. Если включено
+// WithPaymentRequired, неоплаченную посылку нельзя отправить — ErrPaymentRequired.
+// Каждая смена статуса записывается в историю, см. GetHistory.
+func (s ParcelStore) SetStatus(ctx context.Context, number int, status Status) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		current, err := tx.status(ctx, number)
+		if err != nil {
+			return err
+		}
+		if err := tx.transitions.Validate(current, status); err != nil {
+			return err
+		}
+		if err := tx.checkPaid(ctx, number, status); err != nil {
+			return err
+		}
 
-	return nil
+		_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET status = ?, version = version + 1, updated_at = ? WHERE number = ?"),
+			status, tx.timestampArg(), number)
+		if err != nil {
+			return err
+		}
+
+		return tx.addHistory(ctx, StatusChange{
+			Number:    number,
+			OldStatus: current,
+			NewStatus: status,
+			ChangedAt: formatTime(s.now()),
+		})
+	})
 }
 
-func (s ParcelStore) SetAddress(number int, address string) error {
-	// реализуйте обновление адреса в таблице parcel
+// SetSenderAddress меняет адрес отправителя посылки в статусе registered.
+func (s ParcelStore) SetSenderAddress(ctx context.Context, number int, address string) error {
+	return s.setAddress(ctx, number, "sender_address", address)
+}
+
+// SetRecipientAddress меняет адрес получателя посылки в статусе registered.
+func (s ParcelStore) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	return s.setAddress(ctx, number, "recipient_address", address)
+}
+
+// setAddress записывает адрес в колонку column, которую задаёт вызывающий код.
+func (s ParcelStore) setAddress(ctx context.Context, number int, column, address string) error {
+	address, err := prepareAddress(s.addresses, address)
+	if err != nil {
+		return err
+	}
+	if address, err = s.encrypt(address); err != nil {
+		return err
+	}
+
 	// менять адрес можно только если значение статуса registered
+	cond, args := tenantCond(ctx)
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET "+column+" = ?, version = version + 1, updated_at = ?"+
+			" WHERE number = ? AND status = ? AND deleted_at IS NULL"+cond),
+		append([]any{address, s.timestampArg(), number, ParcelStatusRegistered}, args...)...)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return s.checkAffected(ctx, res, number, isRegistered, ErrAddressChangeNotAllowed)
+}
+
+// Delete помечает посылку удалённой: она пропадает из выборок, но её можно
+// вернуть через Restore. Удалять можно только посылки в статусе registered
+// и без открытых претензий.
+func (s ParcelStore) Delete(ctx context.Context, number int) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		if err := tx.checkOpenClaims(ctx, number); err != nil {
+			return err
+		}
+
+		cond, args := tenantCond(ctx)
+		res, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET deleted_at = ?, version = version + 1, updated_at = ?"+
+				" WHERE number = ? AND status = ? AND deleted_at IS NULL"+cond),
+			append([]any{tx.timestampArg(), tx.timestampArg(), number, ParcelStatusRegistered}, args...)...)
+		if err != nil {
+			return err
+		}
+
+		return tx.checkAffected(ctx, res, number, isRegistered, ErrDeleteNotAllowed)
+	})
+}
+
+// HardDelete физически удаляет посылку вместе с историей, событиями и маршрутом,
+// в том числе уже помеченную удалённой. Правило registered и запрет удаления
+// при открытых претензиях сохраняются.
+func (s ParcelStore) HardDelete(ctx context.Context, number int) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		if err := tx.checkOpenClaims(ctx, number); err != nil {
+			return err
+		}
+
+		// удалять строку можно только если значение статуса registered
+		cond, args := tenantCond(ctx)
+		res, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"DELETE FROM parcel WHERE number = ? AND status = ?"+cond),
+			append([]any{number, ParcelStatusRegistered}, args...)...)
+		if err != nil {
+			return err
+		}
+		if err := tx.checkAffected(ctx, res, number, isRegistered, ErrDeleteNotAllowed); err != nil {
+			return err
+		}
+
+		// история, события, заметки и решённые претензии удалённой посылки больше не нужны
+		for _, table := range []string{"parcel_status_history", "parcel_event", "parcel_checkpoint", "parcel_note", "parcel_attachment", "parcel_signature", "delivery_attempt", "courier_manifest_stop", "claims"} {
+			_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+				"DELETE FROM "+table+" WHERE parcel_number = ?"),
+				number)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Restore возвращает посылку, удалённую через Delete.
+// Для посылки, которая не удалялась, ничего не делает.
+func (s ParcelStore) Restore(ctx context.Context, number int) error {
+	cond, args := tenantCond(ctx)
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE number = ? AND deleted_at IS NOT NULL"+cond),
+		append([]any{s.timestampArg(), number}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	_, err = s.status(ctx, number)
+	return err
 }
 
-func (s ParcelStore) Delete(number int) error {
-	// реализуйте удаление строки из таблицы parcel
-	// удалять строку можно только если значение статуса registered
+// checkAffected разбирается, почему UPDATE или DELETE не затронул ни одной строки:
+// посылки нет (ErrParcelNotFound) или её текущий статус не проходит allowed
+// (notAllowed).
+//
+// MySQL не считает строки, значения в которых не изменились, поэтому
+// подходящая по статусу посылка с нулём затронутых строк — не ошибка.
+func (s ParcelStore) checkAffected(ctx context.Context, res sql.Result, number int,
+	allowed func(status Status) bool, notAllowed error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	current, err := s.status(ctx, number)
+	if err != nil {
+		return err
+	}
+	if !allowed(current) {
+		return notAllowed
+	}
 
 	return nil
 }
+
+func isRegistered(status Status) bool {
+	return status == ParcelStatusRegistered
+}
+
+// inBatchSize ограничивает число номеров в одном IN (...):
+// у SQLite и MySQL есть предел количества параметров запроса.
+const inBatchSize = 500
+
+// placeholders возвращает n плейсхолдеров через запятую для IN (...).
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"math/rand"
 	"testing"
 	"time"
@@ -18,70 +18,112 @@ var (
 	randRange = rand.New(randSource)
 )
 
-// getTestParcel возвращает тестовую посылку
+// testCreatedAt — время создания посылок в хранилищах с часами testClock
+var testCreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testClock() time.Time {
+	return testCreatedAt
+}
+
+// getTestParcel возвращает тестовую посылку. CreatedAt и UpdatedAt совпадают
+// с тем, что задаст хранилище с часами testClock, валюта и статус оплаты —
+// со значениями по умолчанию
 func getTestParcel() Parcel {
 	return Parcel{
-		Client:    1000,
-		Status:    ParcelStatusRegistered,
-		Address:   "test",
-		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Client:           1000,
+		Status:           ParcelStatusRegistered,
+		RecipientAddress: "test",
+		CreatedAt:        testCreatedAt,
+		UpdatedAt:        testCreatedAt,
+		TrackCode:        NewTrackCode(),
+		Currency:         defaultCurrency,
+		PaymentStatus:    PaymentStatusUnpaid,
 	}
 }
 
 // TestAddGetDelete проверяет добавление, получение и удаление посылки
 func TestAddGetDelete(t *testing.T) {
 	// prepare
-	db, err := // настройте подключение к БД
-	store := NewParcelStore(db)
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db).WithClock(testClock)
 	parcel := getTestParcel()
 
 	// add
-	// добавьте новую посылку в БД, убедитесь в отсутствии ошибки и наличии идентификатора
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	parcel.Number = id
+	parcel.Version = 1
 
 	// get
-	// получите только что добавленную посылку, убедитесь в отсутствии ошибки
-	// проверьте, что значения всех полей в полученном объекте совпадают со значениями полей в переменной parcel
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel, stored)
 
 	// delete
-	// удалите добавленную посылку, убедитесь в отсутствии ошибки
-	// проверьте, что посылку больше нельзя получить из БД
+	err = store.Delete(ctx, id)
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
 }
 
 // TestSetAddress проверяет обновление адреса
 func TestSetAddress(t *testing.T) {
 	// prepare
-	db, err := // настройте подключение к БД
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
 
 	// add
-	// добавьте новую посылку в БД, убедитесь в отсутствии ошибки и наличии идентификатора
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
 
 	// set address
-	// обновите адрес, убедитесь в отсутствии ошибки
 	newAddress := "new test address"
+	err = store.SetRecipientAddress(ctx, id, newAddress)
+	require.NoError(t, err)
 
 	// check
-	// получите добавленную посылку и убедитесь, что адрес обновился
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, newAddress, stored.RecipientAddress)
 }
 
 // TestSetStatus проверяет обновление статуса
 func TestSetStatus(t *testing.T) {
 	// prepare
-	db, err := // настройте подключение к БД
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
 
 	// add
-	// добавьте новую посылку в БД, убедитесь в отсутствии ошибки и наличии идентификатора
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
 
 	// set status
-	// обновите статус, убедитесь в отсутствии ошибки
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.NoError(t, err)
 
 	// check
-	// получите добавленную посылку и убедитесь, что статус обновился
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, stored.Status)
 }
 
 // TestGetByClient проверяет получение посылок по идентификатору клиента
 func TestGetByClient(t *testing.T) {
 	// prepare
-	db, err := // настройте подключение к БД
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db).WithClock(testClock)
 
 	parcels := []Parcel{
 		getTestParcel(),
@@ -98,24 +140,227 @@ func TestGetByClient(t *testing.T) {
 
 	// add
 	for i := 0; i < len(parcels); i++ {
-		id, err := // добавьте новую посылку в БД, убедитесь в отсутствии ошибки и наличии идентификатора
+		id, err := store.Add(ctx, parcels[i])
+		require.NoError(t, err)
+		require.NotEmpty(t, id)
 
-		// обновляем идентификатор добавленной у посылки
+		// обновляем идентификатор и версию добавленной у посылки
 		parcels[i].Number = id
+		parcels[i].Version = 1
 
 		// сохраняем добавленную посылку в структуру map, чтобы её можно было легко достать по идентификатору посылки
 		parcelMap[id] = parcels[i]
 	}
 
 	// get by client
-	storedParcels, err := // получите список посылок по идентификатору клиента, сохранённого в переменной client
-	// убедитесь в отсутствии ошибки
-	// убедитесь, что количество полученных посылок совпадает с количеством добавленных
+	storedParcels, err := store.GetByClient(ctx, client)
+	require.NoError(t, err)
+	require.Len(t, storedParcels, len(parcels))
 
 	// check
 	for _, parcel := range storedParcels {
 		// в parcelMap лежат добавленные посылки, ключ - идентификатор посылки, значение - сама посылка
-		// убедитесь, что все посылки из storedParcels есть в parcelMap
-		// убедитесь, что значения полей полученных посылок заполнены верно
+		expected, ok := parcelMap[parcel.Number]
+		require.True(t, ok)
+		require.Equal(t, expected, parcel)
 	}
 }
+
+// TestCanceledContext проверяет, что отменённый контекст прерывает запрос к БД
+func TestCanceledContext(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	store := NewParcelStore(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// check
+	_, err := store.Add(ctx, getTestParcel())
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = store.GetByClient(ctx, 1000)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestOpenStorage проверяет получение хранилища из реестра
+func TestOpenStorage(t *testing.T) {
+	db := openTempDB(t)
+
+	store, err := OpenStorage("sqlite", db)
+	require.NoError(t, err)
+	require.IsType(t, ParcelStore{}, store)
+
+	_, err = OpenStorage("unknown", db)
+	require.Error(t, err)
+}
+
+// TestListByClientAndStatus проверяет выборку посылок клиента в заданном статусе
+func TestListByClientAndStatus(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+	client := randRange.Intn(10_000_000)
+
+	// add
+	var sent []int
+	for i := 0; i < 3; i++ {
+		parcel := getTestParcel()
+		parcel.Client = client
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+
+		if i > 0 {
+			err = store.SetStatus(ctx, id, ParcelStatusSent)
+			require.NoError(t, err)
+			sent = append(sent, id)
+		}
+	}
+
+	// get by client and status
+	page, err := store.ListAll(ctx, Filter{Client: client, Statuses: []Status{ParcelStatusSent}}, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Parcels, len(sent))
+	for i, parcel := range page.Parcels {
+		require.Equal(t, sent[i], parcel.Number)
+		require.Equal(t, ParcelStatusSent, parcel.Status)
+	}
+}
+
+// TestNotAllowed проверяет ошибки при изменении и удалении отправленной посылки
+func TestNotAllowed(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.NoError(t, err)
+
+	// set address
+	err = store.SetRecipientAddress(ctx, id, "new test address")
+	require.ErrorIs(t, err, ErrAddressChangeNotAllowed)
+
+	// delete
+	err = store.Delete(ctx, id)
+	require.ErrorIs(t, err, ErrDeleteNotAllowed)
+
+	// not found
+	err = store.SetStatus(ctx, -1, ParcelStatusSent)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestNotFound проверяет ошибки при изменении и удалении несуществующей посылки
+func TestNotFound(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	err = store.Delete(ctx, id)
+	require.NoError(t, err)
+
+	// check
+	err = store.SetRecipientAddress(ctx, id, "new test address")
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	err = store.Delete(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestPoolAndClose проверяет настройку пула соединений и закрытие хранилища
+func TestPoolAndClose(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db).WithPool(PoolConfig{MaxOpenConns: 3, MaxIdleConns: 2, ConnMaxLifetime: time.Minute})
+
+	// check
+	require.Equal(t, 3, db.Stats().MaxOpenConnections)
+
+	_, err := store.GetByClient(ctx, 1)
+	require.NoError(t, err)
+
+	// close
+	require.NoError(t, store.Close())
+
+	_, err = store.GetByClient(ctx, 1)
+	require.Error(t, err)
+}
+
+// checkSoftDelete проверяет мягкое удаление, восстановление и физическое удаление
+func checkSoftDelete(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	parcel := getTestParcel()
+	parcel.Client = randRange.Intn(10_000_000)
+
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	parcel.Number = id
+	parcel.Version = 1
+
+	_, err = store.AddEvent(ctx, id, "accepted", "", time.Now())
+	require.NoError(t, err)
+
+	// delete
+	require.NoError(t, store.Delete(ctx, id))
+
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	parcels, err := store.GetByClient(ctx, parcel.Client)
+	require.NoError(t, err)
+	require.Empty(t, parcels)
+
+	err = store.SetRecipientAddress(ctx, id, "new test address")
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	err = store.Delete(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// restore
+	require.NoError(t, store.Restore(ctx, id))
+	require.NoError(t, store.Restore(ctx, id))
+
+	// удаление и восстановление меняют версию
+	parcel.Version += 2
+
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel, stored)
+
+	events, err := store.GetEvents(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// hard delete
+	require.NoError(t, store.Delete(ctx, id))
+	require.NoError(t, store.HardDelete(ctx, id))
+
+	err = store.Restore(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestSoftDelete проверяет мягкое удаление в SQLite
+func TestSoftDelete(t *testing.T) {
+	db := openTempDB(t)
+
+	checkSoftDelete(t, NewParcelStore(db).WithClock(testClock))
+}
@@ -0,0 +1,3147 @@
+// Package parcelapi provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.0 DO NOT EDIT.
+package parcelapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	ApiKeyScopes = "apiKey.Scopes"
+)
+
+// Defines values for AttachmentKind.
+const (
+	AttachmentKindPhoto     AttachmentKind = "photo"
+	AttachmentKindSignature AttachmentKind = "signature"
+)
+
+// Defines values for CancelRequestReason.
+const (
+	CustomerRequest CancelRequestReason = "customer_request"
+	Duplicate       CancelRequestReason = "duplicate"
+	InvalidAddress  CancelRequestReason = "invalid_address"
+	Other           CancelRequestReason = "other"
+	PaymentFailed   CancelRequestReason = "payment_failed"
+)
+
+// Defines values for Status.
+const (
+	Cancelled       Status = "cancelled"
+	Delivered       Status = "delivered"
+	Registered      Status = "registered"
+	ReturnRequested Status = "return_requested"
+	ReturnToSender  Status = "return_to_sender"
+	Returned        Status = "returned"
+	Returning       Status = "returning"
+	Sent            Status = "sent"
+)
+
+// Defines values for ListClientParcelsParamsSort.
+const (
+	ListClientParcelsParamsSortCreatedAt ListClientParcelsParamsSort = "created_at"
+	ListClientParcelsParamsSortNumber    ListClientParcelsParamsSort = "number"
+	ListClientParcelsParamsSortStatus    ListClientParcelsParamsSort = "status"
+	ListClientParcelsParamsSortUpdatedAt ListClientParcelsParamsSort = "updated_at"
+)
+
+// Defines values for AttachProofOfDeliveryParamsKind.
+const (
+	AttachProofOfDeliveryParamsKindPhoto     AttachProofOfDeliveryParamsKind = "photo"
+	AttachProofOfDeliveryParamsKindSignature AttachProofOfDeliveryParamsKind = "signature"
+)
+
+// Defines values for GetParcelLabelParamsFormat.
+const (
+	Code128 GetParcelLabelParamsFormat = "code128"
+	Pdf     GetParcelLabelParamsFormat = "pdf"
+	Qr      GetParcelLabelParamsFormat = "qr"
+)
+
+// AddParcelRequest defines model for AddParcelRequest.
+type AddParcelRequest struct {
+	Client           int     `json:"client"`
+	HeightMm         *int    `json:"height_mm,omitempty"`
+	LengthMm         *int    `json:"length_mm,omitempty"`
+	RecipientAddress string  `json:"recipient_address"`
+	SenderAddress    *string `json:"sender_address,omitempty"`
+
+	// ServiceClass economy, standard или express; пустой — класс по умолчанию.
+	ServiceClass *string `json:"service_class,omitempty"`
+	WeightGrams  *int    `json:"weight_grams,omitempty"`
+	WidthMm      *int    `json:"width_mm,omitempty"`
+}
+
+// Attachment defines model for Attachment.
+type Attachment struct {
+	ContentType string         `json:"content_type"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Id          int            `json:"id"`
+	Kind        AttachmentKind `json:"kind"`
+	Number      int            `json:"number"`
+	Sha256      string         `json:"sha256"`
+	Size        int64          `json:"size"`
+}
+
+// AttachmentKind defines model for Attachment.Kind.
+type AttachmentKind string
+
+// CancelRequest defines model for CancelRequest.
+type CancelRequest struct {
+	Reason CancelRequestReason `json:"reason"`
+}
+
+// CancelRequestReason defines model for CancelRequest.Reason.
+type CancelRequestReason string
+
+// ClientParcels defines model for ClientParcels.
+type ClientParcels struct {
+	union json.RawMessage
+}
+
+// DeliverRequest defines model for DeliverRequest.
+type DeliverRequest struct {
+	// Signature PNG подписи получателя в base64.
+	Signature []byte `json:"signature"`
+	SignedBy  string `json:"signed_by"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	Error string `json:"error"`
+
+	// Fields Ошибки по полям посылки, не прошедшей проверку.
+	Fields *map[string]string `json:"fields,omitempty"`
+}
+
+// MarkPaidRequest defines model for MarkPaidRequest.
+type MarkPaidRequest struct {
+	TxRef string `json:"tx_ref"`
+}
+
+// Parcel defines model for Parcel.
+type Parcel struct {
+	Amount        int64      `json:"amount"`
+	CancelReason  *string    `json:"cancel_reason,omitempty"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty"`
+	Client        int        `json:"client"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Currency      string     `json:"currency"`
+	Eta           *time.Time `json:"eta,omitempty"`
+	HasSignature  bool       `json:"has_signature"`
+	HeightMm      *int       `json:"height_mm,omitempty"`
+	LengthMm      *int       `json:"length_mm,omitempty"`
+	Number        int        `json:"number"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	PaymentStatus string     `json:"payment_status"`
+
+	// Price Стоимость доставки в копейках.
+	Price            *int64 `json:"price,omitempty"`
+	RecipientAddress string `json:"recipient_address"`
+
+	// ReturnOf Номер исходной посылки у возвратной.
+	ReturnOf      *int       `json:"return_of,omitempty"`
+	SenderAddress *string    `json:"sender_address,omitempty"`
+	ServiceClass  *string    `json:"service_class,omitempty"`
+	SignedAt      *time.Time `json:"signed_at,omitempty"`
+	SignedBy      *string    `json:"signed_by,omitempty"`
+	SlaBreachedAt *time.Time `json:"sla_breached_at,omitempty"`
+	SlaDeadline   *time.Time `json:"sla_deadline,omitempty"`
+	Status        Status     `json:"status"`
+
+	// StatusName Название статуса на языке из заголовка Accept-Language.
+	StatusName  string    `json:"status_name"`
+	TrackCode   string    `json:"track_code"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Uuid        *string   `json:"uuid,omitempty"`
+	WeightGrams *int      `json:"weight_grams,omitempty"`
+	WidthMm     *int      `json:"width_mm,omitempty"`
+}
+
+// ParcelCursorPage defines model for ParcelCursorPage.
+type ParcelCursorPage struct {
+	// NextCursor Курсор следующей страницы, на последней его нет.
+	NextCursor *string  `json:"next_cursor,omitempty"`
+	Parcels    []Parcel `json:"parcels"`
+}
+
+// ParcelList defines model for ParcelList.
+type ParcelList struct {
+	Parcels []Parcel `json:"parcels"`
+	Total   int      `json:"total"`
+}
+
+// SetAddressRequest defines model for SetAddressRequest.
+type SetAddressRequest struct {
+	Address string `json:"address"`
+
+	// Sender Менять адрес отправителя, а не получателя.
+	Sender *bool `json:"sender,omitempty"`
+}
+
+// SetStatusRequest defines model for SetStatusRequest.
+type SetStatusRequest struct {
+	Status Status `json:"status"`
+}
+
+// Status defines model for Status.
+type Status string
+
+// Cursor defines model for Cursor.
+type Cursor = string
+
+// Limit defines model for Limit.
+type Limit = int
+
+// Number defines model for Number.
+type Number = int
+
+// ListClientParcelsParams defines parameters for ListClientParcels.
+type ListClientParcelsParams struct {
+	Limit  *Limit                       `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int                         `form:"offset,omitempty" json:"offset,omitempty"`
+	Sort   *ListClientParcelsParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+	Desc   *bool                        `form:"desc,omitempty" json:"desc,omitempty"`
+
+	// Cursor next_cursor предыдущей страницы, пустой — первая страница.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListClientParcelsParamsSort defines parameters for ListClientParcels.
+type ListClientParcelsParamsSort string
+
+// ListParcelsParams defines parameters for ListParcels.
+type ListParcelsParams struct {
+	Client *int `form:"client,omitempty" json:"client,omitempty"`
+
+	// Status Посылки в любом из статусов.
+	Status *[]Status `form:"status,omitempty" json:"status,omitempty"`
+
+	// Address Подстрока адреса отправителя или получателя с учётом регистра.
+	Address      *string    `form:"address,omitempty" json:"address,omitempty"`
+	Courier      *int       `form:"courier,omitempty" json:"courier,omitempty"`
+	SlaBreached  *bool      `form:"sla_breached,omitempty" json:"sla_breached,omitempty"`
+	ServiceClass *string    `form:"service_class,omitempty" json:"service_class,omitempty"`
+	From         *time.Time `form:"from,omitempty" json:"from,omitempty"`
+	To           *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// Cursor next_cursor предыдущей страницы, пустой — первая страница.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// AddParcelParams defines parameters for AddParcel.
+type AddParcelParams struct {
+	// IdempotencyKey Повтор с тем же ключом возвращает уже созданную посылку.
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// AttachProofOfDeliveryParams defines parameters for AttachProofOfDelivery.
+type AttachProofOfDeliveryParams struct {
+	Kind *AttachProofOfDeliveryParamsKind `form:"kind,omitempty" json:"kind,omitempty"`
+}
+
+// AttachProofOfDeliveryParamsKind defines parameters for AttachProofOfDelivery.
+type AttachProofOfDeliveryParamsKind string
+
+// StreamParcelTrackingParams defines parameters for StreamParcelTracking.
+type StreamParcelTrackingParams struct {
+	// LastEventID Курсор ленты, после которого продолжить поток.
+	LastEventID *string `json:"Last-Event-ID,omitempty"`
+}
+
+// GetParcelLabelParams defines parameters for GetParcelLabel.
+type GetParcelLabelParams struct {
+	Format *GetParcelLabelParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetParcelLabelParamsFormat defines parameters for GetParcelLabel.
+type GetParcelLabelParamsFormat string
+
+// AddParcelJSONRequestBody defines body for AddParcel for application/json ContentType.
+type AddParcelJSONRequestBody = AddParcelRequest
+
+// SetParcelAddressJSONRequestBody defines body for SetParcelAddress for application/json ContentType.
+type SetParcelAddressJSONRequestBody = SetAddressRequest
+
+// CancelParcelJSONRequestBody defines body for CancelParcel for application/json ContentType.
+type CancelParcelJSONRequestBody = CancelRequest
+
+// DeliverParcelJSONRequestBody defines body for DeliverParcel for application/json ContentType.
+type DeliverParcelJSONRequestBody = DeliverRequest
+
+// MarkParcelPaidJSONRequestBody defines body for MarkParcelPaid for application/json ContentType.
+type MarkParcelPaidJSONRequestBody = MarkPaidRequest
+
+// SetParcelStatusJSONRequestBody defines body for SetParcelStatus for application/json ContentType.
+type SetParcelStatusJSONRequestBody = SetStatusRequest
+
+// AsParcelList returns the union data inside the ClientParcels as a ParcelList
+func (t ClientParcels) AsParcelList() (ParcelList, error) {
+	var body ParcelList
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromParcelList overwrites any union data inside the ClientParcels as the provided ParcelList
+func (t *ClientParcels) FromParcelList(v ParcelList) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeParcelList performs a merge with any union data inside the ClientParcels, using the provided ParcelList
+func (t *ClientParcels) MergeParcelList(v ParcelList) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsParcelCursorPage returns the union data inside the ClientParcels as a ParcelCursorPage
+func (t ClientParcels) AsParcelCursorPage() (ParcelCursorPage, error) {
+	var body ParcelCursorPage
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromParcelCursorPage overwrites any union data inside the ClientParcels as the provided ParcelCursorPage
+func (t *ClientParcels) FromParcelCursorPage(v ParcelCursorPage) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeParcelCursorPage performs a merge with any union data inside the ClientParcels, using the provided ParcelCursorPage
+func (t *ClientParcels) MergeParcelCursorPage(v ParcelCursorPage) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t ClientParcels) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *ClientParcels) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// GetAttachment request
+	GetAttachment(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListClientParcels request
+	ListClientParcels(ctx context.Context, id int, params *ListClientParcelsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListParcels request
+	ListParcels(ctx context.Context, params *ListParcelsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// AddParcelWithBody request with any body
+	AddParcelWithBody(ctx context.Context, params *AddParcelParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	AddParcel(ctx context.Context, params *AddParcelParams, body AddParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteParcel request
+	DeleteParcel(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetParcel request
+	GetParcel(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SetParcelAddressWithBody request with any body
+	SetParcelAddressWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	SetParcelAddress(ctx context.Context, number Number, body SetParcelAddressJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListAttachments request
+	ListAttachments(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// AttachProofOfDeliveryWithBody request with any body
+	AttachProofOfDeliveryWithBody(ctx context.Context, number Number, params *AttachProofOfDeliveryParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CancelParcelWithBody request with any body
+	CancelParcelWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	CancelParcel(ctx context.Context, number Number, body CancelParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeliverParcelWithBody request with any body
+	DeliverParcelWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	DeliverParcel(ctx context.Context, number Number, body DeliverParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// StreamParcelTracking request
+	StreamParcelTracking(ctx context.Context, number Number, params *StreamParcelTrackingParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetParcelLabel request
+	GetParcelLabel(ctx context.Context, number Number, params *GetParcelLabelParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// MarkParcelPaidWithBody request with any body
+	MarkParcelPaidWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	MarkParcelPaid(ctx context.Context, number Number, body MarkParcelPaidJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetParcelReturn request
+	GetParcelReturn(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CreateParcelReturn request
+	CreateParcelReturn(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SetParcelStatusWithBody request with any body
+	SetParcelStatusWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	SetParcelStatus(ctx context.Context, number Number, body SetParcelStatusJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// WatchParcel request
+	WatchParcel(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// TrackParcel request
+	TrackParcel(ctx context.Context, code string, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) GetAttachment(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetAttachmentRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListClientParcels(ctx context.Context, id int, params *ListClientParcelsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListClientParcelsRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListParcels(ctx context.Context, params *ListParcelsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListParcelsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) AddParcelWithBody(ctx context.Context, params *AddParcelParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewAddParcelRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) AddParcel(ctx context.Context, params *AddParcelParams, body AddParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewAddParcelRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteParcel(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteParcelRequest(c.Server, number)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetParcel(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetParcelRequest(c.Server, number)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetParcelAddressWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetParcelAddressRequestWithBody(c.Server, number, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetParcelAddress(ctx context.Context, number Number, body SetParcelAddressJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetParcelAddressRequest(c.Server, number, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListAttachments(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListAttachmentsRequest(c.Server, number)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) AttachProofOfDeliveryWithBody(ctx context.Context, number Number, params *AttachProofOfDeliveryParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewAttachProofOfDeliveryRequestWithBody(c.Server, number, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CancelParcelWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCancelParcelRequestWithBody(c.Server, number, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CancelParcel(ctx context.Context, number Number, body CancelParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCancelParcelRequest(c.Server, number, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeliverParcelWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeliverParcelRequestWithBody(c.Server, number, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeliverParcel(ctx context.Context, number Number, body DeliverParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeliverParcelRequest(c.Server, number, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StreamParcelTracking(ctx context.Context, number Number, params *StreamParcelTrackingParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStreamParcelTrackingRequest(c.Server, number, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetParcelLabel(ctx context.Context, number Number, params *GetParcelLabelParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetParcelLabelRequest(c.Server, number, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) MarkParcelPaidWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewMarkParcelPaidRequestWithBody(c.Server, number, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) MarkParcelPaid(ctx context.Context, number Number, body MarkParcelPaidJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewMarkParcelPaidRequest(c.Server, number, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetParcelReturn(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetParcelReturnRequest(c.Server, number)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreateParcelReturn(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreateParcelReturnRequest(c.Server, number)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetParcelStatusWithBody(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetParcelStatusRequestWithBody(c.Server, number, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetParcelStatus(ctx context.Context, number Number, body SetParcelStatusJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetParcelStatusRequest(c.Server, number, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) WatchParcel(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewWatchParcelRequest(c.Server, number)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TrackParcel(ctx context.Context, code string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTrackParcelRequest(c.Server, code)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewGetAttachmentRequest generates requests for GetAttachment
+func NewGetAttachmentRequest(server string, id int) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/attachments/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListClientParcelsRequest generates requests for ListClientParcels
+func NewListClientParcelsRequest(server string, id int, params *ListClientParcelsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/clients/%s/parcels", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Offset != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "offset", runtime.ParamLocationQuery, *params.Offset); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Sort != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sort", runtime.ParamLocationQuery, *params.Sort); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Desc != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "desc", runtime.ParamLocationQuery, *params.Desc); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListParcelsRequest generates requests for ListParcels
+func NewListParcelsRequest(server string, params *ListParcelsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Client != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "client", runtime.ParamLocationQuery, *params.Client); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Status != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "status", runtime.ParamLocationQuery, *params.Status); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Address != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "address", runtime.ParamLocationQuery, *params.Address); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Courier != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "courier", runtime.ParamLocationQuery, *params.Courier); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.SlaBreached != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "sla_breached", runtime.ParamLocationQuery, *params.SlaBreached); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.ServiceClass != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "service_class", runtime.ParamLocationQuery, *params.ServiceClass); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.From != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "from", runtime.ParamLocationQuery, *params.From); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.To != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "to", runtime.ParamLocationQuery, *params.To); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Cursor != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "cursor", runtime.ParamLocationQuery, *params.Cursor); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewAddParcelRequest calls the generic AddParcel builder with application/json body
+func NewAddParcelRequest(server string, params *AddParcelParams, body AddParcelJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewAddParcelRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewAddParcelRequestWithBody generates requests for AddParcel with any type of body
+func NewAddParcelRequestWithBody(server string, params *AddParcelParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params != nil {
+
+		if params.IdempotencyKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "Idempotency-Key", runtime.ParamLocationHeader, *params.IdempotencyKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Idempotency-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewDeleteParcelRequest generates requests for DeleteParcel
+func NewDeleteParcelRequest(server string, number Number) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetParcelRequest generates requests for GetParcel
+func NewGetParcelRequest(server string, number Number) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSetParcelAddressRequest calls the generic SetParcelAddress builder with application/json body
+func NewSetParcelAddressRequest(server string, number Number, body SetParcelAddressJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewSetParcelAddressRequestWithBody(server, number, "application/json", bodyReader)
+}
+
+// NewSetParcelAddressRequestWithBody generates requests for SetParcelAddress with any type of body
+func NewSetParcelAddressRequestWithBody(server string, number Number, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/address", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewListAttachmentsRequest generates requests for ListAttachments
+func NewListAttachmentsRequest(server string, number Number) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/attachments", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewAttachProofOfDeliveryRequestWithBody generates requests for AttachProofOfDelivery with any type of body
+func NewAttachProofOfDeliveryRequestWithBody(server string, number Number, params *AttachProofOfDeliveryParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/attachments", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Kind != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "kind", runtime.ParamLocationQuery, *params.Kind); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewCancelParcelRequest calls the generic CancelParcel builder with application/json body
+func NewCancelParcelRequest(server string, number Number, body CancelParcelJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewCancelParcelRequestWithBody(server, number, "application/json", bodyReader)
+}
+
+// NewCancelParcelRequestWithBody generates requests for CancelParcel with any type of body
+func NewCancelParcelRequestWithBody(server string, number Number, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/cancel", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDeliverParcelRequest calls the generic DeliverParcel builder with application/json body
+func NewDeliverParcelRequest(server string, number Number, body DeliverParcelJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewDeliverParcelRequestWithBody(server, number, "application/json", bodyReader)
+}
+
+// NewDeliverParcelRequestWithBody generates requests for DeliverParcel with any type of body
+func NewDeliverParcelRequestWithBody(server string, number Number, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/deliver", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewStreamParcelTrackingRequest generates requests for StreamParcelTracking
+func NewStreamParcelTrackingRequest(server string, number Number, params *StreamParcelTrackingParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/events/stream", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.LastEventID != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "Last-Event-ID", runtime.ParamLocationHeader, *params.LastEventID)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Last-Event-ID", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewGetParcelLabelRequest generates requests for GetParcelLabel
+func NewGetParcelLabelRequest(server string, number Number, params *GetParcelLabelParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/label", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Format != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "format", runtime.ParamLocationQuery, *params.Format); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewMarkParcelPaidRequest calls the generic MarkParcelPaid builder with application/json body
+func NewMarkParcelPaidRequest(server string, number Number, body MarkParcelPaidJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewMarkParcelPaidRequestWithBody(server, number, "application/json", bodyReader)
+}
+
+// NewMarkParcelPaidRequestWithBody generates requests for MarkParcelPaid with any type of body
+func NewMarkParcelPaidRequestWithBody(server string, number Number, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/payment", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetParcelReturnRequest generates requests for GetParcelReturn
+func NewGetParcelReturnRequest(server string, number Number) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/return", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewCreateParcelReturnRequest generates requests for CreateParcelReturn
+func NewCreateParcelReturnRequest(server string, number Number) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/return", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSetParcelStatusRequest calls the generic SetParcelStatus builder with application/json body
+func NewSetParcelStatusRequest(server string, number Number, body SetParcelStatusJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewSetParcelStatusRequestWithBody(server, number, "application/json", bodyReader)
+}
+
+// NewSetParcelStatusRequestWithBody generates requests for SetParcelStatus with any type of body
+func NewSetParcelStatusRequestWithBody(server string, number Number, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/status", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewWatchParcelRequest generates requests for WatchParcel
+func NewWatchParcelRequest(server string, number Number) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "number", runtime.ParamLocationPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/parcels/%s/watch", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewTrackParcelRequest generates requests for TrackParcel
+func NewTrackParcelRequest(server string, code string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "code", runtime.ParamLocationPath, code)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/track/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// GetAttachmentWithResponse request
+	GetAttachmentWithResponse(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*GetAttachmentResponse, error)
+
+	// ListClientParcelsWithResponse request
+	ListClientParcelsWithResponse(ctx context.Context, id int, params *ListClientParcelsParams, reqEditors ...RequestEditorFn) (*ListClientParcelsResponse, error)
+
+	// ListParcelsWithResponse request
+	ListParcelsWithResponse(ctx context.Context, params *ListParcelsParams, reqEditors ...RequestEditorFn) (*ListParcelsResponse, error)
+
+	// AddParcelWithBodyWithResponse request with any body
+	AddParcelWithBodyWithResponse(ctx context.Context, params *AddParcelParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*AddParcelResponse, error)
+
+	AddParcelWithResponse(ctx context.Context, params *AddParcelParams, body AddParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*AddParcelResponse, error)
+
+	// DeleteParcelWithResponse request
+	DeleteParcelWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*DeleteParcelResponse, error)
+
+	// GetParcelWithResponse request
+	GetParcelWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*GetParcelResponse, error)
+
+	// SetParcelAddressWithBodyWithResponse request with any body
+	SetParcelAddressWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetParcelAddressResponse, error)
+
+	SetParcelAddressWithResponse(ctx context.Context, number Number, body SetParcelAddressJSONRequestBody, reqEditors ...RequestEditorFn) (*SetParcelAddressResponse, error)
+
+	// ListAttachmentsWithResponse request
+	ListAttachmentsWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*ListAttachmentsResponse, error)
+
+	// AttachProofOfDeliveryWithBodyWithResponse request with any body
+	AttachProofOfDeliveryWithBodyWithResponse(ctx context.Context, number Number, params *AttachProofOfDeliveryParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*AttachProofOfDeliveryResponse, error)
+
+	// CancelParcelWithBodyWithResponse request with any body
+	CancelParcelWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CancelParcelResponse, error)
+
+	CancelParcelWithResponse(ctx context.Context, number Number, body CancelParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*CancelParcelResponse, error)
+
+	// DeliverParcelWithBodyWithResponse request with any body
+	DeliverParcelWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*DeliverParcelResponse, error)
+
+	DeliverParcelWithResponse(ctx context.Context, number Number, body DeliverParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*DeliverParcelResponse, error)
+
+	// StreamParcelTrackingWithResponse request
+	StreamParcelTrackingWithResponse(ctx context.Context, number Number, params *StreamParcelTrackingParams, reqEditors ...RequestEditorFn) (*StreamParcelTrackingResponse, error)
+
+	// GetParcelLabelWithResponse request
+	GetParcelLabelWithResponse(ctx context.Context, number Number, params *GetParcelLabelParams, reqEditors ...RequestEditorFn) (*GetParcelLabelResponse, error)
+
+	// MarkParcelPaidWithBodyWithResponse request with any body
+	MarkParcelPaidWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*MarkParcelPaidResponse, error)
+
+	MarkParcelPaidWithResponse(ctx context.Context, number Number, body MarkParcelPaidJSONRequestBody, reqEditors ...RequestEditorFn) (*MarkParcelPaidResponse, error)
+
+	// GetParcelReturnWithResponse request
+	GetParcelReturnWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*GetParcelReturnResponse, error)
+
+	// CreateParcelReturnWithResponse request
+	CreateParcelReturnWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*CreateParcelReturnResponse, error)
+
+	// SetParcelStatusWithBodyWithResponse request with any body
+	SetParcelStatusWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetParcelStatusResponse, error)
+
+	SetParcelStatusWithResponse(ctx context.Context, number Number, body SetParcelStatusJSONRequestBody, reqEditors ...RequestEditorFn) (*SetParcelStatusResponse, error)
+
+	// WatchParcelWithResponse request
+	WatchParcelWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*WatchParcelResponse, error)
+
+	// TrackParcelWithResponse request
+	TrackParcelWithResponse(ctx context.Context, code string, reqEditors ...RequestEditorFn) (*TrackParcelResponse, error)
+}
+
+type GetAttachmentResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetAttachmentResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetAttachmentResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListClientParcelsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ClientParcels
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ListClientParcelsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListClientParcelsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListParcelsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ParcelCursorPage
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ListParcelsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListParcelsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type AddParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *Parcel
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r AddParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r AddParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Parcel
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SetParcelAddressResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SetParcelAddressResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SetParcelAddressResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListAttachmentsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Attachment
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ListAttachmentsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListAttachmentsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type AttachProofOfDeliveryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *Attachment
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r AttachProofOfDeliveryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r AttachProofOfDeliveryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CancelParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r CancelParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CancelParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeliverParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r DeliverParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeliverParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StreamParcelTrackingResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r StreamParcelTrackingResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StreamParcelTrackingResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetParcelLabelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetParcelLabelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetParcelLabelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type MarkParcelPaidResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r MarkParcelPaidResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r MarkParcelPaidResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetParcelReturnResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Parcel
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetParcelReturnResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetParcelReturnResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CreateParcelReturnResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *Parcel
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r CreateParcelReturnResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CreateParcelReturnResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SetParcelStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SetParcelStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SetParcelStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type WatchParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r WatchParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r WatchParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type TrackParcelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Parcel
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r TrackParcelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r TrackParcelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// GetAttachmentWithResponse request returning *GetAttachmentResponse
+func (c *ClientWithResponses) GetAttachmentWithResponse(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*GetAttachmentResponse, error) {
+	rsp, err := c.GetAttachment(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetAttachmentResponse(rsp)
+}
+
+// ListClientParcelsWithResponse request returning *ListClientParcelsResponse
+func (c *ClientWithResponses) ListClientParcelsWithResponse(ctx context.Context, id int, params *ListClientParcelsParams, reqEditors ...RequestEditorFn) (*ListClientParcelsResponse, error) {
+	rsp, err := c.ListClientParcels(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListClientParcelsResponse(rsp)
+}
+
+// ListParcelsWithResponse request returning *ListParcelsResponse
+func (c *ClientWithResponses) ListParcelsWithResponse(ctx context.Context, params *ListParcelsParams, reqEditors ...RequestEditorFn) (*ListParcelsResponse, error) {
+	rsp, err := c.ListParcels(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListParcelsResponse(rsp)
+}
+
+// AddParcelWithBodyWithResponse request with arbitrary body returning *AddParcelResponse
+func (c *ClientWithResponses) AddParcelWithBodyWithResponse(ctx context.Context, params *AddParcelParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*AddParcelResponse, error) {
+	rsp, err := c.AddParcelWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAddParcelResponse(rsp)
+}
+
+func (c *ClientWithResponses) AddParcelWithResponse(ctx context.Context, params *AddParcelParams, body AddParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*AddParcelResponse, error) {
+	rsp, err := c.AddParcel(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAddParcelResponse(rsp)
+}
+
+// DeleteParcelWithResponse request returning *DeleteParcelResponse
+func (c *ClientWithResponses) DeleteParcelWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*DeleteParcelResponse, error) {
+	rsp, err := c.DeleteParcel(ctx, number, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteParcelResponse(rsp)
+}
+
+// GetParcelWithResponse request returning *GetParcelResponse
+func (c *ClientWithResponses) GetParcelWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*GetParcelResponse, error) {
+	rsp, err := c.GetParcel(ctx, number, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetParcelResponse(rsp)
+}
+
+// SetParcelAddressWithBodyWithResponse request with arbitrary body returning *SetParcelAddressResponse
+func (c *ClientWithResponses) SetParcelAddressWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetParcelAddressResponse, error) {
+	rsp, err := c.SetParcelAddressWithBody(ctx, number, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetParcelAddressResponse(rsp)
+}
+
+func (c *ClientWithResponses) SetParcelAddressWithResponse(ctx context.Context, number Number, body SetParcelAddressJSONRequestBody, reqEditors ...RequestEditorFn) (*SetParcelAddressResponse, error) {
+	rsp, err := c.SetParcelAddress(ctx, number, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetParcelAddressResponse(rsp)
+}
+
+// ListAttachmentsWithResponse request returning *ListAttachmentsResponse
+func (c *ClientWithResponses) ListAttachmentsWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*ListAttachmentsResponse, error) {
+	rsp, err := c.ListAttachments(ctx, number, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListAttachmentsResponse(rsp)
+}
+
+// AttachProofOfDeliveryWithBodyWithResponse request with arbitrary body returning *AttachProofOfDeliveryResponse
+func (c *ClientWithResponses) AttachProofOfDeliveryWithBodyWithResponse(ctx context.Context, number Number, params *AttachProofOfDeliveryParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*AttachProofOfDeliveryResponse, error) {
+	rsp, err := c.AttachProofOfDeliveryWithBody(ctx, number, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAttachProofOfDeliveryResponse(rsp)
+}
+
+// CancelParcelWithBodyWithResponse request with arbitrary body returning *CancelParcelResponse
+func (c *ClientWithResponses) CancelParcelWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CancelParcelResponse, error) {
+	rsp, err := c.CancelParcelWithBody(ctx, number, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCancelParcelResponse(rsp)
+}
+
+func (c *ClientWithResponses) CancelParcelWithResponse(ctx context.Context, number Number, body CancelParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*CancelParcelResponse, error) {
+	rsp, err := c.CancelParcel(ctx, number, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCancelParcelResponse(rsp)
+}
+
+// DeliverParcelWithBodyWithResponse request with arbitrary body returning *DeliverParcelResponse
+func (c *ClientWithResponses) DeliverParcelWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*DeliverParcelResponse, error) {
+	rsp, err := c.DeliverParcelWithBody(ctx, number, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeliverParcelResponse(rsp)
+}
+
+func (c *ClientWithResponses) DeliverParcelWithResponse(ctx context.Context, number Number, body DeliverParcelJSONRequestBody, reqEditors ...RequestEditorFn) (*DeliverParcelResponse, error) {
+	rsp, err := c.DeliverParcel(ctx, number, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeliverParcelResponse(rsp)
+}
+
+// StreamParcelTrackingWithResponse request returning *StreamParcelTrackingResponse
+func (c *ClientWithResponses) StreamParcelTrackingWithResponse(ctx context.Context, number Number, params *StreamParcelTrackingParams, reqEditors ...RequestEditorFn) (*StreamParcelTrackingResponse, error) {
+	rsp, err := c.StreamParcelTracking(ctx, number, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStreamParcelTrackingResponse(rsp)
+}
+
+// GetParcelLabelWithResponse request returning *GetParcelLabelResponse
+func (c *ClientWithResponses) GetParcelLabelWithResponse(ctx context.Context, number Number, params *GetParcelLabelParams, reqEditors ...RequestEditorFn) (*GetParcelLabelResponse, error) {
+	rsp, err := c.GetParcelLabel(ctx, number, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetParcelLabelResponse(rsp)
+}
+
+// MarkParcelPaidWithBodyWithResponse request with arbitrary body returning *MarkParcelPaidResponse
+func (c *ClientWithResponses) MarkParcelPaidWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*MarkParcelPaidResponse, error) {
+	rsp, err := c.MarkParcelPaidWithBody(ctx, number, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMarkParcelPaidResponse(rsp)
+}
+
+func (c *ClientWithResponses) MarkParcelPaidWithResponse(ctx context.Context, number Number, body MarkParcelPaidJSONRequestBody, reqEditors ...RequestEditorFn) (*MarkParcelPaidResponse, error) {
+	rsp, err := c.MarkParcelPaid(ctx, number, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMarkParcelPaidResponse(rsp)
+}
+
+// GetParcelReturnWithResponse request returning *GetParcelReturnResponse
+func (c *ClientWithResponses) GetParcelReturnWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*GetParcelReturnResponse, error) {
+	rsp, err := c.GetParcelReturn(ctx, number, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetParcelReturnResponse(rsp)
+}
+
+// CreateParcelReturnWithResponse request returning *CreateParcelReturnResponse
+func (c *ClientWithResponses) CreateParcelReturnWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*CreateParcelReturnResponse, error) {
+	rsp, err := c.CreateParcelReturn(ctx, number, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreateParcelReturnResponse(rsp)
+}
+
+// SetParcelStatusWithBodyWithResponse request with arbitrary body returning *SetParcelStatusResponse
+func (c *ClientWithResponses) SetParcelStatusWithBodyWithResponse(ctx context.Context, number Number, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetParcelStatusResponse, error) {
+	rsp, err := c.SetParcelStatusWithBody(ctx, number, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetParcelStatusResponse(rsp)
+}
+
+func (c *ClientWithResponses) SetParcelStatusWithResponse(ctx context.Context, number Number, body SetParcelStatusJSONRequestBody, reqEditors ...RequestEditorFn) (*SetParcelStatusResponse, error) {
+	rsp, err := c.SetParcelStatus(ctx, number, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetParcelStatusResponse(rsp)
+}
+
+// WatchParcelWithResponse request returning *WatchParcelResponse
+func (c *ClientWithResponses) WatchParcelWithResponse(ctx context.Context, number Number, reqEditors ...RequestEditorFn) (*WatchParcelResponse, error) {
+	rsp, err := c.WatchParcel(ctx, number, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWatchParcelResponse(rsp)
+}
+
+// TrackParcelWithResponse request returning *TrackParcelResponse
+func (c *ClientWithResponses) TrackParcelWithResponse(ctx context.Context, code string, reqEditors ...RequestEditorFn) (*TrackParcelResponse, error) {
+	rsp, err := c.TrackParcel(ctx, code, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrackParcelResponse(rsp)
+}
+
+// ParseGetAttachmentResponse parses an HTTP response from a GetAttachmentWithResponse call
+func ParseGetAttachmentResponse(rsp *http.Response) (*GetAttachmentResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAttachmentResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListClientParcelsResponse parses an HTTP response from a ListClientParcelsWithResponse call
+func ParseListClientParcelsResponse(rsp *http.Response) (*ListClientParcelsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListClientParcelsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ClientParcels
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListParcelsResponse parses an HTTP response from a ListParcelsWithResponse call
+func ParseListParcelsResponse(rsp *http.Response) (*ListParcelsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListParcelsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ParcelCursorPage
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseAddParcelResponse parses an HTTP response from a AddParcelWithResponse call
+func ParseAddParcelResponse(rsp *http.Response) (*AddParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &AddParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest Parcel
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteParcelResponse parses an HTTP response from a DeleteParcelWithResponse call
+func ParseDeleteParcelResponse(rsp *http.Response) (*DeleteParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetParcelResponse parses an HTTP response from a GetParcelWithResponse call
+func ParseGetParcelResponse(rsp *http.Response) (*GetParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Parcel
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSetParcelAddressResponse parses an HTTP response from a SetParcelAddressWithResponse call
+func ParseSetParcelAddressResponse(rsp *http.Response) (*SetParcelAddressResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SetParcelAddressResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListAttachmentsResponse parses an HTTP response from a ListAttachmentsWithResponse call
+func ParseListAttachmentsResponse(rsp *http.Response) (*ListAttachmentsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListAttachmentsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Attachment
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseAttachProofOfDeliveryResponse parses an HTTP response from a AttachProofOfDeliveryWithResponse call
+func ParseAttachProofOfDeliveryResponse(rsp *http.Response) (*AttachProofOfDeliveryResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &AttachProofOfDeliveryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest Attachment
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCancelParcelResponse parses an HTTP response from a CancelParcelWithResponse call
+func ParseCancelParcelResponse(rsp *http.Response) (*CancelParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CancelParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeliverParcelResponse parses an HTTP response from a DeliverParcelWithResponse call
+func ParseDeliverParcelResponse(rsp *http.Response) (*DeliverParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeliverParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStreamParcelTrackingResponse parses an HTTP response from a StreamParcelTrackingWithResponse call
+func ParseStreamParcelTrackingResponse(rsp *http.Response) (*StreamParcelTrackingResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StreamParcelTrackingResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetParcelLabelResponse parses an HTTP response from a GetParcelLabelWithResponse call
+func ParseGetParcelLabelResponse(rsp *http.Response) (*GetParcelLabelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetParcelLabelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseMarkParcelPaidResponse parses an HTTP response from a MarkParcelPaidWithResponse call
+func ParseMarkParcelPaidResponse(rsp *http.Response) (*MarkParcelPaidResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &MarkParcelPaidResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetParcelReturnResponse parses an HTTP response from a GetParcelReturnWithResponse call
+func ParseGetParcelReturnResponse(rsp *http.Response) (*GetParcelReturnResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetParcelReturnResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Parcel
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCreateParcelReturnResponse parses an HTTP response from a CreateParcelReturnWithResponse call
+func ParseCreateParcelReturnResponse(rsp *http.Response) (*CreateParcelReturnResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CreateParcelReturnResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest Parcel
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSetParcelStatusResponse parses an HTTP response from a SetParcelStatusWithResponse call
+func ParseSetParcelStatusResponse(rsp *http.Response) (*SetParcelStatusResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SetParcelStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseWatchParcelResponse parses an HTTP response from a WatchParcelWithResponse call
+func ParseWatchParcelResponse(rsp *http.Response) (*WatchParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &WatchParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseTrackParcelResponse parses an HTTP response from a TrackParcelWithResponse call
+func ParseTrackParcelResponse(rsp *http.Response) (*TrackParcelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TrackParcelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Parcel
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
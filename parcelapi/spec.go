@@ -0,0 +1,11 @@
+// Package parcelapi — клиент REST API трекера посылок, сгенерированный
+// из openapi.yaml; см. NewClientWithResponses. Сервер отдаёт ту же
+// спецификацию на /openapi.json.
+package parcelapi
+
+import _ "embed"
+
+// Spec — спецификация OpenAPI 3 REST API в YAML.
+//
+//go:embed openapi.yaml
+var Spec []byte
@@ -0,0 +1,54 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package parcelgql
+
+type Client struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	Phone   string    `json:"phone"`
+	Parcels []*Parcel `json:"parcels"`
+}
+
+type Mutation struct {
+}
+
+type Parcel struct {
+	Number   int `json:"number"`
+	ClientID int `json:"clientId"`
+	// Клиент посылки; null, если хранилище не ведёт клиентов.
+	Client           *Client          `json:"client,omitempty"`
+	Status           string           `json:"status"`
+	SenderAddress    string           `json:"senderAddress"`
+	RecipientAddress string           `json:"recipientAddress"`
+	TrackCode        string           `json:"trackCode"`
+	CreatedAt        string           `json:"createdAt"`
+	UpdatedAt        string           `json:"updatedAt"`
+	Eta              *string          `json:"eta,omitempty"`
+	WeightGrams      int              `json:"weightGrams"`
+	PaymentStatus    string           `json:"paymentStatus"`
+	History          []*StatusChange  `json:"history"`
+	Events           []*TrackingEvent `json:"events"`
+}
+
+// Страница посылок; следующую отдаёт запрос с after: nextAfter, на последней nextAfter нет.
+type ParcelPage struct {
+	Parcels   []*Parcel `json:"parcels"`
+	NextAfter *int      `json:"nextAfter,omitempty"`
+}
+
+type Query struct {
+}
+
+type StatusChange struct {
+	OldStatus string `json:"oldStatus"`
+	NewStatus string `json:"newStatus"`
+	ChangedAt string `json:"changedAt"`
+}
+
+type TrackingEvent struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	OccurredAt  string `json:"occurredAt"`
+}
@@ -0,0 +1,1167 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: parcel.proto
+
+package parcelpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Parcel struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Number           int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Client           int64                  `protobuf:"varint,2,opt,name=client,proto3" json:"client,omitempty"`
+	Status           string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	RecipientAddress string                 `protobuf:"bytes,4,opt,name=recipient_address,json=recipientAddress,proto3" json:"recipient_address,omitempty"`
+	CreatedAt        string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TrackCode        string                 `protobuf:"bytes,6,opt,name=track_code,json=trackCode,proto3" json:"track_code,omitempty"`
+	// пустой, если хранилище работает без UUID
+	Uuid      string `protobuf:"bytes,7,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	UpdatedAt string `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// пустой у посылок, зарегистрированных без адреса отправителя
+	SenderAddress string `protobuf:"bytes,9,opt,name=sender_address,json=senderAddress,proto3" json:"sender_address,omitempty"`
+	// вес в граммах и габариты в миллиметрах, 0 — не измерены
+	WeightGrams int32 `protobuf:"varint,10,opt,name=weight_grams,json=weightGrams,proto3" json:"weight_grams,omitempty"`
+	LengthMm    int32 `protobuf:"varint,11,opt,name=length_mm,json=lengthMm,proto3" json:"length_mm,omitempty"`
+	WidthMm     int32 `protobuf:"varint,12,opt,name=width_mm,json=widthMm,proto3" json:"width_mm,omitempty"`
+	HeightMm    int32 `protobuf:"varint,13,opt,name=height_mm,json=heightMm,proto3" json:"height_mm,omitempty"`
+	// стоимость доставки в копейках, 0 — тариф не задан
+	Price int64 `protobuf:"varint,14,opt,name=price,proto3" json:"price,omitempty"`
+	// сумма к оплате в минимальных единицах currency
+	Amount   int64  `protobuf:"varint,15,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency string `protobuf:"bytes,16,opt,name=currency,proto3" json:"currency,omitempty"`
+	// unpaid или paid
+	PaymentStatus string `protobuf:"bytes,17,opt,name=payment_status,json=paymentStatus,proto3" json:"payment_status,omitempty"`
+	// пустой у неоплаченной посылки
+	PaidAt        string `protobuf:"bytes,18,opt,name=paid_at,json=paidAt,proto3" json:"paid_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Parcel) Reset() {
+	*x = Parcel{}
+	mi := &file_parcel_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Parcel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Parcel) ProtoMessage() {}
+
+func (x *Parcel) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Parcel.ProtoReflect.Descriptor instead.
+func (*Parcel) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Parcel) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Parcel) GetClient() int64 {
+	if x != nil {
+		return x.Client
+	}
+	return 0
+}
+
+func (x *Parcel) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Parcel) GetRecipientAddress() string {
+	if x != nil {
+		return x.RecipientAddress
+	}
+	return ""
+}
+
+func (x *Parcel) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Parcel) GetTrackCode() string {
+	if x != nil {
+		return x.TrackCode
+	}
+	return ""
+}
+
+func (x *Parcel) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Parcel) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Parcel) GetSenderAddress() string {
+	if x != nil {
+		return x.SenderAddress
+	}
+	return ""
+}
+
+func (x *Parcel) GetWeightGrams() int32 {
+	if x != nil {
+		return x.WeightGrams
+	}
+	return 0
+}
+
+func (x *Parcel) GetLengthMm() int32 {
+	if x != nil {
+		return x.LengthMm
+	}
+	return 0
+}
+
+func (x *Parcel) GetWidthMm() int32 {
+	if x != nil {
+		return x.WidthMm
+	}
+	return 0
+}
+
+func (x *Parcel) GetHeightMm() int32 {
+	if x != nil {
+		return x.HeightMm
+	}
+	return 0
+}
+
+func (x *Parcel) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Parcel) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Parcel) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Parcel) GetPaymentStatus() string {
+	if x != nil {
+		return x.PaymentStatus
+	}
+	return ""
+}
+
+func (x *Parcel) GetPaidAt() string {
+	if x != nil {
+		return x.PaidAt
+	}
+	return ""
+}
+
+type AddRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Client           int64                  `protobuf:"varint,1,opt,name=client,proto3" json:"client,omitempty"`
+	RecipientAddress string                 `protobuf:"bytes,2,opt,name=recipient_address,json=recipientAddress,proto3" json:"recipient_address,omitempty"`
+	SenderAddress    string                 `protobuf:"bytes,3,opt,name=sender_address,json=senderAddress,proto3" json:"sender_address,omitempty"`
+	WeightGrams      int32                  `protobuf:"varint,4,opt,name=weight_grams,json=weightGrams,proto3" json:"weight_grams,omitempty"`
+	LengthMm         int32                  `protobuf:"varint,5,opt,name=length_mm,json=lengthMm,proto3" json:"length_mm,omitempty"`
+	WidthMm          int32                  `protobuf:"varint,6,opt,name=width_mm,json=widthMm,proto3" json:"width_mm,omitempty"`
+	HeightMm         int32                  `protobuf:"varint,7,opt,name=height_mm,json=heightMm,proto3" json:"height_mm,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AddRequest) Reset() {
+	*x = AddRequest{}
+	mi := &file_parcel_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRequest) ProtoMessage() {}
+
+func (x *AddRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRequest.ProtoReflect.Descriptor instead.
+func (*AddRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddRequest) GetClient() int64 {
+	if x != nil {
+		return x.Client
+	}
+	return 0
+}
+
+func (x *AddRequest) GetRecipientAddress() string {
+	if x != nil {
+		return x.RecipientAddress
+	}
+	return ""
+}
+
+func (x *AddRequest) GetSenderAddress() string {
+	if x != nil {
+		return x.SenderAddress
+	}
+	return ""
+}
+
+func (x *AddRequest) GetWeightGrams() int32 {
+	if x != nil {
+		return x.WeightGrams
+	}
+	return 0
+}
+
+func (x *AddRequest) GetLengthMm() int32 {
+	if x != nil {
+		return x.LengthMm
+	}
+	return 0
+}
+
+func (x *AddRequest) GetWidthMm() int32 {
+	if x != nil {
+		return x.WidthMm
+	}
+	return 0
+}
+
+func (x *AddRequest) GetHeightMm() int32 {
+	if x != nil {
+		return x.HeightMm
+	}
+	return 0
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_parcel_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type GetByTrackCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackCode     string                 `protobuf:"bytes,1,opt,name=track_code,json=trackCode,proto3" json:"track_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetByTrackCodeRequest) Reset() {
+	*x = GetByTrackCodeRequest{}
+	mi := &file_parcel_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetByTrackCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetByTrackCodeRequest) ProtoMessage() {}
+
+func (x *GetByTrackCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetByTrackCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetByTrackCodeRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetByTrackCodeRequest) GetTrackCode() string {
+	if x != nil {
+		return x.TrackCode
+	}
+	return ""
+}
+
+type ListByClientRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Client        int64                  `protobuf:"varint,1,opt,name=client,proto3" json:"client,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	SortBy        string                 `protobuf:"bytes,4,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	Desc          bool                   `protobuf:"varint,5,opt,name=desc,proto3" json:"desc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListByClientRequest) Reset() {
+	*x = ListByClientRequest{}
+	mi := &file_parcel_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListByClientRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListByClientRequest) ProtoMessage() {}
+
+func (x *ListByClientRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListByClientRequest.ProtoReflect.Descriptor instead.
+func (*ListByClientRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListByClientRequest) GetClient() int64 {
+	if x != nil {
+		return x.Client
+	}
+	return 0
+}
+
+func (x *ListByClientRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListByClientRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListByClientRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListByClientRequest) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
+type ListByClientResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parcels       []*Parcel              `protobuf:"bytes,1,rep,name=parcels,proto3" json:"parcels,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListByClientResponse) Reset() {
+	*x = ListByClientResponse{}
+	mi := &file_parcel_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListByClientResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListByClientResponse) ProtoMessage() {}
+
+func (x *ListByClientResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListByClientResponse.ProtoReflect.Descriptor instead.
+func (*ListByClientResponse) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListByClientResponse) GetParcels() []*Parcel {
+	if x != nil {
+		return x.Parcels
+	}
+	return nil
+}
+
+func (x *ListByClientResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ListByClientStreamRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Client int64                  `protobuf:"varint,1,opt,name=client,proto3" json:"client,omitempty"`
+	// пустой — посылки в любом статусе
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// продолжить после посылки с этим номером, 0 — с начала
+	AfterNumber   int64 `protobuf:"varint,3,opt,name=after_number,json=afterNumber,proto3" json:"after_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListByClientStreamRequest) Reset() {
+	*x = ListByClientStreamRequest{}
+	mi := &file_parcel_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListByClientStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListByClientStreamRequest) ProtoMessage() {}
+
+func (x *ListByClientStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListByClientStreamRequest.ProtoReflect.Descriptor instead.
+func (*ListByClientStreamRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListByClientStreamRequest) GetClient() int64 {
+	if x != nil {
+		return x.Client
+	}
+	return 0
+}
+
+func (x *ListByClientStreamRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListByClientStreamRequest) GetAfterNumber() int64 {
+	if x != nil {
+		return x.AfterNumber
+	}
+	return 0
+}
+
+type SetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStatusRequest) Reset() {
+	*x = SetStatusRequest{}
+	mi := &file_parcel_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStatusRequest) ProtoMessage() {}
+
+func (x *SetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStatusRequest.ProtoReflect.Descriptor instead.
+func (*SetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetStatusRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *SetStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type SetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStatusResponse) Reset() {
+	*x = SetStatusResponse{}
+	mi := &file_parcel_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStatusResponse) ProtoMessage() {}
+
+func (x *SetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStatusResponse.ProtoReflect.Descriptor instead.
+func (*SetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{8}
+}
+
+type StatusUpdateResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Number int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// код gRPC, как у SetStatus; OK — статус изменён
+	Code          int32  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusUpdateResult) Reset() {
+	*x = StatusUpdateResult{}
+	mi := &file_parcel_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusUpdateResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusUpdateResult) ProtoMessage() {}
+
+func (x *StatusUpdateResult) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusUpdateResult.ProtoReflect.Descriptor instead.
+func (*StatusUpdateResult) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StatusUpdateResult) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *StatusUpdateResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *StatusUpdateResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type SetAddressRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Number  int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Address string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// true меняет адрес отправителя, иначе меняется адрес получателя
+	Sender        bool `protobuf:"varint,3,opt,name=sender,proto3" json:"sender,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAddressRequest) Reset() {
+	*x = SetAddressRequest{}
+	mi := &file_parcel_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAddressRequest) ProtoMessage() {}
+
+func (x *SetAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAddressRequest.ProtoReflect.Descriptor instead.
+func (*SetAddressRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetAddressRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *SetAddressRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SetAddressRequest) GetSender() bool {
+	if x != nil {
+		return x.Sender
+	}
+	return false
+}
+
+type SetAddressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAddressResponse) Reset() {
+	*x = SetAddressResponse{}
+	mi := &file_parcel_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAddressResponse) ProtoMessage() {}
+
+func (x *SetAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAddressResponse.ProtoReflect.Descriptor instead.
+func (*SetAddressResponse) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{11}
+}
+
+type MarkPaidRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Number int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// идентификатор транзакции в платёжной системе
+	TxRef         string `protobuf:"bytes,2,opt,name=tx_ref,json=txRef,proto3" json:"tx_ref,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkPaidRequest) Reset() {
+	*x = MarkPaidRequest{}
+	mi := &file_parcel_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkPaidRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkPaidRequest) ProtoMessage() {}
+
+func (x *MarkPaidRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkPaidRequest.ProtoReflect.Descriptor instead.
+func (*MarkPaidRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MarkPaidRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *MarkPaidRequest) GetTxRef() string {
+	if x != nil {
+		return x.TxRef
+	}
+	return ""
+}
+
+type MarkPaidResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkPaidResponse) Reset() {
+	*x = MarkPaidResponse{}
+	mi := &file_parcel_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkPaidResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkPaidResponse) ProtoMessage() {}
+
+func (x *MarkPaidResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkPaidResponse.ProtoReflect.Descriptor instead.
+func (*MarkPaidResponse) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{13}
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_parcel_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_parcel_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parcel_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_parcel_proto_rawDescGZIP(), []int{15}
+}
+
+var File_parcel_proto protoreflect.FileDescriptor
+
+const file_parcel_proto_rawDesc = "" +
+	"\n" +
+	"\fparcel.proto\x12\tparcel.v1\"\x97\x04\n" +
+	"\x06Parcel\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\x12\x16\n" +
+	"\x06client\x18\x02 \x01(\x03R\x06client\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12+\n" +
+	"\x11recipient_address\x18\x04 \x01(\tR\x10recipientAddress\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"track_code\x18\x06 \x01(\tR\ttrackCode\x12\x12\n" +
+	"\x04uuid\x18\a \x01(\tR\x04uuid\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\tR\tupdatedAt\x12%\n" +
+	"\x0esender_address\x18\t \x01(\tR\rsenderAddress\x12!\n" +
+	"\fweight_grams\x18\n" +
+	" \x01(\x05R\vweightGrams\x12\x1b\n" +
+	"\tlength_mm\x18\v \x01(\x05R\blengthMm\x12\x19\n" +
+	"\bwidth_mm\x18\f \x01(\x05R\awidthMm\x12\x1b\n" +
+	"\theight_mm\x18\r \x01(\x05R\bheightMm\x12\x14\n" +
+	"\x05price\x18\x0e \x01(\x03R\x05price\x12\x16\n" +
+	"\x06amount\x18\x0f \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x10 \x01(\tR\bcurrency\x12%\n" +
+	"\x0epayment_status\x18\x11 \x01(\tR\rpaymentStatus\x12\x17\n" +
+	"\apaid_at\x18\x12 \x01(\tR\x06paidAt\"\xf0\x01\n" +
+	"\n" +
+	"AddRequest\x12\x16\n" +
+	"\x06client\x18\x01 \x01(\x03R\x06client\x12+\n" +
+	"\x11recipient_address\x18\x02 \x01(\tR\x10recipientAddress\x12%\n" +
+	"\x0esender_address\x18\x03 \x01(\tR\rsenderAddress\x12!\n" +
+	"\fweight_grams\x18\x04 \x01(\x05R\vweightGrams\x12\x1b\n" +
+	"\tlength_mm\x18\x05 \x01(\x05R\blengthMm\x12\x19\n" +
+	"\bwidth_mm\x18\x06 \x01(\x05R\awidthMm\x12\x1b\n" +
+	"\theight_mm\x18\a \x01(\x05R\bheightMm\"$\n" +
+	"\n" +
+	"GetRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\"6\n" +
+	"\x15GetByTrackCodeRequest\x12\x1d\n" +
+	"\n" +
+	"track_code\x18\x01 \x01(\tR\ttrackCode\"\x88\x01\n" +
+	"\x13ListByClientRequest\x12\x16\n" +
+	"\x06client\x18\x01 \x01(\x03R\x06client\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x17\n" +
+	"\asort_by\x18\x04 \x01(\tR\x06sortBy\x12\x12\n" +
+	"\x04desc\x18\x05 \x01(\bR\x04desc\"Y\n" +
+	"\x14ListByClientResponse\x12+\n" +
+	"\aparcels\x18\x01 \x03(\v2\x11.parcel.v1.ParcelR\aparcels\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"n\n" +
+	"\x19ListByClientStreamRequest\x12\x16\n" +
+	"\x06client\x18\x01 \x01(\x03R\x06client\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12!\n" +
+	"\fafter_number\x18\x03 \x01(\x03R\vafterNumber\"B\n" +
+	"\x10SetStatusRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\x13\n" +
+	"\x11SetStatusResponse\"Z\n" +
+	"\x12StatusUpdateResult\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"]\n" +
+	"\x11SetAddressRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06sender\x18\x03 \x01(\bR\x06sender\"\x14\n" +
+	"\x12SetAddressResponse\"@\n" +
+	"\x0fMarkPaidRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\x12\x15\n" +
+	"\x06tx_ref\x18\x02 \x01(\tR\x05txRef\"\x12\n" +
+	"\x10MarkPaidResponse\"'\n" +
+	"\rDeleteRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\"\x10\n" +
+	"\x0eDeleteResponse2\xc8\x05\n" +
+	"\rParcelService\x12/\n" +
+	"\x03Add\x12\x15.parcel.v1.AddRequest\x1a\x11.parcel.v1.Parcel\x12/\n" +
+	"\x03Get\x12\x15.parcel.v1.GetRequest\x1a\x11.parcel.v1.Parcel\x12E\n" +
+	"\x0eGetByTrackCode\x12 .parcel.v1.GetByTrackCodeRequest\x1a\x11.parcel.v1.Parcel\x12O\n" +
+	"\fListByClient\x12\x1e.parcel.v1.ListByClientRequest\x1a\x1f.parcel.v1.ListByClientResponse\x12O\n" +
+	"\x12ListByClientStream\x12$.parcel.v1.ListByClientStreamRequest\x1a\x11.parcel.v1.Parcel0\x01\x12F\n" +
+	"\tSetStatus\x12\x1b.parcel.v1.SetStatusRequest\x1a\x1c.parcel.v1.SetStatusResponse\x12U\n" +
+	"\x13StreamStatusUpdates\x12\x1b.parcel.v1.SetStatusRequest\x1a\x1d.parcel.v1.StatusUpdateResult(\x010\x01\x12I\n" +
+	"\n" +
+	"SetAddress\x12\x1c.parcel.v1.SetAddressRequest\x1a\x1d.parcel.v1.SetAddressResponse\x12C\n" +
+	"\bMarkPaid\x12\x1a.parcel.v1.MarkPaidRequest\x1a\x1b.parcel.v1.MarkPaidResponse\x12=\n" +
+	"\x06Delete\x12\x18.parcel.v1.DeleteRequest\x1a\x19.parcel.v1.DeleteResponseB6Z4github.com/Yandex-Practicum/go-db-sql-final/parcelpbb\x06proto3"
+
+var (
+	file_parcel_proto_rawDescOnce sync.Once
+	file_parcel_proto_rawDescData []byte
+)
+
+func file_parcel_proto_rawDescGZIP() []byte {
+	file_parcel_proto_rawDescOnce.Do(func() {
+		file_parcel_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_parcel_proto_rawDesc), len(file_parcel_proto_rawDesc)))
+	})
+	return file_parcel_proto_rawDescData
+}
+
+var file_parcel_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_parcel_proto_goTypes = []any{
+	(*Parcel)(nil),                    // 0: parcel.v1.Parcel
+	(*AddRequest)(nil),                // 1: parcel.v1.AddRequest
+	(*GetRequest)(nil),                // 2: parcel.v1.GetRequest
+	(*GetByTrackCodeRequest)(nil),     // 3: parcel.v1.GetByTrackCodeRequest
+	(*ListByClientRequest)(nil),       // 4: parcel.v1.ListByClientRequest
+	(*ListByClientResponse)(nil),      // 5: parcel.v1.ListByClientResponse
+	(*ListByClientStreamRequest)(nil), // 6: parcel.v1.ListByClientStreamRequest
+	(*SetStatusRequest)(nil),          // 7: parcel.v1.SetStatusRequest
+	(*SetStatusResponse)(nil),         // 8: parcel.v1.SetStatusResponse
+	(*StatusUpdateResult)(nil),        // 9: parcel.v1.StatusUpdateResult
+	(*SetAddressRequest)(nil),         // 10: parcel.v1.SetAddressRequest
+	(*SetAddressResponse)(nil),        // 11: parcel.v1.SetAddressResponse
+	(*MarkPaidRequest)(nil),           // 12: parcel.v1.MarkPaidRequest
+	(*MarkPaidResponse)(nil),          // 13: parcel.v1.MarkPaidResponse
+	(*DeleteRequest)(nil),             // 14: parcel.v1.DeleteRequest
+	(*DeleteResponse)(nil),            // 15: parcel.v1.DeleteResponse
+}
+var file_parcel_proto_depIdxs = []int32{
+	0,  // 0: parcel.v1.ListByClientResponse.parcels:type_name -> parcel.v1.Parcel
+	1,  // 1: parcel.v1.ParcelService.Add:input_type -> parcel.v1.AddRequest
+	2,  // 2: parcel.v1.ParcelService.Get:input_type -> parcel.v1.GetRequest
+	3,  // 3: parcel.v1.ParcelService.GetByTrackCode:input_type -> parcel.v1.GetByTrackCodeRequest
+	4,  // 4: parcel.v1.ParcelService.ListByClient:input_type -> parcel.v1.ListByClientRequest
+	6,  // 5: parcel.v1.ParcelService.ListByClientStream:input_type -> parcel.v1.ListByClientStreamRequest
+	7,  // 6: parcel.v1.ParcelService.SetStatus:input_type -> parcel.v1.SetStatusRequest
+	7,  // 7: parcel.v1.ParcelService.StreamStatusUpdates:input_type -> parcel.v1.SetStatusRequest
+	10, // 8: parcel.v1.ParcelService.SetAddress:input_type -> parcel.v1.SetAddressRequest
+	12, // 9: parcel.v1.ParcelService.MarkPaid:input_type -> parcel.v1.MarkPaidRequest
+	14, // 10: parcel.v1.ParcelService.Delete:input_type -> parcel.v1.DeleteRequest
+	0,  // 11: parcel.v1.ParcelService.Add:output_type -> parcel.v1.Parcel
+	0,  // 12: parcel.v1.ParcelService.Get:output_type -> parcel.v1.Parcel
+	0,  // 13: parcel.v1.ParcelService.GetByTrackCode:output_type -> parcel.v1.Parcel
+	5,  // 14: parcel.v1.ParcelService.ListByClient:output_type -> parcel.v1.ListByClientResponse
+	0,  // 15: parcel.v1.ParcelService.ListByClientStream:output_type -> parcel.v1.Parcel
+	8,  // 16: parcel.v1.ParcelService.SetStatus:output_type -> parcel.v1.SetStatusResponse
+	9,  // 17: parcel.v1.ParcelService.StreamStatusUpdates:output_type -> parcel.v1.StatusUpdateResult
+	11, // 18: parcel.v1.ParcelService.SetAddress:output_type -> parcel.v1.SetAddressResponse
+	13, // 19: parcel.v1.ParcelService.MarkPaid:output_type -> parcel.v1.MarkPaidResponse
+	15, // 20: parcel.v1.ParcelService.Delete:output_type -> parcel.v1.DeleteResponse
+	11, // [11:21] is the sub-list for method output_type
+	1,  // [1:11] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_parcel_proto_init() }
+func file_parcel_proto_init() {
+	if File_parcel_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_parcel_proto_rawDesc), len(file_parcel_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_parcel_proto_goTypes,
+		DependencyIndexes: file_parcel_proto_depIdxs,
+		MessageInfos:      file_parcel_proto_msgTypes,
+	}.Build()
+	File_parcel_proto = out.File
+	file_parcel_proto_goTypes = nil
+	file_parcel_proto_depIdxs = nil
+}
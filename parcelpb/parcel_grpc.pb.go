@@ -0,0 +1,524 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: parcel.proto
+
+package parcelpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	ParcelService_Add_FullMethodName                 = "/parcel.v1.ParcelService/Add"
+	ParcelService_Get_FullMethodName                 = "/parcel.v1.ParcelService/Get"
+	ParcelService_GetByTrackCode_FullMethodName      = "/parcel.v1.ParcelService/GetByTrackCode"
+	ParcelService_ListByClient_FullMethodName        = "/parcel.v1.ParcelService/ListByClient"
+	ParcelService_ListByClientStream_FullMethodName  = "/parcel.v1.ParcelService/ListByClientStream"
+	ParcelService_SetStatus_FullMethodName           = "/parcel.v1.ParcelService/SetStatus"
+	ParcelService_StreamStatusUpdates_FullMethodName = "/parcel.v1.ParcelService/StreamStatusUpdates"
+	ParcelService_SetAddress_FullMethodName          = "/parcel.v1.ParcelService/SetAddress"
+	ParcelService_MarkPaid_FullMethodName            = "/parcel.v1.ParcelService/MarkPaid"
+	ParcelService_Delete_FullMethodName              = "/parcel.v1.ParcelService/Delete"
+)
+
+// ParcelServiceClient is the client API for ParcelService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ParcelService управляет посылками в хранилище трекера.
+type ParcelServiceClient interface {
+	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*Parcel, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Parcel, error)
+	GetByTrackCode(ctx context.Context, in *GetByTrackCodeRequest, opts ...grpc.CallOption) (*Parcel, error)
+	ListByClient(ctx context.Context, in *ListByClientRequest, opts ...grpc.CallOption) (*ListByClientResponse, error)
+	// ListByClientStream отдаёт посылки клиента по возрастанию номера
+	// потоком, не собирая весь список в одном ответе.
+	ListByClientStream(ctx context.Context, in *ListByClientStreamRequest, opts ...grpc.CallOption) (ParcelService_ListByClientStreamClient, error)
+	SetStatus(ctx context.Context, in *SetStatusRequest, opts ...grpc.CallOption) (*SetStatusResponse, error)
+	// StreamStatusUpdates меняет статусы посылок из потока запросов и отвечает
+	// на каждый запрос по порядку; отказ по одной посылке поток не закрывает.
+	StreamStatusUpdates(ctx context.Context, opts ...grpc.CallOption) (ParcelService_StreamStatusUpdatesClient, error)
+	SetAddress(ctx context.Context, in *SetAddressRequest, opts ...grpc.CallOption) (*SetAddressResponse, error)
+	MarkPaid(ctx context.Context, in *MarkPaidRequest, opts ...grpc.CallOption) (*MarkPaidResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+}
+
+type parcelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewParcelServiceClient(cc grpc.ClientConnInterface) ParcelServiceClient {
+	return &parcelServiceClient{cc}
+}
+
+func (c *parcelServiceClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*Parcel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Parcel)
+	err := c.cc.Invoke(ctx, ParcelService_Add_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Parcel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Parcel)
+	err := c.cc.Invoke(ctx, ParcelService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) GetByTrackCode(ctx context.Context, in *GetByTrackCodeRequest, opts ...grpc.CallOption) (*Parcel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Parcel)
+	err := c.cc.Invoke(ctx, ParcelService_GetByTrackCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) ListByClient(ctx context.Context, in *ListByClientRequest, opts ...grpc.CallOption) (*ListByClientResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListByClientResponse)
+	err := c.cc.Invoke(ctx, ParcelService_ListByClient_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) ListByClientStream(ctx context.Context, in *ListByClientStreamRequest, opts ...grpc.CallOption) (ParcelService_ListByClientStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ParcelService_ServiceDesc.Streams[0], ParcelService_ListByClientStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &parcelServiceListByClientStreamClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ParcelService_ListByClientStreamClient interface {
+	Recv() (*Parcel, error)
+	grpc.ClientStream
+}
+
+type parcelServiceListByClientStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *parcelServiceListByClientStreamClient) Recv() (*Parcel, error) {
+	m := new(Parcel)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *parcelServiceClient) SetStatus(ctx context.Context, in *SetStatusRequest, opts ...grpc.CallOption) (*SetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetStatusResponse)
+	err := c.cc.Invoke(ctx, ParcelService_SetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) StreamStatusUpdates(ctx context.Context, opts ...grpc.CallOption) (ParcelService_StreamStatusUpdatesClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ParcelService_ServiceDesc.Streams[1], ParcelService_StreamStatusUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &parcelServiceStreamStatusUpdatesClient{ClientStream: stream}
+	return x, nil
+}
+
+type ParcelService_StreamStatusUpdatesClient interface {
+	Send(*SetStatusRequest) error
+	Recv() (*StatusUpdateResult, error)
+	grpc.ClientStream
+}
+
+type parcelServiceStreamStatusUpdatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *parcelServiceStreamStatusUpdatesClient) Send(m *SetStatusRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *parcelServiceStreamStatusUpdatesClient) Recv() (*StatusUpdateResult, error) {
+	m := new(StatusUpdateResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *parcelServiceClient) SetAddress(ctx context.Context, in *SetAddressRequest, opts ...grpc.CallOption) (*SetAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAddressResponse)
+	err := c.cc.Invoke(ctx, ParcelService_SetAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) MarkPaid(ctx context.Context, in *MarkPaidRequest, opts ...grpc.CallOption) (*MarkPaidResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkPaidResponse)
+	err := c.cc.Invoke(ctx, ParcelService_MarkPaid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, ParcelService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ParcelServiceServer is the server API for ParcelService service.
+// All implementations must embed UnimplementedParcelServiceServer
+// for forward compatibility
+//
+// ParcelService управляет посылками в хранилище трекера.
+type ParcelServiceServer interface {
+	Add(context.Context, *AddRequest) (*Parcel, error)
+	Get(context.Context, *GetRequest) (*Parcel, error)
+	GetByTrackCode(context.Context, *GetByTrackCodeRequest) (*Parcel, error)
+	ListByClient(context.Context, *ListByClientRequest) (*ListByClientResponse, error)
+	// ListByClientStream отдаёт посылки клиента по возрастанию номера
+	// потоком, не собирая весь список в одном ответе.
+	ListByClientStream(*ListByClientStreamRequest, ParcelService_ListByClientStreamServer) error
+	SetStatus(context.Context, *SetStatusRequest) (*SetStatusResponse, error)
+	// StreamStatusUpdates меняет статусы посылок из потока запросов и отвечает
+	// на каждый запрос по порядку; отказ по одной посылке поток не закрывает.
+	StreamStatusUpdates(ParcelService_StreamStatusUpdatesServer) error
+	SetAddress(context.Context, *SetAddressRequest) (*SetAddressResponse, error)
+	MarkPaid(context.Context, *MarkPaidRequest) (*MarkPaidResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	mustEmbedUnimplementedParcelServiceServer()
+}
+
+// UnimplementedParcelServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedParcelServiceServer struct {
+}
+
+func (UnimplementedParcelServiceServer) Add(context.Context, *AddRequest) (*Parcel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedParcelServiceServer) Get(context.Context, *GetRequest) (*Parcel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedParcelServiceServer) GetByTrackCode(context.Context, *GetByTrackCodeRequest) (*Parcel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByTrackCode not implemented")
+}
+func (UnimplementedParcelServiceServer) ListByClient(context.Context, *ListByClientRequest) (*ListByClientResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListByClient not implemented")
+}
+func (UnimplementedParcelServiceServer) ListByClientStream(*ListByClientStreamRequest, ParcelService_ListByClientStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListByClientStream not implemented")
+}
+func (UnimplementedParcelServiceServer) SetStatus(context.Context, *SetStatusRequest) (*SetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetStatus not implemented")
+}
+func (UnimplementedParcelServiceServer) StreamStatusUpdates(ParcelService_StreamStatusUpdatesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamStatusUpdates not implemented")
+}
+func (UnimplementedParcelServiceServer) SetAddress(context.Context, *SetAddressRequest) (*SetAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAddress not implemented")
+}
+func (UnimplementedParcelServiceServer) MarkPaid(context.Context, *MarkPaidRequest) (*MarkPaidResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkPaid not implemented")
+}
+func (UnimplementedParcelServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedParcelServiceServer) mustEmbedUnimplementedParcelServiceServer() {}
+
+// UnsafeParcelServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ParcelServiceServer will
+// result in compilation errors.
+type UnsafeParcelServiceServer interface {
+	mustEmbedUnimplementedParcelServiceServer()
+}
+
+func RegisterParcelServiceServer(s grpc.ServiceRegistrar, srv ParcelServiceServer) {
+	s.RegisterService(&ParcelService_ServiceDesc, srv)
+}
+
+func _ParcelService_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_GetByTrackCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByTrackCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).GetByTrackCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_GetByTrackCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).GetByTrackCode(ctx, req.(*GetByTrackCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_ListByClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListByClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).ListByClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_ListByClient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).ListByClient(ctx, req.(*ListByClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_ListByClientStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListByClientStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ParcelServiceServer).ListByClientStream(m, &parcelServiceListByClientStreamServer{ServerStream: stream})
+}
+
+type ParcelService_ListByClientStreamServer interface {
+	Send(*Parcel) error
+	grpc.ServerStream
+}
+
+type parcelServiceListByClientStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *parcelServiceListByClientStreamServer) Send(m *Parcel) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ParcelService_SetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).SetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_SetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).SetStatus(ctx, req.(*SetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_StreamStatusUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ParcelServiceServer).StreamStatusUpdates(&parcelServiceStreamStatusUpdatesServer{ServerStream: stream})
+}
+
+type ParcelService_StreamStatusUpdatesServer interface {
+	Send(*StatusUpdateResult) error
+	Recv() (*SetStatusRequest, error)
+	grpc.ServerStream
+}
+
+type parcelServiceStreamStatusUpdatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *parcelServiceStreamStatusUpdatesServer) Send(m *StatusUpdateResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *parcelServiceStreamStatusUpdatesServer) Recv() (*SetStatusRequest, error) {
+	m := new(SetStatusRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ParcelService_SetAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).SetAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_SetAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).SetAddress(ctx, req.(*SetAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_MarkPaid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkPaidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).MarkPaid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_MarkPaid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).MarkPaid(ctx, req.(*MarkPaidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ParcelService_ServiceDesc is the grpc.ServiceDesc for ParcelService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ParcelService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "parcel.v1.ParcelService",
+	HandlerType: (*ParcelServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Add",
+			Handler:    _ParcelService_Add_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _ParcelService_Get_Handler,
+		},
+		{
+			MethodName: "GetByTrackCode",
+			Handler:    _ParcelService_GetByTrackCode_Handler,
+		},
+		{
+			MethodName: "ListByClient",
+			Handler:    _ParcelService_ListByClient_Handler,
+		},
+		{
+			MethodName: "SetStatus",
+			Handler:    _ParcelService_SetStatus_Handler,
+		},
+		{
+			MethodName: "SetAddress",
+			Handler:    _ParcelService_SetAddress_Handler,
+		},
+		{
+			MethodName: "MarkPaid",
+			Handler:    _ParcelService_MarkPaid_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ParcelService_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListByClientStream",
+			Handler:       _ParcelService_ListByClientStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamStatusUpdates",
+			Handler:       _ParcelService_StreamStatusUpdates_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "parcel.proto",
+}
@@ -0,0 +1,667 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// partitionSpan — сколько номеров отведено одному месячному разделу:
+// глобальный номер посылки равен yyyymm*partitionSpan + номер в разделе,
+// например 202406_00000017.
+const partitionSpan = 100_000_000
+
+// partitionPlaceholder заменяется в PartitionConfig.DSN на имя раздела.
+const partitionPlaceholder = "{partition}"
+
+// PartitionConfig включает месячные разделы: посылки месяца пишутся
+// в отдельную БД SQLite по шаблону DSN, например data/{partition}.db
+// даёт data/parcel_2024_06.db. Индексы каждого раздела остаются
+// небольшими, а устаревшие разделы удаляются целиком. Почему раздел —
+// файл, а не таблица в общей базе, см. sqlitePartitions.
+type PartitionConfig struct {
+	DSN string `yaml:"dsn"`
+	// Ahead — на сколько месяцев вперёд создавать разделы заранее
+	Ahead int `yaml:"ahead"`
+	// RetainMonths — сколько месяцев хранить разделы, 0 — бессрочно
+	RetainMonths int `yaml:"retain_months"`
+	// Every — период работы PartitionManager в API-сервере, 0 выключает его
+	Every time.Duration `yaml:"every"`
+}
+
+func (c PartitionConfig) enabled() bool {
+	return c.DSN != ""
+}
+
+func (c PartitionConfig) validate(driver string) error {
+	if !c.enabled() {
+		return nil
+	}
+	if driver != "sqlite" {
+		return errors.New("config: partitions need the sqlite driver")
+	}
+	if !strings.Contains(c.DSN, partitionPlaceholder) {
+		return fmt.Errorf("config: partitions dsn must contain %s", partitionPlaceholder)
+	}
+	if c.Ahead < 0 || c.RetainMonths < 0 || c.Every < 0 {
+		return errors.New("config: partitions ahead, retain_months and every must not be negative")
+	}
+	return nil
+}
+
+// partitionKey возвращает ключ yyyymm месячного раздела посылок, созданных в t.
+func partitionKey(t time.Time) int {
+	t = t.UTC()
+	return t.Year()*100 + int(t.Month())
+}
+
+// partitionStart возвращает начало месяца раздела key.
+func partitionStart(key int) time.Time {
+	return time.Date(key/100, time.Month(key%100), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName возвращает имя раздела key, например parcel_2024_06.
+func partitionName(key int) string {
+	return "parcel_" + partitionStart(key).Format("2006_01")
+}
+
+// parsePartitionName разбирает имя из partitionName.
+func parsePartitionName(name string) (int, bool) {
+	t, err := time.Parse("parcel_2006_01", name)
+	if err != nil {
+		return 0, false
+	}
+	return partitionKey(t), true
+}
+
+// PartitionOpener открывает, перечисляет и удаляет месячные разделы.
+type PartitionOpener interface {
+	// Open открывает раздел name, создавая и обновляя его схему.
+	Open(ctx context.Context, name string) (ParcelStorage, error)
+	// List возвращает имена существующих разделов.
+	List() ([]string, error)
+	// Drop удаляет закрытый раздел name вместе с посылками.
+	Drop(name string) error
+}
+
+// PartitionedStorage распределяет посылки по месячным разделам по времени
+// создания. Раздел зашит в номер посылки, поэтому запросы по номеру идут
+// в один раздел, а списки, поиск по трек-коду и запросы по клиенту
+// опрашивают разделы параллельно; фильтр по времени создания пропускает
+// разделы вне интервала. Разделы создаёт и удаляет PartitionManager,
+// а раздел текущего месяца при необходимости открывает и Add.
+type PartitionedStorage struct {
+	opener PartitionOpener
+	now    func() time.Time
+
+	mu    sync.RWMutex
+	parts map[int]ParcelStorage
+}
+
+var _ ParcelStorage = (*PartitionedStorage)(nil)
+
+// partition — открытый раздел и его ключ yyyymm.
+type partition struct {
+	key   int
+	store ParcelStorage
+}
+
+// NewPartitionedStorage открывает существующие разделы opener.
+func NewPartitionedStorage(ctx context.Context, opener PartitionOpener) (*PartitionedStorage, error) {
+	s := &PartitionedStorage{opener: opener, now: time.Now, parts: map[int]ParcelStorage{}}
+
+	names, err := opener.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		key, ok := parsePartitionName(name)
+		if !ok {
+			continue
+		}
+		if _, err := s.open(ctx, key); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// WithClock задаёт часы, по которым Add выбирает раздел. Возвращает s.
+func (s *PartitionedStorage) WithClock(now func() time.Time) *PartitionedStorage {
+	s.now = now
+	return s
+}
+
+// open возвращает раздел key, открывая его при первом обращении.
+func (s *PartitionedStorage) open(ctx context.Context, key int) (ParcelStorage, error) {
+	s.mu.RLock()
+	store, ok := s.parts[key]
+	s.mu.RUnlock()
+	if ok {
+		return store, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if store, ok := s.parts[key]; ok {
+		return store, nil
+	}
+	store, err := s.opener.Open(ctx, partitionName(key))
+	if err != nil {
+		return nil, fmt.Errorf("open partition %s: %w", partitionName(key), err)
+	}
+	s.parts[key] = store
+
+	return store, nil
+}
+
+// partitions возвращает открытые разделы по возрастанию месяца,
+// пересекающиеся с интервалом создания filter.
+func (s *PartitionedStorage) partitions(filter Filter) []partition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]partition, 0, len(s.parts))
+	for key, store := range s.parts {
+		start := partitionStart(key)
+		if !filter.To.IsZero() && !start.Before(filter.To) {
+			continue
+		}
+		if !filter.From.IsZero() && !start.AddDate(0, 1, 0).After(filter.From) {
+			continue
+		}
+		res = append(res, partition{key: key, store: store})
+	}
+	slices.SortFunc(res, func(a, b partition) int { return a.key - b.key })
+
+	return res
+}
+
+// locate возвращает раздел посылки с номером number и её номер в нём;
+// store равен nil, если такого раздела нет.
+func (s *PartitionedStorage) locate(number int) (ParcelStorage, int) {
+	if number <= 0 {
+		return nil, 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.parts[number/partitionSpan], number % partitionSpan
+}
+
+// globalParcels переводит номера посылок раздела key в глобальные.
+func globalParcels(key int, parcels []Parcel) []Parcel {
+	for i := range parcels {
+		parcels[i].Number = key*partitionSpan + parcels[i].Number
+	}
+	return parcels
+}
+
+// Add записывает посылку в раздел текущего месяца.
+func (s *PartitionedStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	key := partitionKey(s.now())
+	store, err := s.open(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	number, err := store.Add(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	if number >= partitionSpan {
+		return 0, fmt.Errorf("partition %s is full", partitionName(key))
+	}
+	return key*partitionSpan + number, nil
+}
+
+func (s *PartitionedStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	store, local := s.locate(number)
+	if store == nil {
+		return Parcel{}, ErrParcelNotFound
+	}
+	p, err := store.Get(ctx, local)
+	if err != nil {
+		return p, err
+	}
+	p.Number = number
+	return p, nil
+}
+
+func (s *PartitionedStorage) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	return s.findOne(func(store ParcelStorage) (Parcel, error) {
+		return store.GetByTrackCode(ctx, code)
+	})
+}
+
+func (s *PartitionedStorage) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	return s.findOne(func(store ParcelStorage) (Parcel, error) {
+		return store.GetByUUID(ctx, id)
+	})
+}
+
+// findOne ищет посылку во всех разделах, начиная с новых.
+func (s *PartitionedStorage) findOne(get func(store ParcelStorage) (Parcel, error)) (Parcel, error) {
+	found, err := fanOut(s.partitions(Filter{}), func(_ int, part partition) ([]Parcel, error) {
+		p, err := get(part.store)
+		if errors.Is(err, ErrParcelNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return globalParcels(part.key, []Parcel{p}), nil
+	})
+	if err != nil {
+		return Parcel{}, err
+	}
+	for i := len(found) - 1; i >= 0; i-- {
+		if len(found[i]) > 0 {
+			return found[i][0], nil
+		}
+	}
+	return Parcel{}, ErrParcelNotFound
+}
+
+// collect собирает посылки всех разделов по возрастанию номера.
+func (s *PartitionedStorage) collect(get func(store ParcelStorage) ([]Parcel, error)) ([]Parcel, error) {
+	found, err := fanOut(s.partitions(Filter{}), func(_ int, part partition) ([]Parcel, error) {
+		parcels, err := get(part.store)
+		return globalParcels(part.key, parcels), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res []Parcel
+	for _, parcels := range found {
+		res = append(res, parcels...)
+	}
+	return res, nil
+}
+
+func (s *PartitionedStorage) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	return s.collect(func(store ParcelStorage) ([]Parcel, error) {
+		return store.GetByClient(ctx, client)
+	})
+}
+
+func (s *PartitionedStorage) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+	head := opts.head()
+	pages, err := fanOut(s.partitions(Filter{}), func(_ int, part partition) (ParcelPage, error) {
+		page, err := part.store.ListByClient(ctx, client, head)
+		page.Parcels = globalParcels(part.key, page.Parcels)
+		return page, err
+	})
+	if err != nil {
+		return ParcelPage{}, err
+	}
+	return mergePages(pages, opts), nil
+}
+
+// List опрашивает разделы не раньше раздела курсора и сливает их страницы.
+func (s *PartitionedStorage) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	limit, err := cursorLimit(limit)
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	afterKey := afterNumber / partitionSpan
+	pages, err := fanOut(s.partitions(filter), func(_ int, part partition) ([]Parcel, error) {
+		after := 0
+		switch {
+		case part.key < afterKey:
+			return nil, nil
+		case part.key == afterKey:
+			after = afterNumber % partitionSpan
+		}
+		page, err := part.store.List(ctx, filter, after, limit+1)
+		return globalParcels(part.key, page.Parcels), err
+	})
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	return mergeCursorPages(pages, limit), nil
+}
+
+func (s *PartitionedStorage) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+	head := opts.head()
+	pages, err := fanOut(s.partitions(filter), func(_ int, part partition) (ParcelPage, error) {
+		page, err := part.store.ListAll(ctx, filter, head)
+		page.Parcels = globalParcels(part.key, page.Parcels)
+		return page, err
+	})
+	if err != nil {
+		return ParcelPage{}, err
+	}
+	return mergePages(pages, opts), nil
+}
+
+// withParcel вызывает fn с разделом посылки number и её номером в нём.
+func (s *PartitionedStorage) withParcel(number int, fn func(store ParcelStorage, local int) error) error {
+	store, local := s.locate(number)
+	if store == nil {
+		return ErrParcelNotFound
+	}
+	return fn(store, local)
+}
+
+func (s *PartitionedStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.SetStatus(ctx, local, status)
+	})
+}
+
+func (s *PartitionedStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.SetSenderAddress(ctx, local, address)
+	})
+}
+
+func (s *PartitionedStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.SetRecipientAddress(ctx, local, address)
+	})
+}
+
+func (s *PartitionedStorage) Update(ctx context.Context, p Parcel) error {
+	return s.withParcel(p.Number, func(store ParcelStorage, local int) error {
+		p.Number = local
+		return store.Update(ctx, p)
+	})
+}
+
+func (s *PartitionedStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.SetETA(ctx, local, eta)
+	})
+}
+
+func (s *PartitionedStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.MarkPaid(ctx, local, txRef)
+	})
+}
+
+func (s *PartitionedStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.Cancel(ctx, local, reason)
+	})
+}
+
+func (s *PartitionedStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.Deliver(ctx, local, sig)
+	})
+}
+
+func (s *PartitionedStorage) Delete(ctx context.Context, number int) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.Delete(ctx, local)
+	})
+}
+
+func (s *PartitionedStorage) HardDelete(ctx context.Context, number int) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.HardDelete(ctx, local)
+	})
+}
+
+func (s *PartitionedStorage) Restore(ctx context.Context, number int) error {
+	return s.withParcel(number, func(store ParcelStorage, local int) error {
+		return store.Restore(ctx, local)
+	})
+}
+
+func (s *PartitionedStorage) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	var history []StatusChange
+	err := s.withParcel(number, func(store ParcelStorage, local int) error {
+		var err error
+		history, err = store.GetHistory(ctx, local)
+		return err
+	})
+	for i := range history {
+		history[i].Number = number
+	}
+	return history, err
+}
+
+func (s *PartitionedStorage) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	var id int
+	err := s.withParcel(number, func(store ParcelStorage, local int) error {
+		var err error
+		id, err = store.AddEvent(ctx, local, code, description, occurredAt)
+		return err
+	})
+	return id, err
+}
+
+func (s *PartitionedStorage) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	var events []TrackingEvent
+	err := s.withParcel(number, func(store ParcelStorage, local int) error {
+		var err error
+		events, err = store.GetEvents(ctx, local)
+		return err
+	})
+	for i := range events {
+		events[i].Number = number
+	}
+	return events, err
+}
+
+// Migrate обновляет схему каждого открытого раздела.
+func (s *PartitionedStorage) Migrate(ctx context.Context) error {
+	for _, part := range s.partitions(Filter{}) {
+		if m, ok := part.store.(interface{ Migrate(context.Context) error }); ok {
+			if err := m.Migrate(ctx); err != nil {
+				return fmt.Errorf("partition %s: %w", partitionName(part.key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Close закрывает все разделы.
+func (s *PartitionedStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for key, store := range s.parts {
+		if c, ok := store.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+		delete(s.parts, key)
+	}
+	return errors.Join(errs...)
+}
+
+// drop закрывает и удаляет раздел key.
+func (s *PartitionedStorage) drop(key int) error {
+	s.mu.Lock()
+	store, ok := s.parts[key]
+	delete(s.parts, key)
+	s.mu.Unlock()
+
+	if ok {
+		if c, isCloser := store.(io.Closer); isCloser {
+			if err := c.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return s.opener.Drop(partitionName(key))
+}
+
+// PartitionReport — итог прогона PartitionManager: созданные и удалённые разделы.
+type PartitionReport struct {
+	Created []string
+	Dropped []string
+}
+
+// PartitionManager заранее создаёт разделы на ahead месяцев вперёд,
+// чтобы первая запись месяца не ждала создания схемы, и удаляет разделы
+// старше retain месяцев вместе с посылками.
+type PartitionManager struct {
+	storage  *PartitionedStorage
+	ahead    int
+	retain   int
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewPartitionManager создаёт менеджер разделов storage по настройкам cfg.
+func NewPartitionManager(storage *PartitionedStorage, cfg PartitionConfig) PartitionManager {
+	return PartitionManager{storage: storage, ahead: cfg.Ahead, retain: cfg.RetainMonths, interval: cfg.Every,
+		logger: slog.Default()}
+}
+
+// WithLogger возвращает копию менеджера, пишущую итоги прогонов в logger.
+func (m PartitionManager) WithLogger(logger *slog.Logger) PartitionManager {
+	m.logger = logger
+	return m
+}
+
+// Run выполняет RunOnce сразу и затем раз в interval до отмены ctx.
+// Ошибка прогона логируется и не останавливает менеджер.
+func (m PartitionManager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.logger.ErrorContext(ctx, "partition manager", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce создаёт недостающие разделы с текущего месяца на ahead вперёд
+// и удаляет разделы, вышедшие за срок хранения.
+func (m PartitionManager) RunOnce(ctx context.Context) (PartitionReport, error) {
+	var report PartitionReport
+	now := partitionStart(partitionKey(m.storage.now()))
+
+	existing := map[int]bool{}
+	for _, part := range m.storage.partitions(Filter{}) {
+		existing[part.key] = true
+	}
+
+	for i := 0; i <= m.ahead; i++ {
+		key := partitionKey(now.AddDate(0, i, 0))
+		if existing[key] {
+			continue
+		}
+		if _, err := m.storage.open(ctx, key); err != nil {
+			return report, err
+		}
+		report.Created = append(report.Created, partitionName(key))
+	}
+
+	if m.retain > 0 {
+		oldest := partitionKey(now.AddDate(0, -m.retain+1, 0))
+		for key := range existing {
+			if key >= oldest {
+				continue
+			}
+			if err := m.storage.drop(key); err != nil {
+				return report, err
+			}
+			report.Dropped = append(report.Dropped, partitionName(key))
+		}
+		slices.Sort(report.Dropped)
+	}
+
+	if len(report.Created) > 0 || len(report.Dropped) > 0 {
+		m.logger.InfoContext(ctx, "partitions updated", "created", report.Created, "dropped", report.Dropped)
+	}
+	return report, nil
+}
+
+// sqlitePartitions хранит разделы в файлах SQLite по шаблону cfg.Partitions.DSN.
+//
+// Раздел — это не таблица parcel_2024_06 в общей базе, а отдельный файл
+// parcel_2024_06 со своей таблицей parcel. Запросы ParcelStore обращаются
+// к parcel и зависящим от неё таблицам (история, события, заметки, подписи)
+// по постоянным именам, и месячные таблицы потребовали бы подставлять имя
+// раздела в каждый из них. Файл на месяц даёт то же самое: небольшие
+// индексы каждого раздела и удаление устаревшего раздела целиком, как
+// DROP TABLE, но вместе с его историей. Другой способ хранения разделов
+// подключается своей реализацией PartitionOpener.
+type sqlitePartitions struct {
+	cfg Config
+}
+
+func (p sqlitePartitions) path(name string) string {
+	return strings.ReplaceAll(p.cfg.Partitions.DSN, partitionPlaceholder, name)
+}
+
+// Open открывает раздел без проверки внешних ключей: справочники клиентов,
+// курьеров и пунктов в разделы не попадают, а в каждом разделе их не было бы.
+func (p sqlitePartitions) Open(ctx context.Context, name string) (ParcelStorage, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(p.path(name), sqliteOptions{}))
+	if err != nil {
+		return nil, err
+	}
+	p.cfg.Pool.apply(db)
+	store := NewParcelStore(db)
+	if err := store.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	s, err := withEncryption(store, p.cfg.Encryption)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return withPaymentRequired(withKeyMode(s, p.cfg.KeyMode), p.cfg.Payment.RequiredForSending), nil
+}
+
+func (p sqlitePartitions) List() ([]string, error) {
+	prefix, suffix, _ := strings.Cut(p.cfg.Partitions.DSN, partitionPlaceholder)
+	matches, err := filepath.Glob(prefix + "parcel_*" + suffix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(m, prefix), suffix)
+		if _, ok := parsePartitionName(name); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Drop удаляет файл раздела вместе с журналами WAL.
+func (p sqlitePartitions) Drop(name string) error {
+	path := p.path(name)
+	var errs []error
+	for _, f := range []string{path, path + "-wal", path + "-shm"} {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPartitions хранит разделы в памяти для тестов PartitionedStorage
+type memoryPartitions struct {
+	parts map[string]ParcelStorage
+	open  func() ParcelStorage
+}
+
+func newMemoryPartitions() *memoryPartitions {
+	return &memoryPartitions{parts: map[string]ParcelStorage{}, open: func() ParcelStorage { return NewMemoryParcelStore() }}
+}
+
+func (m *memoryPartitions) Open(_ context.Context, name string) (ParcelStorage, error) {
+	if store, ok := m.parts[name]; ok {
+		return store, nil
+	}
+	m.parts[name] = m.open()
+	return m.parts[name], nil
+}
+
+func (m *memoryPartitions) List() ([]string, error) {
+	return slices.Sorted(maps.Keys(m.parts)), nil
+}
+
+func (m *memoryPartitions) Drop(name string) error {
+	delete(m.parts, name)
+	return nil
+}
+
+// month возвращает часы, стоящие на 15-м числе месяца key
+func month(key int) func() time.Time {
+	return func() time.Time { return partitionStart(key).AddDate(0, 0, 14) }
+}
+
+// TestPartitionedStorage проверяет запись посылок в раздел месяца,
+// чтение по номеру и слияние списков разделов
+func TestPartitionedStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	opener := newMemoryPartitions()
+	store, err := NewPartitionedStorage(ctx, opener)
+	require.NoError(t, err)
+
+	// add
+	var numbers []int
+	for _, key := range []int{202406, 202406, 202407, 202407} {
+		store.WithClock(month(key))
+		number, err := store.Add(ctx, getTestParcel())
+		require.NoError(t, err)
+		require.Equal(t, key, number/partitionSpan)
+		numbers = append(numbers, number)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+
+	// check
+	require.ElementsMatch(t, []string{"parcel_2024_06", "parcel_2024_07"}, slices.Collect(maps.Keys(opener.parts)))
+	june, err := opener.parts["parcel_2024_06"].GetByClient(ctx, getTestParcel().Client)
+	require.NoError(t, err)
+	require.Len(t, june, 2)
+
+	p, err := store.Get(ctx, numbers[1])
+	require.NoError(t, err)
+	require.Equal(t, numbers[1], p.Number)
+	require.Equal(t, ParcelStatusSent, p.Status)
+	byCode, err := store.GetByTrackCode(ctx, p.TrackCode)
+	require.NoError(t, err)
+	require.Equal(t, numbers[1], byCode.Number)
+
+	_, err = store.Get(ctx, 202301*partitionSpan+1)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	require.ErrorIs(t, store.SetStatus(ctx, 202301*partitionSpan+1, ParcelStatusSent), ErrParcelNotFound)
+
+	parcels, err := store.GetByClient(ctx, getTestParcel().Client)
+	require.NoError(t, err)
+	require.Equal(t, numbers, parcelNumbers(parcels))
+
+	sent, err := store.ListAll(ctx, Filter{Statuses: []Status{ParcelStatusSent}}, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, sent.Total)
+	require.Equal(t, []int{numbers[1]}, parcelNumbers(sent.Parcels))
+
+	page, err := store.ListAll(ctx, Filter{}, ListOptions{Limit: 2, Offset: 1, SortBy: SortByNumber, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, 4, page.Total)
+	require.Equal(t, []int{numbers[2], numbers[1]}, parcelNumbers(page.Parcels))
+
+	// раздел вне интервала создания не опрашивается: опрос nil-раздела упал бы
+	store.parts[202406] = nil
+	july, err := store.ListAll(ctx, Filter{From: partitionStart(202407)}, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, july.Total)
+	store.parts[202406] = opener.parts["parcel_2024_06"]
+
+	// обход курсором переходит из раздела в раздел по возрастанию номера
+	var listed []int
+	for after := 0; ; {
+		page, err := store.List(ctx, Filter{}, after, 1)
+		require.NoError(t, err)
+		listed = append(listed, parcelNumbers(page.Parcels)...)
+		if page.NextCursor == "" {
+			break
+		}
+		after = page.Parcels[len(page.Parcels)-1].Number
+	}
+	require.Equal(t, numbers, listed)
+}
+
+// TestPartitionManager проверяет создание разделов вперёд
+// и удаление разделов старше срока хранения
+func TestPartitionManager(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	opener := newMemoryPartitions()
+	store, err := NewPartitionedStorage(ctx, opener)
+	require.NoError(t, err)
+	manager := NewPartitionManager(store.WithClock(month(202406)), PartitionConfig{Ahead: 2, RetainMonths: 2})
+
+	// check
+	report, err := manager.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"parcel_2024_06", "parcel_2024_07", "parcel_2024_08"}, report.Created)
+	require.Empty(t, report.Dropped)
+
+	report, err = manager.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Empty(t, report.Created)
+
+	store.WithClock(month(202409))
+	report, err = manager.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"parcel_2024_09", "parcel_2024_10", "parcel_2024_11"}, report.Created)
+	require.Equal(t, []string{"parcel_2024_06", "parcel_2024_07"}, report.Dropped)
+
+	names, err := opener.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"parcel_2024_08", "parcel_2024_09", "parcel_2024_10", "parcel_2024_11"}, names)
+	require.Len(t, store.partitions(Filter{}), 4)
+
+	// переход через год
+	store.WithClock(month(202412))
+	report, err = manager.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"parcel_2024_12", "parcel_2025_01", "parcel_2025_02"}, report.Created)
+}
+
+// TestPartitionsConfig проверяет разделы в файлах SQLite по настройкам
+func TestPartitionsConfig(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.DSN = ""
+	cfg.Partitions = PartitionConfig{DSN: filepath.Join(dir, "{partition}.db"), Ahead: 1}
+
+	store, _, err := NewParcelStoreFromConfig(cfg)
+	require.NoError(t, err)
+	parts, ok := store.(*PartitionedStorage)
+	require.True(t, ok)
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	report, err := NewPartitionManager(parts, cfg.Partitions).RunOnce(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Created, 1)
+	require.NoError(t, parts.Close())
+
+	// check
+	files, err := filepath.Glob(filepath.Join(dir, "parcel_*.db"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	store, _, err = NewParcelStoreFromConfig(cfg)
+	require.NoError(t, err)
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, id, p.Number)
+
+	names, err := sqlitePartitions{cfg: cfg}.List()
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	require.NoError(t, store.(*PartitionedStorage).drop(id/partitionSpan))
+	files, err = filepath.Glob(filepath.Join(dir, "parcel_*"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	bad := cfg
+	bad.Partitions.DSN = filepath.Join(dir, "parcels.db")
+	require.Error(t, bad.Validate())
+	bad = cfg
+	bad.Driver = "memory"
+	require.Error(t, bad.Validate())
+	bad = cfg
+	bad.Shards = []string{"a.db", "b.db"}
+	require.Error(t, bad.Validate())
+	bad = cfg
+	bad.Partitions.RetainMonths = -1
+	require.Error(t, bad.Validate())
+}
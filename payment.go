@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Статусы оплаты посылки.
+const (
+	PaymentStatusUnpaid = "unpaid"
+	PaymentStatusPaid   = "paid"
+)
+
+// defaultCurrency — валюта суммы к оплате, если она не задана.
+const defaultCurrency = "RUB"
+
+var (
+	// ErrPaymentRequired возвращается при отправке неоплаченной посылки,
+	// если хранилище требует оплату, см. WithPaymentRequired.
+	ErrPaymentRequired = errors.New("parcel is not paid")
+	// ErrAlreadyPaid возвращает MarkPaid для посылки, оплаченной
+	// другой транзакцией.
+	ErrAlreadyPaid = errors.New("parcel is already paid")
+	// ErrInvalidPayment возвращается для пустой ссылки на транзакцию,
+	// отрицательной суммы, неизвестной валюты или статуса оплаты.
+	ErrInvalidPayment = errors.New("invalid payment")
+)
+
+// PaymentConfig — правила оплаты посылок.
+type PaymentConfig struct {
+	// RequiredForSending запрещает статус sent для неоплаченных посылок
+	RequiredForSending bool `yaml:"required_for_sending"`
+}
+
+// preparePayment проверяет поля оплаты новой посылки и заполняет
+// недостающие: сумма по умолчанию равна цене доставки, валюта — рубли,
+// статус — unpaid.
+func preparePayment(p Parcel) (Parcel, error) {
+	if p.Amount < 0 {
+		return p, fmt.Errorf("%w: amount must not be negative", ErrInvalidPayment)
+	}
+	if p.Amount == 0 {
+		p.Amount = p.Price
+	}
+
+	p.Currency = strings.ToUpper(strings.TrimSpace(p.Currency))
+	if p.Currency == "" {
+		p.Currency = defaultCurrency
+	}
+	if len(p.Currency) != 3 || strings.Trim(p.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return p, fmt.Errorf("%w: currency must be an ISO 4217 code", ErrInvalidPayment)
+	}
+
+	switch p.PaymentStatus {
+	case "":
+		p.PaymentStatus = PaymentStatusUnpaid
+	case PaymentStatusUnpaid, PaymentStatusPaid:
+	default:
+		return p, fmt.Errorf("%w: unknown payment status %q", ErrInvalidPayment, p.PaymentStatus)
+	}
+	return p, nil
+}
+
+// checkPayment возвращает ErrPaymentRequired, если посылку p нельзя
+// перевести в статус status без оплаты.
+func checkPayment(required bool, p Parcel, status Status) error {
+	if required && status == ParcelStatusSent && p.PaymentStatus != PaymentStatusPaid {
+		return ErrPaymentRequired
+	}
+	return nil
+}
+
+// WithPaymentRequired возвращает копию хранилища, которая при required
+// отказывает в статусе sent неоплаченным посылкам.
+func (s ParcelStore) WithPaymentRequired(required bool) ParcelStore {
+	s.paymentRequired = required
+	return s
+}
+
+// SetPaymentRequired включает или выключает запрет отправки без оплаты.
+func (s *MemoryParcelStore) SetPaymentRequired(required bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paymentRequired = required
+}
+
+// withPaymentRequired включает правило оплаты у встроенных хранилищ,
+// как withKeyMode.
+func withPaymentRequired(store ParcelStorage, required bool) ParcelStorage {
+	switch s := store.(type) {
+	case ParcelStore:
+		return s.WithPaymentRequired(required)
+	case *MemoryParcelStore:
+		s.SetPaymentRequired(required)
+	}
+	return store
+}
+
+// checkPaid проверяет правило оплаты перед переводом посылки number
+// в статус status.
+func (s ParcelStore) checkPaid(ctx context.Context, number int, status Status) error {
+	if checkPayment(s.paymentRequired, Parcel{}, status) == nil {
+		return nil
+	}
+
+	var p Parcel
+	err := s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT payment_status FROM parcel WHERE number = ? AND deleted_at IS NULL"),
+		number).Scan(&p.PaymentStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrParcelNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return checkPayment(s.paymentRequired, p, status)
+}
+
+// MarkPaid отмечает посылку оплаченной транзакцией txRef платёжной системы.
+// Повторный вызов с той же txRef ничего не меняет, поэтому его можно
+// повторять; оплата другой транзакцией возвращает ErrAlreadyPaid.
+func (s ParcelStore) MarkPaid(ctx context.Context, number int, txRef string) error {
+	txRef = strings.TrimSpace(txRef)
+	if txRef == "" {
+		return fmt.Errorf("%w: transaction reference is required", ErrInvalidPayment)
+	}
+
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		cond, args := tenantCond(ctx)
+		var status, ref string
+		err := tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+			"SELECT payment_status, payment_ref FROM parcel WHERE number = ? AND deleted_at IS NULL"+cond),
+			append([]any{number}, args...)...).Scan(&status, &ref)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrParcelNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if status == PaymentStatusPaid {
+			if ref == txRef {
+				return nil
+			}
+			return ErrAlreadyPaid
+		}
+
+		now := tx.timestampArg()
+		_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET payment_status = ?, paid_at = ?, payment_ref = ?, version = version + 1, updated_at = ?"+
+				" WHERE number = ?"),
+			PaymentStatusPaid, now, txRef, now, number)
+		return err
+	})
+}
+
+// MarkPaid отмечает посылку оплаченной транзакцией txRef.
+func (s *MemoryParcelStore) MarkPaid(ctx context.Context, number int, txRef string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	txRef = strings.TrimSpace(txRef)
+	if txRef == "" {
+		return fmt.Errorf("%w: transaction reference is required", ErrInvalidPayment)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if p.PaymentStatus == PaymentStatusPaid {
+		if p.PaymentRef == txRef {
+			return nil
+		}
+		return ErrAlreadyPaid
+	}
+
+	p.PaymentStatus = PaymentStatusPaid
+	p.PaymentRef = txRef
+	p.Version++
+	p.UpdatedAt = timestamp(s.now)
+	p.PaidAt = p.UpdatedAt
+	s.parcels[number] = p
+
+	return nil
+}
+
+// paidAtArg возвращает аргумент запроса для paid_at: NULL у неоплаченной посылки.
+func (d dialect) paidAtArg(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return d.timeArg(t)
+}
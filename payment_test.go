@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkParcelPayment проверяет оплату посылок в хранилище store,
+// запрещающем отправку без оплаты
+func checkParcelPayment(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	parcel := getTestParcel()
+	parcel.Price = 35000
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(35000), p.Amount)
+	require.Equal(t, "RUB", p.Currency)
+	require.Equal(t, PaymentStatusUnpaid, p.PaymentStatus)
+	require.True(t, p.PaidAt.IsZero())
+
+	// неоплаченную посылку нельзя отправить ни одним способом
+	require.ErrorIs(t, store.SetStatus(ctx, id, ParcelStatusSent), ErrPaymentRequired)
+	p.Status = ParcelStatusSent
+	require.ErrorIs(t, store.Update(ctx, p), ErrPaymentRequired)
+	batcher := store.(interface {
+		SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error)
+	})
+	results, err := batcher.SetStatusBatch(ctx, []int{id}, ParcelStatusSent)
+	require.NoError(t, err)
+	require.ErrorIs(t, results[0].Err, ErrPaymentRequired)
+
+	require.ErrorIs(t, store.MarkPaid(ctx, id, " "), ErrInvalidPayment)
+	require.NoError(t, store.MarkPaid(ctx, id, "tx-1"))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusPaid, p.PaymentStatus)
+	require.Equal(t, "tx-1", p.PaymentRef)
+	require.False(t, p.PaidAt.IsZero())
+	require.Equal(t, 2, p.Version)
+
+	// повтор той же оплаты ничего не меняет, другая оплата — ошибка
+	require.NoError(t, store.MarkPaid(ctx, id, "tx-1"))
+	require.ErrorIs(t, store.MarkPaid(ctx, id, "tx-2"), ErrAlreadyPaid)
+	require.ErrorIs(t, store.MarkPaid(ctx, 999999, "tx-1"), ErrParcelNotFound)
+
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+
+	parcel.Currency = "рубли"
+	_, err = store.Add(ctx, parcel)
+	require.ErrorIs(t, err, ErrInvalidPayment)
+}
+
+// TestParcelPayment проверяет оплату посылок в SQLite
+func TestParcelPayment(t *testing.T) {
+	checkParcelPayment(t, NewParcelStore(openTempDB(t)).WithPaymentRequired(true))
+}
+
+// TestMemoryParcelPayment проверяет оплату посылок в памяти
+func TestMemoryParcelPayment(t *testing.T) {
+	store := NewMemoryParcelStore()
+	store.SetPaymentRequired(true)
+	checkParcelPayment(t, store)
+}
+
+// TestPaymentNotRequired проверяет, что без правила оплаты посылку можно отправить
+func TestPaymentNotRequired(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+}
+
+// TestHTTPMarkPaid проверяет оплату посылки через REST API
+func TestHTTPMarkPaid(t *testing.T) {
+	// prepare
+	store := NewMemoryParcelStore()
+	store.SetPaymentRequired(true)
+	srv := NewHTTPServer(NewParcelService(store))
+	id, err := store.Add(context.Background(), getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	rec := doRequest(t, srv, http.MethodPatch, "/parcels/1/status", `{"status": "sent"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/1/payment", `{"tx_ref": ""}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/1/payment", `{"tx_ref": "tx-1"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, "/parcels/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"payment_status":"paid"`)
+
+	require.NoError(t, store.SetStatus(context.Background(), id, ParcelStatusSent))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"database/sql"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresParcelStore хранит посылки в PostgreSQL.
+// Запросы те же, что и у ParcelStore, но с плейсхолдерами $1, $2, ...,
+// а номер новой посылки возвращается через INSERT ... RETURNING.
+//
+// Схема создаётся методом Migrate из каталога migrations/postgres.
+type PostgresParcelStore struct {
+	ParcelStore
+}
+
+var _ ParcelStorage = PostgresParcelStore{}
+
+func init() {
+	RegisterStorage("postgres", func(db *sql.DB) ParcelStorage {
+		return NewPostgresParcelStore(db)
+	})
+}
+
+func NewPostgresParcelStore(q Querier) PostgresParcelStore {
+	return PostgresParcelStore{newSQLParcelStore(q, postgresDialect)}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// openPostgres подключается к PostgreSQL из переменной окружения POSTGRES_DSN
+// и применяет миграции схемы. Без POSTGRES_DSN тест пропускается.
+func openPostgres(t *testing.T) *sql.DB {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	err = NewPostgresParcelStore(db).Migrate(context.Background())
+	require.NoError(t, err)
+
+	return db
+}
+
+// TestPostgresAddGetDelete проверяет добавление, получение и удаление посылки в PostgreSQL
+func TestPostgresAddGetDelete(t *testing.T) {
+	// prepare
+	db := openPostgres(t)
+	ctx := context.Background()
+	store := NewPostgresParcelStore(db)
+	parcel := getTestParcel()
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	parcel.Number = id
+	parcel.Version = 1
+
+	// get
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, parcel, stored)
+
+	// set address
+	err = store.SetRecipientAddress(ctx, id, "new test address")
+	require.NoError(t, err)
+
+	// delete
+	err = store.Delete(ctx, id)
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
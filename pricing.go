@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	// ErrNoPriceRate возвращает Pricer, если для зоны и веса посылки
+	// нет тарифа ни в самой зоне, ни в зоне по умолчанию.
+	ErrNoPriceRate = errors.New("no price rate for parcel")
+	// ErrInvalidPriceRate возвращается для тарифа с неположительной
+	// верхней границей веса или отрицательной ценой.
+	ErrInvalidPriceRate = errors.New("invalid price rate")
+)
+
+// PricingConfig — настройки расчёта стоимости доставки. Зоны тарифа
+// берутся из настроек eta, цены — из таблицы price_rates.
+type PricingConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// PriceRate — строка тарифной сетки: доставка в зону Zone посылки
+// с оплачиваемым весом до MaxWeightGrams включительно стоит Price копеек.
+// Пустая зона — тариф для адресов вне зон из настроек eta.
+type PriceRate struct {
+	Zone           string
+	MaxWeightGrams int
+	Price          int64
+}
+
+func (r PriceRate) validate() error {
+	if r.MaxWeightGrams <= 0 || r.Price < 0 {
+		return fmt.Errorf("%w: max weight must be positive and price non-negative", ErrInvalidPriceRate)
+	}
+	return nil
+}
+
+// PriceStore хранит тарифную сетку в таблице price_rates.
+type PriceStore interface {
+	SetPriceRate(ctx context.Context, r PriceRate) error
+	DeletePriceRate(ctx context.Context, zone string, maxWeightGrams int) error
+	ListPriceRates(ctx context.Context) ([]PriceRate, error)
+	// FindPriceRate возвращает тариф зоны с наименьшей границей веса
+	// не меньше weightGrams
+	FindPriceRate(ctx context.Context, zone string, weightGrams int) (PriceRate, bool, error)
+}
+
+var _ PriceStore = ParcelStore{}
+
+// SetPriceRate добавляет тариф или меняет цену существующего
+// с той же зоной и границей веса.
+func (s ParcelStore) SetPriceRate(ctx context.Context, r PriceRate) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	// замена в транзакции не зависит от синтаксиса upsert в СУБД
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		if err := tx.DeletePriceRate(ctx, r.Zone, r.MaxWeightGrams); err != nil {
+			return err
+		}
+		_, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"INSERT INTO price_rates (zone, max_weight_grams, price) VALUES (?, ?, ?)"),
+			r.Zone, r.MaxWeightGrams, r.Price)
+		return err
+	})
+}
+
+// DeletePriceRate удаляет тариф; отсутствие тарифа ошибкой не считается.
+func (s ParcelStore) DeletePriceRate(ctx context.Context, zone string, maxWeightGrams int) error {
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"DELETE FROM price_rates WHERE zone = ? AND max_weight_grams = ?"),
+		zone, maxWeightGrams)
+	return err
+}
+
+// ListPriceRates возвращает тарифную сетку по зонам и возрастанию веса.
+func (s ParcelStore) ListPriceRates(ctx context.Context) ([]PriceRate, error) {
+	rows, err := s.q.QueryContext(ctx,
+		"SELECT zone, max_weight_grams, price FROM price_rates ORDER BY zone, max_weight_grams")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []PriceRate
+	for rows.Next() {
+		var r PriceRate
+		if err := rows.Scan(&r.Zone, &r.MaxWeightGrams, &r.Price); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+func (s ParcelStore) FindPriceRate(ctx context.Context, zone string, weightGrams int) (PriceRate, bool, error) {
+	rows, err := s.q.QueryContext(ctx, s.dialect.rebind(
+		"SELECT zone, max_weight_grams, price FROM price_rates"+
+			" WHERE zone = ? AND max_weight_grams >= ? ORDER BY max_weight_grams LIMIT 1"),
+		zone, weightGrams)
+	if err != nil {
+		return PriceRate{}, false, err
+	}
+	defer rows.Close()
+
+	var r PriceRate
+	if !rows.Next() {
+		return r, false, rows.Err()
+	}
+	if err := rows.Scan(&r.Zone, &r.MaxWeightGrams, &r.Price); err != nil {
+		return PriceRate{}, false, err
+	}
+	return r, true, rows.Err()
+}
+
+// formatPrice возвращает цену в копейках как сумму в рублях.
+func formatPrice(kopecks int64) string {
+	return fmt.Sprintf("%d.%02d ₽", kopecks/100, kopecks%100)
+}
+
+// PriceQuote — расчёт стоимости доставки посылки.
+type PriceQuote struct {
+	// Zone — зона тарифа по адресу получателя, пустая для адресов вне зон
+	Zone string
+	// ChargeableWeight — оплачиваемый вес в граммах, см. ChargeableWeight
+	ChargeableWeight int
+	// Rate — применённая строка тарифной сетки
+	Rate  PriceRate
+	Price int64
+}
+
+// Pricer считает стоимость доставки по тарифной сетке из PriceStore.
+// Зону адреса определяют зоны из настроек eta, см. DeliveryEstimator.Zone.
+type Pricer struct {
+	rates     PriceStore
+	estimator DeliveryEstimator
+}
+
+func NewPricer(rates PriceStore, estimator DeliveryEstimator) Pricer {
+	return Pricer{rates: rates, estimator: estimator}
+}
+
+// Quote возвращает стоимость доставки посылки p, ещё не добавленной
+// в хранилище. Берётся тариф зоны с наименьшей границей не меньше
+// оплачиваемого веса; если в зоне такого нет — тариф зоны по умолчанию.
+func (pr Pricer) Quote(ctx context.Context, p Parcel) (PriceQuote, error) {
+	if err := validateDimensions(p); err != nil {
+		return PriceQuote{}, err
+	}
+
+	q := PriceQuote{
+		Zone:             pr.estimator.Zone(NormalizeAddress(p.RecipientAddress)).Name,
+		ChargeableWeight: ChargeableWeight(p),
+	}
+	zones := []string{q.Zone}
+	if q.Zone != "" {
+		zones = append(zones, "")
+	}
+
+	for _, zone := range zones {
+		r, ok, err := pr.rates.FindPriceRate(ctx, zone, q.ChargeableWeight)
+		if err != nil {
+			return PriceQuote{}, err
+		}
+		if ok {
+			q.Rate, q.Price = r, r.Price
+			return q, nil
+		}
+	}
+	return PriceQuote{}, fmt.Errorf("%w: zone %q, %d g", ErrNoPriceRate, q.Zone, q.ChargeableWeight)
+}
+
+// PricingStorage записывает стоимость доставки при регистрации посылки.
+// Цена фиксируется один раз и не пересчитывается при смене адреса или
+// тарифа. Посылка без подходящего тарифа регистрируется с нулевой ценой,
+// ошибка логируется.
+type PricingStorage struct {
+	ParcelStorage
+	pricer Pricer
+	logger *slog.Logger
+}
+
+var _ ParcelStorage = PricingStorage{}
+
+func NewPricingStorage(next ParcelStorage, pricer Pricer, logger *slog.Logger) PricingStorage {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return PricingStorage{ParcelStorage: next, pricer: pricer, logger: logger}
+}
+
+// Add считает цену посылки, если она не задана явно, например при переносе.
+func (s PricingStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	if p.Price == 0 {
+		q, err := s.pricer.Quote(ctx, p)
+		switch {
+		case err == nil:
+			p.Price = q.Price
+		case errors.Is(err, ErrInvalidDimensions):
+			// отказ вернёт хранилище
+		default:
+			s.logger.WarnContext(ctx, "quote parcel price", "client", p.Client, "error", err)
+		}
+	}
+
+	return s.ParcelStorage.Add(ctx, p)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testPriceTariff — зоны для тестов цены
+var testPriceTariff = Tariff{Zones: []Zone{
+	{Name: "Москва", Match: []string{"Москва"}, TransitDays: 1},
+	{Name: "Северо-Запад", Match: []string{"Санкт-Петербург"}, TransitDays: 3},
+}}
+
+// setTestPriceRates заполняет тарифную сетку хранилища store
+func setTestPriceRates(t *testing.T, store ParcelStore) {
+	t.Helper()
+
+	for _, r := range []PriceRate{
+		{Zone: "Москва", MaxWeightGrams: 1000, Price: 25000},
+		{Zone: "Москва", MaxWeightGrams: 5000, Price: 40000},
+		{Zone: "", MaxWeightGrams: 1000, Price: 50000},
+		{Zone: "", MaxWeightGrams: 20000, Price: 120000},
+	} {
+		require.NoError(t, store.SetPriceRate(context.Background(), r))
+	}
+}
+
+// TestPriceRates проверяет запись и поиск тарифов
+func TestPriceRates(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	setTestPriceRates(t, store)
+
+	// повторная запись меняет цену, а не добавляет строку
+	require.NoError(t, store.SetPriceRate(ctx, PriceRate{Zone: "Москва", MaxWeightGrams: 1000, Price: 30000}))
+
+	// check
+	rates, err := store.ListPriceRates(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []PriceRate{
+		{Zone: "", MaxWeightGrams: 1000, Price: 50000},
+		{Zone: "", MaxWeightGrams: 20000, Price: 120000},
+		{Zone: "Москва", MaxWeightGrams: 1000, Price: 30000},
+		{Zone: "Москва", MaxWeightGrams: 5000, Price: 40000},
+	}, rates)
+
+	r, ok, err := store.FindPriceRate(ctx, "Москва", 1001)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 5000, r.MaxWeightGrams)
+
+	_, ok, err = store.FindPriceRate(ctx, "Москва", 5001)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, store.DeletePriceRate(ctx, "Москва", 5000))
+	rates, err = store.ListPriceRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+
+	require.ErrorIs(t, store.SetPriceRate(ctx, PriceRate{Zone: "Москва", Price: 100}), ErrInvalidPriceRate)
+	require.ErrorIs(t, store.SetPriceRate(ctx, PriceRate{MaxWeightGrams: 100, Price: -1}), ErrInvalidPriceRate)
+}
+
+// TestPricerQuote проверяет расчёт стоимости по зоне и оплачиваемому весу
+func TestPricerQuote(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	setTestPriceRates(t, store)
+	pricer := NewPricer(store, NewDeliveryEstimator(testPriceTariff))
+
+	// check
+	q, err := pricer.Quote(ctx, Parcel{RecipientAddress: "Москва, ул. Тверская", WeightGrams: 800})
+	require.NoError(t, err)
+	require.Equal(t, "Москва", q.Zone)
+	require.Equal(t, int64(25000), q.Price)
+
+	// лёгкая, но объёмная коробка оплачивается по объёмному весу 4,8 кг
+	box := Parcel{RecipientAddress: "Москва", WeightGrams: 800, LengthMM: 400, WidthMM: 300, HeightMM: 200}
+	q, err = pricer.Quote(ctx, box)
+	require.NoError(t, err)
+	require.Equal(t, 4800, q.ChargeableWeight)
+	require.Equal(t, int64(40000), q.Price)
+
+	// тяжелее сетки зоны и зона без тарифов считаются по зоне по умолчанию
+	box.WeightGrams = 8000
+	q, err = pricer.Quote(ctx, box)
+	require.NoError(t, err)
+	require.Equal(t, int64(120000), q.Price)
+	require.Equal(t, "", q.Rate.Zone)
+
+	q, err = pricer.Quote(ctx, Parcel{RecipientAddress: "Санкт-Петербург", WeightGrams: 500})
+	require.NoError(t, err)
+	require.Equal(t, "Северо-Запад", q.Zone)
+	require.Equal(t, int64(50000), q.Price)
+
+	_, err = pricer.Quote(ctx, Parcel{RecipientAddress: "Владивосток", WeightGrams: 30000})
+	require.ErrorIs(t, err, ErrNoPriceRate)
+
+	_, err = pricer.Quote(ctx, Parcel{RecipientAddress: "Москва", WeightGrams: -1})
+	require.ErrorIs(t, err, ErrInvalidDimensions)
+}
+
+// TestPricingStorage проверяет запись цены при регистрации посылки
+func TestPricingStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	sqlStore := NewParcelStore(openTempDB(t))
+	setTestPriceRates(t, sqlStore)
+	store := NewPricingStorage(sqlStore, NewPricer(sqlStore, NewDeliveryEstimator(testPriceTariff)), nil)
+
+	parcel := getTestParcel()
+	parcel.RecipientAddress = "Москва, ул. Тверская"
+	parcel.WeightGrams = 3000
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(40000), p.Price)
+
+	// цена не пересчитывается при смене тарифа
+	require.NoError(t, sqlStore.SetPriceRate(ctx, PriceRate{Zone: "Москва", MaxWeightGrams: 5000, Price: 45000}))
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(40000), p.Price)
+
+	// явно заданная цена сохраняется
+	parcel.Price = 100
+	parcel.TrackCode = ""
+	id, err = store.Add(ctx, parcel)
+	require.NoError(t, err)
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), p.Price)
+
+	// без подходящего тарифа посылка регистрируется с нулевой ценой
+	parcel.Price = 0
+	parcel.WeightGrams = 30000
+	parcel.TrackCode = ""
+	id, err = store.Add(ctx, parcel)
+	require.NoError(t, err)
+	p, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Zero(t, p.Price)
+
+	parcel.WeightGrams = -1
+	_, err = store.Add(ctx, parcel)
+	require.ErrorIs(t, err, ErrInvalidDimensions)
+}
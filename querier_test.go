@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/testutil"
+)
+
+// TestFilterQuery проверяет запросы ListAll с фильтром в диалектах
+// PostgreSQL и MySQL без настоящей СУБД
+func TestFilterQuery(t *testing.T) {
+	filter := Filter{Statuses: []Status{ParcelStatusRegistered, ParcelStatusSent}, Address: "Москва", Courier: 7}
+
+	t.Run("postgres", func(t *testing.T) {
+		// prepare
+		mock := testutil.NewMockQuerier(t)
+		store := NewPostgresParcelStore(mock)
+		where := " WHERE deleted_at IS NULL AND status IN ($1, $2)" +
+			" AND (strpos(sender_address, $3) > 0 OR strpos(recipient_address, $4) > 0)" +
+			" AND courier_id = $5 AND tenant_id = $6"
+		args := []driver.Value{"registered", "sent", "Москва", "Москва", 7, "acme"}
+
+		mock.ExpectQuery("SELECT COUNT(*) FROM parcel" + where).WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery("SELECT " + parcelColumns + " FROM parcel" + where + " ORDER BY number ASC LIMIT $7 OFFSET $8").
+			WithArgs(append(args, math.MaxInt32, 0)...).
+			WillReturnRows(sqlmock.NewRows(nil))
+
+		// check
+		page, err := store.ListAll(WithTenant(context.Background(), "acme"), filter, ListOptions{})
+		require.NoError(t, err)
+		require.Zero(t, page.Total)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		// prepare
+		mock := testutil.NewMockQuerier(t)
+		store := NewMySQLParcelStore(mock)
+		where := " WHERE deleted_at IS NULL AND status IN (?, ?)" +
+			" AND (LOCATE(CAST(? AS BINARY), sender_address) > 0 OR LOCATE(CAST(? AS BINARY), recipient_address) > 0)" +
+			" AND courier_id = ?"
+
+		mock.ExpectQuery("SELECT COUNT(*) FROM parcel"+where).
+			WithArgs("registered", "sent", "Москва", "Москва", 7).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery("SELECT "+parcelColumns+" FROM parcel"+where+" ORDER BY number DESC LIMIT ? OFFSET ?").
+			WithArgs("registered", "sent", "Москва", "Москва", 7, 10, 20).
+			WillReturnRows(sqlmock.NewRows(nil))
+
+		// check
+		_, err := store.ListAll(context.Background(), filter, ListOptions{Limit: 10, Offset: 20, Desc: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// недопустимый фильтр не доходит до БД: у мока нет ожиданий
+		store := NewPostgresParcelStore(testutil.NewMockQuerier(t))
+		_, err := store.ListAll(context.Background(), Filter{Statuses: []Status{"lost"}}, ListOptions{})
+		require.ErrorIs(t, err, ErrUnknownStatus)
+	})
+}
+
+// TestQuerierWithoutDB проверяет, что хранилище поверх Querier,
+// который не *sql.DB, отказывается от транзакций и проверки готовности
+func TestQuerierWithoutDB(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	mock := testutil.NewMockQuerier(t)
+	store := NewParcelStore(mock).WithStatementCache().WithPool(PoolConfig{MaxOpenConns: 1})
+
+	// check
+	err := store.WithTx(ctx, func(ParcelTx) error { return nil })
+	require.ErrorIs(t, err, ErrNoDB)
+	_, err = store.Health(ctx)
+	require.ErrorIs(t, err, ErrNoDB)
+	require.NoError(t, store.Close())
+
+	// поверх DB мока транзакции работают
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	require.NoError(t, NewParcelStore(mock.DB()).WithTx(ctx, func(ParcelTx) error { return nil }))
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrRateLimited возвращается запросу, для которого не хватило токенов.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// clientIDHeader — заголовок HTTP и ключ метаданных gRPC, по которому
+// запросы делятся между клиентами; без него клиентом считается адрес.
+const clientIDHeader = "X-Client-ID"
+
+// Области ограничения для метрики parcel_api_rate_limited_total.
+const (
+	rateScopeGlobal = "global"
+	rateScopeClient = "client"
+)
+
+// rateSweepInterval — как часто RateLimiter забывает клиентов, чьи
+// корзины успели наполниться: такая корзина ничем не отличается от новой.
+const rateSweepInterval = time.Minute
+
+// RateLimitConfig ограничивает частоту запросов к HTTP и gRPC API.
+// Нулевой Rate снимает соответствующее ограничение.
+type RateLimitConfig struct {
+	// Global — общий лимит всех запросов, бережёт единственного писателя SQLite
+	Global RateLimit `yaml:"global"`
+	// PerClient — лимит запросов одного клиента, см. X-Client-ID
+	PerClient RateLimit `yaml:"per_client"`
+}
+
+// RateLimit — параметры корзины токенов: Rate запросов в секунду
+// в среднем и до Burst запросов подряд. Нулевой Burst равен Rate.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+func (c RateLimitConfig) enabled() bool {
+	return c.Global.Rate > 0 || c.PerClient.Rate > 0
+}
+
+func (c RateLimitConfig) validate() error {
+	for _, l := range []RateLimit{c.Global, c.PerClient} {
+		if l.Rate < 0 || l.Burst < 0 {
+			return errors.New("config: rate limit must not be negative")
+		}
+	}
+	return nil
+}
+
+func (l RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.Rate))
+}
+
+// tokenBucket — корзина токенов: пополняется со скоростью rate в секунду
+// до burst, каждый запрос забирает один токен.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill добавляет токены, накопившиеся с прошлого обращения.
+func (b *tokenBucket) refill(l RateLimit, now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst(), b.tokens+elapsed*l.Rate)
+	}
+	b.last = now
+}
+
+// wait возвращает, через сколько в корзине появится токен.
+func (b *tokenBucket) wait(l RateLimit) time.Duration {
+	return time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+}
+
+// RateLimiter ограничивает запросы общей корзиной и корзиной на клиента.
+type RateLimiter struct {
+	cfg RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	global    tokenBucket
+	clients   map[string]*tokenBucket
+	lastSweep time.Time
+
+	rejected *prometheus.CounterVec
+}
+
+// NewRateLimiter создаёт ограничитель и регистрирует в reg счётчик
+// отклонённых запросов parcel_api_rate_limited_total; reg может быть nil.
+func NewRateLimiter(cfg RateLimitConfig, reg prometheus.Registerer) (*RateLimiter, error) {
+	l := &RateLimiter{
+		cfg:     cfg,
+		now:     time.Now,
+		clients: map[string]*tokenBucket{},
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parcel_api_rate_limited_total",
+			Help: "Number of API requests rejected by the rate limiter by scope.",
+		}, []string{"scope"}),
+	}
+	l.global = tokenBucket{tokens: cfg.Global.burst(), last: l.now()}
+	l.lastSweep = l.now()
+
+	if reg != nil {
+		if err := reg.Register(l.rejected); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Allow забирает токен клиента client и общий токен. Если запрос
+// не укладывается в лимит, Allow возвращает false и время, через которое
+// стоит повторить запрос; токены при отказе не тратятся.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	var cb *tokenBucket
+	if l.cfg.PerClient.Rate > 0 {
+		cb = l.clients[client]
+		if cb == nil {
+			cb = &tokenBucket{tokens: l.cfg.PerClient.burst(), last: now}
+			l.clients[client] = cb
+		}
+		cb.refill(l.cfg.PerClient, now)
+		if cb.tokens < 1 {
+			l.rejected.WithLabelValues(rateScopeClient).Inc()
+			return false, cb.wait(l.cfg.PerClient)
+		}
+	}
+	if l.cfg.Global.Rate > 0 {
+		l.global.refill(l.cfg.Global, now)
+		if l.global.tokens < 1 {
+			l.rejected.WithLabelValues(rateScopeGlobal).Inc()
+			return false, l.global.wait(l.cfg.Global)
+		}
+		l.global.tokens--
+	}
+	if cb != nil {
+		cb.tokens--
+	}
+
+	return true, 0
+}
+
+// sweep удаляет полные корзины клиентов, чтобы карта не росла
+// с каждым новым адресом.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.clients {
+		b.refill(l.cfg.PerClient, now)
+		if b.tokens >= l.cfg.PerClient.burst() {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// Middleware отвечает 429 Too Many Requests с заголовком Retry-After
+// на запросы сверх лимита.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := r.Header.Get(clientIDHeader)
+		if client == "" {
+			client = remoteHost(r.RemoteAddr)
+		}
+
+		if ok, wait := l.Allow(client); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, ErrRateLimited)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryInterceptor отклоняет вызовы gRPC сверх лимита с кодом ResourceExhausted.
+func (l *RateLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if ok, _ := l.Allow(grpcClient(ctx)); !ok {
+			return nil, status.Error(codes.ResourceExhausted, ErrRateLimited.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor считает вызовом каждое сообщение клиента в потоке,
+// поэтому один долгий поток не обходит лимит. Сообщение сверх лимита
+// закрывает поток с кодом ResourceExhausted.
+func (l *RateLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, limitedStream{ServerStream: ss, limiter: l, client: grpcClient(ss.Context())})
+	}
+}
+
+// limitedStream проверяет лимит при каждом полученном сообщении.
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *RateLimiter
+	client  string
+}
+
+func (s limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if ok, _ := s.limiter.Allow(s.client); !ok {
+		return status.Error(codes.ResourceExhausted, ErrRateLimited.Error())
+	}
+	return nil
+}
+
+// grpcClient определяет клиента вызова по метаданным или адресу соединения.
+func grpcClient(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(clientIDHeader); len(v) > 0 {
+			return v[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return remoteHost(p.Addr.String())
+	}
+	return ""
+}
+
+// remoteHost отбрасывает порт: соединения одного клиента идут с разных портов.
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
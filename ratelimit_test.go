@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newTestRateLimiter создаёт ограничитель с часами, которые двигает тест
+func newTestRateLimiter(t *testing.T, cfg RateLimitConfig) (*RateLimiter, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewRateLimiter(cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	l.now = func() time.Time { return now }
+	l.global.last = now
+	l.lastSweep = now
+
+	return l, &now
+}
+
+// TestRateLimiter проверяет корзины токенов клиента и общую корзину
+func TestRateLimiter(t *testing.T) {
+	// prepare
+	l, now := newTestRateLimiter(t, RateLimitConfig{
+		Global:    RateLimit{Rate: 10, Burst: 3},
+		PerClient: RateLimit{Rate: 1, Burst: 2},
+	})
+
+	// check
+	ok, _ := l.Allow("a")
+	require.True(t, ok)
+	ok, _ = l.Allow("a")
+	require.True(t, ok)
+	ok, wait := l.Allow("a")
+	require.False(t, ok)
+	require.Equal(t, time.Second, wait)
+
+	// у другого клиента своя корзина, но общая уже почти пуста
+	ok, _ = l.Allow("b")
+	require.True(t, ok)
+	ok, wait = l.Allow("b")
+	require.False(t, ok)
+	require.Equal(t, 100*time.Millisecond, wait)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(l.rejected.WithLabelValues(rateScopeClient)))
+	require.Equal(t, 1.0, testutil.ToFloat64(l.rejected.WithLabelValues(rateScopeGlobal)))
+
+	// корзины пополняются со временем, полные забываются
+	*now = now.Add(time.Second)
+	ok, _ = l.Allow("a")
+	require.True(t, ok)
+
+	*now = now.Add(2 * rateSweepInterval)
+	ok, _ = l.Allow("c")
+	require.True(t, ok)
+	require.Len(t, l.clients, 1)
+}
+
+// TestRateLimitMiddleware проверяет ответ 429 на запросы сверх лимита
+func TestRateLimitMiddleware(t *testing.T) {
+	// prepare
+	l, _ := newTestRateLimiter(t, RateLimitConfig{PerClient: RateLimit{Rate: 0.5, Burst: 1}})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func(client string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/parcels/1", nil)
+		if client != "" {
+			req.Header.Set(clientIDHeader, client)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// check
+	require.Equal(t, http.StatusNoContent, do("a").Code)
+	rec := do("a")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+	require.Contains(t, rec.Body.String(), ErrRateLimited.Error())
+
+	// без заголовка клиентом считается адрес
+	require.Equal(t, http.StatusNoContent, do("").Code)
+	require.Equal(t, http.StatusTooManyRequests, do("").Code)
+	require.Equal(t, http.StatusNoContent, do("b").Code)
+}
+
+// TestRateLimitInterceptor проверяет код ResourceExhausted для gRPC
+func TestRateLimitInterceptor(t *testing.T) {
+	// prepare
+	l, _ := newTestRateLimiter(t, RateLimitConfig{Global: RateLimit{Rate: 1}})
+	interceptor := l.UnaryInterceptor()
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(clientIDHeader, "a"))
+
+	// check
+	resp, err := interceptor(ctx, nil, nil, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	_, err = interceptor(ctx, nil, nil, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// testServerStream — поток gRPC для проверки перехватчиков без сервера.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s testServerStream) RecvMsg(any) error {
+	return nil
+}
+
+// TestRateLimitStreamInterceptor проверяет, что лимит считает каждое
+// сообщение потока
+func TestRateLimitStreamInterceptor(t *testing.T) {
+	// prepare
+	l, _ := newTestRateLimiter(t, RateLimitConfig{Global: RateLimit{Rate: 1}})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(clientIDHeader, "a"))
+	var errs []error
+	handler := func(_ any, ss grpc.ServerStream) error {
+		for range 2 {
+			errs = append(errs, ss.RecvMsg(nil))
+		}
+		return nil
+	}
+
+	// check
+	require.NoError(t, l.StreamInterceptor()(nil, testServerStream{ctx: ctx}, nil, handler))
+	require.NoError(t, errs[0])
+	require.Equal(t, codes.ResourceExhausted, status.Code(errs[1]))
+}
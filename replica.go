@@ -0,0 +1,116 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ReplicaConfig направляет чтения SQL-хранилища на реплику DSN того же
+// драйвера. ReadYourWrites — сколько после Add посылка читается с основной
+// БД, пока реплика её догоняет; ноль выключает это.
+type ReplicaConfig struct {
+	DSN            string        `yaml:"dsn"`
+	ReadYourWrites time.Duration `yaml:"read_your_writes"`
+}
+
+func (c ReplicaConfig) validate(driver string) error {
+	if c.ReadYourWrites < 0 {
+		return errors.New("config: replica read_your_writes must not be negative")
+	}
+	if c.DSN != "" && driver == "memory" {
+		return errors.New("config: storage memory does not support replicas")
+	}
+	return nil
+}
+
+// WithReplica возвращает копию хранилища, которая читает посылки, их историю,
+// события, списки, поиск и отчёты с реплики replica, а пишет в основную БД.
+// Внутри транзакции всё выполняется в ней на основной БД. Реплика
+// закрывается в Close вместе с основной БД.
+func (s ParcelStore) WithReplica(replica *sql.DB) ParcelStore {
+	s.replica = replica
+	if s.stmts != nil {
+		s.replicaStmts = &stmtCache{db: replica, stmts: map[string]*sql.Stmt{}}
+	}
+	s.r = s.wrapReplica()
+	return s
+}
+
+// WithReadYourWrites возвращает копию хранилища, которая window после Add
+// читает добавленную посылку по номеру с основной БД: реплика получает
+// изменения с задержкой, а клиент ждёт увидеть только что созданную посылку.
+// Без WithReplica настройка ни на что не влияет.
+func (s ParcelStore) WithReadYourWrites(window time.Duration) ParcelStore {
+	s.fresh = &freshParcels{window: window, added: map[int]time.Time{}}
+	return s
+}
+
+// wrapReplica оборачивает реплику её кэшем запросов и трассировкой.
+func (s ParcelStore) wrapReplica() querier {
+	if s.replica == nil {
+		return nil
+	}
+	var q querier = s.replica
+	if s.replicaStmts != nil {
+		q = cachingQuerier{q: q, cache: s.replicaStmts}
+	}
+	if s.tracer != nil {
+		q = tracingQuerier{q: q, tracer: s.tracer, system: s.dialect.name}
+	}
+	return q
+}
+
+// reader возвращает querier для чтения: реплику, если она задана, кроме
+// транзакций и недавно добавленной посылки number. Ноль в number означает
+// чтение, не привязанное к одной посылке.
+func (s ParcelStore) reader(number int) querier {
+	if s.r == nil {
+		return s.q
+	}
+	if _, ok := unwrapQuerier(s.q).(*sql.Tx); ok {
+		return s.q
+	}
+	if number != 0 && s.fresh.has(number) {
+		return s.q
+	}
+	return s.r
+}
+
+// freshParcels помнит недавно добавленные посылки для WithReadYourWrites.
+type freshParcels struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	added map[int]time.Time
+}
+
+// add отмечает посылку number только что добавленной и забывает
+// отметки старше window.
+func (f *freshParcels) add(number int) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for n, at := range f.added {
+		if now.Sub(at) >= f.window {
+			delete(f.added, n)
+		}
+	}
+	f.added[number] = now
+}
+
+func (f *freshParcels) has(number int) bool {
+	if f == nil {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	at, ok := f.added[number]
+	return ok && time.Since(at) < f.window
+}
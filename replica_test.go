@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplica проверяет, что чтения идут на реплику, а записи
+// и транзакции — в основную БД. Отдельная пустая БД изображает
+// реплику, которая ещё не получила изменений
+func TestReplica(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	primary, replica := openTempDB(t), openTempDB(t)
+	store := NewParcelStore(primary).WithReplica(replica)
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	parcels, err := store.GetByClient(ctx, getTestParcel().Client)
+	require.NoError(t, err)
+	require.Empty(t, parcels)
+
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+	p, err := NewParcelStore(primary).Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, p.Status)
+
+	require.NoError(t, store.WithTx(ctx, func(tx ParcelTx) error {
+		_, err := tx.Get(ctx, id)
+		return err
+	}))
+
+	// кэш запросов готовит чтения на реплике
+	cached := NewParcelStore(primary).WithStatementCache().WithReplica(replica)
+	_, err = cached.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestReadYourWrites проверяет, что только что добавленная посылка
+// читается с основной БД, пока не пройдёт окно
+func TestReadYourWrites(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t)).WithReplica(openTempDB(t)).WithReadYourWrites(50 * time.Millisecond)
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, id, p.Number)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestReplicaConfig проверяет подключение реплики по настройкам
+func TestReplicaConfig(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.DSN = filepath.Join(dir, "primary.db")
+	cfg.Replica = ReplicaConfig{DSN: filepath.Join(dir, "replica.db"), ReadYourWrites: time.Second}
+
+	// check
+	store, _, err := NewParcelStoreFromConfig(cfg)
+	require.NoError(t, err)
+	s, ok := store.(ParcelStore)
+	require.True(t, ok)
+	require.NotNil(t, s.replica)
+	require.NotNil(t, s.fresh)
+	require.NoError(t, s.Close())
+
+	cfg.Replica.ReadYourWrites = -time.Second
+	require.Error(t, cfg.Validate())
+
+	cfg = DefaultConfig()
+	cfg.Driver = "memory"
+	cfg.Replica.DSN = "replica.db"
+	require.Error(t, cfg.Validate())
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Действия правил хранения.
+const (
+	// RetentionAnonymize стирает адреса посылки и описания её событий,
+	// как ForgetClient, но оставляет саму посылку для статистики
+	RetentionAnonymize = "anonymize"
+	// RetentionDelete удаляет посылку вместе с историей и событиями
+	RetentionDelete = "delete"
+)
+
+// RetentionConfig задаёт сроки хранения посылок. Правила применяются
+// по порядку; пустой список выключает удаление по срокам.
+type RetentionConfig struct {
+	Rules []RetentionRule `yaml:"rules"`
+	// Every — интервал прогонов parcelctl retention --every по умолчанию
+	Every time.Duration `yaml:"every"`
+	// DryRun только считает посылки, ничего не меняя
+	DryRun bool `yaml:"dry_run"`
+}
+
+// RetentionRule применяет Action к посылкам в статусе Status, перешедшим
+// в него больше AfterDays дней назад. Пустой Status означает delivered.
+type RetentionRule struct {
+	Action    string `yaml:"action"`
+	Status    Status `yaml:"status"`
+	AfterDays int    `yaml:"after_days"`
+}
+
+func (r RetentionRule) status() Status {
+	if r.Status == "" {
+		return ParcelStatusDelivered
+	}
+	return r.Status
+}
+
+func (r RetentionRule) String() string {
+	return fmt.Sprintf("%s %s after %d days", r.Action, r.status(), r.AfterDays)
+}
+
+func (c RetentionConfig) validate() error {
+	if c.Every < 0 {
+		return errors.New("config: retention interval must not be negative")
+	}
+	for _, r := range c.Rules {
+		switch r.Action {
+		case RetentionAnonymize, RetentionDelete:
+		default:
+			return fmt.Errorf("config: unknown retention action %q", r.Action)
+		}
+		if r.AfterDays <= 0 {
+			return fmt.Errorf("config: retention rule %q: after_days must be positive", r.String())
+		}
+	}
+	return nil
+}
+
+// RetentionStore применяет правила хранения. Реализуется только
+// SQL-хранилищем: в памяти посылки не переживают перезапуск.
+type RetentionStore interface {
+	// ApplyRetention применяет rule к посылкам, перешедшим в статус
+	// правила раньше cutoff, и возвращает их количество; при dryRun
+	// посылки только считаются
+	ApplyRetention(ctx context.Context, rule RetentionRule, cutoff time.Time, dryRun bool) (int, error)
+}
+
+var _ RetentionStore = ParcelStore{}
+
+// ApplyRetention применяет правило к активным, удалённым и архивным
+// посылкам. Для архивных момент перехода в статус неизвестен, и вместо
+// него берётся время архивации — оно не раньше доставки. Уже обезличенные
+// посылки правило anonymize не считает, а посылки с открытыми претензиями
+// правило delete пропускает. Правило не зависит от арендатора ctx.
+func (s ParcelStore) ApplyRetention(ctx context.Context, rule RetentionRule, cutoff time.Time, dryRun bool) (int, error) {
+	status := rule.status()
+	// у обезличенной посылки не остаётся адресов, а адрес получателя обязателен
+	var pending, archivePending string
+	switch rule.Action {
+	case RetentionAnonymize:
+		pending = " AND recipient_address <> ''"
+		archivePending = pending
+	case RetentionDelete:
+		// посылки с открытыми претензиями удалять нельзя
+		pending = withoutOpenClaims("parcel")
+		archivePending = withoutOpenClaims("parcel_archive")
+	}
+
+	var affected int
+	err := s.withTx(ctx, func(tx ParcelStore) error {
+		numbers, err := tx.numbers(ctx,
+			"SELECT number FROM parcel WHERE status = ? AND "+reachedStatusBefore+pending+
+				" UNION SELECT number FROM parcel_archive WHERE status = ? AND archived_at < ?"+archivePending,
+			status, status, status, formatTime(cutoff), tx.dialect.timeArg(cutoff),
+			status, formatTime(cutoff))
+		if err != nil {
+			return err
+		}
+		affected = len(numbers)
+		if dryRun {
+			return nil
+		}
+
+		for start := 0; start < len(numbers); start += inBatchSize {
+			batch := numbers[start:min(start+inBatchSize, len(numbers))]
+			switch rule.Action {
+			case RetentionAnonymize:
+				err = tx.anonymizeParcels(ctx, batch)
+			case RetentionDelete:
+				err = tx.deleteParcels(ctx, batch)
+			default:
+				err = fmt.Errorf("unknown retention action %q", rule.Action)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// anonymizeParcels стирает адреса, координаты и подписи посылок numbers
+// в parcel и parcel_archive, описания их событий и адреса в outbox.
+func (s ParcelStore) anonymizeParcels(ctx context.Context, numbers []int) error {
+	in, args := numbersIn("number", numbers)
+	_, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET sender_address = '', recipient_address = '', lat = NULL, lon = NULL, signed_by = '', signed_at = NULL,"+
+			" version = version + 1, updated_at = ? WHERE"+in),
+		append([]any{s.timestampArg()}, args...)...)
+	if err != nil {
+		return err
+	}
+	_, err = s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel_archive SET sender_address = '', recipient_address = '' WHERE"+in), args...)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.forgetParcels(ctx, numbers)
+	return err
+}
+
+// deleteParcels удаляет посылки numbers из parcel и parcel_archive
+// вместе со всем, что ссылается на них по номеру.
+func (s ParcelStore) deleteParcels(ctx context.Context, numbers []int) error {
+	in, args := numbersIn("parcel_number", numbers)
+	for _, table := range []string{"parcel_status_history", "parcel_event", "parcel_checkpoint", "parcel_outbox", "parcel_note", "parcel_attachment", "parcel_signature", "delivery_attempt", "courier_manifest_stop", "claims"} {
+		if _, err := s.q.ExecContext(ctx, s.dialect.rebind("DELETE FROM "+table+" WHERE"+in), args...); err != nil {
+			return err
+		}
+	}
+
+	in, _ = numbersIn("number", numbers)
+	for _, table := range []string{"parcel", "parcel_archive"} {
+		if _, err := s.q.ExecContext(ctx, s.dialect.rebind("DELETE FROM "+table+" WHERE"+in), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// numbersIn возвращает условие « column IN (?, ...)» для номеров numbers.
+func numbersIn(column string, numbers []int) (string, []any) {
+	args := make([]any, 0, len(numbers))
+	for _, number := range numbers {
+		args = append(args, number)
+	}
+	return " " + column + " IN (" + placeholders(len(numbers)) + ")", args
+}
+
+// RetentionReport — итог применения одного правила.
+type RetentionReport struct {
+	Rule   RetentionRule
+	Cutoff time.Time
+	// Parcels — сколько посылок правило изменило или, при DryRun, изменило бы
+	Parcels int
+	DryRun  bool
+}
+
+// RetentionRunner периодически применяет правила хранения, как ArchiveRunner.
+type RetentionRunner struct {
+	store    RetentionStore
+	rules    []RetentionRule
+	interval time.Duration
+	dryRun   bool
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewRetentionRunner(store RetentionStore, cfg RetentionConfig) RetentionRunner {
+	return RetentionRunner{store: store, rules: cfg.Rules, interval: cfg.Every, dryRun: cfg.DryRun,
+		logger: slog.Default(), now: time.Now}
+}
+
+// WithLogger возвращает копию планировщика, пишущую отчёты в logger.
+func (r RetentionRunner) WithLogger(logger *slog.Logger) RetentionRunner {
+	r.logger = logger
+	return r
+}
+
+// Run применяет правила сразу и затем каждые interval, пока не отменён ctx.
+// Ошибка одного прогона логируется и не останавливает планировщик.
+func (r RetentionRunner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce применяет правила по порядку и возвращает отчёт по каждому.
+// При ошибке правила следующие не применяются.
+func (r RetentionRunner) RunOnce(ctx context.Context) ([]RetentionReport, error) {
+	now := r.now()
+	reports := make([]RetentionReport, 0, len(r.rules))
+	for _, rule := range r.rules {
+		cutoff := now.AddDate(0, 0, -rule.AfterDays)
+		n, err := r.store.ApplyRetention(ctx, rule, cutoff, r.dryRun)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "apply retention", "rule", rule.String(), "error", err)
+			}
+			return reports, err
+		}
+
+		r.logger.InfoContext(ctx, "apply retention", "rule", rule.String(), "cutoff", formatTime(cutoff),
+			"parcels", n, "dry_run", r.dryRun)
+		reports = append(reports, RetentionReport{Rule: rule, Cutoff: cutoff, Parcels: n, DryRun: r.dryRun})
+	}
+	return reports, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyRetention проверяет обезличивание и удаление посылок по сроку хранения
+func TestApplyRetention(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	db := openTempDB(t)
+	store := NewParcelStore(db)
+
+	delivered, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, delivered, ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, delivered, ParcelStatusDelivered))
+	_, err = store.AddEvent(ctx, delivered, "handover", "вручена лично", time.Now())
+	require.NoError(t, err)
+
+	registered, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	anonymize := RetentionRule{Action: RetentionAnonymize, AfterDays: 90}
+	remove := RetentionRule{Action: RetentionDelete, AfterDays: 365}
+	later := time.Now().Add(time.Hour)
+
+	// dry run
+	n, err := store.ApplyRetention(ctx, anonymize, later, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	p, err := store.Get(ctx, delivered)
+	require.NoError(t, err)
+	require.NotEmpty(t, p.RecipientAddress)
+
+	n, err = store.ApplyRetention(ctx, anonymize, time.Now().Add(-time.Hour), false)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// anonymize
+	n, err = store.ApplyRetention(ctx, anonymize, later, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	p, err = store.Get(ctx, delivered)
+	require.NoError(t, err)
+	require.Empty(t, p.SenderAddress)
+	require.Empty(t, p.RecipientAddress)
+	require.Equal(t, ParcelStatusDelivered, p.Status)
+	events, err := store.GetEvents(ctx, delivered)
+	require.NoError(t, err)
+	require.Empty(t, events[0].Description)
+
+	n, err = store.ApplyRetention(ctx, anonymize, later, false)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// delete archived
+	archived, err := store.ArchiveOlderThan(ctx, later)
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	n, err = store.ApplyRetention(ctx, remove, later.Add(time.Hour), false)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// check
+	var left int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM parcel_archive").Scan(&left))
+	require.Zero(t, left)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM parcel_status_history WHERE parcel_number = ?", delivered).Scan(&left))
+	require.Zero(t, left)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM parcel_event WHERE parcel_number = ?", delivered).Scan(&left))
+	require.Zero(t, left)
+
+	p, err = store.Get(ctx, registered)
+	require.NoError(t, err)
+	require.NotEmpty(t, p.RecipientAddress)
+}
+
+// TestRetentionRunner проверяет отчёт прогона правил и проверку настроек
+func TestRetentionRunner(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	number, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, number, ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, number, ParcelStatusDelivered))
+
+	cfg := RetentionConfig{DryRun: true, Rules: []RetentionRule{
+		{Action: RetentionAnonymize, AfterDays: 90},
+		{Action: RetentionDelete, Status: ParcelStatusSent, AfterDays: 30},
+	}}
+	require.NoError(t, cfg.validate())
+
+	runner := NewRetentionRunner(store, cfg)
+	runner.now = func() time.Time { return time.Now().AddDate(0, 0, 100) }
+
+	// run
+	reports, err := runner.RunOnce(ctx)
+	require.NoError(t, err)
+
+	// check
+	require.Len(t, reports, 2)
+	require.Equal(t, 1, reports[0].Parcels)
+	require.True(t, reports[0].DryRun)
+	require.Zero(t, reports[1].Parcels)
+
+	p, err := store.Get(ctx, number)
+	require.NoError(t, err)
+	require.NotEmpty(t, p.RecipientAddress)
+
+	require.Error(t, RetentionConfig{Rules: []RetentionRule{{Action: "archive", AfterDays: 1}}}.validate())
+	require.Error(t, RetentionConfig{Rules: []RetentionRule{{Action: RetentionDelete}}}.validate())
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// RetryPolicy задаёт повторы операций при временных ошибках БД.
+// Задержка перед n-й повторной попыткой равна BaseDelay*2^(n-1),
+// но не больше MaxDelay, и уменьшается на случайную долю до Jitter.
+type RetryPolicy struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+	Jitter      float64       `yaml:"jitter"`
+}
+
+// DefaultRetryPolicy возвращает политику для конкурентной записи в SQLite.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    500 * time.Millisecond,
+		Jitter:      0.2,
+	}
+}
+
+// delay возвращает паузу перед повторной попыткой attempt (начиная с 1).
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// do выполняет fn, повторяя её при временных ошибках, пока не кончатся
+// попытки или не будет отменён ctx.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.MaxAttempts || !IsTransient(err) {
+			return err
+		}
+
+		t := time.NewTimer(p.delay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-t.C:
+		}
+	}
+}
+
+// IsTransient сообщает, что операцию можно повторить: база занята
+// или заблокирована (SQLite), конфликт сериализации или взаимная
+// блокировка (PostgreSQL, MySQL).
+func IsTransient(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			return true
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1205, 1213:
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryStorage повторяет операции записи хранилища при временных ошибках
+// по заданной политике. Чтение выполняется как есть.
+type RetryStorage struct {
+	ParcelStorage
+	policy RetryPolicy
+}
+
+var _ ParcelStorage = RetryStorage{}
+
+// NewRetryStorage оборачивает хранилище повторами операций записи.
+func NewRetryStorage(next ParcelStorage, policy RetryPolicy) RetryStorage {
+	return RetryStorage{ParcelStorage: next, policy: policy}
+}
+
+func (s RetryStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	var number int
+	err := s.policy.do(ctx, func() (err error) {
+		number, err = s.ParcelStorage.Add(ctx, p)
+		return err
+	})
+	return number, err
+}
+
+func (s RetryStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.SetStatus(ctx, number, status)
+	})
+}
+
+func (s RetryStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.SetSenderAddress(ctx, number, address)
+	})
+}
+
+func (s RetryStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.SetRecipientAddress(ctx, number, address)
+	})
+}
+
+func (s RetryStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.SetETA(ctx, number, eta)
+	})
+}
+
+// MarkPaid повторяется безопасно: оплата той же транзакцией ничего не меняет.
+func (s RetryStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.MarkPaid(ctx, number, txRef)
+	})
+}
+
+func (s RetryStorage) Update(ctx context.Context, p Parcel) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.Update(ctx, p)
+	})
+}
+
+func (s RetryStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.Cancel(ctx, number, reason)
+	})
+}
+
+func (s RetryStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.Deliver(ctx, number, sig)
+	})
+}
+
+func (s RetryStorage) Delete(ctx context.Context, number int) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.Delete(ctx, number)
+	})
+}
+
+func (s RetryStorage) HardDelete(ctx context.Context, number int) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.HardDelete(ctx, number)
+	})
+}
+
+func (s RetryStorage) Restore(ctx context.Context, number int) error {
+	return s.policy.do(ctx, func() error {
+		return s.ParcelStorage.Restore(ctx, number)
+	})
+}
+
+func (s RetryStorage) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	var id int
+	err := s.policy.do(ctx, func() (err error) {
+		id, err = s.ParcelStorage.AddEvent(ctx, number, code, description, occurredAt)
+		return err
+	})
+	return id, err
+}
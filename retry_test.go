@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStorage возвращает err на первые fails вызовов Add
+type flakyStorage struct {
+	ParcelStorage
+	fails int
+	calls int
+	err   error
+}
+
+func (s *flakyStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	s.calls++
+	if s.calls <= s.fails {
+		return 0, s.err
+	}
+	return s.ParcelStorage.Add(ctx, p)
+}
+
+// TestIsTransient проверяет распознавание временных ошибок драйверов
+func TestIsTransient(t *testing.T) {
+	require.True(t, IsTransient(&pq.Error{Code: "40001"}))
+	require.True(t, IsTransient(&mysql.MySQLError{Number: 1213}))
+	require.False(t, IsTransient(&pq.Error{Code: "23505"}))
+	require.False(t, IsTransient(ErrParcelNotFound))
+	require.False(t, IsTransient(nil))
+}
+
+// TestRetryStorage проверяет повторы записи и отказ от повторов
+// для постоянных ошибок
+func TestRetryStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Jitter: 0.5}
+
+	// временная ошибка исчезает
+	flaky := &flakyStorage{ParcelStorage: NewMemoryParcelStore(), fails: 2, err: &pq.Error{Code: "40P01"}}
+	id, err := NewRetryStorage(flaky, policy).Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	require.Equal(t, 3, flaky.calls)
+
+	// попытки кончились
+	flaky = &flakyStorage{ParcelStorage: NewMemoryParcelStore(), fails: 5, err: &pq.Error{Code: "40001"}}
+	_, err = NewRetryStorage(flaky, policy).Add(ctx, getTestParcel())
+	require.Error(t, err)
+	require.Equal(t, 3, flaky.calls)
+
+	// постоянная ошибка не повторяется
+	flaky = &flakyStorage{ParcelStorage: NewMemoryParcelStore(), fails: 5, err: errors.New("constraint")}
+	_, err = NewRetryStorage(flaky, policy).Add(ctx, getTestParcel())
+	require.Error(t, err)
+	require.Equal(t, 1, flaky.calls)
+}
+
+// TestRetrySQLiteBusy проверяет повтор записи, пока база заблокирована другим соединением
+func TestRetrySQLiteBusy(t *testing.T) {
+	// prepare
+	path := filepath.Join(t.TempDir(), "busy.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, NewParcelStore(db).Migrate(ctx))
+
+	locker, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer locker.Close()
+
+	conn, err := locker.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+	require.NoError(t, err)
+
+	// без повторов
+	store := NewParcelStore(db)
+	_, err = store.Add(ctx, getTestParcel())
+	require.True(t, IsTransient(err), err)
+
+	// с повторами: блокировка снимается во время ожидания
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		conn.ExecContext(ctx, "ROLLBACK")
+	}()
+
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 5 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	_, err = NewRetryStorage(store, policy).Add(ctx, getTestParcel())
+	require.NoError(t, err)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// returnOfArg возвращает аргумент запроса для колонки return_of:
+// NULL у посылки, которая не возвращает другую.
+func returnOfArg(number int) any {
+	if number == 0 {
+		return nil
+	}
+	return number
+}
+
+// CreateReturn создаёт возвратную посылку для посылки number в статусе
+// return_requested: того же клиента, с теми же весом, габаритами и классом
+// обслуживания, но от получателя к отправителю. Исходная посылка переходит
+// в статус returning, а после доставки возвратной — в returned.
+func (s ParcelService) CreateReturn(ctx context.Context, number int) (Parcel, error) {
+	original, err := s.store.Get(ctx, number)
+	if err != nil {
+		return Parcel{}, err
+	}
+	if err := s.transitions.Validate(original.Status, ParcelStatusReturning); err != nil {
+		return Parcel{}, err
+	}
+	if original.SenderAddress == "" {
+		return Parcel{}, fmt.Errorf("%w: parcel %d has no sender address to return to", ErrInvalidAddress, number)
+	}
+
+	ret, err := s.Create(ctx, Parcel{
+		Client:           original.Client,
+		SenderAddress:    original.RecipientAddress,
+		RecipientAddress: original.SenderAddress,
+		TrackCode:        NewTrackCode(),
+		WeightGrams:      original.WeightGrams,
+		LengthMM:         original.LengthMM,
+		WidthMM:          original.WidthMM,
+		HeightMM:         original.HeightMM,
+		ServiceClass:     original.ServiceClass,
+		ReturnOf:         number,
+	})
+	if err != nil {
+		return ret, err
+	}
+	// статус меняется после создания возврата: из двух одновременных
+	// запросов его сменит только один, и лишний возврат удаляется
+	if err := s.SetStatus(ctx, number, ParcelStatusReturning); err != nil {
+		return Parcel{}, errors.Join(err, s.Delete(ctx, ret.Number))
+	}
+	s.logger.InfoContext(ctx, "parcel return created",
+		slog.Int("number", ret.Number), slog.Int("return_of", number))
+
+	return ret, nil
+}
+
+// GetReturn возвращает возвратную посылку для посылки number.
+func (s ParcelService) GetReturn(ctx context.Context, number int) (Parcel, error) {
+	page, err := s.store.ListAll(ctx, Filter{ReturnOf: number}, ListOptions{Limit: 1})
+	if err != nil {
+		return Parcel{}, err
+	}
+	if len(page.Parcels) == 0 {
+		return Parcel{}, fmt.Errorf("%w: parcel %d has no return", ErrParcelNotFound, number)
+	}
+	return page.Parcels[0], nil
+}
+
+// GetOriginal возвращает посылку, которую возвращает посылка number.
+func (s ParcelService) GetOriginal(ctx context.Context, number int) (Parcel, error) {
+	p, err := s.store.Get(ctx, number)
+	if err != nil {
+		return Parcel{}, err
+	}
+	if p.ReturnOf == 0 {
+		return Parcel{}, fmt.Errorf("%w: parcel %d is not a return", ErrParcelNotFound, number)
+	}
+	return s.store.Get(ctx, p.ReturnOf)
+}
+
+// completeReturn переводит исходную посылку доставленного возврата ret
+// в статус returned. Исходную посылку в другом статусе, например
+// переведённую вручную, и уже архивированную он не меняет.
+func (s ParcelService) completeReturn(ctx context.Context, ret Parcel) error {
+	original, err := s.store.Get(ctx, ret.ReturnOf)
+	if errors.Is(err, ErrParcelNotFound) {
+		return nil
+	}
+	if err != nil || original.Status != ParcelStatusReturning {
+		return err
+	}
+	if err := s.SetStatus(ctx, original.Number, ParcelStatusReturned); err != nil {
+		return fmt.Errorf("complete return of parcel %d: %w", original.Number, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkReturns проверяет возврат доставленной посылки и связь
+// исходной и возвратной посылок на хранилище store
+func checkReturns(t *testing.T, store ParcelStorage) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(store)
+	parcel := getTestParcel()
+	parcel.SenderAddress = "г. Москва, ул. Тверская, д. 1"
+	parcel.WeightGrams = 500
+	original, err := service.Create(ctx, parcel)
+	require.NoError(t, err)
+
+	// до доставки возврат не создаётся
+	_, err = service.CreateReturn(ctx, original.Number)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+	require.NoError(t, service.SetStatus(ctx, original.Number, ParcelStatusSent))
+	require.NoError(t, service.SetStatus(ctx, original.Number, ParcelStatusDelivered))
+	require.NoError(t, service.SetStatus(ctx, original.Number, ParcelStatusReturnRequested))
+
+	// add
+	ret, err := service.CreateReturn(ctx, original.Number)
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, original.Number, ret.ReturnOf)
+	require.Equal(t, original.Client, ret.Client)
+	require.Equal(t, original.RecipientAddress, ret.SenderAddress)
+	require.Equal(t, original.SenderAddress, ret.RecipientAddress)
+	require.Equal(t, 500, ret.WeightGrams)
+	require.Equal(t, ParcelStatusRegistered, ret.Status)
+	require.NotEqual(t, original.TrackCode, ret.TrackCode)
+
+	p, err := service.Get(ctx, original.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusReturning, p.Status)
+	// второй возврат той же посылки не создаётся
+	_, err = service.CreateReturn(ctx, original.Number)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+
+	got, err := service.GetReturn(ctx, original.Number)
+	require.NoError(t, err)
+	require.Equal(t, ret.Number, got.Number)
+	got, err = service.GetOriginal(ctx, ret.Number)
+	require.NoError(t, err)
+	require.Equal(t, original.Number, got.Number)
+	_, err = service.GetReturn(ctx, ret.Number)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	_, err = service.GetOriginal(ctx, original.Number)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// доставка возврата завершает возврат исходной посылки
+	require.NoError(t, service.NextStatus(ctx, ret.Number))
+	require.NoError(t, service.NextStatus(ctx, ret.Number))
+	p, err = service.Get(ctx, original.Number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusReturned, p.Status)
+}
+
+// TestReturns проверяет возвраты в SQLite и в памяти
+func TestReturns(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkReturns(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkReturns(t, NewMemoryParcelStore())
+	})
+}
+
+// TestReturnWithoutSender проверяет, что посылку без адреса отправителя
+// вернуть нельзя
+func TestReturnWithoutSender(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(NewMemoryParcelStore())
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	for _, status := range []Status{ParcelStatusSent, ParcelStatusDelivered, ParcelStatusReturnRequested} {
+		require.NoError(t, service.SetStatus(ctx, p.Number, status))
+	}
+
+	// check
+	_, err = service.CreateReturn(ctx, p.Number)
+	require.ErrorIs(t, err, ErrInvalidAddress)
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/Yandex-Practicum/go-db-sql-final/parcelpb"
+)
+
+// defaultShutdownTimeout — сколько по умолчанию ждать запросы и фоновые
+// задачи при остановке, прежде чем прервать их.
+const defaultShutdownTimeout = 30 * time.Second
+
+// defaultReadHeaderTimeout — сколько по умолчанию HTTP API ждёт заголовки
+// запроса: без предела медленный клиент держит соединение сколько угодно.
+const defaultReadHeaderTimeout = 10 * time.Second
+
+// Worker — фоновая задача, работающая до отмены ctx, например
+// ArchiveRunner, RetentionRunner или OutboxRelay.
+type Worker interface {
+	Run(ctx context.Context) error
+}
+
+// Server собирает процесс трекера: HTTP и gRPC API поверх сервиса
+// посылок, фоновые задачи и хранилище, которое закрывается последним.
+type Server struct {
+	service  ParcelService
+	httpAddr string
+	grpcAddr string
+
+	metrics http.Handler
+	health  HealthChecker
+	limiter *RateLimiter
+	auth    *Authenticator
+
+	workers []Worker
+	flush   func(ctx context.Context) error
+	closer  io.Closer
+
+	shutdownTimeout   time.Duration
+	readHeaderTimeout time.Duration
+	logger            *slog.Logger
+}
+
+// NewServer создаёт сервер API на заданных адресах; пустой адрес — не запускать.
+func NewServer(service ParcelService, httpAddr, grpcAddr string) Server {
+	return Server{service: service, httpAddr: httpAddr, grpcAddr: grpcAddr,
+		shutdownTimeout: defaultShutdownTimeout, readHeaderTimeout: defaultReadHeaderTimeout, logger: slog.Default()}
+}
+
+// WithMetrics возвращает копию сервера, отдающую h на /metrics.
+func (s Server) WithMetrics(h http.Handler) Server {
+	s.metrics = h
+	return s
+}
+
+// WithHealth возвращает копию сервера с /healthz и /readyz, см. NewHealthHandler.
+func (s Server) WithHealth(c HealthChecker) Server {
+	s.health = c
+	return s
+}
+
+// WithLimiter возвращает копию сервера, ограничивающую запросы к API;
+// /metrics и проверки здоровья не ограничиваются.
+func (s Server) WithLimiter(l *RateLimiter) Server {
+	s.limiter = l
+	return s
+}
+
+// WithAuth возвращает копию сервера, проверяющую API-ключи запросов к API.
+func (s Server) WithAuth(a *Authenticator) Server {
+	s.auth = a
+	return s
+}
+
+// WithWorker возвращает копию сервера с ещё одной фоновой задачей.
+func (s Server) WithWorker(w Worker) Server {
+	s.workers = append(s.workers[:len(s.workers):len(s.workers)], w)
+	return s
+}
+
+// WithFlush возвращает копию сервера, вызывающую fn после остановки задач,
+// например чтобы опубликовать остаток outbox.
+func (s Server) WithFlush(fn func(ctx context.Context) error) Server {
+	s.flush = fn
+	return s
+}
+
+// WithCloser возвращает копию сервера, закрывающую c в конце остановки.
+func (s Server) WithCloser(c io.Closer) Server {
+	s.closer = c
+	return s
+}
+
+// WithShutdownTimeout возвращает копию сервера с другим сроком остановки.
+func (s Server) WithShutdownTimeout(d time.Duration) Server {
+	s.shutdownTimeout = d
+	return s
+}
+
+// WithReadHeaderTimeout возвращает копию сервера с другим сроком чтения
+// заголовков запроса к HTTP API.
+func (s Server) WithReadHeaderTimeout(d time.Duration) Server {
+	s.readHeaderTimeout = d
+	return s
+}
+
+// WithLogger возвращает копию сервера, пишущую запуск и ход остановки в logger.
+func (s Server) WithLogger(logger *slog.Logger) Server {
+	s.logger = logger
+	return s
+}
+
+// Run запускает API и фоновые задачи и работает, пока не отменён ctx
+// или не упал один из серверов. Затем он останавливает процесс по шагам:
+// перестаёт принимать соединения и ждёт начатые запросы, останавливает
+// фоновые задачи, вызывает flush и закрывает хранилище. На всё отводится
+// shutdownTimeout, после чего оставшиеся запросы обрываются. Остановка
+// по ctx не считается ошибкой.
+func (s Server) Run(ctx context.Context) error {
+	var httpSrv *http.Server
+	var grpcSrv *grpc.Server
+	var listeners []net.Listener
+	listen := func(addr string) (net.Listener, error) {
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, lis)
+		return lis, nil
+	}
+
+	errc := make(chan error, 2)
+	if s.httpAddr != "" {
+		lis, err := listen(s.httpAddr)
+		if err != nil {
+			return err
+		}
+		api := NewHTTPServer(s.service)
+		httpSrv = &http.Server{Handler: s.httpHandler(api), ReadHeaderTimeout: s.readHeaderTimeout}
+		httpSrv.RegisterOnShutdown(api.CloseStreams)
+		go func() {
+			s.logger.InfoContext(ctx, "http api listening", "addr", lis.Addr())
+			if err := httpSrv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+				errc <- fmt.Errorf("http: %w", err)
+			}
+		}()
+	}
+	if s.grpcAddr != "" {
+		lis, err := listen(s.grpcAddr)
+		if err != nil {
+			return err
+		}
+		grpcSrv = s.grpcServer()
+		go func() {
+			s.logger.InfoContext(ctx, "grpc api listening", "addr", lis.Addr())
+			if err := grpcSrv.Serve(lis); err != nil {
+				errc <- fmt.Errorf("grpc: %w", err)
+			}
+		}()
+	}
+
+	// задачи получают свой контекст: их останавливают после серверов,
+	// чтобы начатые запросы успели записать события в outbox
+	workerCtx, stopWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	for _, w := range s.workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			w.Run(workerCtx)
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		s.logger.InfoContext(ctx, "shutdown started", "cause", context.Cause(ctx))
+	case runErr = <-errc:
+		s.logger.ErrorContext(ctx, "server failed, shutting down", "error", runErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownTimeout)
+	defer cancel()
+
+	errs := []error{runErr}
+	if httpSrv != nil {
+		if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown http: %w", err))
+			httpSrv.Close()
+		}
+	}
+	if grpcSrv != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcSrv.Stop()
+			errs = append(errs, fmt.Errorf("shutdown grpc: %w", shutdownCtx.Err()))
+		}
+	}
+	s.logger.InfoContext(ctx, "servers stopped")
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		errs = append(errs, fmt.Errorf("stop workers: %w", shutdownCtx.Err()))
+	}
+
+	if s.flush != nil {
+		if err := s.flush(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("flush: %w", err))
+		}
+	}
+	if s.closer != nil {
+		if err := s.closer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close store: %w", err))
+		}
+	}
+	s.logger.InfoContext(ctx, "shutdown finished")
+
+	return errors.Join(errs...)
+}
+
+// httpHandler собирает REST API с ограничением и проверкой ключей,
+// /metrics, проверки здоровья и документацию API.
+func (s Server) httpHandler(rest *HTTPServer) http.Handler {
+	var api http.Handler = rest
+	if s.auth != nil {
+		api = s.auth.Middleware(api)
+	}
+	// лимит проверяется до ключа, чтобы перебор ключей не нагружал БД
+	if s.limiter != nil {
+		api = s.limiter.Middleware(api)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", api)
+	docs := NewOpenAPIHandler()
+	mux.Handle("GET /openapi.json", docs)
+	mux.Handle("GET /docs", docs)
+	if s.metrics != nil {
+		mux.Handle("GET /metrics", s.metrics)
+	}
+	if s.health != nil {
+		h := NewHealthHandler(s.health)
+		mux.Handle("GET /healthz", h)
+		mux.Handle("GET /readyz", h)
+	}
+	return mux
+}
+
+func (s Server) grpcServer() *grpc.Server {
+	var interceptors []grpc.UnaryServerInterceptor
+	var streams []grpc.StreamServerInterceptor
+	if s.limiter != nil {
+		interceptors = append(interceptors, s.limiter.UnaryInterceptor())
+		streams = append(streams, s.limiter.StreamInterceptor())
+	}
+	if s.auth != nil {
+		interceptors = append(interceptors, s.auth.UnaryInterceptor())
+		streams = append(streams, s.auth.StreamInterceptor())
+	}
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...), grpc.ChainStreamInterceptor(streams...))
+	parcelpb.RegisterParcelServiceServer(srv, NewGRPCServer(s.service))
+	return srv
+}
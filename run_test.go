@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingStorage задерживает Get, пока тест не закроет release
+type blockingStorage struct {
+	ParcelStorage
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s blockingStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return s.ParcelStorage.Get(ctx, number)
+}
+
+// shutdownLog записывает шаги остановки по порядку
+type shutdownLog struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (l *shutdownLog) add(step string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.steps = append(l.steps, step)
+}
+
+func (l *shutdownLog) Run(ctx context.Context) error {
+	<-ctx.Done()
+	l.add("worker")
+	return ctx.Err()
+}
+
+func (l *shutdownLog) Close() error {
+	l.add("close")
+	return nil
+}
+
+// freeAddr возвращает свободный локальный адрес
+func freeAddr(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+// TestServerGracefulShutdown проверяет, что остановка дожидается начатого
+// запроса, а затем по порядку останавливает задачи, вызывает flush и закрывает хранилище
+func TestServerGracefulShutdown(t *testing.T) {
+	// prepare
+	memory := NewMemoryParcelStore()
+	number, err := memory.Add(context.Background(), getTestParcel())
+	require.NoError(t, err)
+	store := blockingStorage{ParcelStorage: memory, started: make(chan struct{}), release: make(chan struct{})}
+
+	log := &shutdownLog{}
+	addr := freeAddr(t)
+	server := NewServer(NewParcelService(store), addr, "").
+		WithWorker(log).
+		WithFlush(func(context.Context) error {
+			log.add("flush")
+			return nil
+		}).
+		WithCloser(log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+
+	// in-flight request
+	url := "http://" + addr + "/parcels/" + strconv.Itoa(number)
+	status := make(chan int, 1)
+	go func() {
+		for {
+			resp, err := http.Get(url)
+			if err != nil {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			resp.Body.Close()
+			status <- resp.StatusCode
+			return
+		}
+	}()
+	select {
+	case <-store.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the store")
+	}
+
+	// shutdown
+	cancel()
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped before the request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(store.release)
+
+	// check
+	require.Equal(t, http.StatusOK, <-status)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	require.Equal(t, []string{"worker", "flush", "close"}, log.steps)
+
+	_, err = http.Get(url)
+	require.Error(t, err)
+}
+
+// TestServerListenError проверяет, что занятый адрес возвращается ошибкой
+func TestServerListenError(t *testing.T) {
+	// prepare
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	// check
+	err = NewServer(NewParcelService(NewMemoryParcelStore()), "", lis.Addr().String()).Run(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleMacros — сокращения расписаний, как в crontab.
+var scheduleMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// Schedule — расписание в формате crontab из пяти полей: минута, час,
+// день месяца, месяц и день недели (0 или 7 — воскресенье). Поле
+// допускает *, числа, диапазоны a-b, списки через запятую и шаг /n,
+// например "*/15 2-4 * * 1-5". Если заданы и день месяца, и день
+// недели, подходит любой из них, как в cron.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny и dowAny отмечают поля дня, заданные как *
+	domAny, dowAny bool
+}
+
+// scheduleField — допустимые значения поля расписания.
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = [5]scheduleField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseSchedule разбирает расписание expr в формате crontab
+// или сокращение вроде @daily.
+func ParseSchedule(expr string) (Schedule, error) {
+	if macro, ok := scheduleMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(scheduleFields) {
+		return Schedule{}, fmt.Errorf("schedule %q: want 5 fields, got %d", expr, len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		var err error
+		if bits[i], err = parseScheduleField(part, scheduleFields[i]); err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", expr, err)
+		}
+	}
+	// воскресенье можно записать и как 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return Schedule{minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domAny: parts[2] == "*", dowAny: parts[4] == "*"}, nil
+}
+
+// parseScheduleField возвращает битовую маску значений поля f.
+func parseScheduleField(s string, f scheduleField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("%s: invalid value %q", f.name, loStr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("%s: invalid value %q", f.name, hiStr)
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%s: %q is out of range %d-%d", f.name, item, f.min, f.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next возвращает ближайшую после t минуту расписания в часовом поясе t
+// или нулевое время, если такой нет в ближайшие пять лет, как у 30 февраля.
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
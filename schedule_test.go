@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchedule проверяет разбор расписаний crontab и поиск следующего запуска
+func TestSchedule(t *testing.T) {
+	// 3 июня 2024 года — понедельник
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", at(3, 10, 0).Add(30 * time.Second), at(3, 10, 1)},
+		{"*/15 2-4 * * 1-5", at(3, 4, 50), at(4, 2, 0)},
+		{"*/15 2-4 * * 1-5", at(7, 4, 45), at(10, 2, 0)},
+		{"30 3 * * *", at(3, 3, 30), at(4, 3, 30)},
+		{"@daily", at(30, 12, 0), time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", at(3, 0, 0), at(9, 0, 0)},
+		// заданы и день месяца, и день недели: подходит любой
+		{"0 0 13 * 5", at(3, 0, 0), at(7, 0, 0)},
+		{"0 0 13 * 5", at(12, 0, 0), at(13, 0, 0)},
+		{"5,10-12/2 1 1 1 *", at(3, 0, 0), time.Date(2025, time.January, 1, 1, 5, 0, 0, time.UTC)},
+		{"0 0 30 2 *", at(3, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		// check
+		s, err := ParseSchedule(tt.expr)
+		require.NoError(t, err, tt.expr)
+		require.Equal(t, tt.want, s.Next(tt.from), tt.expr)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@never"} {
+		_, err := ParseSchedule(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidSearchQuery возвращается для поискового запроса без букв и цифр.
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// ErrEncryptedSearch возвращает SearchByAddress при включённом шифровании
+// адресов: полнотекстовый индекс строится по шифротексту.
+var ErrEncryptedSearch = errors.New("address search is unavailable with encryption enabled")
+
+// AddressSearcher ищет посылки по части адреса отправителя или получателя.
+type AddressSearcher interface {
+	SearchByAddress(ctx context.Context, query string, opts ListOptions) (ParcelPage, error)
+}
+
+var (
+	_ AddressSearcher = ParcelStore{}
+	_ AddressSearcher = (*MemoryParcelStore)(nil)
+)
+
+// ErrNoAddressSearcher возвращает SearchByAddress сервиса без поиска по адресу.
+var ErrNoAddressSearcher = errors.New("parcel service has no address searcher")
+
+// WithSearcher возвращает копию сервиса, ищущую посылки по адресу в searcher.
+func (s ParcelService) WithSearcher(searcher AddressSearcher) ParcelService {
+	s.searcher = searcher
+	return s
+}
+
+// SearchByAddress ищет посылки по части адреса отправителя или получателя.
+func (s ParcelService) SearchByAddress(ctx context.Context, query string, opts ListOptions) (ParcelPage, error) {
+	if s.searcher == nil {
+		return ParcelPage{}, ErrNoAddressSearcher
+	}
+	return s.searcher.SearchByAddress(ctx, query, opts)
+}
+
+// searchTerms делит запрос на слова без учёта пунктуации:
+// «ул. Тверская, 1» ищется как «ул», «Тверская» и «1».
+func searchTerms(query string) ([]string, error) {
+	terms := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+	return terms, nil
+}
+
+// addressMatch возвращает условие поиска слов terms в адресах и его
+// аргументы. SQLite ищет по индексу FTS5 parcel_address_fts, MySQL — по
+// FULLTEXT-индексу, и оба находят слова адреса, начинающиеся с terms;
+// PostgreSQL ищет terms в любом месте адреса через ILIKE и триграммные индексы.
+func (d dialect) addressMatch(terms []string) (string, []any) {
+	switch d.name {
+	case "postgres":
+		conds := make([]string, 0, len(terms))
+		args := make([]any, 0, 2*len(terms))
+		for _, t := range terms {
+			conds = append(conds, "(sender_address ILIKE ? OR recipient_address ILIKE ?)")
+			pattern := "%" + t + "%"
+			args = append(args, pattern, pattern)
+		}
+		return strings.Join(conds, " AND "), args
+	case "mysql":
+		expr := make([]string, 0, len(terms))
+		for _, t := range terms {
+			expr = append(expr, "+"+t+"*")
+		}
+		return "MATCH (sender_address, recipient_address) AGAINST (? IN BOOLEAN MODE)", []any{strings.Join(expr, " ")}
+	}
+
+	// слова без пунктуации не нуждаются в экранировании, кавычки
+	// не дают FTS5 принять их за операторы вроде NOT
+	expr := make([]string, 0, len(terms))
+	for _, t := range terms {
+		expr = append(expr, `"`+t+`"*`)
+	}
+	return "number IN (SELECT rowid FROM parcel_address_fts WHERE parcel_address_fts MATCH ?)", []any{strings.Join(expr, " ")}
+}
+
+// SearchByAddress возвращает страницу посылок, в адресе отправителя или
+// получателя которых встречаются все слова query, без учёта регистра.
+// С шифрованием адресов, см. WithCipher, поиск недоступен.
+func (s ParcelStore) SearchByAddress(ctx context.Context, query string, opts ListOptions) (ParcelPage, error) {
+	if s.cipher != nil {
+		return ParcelPage{}, ErrEncryptedSearch
+	}
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+	terms, err := searchTerms(query)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	cond, args := s.dialect.addressMatch(terms)
+	tenant, tenantArgs := tenantCond(ctx)
+	where := " WHERE " + cond + " AND deleted_at IS NULL" + tenant
+	args = append(args, tenantArgs...)
+
+	var page ParcelPage
+	err = s.reader(0).QueryRowContext(ctx, s.dialect.rebind("SELECT COUNT(*) FROM parcel"+where), args...).Scan(&page.Total)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	page.Parcels, err = s.query(ctx,
+		"SELECT "+parcelColumns+" FROM parcel"+where+opts.orderBy()+" LIMIT ? OFFSET ?",
+		append(args, opts.limit(), opts.Offset)...)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	return page, nil
+}
+
+// SearchByAddress возвращает страницу посылок, в адресах которых
+// встречаются все слова query, без учёта регистра. Как и ILIKE
+// в PostgreSQL, слово ищется в любом месте адреса.
+func (s *MemoryParcelStore) SearchByAddress(ctx context.Context, query string, opts ListOptions) (ParcelPage, error) {
+	if err := ctx.Err(); err != nil {
+		return ParcelPage{}, err
+	}
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+	terms, err := searchTerms(query)
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	s.mu.Lock()
+	var parcels []Parcel
+	for _, p := range s.parcels {
+		if matchAddress(p, terms) && s.visible(ctx, p.Number) {
+			parcels = append(parcels, p)
+		}
+	}
+	s.mu.Unlock()
+	opts.sortParcels(parcels)
+
+	return paginate(parcels, opts), nil
+}
+
+func matchAddress(p Parcel, terms []string) bool {
+	address := strings.ToLower(p.SenderAddress + "\n" + p.RecipientAddress)
+	for _, t := range terms {
+		if !strings.Contains(address, strings.ToLower(t)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkSearchByAddress проверяет поиск посылок по адресу в хранилище store
+func checkSearchByAddress(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	searcher := store.(AddressSearcher)
+
+	var numbers []int
+	for _, address := range []string{
+		"Москва, ул. Тверская, д. 1",
+		"Москва, Тверской бульвар, д. 5",
+		"Санкт-Петербург, Невский пр., д. 10",
+	} {
+		parcel := getTestParcel()
+		parcel.RecipientAddress = address
+		parcel.SenderAddress = "Псков, ул. Ленина, д. 3"
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+
+	// check
+	page, err := searcher.SearchByAddress(ctx, "тверс", ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, numbers[0], page.Parcels[0].Number)
+	require.Equal(t, numbers[1], page.Parcels[1].Number)
+
+	// все слова запроса обязательны, пунктуация не мешает
+	page, err = searcher.SearchByAddress(ctx, "москва, тверская", ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.Equal(t, numbers[0], page.Parcels[0].Number)
+
+	// ищется и адрес отправителя
+	page, err = searcher.SearchByAddress(ctx, "Ленин", ListOptions{Limit: 2, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total)
+	require.Len(t, page.Parcels, 2)
+	require.Equal(t, numbers[2], page.Parcels[0].Number)
+
+	// новый адрес находится сразу, удалённая посылка — нет
+	require.NoError(t, store.SetRecipientAddress(ctx, numbers[2], "Казань, ул. Баумана, д. 7"))
+	page, err = searcher.SearchByAddress(ctx, "Невский", ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+	page, err = searcher.SearchByAddress(ctx, "баумана", ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+
+	require.NoError(t, store.Delete(ctx, numbers[2]))
+	page, err = searcher.SearchByAddress(ctx, "баумана", ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+
+	_, err = searcher.SearchByAddress(ctx, " , ", ListOptions{})
+	require.ErrorIs(t, err, ErrInvalidSearchQuery)
+	_, err = searcher.SearchByAddress(ctx, "Москва", ListOptions{SortBy: "address"})
+	require.ErrorIs(t, err, ErrInvalidSort)
+}
+
+// TestSearchByAddress проверяет поиск по адресу в SQLite
+func TestSearchByAddress(t *testing.T) {
+	checkSearchByAddress(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemorySearchByAddress проверяет поиск по адресу в памяти
+func TestMemorySearchByAddress(t *testing.T) {
+	checkSearchByAddress(t, NewMemoryParcelStore())
+}
+
+// TestSearchAfterHardDelete проверяет, что индекс FTS5 не отдаёт удалённые строки
+func TestSearchAfterHardDelete(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	parcel := getTestParcel()
+	parcel.RecipientAddress = "Самара, ул. Куйбышева"
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	require.NoError(t, store.HardDelete(ctx, id))
+	page, err := store.SearchByAddress(ctx, "куйбышева", ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+}
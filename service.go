@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ParcelService — прикладной слой над хранилищем посылок. Он проверяет
+// правила жизненного цикла посылки до записи, публикует события и пишет
+// журнал; HTTP, gRPC и CLI работают с посылками только через него.
+// Хранилище проверяет те же правила ещё раз внутри транзакции, поэтому
+// гонка двух запросов не обходит их.
+type ParcelService struct {
+	store       ParcelStorage
+	transitions StatusTransitions
+	publisher   Publisher
+	estimator   DeliveryEstimator
+	// routes — маршруты посылок для WatchRoute, nil у хранилищ без маршрутов
+	routes RouteStore
+	// attachments — подтверждения доставки, nil — вложения выключены
+	attachments *Attachments
+	// attempts — журнал попыток доставки, nil — попытки не записываются
+	attempts    AttemptStore
+	maxAttempts int
+	// clients — справочник клиентов, nil у хранилищ без клиентов
+	clients ClientStore
+	// searcher ищет посылки по адресу, nil у хранилищ без поиска
+	searcher AddressSearcher
+	// watchInterval — период опроса посылки в Watch
+	watchInterval time.Duration
+	logger        *slog.Logger
+}
+
+func NewParcelService(store ParcelStorage) ParcelService {
+	return ParcelService{store: store, transitions: DefaultStatusTransitions(), logger: slog.Default()}
+}
+
+// WithLogger возвращает копию сервиса, пишущую события в logger.
+func (s ParcelService) WithLogger(logger *slog.Logger) ParcelService {
+	s.logger = logger
+	return s
+}
+
+// WithTransitions возвращает копию сервиса с другими правилами смены статуса.
+// Они должны совпадать с правилами хранилища, см. ParcelStore.WithTransitions.
+func (s ParcelService) WithTransitions(t StatusTransitions) ParcelService {
+	s.transitions = t
+	return s
+}
+
+// WithPublisher возвращает копию сервиса, отправляющую события в publisher.
+// Ошибка публикации логируется и не отменяет уже сохранённое изменение.
+func (s ParcelService) WithPublisher(publisher Publisher) ParcelService {
+	s.publisher = publisher
+	return s
+}
+
+// WithEstimator возвращает копию сервиса с тарифом estimator: по его зоне
+// транспортная этикетка показывает класс доставки.
+func (s ParcelService) WithEstimator(estimator DeliveryEstimator) ParcelService {
+	s.estimator = estimator
+	return s
+}
+
+// WithRoutes возвращает копию сервиса, отслеживающую маршруты посылок
+// в routes, см. WatchRoute.
+func (s ParcelService) WithRoutes(routes RouteStore) ParcelService {
+	s.routes = routes
+	return s
+}
+
+// Create регистрирует посылку p в статусе registered и возвращает её
+// такой, какой её сохранило хранилище: с номером, ключами и временем создания.
+// Повтор с тем же ключом идемпотентности возвращает уже созданную посылку
+// без нового события, см. WithIdempotencyKey.
+func (s ParcelService) Create(ctx context.Context, p Parcel) (Parcel, error) {
+	p.Status = ParcelStatusRegistered
+	if err := validateDimensions(p); err != nil {
+		return p, err
+	}
+
+	number, err := s.store.Add(ctx, p)
+	if err != nil {
+		return p, err
+	}
+	p, err = s.store.Get(ctx, number)
+	if err != nil {
+		return p, err
+	}
+	if AddReplayed(ctx) {
+		s.logger.InfoContext(ctx, "parcel add replayed",
+			slog.Int("number", p.Number), slog.String("idempotency_key", IdempotencyKeyFromContext(ctx)))
+		return p, nil
+	}
+
+	s.logger.InfoContext(ctx, "parcel registered",
+		slog.Int("number", p.Number), slog.Int("client", p.Client))
+	s.publish(ctx, newParcelEvent(EventParcelCreated, p))
+
+	return p, nil
+}
+
+// Register регистрирует посылку клиента и печатает её данные.
+func (s ParcelService) Register(ctx context.Context, client int, sender, recipient string) (Parcel, error) {
+	parcel, err := s.Create(ctx, Parcel{
+		Client:           client,
+		SenderAddress:    sender,
+		RecipientAddress: recipient,
+		TrackCode:        NewTrackCode(),
+	})
+	if err != nil {
+		return parcel, err
+	}
+
+	fmt.Printf("Новая посылка № %d на адрес %s от клиента с идентификатором %d зарегистрирована %s\n",
+		parcel.Number, parcel.RecipientAddress, parcel.Client, formatTime(parcel.CreatedAt))
+
+	return parcel, nil
+}
+
+func (s ParcelService) Get(ctx context.Context, number int) (Parcel, error) {
+	return s.store.Get(ctx, number)
+}
+
+func (s ParcelService) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	return s.store.GetByTrackCode(ctx, code)
+}
+
+// GetHistory возвращает смены статуса посылки в хронологическом порядке.
+func (s ParcelService) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	return s.store.GetHistory(ctx, number)
+}
+
+// GetEvents возвращает события отслеживания посылки в порядке их наступления.
+func (s ParcelService) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	return s.store.GetEvents(ctx, number)
+}
+
+func (s ParcelService) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	return s.store.ListByClient(ctx, client, opts)
+}
+
+func (s ParcelService) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	return s.store.ListAll(ctx, filter, opts)
+}
+
+// ListBreached возвращает посылки с нарушенным сроком SLA, см. SLAChecker.
+func (s ParcelService) ListBreached(ctx context.Context, opts ListOptions) (ParcelPage, error) {
+	return s.store.ListAll(ctx, Filter{SLABreached: true}, opts)
+}
+
+func (s ParcelService) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	return s.store.List(ctx, filter, afterNumber, limit)
+}
+
+func (s ParcelService) PrintClientParcels(ctx context.Context, client int) error {
+	parcels, err := s.store.GetByClient(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Посылки клиента %d:\n", client)
+	for _, parcel := range parcels {
+		fmt.Printf("Посылка № %d на адрес %s от клиента с идентификатором %d зарегистрирована %s, статус %s\n",
+			parcel.Number, parcel.RecipientAddress, parcel.Client, formatTime(parcel.CreatedAt), parcel.Status)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// NextStatuses возвращает статусы, в которые правила сервиса разрешают
+// перевести посылку из status.
+func (s ParcelService) NextStatuses(status Status) []Status {
+	return append([]Status(nil), s.transitions[status]...)
+}
+
+// SetStatus переводит посылку в статус status, если переход разрешён
+// правилами сервиса, иначе возвращает ErrInvalidTransition.
+func (s ParcelService) SetStatus(ctx context.Context, number int, status Status) error {
+	old, err := s.store.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err := s.transitions.Validate(old.Status, status); err != nil {
+		return err
+	}
+
+	if err := s.store.SetStatus(ctx, number, status); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "parcel status changed",
+		slog.Int("number", number), slog.Int("client", old.Client),
+		slog.String("from", string(old.Status)), slog.String("to", string(status)))
+	s.changed(ctx, old)
+
+	if status == ParcelStatusDelivered && old.ReturnOf != 0 {
+		return s.completeReturn(ctx, old)
+	}
+	return nil
+}
+
+// NextStatus переводит посылку в следующий статус: registered → sent → delivered.
+// Доставленная и возвращаемая посылки не меняются: возврат начинается
+// запросом и продолжается CreateReturn.
+func (s ParcelService) NextStatus(ctx context.Context, number int) error {
+	parcel, err := s.store.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	var nextStatus Status
+	switch parcel.Status {
+	case ParcelStatusRegistered:
+		nextStatus = ParcelStatusSent
+	case ParcelStatusSent:
+		nextStatus = ParcelStatusDelivered
+	default:
+		return nil
+	}
+
+	fmt.Printf("У посылки № %d новый статус: %s\n", number, nextStatus.DisplayName(LangRU))
+
+	return s.SetStatus(ctx, number, nextStatus)
+}
+
+// ChangeAddress меняет адрес получателя зарегистрированной посылки.
+func (s ParcelService) ChangeAddress(ctx context.Context, number int, address string) error {
+	return s.changeAddress(ctx, number, address, false)
+}
+
+// ChangeSenderAddress меняет адрес отправителя зарегистрированной посылки.
+func (s ParcelService) ChangeSenderAddress(ctx context.Context, number int, address string) error {
+	return s.changeAddress(ctx, number, address, true)
+}
+
+// changeAddress меняет адрес отправителя или получателя. После отправки
+// посылку уже везут по старому адресу, поэтому менять его поздно.
+func (s ParcelService) changeAddress(ctx context.Context, number int, address string, sender bool) error {
+	old, err := s.store.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if old.Status != ParcelStatusRegistered {
+		return ErrAddressChangeNotAllowed
+	}
+
+	set := s.store.SetRecipientAddress
+	if sender {
+		set = s.store.SetSenderAddress
+	}
+	if err := set(ctx, number, address); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "parcel address changed", slog.Int("number", number), slog.Bool("sender", sender))
+	s.changed(ctx, old)
+
+	return nil
+}
+
+// MarkPaid отмечает посылку оплаченной транзакцией txRef.
+func (s ParcelService) MarkPaid(ctx context.Context, number int, txRef string) error {
+	if err := s.store.MarkPaid(ctx, number, txRef); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "parcel paid", slog.Int("number", number))
+
+	return nil
+}
+
+// AddEvent добавляет посылке событие трекинга с кодом code.
+func (s ParcelService) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	id, err := s.store.AddEvent(ctx, number, code, description, occurredAt)
+	if err != nil {
+		return 0, err
+	}
+	s.logger.InfoContext(ctx, "parcel event added", slog.Int("number", number), slog.String("code", code))
+
+	return id, nil
+}
+
+// Delete удаляет посылку, пока она не отправлена, иначе возвращает ErrDeleteNotAllowed.
+func (s ParcelService) Delete(ctx context.Context, number int) error {
+	old, err := s.store.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if old.Status != ParcelStatusRegistered {
+		return ErrDeleteNotAllowed
+	}
+
+	if err := s.store.Delete(ctx, number); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "parcel deleted", slog.Int("number", number))
+	s.publish(ctx, newParcelEvent(EventParcelDeleted, old))
+
+	return nil
+}
+
+// changed публикует смену статуса и адреса посылки по сравнению с old.
+func (s ParcelService) changed(ctx context.Context, old Parcel) {
+	if s.publisher == nil {
+		return
+	}
+
+	p, err := s.store.Get(ctx, old.Number)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "publish event", "number", old.Number, "error", err)
+		return
+	}
+	for _, e := range changeEvents(old, p) {
+		s.publish(ctx, e)
+	}
+}
+
+func (s ParcelService) publish(ctx context.Context, e ParcelEvent) {
+	if s.publisher == nil {
+		return
+	}
+	if err := s.publisher.Publish(ctx, e); err != nil {
+		s.logger.ErrorContext(ctx, "publish event", "type", e.Type, "number", e.Number, "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeCountingStore считает записи, дошедшие до хранилища.
+type writeCountingStore struct {
+	ParcelStorage
+	writes int
+}
+
+func (s *writeCountingStore) SetStatus(ctx context.Context, number int, status Status) error {
+	s.writes++
+	return s.ParcelStorage.SetStatus(ctx, number, status)
+}
+
+func (s *writeCountingStore) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	s.writes++
+	return s.ParcelStorage.SetRecipientAddress(ctx, number, address)
+}
+
+func (s *writeCountingStore) Delete(ctx context.Context, number int) error {
+	s.writes++
+	return s.ParcelStorage.Delete(ctx, number)
+}
+
+// TestParcelService проверяет правила жизненного цикла и события сервиса посылок
+func TestParcelService(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := &writeCountingStore{ParcelStorage: NewMemoryParcelStore()}
+	rec := &recordPublisher{}
+	service := NewParcelService(store).WithPublisher(rec)
+
+	// add
+	parcel := getTestParcel()
+	parcel.Status = ParcelStatusDelivered
+	p, err := service.Create(ctx, parcel)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+	require.NotZero(t, p.Number)
+	require.False(t, p.CreatedAt.IsZero())
+
+	// check
+	require.NoError(t, service.ChangeAddress(ctx, p.Number, "new address"))
+	require.ErrorIs(t, service.SetStatus(ctx, p.Number, ParcelStatusDelivered), ErrInvalidTransition)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+	require.Equal(t, 2, store.writes)
+
+	// после отправки сервис отказывает, не обращаясь к хранилищу
+	require.ErrorIs(t, service.ChangeAddress(ctx, p.Number, "other"), ErrAddressChangeNotAllowed)
+	require.ErrorIs(t, service.Delete(ctx, p.Number), ErrDeleteNotAllowed)
+	require.Equal(t, 2, store.writes)
+	require.Equal(t, []string{EventParcelCreated, EventAddressChanged, EventStatusChanged}, rec.types())
+
+	p, err = service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, service.Delete(ctx, p.Number))
+	require.Equal(t, EventParcelDeleted, rec.events[len(rec.events)-1].Type)
+
+	parcel = getTestParcel()
+	parcel.WeightGrams = -1
+	_, err = service.Create(ctx, parcel)
+	require.ErrorIs(t, err, ErrInvalidDimensions)
+}
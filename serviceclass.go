@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// Классы обслуживания посылок. Пустой класс считается standard.
+const (
+	ServiceEconomy  = "economy"
+	ServiceStandard = "standard"
+	ServiceExpress  = "express"
+)
+
+// serviceClasses — известные классы обслуживания по убыванию приоритета.
+var serviceClasses = []string{ServiceExpress, ServiceStandard, ServiceEconomy}
+
+// validateServiceClass проверяет, что class — известный класс обслуживания
+// или пустой.
+func validateServiceClass(class string) error {
+	if class == "" || serviceClassRank(class) >= 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %q, must be economy, standard or express", ErrUnknownServiceClass, class)
+}
+
+// serviceClassRank возвращает приоритет класса: 0 у express, чем больше,
+// тем позже посылка в очереди курьера. Для неизвестного класса — -1.
+func serviceClassRank(class string) int {
+	if class == "" {
+		class = ServiceStandard
+	}
+	for i, known := range serviceClasses {
+		if class == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// serviceClassOrder — выражение ORDER BY с тем же порядком, что serviceClassRank.
+const serviceClassOrder = "CASE service_class WHEN '" + ServiceExpress + "' THEN 0 WHEN '" + ServiceEconomy + "' THEN 2 ELSE 1 END"
+
+// ListByServiceClass возвращает посылки класса обслуживания class.
+// Класс standard не включает посылки без класса.
+func (s ParcelService) ListByServiceClass(ctx context.Context, class string, opts ListOptions) (ParcelPage, error) {
+	if class == "" {
+		return ParcelPage{}, fmt.Errorf("%w: class is required", ErrUnknownServiceClass)
+	}
+	if err := validateServiceClass(class); err != nil {
+		return ParcelPage{}, err
+	}
+	return s.store.ListAll(ctx, Filter{ServiceClass: class}, opts)
+}
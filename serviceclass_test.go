@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceClassValidate проверяет, что посылка с неизвестным классом
+// обслуживания не добавляется
+func TestServiceClassValidate(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	p := getTestParcel()
+	p.ServiceClass = "overnight"
+
+	// check
+	_, err := store.Add(ctx, p)
+	require.ErrorIs(t, err, ErrUnknownServiceClass)
+	for _, class := range []string{"", ServiceEconomy, ServiceStandard, ServiceExpress} {
+		p.ServiceClass = class
+		p.TrackCode = NewTrackCode()
+		_, err = store.Add(ctx, p)
+		require.NoError(t, err, class)
+	}
+	require.Error(t, SLAConfig{Classes: map[string]time.Duration{"overnight": time.Hour}}.validate())
+	require.Error(t, Tariff{ServiceDays: map[string]int{"overnight": 1}}.validate())
+}
+
+// TestServiceClassETA проверяет сдвиг срока доставки по классу обслуживания
+func TestServiceClassETA(t *testing.T) {
+	// prepare
+	tariff := testTariff
+	tariff.ServiceDays = map[string]int{ServiceEconomy: 3, ServiceExpress: -5}
+	require.NoError(t, tariff.validate())
+	e := NewDeliveryEstimator(tariff)
+	p := Parcel{Status: ParcelStatusSent, RecipientAddress: "Псков", UpdatedAt: testCreatedAt}
+
+	// check
+	require.Equal(t, testCreatedAt.AddDate(0, 0, 7), e.EstimateDelivery(p))
+	p.ServiceClass = ServiceEconomy
+	require.Equal(t, testCreatedAt.AddDate(0, 0, 10), e.EstimateDelivery(p))
+	p.ServiceClass = ServiceExpress
+	require.Equal(t, testCreatedAt.AddDate(0, 0, 2), e.EstimateDelivery(p))
+	// срок в пути не бывает отрицательным
+	p.RecipientAddress = "Москва"
+	require.Equal(t, testCreatedAt, e.EstimateDelivery(p))
+}
+
+// TestCourierQueueOrder проверяет, что очередь курьера начинается
+// с посылок express и заканчивается economy
+func TestCourierQueueOrder(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	courier, err := store.AddCourier(ctx, Courier{Name: "Алексей"})
+	require.NoError(t, err)
+
+	// add
+	var numbers []int
+	for _, class := range []string{ServiceEconomy, "", ServiceExpress, ServiceStandard} {
+		p := getTestParcel()
+		p.ServiceClass = class
+		number, err := store.Add(ctx, p)
+		require.NoError(t, err)
+		require.NoError(t, store.AssignCourier(ctx, number, courier))
+		numbers = append(numbers, number)
+	}
+
+	// check
+	parcels, err := store.GetByCourier(ctx, courier)
+	require.NoError(t, err)
+	var got []int
+	for _, p := range parcels {
+		got = append(got, p.Number)
+	}
+	require.Equal(t, []int{numbers[2], numbers[1], numbers[3], numbers[0]}, got)
+}
+
+// TestListByServiceClass проверяет выборку посылок по классу обслуживания
+func TestListByServiceClass(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(NewParcelStore(openTempDB(t)))
+	for _, class := range []string{ServiceExpress, ServiceEconomy, ServiceExpress} {
+		p := getTestParcel()
+		p.ServiceClass = class
+		_, err := service.Create(ctx, p)
+		require.NoError(t, err)
+	}
+
+	// check
+	page, err := service.ListByServiceClass(ctx, ServiceExpress, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	for _, p := range page.Parcels {
+		require.Equal(t, ServiceExpress, p.ServiceClass)
+	}
+	_, err = service.ListByServiceClass(ctx, "overnight", ListOptions{})
+	require.ErrorIs(t, err, ErrUnknownServiceClass)
+	_, err = service.ListByServiceClass(ctx, "", ListOptions{})
+	require.ErrorIs(t, err, ErrUnknownServiceClass)
+}
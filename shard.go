@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCrossShardUpdate возвращает ShardedStorage.Update при смене клиента
+// на клиента с другого сегмента: посылка осталась бы на старом сегменте
+// и пропала бы из списков нового клиента.
+var ErrCrossShardUpdate = errors.New("parcel cannot move to a client on another shard")
+
+// ShardedStorage распределяет посылки по сегментам shards по хешу номера
+// клиента: посылки одного клиента лежат на одном сегменте, поэтому запросы
+// по клиенту идут в один сегмент, а запросы по статусу, трек-коду и списки
+// всех посылок опрашивают все сегменты параллельно и сливают результат.
+//
+// Сегмент зашит в номер посылки: номер на сегменте i из n становится
+// глобальным номером local*n + i. Поэтому состав и порядок сегментов
+// после первой записи менять нельзя.
+type ShardedStorage struct {
+	shards []ParcelStorage
+}
+
+var _ ParcelStorage = ShardedStorage{}
+
+// NewShardedStorage объединяет хранилища shards в одно.
+func NewShardedStorage(shards ...ParcelStorage) (ShardedStorage, error) {
+	if len(shards) == 0 {
+		return ShardedStorage{}, errors.New("sharded storage needs at least one shard")
+	}
+	return ShardedStorage{shards: shards}, nil
+}
+
+// openShards открывает сегменты cfg.Shards драйвера cfg.Driver с теми же
+// настройками, что и одиночное хранилище.
+func openShards(cfg Config) (ShardedStorage, error) {
+	var shards []ParcelStorage
+	closeAll := func() {
+		for _, s := range shards {
+			if c, ok := s.(io.Closer); ok {
+				c.Close()
+			}
+		}
+	}
+
+	for _, dsn := range cfg.Shards {
+		db, err := openDB(cfg, dsn)
+		if err != nil {
+			closeAll()
+			return ShardedStorage{}, err
+		}
+		store, err := OpenStorage(cfg.Driver, db)
+		if err == nil {
+			store, err = withEncryption(store, cfg.Encryption)
+		}
+		if err != nil {
+			db.Close()
+			closeAll()
+			return ShardedStorage{}, err
+		}
+		shards = append(shards, withPaymentRequired(withKeyMode(store, cfg.KeyMode), cfg.Payment.RequiredForSending))
+	}
+
+	return NewShardedStorage(shards...)
+}
+
+// shardOf возвращает сегмент клиента.
+func (s ShardedStorage) shardOf(client int) int {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.Itoa(client)))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// global переводит номер посылки на сегменте shard в глобальный.
+func (s ShardedStorage) global(shard, local int) int {
+	return local*len(s.shards) + shard
+}
+
+// locate возвращает сегмент посылки с глобальным номером number и её номер на нём.
+func (s ShardedStorage) locate(number int) (ParcelStorage, int) {
+	n := len(s.shards)
+	if number < 0 {
+		// отрицательных номеров нет ни на одном сегменте
+		return s.shards[0], number
+	}
+	return s.shards[number%n], number / n
+}
+
+// globalParcels переводит номера посылок сегмента shard в глобальные.
+func (s ShardedStorage) globalParcels(shard int, parcels []Parcel) []Parcel {
+	for i := range parcels {
+		parcels[i].Number = s.global(shard, parcels[i].Number)
+	}
+	return parcels
+}
+
+// fanOut параллельно вызывает fn на каждом из items, например сегментов,
+// и возвращает результаты по порядку items или первую по порядку ошибку.
+func fanOut[E, T any](items []E, fn func(i int, item E) (T, error)) ([]T, error) {
+	res := make([]T, len(items))
+	errs := make([]error, len(items))
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i], errs[i] = fn(i, item)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Add записывает посылку на сегмент её клиента и возвращает глобальный номер.
+func (s ShardedStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	shard := s.shardOf(p.Client)
+	number, err := s.shards[shard].Add(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	return s.global(shard, number), nil
+}
+
+func (s ShardedStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	store, local := s.locate(number)
+	p, err := store.Get(ctx, local)
+	if err != nil {
+		return p, err
+	}
+	p.Number = number
+	return p, nil
+}
+
+func (s ShardedStorage) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	return s.findOne(ctx, func(store ParcelStorage) (Parcel, error) {
+		return store.GetByTrackCode(ctx, code)
+	})
+}
+
+func (s ShardedStorage) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	return s.findOne(ctx, func(store ParcelStorage) (Parcel, error) {
+		return store.GetByUUID(ctx, id)
+	})
+}
+
+// findOne ищет посылку на всех сегментах, например по трек-коду, который
+// не указывает на сегмент.
+func (s ShardedStorage) findOne(ctx context.Context, get func(store ParcelStorage) (Parcel, error)) (Parcel, error) {
+	if err := ctx.Err(); err != nil {
+		return Parcel{}, err
+	}
+	found, err := fanOut(s.shards, func(shard int, store ParcelStorage) ([]Parcel, error) {
+		p, err := get(store)
+		if errors.Is(err, ErrParcelNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.globalParcels(shard, []Parcel{p}), nil
+	})
+	if err != nil {
+		return Parcel{}, err
+	}
+	for _, parcels := range found {
+		if len(parcels) > 0 {
+			return parcels[0], nil
+		}
+	}
+	return Parcel{}, ErrParcelNotFound
+}
+
+func (s ShardedStorage) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	shard := s.shardOf(client)
+	parcels, err := s.shards[shard].GetByClient(ctx, client)
+	return s.globalParcels(shard, parcels), err
+}
+
+func (s ShardedStorage) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	shard := s.shardOf(client)
+	page, err := s.shards[shard].ListByClient(ctx, client, opts)
+	page.Parcels = s.globalParcels(shard, page.Parcels)
+	return page, err
+}
+
+// List опрашивает сегменты с места курсора и сливает их страницы
+// по возрастанию глобального номера.
+func (s ShardedStorage) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	limit, err := cursorLimit(limit)
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	n := len(s.shards)
+	pages, err := fanOut(s.shards, func(shard int, store ParcelStorage) ([]Parcel, error) {
+		if filter.Client != 0 && shard != s.shardOf(filter.Client) {
+			return nil, nil
+		}
+		// глобальный номер local*n + shard больше afterNumber,
+		// когда local больше (afterNumber - shard) / n
+		after := max(afterNumber-shard, 0) / n
+		page, err := store.List(ctx, filter, after, limit+1)
+		return s.globalParcels(shard, page.Parcels), err
+	})
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	return mergeCursorPages(pages, limit), nil
+}
+
+// mergeCursorPages сливает посылки, прочитанные List с limit+1,
+// в страницу до limit посылок по возрастанию номера.
+func mergeCursorPages(pages [][]Parcel, limit int) CursorPage {
+	var parcels []Parcel
+	for _, p := range pages {
+		parcels = append(parcels, p...)
+	}
+	ListOptions{}.sortParcels(parcels)
+	if len(parcels) > limit+1 {
+		parcels = parcels[:limit+1]
+	}
+	return cursorPage(parcels, limit)
+}
+
+// ListAll читает с каждого сегмента начало списка, сливает его в порядке
+// opts и вырезает страницу; Total — сумма по сегментам.
+func (s ShardedStorage) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	if err := opts.validate(); err != nil {
+		return ParcelPage{}, err
+	}
+	if filter.Client != 0 {
+		shard := s.shardOf(filter.Client)
+		page, err := s.shards[shard].ListAll(ctx, filter, opts)
+		page.Parcels = s.globalParcels(shard, page.Parcels)
+		return page, err
+	}
+
+	shardOpts := opts.head()
+	pages, err := fanOut(s.shards, func(shard int, store ParcelStorage) (ParcelPage, error) {
+		page, err := store.ListAll(ctx, filter, shardOpts)
+		page.Parcels = s.globalParcels(shard, page.Parcels)
+		return page, err
+	})
+	if err != nil {
+		return ParcelPage{}, err
+	}
+
+	return mergePages(pages, opts), nil
+}
+
+// head возвращает опции, которые читают с одного хранилища всё, что может
+// попасть на страницу o после слияния: первые Offset+Limit посылок.
+func (o ListOptions) head() ListOptions {
+	h := o
+	h.Offset = 0
+	if o.Limit > 0 {
+		h.Limit = o.Offset + o.Limit
+	}
+	return h
+}
+
+// mergePages сливает страницы, прочитанные с опциями opts.head(),
+// в страницу opts; Total — сумма по страницам.
+func mergePages(pages []ParcelPage, opts ListOptions) ParcelPage {
+	var res ParcelPage
+	for _, page := range pages {
+		res.Total += page.Total
+		res.Parcels = append(res.Parcels, page.Parcels...)
+	}
+	opts.sortParcels(res.Parcels)
+	res.Parcels = res.Parcels[min(opts.Offset, len(res.Parcels)):]
+	if opts.Limit > 0 && len(res.Parcels) > opts.Limit {
+		res.Parcels = res.Parcels[:opts.Limit]
+	}
+	return res
+}
+
+func (s ShardedStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	store, local := s.locate(number)
+	return store.SetStatus(ctx, local, status)
+}
+
+func (s ShardedStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	store, local := s.locate(number)
+	return store.SetSenderAddress(ctx, local, address)
+}
+
+func (s ShardedStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	store, local := s.locate(number)
+	return store.SetRecipientAddress(ctx, local, address)
+}
+
+// Update сохраняет посылку на её сегменте. Сменить клиента можно только
+// на клиента того же сегмента, иначе возвращается ErrCrossShardUpdate.
+func (s ShardedStorage) Update(ctx context.Context, p Parcel) error {
+	n := len(s.shards)
+	if p.Number >= 0 && p.Number%n != s.shardOf(p.Client) {
+		return ErrCrossShardUpdate
+	}
+	store, local := s.locate(p.Number)
+	p.Number = local
+	return store.Update(ctx, p)
+}
+
+func (s ShardedStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	store, local := s.locate(number)
+	return store.SetETA(ctx, local, eta)
+}
+
+func (s ShardedStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	store, local := s.locate(number)
+	return store.MarkPaid(ctx, local, txRef)
+}
+
+func (s ShardedStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	store, local := s.locate(number)
+	return store.Cancel(ctx, local, reason)
+}
+
+func (s ShardedStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	store, local := s.locate(number)
+	return store.Deliver(ctx, local, sig)
+}
+
+func (s ShardedStorage) Delete(ctx context.Context, number int) error {
+	store, local := s.locate(number)
+	return store.Delete(ctx, local)
+}
+
+func (s ShardedStorage) HardDelete(ctx context.Context, number int) error {
+	store, local := s.locate(number)
+	return store.HardDelete(ctx, local)
+}
+
+func (s ShardedStorage) Restore(ctx context.Context, number int) error {
+	store, local := s.locate(number)
+	return store.Restore(ctx, local)
+}
+
+func (s ShardedStorage) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	store, local := s.locate(number)
+	history, err := store.GetHistory(ctx, local)
+	for i := range history {
+		history[i].Number = number
+	}
+	return history, err
+}
+
+func (s ShardedStorage) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	store, local := s.locate(number)
+	return store.AddEvent(ctx, local, code, description, occurredAt)
+}
+
+func (s ShardedStorage) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	store, local := s.locate(number)
+	events, err := store.GetEvents(ctx, local)
+	for i := range events {
+		events[i].Number = number
+	}
+	return events, err
+}
+
+// Migrate обновляет схему каждого сегмента, который это умеет.
+func (s ShardedStorage) Migrate(ctx context.Context) error {
+	for i, store := range s.shards {
+		if m, ok := store.(interface{ Migrate(context.Context) error }); ok {
+			if err := m.Migrate(ctx); err != nil {
+				return fmt.Errorf("shard %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Close закрывает все сегменты.
+func (s ShardedStorage) Close() error {
+	var errs []error
+	for _, store := range s.shards {
+		if c, ok := store.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
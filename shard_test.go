@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// shardClients возвращает по клиенту на каждый сегмент store
+func shardClients(t *testing.T, store ShardedStorage) []int {
+	t.Helper()
+
+	clients := make([]int, len(store.shards))
+	found := 0
+	for client := 1; found < len(clients); client++ {
+		if shard := store.shardOf(client); clients[shard] == 0 {
+			clients[shard] = client
+			found++
+		}
+	}
+	return clients
+}
+
+// TestShardedStorage проверяет размещение посылок по сегментам клиентов
+// и слияние списков всех сегментов
+func TestShardedStorage(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	shards := []ParcelStore{NewParcelStore(openTempDB(t)), NewParcelStore(openTempDB(t))}
+	store, err := NewShardedStorage(shards[0], shards[1])
+	require.NoError(t, err)
+	clients := shardClients(t, store)
+
+	// add
+	var numbers []int
+	for i := range 6 {
+		p := getTestParcel()
+		p.Client = clients[i%2]
+		number, err := store.Add(ctx, p)
+		require.NoError(t, err)
+		numbers = append(numbers, number)
+	}
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[2], ParcelStatusSent))
+
+	// check
+	for i, client := range clients {
+		parcels, err := shards[i].GetByClient(ctx, client)
+		require.NoError(t, err)
+		require.Len(t, parcels, 3)
+	}
+
+	p, err := store.Get(ctx, numbers[1])
+	require.NoError(t, err)
+	require.Equal(t, numbers[1], p.Number)
+	require.Equal(t, clients[1], p.Client)
+
+	sent, err := store.ListAll(ctx, Filter{Statuses: []Status{ParcelStatusSent}}, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, sent.Total)
+	require.ElementsMatch(t, []int{numbers[1], numbers[2]}, parcelNumbers(sent.Parcels))
+
+	page, err := store.ListAll(ctx, Filter{}, ListOptions{Limit: 2, Offset: 1, SortBy: SortByNumber, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, 6, page.Total)
+	all, err := store.ListAll(ctx, Filter{}, ListOptions{SortBy: SortByNumber, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, parcelNumbers(all.Parcels[1:3]), parcelNumbers(page.Parcels))
+
+	// обход курсором видит все посылки по одному разу по возрастанию номера
+	var listed []int
+	for after := 0; ; {
+		page, err := store.List(ctx, Filter{}, after, 4)
+		require.NoError(t, err)
+		listed = append(listed, parcelNumbers(page.Parcels)...)
+		if page.NextCursor == "" {
+			break
+		}
+		after, err = DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+	}
+	require.IsIncreasing(t, listed)
+	require.ElementsMatch(t, numbers, listed)
+
+	// клиент с другого сегмента не может забрать посылку
+	p.Client = clients[0]
+	require.ErrorIs(t, store.Update(ctx, p), ErrCrossShardUpdate)
+
+	history, err := store.GetHistory(ctx, numbers[1])
+	require.NoError(t, err)
+	require.Equal(t, numbers[1], history[0].Number)
+}
+
+// TestShardsConfig проверяет открытие сегментов по настройкам
+func TestShardsConfig(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.DSN = ""
+	cfg.Shards = []string{filepath.Join(dir, "shard0.db"), filepath.Join(dir, "shard1.db")}
+
+	// check
+	store, db, err := NewParcelStoreFromConfig(cfg)
+	require.NoError(t, err)
+	require.Nil(t, db)
+	sharded, ok := store.(ShardedStorage)
+	require.True(t, ok)
+	require.Len(t, sharded.shards, 2)
+	require.NoError(t, sharded.Migrate(context.Background()))
+	require.NoError(t, sharded.Close())
+
+	cfg.Driver = "memory"
+	require.Error(t, cfg.Validate())
+}
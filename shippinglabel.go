@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Разметка транспортной этикетки A6 (105×148 мм) в миллиметрах.
+const (
+	shippingLabelMargin  = 6
+	shippingLabelBarcode = 28
+	// shippingLabelFont — шрифт с кириллицей: базовые шрифты PDF её не содержат
+	shippingLabelFont = "go"
+)
+
+// defaultServiceClass печатается для адресов вне зон тарифа.
+const defaultServiceClass = "стандарт"
+
+// GenerateShippingLabelPDF возвращает транспортную этикетку посылки number:
+// PDF формата A6 для термопринтеров с номером и классом доставки, адресами
+// отправителя и получателя, весом, габаритами и штрихкодом Code 128
+// с трек-кодом. Класс доставки — зона тарифа адреса получателя,
+// см. WithEstimator.
+func (s ParcelService) GenerateShippingLabelPDF(ctx context.Context, number int) ([]byte, error) {
+	p, err := s.store.Get(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if p.TrackCode == "" {
+		return nil, ErrNoTrackCode
+	}
+	barcode, err := renderLabel(p.TrackCode, LabelCode128)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("P", "mm", "A6", "")
+	pdf.SetMargins(shippingLabelMargin, shippingLabelMargin, shippingLabelMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	// даты документа — даты посылки, а не время печати
+	pdf.SetCreationDate(p.CreatedAt)
+	pdf.SetModificationDate(p.UpdatedAt)
+	pdf.SetTitle(fmt.Sprintf("Посылка № %d", p.Number), true)
+	pdf.AddUTF8FontFromBytes(shippingLabelFont, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(shippingLabelFont, "B", gobold.TTF)
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - 2*shippingLabelMargin
+
+	pdf.SetFont(shippingLabelFont, "B", 16)
+	pdf.CellFormat(width/2, 9, fmt.Sprintf("№ %d", p.Number), "", 0, "L", false, 0, "")
+	pdf.SetFont(shippingLabelFont, "B", 12)
+	pdf.CellFormat(width/2, 9, s.serviceClass(p), "", 1, "R", false, 0, "")
+	labelSeparator(pdf, width)
+
+	labelAddress(pdf, width, "Отправитель", p.SenderAddress, 9)
+	labelSeparator(pdf, width)
+	labelAddress(pdf, width, "Получатель", p.RecipientAddress, 12)
+	labelSeparator(pdf, width)
+
+	pdf.SetFont(shippingLabelFont, "", 10)
+	pdf.CellFormat(width/2, 6, "Вес: "+formatWeight(p.WeightGrams), "", 0, "L", false, 0, "")
+	pdf.CellFormat(width/2, 6, "Габариты: "+formatDimensions(p), "", 1, "R", false, 0, "")
+	labelSeparator(pdf, width)
+
+	opts := fpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("barcode", opts, bytes.NewReader(barcode))
+	pdf.ImageOptions("barcode", shippingLabelMargin, pdf.GetY()+2, width, shippingLabelBarcode, true, opts, 0, "")
+	pdf.SetFont(shippingLabelFont, "B", 12)
+	pdf.CellFormat(width, 6, p.TrackCode, "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render shipping label: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// serviceClass возвращает класс доставки посылки p для этикетки.
+func (s ParcelService) serviceClass(p Parcel) string {
+	zone := s.estimator.Zone(p.RecipientAddress)
+	class := zone.Name
+	if class == "" {
+		class = defaultServiceClass
+	}
+	if zone.TransitDays > 0 {
+		class = fmt.Sprintf("%s, %d дн.", class, zone.TransitDays)
+	}
+	return class
+}
+
+// labelAddress печатает подпись title и под ней адрес шрифтом size.
+func labelAddress(pdf *fpdf.Fpdf, width float64, title, address string, size float64) {
+	if address == "" {
+		address = "—"
+	}
+	pdf.SetFont(shippingLabelFont, "", 8)
+	pdf.CellFormat(width, 5, title, "", 1, "L", false, 0, "")
+	pdf.SetFont(shippingLabelFont, "B", size)
+	pdf.MultiCell(width, size*0.5, address, "", "L", false)
+}
+
+// labelSeparator рисует горизонтальную черту во всю ширину этикетки.
+func labelSeparator(pdf *fpdf.Fpdf, width float64) {
+	y := pdf.GetY() + 1.5
+	pdf.SetLineWidth(0.4)
+	pdf.Line(shippingLabelMargin, y, shippingLabelMargin+width, y)
+	pdf.SetY(y + 1.5)
+}
+
+// formatWeight возвращает вес в килограммах или прочерк для невзвешенной посылки.
+func formatWeight(grams int) string {
+	if grams == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%.3f кг", float64(grams)/1000)
+}
+
+// formatDimensions возвращает габариты посылки p в миллиметрах
+// или прочерк для неизмеренной.
+func formatDimensions(p Parcel) string {
+	if p.LengthMM == 0 && p.WidthMM == 0 && p.HeightMM == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%d×%d×%d мм", p.LengthMM, p.WidthMM, p.HeightMM)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ограничения подписи: имя помещается в колонку signed_by, а PNG
+// с росчерком на экране курьера редко больше нескольких десятков КиБ.
+const (
+	maxSignedByLen    = 128
+	maxSignatureBytes = 512 << 10
+)
+
+var (
+	// ErrInvalidSignature возвращается для подписи без имени
+	// или с изображением не в формате PNG.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrSignatureNotFound возвращается для посылки, доставленной без подписи.
+	ErrSignatureNotFound = errors.New("signature not found")
+)
+
+// Signature — подпись получателя при доставке: PNG-изображение росчерка
+// и имя расписавшегося.
+type Signature struct {
+	SignedBy string
+	Image    []byte
+}
+
+func (sig Signature) validate() error {
+	if strings.TrimSpace(sig.SignedBy) == "" || utf8.RuneCountInString(sig.SignedBy) > maxSignedByLen {
+		return fmt.Errorf("%w: signed_by must be 1 to %d characters", ErrInvalidSignature, maxSignedByLen)
+	}
+	if len(sig.Image) == 0 || len(sig.Image) > maxSignatureBytes {
+		return fmt.Errorf("%w: image must be 1 to %d bytes", ErrInvalidSignature, maxSignatureBytes)
+	}
+	if ct := http.DetectContentType(sig.Image); ct != "image/png" {
+		return fmt.Errorf("%w: image must be PNG, got %s", ErrInvalidSignature, ct)
+	}
+	return nil
+}
+
+// HasSignature сообщает, что получатель расписался при доставке.
+func (p Parcel) HasSignature() bool {
+	return !p.SignedAt.IsZero()
+}
+
+// SignatureStore читает подписи, сохранённые Deliver.
+type SignatureStore interface {
+	// GetSignature возвращает подпись посылки number или ErrSignatureNotFound
+	GetSignature(ctx context.Context, number int) (Signature, error)
+}
+
+var (
+	_ SignatureStore = ParcelStore{}
+	_ SignatureStore = (*MemoryParcelStore)(nil)
+)
+
+// Deliver меняет статус как SetStatus, со всеми его проверками, и в той же
+// транзакции сохраняет подпись, поэтому доставленной без подписи посылка
+// не окажется, даже если запись подписи не удалась.
+func (s ParcelStore) Deliver(ctx context.Context, number int, sig Signature) error {
+	if err := sig.validate(); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		if err := tx.SetStatus(ctx, number, ParcelStatusDelivered); err != nil {
+			return err
+		}
+		_, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"INSERT INTO parcel_signature (parcel_number, image) VALUES (?, ?)"),
+			number, sig.Image)
+		if err != nil {
+			return err
+		}
+		_, err = tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET signed_by = ?, signed_at = ? WHERE number = ?"),
+			sig.SignedBy, tx.timestampArg(), number)
+		return err
+	})
+}
+
+func (s ParcelStore) GetSignature(ctx context.Context, number int) (Signature, error) {
+	p, err := s.Get(ctx, number)
+	if err != nil {
+		return Signature{}, err
+	}
+	if !p.HasSignature() {
+		return Signature{}, ErrSignatureNotFound
+	}
+
+	sig := Signature{SignedBy: p.SignedBy}
+	err = s.q.QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT image FROM parcel_signature WHERE parcel_number = ?"), number).Scan(&sig.Image)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Signature{}, ErrSignatureNotFound
+	}
+	if err != nil {
+		return Signature{}, err
+	}
+	return sig, nil
+}
+
+func (s *MemoryParcelStore) Deliver(ctx context.Context, number int, sig Signature) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := sig.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	if err := s.transitions.Validate(p.Status, ParcelStatusDelivered); err != nil {
+		return err
+	}
+	if err := checkPayment(s.paymentRequired, p, ParcelStatusDelivered); err != nil {
+		return err
+	}
+	s.history[number] = append(s.history[number], StatusChange{
+		Number:    number,
+		OldStatus: p.Status,
+		NewStatus: ParcelStatusDelivered,
+		ChangedAt: formatTime(s.now()),
+	})
+	p.Status = ParcelStatusDelivered
+	p.Version++
+	p.UpdatedAt = timestamp(s.now)
+	p.SignedBy = sig.SignedBy
+	p.SignedAt = p.UpdatedAt
+	s.parcels[number] = p
+	s.signatures[number] = append([]byte(nil), sig.Image...)
+
+	return nil
+}
+
+func (s *MemoryParcelStore) GetSignature(ctx context.Context, number int) (Signature, error) {
+	if err := ctx.Err(); err != nil {
+		return Signature{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.parcel(ctx, number)
+	if !ok {
+		return Signature{}, ErrParcelNotFound
+	}
+	image, ok := s.signatures[number]
+	if !ok {
+		return Signature{}, ErrSignatureNotFound
+	}
+	return Signature{SignedBy: p.SignedBy, Image: append([]byte(nil), image...)}, nil
+}
+
+func (s OutboxStore) Deliver(ctx context.Context, number int, sig Signature) error {
+	return s.record(ctx, []int{number}, func(tx ParcelStore) error {
+		return tx.Deliver(ctx, number, sig)
+	})
+}
+
+// Deliver доставляет посылку number с подписью получателя signedBy
+// в формате PNG. Переход проверяется правилами сервиса, как в SetStatus.
+func (s ParcelService) Deliver(ctx context.Context, number int, signaturePNG []byte, signedBy string) error {
+	old, err := s.store.Get(ctx, number)
+	if err != nil {
+		return err
+	}
+	if err := s.transitions.Validate(old.Status, ParcelStatusDelivered); err != nil {
+		return err
+	}
+
+	if err := s.store.Deliver(ctx, number, Signature{SignedBy: signedBy, Image: signaturePNG}); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "parcel delivered with signature",
+		slog.Int("number", number), slog.Int("client", old.Client), slog.String("from", string(old.Status)))
+	s.changed(ctx, old)
+
+	if old.ReturnOf != 0 {
+		return s.completeReturn(ctx, old)
+	}
+	return nil
+}
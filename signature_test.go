@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkSignature проверяет, что подпись, сохранённая при доставке,
+// читается из хранилища store
+func checkSignature(t *testing.T, store interface {
+	ParcelStorage
+	SignatureStore
+}) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(store)
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+	_, err = store.GetSignature(ctx, p.Number)
+	require.ErrorIs(t, err, ErrSignatureNotFound)
+
+	// add
+	require.NoError(t, service.Deliver(ctx, p.Number, testPNG, "Петров П. П."))
+
+	// check
+	sig, err := store.GetSignature(ctx, p.Number)
+	require.NoError(t, err)
+	require.Equal(t, Signature{SignedBy: "Петров П. П.", Image: testPNG}, sig)
+	_, err = store.GetSignature(ctx, p.Number+1000)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestSignature проверяет подписи в SQLite и в памяти
+func TestSignature(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkSignature(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkSignature(t, NewMemoryParcelStore())
+	})
+}
+
+// TestHTTPDeliver проверяет доставку с подписью через REST API
+func TestHTTPDeliver(t *testing.T) {
+	// prepare
+	store := NewMemoryParcelStore()
+	srv := NewHTTPServer(NewParcelService(store))
+	rec := doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 7, "recipient_address": "test"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.False(t, created.HasSignature)
+	number := strconv.Itoa(created.Number)
+	require.NoError(t, store.SetStatus(context.Background(), created.Number, ParcelStatusSent))
+
+	// deliver
+	body := `{"signed_by": "Петров", "signature": "` + base64.StdEncoding.EncodeToString(testPNG) + `"}`
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/deliver", `{"signed_by": "Петров", "signature": "aGVsbG8="}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/deliver", body)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(t, srv, http.MethodPost, "/parcels/"+number+"/deliver", body)
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	// check
+	rec = doRequest(t, srv, http.MethodGet, "/parcels/"+number, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stored parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stored))
+	require.Equal(t, ParcelStatusDelivered.String(), stored.Status)
+	require.True(t, stored.HasSignature)
+	require.Equal(t, "Петров", stored.SignedBy)
+	require.NotEmpty(t, stored.SignedAt)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrUnknownServiceClass возвращается для посылки с классом обслуживания
+// не из serviceClasses или которого нет в настройках sla.
+var ErrUnknownServiceClass = errors.New("unknown service class")
+
+// SLAConfig задаёт сроки доставки по классам обслуживания: посылка класса
+// должна быть доставлена за Classes[класс] с регистрации. Посылки без
+// класса получают DefaultClass, пустой DefaultClass оставляет их без срока.
+// Every — интервал проверки просрочек в API-сервере и parcelctl sla check
+// --every, ноль выключает её в API-сервере.
+type SLAConfig struct {
+	Classes      map[string]time.Duration `yaml:"classes"`
+	DefaultClass string                   `yaml:"default_class"`
+	Every        time.Duration            `yaml:"every"`
+}
+
+func (c SLAConfig) enabled() bool {
+	return len(c.Classes) > 0
+}
+
+func (c SLAConfig) validate() error {
+	if c.Every < 0 {
+		return errors.New("config: sla check interval must not be negative")
+	}
+	for class, d := range c.Classes {
+		if class == "" || serviceClassRank(class) < 0 {
+			return fmt.Errorf("config: sla class %q: must be economy, standard or express", class)
+		}
+		if d <= 0 {
+			return fmt.Errorf("config: sla class %q: deadline must be positive", class)
+		}
+	}
+	if _, ok := c.Classes[c.DefaultClass]; c.DefaultClass != "" && !ok {
+		return fmt.Errorf("config: sla default class %q is not configured", c.DefaultClass)
+	}
+	return nil
+}
+
+// SLAStore отмечает посылки, не доставленные в срок SLA.
+type SLAStore interface {
+	// MarkBreached записывает now в sla_breached_at недоставленным посылкам
+	// (см. slaPending) со сроком раньше now и возвращает число отмеченных.
+	// Отмеченные раньше посылки не меняются
+	MarkBreached(ctx context.Context, now time.Time) (int, error)
+}
+
+var (
+	_ SLAStore = ParcelStore{}
+	_ SLAStore = (*MemoryParcelStore)(nil)
+)
+
+// MarkBreached не зависит от арендатора ctx.
+func (s ParcelStore) MarkBreached(ctx context.Context, now time.Time) (int, error) {
+	at := s.dialect.timeArg(now.UTC().Truncate(time.Second))
+	res, err := s.q.ExecContext(ctx, s.dialect.rebind(
+		"UPDATE parcel SET sla_breached_at = ?, version = version + 1, updated_at = ?"+
+			" WHERE deleted_at IS NULL AND status IN (?, ?) AND sla_breached_at IS NULL AND sla_deadline < ?"),
+		at, at, ParcelStatusRegistered, ParcelStatusSent, s.dialect.timeArg(now))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (s *MemoryParcelStore) MarkBreached(ctx context.Context, now time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at := now.UTC().Truncate(time.Second)
+	n := 0
+	for number, p := range s.parcels {
+		if !slaPending(p.Status) || !p.SLABreachedAt.IsZero() ||
+			p.SLADeadline.IsZero() || !p.SLADeadline.Before(now) {
+			continue
+		}
+		p.SLABreachedAt = at
+		p.Version++
+		p.UpdatedAt = at
+		s.parcels[number] = p
+		n++
+	}
+	return n, nil
+}
+
+// slaPending сообщает, что посылка в статусе status ещё не доставлена:
+// после доставки, в том числе в ветке возврата, срок SLA не нарушается.
+func slaPending(status Status) bool {
+	return status == ParcelStatusRegistered || status == ParcelStatusSent
+}
+
+// SLAStorage назначает новой посылке класс обслуживания и срок доставки
+// по нему. Срок, заданный явно, например при переносе, не меняется.
+type SLAStorage struct {
+	ParcelStorage
+	cfg SLAConfig
+	now func() time.Time
+}
+
+var _ ParcelStorage = SLAStorage{}
+
+func NewSLAStorage(next ParcelStorage, cfg SLAConfig) SLAStorage {
+	return SLAStorage{ParcelStorage: next, cfg: cfg, now: time.Now}
+}
+
+func (s SLAStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	if p.ServiceClass == "" {
+		p.ServiceClass = s.cfg.DefaultClass
+	}
+	if p.ServiceClass != "" {
+		d, ok := s.cfg.Classes[p.ServiceClass]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownServiceClass, p.ServiceClass)
+		}
+		if p.SLADeadline.IsZero() {
+			p.SLADeadline = timestamp(s.now).Add(d)
+		}
+	}
+	return s.ParcelStorage.Add(ctx, p)
+}
+
+// SLAChecker периодически отмечает посылки, не доставленные в срок SLA.
+type SLAChecker struct {
+	store    SLAStore
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewSLAChecker(store SLAStore, cfg SLAConfig) SLAChecker {
+	return SLAChecker{store: store, interval: cfg.Every, logger: slog.Default(), now: time.Now}
+}
+
+// WithLogger возвращает копию проверки, пишущую отчёты в logger.
+func (c SLAChecker) WithLogger(logger *slog.Logger) SLAChecker {
+	c.logger = logger
+	return c
+}
+
+// Run выполняет проверку сразу и затем каждые interval, пока не отменён ctx.
+// Ошибки проверки логируются и не останавливают её.
+func (c SLAChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce отмечает просроченные посылки и возвращает их число.
+func (c SLAChecker) RunOnce(ctx context.Context) (int, error) {
+	n, err := c.store.MarkBreached(ctx, c.now())
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.ErrorContext(ctx, "sla check", "error", err)
+		}
+		return n, err
+	}
+	if n > 0 {
+		c.logger.WarnContext(ctx, "sla check", "breached", n)
+	}
+	return n, nil
+}
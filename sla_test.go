@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkSLA проверяет сроки SLA новых посылок, отметку просроченных
+// и выборку ListBreached на хранилище store
+func checkSLA(t *testing.T, store interface {
+	ParcelStorage
+	SLAStore
+}) {
+	// prepare
+	ctx := context.Background()
+	cfg := SLAConfig{Classes: map[string]time.Duration{"standard": 120 * time.Hour, "express": 24 * time.Hour},
+		DefaultClass: "standard"}
+	require.NoError(t, cfg.validate())
+	sla := NewSLAStorage(store, cfg)
+	service := NewParcelService(sla)
+
+	// add
+	standard, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	express := getTestParcel()
+	express.ServiceClass = "express"
+	fast, err := service.Create(ctx, express)
+	require.NoError(t, err)
+	express = getTestParcel()
+	express.ServiceClass = "express"
+	delivered, err := service.Create(ctx, express)
+	require.NoError(t, err)
+	require.NoError(t, service.SetStatus(ctx, delivered.Number, ParcelStatusSent))
+	require.NoError(t, service.SetStatus(ctx, delivered.Number, ParcelStatusDelivered))
+
+	unknown := getTestParcel()
+	unknown.ServiceClass = "overnight"
+	_, err = service.Create(ctx, unknown)
+	require.ErrorIs(t, err, ErrUnknownServiceClass)
+
+	// check
+	require.Equal(t, "standard", standard.ServiceClass)
+	require.WithinDuration(t, time.Now().Add(120*time.Hour), standard.SLADeadline, 2*time.Second)
+	require.Equal(t, "express", fast.ServiceClass)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), fast.SLADeadline, 2*time.Second)
+	require.True(t, fast.SLABreachedAt.IsZero())
+
+	checker := NewSLAChecker(store, cfg)
+	checker.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	n, err := checker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	// отмеченная посылка не отмечается снова
+	n, err = checker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	p, err := store.Get(ctx, fast.Number)
+	require.NoError(t, err)
+	require.False(t, p.SLABreachedAt.IsZero())
+	require.Equal(t, fast.Version+1, p.Version)
+
+	page, err := service.ListBreached(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.Equal(t, []int{fast.Number}, parcelNumbers(page.Parcels))
+}
+
+// TestSLA проверяет сроки SLA в SQLite и в памяти
+func TestSLA(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkSLA(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkSLA(t, NewMemoryParcelStore())
+	})
+}
+
+// TestSLAConfig проверяет проверку настроек SLA
+func TestSLAConfig(t *testing.T) {
+	require.NoError(t, SLAConfig{}.validate())
+	require.NoError(t, SLAConfig{Classes: map[string]time.Duration{"standard": time.Hour}, DefaultClass: "standard"}.validate())
+
+	tests := []SLAConfig{
+		{Every: -time.Minute},
+		{Classes: map[string]time.Duration{"standard": 0}},
+		{Classes: map[string]time.Duration{"": time.Hour}},
+		{Classes: map[string]time.Duration{"standard": time.Hour}, DefaultClass: "express"},
+		{DefaultClass: "standard"},
+	}
+	for _, cfg := range tests {
+		require.Error(t, cfg.validate(), cfg)
+	}
+}
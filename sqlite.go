@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sqliteOptions — настройки соединений SQLite, см. OpenSQLite.
+type sqliteOptions struct {
+	journalMode string
+	busyTimeout time.Duration
+	synchronous string
+	foreignKeys bool
+	pool        PoolConfig
+}
+
+// Option меняет настройки OpenSQLite.
+type Option func(*sqliteOptions)
+
+// WithJournalMode задаёт PRAGMA journal_mode, по умолчанию WAL.
+func WithJournalMode(mode string) Option {
+	return func(o *sqliteOptions) { o.journalMode = mode }
+}
+
+// WithBusyTimeout задаёт, сколько соединение ждёт снятия блокировки
+// (PRAGMA busy_timeout), по умолчанию 5 секунд.
+func WithBusyTimeout(d time.Duration) Option {
+	return func(o *sqliteOptions) { o.busyTimeout = d }
+}
+
+// WithSynchronous задаёт PRAGMA synchronous, по умолчанию NORMAL:
+// в режиме WAL это безопасно и заметно быстрее FULL.
+func WithSynchronous(mode string) Option {
+	return func(o *sqliteOptions) { o.synchronous = mode }
+}
+
+// WithForeignKeys включает или выключает проверку внешних ключей
+// (PRAGMA foreign_keys), по умолчанию она включена: SQLite проверяет
+// их только по запросу соединения.
+func WithForeignKeys(on bool) Option {
+	return func(o *sqliteOptions) { o.foreignKeys = on }
+}
+
+// WithPoolConfig задаёт настройки пула соединений.
+func WithPoolConfig(p PoolConfig) Option {
+	return func(o *sqliteOptions) { o.pool = p }
+}
+
+// OpenSQLite открывает базу SQLite по пути path и возвращает хранилище.
+// Прагмы применяются к каждому новому соединению пула, а не только
+// к первому, поэтому вызывающему коду не нужно помнить о них.
+func OpenSQLite(path string, opts ...Option) (ParcelStore, error) {
+	o := sqliteOptions{
+		journalMode: "WAL",
+		busyTimeout: 5 * time.Second,
+		synchronous: "NORMAL",
+		foreignKeys: true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(path, o))
+	if err != nil {
+		return ParcelStore{}, err
+	}
+	// проверяем путь и прагмы сразу, а не при первом запросе
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return ParcelStore{}, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+
+	return NewParcelStore(db).WithPool(o.pool), nil
+}
+
+// sqliteDSN добавляет прагмы к пути в параметрах _pragma драйвера.
+// busy_timeout идёт первым, чтобы смена journal_mode тоже ждала блокировку.
+func sqliteDSN(path string, o sqliteOptions) string {
+	q := url.Values{}
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", o.busyTimeout.Milliseconds()))
+	if o.journalMode != "" {
+		q.Add("_pragma", "journal_mode("+o.journalMode+")")
+	}
+	if o.synchronous != "" {
+		q.Add("_pragma", "synchronous("+o.synchronous+")")
+	}
+	if o.foreignKeys {
+		q.Add("_pragma", "foreign_keys(1)")
+	}
+
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + q.Encode()
+}
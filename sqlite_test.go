@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestOpenSQLite проверяет, что прагмы применяются к каждому соединению пула
+func TestOpenSQLite(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store, err := OpenSQLite(filepath.Join(t.TempDir(), "wal.db"),
+		WithBusyTimeout(time.Second), WithPoolConfig(PoolConfig{MaxOpenConns: 2}))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.Migrate(ctx))
+
+	// check: держим оба соединения, чтобы проверить каждое
+	var conns []*sql.Conn
+	for i := 0; i < 2; i++ {
+		conn, err := store.db.Conn(ctx)
+		require.NoError(t, err)
+		conns = append(conns, conn)
+
+		var mode string
+		var timeout, synchronous, foreignKeys int
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&timeout))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA synchronous").Scan(&synchronous))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys))
+
+		require.Equal(t, "wal", mode)
+		require.Equal(t, 1000, timeout)
+		require.Equal(t, 1, synchronous) // NORMAL
+		require.Equal(t, 1, foreignKeys)
+	}
+	for _, conn := range conns {
+		require.NoError(t, conn.Close())
+	}
+
+	// add: внешний ключ не пускает посылки несуществующего клиента
+	parcel := getTestParcel()
+	_, err = store.Add(ctx, parcel)
+	require.ErrorIs(t, err, ErrClientNotFound)
+
+	_, err = store.AddClient(ctx, Client{ID: parcel.Client, Name: "test"})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// invalid path
+	_, err = OpenSQLite(filepath.Join(t.TempDir(), "missing", "wal.db"))
+	require.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseHeartbeat — как часто по умолчанию молчащий поток Server-Sent Events
+// пишет комментарий, чтобы прокси и балансировщики не закрыли соединение.
+const sseHeartbeat = 15 * time.Second
+
+// sseRetry — через сколько миллисекунд EventSource переподключается
+// после обрыва потока.
+const sseRetry = 3000
+
+// sseMessage — запись потока Server-Sent Events; пустой id не пишется.
+type sseMessage struct {
+	id    string
+	event string
+	data  any
+}
+
+// streamContext возвращает контекст потока запроса r: он отменяется
+// при отключении клиента и при CloseStreams.
+func (s *HTTPServer) streamContext(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	go func() {
+		select {
+		case <-s.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// streamSSE пишет записи из items потоком Server-Sent Events, пока канал
+// не закроется или клиент не отключится, и раз в heartbeat — комментарий
+// в молчащий поток. Перед первой записью он отправляет заголовки и retry.
+func streamSSE[T any](s *HTTPServer, w http.ResponseWriter, items <-chan T, message func(T) sseMessage) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				return
+			}
+			msg := message(item)
+			data, err := json.Marshal(msg.data)
+			if err != nil {
+				return
+			}
+			if msg.id != "" {
+				fmt.Fprintf(w, "id: %s\n", msg.id)
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.event, data)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
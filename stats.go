@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// StatsReport — показатели доставки по посылкам, созданным в полуинтервале [From, To).
+type StatsReport struct {
+	From time.Time
+	To   time.Time
+	// Created — число созданных посылок, Delivered — сколько из них доставлено
+	Created   int
+	Delivered int
+	// AvgDeliveryTime — среднее время от регистрации до доставки
+	// по доставленным посылкам, ноль, если таких нет
+	AvgDeliveryTime time.Duration
+	// PerDay упорядочен по дням (UTC), PerClient — по убыванию числа посылок
+	PerDay    []DayCount
+	PerClient []ClientCount
+}
+
+// DayCount — число посылок, созданных за день Day в формате 2006-01-02.
+type DayCount struct {
+	Day   string
+	Count int
+}
+
+// ClientCount — число посылок клиента.
+type ClientCount struct {
+	Client int
+	Count  int
+}
+
+// ParcelReporter строит отчёты по посылкам.
+type ParcelReporter interface {
+	Stats(ctx context.Context, from, to time.Time) (StatsReport, error)
+}
+
+var (
+	_ ParcelReporter = ParcelStore{}
+	_ ParcelReporter = (*MemoryParcelStore)(nil)
+)
+
+// dayLayout — формат дня в StatsReport.PerDay.
+const dayLayout = "2006-01-02"
+
+// Stats считает показатели доставки агрегатами в БД. Моментом доставки
+// считается последняя смена статуса на delivered из истории.
+func (s ParcelStore) Stats(ctx context.Context, from, to time.Time) (StatsReport, error) {
+	if !to.After(from) {
+		return StatsReport{}, ErrInvalidRange
+	}
+	report := StatsReport{From: from, To: to}
+	tenant, tenantArgs := tenantCondOn(ctx, "p.tenant_id")
+	inRange := " WHERE p.created_at >= ? AND p.created_at < ? AND p.deleted_at IS NULL" + tenant
+	rangeArgs := append([]any{s.dialect.timeArg(from), s.dialect.timeArg(to)}, tenantArgs...)
+
+	var avg sql.NullFloat64
+	err := s.reader(0).QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT COUNT(*), AVG(seconds) FROM (SELECT "+
+			s.dialect.secondsBetween("p.created_at", "MAX(h.changed_at)")+" AS seconds"+
+			" FROM parcel p JOIN parcel_status_history h"+
+			" ON h.parcel_number = p.number AND h.new_status = ?"+inRange+
+			" GROUP BY p.number, p.created_at) d"),
+		append([]any{ParcelStatusDelivered}, rangeArgs...)...).Scan(&report.Delivered, &avg)
+	if err != nil {
+		return StatsReport{}, err
+	}
+	if avg.Valid {
+		report.AvgDeliveryTime = time.Duration(avg.Float64 * float64(time.Second))
+	}
+
+	rows, err := s.reader(0).QueryContext(ctx, s.dialect.rebind(
+		"SELECT "+s.dialect.day("p.created_at")+" AS day, COUNT(*) FROM parcel p"+inRange+
+			" GROUP BY day ORDER BY day"),
+		rangeArgs...)
+	if err != nil {
+		return StatsReport{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d DayCount
+		if err := rows.Scan(&d.Day, &d.Count); err != nil {
+			return StatsReport{}, err
+		}
+		report.PerDay = append(report.PerDay, d)
+		report.Created += d.Count
+	}
+	if err := rows.Err(); err != nil {
+		return StatsReport{}, err
+	}
+
+	rows, err = s.reader(0).QueryContext(ctx, s.dialect.rebind(
+		"SELECT p.client, COUNT(*) FROM parcel p"+inRange+
+			" GROUP BY p.client ORDER BY COUNT(*) DESC, p.client"),
+		rangeArgs...)
+	if err != nil {
+		return StatsReport{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c ClientCount
+		if err := rows.Scan(&c.Client, &c.Count); err != nil {
+			return StatsReport{}, err
+		}
+		report.PerClient = append(report.PerClient, c)
+	}
+	if err := rows.Err(); err != nil {
+		return StatsReport{}, err
+	}
+
+	return report, nil
+}
+
+// Stats считает показатели доставки по посылкам в памяти.
+func (s *MemoryParcelStore) Stats(ctx context.Context, from, to time.Time) (StatsReport, error) {
+	if err := ctx.Err(); err != nil {
+		return StatsReport{}, err
+	}
+	if !to.After(from) {
+		return StatsReport{}, ErrInvalidRange
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := StatsReport{From: from, To: to}
+	perDay := map[string]int{}
+	perClient := map[int]int{}
+	var total time.Duration
+	for _, p := range s.parcels {
+		if p.CreatedAt.Before(from) || !p.CreatedAt.Before(to) || !s.visible(ctx, p.Number) {
+			continue
+		}
+		report.Created++
+		perDay[p.CreatedAt.UTC().Format(dayLayout)]++
+		perClient[p.Client]++
+
+		var deliveredAt string
+		for _, h := range s.history[p.Number] {
+			if h.NewStatus == ParcelStatusDelivered && h.ChangedAt > deliveredAt {
+				deliveredAt = h.ChangedAt
+			}
+		}
+		if t, err := time.Parse(time.RFC3339, deliveredAt); err == nil {
+			report.Delivered++
+			total += t.Sub(p.CreatedAt)
+		}
+	}
+	if report.Delivered > 0 {
+		report.AvgDeliveryTime = total / time.Duration(report.Delivered)
+	}
+
+	for day, n := range perDay {
+		report.PerDay = append(report.PerDay, DayCount{Day: day, Count: n})
+	}
+	sort.Slice(report.PerDay, func(i, j int) bool { return report.PerDay[i].Day < report.PerDay[j].Day })
+
+	for client, n := range perClient {
+		report.PerClient = append(report.PerClient, ClientCount{Client: client, Count: n})
+	}
+	sort.Slice(report.PerClient, func(i, j int) bool {
+		a, b := report.PerClient[i], report.PerClient[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Client < b.Client
+	})
+
+	return report, nil
+}
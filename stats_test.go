@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkStats проверяет отчёт по доставке. Часы store должны возвращать *now
+func checkStats(t *testing.T, store interface {
+	ParcelStorage
+	ParcelReporter
+}, now *time.Time) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	created := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+
+	// посылка вне периода отчёта
+	*now = created.Add(-30 * 24 * time.Hour)
+	_, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	*now = created
+	var numbers []int
+	for _, client := range []int{1, 1, 2} {
+		parcel := getTestParcel()
+		parcel.Client = client
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	*now = created.Add(2 * time.Hour)
+	require.NoError(t, store.SetStatus(ctx, numbers[0], ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, numbers[0], ParcelStatusDelivered))
+	require.NoError(t, store.SetStatus(ctx, numbers[1], ParcelStatusSent))
+
+	// stats
+	report, err := store.Stats(ctx, created.Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	// check
+	require.Equal(t, 3, report.Created)
+	require.Equal(t, 1, report.Delivered)
+	require.InDelta(t, 2*time.Hour, report.AvgDeliveryTime, float64(5*time.Second))
+	require.Equal(t, []DayCount{{Day: created.Format(dayLayout), Count: 3}}, report.PerDay)
+	require.Equal(t, []ClientCount{{Client: 1, Count: 2}, {Client: 2, Count: 1}}, report.PerClient)
+
+	// empty
+	report, err = store.Stats(ctx, created.Add(time.Hour), created.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, report.Created)
+	require.Zero(t, report.AvgDeliveryTime)
+
+	_, err = store.Stats(ctx, created, created)
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
+
+// TestStats проверяет отчёт по доставке в SQLite
+func TestStats(t *testing.T) {
+	var now time.Time
+	checkStats(t, NewParcelStore(openTempDB(t)).WithClock(func() time.Time { return now }), &now)
+}
+
+// TestMemoryStats проверяет отчёт по доставке в памяти
+func TestMemoryStats(t *testing.T) {
+	var now time.Time
+	store := NewMemoryParcelStore()
+	store.SetClock(func() time.Time { return now })
+	checkStats(t, store, &now)
+}
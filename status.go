@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Status — статус посылки. Статус из внешнего ввода разбирает ParseStatus.
+type Status string
+
+const (
+	ParcelStatusRegistered Status = "registered"
+	ParcelStatusSent       Status = "sent"
+	ParcelStatusDelivered  Status = "delivered"
+	// возврат доставленной посылки: получатель запросил возврат, возвратная
+	// посылка в пути к отправителю, возврат получен
+	ParcelStatusReturnRequested Status = "return_requested"
+	ParcelStatusReturning       Status = "returning"
+	ParcelStatusReturned        Status = "returned"
+	// ParcelStatusCancelled — посылка отменена до отправки, см. ParcelService.Cancel
+	ParcelStatusCancelled Status = "cancelled"
+	// ParcelStatusReturnToSender — посылку не удалось доставить, и она едет
+	// обратно к отправителю, см. ParcelService.RecordDeliveryAttempt
+	ParcelStatusReturnToSender Status = "return_to_sender"
+)
+
+// ErrUnknownStatus возвращает ParseStatus для статуса не из statusNames
+// и не из переходов, добавленных через StatusTransitions.With.
+var ErrUnknownStatus = errors.New("unknown parcel status")
+
+// Языки названий статусов.
+const (
+	LangRU = "ru"
+	LangEN = "en"
+	// defaultLang — язык названий, если нужного языка нет
+	defaultLang = LangEN
+)
+
+// statusNames — названия статусов для людей по языкам. Кроме статусов
+// из этой таблицы, ParseStatus принимает только зарегистрированные With.
+var statusNames = map[Status]map[string]string{
+	ParcelStatusRegistered:      {LangRU: "зарегистрирована", LangEN: "registered"},
+	ParcelStatusSent:            {LangRU: "отправлена", LangEN: "in transit"},
+	ParcelStatusDelivered:       {LangRU: "доставлена", LangEN: "delivered"},
+	ParcelStatusReturnRequested: {LangRU: "запрошен возврат", LangEN: "return requested"},
+	ParcelStatusReturning:       {LangRU: "возвращается", LangEN: "returning"},
+	ParcelStatusReturned:        {LangRU: "возвращена", LangEN: "returned"},
+	ParcelStatusCancelled:       {LangRU: "отменена", LangEN: "cancelled"},
+	ParcelStatusReturnToSender:  {LangRU: "возвращается отправителю", LangEN: "returning to sender"},
+}
+
+// extraStatuses — статусы, которые добавил StatusTransitions.With.
+var extraStatuses struct {
+	sync.RWMutex
+	m map[Status]bool
+}
+
+// registerStatus делает статус s известным для ParseStatus и Valid.
+func registerStatus(s Status) {
+	if _, ok := statusNames[s]; ok {
+		return
+	}
+	extraStatuses.Lock()
+	defer extraStatuses.Unlock()
+
+	if extraStatuses.m == nil {
+		extraStatuses.m = map[Status]bool{}
+	}
+	extraStatuses.m[s] = true
+}
+
+// ParseStatus разбирает статус из запроса API или аргумента CLI
+// без учёта регистра и пробелов по краям.
+func ParseStatus(s string) (Status, error) {
+	status := Status(strings.ToLower(strings.TrimSpace(s)))
+	if !status.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+	}
+	return status, nil
+}
+
+// Valid сообщает, что статус известен: есть в statusNames
+// или добавлен через StatusTransitions.With.
+func (s Status) Valid() bool {
+	if _, ok := statusNames[s]; ok {
+		return true
+	}
+	extraStatuses.RLock()
+	defer extraStatuses.RUnlock()
+
+	return extraStatuses.m[s]
+}
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// DisplayName возвращает название статуса на языке lang, например
+// из заголовка Accept-Language: ru-RU означает ru. Для языка без перевода
+// возвращается название на английском, для неизвестного статуса — сам статус.
+func (s Status) DisplayName(lang string) string {
+	names, ok := statusNames[s]
+	if !ok {
+		return string(s)
+	}
+	lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+	if name, ok := names[lang]; ok {
+		return name
+	}
+	return names[defaultLang]
+}
+
+// Set разбирает значение флага CLI через ParseStatus: вместе со String
+// и Type статус реализует pflag.Value.
+func (s *Status) Set(value string) error {
+	status, err := ParseStatus(value)
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+func (s *Status) Type() string {
+	return "status"
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText разбирает статус через ParseStatus, поэтому JSON
+// с неизвестным статусом не декодируется.
+func (s *Status) UnmarshalText(text []byte) error {
+	return s.Set(string(text))
+}
+
+// ErrInvalidTransition возвращается при попытке перевести посылку
+// в статус, недопустимый из текущего.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// StatusTransitions описывает допустимые переходы статусов:
+// ключ — текущий статус, значение — статусы, в которые из него можно перейти.
+type StatusTransitions map[Status][]Status
+
+// DefaultStatusTransitions возвращает переходы registered → sent → delivered,
+// ветку возврата delivered → return_requested → returning → returned
+// и возврат недоставленной посылки sent → return_to_sender → returned.
+func DefaultStatusTransitions() StatusTransitions {
+	return StatusTransitions{
+		ParcelStatusRegistered:      {ParcelStatusSent},
+		ParcelStatusSent:            {ParcelStatusDelivered, ParcelStatusReturnToSender},
+		ParcelStatusReturnToSender:  {ParcelStatusReturned},
+		ParcelStatusDelivered:       {ParcelStatusReturnRequested},
+		ParcelStatusReturnRequested: {ParcelStatusReturning},
+		ParcelStatusReturning:       {ParcelStatusReturned},
+	}
+}
+
+// With возвращает копию переходов, дополненную переходами из from в to.
+// Так подключаются дополнительные статусы, например lost: With регистрирует
+// новые статусы, и дальше их принимают ParseStatus, декодирование JSON
+// и Filter. Названием для людей у такого статуса служит он сам, поэтому
+// его стоит задавать в нижнем регистре, как его нормализует ParseStatus.
+func (t StatusTransitions) With(from Status, to ...Status) StatusTransitions {
+	registerStatus(from)
+	for _, st := range to {
+		registerStatus(st)
+	}
+
+	res := make(StatusTransitions, len(t)+1)
+	for k, v := range t {
+		res[k] = append([]Status(nil), v...)
+	}
+	res[from] = append(res[from], to...)
+
+	return res
+}
+
+// Allowed сообщает, можно ли перевести посылку из статуса from в статус to.
+func (t StatusTransitions) Allowed(from, to Status) bool {
+	for _, next := range t[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Known сообщает, встречается ли status в правилах переходов.
+func (t StatusTransitions) Known(status Status) bool {
+	for from, to := range t {
+		if from == status {
+			return true
+		}
+		for _, next := range to {
+			if next == status {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate возвращает ErrInvalidTransition, если переход from → to запрещён.
+func (t StatusTransitions) Validate(from, to Status) error {
+	if !t.Allowed(from, to) {
+		return ErrInvalidTransition
+	}
+	return nil
+}
+
+// sources возвращает отсортированные статусы, из которых разрешён переход в to.
+func (t StatusTransitions) sources(to Status) []Status {
+	var res []Status
+	for from := range t {
+		if t.Allowed(from, to) {
+			res = append(res, from)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+
+	return res
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestParseStatus проверяет разбор статуса и отказ от неизвестных
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus(" Sent ")
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, status)
+
+	_, err = ParseStatus("lost")
+	require.ErrorIs(t, err, ErrUnknownStatus)
+	_, err = ParseStatus("")
+	require.ErrorIs(t, err, ErrUnknownStatus)
+
+	// JSON и YAML разбирают статус так же
+	var req setStatusRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"status": "delivered"}`), &req))
+	require.Equal(t, ParcelStatusDelivered, req.Status)
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"status": "lost"}`), &req), ErrUnknownStatus)
+
+	var rule RetentionRule
+	require.ErrorIs(t, yaml.Unmarshal([]byte("status: lost"), &rule), ErrUnknownStatus)
+
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"status": "delivered"}`, string(data))
+}
+
+// TestStatusDisplayName проверяет названия статусов на разных языках
+func TestStatusDisplayName(t *testing.T) {
+	require.Equal(t, "отправлена", ParcelStatusSent.DisplayName(LangRU))
+	require.Equal(t, "отправлена", ParcelStatusSent.DisplayName("ru-RU"))
+	require.Equal(t, "in transit", ParcelStatusSent.DisplayName(LangEN))
+	// язык без перевода и неизвестный статус
+	require.Equal(t, "delivered", ParcelStatusDelivered.DisplayName("de"))
+	require.Equal(t, "delivered", ParcelStatusDelivered.DisplayName(""))
+	require.Equal(t, "lost", Status("lost").DisplayName(LangRU))
+
+	for status := range statusNames {
+		require.NotEmpty(t, status.DisplayName(LangRU), status)
+		require.NotEmpty(t, status.DisplayName(LangEN), status)
+	}
+}
+
+// TestHTTPStatus проверяет название статуса в ответе и отказ от неизвестного статуса
+func TestHTTPStatus(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+	rec := doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 7, "recipient_address": "test"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	// check
+	req, err := http.NewRequest(http.MethodGet, "/parcels/1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp parcelResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, ParcelStatusRegistered.String(), resp.Status)
+	require.Equal(t, "зарегистрирована", resp.StatusName)
+
+	rec = doRequest(t, srv, http.MethodPatch, "/parcels/1/status", `{"status": "lost"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(t, srv, http.MethodGet, "/parcels?status=lost", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestStatusTransitions проверяет правила смены статуса
+func TestStatusTransitions(t *testing.T) {
+	transitions := DefaultStatusTransitions()
+
+	require.True(t, transitions.Allowed(ParcelStatusRegistered, ParcelStatusSent))
+	require.True(t, transitions.Allowed(ParcelStatusSent, ParcelStatusDelivered))
+	require.False(t, transitions.Allowed(ParcelStatusDelivered, ParcelStatusRegistered))
+	require.False(t, transitions.Allowed(ParcelStatusRegistered, ParcelStatusDelivered))
+	require.ErrorIs(t, transitions.Validate(ParcelStatusSent, ParcelStatusRegistered), ErrInvalidTransition)
+
+	// extra states
+	extended := transitions.With(ParcelStatusSent, "returned")
+	require.True(t, extended.Allowed(ParcelStatusSent, "returned"))
+	require.True(t, extended.Allowed(ParcelStatusSent, ParcelStatusDelivered))
+	require.False(t, transitions.Allowed(ParcelStatusSent, "returned"))
+}
+
+// TestSetStatusInvalidTransition проверяет, что хранилище отклоняет недопустимый переход
+func TestSetStatusInvalidTransition(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	stores := map[string]ParcelStorage{
+		"sqlite": NewParcelStore(db),
+		"memory": NewMemoryParcelStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			id, err := store.Add(ctx, getTestParcel())
+			require.NoError(t, err)
+
+			// registered → delivered
+			err = store.SetStatus(ctx, id, ParcelStatusDelivered)
+			require.ErrorIs(t, err, ErrInvalidTransition)
+
+			// registered → sent → delivered
+			require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+			require.NoError(t, store.SetStatus(ctx, id, ParcelStatusDelivered))
+
+			// delivered → registered
+			err = store.SetStatus(ctx, id, ParcelStatusRegistered)
+			require.ErrorIs(t, err, ErrInvalidTransition)
+
+			stored, err := store.Get(ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, ParcelStatusDelivered, stored.Status)
+		})
+	}
+}
+
+// TestWithTransitions проверяет подключение дополнительного статуса
+func TestWithTransitions(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db).WithTransitions(
+		DefaultStatusTransitions().With(ParcelStatusSent, "returned"))
+
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+	require.NoError(t, store.SetStatus(ctx, id, "returned"))
+
+	err = store.SetStatus(ctx, id, ParcelStatusDelivered)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+}
+
+// TestWithTransitionsStatus проверяет, что статус, добавленный через With,
+// принимают разбор статуса, JSON и фильтр
+func TestWithTransitionsStatus(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	const customs Status = "held_at_customs"
+	store := NewMemoryParcelStore()
+	store.SetTransitions(DefaultStatusTransitions().With(ParcelStatusSent, customs))
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusSent))
+
+	// check
+	status, err := ParseStatus(" Held_At_Customs ")
+	require.NoError(t, err)
+	require.Equal(t, customs, status)
+
+	var req setStatusRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"status": "held_at_customs"}`), &req))
+	require.NoError(t, store.SetStatus(ctx, id, req.Status))
+
+	page, err := store.ListAll(ctx, Filter{Statuses: []Status{customs}}, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.Equal(t, "held_at_customs", customs.DisplayName(LangRU))
+}
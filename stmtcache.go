@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// stmtCache хранит подготовленные запросы хранилища. Запросы готовятся
+// на *sql.DB один раз, а database/sql сам переподготавливает их
+// на каждом соединении пула при первом использовании.
+type stmtCache struct {
+	db *sql.DB
+
+	mu     sync.RWMutex
+	stmts  map[string]*sql.Stmt
+	closed bool
+}
+
+// WithStatementCache возвращает копию хранилища, которая готовит запросы
+// SELECT, INSERT, UPDATE и DELETE один раз и переиспользует их, в том числе
+// внутри транзакций. Подготовленные запросы закрываются в Close.
+// Кэш общий для всех копий хранилища, полученных от возвращённого значения.
+// Хранилище не поверх *sql.DB возвращается без изменений.
+func (s ParcelStore) WithStatementCache() ParcelStore {
+	if s.db == nil {
+		return s
+	}
+	s.stmts = &stmtCache{db: s.db, stmts: map[string]*sql.Stmt{}}
+	s.q = s.wrap(unwrapQuerier(s.q))
+	if s.replica != nil {
+		s.replicaStmts = &stmtCache{db: s.replica, stmts: map[string]*sql.Stmt{}}
+		s.r = s.wrapReplica()
+	}
+	return s
+}
+
+// get возвращает подготовленный запрос; ok равен false, если запрос
+// не кэшируется или кэш уже закрыт.
+func (c *stmtCache) get(ctx context.Context, query string) (stmt *sql.Stmt, ok bool, err error) {
+	if !cacheable(query) {
+		return nil, false, nil
+	}
+
+	c.mu.RLock()
+	stmt, ok = c.stmts[query]
+	closed := c.closed
+	c.mu.RUnlock()
+	if ok || closed {
+		return stmt, ok, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, false, nil
+	}
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, true, nil
+	}
+
+	stmt, err = c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+	c.stmts[query] = stmt
+
+	return stmt, true, nil
+}
+
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for query, stmt := range c.stmts {
+		errs = append(errs, stmt.Close())
+		delete(c.stmts, query)
+	}
+	c.closed = true
+
+	return errors.Join(errs...)
+}
+
+// cacheable отсекает DDL миграций и прочие разовые запросы.
+func cacheable(query string) bool {
+	verb, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	switch strings.ToUpper(verb) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+// cachingQuerier выполняет запросы через подготовленные запросы кэша.
+type cachingQuerier struct {
+	q     querier
+	cache *stmtCache
+}
+
+// stmt возвращает подготовленный запрос, привязанный к транзакции, если q — транзакция.
+func (c cachingQuerier) stmt(ctx context.Context, query string) (*sql.Stmt, bool) {
+	stmt, ok, err := c.cache.get(ctx, query)
+	if err != nil || !ok {
+		// запрос выполнится без подготовки и сам вернёт ошибку, если она есть
+		return nil, false
+	}
+	if tx, isTx := c.q.(*sql.Tx); isTx {
+		return tx.StmtContext(ctx, stmt), true
+	}
+	return stmt, true
+}
+
+func (c cachingQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if stmt, ok := c.stmt(ctx, query); ok {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return c.q.ExecContext(ctx, query, args...)
+}
+
+func (c cachingQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if stmt, ok := c.stmt(ctx, query); ok {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return c.q.QueryContext(ctx, query, args...)
+}
+
+func (c cachingQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if stmt, ok := c.stmt(ctx, query); ok {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return c.q.QueryRowContext(ctx, query, args...)
+}
+
+func (c cachingQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.q.PrepareContext(ctx, query)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatementCache проверяет работу хранилища с кэшем подготовленных запросов
+func TestStatementCache(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+	ctx := context.Background()
+	store := NewParcelStore(db).WithStatementCache()
+	parcel := getTestParcel()
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// get, set status и delete повторно используют подготовленные запросы
+	for i := 0; i < 2; i++ {
+		_, err = store.Get(ctx, id)
+		require.NoError(t, err)
+	}
+	cached := len(store.stmts.stmts)
+
+	_, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, store.stmts.stmts, cached)
+
+	// запросы внутри транзакции
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.NoError(t, err)
+
+	err = store.Delete(ctx, id)
+	require.ErrorIs(t, err, ErrDeleteNotAllowed)
+
+	// close
+	require.NoError(t, store.Close())
+	require.Empty(t, store.stmts.stmts)
+
+	// хранилище закрыто вместе с подключением
+	_, err = store.Get(ctx, id)
+	require.Error(t, err)
+}
+
+func benchmarkGet(b *testing.B, store ParcelStore) {
+	ctx := context.Background()
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := store.Get(ctx, id); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	benchmarkGet(b, NewParcelStore(openTempDB(b)))
+}
+
+func BenchmarkGetCached(b *testing.B) {
+	store := NewParcelStore(openTempDB(b)).WithStatementCache()
+	defer store.Close()
+
+	benchmarkGet(b, store)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ParcelStorage описывает хранилище посылок, с которым работает сервис.
+// Реализации должны соблюдать общие правила: менять адреса и удалять
+// можно только посылки в статусе registered, а удалённая через Delete
+// посылка не видна остальным методам, пока её не вернёт Restore.
+type ParcelStorage interface {
+	Add(ctx context.Context, p Parcel) (int, error)
+	Get(ctx context.Context, number int) (Parcel, error)
+	GetByTrackCode(ctx context.Context, code string) (Parcel, error)
+	GetByUUID(ctx context.Context, id string) (Parcel, error)
+	GetByClient(ctx context.Context, client int) ([]Parcel, error)
+	ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error)
+	List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error)
+	ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error)
+	SetStatus(ctx context.Context, number int, status Status) error
+	SetSenderAddress(ctx context.Context, number int, address string) error
+	SetRecipientAddress(ctx context.Context, number int, address string) error
+	Update(ctx context.Context, p Parcel) error
+	SetETA(ctx context.Context, number int, eta time.Time) error
+	MarkPaid(ctx context.Context, number int, txRef string) error
+	Cancel(ctx context.Context, number int, reason CancelReason) error
+	// Deliver переводит посылку в delivered и сохраняет подпись получателя
+	// в одной транзакции, см. Signature
+	Deliver(ctx context.Context, number int, sig Signature) error
+	Delete(ctx context.Context, number int) error
+	HardDelete(ctx context.Context, number int) error
+	Restore(ctx context.Context, number int) error
+	GetHistory(ctx context.Context, number int) ([]StatusChange, error)
+	AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error)
+	GetEvents(ctx context.Context, number int) ([]TrackingEvent, error)
+}
+
+// StorageFactory создаёт хранилище поверх открытого подключения к БД.
+type StorageFactory func(db *sql.DB) ParcelStorage
+
+var (
+	storagesMu sync.RWMutex
+	storages   = map[string]StorageFactory{}
+)
+
+// RegisterStorage регистрирует реализацию хранилища под именем name.
+// Повторная регистрация того же имени или nil-фабрики приводит к панике,
+// по аналогии с sql.Register.
+func RegisterStorage(name string, factory StorageFactory) {
+	storagesMu.Lock()
+	defer storagesMu.Unlock()
+
+	if factory == nil {
+		panic("storage: RegisterStorage factory is nil")
+	}
+	if _, dup := storages[name]; dup {
+		panic("storage: RegisterStorage called twice for " + name)
+	}
+	storages[name] = factory
+}
+
+// Storages возвращает отсортированный список зарегистрированных хранилищ.
+func Storages() []string {
+	storagesMu.RLock()
+	defer storagesMu.RUnlock()
+
+	names := make([]string, 0, len(storages))
+	for name := range storages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// OpenStorage создаёт хранилище, зарегистрированное под именем name.
+func OpenStorage(name string, db *sql.DB) (ParcelStorage, error) {
+	storagesMu.RLock()
+	factory, ok := storages[name]
+	storagesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("storage: unknown storage %q", name)
+	}
+
+	return factory(db), nil
+}
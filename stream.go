@@ -0,0 +1,41 @@
+package main
+
+import "context"
+
+// ParcelIterator обходит посылки по одной, не собирая их в срез,
+// поэтому память не растёт с числом посылок клиента.
+type ParcelIterator interface {
+	// IterByClient вызывает fn для каждой посылки клиента по возрастанию
+	// номера и останавливается на первой ошибке fn, возвращая её
+	IterByClient(ctx context.Context, client int, fn func(Parcel) error) error
+}
+
+var (
+	_ ParcelIterator = ParcelStore{}
+	_ ParcelIterator = (*MemoryParcelStore)(nil)
+)
+
+// IterByClient читает посылки клиента из БД по одной строке. Пока идёт
+// обход, курсор держит соединение из пула.
+func (s ParcelStore) IterByClient(ctx context.Context, client int, fn func(Parcel) error) error {
+	return s.eachParcel(ctx, Filter{Client: client}, fn)
+}
+
+// IterByClient вызывает fn для снимка посылок клиента, поэтому fn может
+// менять хранилище во время обхода.
+func (s *MemoryParcelStore) IterByClient(ctx context.Context, client int, fn func(Parcel) error) error {
+	parcels, err := s.filtered(ctx, Filter{Client: client})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range parcels {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
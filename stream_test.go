@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkIterByClient проверяет построчный обход посылок клиента в хранилище store
+func checkIterByClient(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	iter := store.(ParcelIterator)
+	parcel := getTestParcel()
+
+	// add
+	var numbers []int
+	for i := 0; i < 3; i++ {
+		parcel.TrackCode = ""
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	other := getTestParcel()
+	other.Client++
+	_, err := store.Add(ctx, other)
+	require.NoError(t, err)
+
+	// check
+	var got []int
+	err = iter.IterByClient(ctx, parcel.Client, func(p Parcel) error {
+		require.Equal(t, parcel.Client, p.Client)
+		got = append(got, p.Number)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, numbers, got)
+
+	// ошибка fn останавливает обход и возвращается как есть
+	errStop := errors.New("stop")
+	got = nil
+	err = iter.IterByClient(ctx, parcel.Client, func(p Parcel) error {
+		got = append(got, p.Number)
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, numbers[:2], got)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = iter.IterByClient(cancelled, parcel.Client, func(Parcel) error { return nil })
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestIterByClient проверяет обход посылок клиента в SQLite
+func TestIterByClient(t *testing.T) {
+	checkIterByClient(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryIterByClient проверяет обход посылок клиента в памяти
+func TestMemoryIterByClient(t *testing.T) {
+	checkIterByClient(t, NewMemoryParcelStore())
+}
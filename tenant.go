@@ -0,0 +1,48 @@
+package main
+
+import "context"
+
+// tenantKey — ключ арендатора в контексте запроса.
+type tenantKey struct{}
+
+// WithTenant возвращает контекст запросов от имени арендатора tenant —
+// магазина, для которого работает трекер. ParcelStore в таком контексте
+// видит и меняет только посылки этого арендатора, а новые посылки
+// записывает на него. Пустой tenant снимает ограничение: так работают
+// CLI и ключи без арендатора.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext возвращает арендатора запроса или пустую строку.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// tenantCond возвращает условие « AND tenant_id = ?» для арендатора
+// из ctx и его аргумент или пустое условие без арендатора.
+func tenantCond(ctx context.Context) (string, []any) {
+	return tenantCondOn(ctx, "tenant_id")
+}
+
+// tenantCondOn — tenantCond для колонки column, например "p.tenant_id"
+// в запросах с JOIN.
+func tenantCondOn(ctx context.Context, column string) (string, []any) {
+	tenant := TenantFromContext(ctx)
+	if tenant == "" {
+		return "", nil
+	}
+	return " AND " + column + " = ?", []any{tenant}
+}
+
+// scopedWhere проверяет filter и возвращает его условие, ограниченное
+// арендатором из ctx.
+func (s ParcelStore) scopedWhere(ctx context.Context, filter Filter) (string, []any, error) {
+	if err := s.checkFilter(filter); err != nil {
+		return "", nil, err
+	}
+	where, args := filter.where(s.dialect)
+	cond, tenantArgs := tenantCond(ctx)
+	return where + cond, append(args, tenantArgs...), nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantIsolation проверяет, что арендатор не видит и не меняет чужие посылки
+func TestTenantIsolation(t *testing.T) {
+	// prepare
+	store := NewParcelStore(openTempDB(t))
+	shopA := WithTenant(context.Background(), "shop-a")
+	shopB := WithTenant(context.Background(), "shop-b")
+	parcel := getTestParcel()
+
+	// add
+	id, err := store.Add(shopA, parcel)
+	require.NoError(t, err)
+	stored, err := store.Get(shopA, id)
+	require.NoError(t, err)
+
+	// check
+	_, err = store.Get(shopB, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	_, err = store.GetByTrackCode(shopB, stored.TrackCode)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	_, err = store.GetHistory(shopB, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	require.ErrorIs(t, store.SetStatus(shopB, id, ParcelStatusSent), ErrParcelNotFound)
+	require.ErrorIs(t, store.SetRecipientAddress(shopB, id, "чужой адрес"), ErrParcelNotFound)
+	require.ErrorIs(t, store.Delete(shopB, id), ErrParcelNotFound)
+
+	page, err := store.ListByClient(shopB, parcel.Client, ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+	require.Empty(t, page.Parcels)
+	all, err := store.ListAll(shopB, Filter{}, ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, all.Total)
+	cursor, err := store.List(shopB, Filter{Client: parcel.Client}, 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, cursor.Parcels)
+
+	// посылка осталась нетронутой, а оператор без арендатора видит всех
+	got, err := store.Get(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, got.Status)
+	require.Equal(t, parcel.RecipientAddress, got.RecipientAddress)
+
+	parcel.TrackCode = ""
+	_, err = store.Add(shopB, parcel)
+	require.NoError(t, err)
+	page, err = store.ListByClient(context.Background(), parcel.Client, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	page, err = store.ListByClient(shopA, parcel.Client, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.NoError(t, store.SetStatus(shopA, id, ParcelStatusSent))
+}
+
+// tenantStorage — хранилище посылок со счётчиками, поиском, отчётами
+// и пакетной сменой статуса.
+type tenantStorage interface {
+	ParcelStorage
+	ParcelCounter
+	AddressSearcher
+	ParcelReporter
+	SetStatusBatch(ctx context.Context, numbers []int, status Status) ([]StatusResult, error)
+}
+
+// checkTenantScope проверяет, что арендатор не считает, не находит
+// и не меняет пачкой чужие посылки на хранилище store
+func checkTenantScope(t *testing.T, store tenantStorage) {
+	// prepare
+	shopA := WithTenant(context.Background(), "shop-a")
+	shopB := WithTenant(context.Background(), "shop-b")
+	parcel := getTestParcel()
+	id, err := store.Add(shopA, parcel)
+	require.NoError(t, err)
+	stored, err := store.Get(shopA, id)
+	require.NoError(t, err)
+
+	// check
+	_, err = store.Get(shopB, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	_, err = store.GetByTrackCode(shopB, stored.TrackCode)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	page, err := store.ListAll(shopB, Filter{}, ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+
+	n, err := store.CountByClient(shopB, parcel.Client)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = store.CountByStatus(shopB, ParcelStatusRegistered)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	counts, err := store.CountsByStatus(shopB)
+	require.NoError(t, err)
+	require.Empty(t, counts)
+	n, err = store.CountByClient(shopA, parcel.Client)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	page, err = store.SearchByAddress(shopB, parcel.RecipientAddress, ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+	page, err = store.SearchByAddress(shopA, parcel.RecipientAddress, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+
+	from, to := stored.CreatedAt.Add(-time.Hour), stored.CreatedAt.Add(time.Hour)
+	report, err := store.Stats(shopB, from, to)
+	require.NoError(t, err)
+	require.Zero(t, report.Created)
+	report, err = store.Stats(shopA, from, to)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Created)
+
+	results, err := store.SetStatusBatch(shopB, []int{id}, ParcelStatusSent)
+	require.NoError(t, err)
+	require.ErrorIs(t, results[0].Err, ErrParcelNotFound)
+	got, err := store.Get(shopA, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, got.Status)
+
+	results, err = store.SetStatusBatch(shopA, []int{id}, ParcelStatusSent)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+}
+
+// TestTenantScope проверяет счётчики, поиск, отчёты и пакетную смену
+// статуса в пределах арендатора в SQLite и в памяти
+func TestTenantScope(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		checkTenantScope(t, NewParcelStore(openTempDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		checkTenantScope(t, NewMemoryParcelStore())
+	})
+}
+
+// TestTenantCourierAndGeo проверяет, что арендатор не назначает чужие
+// посылки курьеру и не находит их по времени создания и координатам
+func TestTenantCourierAndGeo(t *testing.T) {
+	// prepare
+	store := NewParcelStore(openTempDB(t))
+	shopA := WithTenant(context.Background(), "shop-a")
+	shopB := WithTenant(context.Background(), "shop-b")
+	id, err := store.Add(shopA, getTestParcel())
+	require.NoError(t, err)
+	stored, err := store.Get(shopA, id)
+	require.NoError(t, err)
+	courier, err := store.AddCourier(context.Background(), Courier{Name: "Алексей"})
+	require.NoError(t, err)
+	point := Coordinates{Lat: 55.75, Lon: 37.62}
+
+	// check
+	require.ErrorIs(t, store.SetCoordinates(shopB, id, point), ErrParcelNotFound)
+	require.NoError(t, store.SetCoordinates(shopA, id, point))
+	_, err = store.GetCoordinates(shopB, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+	near, err := store.GetNear(shopB, point.Lat, point.Lon, 1000)
+	require.NoError(t, err)
+	require.Empty(t, near)
+	near, err = store.GetNear(shopA, point.Lat, point.Lon, 1000)
+	require.NoError(t, err)
+	require.Len(t, near, 1)
+
+	created, err := store.ListAll(shopB, Filter{From: stored.CreatedAt, To: stored.CreatedAt.Add(time.Hour)}, ListOptions{})
+	require.NoError(t, err)
+	require.Zero(t, created.Total)
+
+	require.ErrorIs(t, store.AssignCourier(shopB, id, courier), ErrParcelNotFound)
+	require.NoError(t, store.AssignCourier(shopA, id, courier))
+	queue, err := store.GetByCourier(shopB, courier)
+	require.NoError(t, err)
+	require.Empty(t, queue)
+	require.ErrorIs(t, store.CompleteDelivery(shopB, courier, id), ErrParcelNotFound)
+	queue, err = store.GetByCourier(shopA, courier)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+}
+
+// TestTenantFromAPIKey проверяет, что REST API работает от имени арендатора ключа
+func TestTenantFromAPIKey(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, keyA, err := store.IssueAPIKey(ctx, APIKey{Name: "shop a", Tenant: "shop-a", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+	_, keyB, err := store.IssueAPIKey(ctx, APIKey{Name: "shop b", Tenant: "shop-b", Scopes: []string{ScopeWrite}})
+	require.NoError(t, err)
+
+	h := NewAuthenticator(store).Middleware(NewHTTPServer(NewParcelService(store)))
+	do := func(method, target, body, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// add
+	rec := do(http.MethodPost, "/parcels", `{"client": 1000, "recipient_address": "test"}`, keyA)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	page, err := store.ListByClient(WithTenant(ctx, "shop-a"), 1000, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Parcels, 1)
+	target := "/parcels/" + strconv.Itoa(page.Parcels[0].Number)
+
+	// check
+	require.Equal(t, http.StatusOK, do(http.MethodGet, target, "", keyA).Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, target, "", keyB).Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodDelete, target, "", keyB).Code)
+
+	keys, err := store.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "shop-a", keys[0].Tenant)
+}
@@ -0,0 +1,60 @@
+// Package testutil помогает писать unit-тесты хранилища посылок без
+// настоящей СУБД: MockQuerier выполняет запросы ParcelStore через
+// go-sqlmock и сверяет их текст и аргументы с ожиданиями теста.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// MockQuerier — Querier хранилища поверх go-sqlmock. Ожидания задаются
+// методами Sqlmock, например ExpectQuery и ExpectExec; текст запроса
+// сравнивается с ожидаемым целиком, а не как регулярное выражение.
+//
+// MockQuerier не *sql.DB, поэтому хранилище поверх него работает без
+// транзакций. Чтобы проверить запросы внутри транзакции, создайте
+// хранилище поверх DB и задайте ExpectBegin и ExpectCommit.
+type MockQuerier struct {
+	sqlmock.Sqlmock
+	db *sql.DB
+}
+
+// NewMockQuerier создаёт MockQuerier. В конце теста он закрывается
+// и проверяет, что все ожидания выполнены.
+func NewMockQuerier(t testing.TB) *MockQuerier {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("open sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	return &MockQuerier{Sqlmock: mock, db: db}
+}
+
+// DB возвращает *sql.DB, через который MockQuerier выполняет запросы.
+func (m *MockQuerier) DB() *sql.DB {
+	return m.db
+}
+
+func (m *MockQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return m.db.ExecContext(ctx, query, args...)
+}
+
+func (m *MockQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, query, args...)
+}
+
+func (m *MockQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return m.db.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// mysqlTimeLayout — формат DATETIME, в котором драйвер MySQL без parseTime
+// возвращает значения.
+const mysqlTimeLayout = "2006-01-02 15:04:05.999999"
+
+// WithClock возвращает копию хранилища, которое берёт created_at
+// и updated_at посылок из now. По умолчанию это time.Now; свои часы нужны
+// в основном тестам.
+func (s ParcelStore) WithClock(now func() time.Time) ParcelStore {
+	s.now = now
+	return s
+}
+
+// timestamp возвращает значение для created_at и updated_at. Время
+// округляется до секунды: в SQLite отметки хранятся строками RFC3339,
+// и сравнение строк совпадает с хронологическим только при одинаковой точности.
+func timestamp(now func() time.Time) time.Time {
+	return now().UTC().Truncate(time.Second)
+}
+
+// timeArg возвращает аргумент запроса для колонки с отметкой времени:
+// в SQLite это строка RFC3339, в остальных СУБД колонка имеет тип
+// даты, и драйвер передаёт time.Time сам.
+func (d dialect) timeArg(t time.Time) any {
+	if d.textTime {
+		return formatTime(t)
+	}
+	return t.UTC()
+}
+
+// timestampArg возвращает аргумент запроса с текущей отметкой для updated_at.
+func (s ParcelStore) timestampArg() any {
+	return s.dialect.timeArg(timestamp(s.now))
+}
+
+// scanTime читает отметку времени в t независимо от того, вернул драйвер
+// time.Time или строку. NULL читается как нулевое время.
+type scanTime struct {
+	t *time.Time
+}
+
+func (s scanTime) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*s.t = time.Time{}
+		return nil
+	case time.Time:
+		*s.t = v.UTC()
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("scan time: unsupported type %T", src)
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, mysqlTimeLayout} {
+		if t, err := time.Parse(layout, text); err == nil {
+			*s.t = t.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("scan time: invalid value %q", text)
+}
+
+// SetClock задаёт источник времени для created_at и updated_at.
+func (s *MemoryParcelStore) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = now
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreatedAtSetByStore проверяет, что время создания задаёт хранилище,
+// а не вызывающий код
+func TestCreatedAtSetByStore(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	parcel := getTestParcel()
+	parcel.CreatedAt = time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// add
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	// check
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), stored.CreatedAt, time.Minute)
+	require.Equal(t, time.UTC, stored.CreatedAt.Location())
+	require.Zero(t, stored.CreatedAt.Nanosecond())
+}
+
+// TestScanTime проверяет чтение отметок времени в форматах разных драйверов
+func TestScanTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	for _, src := range []any{
+		want.In(moscow),
+		"2024-01-02T03:04:05Z",
+		"2024-01-02T06:04:05+03:00",
+		[]byte("2024-01-02 03:04:05"),
+		[]byte("2024-01-02 03:04:05.000000"),
+	} {
+		var got time.Time
+		require.NoError(t, scanTime{&got}.Scan(src), src)
+		require.Equal(t, want, got, src)
+	}
+
+	var got time.Time
+	require.Error(t, scanTime{&got}.Scan("yesterday"))
+	require.Error(t, scanTime{&got}.Scan(int64(42)))
+}
+
+// checkUpdatedAt проверяет, что изменения посылки сдвигают UpdatedAt,
+// а CreatedAt остаётся прежним. Часы store должны возвращать *now
+func checkUpdatedAt(t *testing.T, store ParcelStorage, now *time.Time) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	*now = testCreatedAt
+
+	client := randRange.Intn(10_000_000)
+	var numbers []int
+	for i := 0; i < 2; i++ {
+		parcel := getTestParcel()
+		parcel.Client = client
+		id, err := store.Add(ctx, parcel)
+		require.NoError(t, err)
+		numbers = append(numbers, id)
+	}
+	first, second := numbers[0], numbers[1]
+
+	p, err := store.Get(ctx, first)
+	require.NoError(t, err)
+	require.Equal(t, testCreatedAt, p.UpdatedAt)
+
+	// set address, set status, update
+	changes := []func() error{
+		func() error { return store.SetRecipientAddress(ctx, first, "new test address") },
+		func() error { return store.SetStatus(ctx, first, ParcelStatusSent) },
+		func() error {
+			p, err := store.Get(ctx, first)
+			require.NoError(t, err)
+			p.Status = ParcelStatusDelivered
+			return store.Update(ctx, p)
+		},
+	}
+	for _, change := range changes {
+		*now = now.Add(time.Minute)
+		require.NoError(t, change())
+
+		p, err := store.Get(ctx, first)
+		require.NoError(t, err)
+		require.Equal(t, *now, p.UpdatedAt)
+		require.Equal(t, testCreatedAt, p.CreatedAt)
+	}
+
+	// check
+	page, err := store.ListByClient(ctx, client, ListOptions{SortBy: SortByUpdatedAt, Desc: true})
+	require.NoError(t, err)
+	require.Len(t, page.Parcels, 2)
+	require.Equal(t, first, page.Parcels[0].Number)
+	require.Equal(t, second, page.Parcels[1].Number)
+
+	// время в истории статусов тоже берётся из часов хранилища
+	*now = now.Add(time.Minute)
+	require.NoError(t, store.Cancel(ctx, second, CancelDuplicate))
+	for number, want := range map[int][]time.Time{
+		first:  {testCreatedAt.Add(2 * time.Minute), testCreatedAt.Add(3 * time.Minute)},
+		second: {*now},
+	} {
+		history, err := store.GetHistory(ctx, number)
+		require.NoError(t, err)
+		require.Len(t, history, len(want))
+		for i, h := range history {
+			require.Equal(t, formatTime(want[i]), h.ChangedAt)
+		}
+	}
+}
+
+// TestUpdatedAt проверяет updated_at в SQLite
+func TestUpdatedAt(t *testing.T) {
+	var now time.Time
+	store := NewParcelStore(openTempDB(t)).WithClock(func() time.Time { return now })
+	checkUpdatedAt(t, store, &now)
+}
+
+// TestMemoryUpdatedAt проверяет updated_at в памяти
+func TestMemoryUpdatedAt(t *testing.T) {
+	var now time.Time
+	store := NewMemoryParcelStore()
+	store.SetClock(func() time.Time { return now })
+	checkUpdatedAt(t, store, &now)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Yandex-Practicum/go-db-sql-final"
+
+// TracingStorage оборачивает каждую операцию хранилища в OpenTelemetry-спан
+// с атрибутами parcel.number и client.id. Спаны запросов (db.statement)
+// создаёт ParcelStore.WithTracer, они становятся дочерними через ctx.
+type TracingStorage struct {
+	next   ParcelStorage
+	tracer trace.Tracer
+}
+
+var _ ParcelStorage = TracingStorage{}
+
+// NewTracingStorage оборачивает хранилище трассировкой.
+// Если tp равен nil, используется глобальный otel.GetTracerProvider().
+func NewTracingStorage(next ParcelStorage, tp trace.TracerProvider) TracingStorage {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return TracingStorage{next: next, tracer: tp.Tracer(tracerName)}
+}
+
+func (s TracingStorage) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "ParcelStorage."+op, trace.WithAttributes(attrs...))
+}
+
+// endSpan завершает спан, помечая ошибкой только сбои, а не доменные отказы.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !isDomainError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func numberAttr(number int) attribute.KeyValue { return attribute.Int("parcel.number", number) }
+
+func clientAttr(client int) attribute.KeyValue { return attribute.Int("client.id", client) }
+
+func (s TracingStorage) Add(ctx context.Context, p Parcel) (int, error) {
+	ctx, span := s.start(ctx, "Add", clientAttr(p.Client))
+	number, err := s.next.Add(ctx, p)
+	if err == nil {
+		span.SetAttributes(numberAttr(number))
+	}
+	endSpan(span, err)
+	return number, err
+}
+
+func (s TracingStorage) Get(ctx context.Context, number int) (Parcel, error) {
+	ctx, span := s.start(ctx, "Get", numberAttr(number))
+	p, err := s.next.Get(ctx, number)
+	endSpan(span, err)
+	return p, err
+}
+
+func (s TracingStorage) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	ctx, span := s.start(ctx, "GetByTrackCode", attribute.String("parcel.track_code", code))
+	p, err := s.next.GetByTrackCode(ctx, code)
+	if err == nil {
+		span.SetAttributes(numberAttr(p.Number))
+	}
+	endSpan(span, err)
+	return p, err
+}
+
+func (s TracingStorage) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	ctx, span := s.start(ctx, "GetByUUID", attribute.String("parcel.uuid", id))
+	p, err := s.next.GetByUUID(ctx, id)
+	if err == nil {
+		span.SetAttributes(numberAttr(p.Number))
+	}
+	endSpan(span, err)
+	return p, err
+}
+
+func (s TracingStorage) GetByClient(ctx context.Context, client int) ([]Parcel, error) {
+	ctx, span := s.start(ctx, "GetByClient", clientAttr(client))
+	parcels, err := s.next.GetByClient(ctx, client)
+	endSpan(span, err)
+	return parcels, err
+}
+
+func (s TracingStorage) ListByClient(ctx context.Context, client int, opts ListOptions) (ParcelPage, error) {
+	ctx, span := s.start(ctx, "ListByClient", clientAttr(client))
+	page, err := s.next.ListByClient(ctx, client, opts)
+	endSpan(span, err)
+	return page, err
+}
+
+func (s TracingStorage) List(ctx context.Context, filter Filter, afterNumber, limit int) (CursorPage, error) {
+	ctx, span := s.start(ctx, "List", attribute.Int("parcel.after", afterNumber))
+	page, err := s.next.List(ctx, filter, afterNumber, limit)
+	endSpan(span, err)
+	return page, err
+}
+
+func (s TracingStorage) ListAll(ctx context.Context, filter Filter, opts ListOptions) (ParcelPage, error) {
+	ctx, span := s.start(ctx, "ListAll")
+	page, err := s.next.ListAll(ctx, filter, opts)
+	endSpan(span, err)
+	return page, err
+}
+
+func (s TracingStorage) SetStatus(ctx context.Context, number int, status Status) error {
+	ctx, span := s.start(ctx, "SetStatus", numberAttr(number), attribute.String("parcel.status", string(status)))
+	err := s.next.SetStatus(ctx, number, status)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) SetSenderAddress(ctx context.Context, number int, address string) error {
+	ctx, span := s.start(ctx, "SetSenderAddress", numberAttr(number))
+	err := s.next.SetSenderAddress(ctx, number, address)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) SetRecipientAddress(ctx context.Context, number int, address string) error {
+	ctx, span := s.start(ctx, "SetRecipientAddress", numberAttr(number))
+	err := s.next.SetRecipientAddress(ctx, number, address)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) SetETA(ctx context.Context, number int, eta time.Time) error {
+	ctx, span := s.start(ctx, "SetETA", numberAttr(number))
+	err := s.next.SetETA(ctx, number, eta)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) MarkPaid(ctx context.Context, number int, txRef string) error {
+	ctx, span := s.start(ctx, "MarkPaid", numberAttr(number))
+	err := s.next.MarkPaid(ctx, number, txRef)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) Update(ctx context.Context, p Parcel) error {
+	ctx, span := s.start(ctx, "Update", numberAttr(p.Number), attribute.Int("parcel.version", p.Version))
+	err := s.next.Update(ctx, p)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) Cancel(ctx context.Context, number int, reason CancelReason) error {
+	ctx, span := s.start(ctx, "Cancel", numberAttr(number))
+	err := s.next.Cancel(ctx, number, reason)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) Deliver(ctx context.Context, number int, sig Signature) error {
+	ctx, span := s.start(ctx, "Deliver", numberAttr(number))
+	err := s.next.Deliver(ctx, number, sig)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) Delete(ctx context.Context, number int) error {
+	ctx, span := s.start(ctx, "Delete", numberAttr(number))
+	err := s.next.Delete(ctx, number)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) HardDelete(ctx context.Context, number int) error {
+	ctx, span := s.start(ctx, "HardDelete", numberAttr(number))
+	err := s.next.HardDelete(ctx, number)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) Restore(ctx context.Context, number int) error {
+	ctx, span := s.start(ctx, "Restore", numberAttr(number))
+	err := s.next.Restore(ctx, number)
+	endSpan(span, err)
+	return err
+}
+
+func (s TracingStorage) GetHistory(ctx context.Context, number int) ([]StatusChange, error) {
+	ctx, span := s.start(ctx, "GetHistory", numberAttr(number))
+	history, err := s.next.GetHistory(ctx, number)
+	endSpan(span, err)
+	return history, err
+}
+
+func (s TracingStorage) AddEvent(ctx context.Context, number int, code, description string, occurredAt time.Time) (int, error) {
+	ctx, span := s.start(ctx, "AddEvent", numberAttr(number), attribute.String("event.code", code))
+	id, err := s.next.AddEvent(ctx, number, code, description, occurredAt)
+	endSpan(span, err)
+	return id, err
+}
+
+func (s TracingStorage) GetEvents(ctx context.Context, number int) ([]TrackingEvent, error) {
+	ctx, span := s.start(ctx, "GetEvents", numberAttr(number))
+	events, err := s.next.GetEvents(ctx, number)
+	endSpan(span, err)
+	return events, err
+}
+
+// WithTracer возвращает копию хранилища, которая создаёт спан на каждый
+// SQL-запрос с атрибутами db.system и db.statement.
+func (s ParcelStore) WithTracer(tp trace.TracerProvider) ParcelStore {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	s.tracer = tp.Tracer(tracerName)
+	s.q = s.wrap(unwrapQuerier(s.q))
+	s.r = s.wrapReplica()
+	return s
+}
+
+// tracingQuerier создаёт спан на каждый запрос к q.
+type tracingQuerier struct {
+	q      querier
+	tracer trace.Tracer
+	system string
+}
+
+func (t tracingQuerier) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	name, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return t.tracer.Start(ctx, strings.ToUpper(name),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", t.system),
+			attribute.String("db.statement", query),
+		))
+}
+
+func (t tracingQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	res, err := t.q.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return res, err
+}
+
+func (t tracingQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (t tracingQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	row := t.q.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+func (t tracingQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := t.start(ctx, query)
+	stmt, err := t.q.PrepareContext(ctx, query)
+	endSpan(span, err)
+	return stmt, err
+}
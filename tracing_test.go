@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracingStorage проверяет спаны операций и вложенные спаны запросов
+func TestTracingStorage(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+	ctx := context.Background()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	store := NewTracingStorage(NewParcelStore(db).WithTracer(tp), tp)
+
+	// operations
+	parcel := getTestParcel()
+	id, err := store.Add(ctx, parcel)
+	require.NoError(t, err)
+
+	err = store.SetStatus(ctx, id, ParcelStatusSent)
+	require.NoError(t, err)
+
+	// check
+	spans := recorder.Ended()
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		if _, ok := byName[s.Name()]; !ok {
+			byName[s.Name()] = s
+		}
+	}
+
+	add := byName["ParcelStorage.Add"]
+	require.NotNil(t, add)
+	require.Contains(t, add.Attributes(), attribute.Int("client.id", parcel.Client))
+	require.Contains(t, add.Attributes(), attribute.Int("parcel.number", id))
+
+	insert := byName["INSERT"]
+	require.NotNil(t, insert)
+	require.Equal(t, add.SpanContext().SpanID(), insert.Parent().SpanID())
+	require.Contains(t, insert.Attributes(), attribute.String("db.system", "sqlite"))
+
+	// запросы внутри транзакции тоже трассируются
+	setStatus := byName["ParcelStorage.SetStatus"]
+	require.NotNil(t, setStatus)
+
+	var children int
+	for _, s := range spans {
+		if s.Parent().SpanID() == setStatus.SpanContext().SpanID() {
+			children++
+		}
+	}
+	require.GreaterOrEqual(t, children, 2)
+}
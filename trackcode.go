@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTrackCode возвращается для трек-кода неверного формата
+// или с неверной контрольной суммой.
+var ErrInvalidTrackCode = errors.New("invalid track code")
+
+// crockford — алфавит Crockford Base32: без I, L, O и U,
+// которые легко спутать с другими символами.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// trackCodeLen — длина трек-кода: 10 случайных символов (50 бит) и контрольный.
+const trackCodeLen = 11
+
+// NewTrackCode возвращает случайный трек-код для клиентов, например
+// "7K3QZ9M1XDB". Последний символ — контрольная сумма Луна по модулю 32,
+// она ловит опечатки в одном символе и перестановку соседних символов.
+// Код не выводится из номера посылки, поэтому его нельзя подобрать перебором.
+func NewTrackCode() string {
+	buf := make([]byte, trackCodeLen-1)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	code := make([]byte, 0, trackCodeLen)
+	for _, b := range buf {
+		code = append(code, crockford[b%32])
+	}
+	return string(append(code, checkSymbol(code)))
+}
+
+// NormalizeTrackCode приводит введённый пользователем код к каноническому
+// виду (верхний регистр, без дефисов и пробелов, I и L — 1, O — 0)
+// и проверяет контрольный символ.
+func NormalizeTrackCode(code string) (string, error) {
+	code = strings.NewReplacer("-", "", " ", "", "I", "1", "L", "1", "O", "0").
+		Replace(strings.ToUpper(code))
+	if len(code) != trackCodeLen {
+		return "", ErrInvalidTrackCode
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(crockford, code[i]) < 0 {
+			return "", ErrInvalidTrackCode
+		}
+	}
+	if checkSymbol([]byte(code[:trackCodeLen-1])) != code[trackCodeLen-1] {
+		return "", ErrInvalidTrackCode
+	}
+
+	return code, nil
+}
+
+// checkSymbol считает контрольный символ алгоритмом Луна по модулю 32.
+func checkSymbol(code []byte) byte {
+	const n = len(crockford)
+
+	sum, factor := 0, 2
+	for i := len(code) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(crockford, code[i])
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+
+	return crockford[(n-sum%n)%n]
+}
+
+// GetByTrackCode возвращает посылку по трек-коду.
+func (s ParcelStore) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	code, err := NormalizeTrackCode(code)
+	if err != nil {
+		return Parcel{}, err
+	}
+
+	cond, args := tenantCond(ctx)
+	row := s.reader(0).QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT "+parcelColumns+" FROM parcel WHERE track_code = ? AND deleted_at IS NULL"+cond),
+		append([]any{code}, args...)...)
+
+	p, err := s.scanParcel(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrParcelNotFound
+	}
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// GetByTrackCode возвращает посылку по трек-коду.
+func (s *MemoryParcelStore) GetByTrackCode(ctx context.Context, code string) (Parcel, error) {
+	if err := ctx.Err(); err != nil {
+		return Parcel{}, err
+	}
+	code, err := NormalizeTrackCode(code)
+	if err != nil {
+		return Parcel{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.parcels {
+		if p.TrackCode == code && s.visible(ctx, p.Number) {
+			return p, nil
+		}
+	}
+
+	return Parcel{}, ErrParcelNotFound
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrackCode проверяет формат, нормализацию и контрольный символ трек-кода
+func TestTrackCode(t *testing.T) {
+	code := NewTrackCode()
+	require.Len(t, code, trackCodeLen)
+	require.NotEqual(t, code, NewTrackCode())
+
+	normalized, err := NormalizeTrackCode(code)
+	require.NoError(t, err)
+	require.Equal(t, code, normalized)
+
+	// регистр, дефисы и пробелы не важны
+	typed := strings.ToLower(code[:4] + "-" + code[4:8] + " " + code[8:])
+	normalized, err = NormalizeTrackCode(typed)
+	require.NoError(t, err)
+	require.Equal(t, code, normalized)
+
+	// опечатка в одном символе ловится контрольной суммой
+	i := strings.IndexByte(crockford, code[0])
+	typo := string(crockford[(i+1)%len(crockford)]) + code[1:]
+	_, err = NormalizeTrackCode(typo)
+	require.ErrorIs(t, err, ErrInvalidTrackCode)
+
+	_, err = NormalizeTrackCode("123")
+	require.ErrorIs(t, err, ErrInvalidTrackCode)
+	_, err = NormalizeTrackCode("UUUUUUUUUUU")
+	require.ErrorIs(t, err, ErrInvalidTrackCode)
+}
+
+// checkGetByTrackCode проверяет поиск посылки по трек-коду в хранилище store
+func checkGetByTrackCode(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, p.TrackCode, trackCodeLen)
+
+	// get
+	found, err := store.GetByTrackCode(ctx, strings.ToLower(p.TrackCode))
+	require.NoError(t, err)
+	require.Equal(t, p, found)
+
+	// check
+	_, err = store.GetByTrackCode(ctx, NewTrackCode())
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	_, err = store.GetByTrackCode(ctx, "not a code")
+	require.ErrorIs(t, err, ErrInvalidTrackCode)
+
+	// удалённую посылку по коду не найти
+	require.NoError(t, store.Delete(ctx, id))
+	_, err = store.GetByTrackCode(ctx, p.TrackCode)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestGetByTrackCode проверяет поиск по трек-коду в SQLite
+func TestGetByTrackCode(t *testing.T) {
+	checkGetByTrackCode(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryGetByTrackCode проверяет поиск по трек-коду в памяти
+func TestMemoryGetByTrackCode(t *testing.T) {
+	checkGetByTrackCode(t, NewMemoryParcelStore())
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TrackingCursor — место в ленте отслеживания посылки: сколько смен
+// статуса уже получено и идентификатор последнего полученного события
+// отслеживания. Строковый вид «3-17» служит id записей Server-Sent Events.
+type TrackingCursor struct {
+	StatusChanges int
+	LastEventID   int
+}
+
+func (c TrackingCursor) String() string {
+	return fmt.Sprintf("%d-%d", c.StatusChanges, c.LastEventID)
+}
+
+// ParseTrackingCursor разбирает курсор из TrackingCursor.String, например
+// из заголовка Last-Event-ID. Пустая строка означает начало ленты.
+func ParseTrackingCursor(s string) (TrackingCursor, error) {
+	var c TrackingCursor
+	if s == "" {
+		return c, nil
+	}
+	if _, err := fmt.Sscanf(s, "%d-%d", &c.StatusChanges, &c.LastEventID); err != nil ||
+		c.String() != s || c.StatusChanges < 0 || c.LastEventID < 0 {
+		return TrackingCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// TrackingUpdate — запись ленты отслеживания: смена статуса или событие
+// отслеживания; заполнено ровно одно из полей. Cursor указывает на место
+// сразу после записи.
+type TrackingUpdate struct {
+	Cursor       TrackingCursor
+	StatusChange *StatusChange
+	Event        *TrackingEvent
+}
+
+// StreamTracking возвращает канал ленты отслеживания посылки number:
+// сначала записи после курсора after, затем новые смены статуса и события
+// по мере появления. Как и Watch, сервис перечитывает ленту раз
+// в WithWatchInterval. Канал закрывается при отмене ctx и удалении
+// посылки; для отсутствующей посылки сразу возвращается ErrParcelNotFound.
+func (s ParcelService) StreamTracking(ctx context.Context, number int, after TrackingCursor) (<-chan TrackingUpdate, error) {
+	pending, cursor, err := s.trackingSince(ctx, number, after)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := s.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	updates := make(chan TrackingUpdate)
+	go func() {
+		defer close(updates)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for _, u := range pending {
+				select {
+				case updates <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			pending, cursor, err = s.trackingSince(ctx, number, cursor)
+			switch {
+			case errors.Is(err, ErrParcelNotFound):
+				return
+			case err != nil:
+				if ctx.Err() == nil {
+					s.logger.WarnContext(ctx, "stream tracking", "number", number, "error", err)
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// trackingSince возвращает записи ленты посылки number после курсора after
+// и курсор после последней из них. Смены статуса идут раньше событий:
+// у них разные часы, и общий порядок всё равно приблизителен.
+func (s ParcelService) trackingSince(ctx context.Context, number int, after TrackingCursor) ([]TrackingUpdate, TrackingCursor, error) {
+	history, err := s.store.GetHistory(ctx, number)
+	if err != nil {
+		return nil, after, err
+	}
+	events, err := s.store.GetEvents(ctx, number)
+	if err != nil {
+		return nil, after, err
+	}
+
+	cursor := after
+	var res []TrackingUpdate
+	for i := after.StatusChanges; i < len(history); i++ {
+		cursor.StatusChanges = i + 1
+		res = append(res, TrackingUpdate{Cursor: cursor, StatusChange: &history[i]})
+	}
+
+	// события приходят по времени наступления, а курсор растёт по id
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	for i := range events {
+		if events[i].ID <= after.LastEventID {
+			continue
+		}
+		cursor.LastEventID = events[i].ID
+		res = append(res, TrackingUpdate{Cursor: cursor, Event: &events[i]})
+	}
+
+	return res, cursor, nil
+}
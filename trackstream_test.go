@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTrackingCursor проверяет разбор курсора ленты отслеживания
+func TestParseTrackingCursor(t *testing.T) {
+	c, err := ParseTrackingCursor("3-17")
+	require.NoError(t, err)
+	require.Equal(t, TrackingCursor{StatusChanges: 3, LastEventID: 17}, c)
+	require.Equal(t, "3-17", c.String())
+
+	c, err = ParseTrackingCursor("")
+	require.NoError(t, err)
+	require.Equal(t, TrackingCursor{}, c)
+
+	for _, bad := range []string{"3", "3-x", "-1-2", "3-17-1", " 3-17"} {
+		_, err := ParseTrackingCursor(bad)
+		require.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
+
+// nextUpdate ждёт запись ленты из updates не дольше секунды
+func nextUpdate(t *testing.T, updates <-chan TrackingUpdate) (TrackingUpdate, bool) {
+	t.Helper()
+
+	select {
+	case u, ok := <-updates:
+		return u, ok
+	case <-time.After(time.Second):
+		t.Fatal("no update from StreamTracking")
+		return TrackingUpdate{}, false
+	}
+}
+
+// checkStreamTracking проверяет ленту отслеживания и продолжение
+// с курсора поверх хранилища store
+func checkStreamTracking(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewParcelService(store).WithWatchInterval(10 * time.Millisecond)
+
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+	eventID, err := store.AddEvent(ctx, p.Number, "sorting", "Сортировочный центр", time.Now())
+	require.NoError(t, err)
+
+	_, err = service.StreamTracking(ctx, p.Number+1, TrackingCursor{})
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	// check
+	updates, err := service.StreamTracking(ctx, p.Number, TrackingCursor{})
+	require.NoError(t, err)
+
+	u, _ := nextUpdate(t, updates)
+	require.NotNil(t, u.StatusChange)
+	require.Equal(t, ParcelStatusSent, u.StatusChange.NewStatus)
+	require.Equal(t, TrackingCursor{StatusChanges: 1}, u.Cursor)
+
+	u, _ = nextUpdate(t, updates)
+	require.NotNil(t, u.Event)
+	require.Equal(t, "sorting", u.Event.Code)
+	last := u.Cursor
+	require.Equal(t, TrackingCursor{StatusChanges: 1, LastEventID: eventID}, last)
+
+	// новые записи приходят по мере появления
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusDelivered))
+	u, _ = nextUpdate(t, updates)
+	require.NotNil(t, u.StatusChange)
+	require.Equal(t, ParcelStatusDelivered, u.StatusChange.NewStatus)
+
+	// продолжение с курсора не повторяет полученное
+	resumed, err := service.StreamTracking(ctx, p.Number, last)
+	require.NoError(t, err)
+	u, _ = nextUpdate(t, resumed)
+	require.Equal(t, ParcelStatusDelivered, u.StatusChange.NewStatus)
+	require.Equal(t, TrackingCursor{StatusChanges: 2, LastEventID: eventID}, u.Cursor)
+
+	cancel()
+	_, ok := nextUpdate(t, updates)
+	require.False(t, ok)
+}
+
+// TestStreamTracking проверяет ленту отслеживания в SQLite
+func TestStreamTracking(t *testing.T) {
+	checkStreamTracking(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryStreamTracking проверяет ленту отслеживания в памяти
+func TestMemoryStreamTracking(t *testing.T) {
+	checkStreamTracking(t, NewMemoryParcelStore())
+}
+
+// nextSSEEvent читает из r записи, пропуская комментарии и retry, до записи с событием
+func nextSSEEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+
+	for {
+		if msg := readSSE(t, r); msg["event"] != "" {
+			return msg
+		}
+	}
+}
+
+// TestHTTPTrackingStream проверяет GET /parcels/{number}/events/stream
+// с Last-Event-ID и комментариями в молчащем потоке
+func TestHTTPTrackingStream(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	service := NewParcelService(store).WithWatchInterval(10 * time.Millisecond)
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+
+	api := NewHTTPServer(service)
+	api.heartbeat = 20 * time.Millisecond
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	defer api.CloseStreams()
+
+	stream := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/parcels/%d/events/stream", srv.URL, p.Number), nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp, bufio.NewReader(resp.Body)
+	}
+
+	// check
+	resp, body := stream("")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Equal(t, map[string]string{"retry": "3000"}, readSSE(t, body))
+
+	msg := readSSE(t, body)
+	require.Equal(t, "1-0", msg["id"])
+	require.Equal(t, "status_changed", msg["event"])
+	require.Contains(t, msg["data"], `"old_status":"registered","new_status":"sent"`)
+
+	// пока записей нет, в поток идут комментарии
+	require.Equal(t, map[string]string{"": "heartbeat"}, readSSE(t, body))
+
+	_, err = store.AddEvent(ctx, p.Number, "sorting", "", time.Now())
+	require.NoError(t, err)
+	msg = nextSSEEvent(t, body)
+	require.Equal(t, "tracking_event", msg["event"])
+	require.Equal(t, "1-1", msg["id"])
+
+	// переподключение с Last-Event-ID продолжает ленту
+	resp, body = stream("1-0")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	msg = nextSSEEvent(t, body)
+	require.Equal(t, "1-1", msg["id"])
+	require.Equal(t, "tracking_event", msg["event"])
+
+	resp, _ = stream("bad")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+)
+
+// ParcelTx даёт доступ к методам хранилища внутри одной транзакции.
+type ParcelTx interface {
+	ParcelStorage
+}
+
+// WithTx выполняет fn в транзакции. Если fn возвращает ошибку или паникует,
+// транзакция откатывается, иначе фиксируется. Вызов WithTx на хранилище,
+// уже привязанном к транзакции, выполняет fn в той же транзакции.
+func (s ParcelStore) WithTx(ctx context.Context, fn func(tx ParcelTx) error) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		return fn(tx)
+	})
+}
+
+func (s ParcelStore) withTx(ctx context.Context, fn func(tx ParcelStore) error) (err error) {
+	if _, ok := unwrapQuerier(s.q).(*sql.Tx); ok {
+		return fn(s)
+	}
+
+	db, err := s.sqlDB()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	txStore := s
+	txStore.q = s.wrap(tx)
+
+	return fn(txStore)
+}
+
+// WithTx выполняет fn над копией данных и применяет изменения,
+// только если fn завершилась без ошибки. Внутри fn нужно работать только
+// с tx: хранилище заблокировано до конца транзакции.
+func (s *MemoryParcelStore) WithTx(ctx context.Context, fn func(tx ParcelTx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txStore := &MemoryParcelStore{
+		transitions: s.transitions,
+		addresses:   s.addresses,
+		keys:        s.keys,
+		now:         s.now,
+
+		paymentRequired: s.paymentRequired,
+	}
+	txStore.copyData(s)
+
+	if err := fn(txStore); err != nil {
+		return err
+	}
+
+	s.copyData(txStore)
+
+	return nil
+}
+
+// copyData заменяет посылки, их историю, события, вложения и прочие
+// данные хранилища, а также счётчики идентификаторов глубокими копиями
+// из src, чтобы изменения одного хранилища не задевали другое.
+func (s *MemoryParcelStore) copyData(src *MemoryParcelStore) {
+	s.parcels = maps.Clone(src.parcels)
+	s.deleted = maps.Clone(src.deleted)
+	s.history = cloneSlices(src.history)
+	s.events = cloneSlices(src.events)
+	s.tenants = maps.Clone(src.tenants)
+	s.idempotent = maps.Clone(src.idempotent)
+	s.claims = slices.Clone(src.claims)
+	s.notes = cloneSlices(src.notes)
+	s.attachments = cloneSlices(src.attachments)
+	s.signatures = cloneSlices(src.signatures)
+	s.attempts = cloneSlices(src.attempts)
+	s.last = src.last
+	s.lastEvent = src.lastEvent
+	s.lastClaim = src.lastClaim
+	s.lastNote = src.lastNote
+	s.lastAttach = src.lastAttach
+	s.lastAttempt = src.lastAttempt
+}
+
+// cloneSlices копирует m вместе со срезами-значениями.
+func cloneSlices[K comparable, V any](m map[K][]V) map[K][]V {
+	res := make(map[K][]V, len(m))
+	for k, v := range m {
+		res[k] = slices.Clone(v)
+	}
+	return res
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithTxCommit проверяет, что изменения в транзакции фиксируются
+func TestWithTxCommit(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+
+	// add + set status
+	var id int
+	err := store.WithTx(ctx, func(tx ParcelTx) error {
+		var err error
+		id, err = tx.Add(ctx, getTestParcel())
+		if err != nil {
+			return err
+		}
+		return tx.SetStatus(ctx, id, ParcelStatusSent)
+	})
+	require.NoError(t, err)
+
+	// check
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+}
+
+// TestWithTxRollback проверяет, что при ошибке изменения откатываются
+func TestWithTxRollback(t *testing.T) {
+	// prepare
+	db := openTempDB(t)
+
+	ctx := context.Background()
+	store := NewParcelStore(db)
+	errStop := errors.New("stop")
+
+	// add
+	var id int
+	err := store.WithTx(ctx, func(tx ParcelTx) error {
+		var err error
+		id, err = tx.Add(ctx, getTestParcel())
+		if err != nil {
+			return err
+		}
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+
+	// check
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestMemoryWithTxRollback проверяет откат транзакции в хранилище в памяти
+func TestMemoryWithTxRollback(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewMemoryParcelStore()
+	errStop := errors.New("stop")
+
+	// add
+	err := store.WithTx(ctx, func(tx ParcelTx) error {
+		_, err := tx.Add(ctx, getTestParcel())
+		if err != nil {
+			return err
+		}
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+
+	// check
+	parcels, err := store.GetByClient(ctx, 1000)
+	require.NoError(t, err)
+	require.Empty(t, parcels)
+}
+
+// TestMemoryWithTxData проверяет, что транзакция в памяти применяет
+// и откатывает ключи идемпотентности, заметки, вложения, подписи,
+// попытки доставки и претензии
+func TestMemoryWithTxData(t *testing.T) {
+	// prepare
+	ctx := WithIdempotencyKey(context.Background(), "order-1")
+	store := NewMemoryParcelStore()
+	sig := Signature{SignedBy: "Иванов", Image: testPNG}
+	write := func(tx *MemoryParcelStore) int {
+		number, err := tx.Add(ctx, getTestParcel())
+		require.NoError(t, err)
+		require.NoError(t, tx.SetStatus(ctx, number, ParcelStatusSent))
+		_, err = tx.AddNote(ctx, number, "оператор", "позвонить заранее")
+		require.NoError(t, err)
+		_, err = tx.AddAttachment(ctx, Attachment{Number: number, Kind: AttachmentPhoto})
+		require.NoError(t, err)
+		_, err = tx.AddDeliveryAttempt(ctx, number, AttemptNoAnswer, "")
+		require.NoError(t, err)
+		_, err = tx.OpenClaim(ctx, Claim{Number: number, Type: ClaimDamaged, Description: "мятая коробка"})
+		require.NoError(t, err)
+		require.NoError(t, tx.Deliver(ctx, number, sig))
+		return number
+	}
+
+	// add
+	errStop := errors.New("stop")
+	err := store.WithTx(ctx, func(tx ParcelTx) error {
+		write(tx.(*MemoryParcelStore))
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+
+	// check
+	parcels, err := store.GetByClient(ctx, getTestParcel().Client)
+	require.NoError(t, err)
+	require.Empty(t, parcels)
+
+	var number int
+	require.NoError(t, store.WithTx(ctx, func(tx ParcelTx) error {
+		number = write(tx.(*MemoryParcelStore))
+		return nil
+	}))
+	notes, err := store.GetNotes(ctx, number)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	attachments, err := store.ListAttachments(ctx, number)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	attempts, err := store.GetAttempts(ctx, number)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	claims, err := store.ListClaims(ctx, number)
+	require.NoError(t, err)
+	require.Len(t, claims, 1)
+	got, err := store.GetSignature(ctx, number)
+	require.NoError(t, err)
+	require.Equal(t, sig.Image, got.Image)
+
+	// повтор с тем же ключом возвращает посылку из транзакции,
+	// а новые записи продолжают счётчики, а не начинают их заново
+	replayed, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	require.Equal(t, number, replayed)
+	note, err := store.AddNote(ctx, number, "оператор", "ещё одна")
+	require.NoError(t, err)
+	require.Equal(t, notes[0].ID+1, note.ID)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Update сохраняет клиента, адреса и статус посылки p.Number одним действием.
+// p.Version должна совпадать с текущей версией посылки, иначе кто-то уже
+// изменил её после чтения и возвращается ErrConflict; при успехе версия
+// увеличивается на 1. Действуют общие правила: новые адреса нормализуются,
+// проверяются и меняются только в статусе registered, статус — по правилам
+// переходов с записью в историю. Поля p проверяются через Validate.
+func (s ParcelStore) Update(ctx context.Context, p Parcel) error {
+	return s.withTx(ctx, func(tx ParcelStore) error {
+		cond, args := tenantCond(ctx)
+		var current Parcel
+		err := tx.q.QueryRowContext(ctx, tx.dialect.rebind(
+			"SELECT status, sender_address, recipient_address, version, payment_status FROM parcel WHERE number = ? AND deleted_at IS NULL"+cond),
+			append([]any{p.Number}, args...)...).Scan(&current.Status, &current.SenderAddress, &current.RecipientAddress, &current.Version, &current.PaymentStatus)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrParcelNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.openParcel(&current); err != nil {
+			return err
+		}
+
+		if p, err = updateAddress(tx.addresses, current, p); err != nil {
+			return err
+		}
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if err := validateUpdate(tx.transitions, tx.paymentRequired, current, p); err != nil {
+			return err
+		}
+
+		sealed, err := tx.sealParcel(p)
+		if err != nil {
+			return err
+		}
+
+		// версия в WHERE защищает от записи, успевшей между SELECT и UPDATE
+		res, err := tx.q.ExecContext(ctx, tx.dialect.rebind(
+			"UPDATE parcel SET client = ?, status = ?, sender_address = ?, recipient_address = ?,"+
+				" version = version + 1, updated_at = ? WHERE number = ? AND version = ? AND deleted_at IS NULL"),
+			p.Client, p.Status, sealed.SenderAddress, sealed.RecipientAddress, tx.timestampArg(), p.Number, p.Version)
+		if err != nil {
+			return clientError(err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrConflict
+		}
+
+		if p.Status == current.Status {
+			return nil
+		}
+		return tx.addHistory(ctx, StatusChange{
+			Number:    p.Number,
+			OldStatus: current.Status,
+			NewStatus: p.Status,
+			ChangedAt: formatTime(s.now()),
+		})
+	})
+}
+
+// updateAddress нормализует и проверяет новые адреса посылки p. Прежние
+// адреса current не трогаются, чтобы старые записи, сохранённые до
+// нормализации, не считались сменой адреса.
+func updateAddress(v AddressValidator, current, p Parcel) (Parcel, error) {
+	var err error
+	if p.RecipientAddress != current.RecipientAddress {
+		if p.RecipientAddress, err = prepareAddress(v, p.RecipientAddress); err != nil {
+			return p, err
+		}
+	}
+	if p.SenderAddress != current.SenderAddress && p.SenderAddress != "" {
+		if p.SenderAddress, err = prepareAddress(v, p.SenderAddress); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// validateUpdate проверяет, что посылку current можно привести к p
+// по правилам хранилища: переходам t и, если paymentRequired, запрету
+// отправки без оплаты.
+func validateUpdate(t StatusTransitions, paymentRequired bool, current, p Parcel) error {
+	if current.Version != p.Version {
+		return ErrConflict
+	}
+	addressChanged := p.SenderAddress != current.SenderAddress || p.RecipientAddress != current.RecipientAddress
+	if addressChanged && current.Status != ParcelStatusRegistered {
+		return ErrAddressChangeNotAllowed
+	}
+	if p.Status != current.Status {
+		if err := t.Validate(current.Status, p.Status); err != nil {
+			return err
+		}
+		return checkPayment(paymentRequired, current, p.Status)
+	}
+	return nil
+}
+
+// Update сохраняет клиента, адреса и статус посылки, если p.Version
+// совпадает с текущей версией, иначе возвращает ErrConflict.
+func (s *MemoryParcelStore) Update(ctx context.Context, p Parcel) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.parcel(ctx, p.Number)
+	if !ok {
+		return ErrParcelNotFound
+	}
+	p, err := updateAddress(s.addresses, current, p)
+	if err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := validateUpdate(s.transitions, s.paymentRequired, current, p); err != nil {
+		return err
+	}
+
+	if p.Status != current.Status {
+		s.history[p.Number] = append(s.history[p.Number], StatusChange{
+			Number:    p.Number,
+			OldStatus: current.Status,
+			NewStatus: p.Status,
+			ChangedAt: formatTime(s.now()),
+		})
+	}
+	current.Client = p.Client
+	current.Status = p.Status
+	current.SenderAddress = p.SenderAddress
+	current.RecipientAddress = p.RecipientAddress
+	current.Version++
+	current.UpdatedAt = timestamp(s.now)
+	s.parcels[p.Number] = current
+
+	return nil
+}
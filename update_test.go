@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkUpdate проверяет обновление посылки и защиту от одновременных изменений
+func checkUpdate(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Version)
+
+	// update
+	p.RecipientAddress = "new test address"
+	p.Status = ParcelStatusSent
+	require.NoError(t, store.Update(ctx, p))
+
+	stored, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "new test address", stored.RecipientAddress)
+	require.Equal(t, ParcelStatusSent, stored.Status)
+	require.Equal(t, 2, stored.Version)
+
+	history, err := store.GetHistory(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	// устаревшая версия
+	p.Client++
+	err = store.Update(ctx, p)
+	require.ErrorIs(t, err, ErrConflict)
+
+	// изменение через другой метод тоже меняет версию
+	require.NoError(t, store.SetStatus(ctx, id, ParcelStatusDelivered))
+	err = store.Update(ctx, stored)
+	require.ErrorIs(t, err, ErrConflict)
+
+	// правила хранилища
+	stored, err = store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 3, stored.Version)
+
+	stored.RecipientAddress = "other address"
+	err = store.Update(ctx, stored)
+	require.ErrorIs(t, err, ErrAddressChangeNotAllowed)
+
+	stored.RecipientAddress = "new test address"
+	stored.Status = ParcelStatusRegistered
+	err = store.Update(ctx, stored)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+
+	// not found
+	err = store.Update(ctx, Parcel{Number: -1, Version: 1})
+	require.ErrorIs(t, err, ErrParcelNotFound)
+}
+
+// TestUpdate проверяет Update в SQLite
+func TestUpdate(t *testing.T) {
+	checkUpdate(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryUpdate проверяет Update в памяти
+func TestMemoryUpdate(t *testing.T) {
+	checkUpdate(t, NewMemoryParcelStore())
+}
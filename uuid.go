@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidUUID возвращается для строки, которая не является UUID.
+var ErrInvalidUUID = errors.New("invalid parcel uuid")
+
+// KeyMode определяет, какие ключи хранилище выдаёт новым посылкам.
+type KeyMode string
+
+const (
+	// KeyModeInt — только номер из автоинкремента, как раньше.
+	KeyModeInt KeyMode = "int"
+	// KeyModeUUID — дополнительно UUIDv7 в Parcel.UUID. Номера разных
+	// экземпляров совпадают, а UUID уникальны глобально, поэтому базы
+	// можно сливать по ним. UUIDv7 растут со временем и не фрагментируют индекс.
+	KeyModeUUID KeyMode = "uuid"
+)
+
+// newParcelUUID генерирует UUIDv7 для посылки.
+func newParcelUUID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// WithKeyMode возвращает копию хранилища, выдающую ключи в режиме m.
+func (s ParcelStore) WithKeyMode(m KeyMode) ParcelStore {
+	s.keys = m
+	return s
+}
+
+// GetByUUID возвращает посылку по UUID.
+func (s ParcelStore) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return Parcel{}, ErrInvalidUUID
+	}
+
+	cond, args := tenantCond(ctx)
+	row := s.reader(0).QueryRowContext(ctx, s.dialect.rebind(
+		"SELECT "+parcelColumns+" FROM parcel WHERE uuid = ? AND deleted_at IS NULL"+cond),
+		append([]any{parsed.String()}, args...)...)
+
+	p, err := s.scanParcel(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrParcelNotFound
+	}
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// SetKeyMode задаёт режим выдачи ключей новым посылкам.
+func (s *MemoryParcelStore) SetKeyMode(m KeyMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = m
+}
+
+// GetByUUID возвращает посылку по UUID.
+func (s *MemoryParcelStore) GetByUUID(ctx context.Context, id string) (Parcel, error) {
+	if err := ctx.Err(); err != nil {
+		return Parcel{}, err
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return Parcel{}, ErrInvalidUUID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.parcels {
+		if p.UUID == parsed.String() && s.visible(ctx, p.Number) {
+			return p, nil
+		}
+	}
+
+	return Parcel{}, ErrParcelNotFound
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// checkUUIDKeys проверяет выдачу UUID и поиск по нему в хранилище store,
+// работающем в режиме KeyModeUUID
+func checkUUIDKeys(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+	first, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+	second, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, first)
+	require.NoError(t, err)
+	parsed, err := uuid.Parse(p.UUID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(7), parsed.Version())
+
+	next, err := store.Get(ctx, second)
+	require.NoError(t, err)
+	require.Less(t, p.UUID, next.UUID)
+
+	// get by uuid
+	found, err := store.GetByUUID(ctx, strings.ToUpper(p.UUID))
+	require.NoError(t, err)
+	require.Equal(t, p, found)
+
+	_, err = store.GetByUUID(ctx, newParcelUUID())
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	_, err = store.GetByUUID(ctx, "42")
+	require.ErrorIs(t, err, ErrInvalidUUID)
+}
+
+// TestUUIDKeys проверяет режим KeyModeUUID в SQLite
+func TestUUIDKeys(t *testing.T) {
+	checkUUIDKeys(t, NewParcelStore(openTempDB(t)).WithKeyMode(KeyModeUUID))
+}
+
+// TestMemoryUUIDKeys проверяет режим KeyModeUUID в памяти
+func TestMemoryUUIDKeys(t *testing.T) {
+	store := NewMemoryParcelStore()
+	store.SetKeyMode(KeyModeUUID)
+	checkUUIDKeys(t, store)
+}
+
+// TestIntKeys проверяет, что в режиме по умолчанию UUID не выдаётся,
+// а заданный вызывающим UUID сохраняется
+func TestIntKeys(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+
+	// add
+	id, err := store.Add(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	imported := getTestParcel()
+	imported.UUID = newParcelUUID()
+	importedID, err := store.Add(ctx, imported)
+	require.NoError(t, err)
+
+	// check
+	p, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Empty(t, p.UUID)
+
+	found, err := store.GetByUUID(ctx, imported.UUID)
+	require.NoError(t, err)
+	require.Equal(t, importedID, found.Number)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidParcel возвращается, если посылка не прошла Validate.
+// Подробности по полям — в ValidationError.
+var ErrInvalidParcel = errors.New("invalid parcel")
+
+// Ограничения длины полей посылки, совпадают с колонками parcel.
+const (
+	maxStatusLen     = 128
+	maxPaymentRefLen = 128
+)
+
+// FieldError — ошибка в одном поле посылки. Field совпадает с именем
+// поля в JSON API, например recipient_address.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError собирает ошибки всех полей посылки, а не только первого.
+// errors.Is находит через неё ErrInvalidParcel и ошибки отдельных полей,
+// например ErrInvalidAddress.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return ErrInvalidParcel.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidParcel
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
+// Validate проверяет обязательные поля и длины посылки p: клиент,
+// статус и адрес получателя заданы, строки помещаются в колонки,
+// трек-код, UUID, вес, габариты и класс обслуживания корректны. Новая посылка, ещё без номера,
+// может быть только в статусе registered. Формат адресов проверяет
+// AddressValidator хранилища, здесь — только их наличие и длина.
+// Возвращает *ValidationError со всеми найденными ошибками или nil.
+func (p Parcel) Validate() error {
+	var errs []FieldError
+	invalid := func(field string, err error, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Err: fmt.Errorf("%w: "+format, append([]any{err}, args...)...)})
+	}
+
+	if p.Client <= 0 {
+		invalid("client", ErrInvalidParcel, "must be positive")
+	}
+
+	switch {
+	case p.Status == "":
+		invalid("status", ErrInvalidParcel, "required")
+	case utf8.RuneCountInString(string(p.Status)) > maxStatusLen:
+		invalid("status", ErrInvalidParcel, "longer than %d characters", maxStatusLen)
+	case p.Number == 0 && p.Status != ParcelStatusRegistered:
+		invalid("status", ErrInvalidParcel, "new parcel must be %s", ParcelStatusRegistered)
+	}
+
+	if strings.TrimSpace(p.RecipientAddress) == "" {
+		invalid("recipient_address", ErrInvalidAddress, "required")
+	} else if utf8.RuneCountInString(p.RecipientAddress) > maxAddressLen {
+		invalid("recipient_address", ErrInvalidAddress, "longer than %d characters", maxAddressLen)
+	}
+	if utf8.RuneCountInString(p.SenderAddress) > maxAddressLen {
+		invalid("sender_address", ErrInvalidAddress, "longer than %d characters", maxAddressLen)
+	}
+
+	if p.TrackCode != "" {
+		if _, err := NormalizeTrackCode(p.TrackCode); err != nil {
+			errs = append(errs, FieldError{Field: "track_code", Err: err})
+		}
+	}
+	if p.UUID != "" {
+		if _, err := uuid.Parse(p.UUID); err != nil {
+			invalid("uuid", ErrInvalidUUID, "%v", err)
+		}
+	}
+	if err := validateDimensions(p); err != nil {
+		errs = append(errs, FieldError{Field: "dimensions", Err: err})
+	}
+	if err := validateServiceClass(p.ServiceClass); err != nil {
+		errs = append(errs, FieldError{Field: "service_class", Err: err})
+	}
+	if utf8.RuneCountInString(p.PaymentRef) > maxPaymentRefLen {
+		invalid("payment_ref", ErrInvalidPayment, "longer than %d characters", maxPaymentRefLen)
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
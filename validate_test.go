@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParcelValidate проверяет, что Validate собирает ошибки всех полей
+func TestParcelValidate(t *testing.T) {
+	// prepare
+	require.NoError(t, getTestParcel().Validate())
+
+	parcel := Parcel{
+		Status:        ParcelStatusSent,
+		SenderAddress: strings.Repeat("а", maxAddressLen+1),
+		TrackCode:     "abc",
+		WeightGrams:   -1,
+	}
+
+	// check
+	err := parcel.Validate()
+	require.ErrorIs(t, err, ErrInvalidParcel)
+	require.ErrorIs(t, err, ErrInvalidAddress)
+	require.ErrorIs(t, err, ErrInvalidTrackCode)
+	require.ErrorIs(t, err, ErrInvalidDimensions)
+
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	fields := make([]string, 0, len(invalid.Fields))
+	for _, f := range invalid.Fields {
+		fields = append(fields, f.Field)
+	}
+	require.Equal(t, []string{"client", "status", "recipient_address", "sender_address", "track_code", "dimensions"}, fields)
+
+	// у посылки с номером статус может быть любым
+	stored := getTestParcel()
+	stored.Number = 1
+	stored.Status = ParcelStatusDelivered
+	require.NoError(t, stored.Validate())
+}
+
+// checkAddValidation проверяет проверку посылки в Add и Update хранилища store
+func checkAddValidation(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx := context.Background()
+
+	// add
+	noClient := getTestParcel()
+	noClient.Client = 0
+	_, err := store.Add(ctx, noClient)
+	require.ErrorIs(t, err, ErrInvalidParcel)
+
+	sent := getTestParcel()
+	sent.Status = ParcelStatusSent
+	_, err = store.Add(ctx, sent)
+	require.ErrorIs(t, err, ErrInvalidParcel)
+
+	noStatus := getTestParcel()
+	noStatus.Status = ""
+	number, err := store.Add(ctx, noStatus)
+	require.NoError(t, err)
+
+	// update
+	p, err := store.Get(ctx, number)
+	require.NoError(t, err)
+	require.Equal(t, ParcelStatusRegistered, p.Status)
+	p.Client = 0
+	require.ErrorIs(t, store.Update(ctx, p), ErrInvalidParcel)
+}
+
+// TestAddValidation проверяет проверку посылок в SQLite
+func TestAddValidation(t *testing.T) {
+	checkAddValidation(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryAddValidation проверяет проверку посылок в памяти
+func TestMemoryAddValidation(t *testing.T) {
+	checkAddValidation(t, NewMemoryParcelStore())
+}
+
+// TestHTTPValidationError проверяет ошибки по полям в ответе REST API
+func TestHTTPValidationError(t *testing.T) {
+	// prepare
+	srv := NewHTTPServer(NewParcelService(NewMemoryParcelStore()))
+
+	// add
+	rec := doRequest(t, srv, http.MethodPost, "/parcels", `{"client": 0, "recipient_address": ""}`)
+
+	// check
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp errorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Contains(t, resp.Fields, "client")
+	require.Contains(t, resp.Fields, "recipient_address")
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoRouteStore возвращает WatchRoute сервиса без WithRoutes.
+var ErrNoRouteStore = errors.New("parcel service has no route store")
+
+// defaultWatchInterval — как часто Watch по умолчанию перечитывает посылку.
+const defaultWatchInterval = time.Second
+
+// WithWatchInterval возвращает копию сервиса, в которой Watch перечитывает
+// посылку раз в d. Неположительный d означает defaultWatchInterval.
+func (s ParcelService) WithWatchInterval(d time.Duration) ParcelService {
+	s.watchInterval = d
+	return s
+}
+
+// Watch возвращает канал изменений посылки number: события смены статуса
+// и адреса, как в шине событий, и ParcelDeleted, после которого канал
+// закрывается. Канал закрывается и при отмене ctx. Для отсутствующей
+// посылки сразу возвращается ErrParcelNotFound.
+//
+// Сервис сам перечитывает посылку раз в WithWatchInterval, поэтому видит
+// изменения из других процессов при любом хранилище, а клиент не опрашивает
+// Get. Несколько изменений между двумя чтениями приходят одним событием
+// с последним значением. Ошибка чтения логируется, и Watch пробует снова
+// на следующем шаге.
+func (s ParcelService) Watch(ctx context.Context, number int) (<-chan ParcelEvent, error) {
+	old, err := s.store.Get(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := s.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	events := make(chan ParcelEvent)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			p, err := s.store.Get(ctx, number)
+			switch {
+			case errors.Is(err, ErrParcelNotFound):
+				p = Parcel{}
+			case err != nil:
+				if ctx.Err() == nil {
+					s.logger.WarnContext(ctx, "watch parcel", "number", number, "error", err)
+				}
+				continue
+			}
+
+			for _, e := range changeEvents(old, p) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if p.Number == 0 {
+				return
+			}
+			old = p
+		}
+	}()
+
+	return events, nil
+}
+
+// WatchRoute возвращает канал отметок посылки number о прохождении пунктов
+// маршрута: сначала уже записанные, затем новые по мере появления. Как
+// и Watch, сервис перечитывает маршрут раз в WithWatchInterval; канал
+// закрывается при отмене ctx и удалении посылки.
+func (s ParcelService) WatchRoute(ctx context.Context, number int) (<-chan Checkpoint, error) {
+	if s.routes == nil {
+		return nil, ErrNoRouteStore
+	}
+	route, err := s.routes.GetRoute(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := s.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	checkpoints := make(chan Checkpoint)
+	go func() {
+		defer close(checkpoints)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		seen := map[int]bool{}
+		for {
+			for _, c := range route {
+				if seen[c.ID] {
+					continue
+				}
+				select {
+				case checkpoints <- c:
+					seen[c.ID] = true
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			route, err = s.routes.GetRoute(ctx, number)
+			switch {
+			case errors.Is(err, ErrParcelNotFound):
+				return
+			case err != nil:
+				if ctx.Err() == nil {
+					s.logger.WarnContext(ctx, "watch route", "number", number, "error", err)
+				}
+			}
+		}
+	}()
+
+	return checkpoints, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nextEvent ждёт событие из events не дольше секунды
+func nextEvent(t *testing.T, events <-chan ParcelEvent) (ParcelEvent, bool) {
+	t.Helper()
+
+	select {
+	case e, ok := <-events:
+		return e, ok
+	case <-time.After(time.Second):
+		t.Fatal("no event from Watch")
+		return ParcelEvent{}, false
+	}
+}
+
+// checkWatch проверяет события Watch поверх хранилища store
+func checkWatch(t *testing.T, store ParcelStorage) {
+	t.Helper()
+
+	// prepare
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewParcelService(store).WithWatchInterval(10 * time.Millisecond)
+
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	deleted, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	_, err = service.Watch(ctx, deleted.Number+1)
+	require.ErrorIs(t, err, ErrParcelNotFound)
+
+	events, err := service.Watch(ctx, p.Number)
+	require.NoError(t, err)
+	deletedEvents, err := service.Watch(ctx, deleted.Number)
+	require.NoError(t, err)
+
+	// check
+	require.NoError(t, service.ChangeAddress(ctx, p.Number, "new address"))
+	e, ok := nextEvent(t, events)
+	require.True(t, ok)
+	require.Equal(t, EventAddressChanged, e.Type)
+	require.Equal(t, "new address", e.RecipientAddress)
+
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+	e, _ = nextEvent(t, events)
+	require.Equal(t, EventStatusChanged, e.Type)
+	require.Equal(t, ParcelStatusSent.String(), e.Status)
+	require.Equal(t, ParcelStatusRegistered.String(), e.OldStatus)
+
+	require.NoError(t, service.Delete(ctx, deleted.Number))
+	e, _ = nextEvent(t, deletedEvents)
+	require.Equal(t, EventParcelDeleted, e.Type)
+	_, ok = nextEvent(t, deletedEvents)
+	require.False(t, ok)
+
+	cancel()
+	_, ok = nextEvent(t, events)
+	require.False(t, ok)
+}
+
+// TestWatch проверяет Watch в SQLite
+func TestWatch(t *testing.T) {
+	checkWatch(t, NewParcelStore(openTempDB(t)))
+}
+
+// TestMemoryWatch проверяет Watch в памяти
+func TestMemoryWatch(t *testing.T) {
+	checkWatch(t, NewMemoryParcelStore())
+}
+
+// readSSE читает из r одну запись потока Server-Sent Events по полям;
+// комментарий возвращается под пустым именем поля
+func readSSE(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+
+	msg := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return msg
+		}
+		field, value, _ := strings.Cut(line, ":")
+		msg[field] = strings.TrimPrefix(value, " ")
+	}
+}
+
+// TestHTTPWatch проверяет поток Server-Sent Events и его закрытие при остановке
+func TestHTTPWatch(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	service := NewParcelService(NewMemoryParcelStore()).WithWatchInterval(10 * time.Millisecond)
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+
+	api := NewHTTPServer(service)
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%s/parcels/%d/watch", srv.URL, p.Number))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// check
+	body := bufio.NewReader(resp.Body)
+	require.Equal(t, map[string]string{"retry": "3000"}, readSSE(t, body))
+
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+	msg := readSSE(t, body)
+	require.Equal(t, EventStatusChanged, msg["event"])
+	require.Contains(t, msg["data"], `"status":"sent"`)
+
+	api.CloseStreams()
+	rest, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+
+	missing, err := http.Get(fmt.Sprintf("%s/parcels/%d/watch", srv.URL, p.Number+1))
+	require.NoError(t, err)
+	missing.Body.Close()
+	require.Equal(t, http.StatusNotFound, missing.StatusCode)
+}
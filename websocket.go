@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// wsMaxSubscriptions — сколько посылок по умолчанию отслеживает одно
+// соединение WebSocket.
+const wsMaxSubscriptions = 20
+
+// ErrTooManySubscriptions возвращается подписке сверх лимита соединения.
+var ErrTooManySubscriptions = errors.New("too many subscriptions")
+
+// Действия клиента WebSocket.
+const (
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+)
+
+// wsRequest — сообщение клиента: подписка на посылки Numbers или отписка
+// от них. After — курсор ленты отслеживания, с которого продолжить, как
+// в Last-Event-ID потока /parcels/{number}/events/stream.
+type wsRequest struct {
+	Action  string `json:"action"`
+	Numbers []int  `json:"numbers"`
+	After   string `json:"after,omitempty"`
+}
+
+// wsMessage — сообщение сервера. Type — subscribed, unsubscribed,
+// status_changed, tracking_event, checkpoint или error; к типу
+// заполняется одно из полей с данными.
+type wsMessage struct {
+	Type         string                 `json:"type"`
+	Number       int                    `json:"number,omitempty"`
+	Cursor       string                 `json:"cursor,omitempty"`
+	StatusChange *statusChangeResponse  `json:"status_change,omitempty"`
+	Event        *trackingEventResponse `json:"event,omitempty"`
+	Checkpoint   *checkpointResponse    `json:"checkpoint,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+type checkpointResponse struct {
+	ID        int    `json:"id"`
+	Location  string `json:"location"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	ArrivedAt string `json:"arrived_at"`
+}
+
+// wsConn — подписки одного соединения WebSocket: номер посылки → отмена.
+type wsConn struct {
+	s    *HTTPServer
+	conn *websocket.Conn
+
+	mu   sync.Mutex
+	subs map[int]context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// handleWS открывает соединение WebSocket, в котором клиент подписывается
+// на посылки сообщениями wsRequest и получает wsMessage при каждой смене
+// статуса, событии отслеживания и отметке маршрута. Удалённая посылка
+// отписывается сама. При остановке сервера соединение закрывается
+// с кодом 1001 Going Away.
+func (s *HTTPServer) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx, cancel := s.streamContext(r)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close(websocket.StatusGoingAway, "server is shutting down")
+	})
+
+	c := &wsConn{s: s, conn: conn, subs: map[int]context.CancelFunc{}}
+	defer func() {
+		stop()
+		cancel()
+		c.wg.Wait()
+	}()
+
+	for {
+		// чтение завершает закрытие соединения, в том числе из AfterFunc
+		_, data, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.send(ctx, wsMessage{Type: "error", Error: "invalid JSON: " + err.Error()})
+			continue
+		}
+		switch req.Action {
+		case wsSubscribe:
+			after, err := ParseTrackingCursor(req.After)
+			if err != nil {
+				c.send(ctx, wsMessage{Type: "error", Error: err.Error()})
+				continue
+			}
+			for _, number := range req.Numbers {
+				c.subscribe(ctx, number, after)
+			}
+		case wsUnsubscribe:
+			for _, number := range req.Numbers {
+				c.unsubscribe(number)
+				c.send(ctx, wsMessage{Type: "unsubscribed", Number: number})
+			}
+		default:
+			c.send(ctx, wsMessage{Type: "error", Error: fmt.Sprintf("unknown action %q", req.Action)})
+		}
+	}
+}
+
+// subscribe начинает отслеживать посылку number с курсора after.
+// Повторная подписка на ту же посылку ничего не меняет.
+func (c *wsConn) subscribe(ctx context.Context, number int, after TrackingCursor) {
+	c.mu.Lock()
+	if _, ok := c.subs[number]; ok {
+		c.mu.Unlock()
+		c.send(ctx, wsMessage{Type: "subscribed", Number: number})
+		return
+	}
+	if len(c.subs) >= c.s.maxSubscriptions {
+		c.mu.Unlock()
+		c.sendError(ctx, number, ErrTooManySubscriptions)
+		return
+	}
+
+	sub, cancel := context.WithCancel(ctx)
+	updates, err := c.s.service.StreamTracking(sub, number, after)
+	if err != nil {
+		c.mu.Unlock()
+		cancel()
+		c.sendError(ctx, number, err)
+		return
+	}
+	// маршрут есть не у всех хранилищ: без него приходят только статусы и события
+	checkpoints, err := c.s.service.WatchRoute(sub, number)
+	if err != nil && !errors.Is(err, ErrNoRouteStore) {
+		c.mu.Unlock()
+		cancel()
+		c.sendError(ctx, number, err)
+		return
+	}
+	c.subs[number] = cancel
+	c.mu.Unlock()
+
+	c.send(ctx, wsMessage{Type: "subscribed", Number: number})
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+
+		for updates != nil || checkpoints != nil {
+			var msg wsMessage
+			select {
+			case u, ok := <-updates:
+				if !ok {
+					// лента закрывается при удалении посылки и при отписке
+					if sub.Err() == nil {
+						c.unsubscribe(number)
+						c.send(ctx, wsMessage{Type: "unsubscribed", Number: number})
+					}
+					return
+				}
+				msg = wsUpdateMessage(number, u)
+			case cp, ok := <-checkpoints:
+				if !ok {
+					checkpoints = nil
+					continue
+				}
+				msg = wsMessage{Type: "checkpoint", Number: number, Checkpoint: &checkpointResponse{
+					ID:        cp.ID,
+					Location:  cp.Location.Code,
+					Name:      cp.Location.Name,
+					Kind:      cp.Location.Kind,
+					ArrivedAt: formatTime(cp.ArrivedAt),
+				}}
+			}
+			if err := c.send(sub, msg); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// unsubscribe прекращает отслеживать посылку number.
+func (c *wsConn) unsubscribe(number int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cancel, ok := c.subs[number]; ok {
+		cancel()
+		delete(c.subs, number)
+	}
+}
+
+func (c *wsConn) send(ctx context.Context, msg wsMessage) error {
+	return wsjson.Write(ctx, c.conn, msg)
+}
+
+// sendError отправляет ошибку подписки; детали ошибок БД наружу не отдаём.
+func (c *wsConn) sendError(ctx context.Context, number int, err error) {
+	if httpStatus(err) == http.StatusInternalServerError {
+		err = errors.New(http.StatusText(http.StatusInternalServerError))
+	}
+	c.send(ctx, wsMessage{Type: "error", Number: number, Error: err.Error()})
+}
+
+// wsUpdateMessage переводит запись ленты отслеживания в сообщение WebSocket.
+func wsUpdateMessage(number int, u TrackingUpdate) wsMessage {
+	msg := wsMessage{Number: number, Cursor: u.Cursor.String()}
+	if c := u.StatusChange; c != nil {
+		msg.Type = "status_changed"
+		msg.StatusChange = &statusChangeResponse{OldStatus: string(c.OldStatus), NewStatus: string(c.NewStatus), ChangedAt: c.ChangedAt}
+	} else {
+		e := u.Event
+		msg.Type = "tracking_event"
+		msg.Event = &trackingEventResponse{ID: e.ID, Code: e.Code, Description: e.Description, OccurredAt: e.OccurredAt}
+	}
+	return msg
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/require"
+)
+
+// dialWS открывает соединение WebSocket с /ws тестового сервера srv
+func dialWS(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws"+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+// readWS ждёт сообщение сервера не дольше секунды
+func readWS(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var msg wsMessage
+	require.NoError(t, wsjson.Read(ctx, conn, &msg))
+	return msg
+}
+
+// TestHTTPWebSocket проверяет подписку на посылки через /ws: смены статуса,
+// отметки маршрута, отписку удалённой посылки и лимит подписок
+func TestHTTPWebSocket(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	service := NewParcelService(store).WithRoutes(store).WithWatchInterval(10 * time.Millisecond)
+	p, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	deleted, err := service.Create(ctx, getTestParcel())
+	require.NoError(t, err)
+	hubID, err := store.AddLocation(ctx, Location{Code: "MSK-HUB-1", Name: "Москва", Kind: LocationHub})
+	require.NoError(t, err)
+
+	api := NewHTTPServer(service)
+	api.maxSubscriptions = 2
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	defer api.CloseStreams()
+
+	conn := dialWS(t, srv, "")
+	subscribe := func(numbers ...int) {
+		require.NoError(t, wsjson.Write(ctx, conn, wsRequest{Action: wsSubscribe, Numbers: numbers}))
+	}
+
+	// check
+	subscribe(p.Number, deleted.Number, deleted.Number+1)
+	require.Equal(t, wsMessage{Type: "subscribed", Number: p.Number}, readWS(t, conn))
+	require.Equal(t, wsMessage{Type: "subscribed", Number: deleted.Number}, readWS(t, conn))
+	require.Equal(t, wsMessage{Type: "error", Number: deleted.Number + 1, Error: ErrTooManySubscriptions.Error()}, readWS(t, conn))
+
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusSent))
+	msg := readWS(t, conn)
+	require.Equal(t, "status_changed", msg.Type)
+	require.Equal(t, p.Number, msg.Number)
+	require.Equal(t, "1-0", msg.Cursor)
+	require.Equal(t, ParcelStatusSent.String(), msg.StatusChange.NewStatus)
+
+	_, err = store.AddCheckpoint(ctx, p.Number, hubID, time.Now())
+	require.NoError(t, err)
+	msg = readWS(t, conn)
+	require.Equal(t, "checkpoint", msg.Type)
+	require.Equal(t, "MSK-HUB-1", msg.Checkpoint.Location)
+
+	// удалённая посылка отписывается и освобождает место под новую подписку
+	require.NoError(t, service.Delete(ctx, deleted.Number))
+	require.Equal(t, wsMessage{Type: "unsubscribed", Number: deleted.Number}, readWS(t, conn))
+	subscribe(deleted.Number + 1)
+	require.Equal(t, wsMessage{Type: "error", Number: deleted.Number + 1, Error: ErrParcelNotFound.Error()}, readWS(t, conn))
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("{")))
+	require.Equal(t, "error", readWS(t, conn).Type)
+
+	// после отписки обновления посылки не приходят, пока на неё не подпишутся снова
+	require.NoError(t, wsjson.Write(ctx, conn, wsRequest{Action: wsUnsubscribe, Numbers: []int{p.Number}}))
+	require.Equal(t, wsMessage{Type: "unsubscribed", Number: p.Number}, readWS(t, conn))
+	require.NoError(t, service.SetStatus(ctx, p.Number, ParcelStatusDelivered))
+	subscribe(p.Number)
+	require.Equal(t, wsMessage{Type: "subscribed", Number: p.Number}, readWS(t, conn))
+	// новая подписка без курсора повторяет ленту и маршрут с начала
+	types := map[string]int{}
+	for range 3 {
+		types[readWS(t, conn).Type]++
+	}
+	require.Equal(t, map[string]int{"status_changed": 2, "checkpoint": 1}, types)
+
+	// остановка сервера закрывает соединение с кодом Going Away
+	api.CloseStreams()
+	readCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	for {
+		if _, _, err = conn.Read(readCtx); err != nil {
+			break
+		}
+	}
+	require.Equal(t, websocket.StatusGoingAway, websocket.CloseStatus(err))
+}
+
+// TestHTTPWebSocketAuth проверяет ключ API в параметре access_token при открытии WebSocket
+func TestHTTPWebSocketAuth(t *testing.T) {
+	// prepare
+	ctx := context.Background()
+	store := NewParcelStore(openTempDB(t))
+	_, secret, err := store.IssueAPIKey(ctx, APIKey{Name: "dashboard", Scopes: []string{ScopeRead}})
+	require.NoError(t, err)
+
+	api := NewHTTPServer(NewParcelService(store))
+	srv := httptest.NewServer(NewAuthenticator(store).Middleware(api))
+	defer srv.Close()
+	defer api.CloseStreams()
+
+	// check
+	_, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	conn := dialWS(t, srv, "?access_token="+secret)
+	require.NoError(t, wsjson.Write(ctx, conn, wsRequest{Action: "ping"}))
+	require.Equal(t, wsMessage{Type: "error", Error: `unknown action "ping"`}, readWS(t, conn))
+}